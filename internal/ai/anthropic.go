@@ -0,0 +1,134 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// DefaultAnthropicModel is the model used when AI_MODEL is not set
+const DefaultAnthropicModel = "claude-3-5-haiku-latest"
+
+// anthropicURL is the Anthropic messages endpoint
+const anthropicURL = "https://api.anthropic.com/v1/messages"
+
+// anthropicVersion is the API version the requests are written for
+const anthropicVersion = "2023-06-01"
+
+// anthropicMaxTokens bounds the length of an answer
+const anthropicMaxTokens = 2048
+
+// Claude generates texts with the Anthropic messages API
+type Claude struct {
+	apiKey string
+	model  string
+	url    string
+	client *http.Client
+}
+
+// NewClaude creates a client for the model, DefaultAnthropicModel if model is empty
+func NewClaude(apiKey, model string) *Claude {
+	if model == "" {
+		model = DefaultAnthropicModel
+	}
+	return &Claude{
+		apiKey: apiKey,
+		model:  model,
+		url:    anthropicURL,
+		client: httpClient,
+	}
+}
+
+// GenerateText implements Provider
+func (c *Claude) GenerateText(ctx context.Context, words []string) (*Story, error) {
+	return generateText(ctx, c, words)
+}
+
+// Translate implements Provider
+func (c *Claude) Translate(ctx context.Context, text string) (string, error) {
+	return translate(ctx, c, text)
+}
+
+// GenerateExamples implements Provider
+func (c *Claude) GenerateExamples(ctx context.Context, word string, n int) ([]string, error) {
+	return generateExamples(ctx, c, word, n)
+}
+
+// DescribeWord implements Provider
+func (c *Claude) DescribeWord(ctx context.Context, word, translation string) (*WordDetails, error) {
+	return describeWord(ctx, c, word, translation)
+}
+
+// Converse implements Provider
+func (c *Claude) Converse(ctx context.Context, words []string, dialogue []Turn) (*Reply, error) {
+	return converse(ctx, c, words, dialogue)
+}
+
+// CheckSentence implements Provider
+func (c *Claude) CheckSentence(ctx context.Context, word, translation, sentence string) (*SentenceCheck, error) {
+	return checkSentence(ctx, c, word, translation, sentence)
+}
+
+// DefineWord implements Provider
+func (c *Claude) DefineWord(ctx context.Context, word, translation string) (string, error) {
+	return defineWord(ctx, c, word, translation)
+}
+
+// anthropicRequest is the body of a messages request
+type anthropicRequest struct {
+	Model     string        `json:"model"`
+	MaxTokens int           `json:"max_tokens"`
+	System    string        `json:"system"`
+	Messages  []chatMessage `json:"messages"`
+}
+
+// anthropicResponse is the part of a messages response the bot reads
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// complete sends the system and user prompts and returns the model's answer. The messages API
+// has no JSON mode, the prompts ask for JSON and the answer is cut out by the callers.
+func (c *Claude) complete(ctx context.Context, system, prompt string, jsonOutput bool) (string, error) {
+	body := anthropicRequest{
+		Model:     c.model,
+		MaxTokens: anthropicMaxTokens,
+		System:    system,
+		Messages:  []chatMessage{{Role: "user", Content: prompt}},
+	}
+	header := http.Header{}
+	header.Set("x-api-key", c.apiKey)
+	header.Set("anthropic-version", anthropicVersion)
+
+	var result anthropicResponse
+	if err := postJSON(ctx, c.client, "Anthropic", c.url, header, body, &result, anthropicErrorMessage); err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", ErrEmptyResponse
+	}
+	return text.String(), nil
+}
+
+// anthropicErrorMessage reads the message of an Anthropic error response
+func anthropicErrorMessage(data []byte) string {
+	var result anthropicResponse
+	if json.Unmarshal(data, &result) != nil || result.Error == nil {
+		return ""
+	}
+	return result.Error.Message
+}
@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/example/engbot/internal/logging"
+)
+
+// maxRetries is how many times a request is repeated after a 429, a 5xx or a network error
+const maxRetries = 3
+
+// maxRetryDelay caps the wait before a retry, a longer Retry-After fails the request instead
+const maxRetryDelay = 30 * time.Second
+
+// retryBaseDelay is the first backoff, doubled on every retry
+var retryBaseDelay = time.Second
+
+// httpClient is shared by all the providers so they reuse connections
+var httpClient = &http.Client{Timeout: requestTimeout}
+
+// APIError is returned when a model API answers with an error status
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	status := fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message == "" {
+		return fmt.Sprintf("%s returned %s", e.Provider, status)
+	}
+	return fmt.Sprintf("%s returned %s: %s", e.Provider, status, e.Message)
+}
+
+// Temporary reports whether the request may succeed if repeated later
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// postJSON posts the body as JSON and decodes a successful response into out, see postPayload
+func postJSON(ctx context.Context, client *http.Client, provider, url string, header http.Header,
+	body, out any, errorMessage func(data []byte) string) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+	return postPayload(ctx, client, provider, url, header, payload, out, errorMessage)
+}
+
+// postPayload posts the payload, typed by the Content-Type in header, and decodes a successful
+// JSON response into out. Rate limits, server errors and network errors are retried with
+// exponential backoff, honouring Retry-After. An error status is returned as *APIError with
+// the text errorMessage finds in the response.
+func postPayload(ctx context.Context, client *http.Client, provider, url string, header http.Header,
+	payload []byte, out any, errorMessage func(data []byte) string) error {
+	for attempt := 0; ; attempt++ {
+		data, retryAfter, err := post(ctx, client, provider, url, header, payload)
+		if err == nil {
+			if err := json.Unmarshal(data, out); err != nil {
+				return fmt.Errorf("failed to parse %s response: %w", provider, err)
+			}
+			return nil
+		}
+
+		apiErr, isAPIErr := err.(*APIError)
+		if isAPIErr && apiErr.Message == "" {
+			apiErr.Message = errorMessage(data)
+		}
+		if ctx.Err() != nil || (isAPIErr && !apiErr.Temporary()) {
+			return err
+		}
+		if attempt == maxRetries {
+			return fmt.Errorf("still failing after %d retries: %w", maxRetries, err)
+		}
+
+		backoff := max(retryAfter, retryBaseDelay<<attempt)
+		if backoff > maxRetryDelay {
+			return err
+		}
+		logging.FromContext(ctx).Warn("model request failed, retrying", "provider", provider,
+			"attempt", attempt+1, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// post sends one request and returns the response body. An error status is returned as
+// *APIError together with the body and the server's Retry-After.
+func post(ctx context.Context, client *http.Client, provider, url string, header http.Header,
+	payload []byte) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to call %s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s response: %w", provider, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return data, retryAfter(resp.Header), &APIError{Provider: provider, StatusCode: resp.StatusCode}
+	}
+	return data, 0, nil
+}
+
+// retryAfter reads the Retry-After header given in seconds
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,118 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// DefaultOllamaModel is the model used when AI_MODEL is not set
+const DefaultOllamaModel = "llama3.1"
+
+// DefaultOllamaURL is the address of a local Ollama server
+const DefaultOllamaURL = "http://localhost:11434"
+
+// Ollama generates texts with a self-hosted Ollama server
+type Ollama struct {
+	model  string
+	url    string
+	client *http.Client
+}
+
+// NewOllama creates a client for the model on the server at baseURL, DefaultOllamaURL and
+// DefaultOllamaModel if they are empty
+func NewOllama(baseURL, model string) *Ollama {
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+	return &Ollama{
+		model:  model,
+		url:    strings.TrimSuffix(baseURL, "/") + "/api/chat",
+		client: httpClient,
+	}
+}
+
+// GenerateText implements Provider
+func (c *Ollama) GenerateText(ctx context.Context, words []string) (*Story, error) {
+	return generateText(ctx, c, words)
+}
+
+// Translate implements Provider
+func (c *Ollama) Translate(ctx context.Context, text string) (string, error) {
+	return translate(ctx, c, text)
+}
+
+// GenerateExamples implements Provider
+func (c *Ollama) GenerateExamples(ctx context.Context, word string, n int) ([]string, error) {
+	return generateExamples(ctx, c, word, n)
+}
+
+// DescribeWord implements Provider
+func (c *Ollama) DescribeWord(ctx context.Context, word, translation string) (*WordDetails, error) {
+	return describeWord(ctx, c, word, translation)
+}
+
+// Converse implements Provider
+func (c *Ollama) Converse(ctx context.Context, words []string, dialogue []Turn) (*Reply, error) {
+	return converse(ctx, c, words, dialogue)
+}
+
+// CheckSentence implements Provider
+func (c *Ollama) CheckSentence(ctx context.Context, word, translation, sentence string) (*SentenceCheck, error) {
+	return checkSentence(ctx, c, word, translation, sentence)
+}
+
+// DefineWord implements Provider
+func (c *Ollama) DefineWord(ctx context.Context, word, translation string) (string, error) {
+	return defineWord(ctx, c, word, translation)
+}
+
+// ollamaRequest is the body of a chat request
+type ollamaRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Format   string        `json:"format,omitempty"`
+}
+
+// ollamaResponse is the part of a chat response the bot reads
+type ollamaResponse struct {
+	Message chatMessage `json:"message"`
+	Error   string      `json:"error"`
+}
+
+// complete sends the system and user prompts and returns the model's answer
+func (c *Ollama) complete(ctx context.Context, system, prompt string, jsonOutput bool) (string, error) {
+	body := ollamaRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+	}
+	if jsonOutput {
+		body.Format = "json"
+	}
+
+	var result ollamaResponse
+	if err := postJSON(ctx, c.client, "Ollama", c.url, nil, body, &result, ollamaErrorMessage); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(result.Message.Content) == "" {
+		return "", ErrEmptyResponse
+	}
+	return result.Message.Content, nil
+}
+
+// ollamaErrorMessage reads the message of an Ollama error response
+func ollamaErrorMessage(data []byte) string {
+	var result ollamaResponse
+	if json.Unmarshal(data, &result) != nil {
+		return ""
+	}
+	return result.Error
+}
@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// DefaultOpenAIModel is the model used when AI_MODEL is not set
+const DefaultOpenAIModel = "gpt-4o-mini"
+
+// openAIURL is the OpenAI chat completions endpoint
+const openAIURL = "https://api.openai.com/v1/chat/completions"
+
+// ChatGPT generates texts with the OpenAI chat completions API
+type ChatGPT struct {
+	apiKey string
+	model  string
+	url    string
+	client *http.Client
+}
+
+// NewChatGPT creates a client for the model, DefaultOpenAIModel if model is empty
+func NewChatGPT(apiKey, model string) *ChatGPT {
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	return &ChatGPT{
+		apiKey: apiKey,
+		model:  model,
+		url:    openAIURL,
+		client: httpClient,
+	}
+}
+
+// GenerateText implements Provider
+func (c *ChatGPT) GenerateText(ctx context.Context, words []string) (*Story, error) {
+	return generateText(ctx, c, words)
+}
+
+// Translate implements Provider
+func (c *ChatGPT) Translate(ctx context.Context, text string) (string, error) {
+	return translate(ctx, c, text)
+}
+
+// GenerateExamples implements Provider
+func (c *ChatGPT) GenerateExamples(ctx context.Context, word string, n int) ([]string, error) {
+	return generateExamples(ctx, c, word, n)
+}
+
+// DescribeWord implements Provider
+func (c *ChatGPT) DescribeWord(ctx context.Context, word, translation string) (*WordDetails, error) {
+	return describeWord(ctx, c, word, translation)
+}
+
+// Converse implements Provider
+func (c *ChatGPT) Converse(ctx context.Context, words []string, dialogue []Turn) (*Reply, error) {
+	return converse(ctx, c, words, dialogue)
+}
+
+// CheckSentence implements Provider
+func (c *ChatGPT) CheckSentence(ctx context.Context, word, translation, sentence string) (*SentenceCheck, error) {
+	return checkSentence(ctx, c, word, translation, sentence)
+}
+
+// DefineWord implements Provider
+func (c *ChatGPT) DefineWord(ctx context.Context, word, translation string) (string, error) {
+	return defineWord(ctx, c, word, translation)
+}
+
+// chatMessage is a message of the chat completions API
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest is the body of a chat completions request
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+// responseFormat asks the model for a JSON object
+type responseFormat struct {
+	Type string `json:"type"`
+}
+
+// chatResponse is the part of a chat completions response the bot reads
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// complete sends the system and user prompts and returns the model's answer
+func (c *ChatGPT) complete(ctx context.Context, system, prompt string, jsonOutput bool) (string, error) {
+	body := chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+	}
+	if jsonOutput {
+		body.ResponseFormat = &responseFormat{Type: "json_object"}
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+c.apiKey)
+
+	var result chatResponse
+	if err := postJSON(ctx, c.client, "OpenAI", c.url, header, body, &result, openAIErrorMessage); err != nil {
+		return "", err
+	}
+	if len(result.Choices) == 0 || strings.TrimSpace(result.Choices[0].Message.Content) == "" {
+		return "", ErrEmptyResponse
+	}
+	return result.Choices[0].Message.Content, nil
+}
+
+// openAIErrorMessage reads the message of an OpenAI error response
+func openAIErrorMessage(data []byte) string {
+	var result chatResponse
+	if json.Unmarshal(data, &result) != nil || result.Error == nil {
+		return ""
+	}
+	return result.Error.Message
+}
@@ -0,0 +1,412 @@
+// Package ai generates learning material with language models
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Provider generates learning material with a language model
+type Provider interface {
+	// GenerateText writes a short English text for a learner that uses all the words,
+	// with its Russian translation
+	GenerateText(ctx context.Context, words []string) (*Story, error)
+	// Translate translates the English text into Russian
+	Translate(ctx context.Context, text string) (string, error)
+	// GenerateExamples writes n English sentences that use the word
+	GenerateExamples(ctx context.Context, word string, n int) ([]string, error)
+	// DescribeWord explains the English word with the given translation and writes examples
+	// of its use
+	DescribeWord(ctx context.Context, word, translation string) (*WordDetails, error)
+	// Converse answers the learner in a practice conversation meant to get them to use the
+	// words and tells which of the words the learner's last message used correctly. With no
+	// dialogue yet it opens the conversation.
+	Converse(ctx context.Context, words []string, dialogue []Turn) (*Reply, error)
+	// CheckSentence checks the grammar of a sentence the learner wrote with the word and
+	// whether the word is used in the meaning of the translation
+	CheckSentence(ctx context.Context, word, translation, sentence string) (*SentenceCheck, error)
+	// DefineWord writes a short English definition of the word in the meaning of the translation
+	// that doesn't use the word itself
+	DefineWord(ctx context.Context, word, translation string) (string, error)
+}
+
+// Names of the providers in AI_PROVIDER
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// Config selects the provider and its model
+type Config struct {
+	// Provider is one of the Provider* names, empty picks the first one configured:
+	// OpenAI with a key, then Anthropic with a key, then Ollama with a URL
+	Provider string
+	// Model overrides the provider's default model
+	Model           string
+	OpenAIAPIKey    string
+	AnthropicAPIKey string
+	OllamaURL       string
+}
+
+// New returns the configured provider, or nil if none is configured
+func New(cfg Config) (Provider, error) {
+	name := cfg.Provider
+	if name == "" {
+		switch {
+		case cfg.OpenAIAPIKey != "":
+			name = ProviderOpenAI
+		case cfg.AnthropicAPIKey != "":
+			name = ProviderAnthropic
+		case cfg.OllamaURL != "":
+			name = ProviderOllama
+		default:
+			return nil, nil
+		}
+	}
+
+	switch strings.ToLower(name) {
+	case ProviderOpenAI:
+		if cfg.OpenAIAPIKey == "" {
+			return nil, errors.New("OPENAI_API_KEY is required for the openai provider")
+		}
+		return NewChatGPT(cfg.OpenAIAPIKey, cfg.Model), nil
+	case ProviderAnthropic:
+		if cfg.AnthropicAPIKey == "" {
+			return nil, errors.New("ANTHROPIC_API_KEY is required for the anthropic provider")
+		}
+		return NewClaude(cfg.AnthropicAPIKey, cfg.Model), nil
+	case ProviderOllama:
+		return NewOllama(cfg.OllamaURL, cfg.Model), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q, use openai, anthropic or ollama", name)
+	}
+}
+
+// requestTimeout bounds a single generation
+const requestTimeout = time.Minute
+
+// maxResponseSize bounds the response body read from a model API
+const maxResponseSize = 1 << 20
+
+// ErrEmptyResponse is returned when the model answers with nothing usable
+var ErrEmptyResponse = errors.New("empty response from the model")
+
+// completer sends a system and a user prompt to a model and returns its answer.
+// With jsonOutput the answer is expected to be a JSON object.
+type completer interface {
+	complete(ctx context.Context, system, prompt string, jsonOutput bool) (string, error)
+}
+
+// Story is a short text for reading practice with its translation
+type Story struct {
+	Text        string `json:"text"`
+	Translation string `json:"translation"`
+}
+
+// WordDetails is what a model knows about a word
+type WordDetails struct {
+	// Description is a short explanation in Russian
+	Description string   `json:"description"`
+	Examples    []string `json:"examples"`
+	// VerbForms are the forms of an irregular verb as "go - went - gone", empty for other words
+	VerbForms string `json:"verb_forms"`
+	// Synonyms and Antonyms are English words close and opposite in this meaning, none if
+	// there are no common ones
+	Synonyms []string `json:"synonyms"`
+	Antonyms []string `json:"antonyms"`
+}
+
+// Turn is a message of a practice conversation
+type Turn struct {
+	Learner bool // written by the learner, otherwise by the model
+	Text    string
+}
+
+// Reply is the model's message in a practice conversation
+type Reply struct {
+	Text string `json:"reply"`
+	// Used are the words the learner's last message used correctly, as given to Converse
+	Used []string `json:"used"`
+	// Correction points out a mistake in the learner's last message in Russian, empty if
+	// there was none
+	Correction string `json:"correction"`
+}
+
+// SentenceCheck is what a model found in a sentence a learner wrote with a word
+type SentenceCheck struct {
+	// UsesWord reports whether the sentence has the word in some grammatical form
+	UsesWord bool `json:"uses_word"`
+	// RightMeaning reports whether the word is used correctly in the meaning asked
+	RightMeaning bool      `json:"right_meaning"`
+	Mistakes     []Mistake `json:"mistakes"`
+	// Corrected is the sentence with the mistakes fixed
+	Corrected string `json:"corrected"`
+}
+
+// Mistake is a mistake in a learner's sentence
+type Mistake struct {
+	Wrong       string `json:"wrong"` // the words as written
+	Right       string `json:"right"`
+	Explanation string `json:"explanation"` // in Russian
+}
+
+// maxRelatedWords bounds the synonyms and the antonyms kept for a word
+const maxRelatedWords = 3
+
+// systemPrompt sets up the model for all the requests
+const systemPrompt = "You write short, natural English texts for Russian-speaking learners of English."
+
+// generateText implements Provider.GenerateText on top of a model's completions
+func generateText(ctx context.Context, c completer, words []string) (*Story, error) {
+	if len(words) == 0 {
+		return nil, errors.New("no words for the text")
+	}
+
+	prompt := fmt.Sprintf("Write a coherent story of 80-150 words at B1 level that uses each of these words "+
+		"or phrases at least once: %s. Keep the words as given or in their grammatical forms. "+
+		"Answer with a JSON object with two string fields: \"text\" with the English text "+
+		"and \"translation\" with its Russian translation.", strings.Join(words, ", "))
+	content, err := c.complete(ctx, systemPrompt, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var story Story
+	if err := json.Unmarshal([]byte(jsonObject(content)), &story); err != nil {
+		return nil, fmt.Errorf("failed to parse story: %w", err)
+	}
+	story.Text = strings.TrimSpace(story.Text)
+	story.Translation = strings.TrimSpace(story.Translation)
+	if story.Text == "" {
+		return nil, ErrEmptyResponse
+	}
+	return &story, nil
+}
+
+// translate implements Provider.Translate on top of a model's completions
+func translate(ctx context.Context, c completer, text string) (string, error) {
+	prompt := "Translate this English text into Russian. Answer with the translation only.\n\n" + text
+	translation, err := c.complete(ctx, systemPrompt, prompt, false)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(translation), nil
+}
+
+// generateExamples implements Provider.GenerateExamples on top of a model's completions
+func generateExamples(ctx context.Context, c completer, word string, n int) ([]string, error) {
+	prompt := fmt.Sprintf("Write %d short, everyday English sentences at B1 level that use \"%s\". "+
+		"Answer with a JSON object with the field \"examples\": an array of the sentences.", n, word)
+	content, err := c.complete(ctx, systemPrompt, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Examples []string `json:"examples"`
+	}
+	if err := json.Unmarshal([]byte(jsonObject(content)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse examples: %w", err)
+	}
+	var examples []string
+	for _, e := range result.Examples {
+		if e = strings.TrimSpace(e); e != "" {
+			examples = append(examples, e)
+		}
+	}
+	if len(examples) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return examples[:min(n, len(examples))], nil
+}
+
+// describeWord implements Provider.DescribeWord on top of a model's completions
+func describeWord(ctx context.Context, c completer, word, translation string) (*WordDetails, error) {
+	prompt := fmt.Sprintf("Describe the English word or phrase \"%s\" in the meaning \"%s\". "+
+		"Answer with a JSON object with the fields: \"description\" - one short sentence in Russian "+
+		"explaining the meaning and usage, \"examples\" - an array of 2 short everyday English sentences "+
+		"at B1 level that use it, \"verb_forms\" - for an irregular verb its three forms as "+
+		"\"go - went - gone\", otherwise an empty string, \"synonyms\" and \"antonyms\" - arrays of up to "+
+		"%d common English words or phrases with the same and the opposite meaning, empty if there are none.",
+		word, translation, maxRelatedWords)
+	content, err := c.complete(ctx, systemPrompt, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var details WordDetails
+	if err := json.Unmarshal([]byte(jsonObject(content)), &details); err != nil {
+		return nil, fmt.Errorf("failed to parse word details: %w", err)
+	}
+	details.Description = strings.TrimSpace(details.Description)
+	details.VerbForms = strings.TrimSpace(details.VerbForms)
+	details.Examples = nonEmpty(details.Examples)
+	details.Synonyms = relatedWords(details.Synonyms, word)
+	details.Antonyms = relatedWords(details.Antonyms, word)
+	if details.Description == "" && len(details.Examples) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return &details, nil
+}
+
+// conversationPrompt sets up the model for the practice conversations
+const conversationPrompt = "You are a friendly English conversation partner of a Russian-speaking learner. " +
+	"You keep the conversation going with short, natural messages at B1 level and give the learner " +
+	"chances to use the words they are studying without naming the words outright."
+
+// converse implements Provider.Converse on top of a model's completions. The dialogue goes
+// into the prompt, so the providers need no multi-turn support.
+func converse(ctx context.Context, c completer, words []string, dialogue []Turn) (*Reply, error) {
+	if len(words) == 0 {
+		return nil, errors.New("no words for the conversation")
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "The learner is practicing these words and phrases: %s.\n\n", strings.Join(words, ", "))
+	if len(dialogue) == 0 {
+		prompt.WriteString("Open the conversation with a question on an everyday topic that invites " +
+			"the learner to use some of the words. Leave \"used\" empty and \"correction\" blank.")
+	} else {
+		prompt.WriteString("The conversation so far:\n")
+		for _, turn := range dialogue {
+			speaker := "You"
+			if turn.Learner {
+				speaker = "Learner"
+			}
+			fmt.Fprintf(&prompt, "%s: %s\n", speaker, turn.Text)
+		}
+		prompt.WriteString("\nReply to the learner's last message in 1-3 sentences and steer the conversation " +
+			"towards the words not used yet. In \"used\" list the practiced words the learner's last " +
+			"message used correctly in meaning and grammar, in any grammatical form, exactly as given above. " +
+			"In \"correction\" briefly explain in Russian a mistake in the learner's last message, " +
+			"or leave it blank if there is none.")
+	}
+	prompt.WriteString(" Answer with a JSON object with the fields \"reply\" (string), \"used\" " +
+		"(array of strings) and \"correction\" (string).")
+
+	content, err := c.complete(ctx, conversationPrompt, prompt.String(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply Reply
+	if err := json.Unmarshal([]byte(jsonObject(content)), &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse reply: %w", err)
+	}
+	reply.Text = strings.TrimSpace(reply.Text)
+	reply.Correction = strings.TrimSpace(reply.Correction)
+	if reply.Text == "" {
+		return nil, ErrEmptyResponse
+	}
+	// Models echo the words in their own spelling and sometimes add ones that weren't asked
+	var used []string
+	for _, u := range nonEmpty(reply.Used) {
+		i := slices.IndexFunc(words, func(w string) bool { return strings.EqualFold(w, u) })
+		if i >= 0 && !slices.Contains(used, words[i]) {
+			used = append(used, words[i])
+		}
+	}
+	reply.Used = used
+	return &reply, nil
+}
+
+// checkSentence implements Provider.CheckSentence on top of a model's completions
+func checkSentence(ctx context.Context, c completer, word, translation, sentence string) (*SentenceCheck, error) {
+	prompt := fmt.Sprintf("A learner was asked to write an English sentence with \"%s\" in the meaning \"%s\" "+
+		"and wrote:\n\n%s\n\nCheck the sentence. Answer with a JSON object with the fields: \"uses_word\" - "+
+		"whether the sentence has the word or phrase in any grammatical form, \"right_meaning\" - whether it is "+
+		"used correctly in that meaning, \"mistakes\" - an array of the grammar, spelling and word usage mistakes, "+
+		"each an object with \"wrong\" - the words as written, \"right\" - the correct words and \"explanation\" - "+
+		"a short explanation in Russian, empty if there are none, \"corrected\" - the whole sentence with "+
+		"the mistakes fixed. Don't count style preferences and missing final punctuation as mistakes.",
+		word, translation, sentence)
+	content, err := c.complete(ctx, systemPrompt, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var check SentenceCheck
+	if err := json.Unmarshal([]byte(jsonObject(content)), &check); err != nil {
+		return nil, fmt.Errorf("failed to parse sentence check: %w", err)
+	}
+	var mistakes []Mistake
+	for _, m := range check.Mistakes {
+		m.Wrong, m.Right, m.Explanation = strings.TrimSpace(m.Wrong), strings.TrimSpace(m.Right), strings.TrimSpace(m.Explanation)
+		if m.Wrong != "" || m.Right != "" {
+			mistakes = append(mistakes, m)
+		}
+	}
+	check.Mistakes = mistakes
+	check.Corrected = strings.TrimSpace(check.Corrected)
+	if check.Corrected == "" {
+		check.Corrected = strings.TrimSpace(sentence)
+	}
+	return &check, nil
+}
+
+// defineWord implements Provider.DefineWord on top of a model's completions
+func defineWord(ctx context.Context, c completer, word, translation string) (string, error) {
+	prompt := fmt.Sprintf("Write a short definition of the English word or phrase \"%s\" in the meaning \"%s\" "+
+		"as in a learner's dictionary: one sentence in simple English at B1 level that doesn't use the word "+
+		"itself or words with the same root. Answer with a JSON object with the field \"definition\".",
+		word, translation)
+	content, err := c.complete(ctx, systemPrompt, prompt, true)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Definition string `json:"definition"`
+	}
+	if err := json.Unmarshal([]byte(jsonObject(content)), &result); err != nil {
+		return "", fmt.Errorf("failed to parse definition: %w", err)
+	}
+	definition := strings.TrimSpace(result.Definition)
+	if definition == "" {
+		return "", ErrEmptyResponse
+	}
+	return definition, nil
+}
+
+// nonEmpty returns the trimmed strings that aren't blank
+func nonEmpty(items []string) []string {
+	var result []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// relatedWords keeps up to maxRelatedWords distinct synonyms or antonyms other than the word itself
+func relatedWords(items []string, word string) []string {
+	var result []string
+	for _, item := range nonEmpty(items) {
+		if len(result) == maxRelatedWords {
+			break
+		}
+		// The words are stored comma-separated
+		if strings.Contains(item, ",") || strings.EqualFold(item, word) || slices.ContainsFunc(result, func(r string) bool { return strings.EqualFold(r, item) }) {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// jsonObject cuts the JSON object out of an answer, dropping the Markdown fences and the
+// words around it that models without a JSON mode add
+func jsonObject(s string) string {
+	start, end := strings.Index(s, "{"), strings.LastIndex(s, "}")
+	if start < 0 || end < start {
+		return s
+	}
+	return s[start : end+1]
+}
@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// DefaultWhisperModel is the speech recognition model
+const DefaultWhisperModel = "whisper-1"
+
+// openAITranscriptionURL is the OpenAI transcriptions endpoint
+const openAITranscriptionURL = "https://api.openai.com/v1/audio/transcriptions"
+
+// Transcriber turns recorded English speech into text
+type Transcriber interface {
+	// Transcribe returns what is said in the audio file. The name's extension tells the format,
+	// Telegram voice messages are .ogg (Opus).
+	Transcribe(ctx context.Context, audio []byte, name string) (string, error)
+}
+
+// Whisper recognizes speech with the OpenAI transcriptions API
+type Whisper struct {
+	apiKey string
+	model  string
+	url    string
+	client *http.Client
+}
+
+// NewWhisper creates a transcriber for the model, DefaultWhisperModel if model is empty
+func NewWhisper(apiKey, model string) *Whisper {
+	if model == "" {
+		model = DefaultWhisperModel
+	}
+	return &Whisper{
+		apiKey: apiKey,
+		model:  model,
+		url:    openAITranscriptionURL,
+		client: httpClient,
+	}
+}
+
+// Transcribe implements Transcriber. The speech is taken as English, so a learner's accent
+// is not mistaken for another language.
+func (w *Whisper) Transcribe(ctx context.Context, audio []byte, name string) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	if _, err := file.Write(audio); err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	for field, value := range map[string]string{"model": w.model, "language": "en", "response_format": "json"} {
+		if err := form.WriteField(field, value); err != nil {
+			return "", fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+w.apiKey)
+	header.Set("Content-Type", form.FormDataContentType())
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := postPayload(ctx, w.client, "OpenAI", w.url, header, body.Bytes(), &result, openAIErrorMessage); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Text), nil
+}
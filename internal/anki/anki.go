@@ -0,0 +1,145 @@
+// Package anki reads Anki exports (.apkg packages and "Notes in Plain Text" files) into
+// words and decks, and writes words back as decks Anki can import.
+package anki
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Note is an Anki note reduced to the word fields the bot stores. Deck is empty for notes
+// from Anki's catch-all "Default" deck.
+type Note struct {
+	Deck        string
+	Word        string
+	Translation string
+	Description string
+	Examples    string
+}
+
+// FieldMapping tells which Anki note field holds which word field. Each value is a field
+// name as shown in Anki (case-insensitive) or a 1-based field number; empty skips the field.
+type FieldMapping struct {
+	Word        string
+	Translation string
+	Description string
+	Examples    string
+}
+
+// DefaultFieldMapping reads the word from the first field and the translation from the second,
+// which matches Anki's Basic note type (Front/Back)
+func DefaultFieldMapping() FieldMapping {
+	return FieldMapping{Word: "1", Translation: "2"}
+}
+
+// ParseFieldMapping parses a mapping like "word=Front,translation=Back,examples=3".
+// Word fields that aren't mentioned keep their defaults.
+func ParseFieldMapping(s string) (FieldMapping, error) {
+	mapping := DefaultFieldMapping()
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return mapping, fmt.Errorf("invalid field mapping %q: expected field=anki_field", pair)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "word":
+			mapping.Word = value
+		case "translation":
+			mapping.Translation = value
+		case "description":
+			mapping.Description = value
+		case "examples":
+			mapping.Examples = value
+		default:
+			return mapping, fmt.Errorf("unknown word field %q in field mapping", key)
+		}
+	}
+	if mapping.Word == "" || mapping.Translation == "" {
+		return mapping, fmt.Errorf("field mapping must name the word and translation fields")
+	}
+	return mapping, nil
+}
+
+// String formats the mapping the way ParseFieldMapping reads it
+func (m FieldMapping) String() string {
+	parts := []string{"word=" + m.Word, "translation=" + m.Translation}
+	if m.Description != "" {
+		parts = append(parts, "description="+m.Description)
+	}
+	if m.Examples != "" {
+		parts = append(parts, "examples="+m.Examples)
+	}
+	return strings.Join(parts, ",")
+}
+
+// note builds a note from the field values of one Anki note. names are the field names of its
+// note type, if known. Notes without a word or translation are reported as not ok.
+func (m FieldMapping) note(deck string, names, values []string, isHTML bool) (Note, bool) {
+	note := Note{
+		Deck:        deckName(deck),
+		Word:        cleanField(pickField(m.Word, names, values), isHTML),
+		Translation: cleanField(pickField(m.Translation, names, values), isHTML),
+		Description: cleanField(pickField(m.Description, names, values), isHTML),
+		Examples:    cleanField(pickField(m.Examples, names, values), isHTML),
+	}
+	return note, note.Word != "" && note.Translation != ""
+}
+
+// pickField returns the value of the field selected by name or 1-based number
+func pickField(selector string, names, values []string) string {
+	if selector == "" {
+		return ""
+	}
+	for i, name := range names {
+		if strings.EqualFold(strings.TrimSpace(name), selector) && i < len(values) {
+			return values[i]
+		}
+	}
+	if n, err := strconv.Atoi(selector); err == nil && n >= 1 && n <= len(values) {
+		return values[n-1]
+	}
+	return ""
+}
+
+var (
+	lineBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</div>|</p>|</li>`)
+	tagPattern       = regexp.MustCompile(`<[^>]*>`)
+	soundPattern     = regexp.MustCompile(`\[sound:[^\]]*\]`)
+	spacePattern     = regexp.MustCompile(`[ \t\x{00a0}]+`)
+	newlinesPattern  = regexp.MustCompile(`\s*\n\s*`)
+)
+
+// cleanField turns an Anki field into plain text: HTML is stripped, media references dropped
+func cleanField(s string, isHTML bool) string {
+	s = soundPattern.ReplaceAllString(s, "")
+	if isHTML {
+		s = lineBreakPattern.ReplaceAllString(s, "\n")
+		s = tagPattern.ReplaceAllString(s, "")
+		s = html.UnescapeString(s)
+	}
+	s = spacePattern.ReplaceAllString(s, " ")
+	s = newlinesPattern.ReplaceAllString(s, "\n")
+	return strings.TrimSpace(s)
+}
+
+// htmlField prepares plain text for an Anki field, which Anki renders as HTML
+func htmlField(s string) string {
+	return strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+}
+
+// deckName returns the deck's name, or "" for Anki's catch-all deck
+func deckName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "Default" {
+		return ""
+	}
+	return name
+}
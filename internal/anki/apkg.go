@@ -0,0 +1,455 @@
+package anki
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// maxCollectionSize bounds the unpacked collection database
+const maxCollectionSize = 200 << 20
+
+// Collection files inside an .apkg. collection.anki21b is zstd-compressed and not supported;
+// packages that have it carry only a placeholder "please update Anki" collection.anki2.
+const (
+	collectionAnki21  = "collection.anki21"
+	collectionAnki2   = "collection.anki2"
+	collectionAnki21b = "collection.anki21b"
+)
+
+// ErrNewPackageFormat is returned for packages exported without "Support older Anki versions"
+var ErrNewPackageFormat = errors.New("the package uses the new Anki format")
+
+// ErrNotPackage is returned for files that aren't Anki packages
+var ErrNotPackage = errors.New("not an Anki package")
+
+// noteType is an Anki note type with its field names in order
+type noteType struct {
+	Name   string `json:"name"`
+	Fields []struct {
+		Name string `json:"name"`
+		Ord  int    `json:"ord"`
+	} `json:"flds"`
+}
+
+// ReadPackage reads the notes of an .apkg package
+func ReadPackage(r io.ReaderAt, size int64, mapping FieldMapping) ([]Note, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotPackage, err)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	collection := files[collectionAnki21]
+	if collection == nil && files[collectionAnki21b] != nil {
+		return nil, ErrNewPackageFormat
+	}
+	if collection == nil {
+		collection = files[collectionAnki2]
+	}
+	if collection == nil {
+		return nil, fmt.Errorf("%w: no collection inside", ErrNotPackage)
+	}
+
+	path, err := unpackCollection(collection)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open collection: %w", err)
+	}
+	defer db.Close()
+
+	return readNotes(db, mapping)
+}
+
+// unpackCollection copies the collection database to a temporary file for SQLite to open
+func unpackCollection(f *zip.File) (string, error) {
+	if f.UncompressedSize64 > maxCollectionSize {
+		return "", fmt.Errorf("collection is too large: %d bytes", f.UncompressedSize64)
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNotPackage, err)
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "anki-*.db")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	_, copyErr := io.Copy(dst, io.LimitReader(src, maxCollectionSize))
+	closeErr := dst.Close()
+	if err := errors.Join(copyErr, closeErr); err != nil {
+		os.Remove(dst.Name())
+		return "", fmt.Errorf("failed to unpack collection: %w", err)
+	}
+	return dst.Name(), nil
+}
+
+// readNotes reads every note of the collection with the deck of its first card
+func readNotes(db *sql.DB, mapping FieldMapping) ([]Note, error) {
+	noteTypes, decks, err := readCollectionInfo(db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT n.mid, n.flds,
+			COALESCE((SELECT c.did FROM cards c WHERE c.nid = n.id ORDER BY c.ord LIMIT 1), 0)
+		FROM notes n
+		ORDER BY n.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read notes: %v", ErrNotPackage, err)
+	}
+	defer rows.Close()
+
+	var notes []Note
+	for rows.Next() {
+		var mid, did int64
+		var fields string
+		if err := rows.Scan(&mid, &fields, &did); err != nil {
+			return nil, fmt.Errorf("failed to read note: %w", err)
+		}
+		if note, ok := mapping.note(decks[did], noteTypes[mid], strings.Split(fields, "\x1f"), true); ok {
+			notes = append(notes, note)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+	return notes, nil
+}
+
+// readCollectionInfo returns the field names of each note type and the deck names. Collections
+// up to schema 11 keep them as JSON in the col table, later ones in separate tables.
+func readCollectionInfo(db *sql.DB) (map[int64][]string, map[int64]string, error) {
+	var modelsJSON, decksJSON string
+	if err := db.QueryRow("SELECT models, decks FROM col").Scan(&modelsJSON, &decksJSON); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrNotPackage, err)
+	}
+
+	noteTypes := make(map[int64][]string)
+	decks := make(map[int64]string)
+	if strings.TrimSpace(modelsJSON) != "" && strings.TrimSpace(modelsJSON) != "{}" {
+		var models map[string]noteType
+		if err := json.Unmarshal([]byte(modelsJSON), &models); err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid note types: %v", ErrNotPackage, err)
+		}
+		for id, model := range models {
+			mid, _ := strconv.ParseInt(id, 10, 64)
+			names := make([]string, len(model.Fields))
+			for _, f := range model.Fields {
+				if f.Ord >= 0 && f.Ord < len(names) {
+					names[f.Ord] = f.Name
+				}
+			}
+			noteTypes[mid] = names
+		}
+
+		var deckMap map[string]struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(decksJSON), &deckMap); err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid decks: %v", ErrNotPackage, err)
+		}
+		for id, deck := range deckMap {
+			did, _ := strconv.ParseInt(id, 10, 64)
+			decks[did] = deck.Name
+		}
+		return noteTypes, decks, nil
+	}
+
+	fieldRows, err := db.Query("SELECT ntid, name FROM fields ORDER BY ntid, ord")
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to read note types: %v", ErrNotPackage, err)
+	}
+	defer fieldRows.Close()
+	for fieldRows.Next() {
+		var ntid int64
+		var name string
+		if err := fieldRows.Scan(&ntid, &name); err != nil {
+			return nil, nil, fmt.Errorf("failed to read note type field: %w", err)
+		}
+		noteTypes[ntid] = append(noteTypes[ntid], name)
+	}
+
+	deckRows, err := db.Query("SELECT id, name FROM decks")
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to read decks: %v", ErrNotPackage, err)
+	}
+	defer deckRows.Close()
+	for deckRows.Next() {
+		var did int64
+		var name string
+		if err := deckRows.Scan(&did, &name); err != nil {
+			return nil, nil, fmt.Errorf("failed to read deck: %w", err)
+		}
+		// Вложенные колоды хранятся через \x1f, в интерфейсе Anki они пишутся через ::
+		decks[did] = strings.ReplaceAll(name, "\x1f", "::")
+	}
+	return noteTypes, decks, nil
+}
+
+// Fixed IDs of the exported note type and Anki's catch-all deck. Keeping the note type ID
+// stable lets Anki update notes from an earlier export instead of duplicating the note type.
+const (
+	exportNoteTypeID = 1700000000001
+	defaultDeckID    = 1
+)
+
+// exportFields are the fields of the exported note type, in order
+var exportFields = []string{"Word", "Translation", "Description", "Examples"}
+
+// WritePackage writes the notes as an .apkg package. Notes without a deck go to defaultDeck.
+func WritePackage(w io.Writer, notes []Note, defaultDeck string, now time.Time) error {
+	dbFile, err := os.CreateTemp("", "anki-export-*.db")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path := dbFile.Name()
+	dbFile.Close()
+	defer os.Remove(path)
+
+	if err := writeCollection(path, notes, defaultDeck, now); err != nil {
+		return err
+	}
+
+	collection, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read collection: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+	fw, err := zw.Create(collectionAnki2)
+	if err != nil {
+		return fmt.Errorf("failed to write package: %w", err)
+	}
+	if _, err := fw.Write(collection); err != nil {
+		return fmt.Errorf("failed to write package: %w", err)
+	}
+	// Экспортируем только текст, поэтому список медиафайлов пуст
+	fw, err = zw.Create("media")
+	if err != nil {
+		return fmt.Errorf("failed to write package: %w", err)
+	}
+	if _, err := io.WriteString(fw, "{}"); err != nil {
+		return fmt.Errorf("failed to write package: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write package: %w", err)
+	}
+	return nil
+}
+
+// collectionSchema is the schema 11 layout every Anki version can import
+const collectionSchema = `
+CREATE TABLE col (
+	id integer primary key, crt integer not null, mod integer not null, scm integer not null,
+	ver integer not null, dty integer not null, usn integer not null, ls integer not null,
+	conf text not null, models text not null, decks text not null, dconf text not null, tags text not null
+);
+CREATE TABLE notes (
+	id integer primary key, guid text not null, mid integer not null, mod integer not null,
+	usn integer not null, tags text not null, flds text not null, sfld integer not null,
+	csum integer not null, flags integer not null, data text not null
+);
+CREATE TABLE cards (
+	id integer primary key, nid integer not null, did integer not null, ord integer not null,
+	mod integer not null, usn integer not null, type integer not null, queue integer not null,
+	due integer not null, ivl integer not null, factor integer not null, reps integer not null,
+	lapses integer not null, left integer not null, odue integer not null, odid integer not null,
+	flags integer not null, data text not null
+);
+CREATE TABLE revlog (
+	id integer primary key, cid integer not null, usn integer not null, ease integer not null,
+	ivl integer not null, lastIvl integer not null, factor integer not null, time integer not null,
+	type integer not null
+);
+CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null);
+CREATE INDEX ix_notes_usn ON notes (usn);
+CREATE INDEX ix_cards_usn ON cards (usn);
+CREATE INDEX ix_revlog_usn ON revlog (usn);
+CREATE INDEX ix_cards_nid ON cards (nid);
+CREATE INDEX ix_cards_sched ON cards (did, queue, due);
+CREATE INDEX ix_revlog_cid ON revlog (cid);
+CREATE INDEX ix_notes_csum ON notes (csum);
+`
+
+// writeCollection creates the collection database with one new card per note
+func writeCollection(path string, notes []Note, defaultDeck string, now time.Time) error {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(collectionSchema); err != nil {
+		return fmt.Errorf("failed to create collection schema: %w", err)
+	}
+
+	deckIDs := map[string]int64{}
+	decks := map[string]any{strconv.Itoa(defaultDeckID): deckJSON(defaultDeckID, "Default", now)}
+	for _, note := range notes {
+		name := note.Deck
+		if name == "" {
+			name = defaultDeck
+		}
+		if _, ok := deckIDs[name]; !ok {
+			id := deckID(name)
+			deckIDs[name] = id
+			decks[strconv.FormatInt(id, 10)] = deckJSON(id, name, now)
+		}
+	}
+
+	conf, _ := json.Marshal(map[string]any{
+		"activeDecks": []int{defaultDeckID}, "curDeck": defaultDeckID, "newSpread": 0, "collapseTime": 1200,
+		"timeLim": 0, "estTimes": true, "dueCounts": true, "curModel": nil, "nextPos": len(notes) + 1,
+		"sortType": "noteFld", "sortBackwards": false, "addToCur": true,
+	})
+	models, _ := json.Marshal(map[string]any{strconv.Itoa(exportNoteTypeID): noteTypeJSON(now)})
+	decksJSON, _ := json.Marshal(decks)
+	dconf, _ := json.Marshal(map[string]any{"1": deckConfigJSON()})
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')`,
+		now.Truncate(24*time.Hour).Unix(), now.UnixMilli(), now.UnixMilli(),
+		string(conf), string(models), string(decksJSON), string(dconf))
+	if err != nil {
+		return fmt.Errorf("failed to write collection: %w", err)
+	}
+
+	for i, note := range notes {
+		name := note.Deck
+		if name == "" {
+			name = defaultDeck
+		}
+		fields := []string{htmlField(note.Word), htmlField(note.Translation), htmlField(note.Description), htmlField(note.Examples)}
+		id := now.UnixMilli() + int64(i)
+
+		_, err := tx.Exec(`INSERT INTO notes VALUES (?, ?, ?, ?, -1, '', ?, ?, ?, 0, '')`,
+			id, noteGUID(name, note.Word), exportNoteTypeID, now.Unix(),
+			strings.Join(fields, "\x1f"), note.Word, fieldChecksum(note.Word))
+		if err != nil {
+			return fmt.Errorf("failed to write note: %w", err)
+		}
+		_, err = tx.Exec(`INSERT INTO cards VALUES (?, ?, ?, 0, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')`,
+			id, id, deckIDs[name], now.Unix(), i+1)
+		if err != nil {
+			return fmt.Errorf("failed to write card: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit collection: %w", err)
+	}
+	return nil
+}
+
+// noteTypeJSON describes the exported note type: the word on the front, the rest on the back
+func noteTypeJSON(now time.Time) map[string]any {
+	fields := make([]map[string]any, len(exportFields))
+	for i, name := range exportFields {
+		fields[i] = map[string]any{
+			"name": name, "ord": i, "font": "Arial", "size": 20, "media": []any{}, "rtl": false, "sticky": false,
+		}
+	}
+	return map[string]any{
+		"id":        exportNoteTypeID,
+		"name":      "English Bot",
+		"type":      0,
+		"mod":       now.Unix(),
+		"usn":       -1,
+		"sortf":     0,
+		"did":       defaultDeckID,
+		"flds":      fields,
+		"tags":      []any{},
+		"vers":      []any{},
+		"req":       []any{[]any{0, "any", []int{0}}},
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"css":       ".card { font-family: arial; font-size: 20px; text-align: center; color: black; background-color: white; }",
+		"tmpls": []map[string]any{{
+			"name":  "Card 1",
+			"ord":   0,
+			"qfmt":  "{{Word}}",
+			"afmt":  "{{FrontSide}}<hr id=answer>{{Translation}}{{#Description}}<br><br>{{Description}}{{/Description}}{{#Examples}}<br><br><i>{{Examples}}</i>{{/Examples}}",
+			"bqfmt": "",
+			"bafmt": "",
+			"did":   nil,
+		}},
+	}
+}
+
+// deckJSON describes a deck of the exported collection
+func deckJSON(id int64, name string, now time.Time) map[string]any {
+	return map[string]any{
+		"id": id, "name": name, "desc": "", "mod": now.Unix(), "usn": -1, "dyn": 0, "conf": 1,
+		"collapsed": false, "browserCollapsed": true, "extendNew": 10, "extendRev": 50,
+		"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+	}
+}
+
+// deckConfigJSON is Anki's default deck options group
+func deckConfigJSON() map[string]any {
+	return map[string]any{
+		"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true, "timer": 0, "replayq": true,
+		"new": map[string]any{
+			"bury": true, "delays": []int{1, 10}, "initialFactor": 2500, "ints": []int{1, 4, 7},
+			"order": 1, "perDay": 20, "separate": true,
+		},
+		"rev": map[string]any{
+			"bury": true, "ease4": 1.3, "fuzz": 0.05, "ivlFct": 1, "maxIvl": 36500, "minSpace": 1, "perDay": 100,
+		},
+		"lapse": map[string]any{
+			"delays": []int{10}, "leechAction": 0, "leechFails": 8, "minInt": 1, "mult": 0,
+		},
+	}
+}
+
+// deckID derives a stable deck ID from the name, so repeated exports land in the same deck.
+// IDs stay below 2^53 because Anki handles them in JavaScript too.
+func deckID(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64()%(1<<50)) + 1<<40
+}
+
+// noteGUID derives a stable note GUID, so Anki updates a word exported again instead of duplicating it
+func noteGUID(deck, word string) string {
+	sum := sha256.Sum256([]byte(deck + "\x1f" + word))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// fieldChecksum is Anki's duplicate check value: the first 8 hex digits of the SHA-1 of the field
+func fieldChecksum(field string) int64 {
+	sum := sha1.Sum([]byte(field))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
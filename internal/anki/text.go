@@ -0,0 +1,198 @@
+package anki
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxTextSize bounds a plain text export
+const maxTextSize = 20 << 20
+
+// ErrNotText is returned for files that aren't tab or comma separated text
+var ErrNotText = errors.New("not an Anki text export")
+
+// textHeader holds the "#key:value" lines Anki writes at the top of a plain text export
+type textHeader struct {
+	separator  rune
+	html       bool
+	deck       string
+	deckColumn int // 1-based, 0 if absent
+	skip       map[int]bool
+	columns    []string
+}
+
+// ReadText reads a "Notes in Plain Text" export or a plain word list with one note per line.
+// Without a #separator header, tabs, semicolons and commas are tried in that order.
+func ReadText(r io.Reader, mapping FieldMapping) ([]Note, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxTextSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > maxTextSize {
+		return nil, fmt.Errorf("file is too large")
+	}
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("%w: the file is not UTF-8 text", ErrNotText)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	header, body, err := parseTextHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.Comma = header.separator
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	var names []string
+	for i, name := range header.columns {
+		if !header.skip[i+1] {
+			names = append(names, name)
+		}
+	}
+
+	var notes []Note
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotText, err)
+		}
+
+		deck := header.deck
+		var values []string
+		for i, value := range record {
+			switch {
+			case i+1 == header.deckColumn:
+				deck = value
+			case !header.skip[i+1]:
+				values = append(values, value)
+			}
+		}
+		if note, ok := mapping.note(deck, names, values, header.html); ok {
+			notes = append(notes, note)
+		}
+	}
+	return notes, nil
+}
+
+// parseTextHeader reads the leading "#key:value" lines and returns them with the rest of the file
+func parseTextHeader(data []byte) (*textHeader, []byte, error) {
+	header := &textHeader{skip: make(map[int]bool)}
+	var columns string
+
+	rest := data
+	for len(rest) > 0 && rest[0] == '#' {
+		line, next, _ := bytes.Cut(rest, []byte("\n"))
+		rest = next
+
+		key, value, ok := strings.Cut(strings.TrimSpace(string(line[1:])), ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "separator":
+			sep, err := parseSeparator(value)
+			if err != nil {
+				return nil, nil, err
+			}
+			header.separator = sep
+		case "html":
+			header.html = strings.EqualFold(strings.TrimSpace(value), "true")
+		case "deck":
+			header.deck = strings.TrimSpace(value)
+		case "deck column":
+			header.deckColumn, _ = strconv.Atoi(strings.TrimSpace(value))
+			header.skip[header.deckColumn] = true
+		case "notetype column", "tags column", "guid column":
+			if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				header.skip[n] = true
+			}
+		case "columns":
+			columns = value
+		}
+	}
+
+	if header.separator == 0 {
+		header.separator = detectSeparator(rest)
+	}
+	if columns != "" {
+		header.columns = strings.Split(columns, string(header.separator))
+	}
+	return header, rest, nil
+}
+
+// parseSeparator understands the separator names Anki writes as well as a single character
+func parseSeparator(value string) (rune, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "tab":
+		return '\t', nil
+	case "comma":
+		return ',', nil
+	case "semicolon":
+		return ';', nil
+	case "space":
+		return ' ', nil
+	case "pipe":
+		return '|', nil
+	case "colon":
+		return ':', nil
+	}
+	if r, size := utf8.DecodeRuneInString(value); size > 0 && size == len(value) {
+		return r, nil
+	}
+	return 0, fmt.Errorf("%w: unknown separator %q", ErrNotText, value)
+}
+
+// detectSeparator guesses the separator from the first line
+func detectSeparator(body []byte) rune {
+	line, _, _ := bytes.Cut(body, []byte("\n"))
+	for _, sep := range []rune{'\t', ';', ','} {
+		if bytes.ContainsRune(line, sep) {
+			return sep
+		}
+	}
+	return '\t'
+}
+
+// WriteText writes the notes as a tab-separated file with the headers Anki 2.1.55+ reads,
+// one deck per note. Notes without a deck go to defaultDeck.
+func WriteText(w io.Writer, notes []Note, defaultDeck string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("#separator:tab\n")
+	bw.WriteString("#html:true\n")
+	bw.WriteString("#deck column:1\n")
+	bw.WriteString("#columns:Deck\t" + strings.Join(exportFields, "\t") + "\n")
+
+	cw := csv.NewWriter(bw)
+	cw.Comma = '\t'
+	for _, note := range notes {
+		deck := note.Deck
+		if deck == "" {
+			deck = defaultDeck
+		}
+		record := []string{deck, htmlField(note.Word), htmlField(note.Translation), htmlField(note.Description), htmlField(note.Examples)}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write note: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+	return nil
+}
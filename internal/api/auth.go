@@ -0,0 +1,134 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loginMaxAge is how old the data of the Telegram Login Widget may be, so a leaked login link
+// stops working soon
+const loginMaxAge = 24 * time.Hour
+
+var (
+	// errBadLogin is returned for login data not signed by Telegram for this bot
+	errBadLogin = errors.New("invalid Telegram login data")
+	// errBadInitData is returned for Mini App data not signed by Telegram for this bot
+	errBadInitData = errors.New("invalid Telegram Mini App data")
+	// errBadSession is returned for a session token that is forged, damaged or expired
+	errBadSession = errors.New("invalid or expired session")
+)
+
+// login is the user the Telegram Login Widget has vouched for
+type login struct {
+	TelegramID int64
+	Username   string
+	FirstName  string
+	LastName   string
+}
+
+// verifyLogin checks the fields the Telegram Login Widget passes to the page: their hash is the
+// HMAC-SHA-256 of the other fields, sorted and joined as key=value lines, keyed with the SHA-256
+// of the bot token. See https://core.telegram.org/widgets/login#checking-authorization.
+func verifyLogin(fields map[string]string, botToken string, now time.Time) (*login, error) {
+	hash, err := hex.DecodeString(fields["hash"])
+	if err != nil || len(hash) == 0 {
+		return nil, errBadLogin
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key + "=" + fields[key]
+	}
+
+	secret := sha256.Sum256([]byte(botToken))
+	if !hmac.Equal(hash, hmacSHA256(secret[:], strings.Join(lines, "\n"))) {
+		return nil, errBadLogin
+	}
+
+	authDate, err := strconv.ParseInt(fields["auth_date"], 10, 64)
+	if err != nil || now.Sub(time.Unix(authDate, 0)) > loginMaxAge {
+		return nil, fmt.Errorf("%w: the login has expired, log in again", errBadLogin)
+	}
+	id, err := strconv.ParseInt(fields["id"], 10, 64)
+	if err != nil {
+		return nil, errBadLogin
+	}
+	return &login{
+		TelegramID: id,
+		Username:   fields["username"],
+		FirstName:  fields["first_name"],
+		LastName:   fields["last_name"],
+	}, nil
+}
+
+// sessions issues and checks the bearer tokens of logged in users. A token is the Telegram ID
+// and the expiry time, signed with a key derived from the bot token, so no sessions are stored
+// and changing the bot token logs everyone out.
+type sessions struct {
+	key []byte
+	ttl time.Duration
+}
+
+// newSessions creates tokens that are valid for ttl
+func newSessions(botToken string, ttl time.Duration) *sessions {
+	return &sessions{key: hmacSHA256([]byte(botToken), "engbot api session"), ttl: ttl}
+}
+
+// issue returns a token for the user and the time it expires
+func (s *sessions) issue(telegramID int64, now time.Time) (string, time.Time) {
+	expires := now.Add(s.ttl).Truncate(time.Second)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", telegramID, expires.Unix())))
+	signature := base64.RawURLEncoding.EncodeToString(hmacSHA256(s.key, payload))
+	return payload + "." + signature, expires
+}
+
+// parse returns the Telegram ID of the user the token was issued to
+func (s *sessions) parse(token string, now time.Time) (int64, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, errBadSession
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, hmacSHA256(s.key, payload)) {
+		return 0, errBadSession
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return 0, errBadSession
+	}
+	id, expires, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return 0, errBadSession
+	}
+	telegramID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, errBadSession
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !now.Before(time.Unix(unix, 0)) {
+		return 0, errBadSession
+	}
+	return telegramID, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/ical"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/service"
+)
+
+// calendarRefresh is how often calendar apps are asked to fetch the feed again. Google Calendar
+// ignores it and refreshes about twice a day.
+const calendarRefresh = time.Hour
+
+// calendarFeed handles GET /calendar/{token}.ics with the user's upcoming repetitions as all-day
+// events. The token in the URL is the only credential, calendar apps can't send headers.
+func (s *Server) calendarFeed(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return errMethodNotAllowed
+	}
+	token, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/calendar/"), ".ics")
+	if !ok || token == "" || strings.Contains(token, "/") {
+		return errorf(http.StatusNotFound, "not found")
+	}
+
+	telegramID, err := s.calendars.GetTelegramID(r.Context(), token)
+	if err != nil {
+		return err
+	}
+	user, err := s.users.GetByTelegramID(r.Context(), telegramID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errorf(http.StatusNotFound, "not found")
+	}
+	repetitions, err := s.repetitionDB.GetUpcoming(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := s.config.Locale
+	if user.Language != "" {
+		loc = locale.Parse(user.Language)
+	}
+	now := s.clock.Now()
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	calendar := ical.Calendar{
+		ProdID:  "-//engbot//reviews//" + strings.ToUpper(string(loc)),
+		Name:    i18n.T(loc, "calendar.name"),
+		Refresh: calendarRefresh,
+	}
+	for _, rep := range repetitions {
+		// Overdue repetitions are still to be done, so they show up today
+		date := rep.NextReviewDate.In(now.Location())
+		if date.Before(today) {
+			date = today
+		}
+		description := i18n.T(loc, "calendar.description", rep.RepetitionNumber, service.CycleRepetitions)
+		if rep.Maintenance {
+			description = i18n.T(loc, "calendar.maintenance")
+		}
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         fmt.Sprintf("repetition-%d@engbot", rep.ID),
+			Date:        date,
+			Summary:     i18n.T(loc, "calendar.summary", rep.TopicName),
+			Description: description,
+			Stamp:       now,
+		})
+	}
+
+	var body bytes.Buffer
+	if err := ical.Write(&body, calendar); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="engbot.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	_, err = w.Write(body.Bytes())
+	return err
+}
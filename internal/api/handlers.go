@@ -0,0 +1,363 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/spaced_repetition"
+	"github.com/example/engbot/pkg/models"
+)
+
+// loginResponse is the session of the logged in user
+type loginResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      *models.User `json:"user"`
+}
+
+// login handles POST /api/auth/telegram with the fields the Telegram Login Widget passes to the
+// page, as a JSON object, and answers with a session token for the other endpoints
+func (s *Server) login(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return errMethodNotAllowed
+	}
+
+	var body map[string]any
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		return errorf(http.StatusBadRequest, "invalid JSON body: %v", err)
+	}
+	fields := make(map[string]string, len(body))
+	for key, value := range body {
+		switch value := value.(type) {
+		case string:
+			fields[key] = value
+		case json.Number:
+			fields[key] = value.String()
+		default:
+			return errorf(http.StatusBadRequest, "field %s must be a string or a number", key)
+		}
+	}
+
+	login, err := verifyLogin(fields, s.config.BotToken, s.clock.Now())
+	if err != nil {
+		return errorf(http.StatusUnauthorized, "%v", err)
+	}
+	user, err := s.users.GetByTelegramID(r.Context(), login.TelegramID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errorf(http.StatusForbidden, "start the bot in Telegram first")
+	}
+
+	token, expires := s.sessions.issue(login.TelegramID, s.clock.Now())
+	logging.FromContext(r.Context()).Info("API login", "telegram_id", login.TelegramID)
+	writeJSON(w, http.StatusOK, loginResponse{Token: token, ExpiresAt: expires, User: user})
+	return nil
+}
+
+// me handles GET /api/me with the profile and settings of the user
+func (s *Server) me(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	if r.Method != http.MethodGet {
+		return errMethodNotAllowed
+	}
+	writeJSON(w, http.StatusOK, user)
+	return nil
+}
+
+// topicInput is the body of creating or updating a topic, absent fields are left as they are
+type topicInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Difficulty  *int    `json:"difficulty"`
+}
+
+// topicList handles GET /api/topics with all topics of the user and POST /api/topics creating a
+// topic together with its statistics and first repetition
+func (s *Server) topicList(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	switch r.Method {
+	case http.MethodGet:
+		topics, err := s.topics.GetAllByUserID(r.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+		if topics == nil {
+			topics = []models.Topic{}
+		}
+		writeJSON(w, http.StatusOK, topics)
+		return nil
+	case http.MethodPost:
+		return s.createTopic(w, r, user)
+	default:
+		return errMethodNotAllowed
+	}
+}
+
+// createTopic creates the topic like adding it in the chat does: the first repetition follows the
+// user's intervals and skips the days already at the daily review limit
+func (s *Server) createTopic(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	var input topicInput
+	if err := readJSON(r, &input); err != nil {
+		return err
+	}
+	if input.Name == nil {
+		return errorf(http.StatusBadRequest, "name is required")
+	}
+	draft := models.Topic{Difficulty: models.DefaultTopicDifficulty}
+	if err := applyTopicInput(&draft, input); err != nil {
+		return err
+	}
+
+	if s.config.MaxTopicsPerUser > 0 && !user.IsAdmin && !s.config.AdminUserIDs[user.TelegramID] {
+		count, err := s.topics.CountByUserID(r.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+		if count >= s.config.MaxTopicsPerUser {
+			return errorf(http.StatusForbidden, "the limit of %d topics is reached", s.config.MaxTopicsPerUser)
+		}
+	}
+
+	intervals, err := database.GetUserIntervals(r.Context(), user.ID)
+	if err != nil {
+		logging.FromContext(r.Context()).Warn("failed to get user intervals, using defaults", "user_id", user.ID, "error", err)
+		intervals = database.DefaultIntervals
+	}
+	firstReview := s.repetitionDB.CalculateNextReviewDate(0, intervals)
+	if spilled, err := s.repetitionDB.SpillDate(r.Context(), user.ID, firstReview, user.DailyReviewLimit); err != nil {
+		logging.FromContext(r.Context()).Warn("failed to balance review date", "user_id", user.ID, "error", err)
+	} else {
+		firstReview = spilled
+	}
+
+	created, _, err := s.topics.CreateMany(r.Context(), user.ID, []string{draft.Name}, firstReview)
+	if err != nil {
+		return err
+	}
+	if len(created) == 0 {
+		return database.ErrTopicExists
+	}
+	topic := created[0]
+
+	// New topics start with an empty description and the default difficulty
+	if draft.Description != topic.Description || draft.Difficulty != topic.Difficulty {
+		topic.Description, topic.Difficulty = draft.Description, draft.Difficulty
+		if err := s.topics.Update(r.Context(), &topic); err != nil {
+			return err
+		}
+	}
+	writeJSON(w, http.StatusCreated, topic)
+	return nil
+}
+
+// topicDetails is a topic with its repetitions and statistics
+type topicDetails struct {
+	models.Topic
+	Repetitions []models.Repetition `json:"repetitions"`
+	Statistics  *models.Statistics  `json:"statistics"`
+}
+
+// topic handles GET, PATCH and DELETE of /api/topics/{id}. A deleted topic goes to the trash
+// and can be restored in the chat for a while.
+func (s *Server) topic(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	id, rest, err := pathID(r.URL.Path, "/api/topics/")
+	if err != nil || rest != "" {
+		return errorf(http.StatusNotFound, "not found")
+	}
+	topic, err := s.topics.GetByID(r.Context(), user.ID, id)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		return errorf(http.StatusNotFound, "topic %d not found", id)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		repetitions, err := s.repetitionDB.GetByTopic(r.Context(), user.ID, id)
+		if err != nil {
+			return err
+		}
+		if repetitions == nil {
+			repetitions = []models.Repetition{}
+		}
+		stats, err := s.stats.GetByUserAndTopic(r.Context(), user.ID, id)
+		if err != nil {
+			return err
+		}
+		writeJSON(w, http.StatusOK, topicDetails{Topic: *topic, Repetitions: repetitions, Statistics: stats})
+		return nil
+
+	case http.MethodPatch:
+		var input topicInput
+		if err := readJSON(r, &input); err != nil {
+			return err
+		}
+		if err := applyTopicInput(topic, input); err != nil {
+			return err
+		}
+		taken, err := s.topics.NameTaken(r.Context(), user.ID, topic.Name, topic.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return database.ErrTopicExists
+		}
+		if err := s.topics.Update(r.Context(), topic); err != nil {
+			return err
+		}
+		writeJSON(w, http.StatusOK, topic)
+		return nil
+
+	case http.MethodDelete:
+		if _, err := s.topics.Delete(r.Context(), user.ID, id); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+
+	default:
+		return errMethodNotAllowed
+	}
+}
+
+// applyTopicInput copies the fields present in the input to the topic
+func applyTopicInput(topic *models.Topic, input topicInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return errorf(http.StatusBadRequest, "name must not be empty")
+		}
+		topic.Name = name
+	}
+	if input.Description != nil {
+		topic.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Difficulty != nil {
+		if *input.Difficulty < 1 || *input.Difficulty > 5 {
+			return errorf(http.StatusBadRequest, "difficulty must be from 1 to 5")
+		}
+		topic.Difficulty = *input.Difficulty
+	}
+	return nil
+}
+
+// dueRepetitions handles GET /api/repetitions/due with the repetitions to review now
+func (s *Server) dueRepetitions(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	if r.Method != http.MethodGet {
+		return errMethodNotAllowed
+	}
+	repetitions, err := s.repetitionDB.GetDueRepetitions(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+	if repetitions == nil {
+		repetitions = []models.Repetition{}
+	}
+	writeJSON(w, http.StatusOK, repetitions)
+	return nil
+}
+
+// completeInput is the body of completing a review
+type completeInput struct {
+	// SM-2 answer quality from 0 (blackout) to 5 (perfect), 3 and above pass the repetition
+	Quality *int `json:"quality"`
+}
+
+// completion is the outcome of a completed review
+type completion struct {
+	Repetition *models.Repetition `json:"repetition"`
+	Topic      *models.Topic      `json:"topic"`
+	Passed     bool               `json:"passed"`
+	// nil once the topic has gone through the whole cycle
+	Next *models.Repetition `json:"next"`
+}
+
+// completeRepetition handles POST /api/repetitions/{id}/complete. The review counts toward the
+// daily goal and the streak just like one done in the chat.
+func (s *Server) completeRepetition(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	id, rest, err := pathID(r.URL.Path, "/api/repetitions/")
+	if err != nil || rest != "complete" {
+		return errorf(http.StatusNotFound, "not found")
+	}
+	if r.Method != http.MethodPost {
+		return errMethodNotAllowed
+	}
+
+	var input completeInput
+	if err := readJSON(r, &input); err != nil {
+		return err
+	}
+	if input.Quality == nil || *input.Quality < int(spaced_repetition.QualityBlackout) || *input.Quality > int(spaced_repetition.QualityPerfect) {
+		return errorf(http.StatusBadRequest, "quality must be from %d to %d", spaced_repetition.QualityBlackout, spaced_repetition.QualityPerfect)
+	}
+
+	result, err := s.repetitions.Complete(r.Context(), user, id, spaced_repetition.QualityResponse(*input.Quality))
+	if err != nil {
+		return err
+	}
+	if err := s.activity.RecordReview(r.Context(), user.ID); err != nil {
+		logging.FromContext(r.Context()).Warn("failed to record review", "user_id", user.ID, "error", err)
+	}
+
+	writeJSON(w, http.StatusOK, completion{
+		Repetition: result.Repetition,
+		Topic:      result.Topic,
+		Passed:     result.Passed,
+		Next:       result.Next,
+	})
+	return nil
+}
+
+// statsResponse is the progress of the user
+type statsResponse struct {
+	DailyGoal int                   `json:"daily_goal"`
+	Today     *models.DailyActivity `json:"today"`
+	Streak    int                   `json:"streak"`
+	Due       int                   `json:"due"`
+	Topics    []models.Statistics   `json:"topics"`
+}
+
+// statistics handles GET /api/stats with today's reviews, the streak and the statistics of every
+// topic, as /stats shows them in the chat
+func (s *Server) statistics(w http.ResponseWriter, r *http.Request, user *models.User) error {
+	if r.Method != http.MethodGet {
+		return errMethodNotAllowed
+	}
+
+	topics, err := s.stats.GetUserStatistics(r.Context(), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get statistics: %w", err)
+	}
+	if topics == nil {
+		topics = []models.Statistics{}
+	}
+	today, err := s.activity.GetToday(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+	streak, err := s.activity.GetStreak(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+	due, err := s.repetitionDB.GetDueRepetitions(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	writeJSON(w, http.StatusOK, statsResponse{
+		DailyGoal: user.DailyGoal,
+		Today:     today,
+		Streak:    streak,
+		Due:       len(due),
+		Topics:    topics,
+	})
+	return nil
+}
@@ -0,0 +1,334 @@
+// Package api serves the REST API of the companion web and mobile apps and the flashcard Telegram
+// Mini App. They share the data of the bot: the user logs in with the Telegram Login Widget, or
+// is vouched for by the Mini App data, and works with the same topics, repetitions and statistics
+// as in the chat.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/service"
+	"github.com/example/engbot/internal/spaced_repetition"
+	"github.com/example/engbot/pkg/models"
+)
+
+const (
+	// shutdownTimeout bounds how long in-flight requests may take on shutdown
+	shutdownTimeout = 5 * time.Second
+	// maxBodySize limits request bodies, which are small JSON objects
+	maxBodySize = 1 << 20
+	// defaultSessionTTL is how long a login lasts unless API_SESSION_TTL says otherwise
+	defaultSessionTTL = 30 * 24 * time.Hour
+)
+
+// Config configures the API server
+type Config struct {
+	// Address to listen on, like ":8081"
+	Addr string
+	// Token of the bot the Telegram Login Widget is set up for, it also signs the sessions
+	BotToken string
+	// How long a session token lasts
+	SessionTTL time.Duration
+	// Origins of the web apps allowed to call the API from a browser, "*" allows any
+	AllowedOrigins []string
+	// Maximum number of topics per user, 0 means unlimited. Admins are exempt.
+	MaxTopicsPerUser int
+	// Telegram IDs of admins from ADMIN_USER_IDS
+	AdminUserIDs map[int64]bool
+	// Language of the calendar feeds of users who haven't picked one, from BOT_LOCALE
+	Locale locale.Locale
+}
+
+// ConfigFromEnv reads API_ADDR, API_SESSION_TTL, API_ALLOWED_ORIGINS, MAX_TOPICS_PER_USER,
+// ADMIN_USER_IDS and BOT_LOCALE. The API is off when API_ADDR is empty.
+func ConfigFromEnv(botToken string) Config {
+	config := Config{
+		Addr:             os.Getenv("API_ADDR"),
+		BotToken:         botToken,
+		SessionTTL:       defaultSessionTTL,
+		MaxTopicsPerUser: 100,
+		AdminUserIDs:     make(map[int64]bool),
+		Locale:           locale.Parse(os.Getenv("BOT_LOCALE")),
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("API_SESSION_TTL")); err == nil && ttl > 0 {
+		config.SessionTTL = ttl
+	}
+	if limit, err := strconv.Atoi(os.Getenv("MAX_TOPICS_PER_USER")); err == nil && limit >= 0 {
+		config.MaxTopicsPerUser = limit
+	}
+	for _, origin := range strings.Split(os.Getenv("API_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			config.AllowedOrigins = append(config.AllowedOrigins, strings.TrimRight(origin, "/"))
+		}
+	}
+	for _, field := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64); err == nil {
+			config.AdminUserIDs[id] = true
+		}
+	}
+	return config
+}
+
+// Server answers the API requests
+type Server struct {
+	config       Config
+	clock        clock.Clock
+	sessions     *sessions
+	users        *database.UserRepository
+	topics       *database.TopicRepository
+	repetitionDB *database.RepetitionRepository
+	stats        *database.StatisticsRepository
+	activity     *database.ActivityRepository
+	calendars    *database.CalendarRepository
+	repetitions  *service.RepetitionService
+}
+
+// NewServer creates an API server
+func NewServer(config Config) *Server {
+	return NewServerWithClock(config, clock.System{})
+}
+
+// NewServerWithClock creates an API server that reads the current time from c
+func NewServerWithClock(config Config, c clock.Clock) *Server {
+	if config.SessionTTL <= 0 {
+		config.SessionTTL = defaultSessionTTL
+	}
+	sm2 := spaced_repetition.NewSM2()
+	sm2.Clock = c
+	return &Server{
+		config:       config,
+		clock:        c,
+		sessions:     newSessions(config.BotToken, config.SessionTTL),
+		users:        database.NewUserRepository(),
+		topics:       database.NewTopicRepositoryWithClock(c),
+		repetitionDB: database.NewRepetitionRepositoryWithClock(c),
+		stats:        database.NewStatisticsRepository(),
+		activity:     database.NewActivityRepositoryWithClock(c),
+		calendars:    database.NewCalendarRepository(),
+		repetitions:  service.NewRepetitionService(c, sm2),
+	}
+}
+
+// Handler returns the HTTP handler of all endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/auth/telegram", s.handle(s.login))
+	mux.HandleFunc("/api/me", s.authorized(s.me))
+	mux.HandleFunc("/api/topics", s.authorized(s.topicList))
+	mux.HandleFunc("/api/topics/", s.authorized(s.topic))
+	mux.HandleFunc("/api/repetitions/due", s.authorized(s.dueRepetitions))
+	mux.HandleFunc("/api/repetitions/", s.authorized(s.completeRepetition))
+	mux.HandleFunc("/api/stats", s.authorized(s.statistics))
+	mux.HandleFunc("/webapp/", s.handle(s.serveWebApp))
+	mux.HandleFunc("/calendar/", s.handle(s.calendarFeed))
+	return s.cors(mux)
+}
+
+// Run serves the API until ctx is cancelled, then shuts the server down
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Addr, err)
+	}
+
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	slog.Info("serving API", "addr", listener.Addr().String())
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("API server stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down API server: %w", err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("API server stopped: %w", err)
+	}
+	return nil
+}
+
+// statusError is an error with the HTTP status and the message the client gets
+type statusError struct {
+	status  int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+// errorf returns an error answered with the status
+func errorf(status int, format string, args ...any) error {
+	return &statusError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// errMethodNotAllowed answers a method the endpoint doesn't have
+var errMethodNotAllowed = errorf(http.StatusMethodNotAllowed, "method not allowed")
+
+// handlerFunc handles a request, leaving the error response to handle
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// userHandlerFunc handles a request of the logged in user
+type userHandlerFunc func(w http.ResponseWriter, r *http.Request, user *models.User) error
+
+// handle tags the log lines of the request with a request ID and turns the error of h into a
+// JSON error response
+func (s *Server) handle(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := slog.Default().With("request_id", logging.NewRequestID(), "method", r.Method, "path", r.URL.Path)
+		r = r.WithContext(logging.WithLogger(r.Context(), logger))
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+		err := h(w, r)
+		if err == nil {
+			return
+		}
+
+		var se *statusError
+		switch {
+		case errors.As(err, &se):
+		case errors.Is(err, database.ErrNotFound):
+			se = &statusError{status: http.StatusNotFound, message: "not found"}
+		case errors.Is(err, database.ErrTopicExists):
+			se = &statusError{status: http.StatusConflict, message: "the user already has a topic with this name"}
+		case errors.Is(err, database.ErrAlreadyCompleted):
+			se = &statusError{status: http.StatusConflict, message: "the repetition is already completed"}
+		case errors.Is(err, service.ErrTopicNotFound):
+			se = &statusError{status: http.StatusNotFound, message: "the topic of the repetition is gone"}
+		case database.IsTransient(err):
+			se = &statusError{status: http.StatusServiceUnavailable, message: "the service is busy, try again"}
+		default:
+			logger.Error("API request failed", "error", err)
+			se = &statusError{status: http.StatusInternalServerError, message: "internal error"}
+		}
+		writeJSON(w, se.status, map[string]string{"error": se.message})
+	}
+}
+
+// authorized handles the request of the user identified by the Authorization header: a session
+// token as "Bearer <token>" or, from the Mini App, its initData as "tma <initData>"
+func (s *Server) authorized(h userHandlerFunc) http.HandlerFunc {
+	return s.handle(func(w http.ResponseWriter, r *http.Request) error {
+		telegramID, err := s.authenticate(r.Header.Get("Authorization"))
+		if err != nil {
+			return err
+		}
+
+		user, err := s.users.GetByTelegramID(r.Context(), telegramID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return errorf(http.StatusForbidden, "start the bot in Telegram first")
+		}
+		ctx := logging.WithLogger(r.Context(), logging.FromContext(r.Context()).With("telegram_id", telegramID))
+		return h(w, r.WithContext(ctx), user)
+	})
+}
+
+// authenticate returns the Telegram ID of the user the Authorization header belongs to
+func (s *Server) authenticate(header string) (int64, error) {
+	scheme, credentials, _ := strings.Cut(header, " ")
+	var telegramID int64
+	var err error
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		telegramID, err = s.sessions.parse(strings.TrimSpace(credentials), s.clock.Now())
+	case "tma":
+		telegramID, err = verifyInitData(strings.TrimSpace(credentials), s.config.BotToken, s.clock.Now())
+	default:
+		return 0, errorf(http.StatusUnauthorized, "log in with POST /api/auth/telegram and send the token as Authorization: Bearer <token>")
+	}
+	if err != nil {
+		return 0, errorf(http.StatusUnauthorized, "%v", err)
+	}
+	return telegramID, nil
+}
+
+// cors lets the allowed web apps call the API from the browser and answers their preflight requests
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && s.originAllowed(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Max-Age", "86400")
+			w.Header().Add("Vary", "Origin")
+		}
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed reports whether the web app at origin may call the API
+func (s *Server) originAllowed(origin string) bool {
+	for _, allowed := range s.config.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// readJSON decodes the request body into v
+func readJSON(r *http.Request, v any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, "invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+// writeJSON answers with v as JSON
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Debug("failed to write API response", "error", err)
+	}
+}
+
+// pathID parses the ID that follows prefix in the path, returning the rest of the path after it
+func pathID(path, prefix string) (int64, string, error) {
+	rest := strings.TrimPrefix(path, prefix)
+	idPart, tail, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, "", errorf(http.StatusNotFound, "not found")
+	}
+	return id, tail, nil
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"crypto/hmac"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// webAppPage is the flashcard review of the Telegram Mini App. It calls the API with the
+// initData Telegram gives it, so it needs no login of its own.
+//
+//go:embed webapp/index.html
+var webAppPage []byte
+
+// serveWebApp handles GET /webapp/ with the page of the Mini App
+func (s *Server) serveWebApp(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return errMethodNotAllowed
+	}
+	if r.URL.Path != "/webapp/" {
+		return errorf(http.StatusNotFound, "not found")
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'self' 'unsafe-inline' https://telegram.org; style-src 'unsafe-inline'")
+	_, err := w.Write(webAppPage)
+	return err
+}
+
+// verifyInitData checks the initData Telegram passes to a Mini App and returns the Telegram ID
+// of its user. The hash is the HMAC-SHA-256 of the other fields, sorted and joined as key=value
+// lines, keyed with the HMAC-SHA-256 of the bot token under the key "WebAppData". See
+// https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app.
+func verifyInitData(initData, botToken string, now time.Time) (int64, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return 0, errBadInitData
+	}
+	hash, err := hex.DecodeString(values.Get("hash"))
+	if err != nil || len(hash) == 0 {
+		return 0, errBadInitData
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key + "=" + values.Get(key)
+	}
+
+	secret := hmacSHA256([]byte("WebAppData"), botToken)
+	if !hmac.Equal(hash, hmacSHA256(secret, strings.Join(lines, "\n"))) {
+		return 0, errBadInitData
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil || now.Sub(time.Unix(authDate, 0)) > loginMaxAge {
+		return 0, fmt.Errorf("%w: reopen the app", errBadInitData)
+	}
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return 0, errBadInitData
+	}
+	return user.ID, nil
+}
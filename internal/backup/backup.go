@@ -0,0 +1,199 @@
+// Package backup makes backups of the SQLite database on a schedule or on request, keeps them in
+// a directory or an S3-compatible bucket and deletes the old ones
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/internal/database"
+)
+
+// DefaultKeep is how many of the newest backups are kept unless BACKUP_KEEP says otherwise
+const DefaultKeep = 7
+
+// Backups are named engbot-<UTC time>.db, so their names sort by age
+const (
+	namePrefix = "engbot-"
+	nameSuffix = ".db"
+	timeLayout = "20060102-150405"
+)
+
+// Object is a backup in a store
+type Object struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+}
+
+// Store keeps backup files
+type Store interface {
+	// Put stores the size bytes of r under name
+	Put(ctx context.Context, name string, r io.ReadSeeker, size int64) error
+	// Get opens the object stored under name
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	// List returns all objects of the store
+	List(ctx context.Context) ([]Object, error)
+	// Delete removes the object stored under name
+	Delete(ctx context.Context, name string) error
+}
+
+// Manager backs up the database into a store and keeps the newest backups of it
+type Manager struct {
+	store Store
+	keep  int
+	clock clock.Clock
+}
+
+// NewManager creates a manager keeping the newest keep backups in store
+func NewManager(store Store, keep int) *Manager {
+	return NewManagerWithClock(store, keep, clock.System{})
+}
+
+// NewManagerWithClock creates a manager that names the backups by the time read from c
+func NewManagerWithClock(store Store, keep int, c clock.Clock) *Manager {
+	return &Manager{store: store, keep: max(keep, 1), clock: c}
+}
+
+// NewManagerFromEnv creates a manager for the store configured by BACKUP_S3_BUCKET or BACKUP_DIR,
+// keeping BACKUP_KEEP backups. It returns nil when no store is configured.
+func NewManagerFromEnv() (*Manager, error) {
+	var store Store
+	switch {
+	case os.Getenv("BACKUP_S3_BUCKET") != "":
+		s3, err := NewS3Store(S3Config{
+			Endpoint:  os.Getenv("BACKUP_S3_ENDPOINT"),
+			Region:    os.Getenv("BACKUP_S3_REGION"),
+			Bucket:    os.Getenv("BACKUP_S3_BUCKET"),
+			Prefix:    os.Getenv("BACKUP_S3_PREFIX"),
+			AccessKey: os.Getenv("BACKUP_S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("BACKUP_S3_SECRET_KEY"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		store = s3
+	case os.Getenv("BACKUP_DIR") != "":
+		store = NewDirStore(os.Getenv("BACKUP_DIR"))
+	default:
+		return nil, nil
+	}
+
+	keep := DefaultKeep
+	if value := os.Getenv("BACKUP_KEEP"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid BACKUP_KEEP %q: expected a positive number", value)
+		}
+		keep = n
+	}
+	return NewManager(store, keep), nil
+}
+
+// Run backs up the database into the store and deletes the backups beyond the newest ones kept.
+// A failure to delete the old backups doesn't fail the new one, it is returned with it.
+func (m *Manager) Run(ctx context.Context) (*Object, error) {
+	dir, err := os.MkdirTemp("", "engbot-backup")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := namePrefix + m.clock.Now().UTC().Format(timeLayout) + nameSuffix
+	path := filepath.Join(dir, name)
+	if err := database.Backup(ctx, path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	if err := m.store.Put(ctx, name, file, info.Size()); err != nil {
+		return nil, fmt.Errorf("failed to store backup: %w", err)
+	}
+
+	object := &Object{Name: name, Size: info.Size(), Modified: m.clock.Now()}
+	if err := m.prune(ctx); err != nil {
+		return object, err
+	}
+	return object, nil
+}
+
+// List returns the backups in the store, the newest first. Other objects are left out.
+func (m *Manager) List(ctx context.Context) ([]Object, error) {
+	objects, err := m.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	var backups []Object
+	for _, object := range objects {
+		if strings.HasPrefix(object.Name, namePrefix) && strings.HasSuffix(object.Name, nameSuffix) {
+			backups = append(backups, object)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name > backups[j].Name })
+	return backups, nil
+}
+
+// prune deletes the backups beyond the newest ones kept
+func (m *Manager) prune(ctx context.Context) error {
+	backups, err := m.List(ctx)
+	if err != nil {
+		return err
+	}
+	for i := m.keep; i < len(backups); i++ {
+		if err := m.store.Delete(ctx, backups[i].Name); err != nil {
+			return fmt.Errorf("failed to delete old backup %s: %w", backups[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// Download saves the backup called name, or the newest one for "latest", into dir and returns
+// the path of the file
+func (m *Manager) Download(ctx context.Context, name, dir string) (string, error) {
+	if name == "latest" {
+		backups, err := m.List(ctx)
+		if err != nil {
+			return "", err
+		}
+		if len(backups) == 0 {
+			return "", fmt.Errorf("no backups found")
+		}
+		name = backups[0].Name
+	}
+
+	in, err := m.store.Get(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get backup %s: %w", name, err)
+	}
+	defer in.Close()
+
+	path := filepath.Join(dir, filepath.Base(name))
+	out, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to save backup: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return "", fmt.Errorf("failed to download backup %s: %w", name, err)
+	}
+	if err := out.Close(); err != nil {
+		return "", fmt.Errorf("failed to save backup: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,80 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// DirStore keeps the backups in a local directory, e.g. a mounted volume
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore creates a store in dir, which is created on the first backup
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{dir: dir}
+}
+
+// Put writes the object to a temporary file first, so a failed backup never looks complete
+func (s *DirStore) Put(_ context.Context, name string, r io.ReadSeeker, _ int64) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	path := filepath.Join(s.dir, name)
+	tmp := path + ".tmp"
+
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get opens the file of the object
+func (s *DirStore) Get(_ context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.dir, filepath.Base(name)))
+}
+
+// List returns the files of the directory, none if it doesn't exist yet
+func (s *DirStore) List(_ context.Context) ([]Object, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []Object
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, Object{Name: entry.Name(), Size: info.Size(), Modified: info.ModTime()})
+	}
+	return objects, nil
+}
+
+// Delete removes the file of the object
+func (s *DirStore) Delete(_ context.Context, name string) error {
+	return os.Remove(filepath.Join(s.dir, filepath.Base(name)))
+}
@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/logging"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxTopicsPerList limits how many topics one pasted list can create
+const maxTopicsPerList = 50
+
+// handleAddManyCommand handles /addmany: the topics come one per line right after the command,
+// or in the next message
+func (b *Bot) handleAddManyCommand(ctx context.Context, message *tgbotapi.Message) error {
+	if strings.TrimSpace(message.CommandArguments()) != "" {
+		return b.addTopics(ctx, message, message.CommandArguments())
+	}
+
+	b.states.put(message.From.ID, &UserState{
+		Action: "adding_topic",
+		Step:   1,
+		Data:   make(map[string]string),
+	})
+	loc := b.localeFor(ctx, message.From.ID)
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "addmany.prompt", maxTopicsPerList))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}}})
+	return b.sendMessage(msg)
+}
+
+// addTopics creates a topic for every non-empty line of text in one go and reports how many
+// were created and which were skipped as duplicates or over the topic limit
+func (b *Bot) addTopics(ctx context.Context, message *tgbotapi.Message, text string) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	names := topicLines(text)
+	if len(names) == 0 {
+		return &ValidationError{Message: i18n.T(loc, "addmany.empty")}
+	}
+	if len(names) > maxTopicsPerList {
+		return &ValidationError{Message: i18n.T(loc, "addmany.too_many", maxTopicsPerList, len(names))}
+	}
+	// The topics of a list come due together, "Тема | 3d" works for one topic only
+	for _, name := range names {
+		if _, days, err := parseFirstReview(loc, name); err == nil && days != scheduledFirstReview {
+			return &ValidationError{Message: i18n.T(loc, "addmany.first_review")}
+		}
+	}
+
+	var overLimit []string
+	if b.config.MaxTopicsPerUser > 0 && !b.isAdmin(user) {
+		count, err := b.topicRepo.CountByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		free := max(b.config.MaxTopicsPerUser-count, 0)
+		if free == 0 {
+			b.states.remove(message.From.ID)
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, b.topicLimitText(loc)))
+		}
+		if len(names) > free {
+			names, overLimit = names[:free], names[free:]
+		}
+	}
+
+	intervals, err := database.GetUserIntervals(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to get user intervals, using defaults", "user_id", user.ID, "error", err)
+		intervals = database.DefaultIntervals
+	}
+	created, duplicates, err := b.topicRepo.CreateMany(ctx, user.ID, names, b.repetitionRepo.CalculateNextReviewDate(0, intervals))
+	if err != nil {
+		return err
+	}
+	b.states.remove(message.From.ID)
+	// All the new topics come due on the same day, the ones over the daily limit move on
+	if _, err := b.repetitionRepo.Rebalance(ctx, user.ID, user.DailyReviewLimit, database.LoadBalanceDays); err != nil {
+		logging.FromContext(ctx).Warn("failed to balance review load", "user_id", user.ID, "error", err)
+	}
+
+	var reply strings.Builder
+	reply.WriteString(i18n.T(loc, "addmany.created", len(created)))
+	if len(duplicates) > 0 {
+		reply.WriteString(i18n.T(loc, "addmany.duplicates", len(duplicates)))
+		for _, name := range duplicates {
+			reply.WriteString("• " + name + "\n")
+		}
+	}
+	if len(overLimit) > 0 {
+		reply.WriteString(i18n.T(loc, "addmany.over_limit", b.config.MaxTopicsPerUser, len(overLimit)))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, reply.String())
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "button.topic_list"), CallbackData: "list_topics"}},
+		{{Text: i18n.T(loc, "button.menu"), CallbackData: "main_menu"}},
+	})
+	return b.sendMessage(msg)
+}
+
+// topicLines returns the non-empty lines of a pasted list without list bullets
+func topicLines(text string) []string {
+	var names []string
+	for _, line := range strings.Split(text, "\n") {
+		name := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-•*"))
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,308 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackBroadcastSend starts the broadcast the admin has previewed
+const callbackBroadcastSend = "broadcast_send"
+
+// actionConfirmBroadcast is the admin state between /broadcast and the confirmation button
+const actionConfirmBroadcast = "confirm_broadcast"
+
+// broadcastProgressEvery is how many deliveries pass between progress updates
+const broadcastProgressEvery = 25
+
+// broadcastProgress counts the outcome of a running broadcast
+type broadcastProgress struct {
+	loc    locale.Locale // the admin's interface language
+	total  int
+	sent   int
+	failed int
+}
+
+// text renders the progress or, once done, the summary of the broadcast
+func (p broadcastProgress) text(done bool) string {
+	if done {
+		return i18n.T(p.loc, "broadcast.done", p.sent, p.total, p.failed)
+	}
+	return i18n.T(p.loc, "broadcast.progress", p.sent+p.failed, p.total, p.failed)
+}
+
+// handleAdminCommand handles /admin stats with bot-wide totals, /admin backup and
+// /admin undeliverable. Non-admins see the unknown command reply.
+func (b *Bot) handleAdminCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	if !b.isAdmin(user) {
+		return b.handleUnknownCommand(message)
+	}
+	loc := b.userLocale(user)
+
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "", "stats":
+	case "backup":
+		return b.handleAdminBackup(ctx, loc, message.Chat.ID)
+	case "undeliverable":
+		return b.handleAdminUndeliverable(ctx, loc, message.Chat.ID)
+	default:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "admin.usage")))
+	}
+
+	stats, err := b.userRepo.GetAdminStats(ctx)
+	if err != nil {
+		return err
+	}
+	deliveries, err := b.deliveryRepo.GetStats(ctx)
+	if err != nil {
+		return err
+	}
+	dispatch := b.dispatcher.Stats()
+
+	text := i18n.T(loc, "admin.stats",
+		stats.Users, stats.NotificationsEnabled, stats.BroadcastOptOut, stats.Inactive,
+		stats.Topics, stats.ArchivedTopics,
+		stats.Repetitions, stats.CompletedRepetitions,
+		dispatch.Sent, dispatch.Queued, dispatch.Retried, dispatch.Dropped,
+		deliveries.Pending, deliveries.Undeliverable)
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, text))
+}
+
+// undeliverableReportLimit is how many users /admin undeliverable lists
+const undeliverableReportLimit = 30
+
+// handleAdminUndeliverable lists the users the bot failed to reach, the latest failures first
+func (b *Bot) handleAdminUndeliverable(ctx context.Context, loc locale.Locale, chatID int64) error {
+	users, err := b.deliveryRepo.GetUndeliverable(ctx, undeliverableReportLimit)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "admin.all_delivered")))
+	}
+
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "admin.undeliverable"))
+	for _, u := range users {
+		name := u.FirstName
+		if u.Username != "" {
+			name = "@" + u.Username
+		}
+		text.WriteString(fmt.Sprintf("\n%s (%d), %s\n", name, u.TelegramID, u.LastFailedAt.Local().Format("02.01 15:04")))
+		if u.Blocked > 0 {
+			text.WriteString(i18n.T(loc, "admin.blocked", u.Blocked))
+		}
+		if u.Failed > 0 {
+			text.WriteString(i18n.T(loc, "admin.failed", u.Failed))
+		}
+		if u.Disabled {
+			text.WriteString(i18n.T(loc, "admin.disabled"))
+		}
+		if u.Inactive {
+			text.WriteString(i18n.T(loc, "admin.inactive"))
+		}
+		text.WriteString(i18n.T(loc, "admin.error", truncateRunes(u.LastError, 200)))
+	}
+	if len(users) == undeliverableReportLimit {
+		text.WriteString(i18n.T(loc, "admin.latest", undeliverableReportLimit))
+	}
+	return b.sendMessage(tgbotapi.NewMessage(chatID, text.String()))
+}
+
+// handleAdminBackup backs up the database right away, as the scheduled backup job does
+func (b *Bot) handleAdminBackup(ctx context.Context, loc locale.Locale, chatID int64) error {
+	manager := b.config.Scheduler.Backup
+	if manager == nil {
+		return &ValidationError{Message: i18n.T(loc, "admin.backup_off")}
+	}
+
+	object, err := manager.Run(ctx)
+	if errors.Is(err, database.ErrBackupUnsupported) {
+		return &ValidationError{Message: i18n.T(loc, "admin.backup_unsupported")}
+	}
+	if object == nil {
+		return err
+	}
+
+	text := i18n.T(loc, "admin.backup_done", object.Name, float64(object.Size)/(1<<20))
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to delete old backups", "error", err)
+		text += i18n.T(loc, "admin.backup_cleanup_failed")
+	}
+	return b.sendMessage(tgbotapi.NewMessage(chatID, text))
+}
+
+// handleBroadcastCommand handles /broadcast <текст>: it shows a preview and asks the admin to confirm
+func (b *Bot) handleBroadcastCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	if !b.isAdmin(user) {
+		return b.handleUnknownCommand(message)
+	}
+	loc := b.userLocale(user)
+
+	text := strings.TrimSpace(message.CommandArguments())
+	if text == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "broadcast.usage")))
+	}
+
+	stats, err := b.userRepo.GetAdminStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	b.states.put(message.From.ID, &UserState{
+		Action: actionConfirmBroadcast,
+		Step:   1,
+		Data:   map[string]string{"text": text},
+	})
+
+	preview := i18n.T(loc, "broadcast.preview", stats.BroadcastRecipients, stats.BroadcastOptOut, text)
+	msg := tgbotapi.NewMessage(message.Chat.ID, preview)
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "broadcast.send"), CallbackData: callbackBroadcastSend}},
+		{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+	})
+	return b.sendMessage(msg)
+}
+
+// handleBroadcastSend starts delivering the previewed broadcast in the background
+func (b *Bot) handleBroadcastSend(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if !b.isAdmin(user) {
+		return &ValidationError{Message: i18n.T(loc, "broadcast.admins_only")}
+	}
+
+	state, ok := b.states.get(callback.From.ID)
+	if !ok || state.Action != actionConfirmBroadcast {
+		return &ValidationError{Message: i18n.T(loc, "broadcast.not_found")}
+	}
+
+	if !b.startBroadcast() {
+		return &ValidationError{Message: i18n.T(loc, "broadcast.running")}
+	}
+	b.states.remove(callback.From.ID)
+
+	recipients, err := b.userRepo.GetBroadcastRecipients(ctx)
+	if err != nil {
+		b.finishBroadcast()
+		return err
+	}
+
+	progress := broadcastProgress{loc: loc, total: len(recipients)}
+	chatID, messageID := callback.Message.Chat.ID, callback.Message.MessageID
+	if err := b.editMessage(tgbotapi.NewEditMessageText(chatID, messageID, progress.text(false))); err != nil {
+		logging.FromContext(ctx).Warn("failed to show broadcast progress", "error", err)
+	}
+
+	text := state.Data["text"]
+	safeGoroutine(func() {
+		defer b.finishBroadcast()
+		b.runBroadcast(ctx, loc, chatID, messageID, text, recipients)
+	})
+	return nil
+}
+
+// startBroadcast marks a broadcast as running, reporting false if one already is
+func (b *Bot) startBroadcast() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.broadcasting {
+		return false
+	}
+	b.broadcasting = true
+	return true
+}
+
+// finishBroadcast allows the next broadcast to start
+func (b *Bot) finishBroadcast() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.broadcasting = false
+}
+
+// runBroadcast sends the text to every recipient at no more than BroadcastRate messages per second,
+// editing the admin's status message as it goes
+func (b *Bot) runBroadcast(ctx context.Context, loc locale.Locale, chatID int64, messageID int, text string, recipients []models.User) {
+	ticker := time.NewTicker(time.Second / time.Duration(max(1, b.config.BroadcastRate)))
+	defer ticker.Stop()
+
+	progress := broadcastProgress{loc: loc, total: len(recipients)}
+	for i, recipient := range recipients {
+		select {
+		case <-ctx.Done():
+			logging.FromContext(ctx).Warn("broadcast interrupted", "done", i, "total", len(recipients))
+			return
+		case <-ticker.C:
+		}
+
+		if err := b.sendBroadcastMessage(ctx, &recipient, text); err != nil {
+			logging.FromContext(ctx).Warn("failed to deliver broadcast", "telegram_id", recipient.TelegramID, "error", err)
+			progress.failed++
+		} else {
+			progress.sent++
+		}
+
+		if (i+1)%broadcastProgressEvery == 0 && i+1 < len(recipients) {
+			if err := b.editMessage(tgbotapi.NewEditMessageText(chatID, messageID, progress.text(false))); err != nil {
+				logging.FromContext(ctx).Warn("failed to update broadcast progress", "error", err)
+			}
+		}
+	}
+
+	logging.FromContext(ctx).Info("broadcast finished", "sent", progress.sent, "failed", progress.failed)
+	if err := b.editMessage(tgbotapi.NewEditMessageText(chatID, messageID, progress.text(true))); err != nil {
+		logging.FromContext(ctx).Warn("failed to report broadcast result", "error", err)
+	}
+}
+
+// sendBroadcastMessage delivers one broadcast message with the opt-out hint in the recipient's language
+func (b *Bot) sendBroadcastMessage(ctx context.Context, recipient *models.User, text string) error {
+	msg := tgbotapi.NewMessage(recipient.TelegramID, text+"\n\n"+i18n.T(b.userLocale(recipient), "broadcast.footer"))
+	if _, err := b.dispatcher.Send(ctx, recipient.TelegramID, msg); err != nil {
+		return fmt.Errorf("failed to send broadcast: %w", err)
+	}
+	return nil
+}
+
+// handleNewsCommand handles /news on|off, the user's opt-out from admin broadcasts
+func (b *Bot) handleNewsCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "on":
+		user.BroadcastOptOut = false
+	case "off":
+		user.BroadcastOptOut = true
+	default:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "news.usage")))
+	}
+
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "news.done", enabledString(loc, !user.BroadcastOptOut))))
+}
@@ -0,0 +1,287 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/anki"
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/textutil"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxImportFileSize is the largest file the Bot API lets bots download
+const maxImportFileSize = 20 << 20
+
+// fileDownloadTimeout bounds downloading a file the user sent
+const fileDownloadTimeout = time.Minute
+
+// handleAnkiCommand handles /anki: without arguments it explains the import,
+// "/anki export [all] [txt]" sends the words as an Anki deck
+func (b *Bot) handleAnkiCommand(ctx context.Context, message *tgbotapi.Message) error {
+	loc := b.localeFor(ctx, message.From.ID)
+	args := strings.Fields(strings.ToLower(message.CommandArguments()))
+	if len(args) == 0 || args[0] != "export" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "anki.usage", b.config.AnkiFields)))
+	}
+
+	all, text := false, false
+	for _, arg := range args[1:] {
+		switch arg {
+		case "all":
+			all = true
+		case "txt":
+			text = true
+		default:
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "anki.export_usage")))
+		}
+	}
+	return b.sendAnkiExport(ctx, message, all, text)
+}
+
+// sendAnkiExport sends the user's learned words, or all their words, as an .apkg or .txt deck
+// with one subdeck per topic
+func (b *Bot) sendAnkiExport(ctx context.Context, message *tgbotapi.Message, all, text bool) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	var words []models.Word
+	if all {
+		words, err = b.wordRepo.GetByUserID(ctx, user.ID)
+	} else {
+		words, err = b.progressRepo.GetLearnedWords(user.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		if all {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "anki.no_words")))
+		}
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "anki.no_learned")))
+	}
+
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	topicNames := make(map[int64]string, len(topics))
+	for _, t := range topics {
+		topicNames[t.ID] = t.Name
+	}
+
+	notes := make([]anki.Note, len(words))
+	for i, w := range words {
+		notes[i] = anki.Note{
+			Deck:        topicNames[w.TopicID],
+			Word:        w.Word,
+			Translation: w.Translation,
+			Description: w.Description,
+			Examples:    w.Examples,
+		}
+	}
+
+	var buf bytes.Buffer
+	name := "engbot-" + b.clock.Now().Format("2006-01-02")
+	if text {
+		err = anki.WriteText(&buf, notes, b.config.DefaultTopicName)
+		name += ".txt"
+	} else {
+		err = anki.WritePackage(&buf, notes, b.config.DefaultTopicName, b.clock.Now())
+		name += ".apkg"
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export Anki deck: %w", err)
+	}
+
+	doc := tgbotapi.NewDocument(message.Chat.ID, tgbotapi.FileBytes{Name: name, Bytes: buf.Bytes()})
+	doc.Caption = i18n.T(loc, "anki.exported", len(notes), i18n.Plural(loc, "plural.word", len(notes)))
+	if _, err := b.dispatcher.Send(ctx, message.Chat.ID, doc); err != nil {
+		return fmt.Errorf("failed to send Anki deck: %w", err)
+	}
+	return nil
+}
+
+// handleDocument imports a file the user sent. Errors are reported in the chat.
+func (b *Bot) handleDocument(ctx context.Context, message *tgbotapi.Message) error {
+	if err := b.importAnkiFile(ctx, message); err != nil {
+		logging.FromContext(ctx).Error("failed to import file", "file_name", message.Document.FileName, "error", err)
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, userErrorMessage(b.localeFor(ctx, message.From.ID), err)))
+	}
+	return nil
+}
+
+// importAnkiFile reads an .apkg or .txt Anki export and adds its words, one topic per deck.
+// The caption may override the field mapping.
+func (b *Bot) importAnkiFile(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	doc := message.Document
+	ext := strings.ToLower(filepath.Ext(doc.FileName))
+	if ext != ".apkg" && ext != ".txt" && ext != ".tsv" && ext != ".csv" {
+		return &ValidationError{Message: i18n.T(loc, "anki.unsupported")}
+	}
+	if doc.FileSize > maxImportFileSize {
+		return &ValidationError{Message: i18n.T(loc, "anki.too_big", maxImportFileSize>>20)}
+	}
+
+	mapping := b.config.AnkiFields
+	if caption := strings.TrimSpace(message.Caption); caption != "" {
+		var err error
+		if mapping, err = anki.ParseFieldMapping(caption); err != nil {
+			return &ValidationError{Message: i18n.T(loc, "anki.bad_caption")}
+		}
+	}
+
+	data, err := b.downloadFile(ctx, loc, doc.FileID)
+	if err != nil {
+		return err
+	}
+
+	var notes []anki.Note
+	if ext == ".apkg" {
+		notes, err = anki.ReadPackage(bytes.NewReader(data), int64(len(data)), mapping)
+	} else {
+		notes, err = anki.ReadText(bytes.NewReader(data), mapping)
+	}
+	switch {
+	case errors.Is(err, anki.ErrNewPackageFormat):
+		return &ValidationError{Message: i18n.T(loc, "anki.new_format")}
+	case errors.Is(err, anki.ErrNotPackage), errors.Is(err, anki.ErrNotText):
+		return &ValidationError{Message: i18n.T(loc, "anki.unreadable")}
+	case err != nil:
+		return err
+	}
+	if len(notes) == 0 {
+		return &ValidationError{Message: i18n.T(loc, "anki.no_notes", mapping)}
+	}
+
+	decks, added, err := b.importAnkiNotes(ctx, user, notes)
+	if err != nil {
+		return err
+	}
+
+	text := i18n.T(loc, "anki.imported", decks, added, len(notes))
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, text))
+}
+
+// importAnkiNotes adds the notes to the topics named after their decks, creating missing topics.
+// Returns the number of decks and of words that were new.
+func (b *Bot) importAnkiNotes(ctx context.Context, user *models.User, notes []anki.Note) (int, int, error) {
+	byDeck := make(map[string][]anki.Note)
+	var decks []string
+	for _, note := range notes {
+		deck := note.Deck
+		if deck == "" {
+			deck = b.config.DefaultTopicName
+		}
+		if _, ok := byDeck[deck]; !ok {
+			decks = append(decks, deck)
+		}
+		byDeck[deck] = append(byDeck[deck], note)
+	}
+
+	// Checked up front so that an import too big for the limit adds nothing
+	limit := b.topicLimit(user)
+	if limit > 0 {
+		topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+		if err != nil {
+			return 0, 0, err
+		}
+		existing := make(map[string]bool, len(topics))
+		for _, t := range topics {
+			existing[textutil.Normalize(t.Name)] = true
+		}
+		newTopics := 0
+		for _, deck := range decks {
+			if !existing[textutil.Normalize(deck)] {
+				existing[textutil.Normalize(deck)] = true
+				newTopics++
+			}
+		}
+		if len(topics)+newTopics > limit {
+			return 0, 0, &ValidationError{Message: i18n.T(b.userLocale(user), "anki.topic_limit",
+				newTopics, max(0, limit-len(topics)), limit)}
+		}
+	}
+
+	var words []models.Word
+	for _, deck := range decks {
+		topic, err := b.topicRepo.GetGeneralTopic(ctx, user.ID, deck, limit)
+		if errors.Is(err, database.ErrTopicLimit) {
+			return 0, 0, &ValidationError{Message: b.topicLimitText(b.userLocale(user))}
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, note := range byDeck[deck] {
+			words = append(words, models.Word{
+				Word:        note.Word,
+				Translation: note.Translation,
+				Description: note.Description,
+				Examples:    note.Examples,
+				TopicID:     topic.ID,
+			})
+		}
+	}
+
+	added, err := b.wordRepo.ImportWords(ctx, user.ID, words)
+	if err != nil {
+		return 0, 0, err
+	}
+	logging.FromContext(ctx).Info("imported Anki deck", "user_id", user.ID, "decks", len(decks), "notes", len(notes), "added", added)
+	if added > 0 && b.enricher != nil {
+		b.enricher.Notify()
+	}
+	return len(decks), added, nil
+}
+
+// downloadFile fetches a file the user sent to the bot
+func (b *Bot) downloadFile(ctx context.Context, loc locale.Locale, fileID string) ([]byte, error) {
+	file, err := b.api.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fileDownloadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.Link(b.api.Token), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if len(data) > maxImportFileSize {
+		return nil, &ValidationError{Message: i18n.T(loc, "anki.too_big", maxImportFileSize>>20)}
+	}
+	return data, nil
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/example/engbot/internal/anki"
+	"github.com/example/engbot/internal/database/dbtest"
+)
+
+func TestImportAnkiNotesWithoutDeck(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	user := dbtest.User(t, 800)
+	name := tb.config.DefaultTopicName
+
+	// Notes without a deck go to the default topic, the others to their deck's topic
+	notes := []anki.Note{
+		{Word: "cat", Translation: "кошка"},
+		{Deck: "Food", Word: "bread", Translation: "хлеб"},
+		{Word: "dog", Translation: "собака"},
+	}
+	if decks, added, err := tb.importAnkiNotes(ctx, user, notes); err != nil || decks != 2 || added != 3 {
+		t.Fatalf("first import: %d decks, %d words, %v; want 2 and 3", decks, added, err)
+	}
+	// A second import reuses the default topic instead of creating another one
+	if _, added, err := tb.importAnkiNotes(ctx, user, []anki.Note{{Word: "bird", Translation: "птица"}}); err != nil || added != 1 {
+		t.Fatalf("second import: %d words, %v; want 1", added, err)
+	}
+
+	topics := tb.topicsByName(t, user.ID)
+	if len(topics) != 2 {
+		t.Fatalf("%d topics, want %q and Food", len(topics), name)
+	}
+	general, ok := topics[name]
+	if !ok {
+		t.Fatalf("no default topic %q among %v", name, topics)
+	}
+	words, err := tb.wordRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("failed to get words: %v", err)
+	}
+	for _, word := range words {
+		want := general.ID
+		if word.Word == "bread" {
+			want = topics["Food"].ID
+		}
+		if word.TopicID != want {
+			t.Errorf("%q in topic %d, want %d", word.Word, word.TopicID, want)
+		}
+	}
+	if len(words) != 4 {
+		t.Errorf("%d words stored, want 4", len(words))
+	}
+
+	// A deck that would be a new topic over the limit is refused
+	tb.config.MaxTopicsPerUser = 2
+	var validation *ValidationError
+	if _, _, err := tb.importAnkiNotes(ctx, user, []anki.Note{{Deck: "Verbs", Word: "run", Translation: "бежать"}}); !errors.As(err, &validation) {
+		t.Errorf("import over the limit: %v, want a validation error", err)
+	}
+	if topics := tb.topicsByName(t, user.ID); len(topics) != 2 {
+		t.Errorf("%d topics after the refused import, want 2", len(topics))
+	}
+}
@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data of the topic archive
+const (
+	callbackArchiveMenu        = "archive_menu"
+	callbackArchiveTopicPrefix = "archive_topic_"
+	callbackRestoreTopicPrefix = "restore_topic_"
+)
+
+// handleArchiveCommand handles /archive <номер>, or shows the archived topics without a number
+func (b *Bot) handleArchiveCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, archiveText(loc, topics))
+		msg.ReplyMarkup = createKeyboard(archiveButtons(loc, topics))
+		return b.sendMessage(msg)
+	}
+
+	index, err := strconv.Atoi(args)
+	if err != nil || index < 1 || index > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "archive.usage")))
+	}
+
+	topic := topics[index-1]
+	if topic.Archived {
+		return &ValidationError{Message: i18n.T(loc, "archive.already", topic.Name)}
+	}
+	return b.setTopicArchived(ctx, message.Chat.ID, user, topic, true)
+}
+
+// handleArchiveMenu shows the archived topics with restore buttons
+func (b *Bot) handleArchiveMenu(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		archiveText(loc, topics),
+		createKeyboard(archiveButtons(loc, topics)),
+	)
+	return b.editMessage(msg)
+}
+
+// handleArchiveTopicCallback archives or restores the topic from an inline button
+func (b *Bot) handleArchiveTopicCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, topicID int64, archived bool) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+
+	topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		return &ValidationError{Message: i18n.T(b.userLocale(user), "topic.not_found")}
+	}
+	if topic.Archived == archived {
+		return b.handleArchiveMenu(ctx, callback)
+	}
+
+	return b.setTopicArchived(ctx, callback.Message.Chat.ID, user, *topic, archived)
+}
+
+// setTopicArchived moves the topic to the archive or back, keeping its repetitions and statistics
+func (b *Bot) setTopicArchived(ctx context.Context, chatID int64, user *models.User, topic models.Topic, archived bool) error {
+	count, err := b.topicRepo.BulkSetArchived(ctx, user.ID, []int64{topic.ID}, archived)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	text := i18n.T(loc, "archive.done", topic.Name)
+	if !archived {
+		text = i18n.T(loc, "archive.restored", topic.Name)
+	}
+	if count > 1 {
+		text += i18n.T(loc, "archive.subtopics", count-1)
+	}
+	buttons := b.topicsMenuButtons(loc)
+	if archived {
+		text += "\n\n" + b.undoHint(loc)
+		buttons = append([][]MenuButton{undoArchiveButton(loc, topic.ID, b.clock.Now())}, buttons...)
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = createKeyboard(buttons)
+	return b.sendMessage(msg)
+}
+
+// archiveText lists the archived topics
+func archiveText(loc locale.Locale, topics []models.Topic) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "archive.title"))
+
+	count := 0
+	for _, topic := range topics {
+		if !topic.Archived {
+			continue
+		}
+		count++
+		text.WriteString(fmt.Sprintf("%d. %s\n", count, topic.Name))
+	}
+	if count == 0 {
+		text.WriteString(i18n.T(loc, "archive.empty"))
+	}
+
+	text.WriteString(i18n.T(loc, "archive.hint"))
+	return text.String()
+}
+
+// archiveButtons returns a restore button per archived topic and the way back
+func archiveButtons(loc locale.Locale, topics []models.Topic) [][]MenuButton {
+	var buttons [][]MenuButton
+	for _, topic := range topics {
+		if topic.Archived {
+			buttons = append(buttons, []MenuButton{{
+				Text:         i18n.T(loc, "archive.restore_button", topic.Name),
+				CallbackData: fmt.Sprintf("%s%d", callbackRestoreTopicPrefix, topic.ID),
+			}})
+		}
+	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "button.back_to_topics"), CallbackData: "topics_menu"}})
+	return buttons
+}
+
+// archiveTopicButton returns the inline button that archives a topic
+func archiveTopicButton(loc locale.Locale, topicID int64, name string) MenuButton {
+	return MenuButton{
+		Text:         i18n.T(loc, "archive.button", name),
+		CallbackData: fmt.Sprintf("%s%d", callbackArchiveTopicPrefix, topicID),
+	}
+}
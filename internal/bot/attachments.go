@@ -0,0 +1,238 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Topic attachments
+const (
+	callbackMaterialsPrefix      = "materials_"       // sends the attachments of the topic with the ID that follows
+	callbackClearMaterialsPrefix = "clear_materials_" // deletes them
+	callbackAttachDone           = "attach_done"      // stops waiting for attachments
+	actionAddingAttachment       = "adding_attachment"
+	maxAttachmentsPerTopic       = 10
+	maxAttachmentCaption         = 200
+)
+
+// handleAttachCommand handles /attach <номер>: the links, photos and documents the user sends
+// next are attached to the topic
+func (b *Bot) handleAttachCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	index, err := strconv.Atoi(strings.TrimSpace(message.CommandArguments()))
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "attach.usage")))
+	}
+
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+	if index < 1 || index > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topic.wrong_number")))
+	}
+	topic := topics[index-1]
+
+	attachments, err := b.attachmentRepo.GetByTopic(ctx, user.ID, topic.ID)
+	if err != nil {
+		return err
+	}
+
+	b.states.put(message.From.ID, &UserState{
+		Action: actionAddingAttachment,
+		Step:   1,
+		Data:   map[string]string{"topic_id": strconv.FormatInt(topic.ID, 10)},
+	})
+
+	text := i18n.T(loc, "attach.start", topic.Name, len(attachments), maxAttachmentsPerTopic)
+	buttons := [][]MenuButton{{{Text: i18n.T(loc, "button.done"), CallbackData: callbackAttachDone}}}
+	if len(attachments) > 0 {
+		buttons = append([][]MenuButton{
+			{materialsButton(topic.ID, i18n.T(loc, "attach.show"))},
+			{{Text: i18n.T(loc, "attach.clear"), CallbackData: fmt.Sprintf("%s%d", callbackClearMaterialsPrefix, topic.ID)}},
+		}, buttons...)
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyMarkup = createKeyboard(buttons)
+	return b.sendMessage(msg)
+}
+
+// handleAttachmentMessage attaches the link, photo or document from the message to the topic
+// from the user state
+func (b *Bot) handleAttachmentMessage(ctx context.Context, message *tgbotapi.Message) error {
+	state, _ := b.states.get(message.From.ID)
+	topicID, err := strconv.ParseInt(state.Data["topic_id"], 10, 64)
+	if err != nil {
+		b.states.remove(message.From.ID)
+		return fmt.Errorf("invalid topic ID in attachment state: %w", err)
+	}
+
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	attachment, err := attachmentFromMessage(loc, message)
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, userErrorMessage(loc, err)))
+	}
+
+	topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		b.states.remove(message.From.ID)
+		return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+	}
+
+	attachments, err := b.attachmentRepo.GetByTopic(ctx, user.ID, topic.ID)
+	if err != nil {
+		return err
+	}
+	if len(attachments) >= maxAttachmentsPerTopic {
+		b.states.remove(message.From.ID)
+		return &ValidationError{Message: i18n.T(loc, "attach.limit", maxAttachmentsPerTopic)}
+	}
+
+	attachment.UserID = user.ID
+	attachment.TopicID = topic.ID
+	if err := b.attachmentRepo.Create(ctx, attachment); err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "attach.added", topic.Name, len(attachments)+1, maxAttachmentsPerTopic))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{{{Text: i18n.T(loc, "button.done"), CallbackData: callbackAttachDone}}})
+	return b.sendMessage(msg)
+}
+
+// attachmentFromMessage reads the attachment from a message: a photo, a document or an http(s) link
+func attachmentFromMessage(loc locale.Locale, message *tgbotapi.Message) (*models.TopicAttachment, error) {
+	caption := []rune(strings.TrimSpace(message.Caption))
+	if len(caption) > maxAttachmentCaption {
+		caption = caption[:maxAttachmentCaption]
+	}
+
+	switch {
+	case len(message.Photo) > 0:
+		// Telegram sends a photo in several sizes, the last one is the largest
+		photo := message.Photo[len(message.Photo)-1]
+		return &models.TopicAttachment{Kind: models.AttachmentPhoto, Content: photo.FileID, Caption: string(caption)}, nil
+	case message.Document != nil:
+		if len(caption) == 0 {
+			caption = []rune(message.Document.FileName)
+		}
+		return &models.TopicAttachment{Kind: models.AttachmentDocument, Content: message.Document.FileID, Caption: string(caption)}, nil
+	}
+
+	link := strings.TrimSpace(message.Text)
+	u, err := url.Parse(link)
+	if link == "" || strings.ContainsAny(link, " \n") || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, &ValidationError{Message: i18n.T(loc, "attach.invalid")}
+	}
+	return &models.TopicAttachment{Kind: models.AttachmentLink, Content: link}, nil
+}
+
+// handleShowMaterials sends the attachments of the user's topic: the notes and links in one
+// message, then the photos and documents
+func (b *Bot) handleShowMaterials(ctx context.Context, callback *tgbotapi.CallbackQuery, topicID int64) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if topic == nil {
+		return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+	}
+	attachments, err := b.attachmentRepo.GetByTopic(ctx, user.ID, topic.ID)
+	if err != nil {
+		return err
+	}
+	if len(attachments) == 0 {
+		return &ValidationError{Message: i18n.T(loc, "attach.empty")}
+	}
+
+	chatID := callback.Message.Chat.ID
+	var links strings.Builder
+	for _, a := range attachments {
+		switch a.Kind {
+		case models.AttachmentNote:
+			links.WriteString("📝 " + a.Content + "\n")
+		case models.AttachmentLink:
+			links.WriteString("🔗 " + a.Content + "\n")
+		}
+	}
+	header := i18n.T(loc, "attach.header", topic.Name)
+	if links.Len() > 0 {
+		header += "\n\n" + links.String()
+	}
+	if err := b.sendMessage(tgbotapi.NewMessage(chatID, header)); err != nil {
+		return err
+	}
+
+	for _, a := range attachments {
+		var file tgbotapi.Chattable
+		switch a.Kind {
+		case models.AttachmentPhoto:
+			photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(a.Content))
+			photo.Caption = a.Caption
+			file = photo
+		case models.AttachmentDocument:
+			doc := tgbotapi.NewDocument(chatID, tgbotapi.FileID(a.Content))
+			doc.Caption = a.Caption
+			file = doc
+		default:
+			continue
+		}
+		if _, err := b.dispatcher.Send(ctx, chatID, file); err != nil {
+			return fmt.Errorf("failed to send attachment %d: %w", a.ID, err)
+		}
+	}
+	return nil
+}
+
+// handleClearMaterials deletes all attachments of the user's topic
+func (b *Bot) handleClearMaterials(ctx context.Context, callback *tgbotapi.CallbackQuery, topicID int64) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	deleted, err := b.attachmentRepo.DeleteByTopic(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, i18n.T(loc, "attach.cleared", deleted))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{{{Text: i18n.T(loc, "button.done"), CallbackData: callbackAttachDone}}})
+	return b.sendMessage(msg)
+}
+
+// handleAttachDone stops waiting for attachments
+func (b *Bot) handleAttachDone(callback *tgbotapi.CallbackQuery) error {
+	b.states.remove(callback.From.ID)
+	loc := b.localeFor(context.Background(), callback.From.ID)
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, i18n.T(loc, "attach.done"))
+	msg.ReplyMarkup = createKeyboard(b.mainMenuButtons(loc))
+	return b.sendMessage(msg)
+}
+
+// materialsButton returns the button that sends the attachments of a topic
+func materialsButton(topicID int64, text string) MenuButton {
+	return MenuButton{Text: text, CallbackData: fmt.Sprintf("%s%d", callbackMaterialsPrefix, topicID)}
+}
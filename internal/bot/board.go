@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackBoardToggle turns the daily board on or off in the settings
+const callbackBoardToggle = "board_toggle"
+
+// maxBoardButtons is how many due topics get a "done" button on the board
+const maxBoardButtons = 10
+
+// handleBoardToggle turns the daily board on or off. Turning it on sends and pins the board
+// right away, turning it off unpins it.
+func (b *Bot) handleBoardToggle(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+
+	user.BoardEnabled = !user.BoardEnabled
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	text := i18n.T(loc, "board.off")
+	if user.BoardEnabled {
+		text = i18n.T(loc, "board.on")
+		if err := b.refreshBoard(ctx, user); err != nil {
+			return err
+		}
+	} else if user.BoardMessageID != 0 {
+		unpin := tgbotapi.UnpinChatMessageConfig{ChatID: user.TelegramID, MessageID: user.BoardMessageID}
+		if _, err := b.api.Request(unpin); err != nil {
+			logging.FromContext(ctx).Warn("failed to unpin board", "user_id", user.ID, "error", err)
+		}
+		if err := b.userRepo.SetBoardMessageID(ctx, user.ID, 0); err != nil {
+			return err
+		}
+	}
+
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+	msg.ReplyMarkup = createKeyboard(b.settingsMenuButtons(loc))
+	return b.sendMessage(msg)
+}
+
+// refreshBoard brings the user's board up to date. The board is edited in place; if it was
+// never sent or can't be edited anymore, e.g. the user deleted it, a new one is sent and pinned.
+func (b *Bot) refreshBoard(ctx context.Context, user *models.User) error {
+	due, err := b.repetitionRepo.GetDueRepetitionsForNotification(ctx, user.ID, user.SkipFirstRepetitions)
+	if err != nil {
+		return err
+	}
+	now := b.clock.Now()
+	done, err := b.repetitionRepo.GetCompletedSince(ctx, user.ID, startOfDay(now))
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	text := boardText(loc, now, due, done)
+	keyboard := boardKeyboard(loc, due)
+
+	if user.BoardMessageID != 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(user.TelegramID, user.BoardMessageID, text, keyboard)
+		_, err := b.dispatcher.Send(ctx, user.TelegramID, edit)
+		if err == nil || strings.Contains(err.Error(), "message is not modified") {
+			return nil
+		}
+		logging.FromContext(ctx).Info("board can't be edited, sending a new one", "user_id", user.ID, "error", err)
+	}
+
+	msg := tgbotapi.NewMessage(user.TelegramID, text)
+	msg.ReplyMarkup = keyboard
+	sent, err := b.dispatcher.Send(ctx, user.TelegramID, msg)
+	if err != nil {
+		return fmt.Errorf("failed to send board: %w", err)
+	}
+	if err := b.userRepo.SetBoardMessageID(ctx, user.ID, sent.MessageID); err != nil {
+		return err
+	}
+	user.BoardMessageID = sent.MessageID
+
+	pin := tgbotapi.PinChatMessageConfig{ChatID: user.TelegramID, MessageID: sent.MessageID, DisableNotification: true}
+	if _, err := b.api.Request(pin); err != nil {
+		logging.FromContext(ctx).Warn("failed to pin board", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+// refreshBoardAfterReview updates the board of a user who has one after a completed repetition
+func (b *Bot) refreshBoardAfterReview(ctx context.Context, user *models.User) {
+	if !user.BoardEnabled {
+		return
+	}
+	if err := b.refreshBoard(ctx, user); err != nil {
+		logging.FromContext(ctx).Warn("failed to refresh board", "user_id", user.ID, "error", err)
+	}
+}
+
+// boardText renders the board: the topics left for today and the ones done today
+func boardText(loc locale.Locale, now time.Time, due, done []models.Repetition) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "board.title", now.Format("02.01")))
+	if len(due) == 0 {
+		text.WriteString(i18n.T(loc, "board.all_done") + "\n")
+	} else {
+		text.WriteString(i18n.T(loc, "board.left", len(due)))
+		for _, rep := range due {
+			text.WriteString(fmt.Sprintf("⬜️ %s - %s\n", rep.TopicName, loc.Repetition(rep.RepetitionNumber)))
+		}
+	}
+	if len(done) > 0 {
+		text.WriteString(i18n.T(loc, "board.done", len(done)))
+		for _, rep := range done {
+			text.WriteString(fmt.Sprintf("✅ %s - %s\n", rep.TopicName, loc.Repetition(rep.RepetitionNumber)))
+		}
+	}
+	return text.String()
+}
+
+// boardKeyboard returns a "done" button per due topic. The board has no menu buttons:
+// they would replace the pinned message with a menu.
+func boardKeyboard(loc locale.Locale, due []models.Repetition) tgbotapi.InlineKeyboardMarkup {
+	if len(due) == 0 {
+		// An empty keyboard, not a missing one, so the edit removes the old buttons
+		return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	}
+	var rows [][]MenuButton
+	for _, rep := range due[:min(len(due), maxBoardButtons)] {
+		rows = append(rows, []MenuButton{{
+			Text:         reminderButtonText(loc, rep.TopicName),
+			CallbackData: fmt.Sprintf("complete_%d", rep.ID),
+		}})
+	}
+	return createKeyboard(rows)
+}
+
+// boardToggleButton returns the settings button that turns the board on or off
+func boardToggleButton(loc locale.Locale, enabled bool) MenuButton {
+	if enabled {
+		return MenuButton{Text: i18n.T(loc, "board.off_button"), CallbackData: callbackBoardToggle}
+	}
+	return MenuButton{Text: i18n.T(loc, "board.on_button"), CallbackData: callbackBoardToggle}
+}
+
+// startOfDay returns the midnight the day of t starts at
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
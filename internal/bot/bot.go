@@ -2,16 +2,28 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/example/engbot/internal/ai"
+	"github.com/example/engbot/internal/clock"
 	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/enrichment"
+	"github.com/example/engbot/internal/excel"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
 	"github.com/example/engbot/internal/scheduler"
+	"github.com/example/engbot/internal/service"
+	"github.com/example/engbot/internal/spaced_repetition"
+	"github.com/example/engbot/internal/tts"
 	"github.com/example/engbot/pkg/models"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/joho/godotenv"
@@ -20,7 +32,7 @@ import (
 func init() {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
-		log.Printf("Warning: .env file not found or error loading it: %v", err)
+		slog.Warn(".env file not loaded", "error", err)
 	}
 }
 
@@ -49,12 +61,40 @@ type Bot struct {
 	token             string
 	schedulerEnabled  bool
 	scheduler         *scheduler.Scheduler
+	clock             clock.Clock
+	config            *BotConfig
 	mu               sync.RWMutex
+	broadcasting     bool // an admin /broadcast is being delivered, guarded by mu
+	ready            atomic.Bool // updates are being received, reported by /readyz
+	dispatcher       *dispatcher
 	
 	userRepo          *database.UserRepository
 	topicRepo         *database.TopicRepository
 	repetitionRepo    *database.RepetitionRepository
 	statsRepo         *database.StatisticsRepository
+	wordRepo          *database.WordRepository
+	progressRepo      *database.UserProgressRepository
+	deckRepo          *database.DeckRepository
+	wordOfDayRepo     *database.WordOfDayRepository
+	activityRepo      *database.ActivityRepository
+	testResultRepo    *database.TestResultRepository
+	quizzes           *quizSessions
+	practices         *practiceSessions
+	states            *userStates
+	languageModel     ai.Provider          // nil when no language model is configured
+	enricher          *enrichment.Enricher // nil without a language model
+	speech            tts.Synthesizer      // nil without OPENAI_API_KEY
+	transcriber       ai.Transcriber       // nil without OPENAI_API_KEY
+	pronunciationRepo *database.PronunciationRepository
+	notificationRepo  *database.NotificationRepository
+	attachmentRepo    *database.TopicAttachmentRepository
+	searchRepo        *database.SearchRepository
+	calendarRepo      *database.CalendarRepository
+	channelRepo       *database.NotificationChannelRepository
+	deliveryRepo      *database.DeliveryRepository
+	exporter          *excel.Exporter
+	sm2               *spaced_repetition.SM2
+	repetitions       *service.RepetitionService
 }
 
 // NewBot creates a new bot instance
@@ -63,17 +103,62 @@ func NewBot(token string) (*Bot, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot API: %w", err)
 	}
+	return newBot(api, token, clock.System{}), nil
+}
+
+// newBot wires the bot to the API client and the repositories, reading the time from clk
+func newBot(api *tgbotapi.BotAPI, token string, clk clock.Clock) *Bot {
+	var err error
+	sm2 := spaced_repetition.NewSM2()
+	sm2.Clock = clk
 
-	return &Bot{
+	config := DefaultConfig()
+	b := &Bot{
 		api:               api,
 		token:             token,
 		schedulerEnabled:  os.Getenv("ENABLE_SCHEDULER") != "false",
+		clock:             clk,
+		config:            config,
 		mu:               sync.RWMutex{},
 		userRepo:          database.NewUserRepository(),
-		topicRepo:         database.NewTopicRepository(),
-		repetitionRepo:    database.NewRepetitionRepository(),
+		topicRepo:         database.NewTopicRepositoryWithClock(clk),
+		repetitionRepo:    database.NewRepetitionRepositoryWithClock(clk),
 		statsRepo:         database.NewStatisticsRepository(),
-	}, nil
+		wordRepo:          database.NewWordRepository(),
+		progressRepo:      database.NewUserProgressRepository(),
+		deckRepo:          database.NewDeckRepository(),
+		wordOfDayRepo:     database.NewWordOfDayRepository(),
+		activityRepo:      database.NewActivityRepositoryWithClock(clk),
+		testResultRepo:    database.NewTestResultRepository(),
+		pronunciationRepo: database.NewPronunciationRepository(),
+		notificationRepo:  database.NewNotificationRepositoryWithClock(clk),
+		attachmentRepo:    database.NewTopicAttachmentRepository(),
+		searchRepo:        database.NewSearchRepository(),
+		calendarRepo:      database.NewCalendarRepository(),
+		channelRepo:       database.NewNotificationChannelRepository(),
+		deliveryRepo:      database.NewDeliveryRepositoryWithClock(clk),
+		quizzes:           newQuizSessions(),
+		practices:         newPracticeSessions(),
+		states:            newUserStates(),
+		exporter:          excel.NewExporter(),
+		sm2:               sm2,
+		repetitions:       service.NewRepetitionService(clk, sm2),
+	}
+	if b.languageModel, err = ai.New(config.AI); err != nil {
+		slog.Default().Warn("stories are disabled", "error", err)
+	}
+	if config.AI.OpenAIAPIKey != "" {
+		b.speech = tts.NewOpenAI(config.AI.OpenAIAPIKey, config.TTSModel, config.TTSVoice)
+		b.transcriber = ai.NewWhisper(config.AI.OpenAIAPIKey, "")
+	}
+	if b.languageModel != nil {
+		b.enricher = enrichment.New(b.languageModel, b.wordRepo, config.EnrichWorkers, config.EnrichRate)
+	}
+	// Отправка идет через b.api, который Start заменяет на новый клиент
+	b.dispatcher = newDispatcher(func(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+		return b.api.Send(c)
+	}, config.MessagesPerSecond)
+	return b
 }
 
 // safeGoroutine выполняет функцию в горутине с восстановлением после паники
@@ -81,9 +166,7 @@ func safeGoroutine(f func()) {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("Recovered from panic in goroutine: %v", r)
-				// Можно добавить stack trace для отладки
-				debug.PrintStack()
+				slog.Error("recovered from panic in goroutine", "panic", r, "stack", string(debug.Stack()))
 			}
 		}()
 		f()
@@ -92,7 +175,7 @@ func safeGoroutine(f func()) {
 
 // Start initializes and starts the bot
 func (b *Bot) Start(ctx context.Context) error {
-	log.Println("Starting bot initialization...")
+	slog.Info("starting bot")
 
 	// Initialize the bot with the given token
 	botAPI, err := tgbotapi.NewBotAPI(b.token)
@@ -100,48 +183,34 @@ func (b *Bot) Start(ctx context.Context) error {
 		return fmt.Errorf("unable to create bot: %w", err)
 	}
 	
+	b.mu.Lock()
 	b.api = botAPI
-	log.Printf("Authorized on account %s (ID: %d)", botAPI.Self.UserName, botAPI.Self.ID)
-
-	// Set up bot commands menu
-	commands := []tgbotapi.BotCommand{
-		{Command: "start", Description: "🚀 Запустить бота"},
-		{Command: "add", Description: "📝 Добавить новую тему"},
-		{Command: "list", Description: "📋 Список всех тем"},
-		{Command: "delete", Description: "🗑 Удалить тему"},
-		{Command: "stats", Description: "📊 Статистика"},
-		{Command: "notify", Description: "🔔 Вкл/выкл уведомления"},
-		{Command: "time", Description: "🕒 Время уведомлений"},
-		{Command: "help", Description: "❓ Помощь"},
-	}
-
-	// Set commands for the menu button
-	log.Println("Setting up bot commands menu...")
-	cmdConfig := tgbotapi.NewSetMyCommands(commands...)
-	if _, err := b.api.Request(cmdConfig); err != nil {
-		log.Printf("Warning: Failed to set bot commands menu: %v", err)
-	} else {
-		log.Printf("Successfully set up bot commands menu")
+	b.mu.Unlock()
+	slog.Info("authorized", "account", botAPI.Self.UserName, "bot_id", botAPI.Self.ID)
+
+	// Set up bot commands menu, in the default language and in each interface language for the
+	// users whose Telegram app uses it
+	b.setCommands(b.config.Locale, "")
+	for _, lang := range i18n.Languages {
+		b.setCommands(lang.Locale, string(lang.Locale))
 	}
 	
-	// Set up the update configuration
-	log.Println("Configuring updates...")
-	updateConfig := tgbotapi.NewUpdate(0)
-	updateConfig.Timeout = 60
-	
-	// Get updates channel
-	updates := b.api.GetUpdatesChan(updateConfig)
-	log.Println("Successfully started receiving updates")
+	// Get updates channel (webhook or long polling)
+	updates := b.receiveUpdates(ctx)
 	
 	// Start goroutine to handle scheduled reminders
 	if b.schedulerEnabled {
-		log.Println("Starting scheduler...")
 		if err := b.scheduleReminders(ctx); err != nil {
 			return fmt.Errorf("failed to schedule reminders: %w", err)
 		}
-		log.Println("Scheduler started successfully")
+		slog.Info("scheduler started")
 	} else {
-		log.Println("Scheduler is disabled")
+		slog.Info("scheduler is disabled")
+	}
+
+	// Fill in the imported words without examples, including the ones left from the last run
+	if b.enricher != nil {
+		safeGoroutine(func() { b.enricher.Run(ctx) })
 	}
 	
 	// Wait for termination signal in a separate goroutine
@@ -150,55 +219,89 @@ func (b *Bot) Start(ctx context.Context) error {
 		errChan <- b.waitForTermination(ctx)
 	})
 	
-	log.Println("Bot is ready to handle messages")
+	b.ready.Store(true)
+	defer b.ready.Store(false)
+	slog.Info("bot is ready to handle messages")
 	
-	// Handle incoming updates
-	for update := range updates {
+	// Handle incoming updates until the context is cancelled or the channel is closed
+	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Context cancelled, stopping bot...")
+			slog.Info("context cancelled, stopping bot")
 			return ctx.Err()
 		case err := <-errChan:
-			log.Printf("Termination signal received: %v", err)
+			slog.Info("termination signal received", "error", err)
 			return fmt.Errorf("termination error: %w", err)
-		default:
-			update := update // Create a new variable for the goroutine
+		case update, ok := <-updates:
+			if !ok {
+				slog.Info("update loop finished")
+				return nil
+			}
 			safeGoroutine(func() {
+				ctx := updateContext(ctx, update)
 				if err := b.handleUpdate(ctx, update); err != nil {
-					log.Printf("Error handling update [ID: %d]: %v", update.UpdateID, err)
+					logging.FromContext(ctx).Error("failed to handle update", "error", err)
 				}
 			})
 		}
 	}
-	
-	log.Println("Update loop finished")
-	return nil
 }
 
 // Stop gracefully stops the bot
 func (b *Bot) Stop(ctx context.Context) error {
+	b.ready.Store(false)
+
 	// Stop the scheduler
 	if b.schedulerEnabled && b.scheduler != nil {
 		b.scheduler.Stop()
 	}
 	
-	log.Println("Bot stopped successfully")
+	slog.Info("bot stopped")
+	return nil
+}
+
+// CheckTelegram calls getMe to make sure the Telegram Bot API is reachable with the bot's token
+func (b *Bot) CheckTelegram(ctx context.Context) error {
+	b.mu.RLock()
+	api := b.api
+	b.mu.RUnlock()
+
+	// Клиент tgbotapi не принимает контекст, поэтому ждем ответа не дольше ctx
+	result := make(chan error, 1)
+	go func() {
+		_, err := api.GetMe()
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			return fmt.Errorf("failed to call getMe: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("getMe timed out: %w", ctx.Err())
+	}
+}
+
+// CheckReady reports an error until the bot receives updates and after it has stopped
+func (b *Bot) CheckReady(ctx context.Context) error {
+	if !b.ready.Load() {
+		return fmt.Errorf("bot is not receiving updates")
+	}
 	return nil
 }
 
 // scheduleReminders sets up scheduled reminder jobs
 func (b *Bot) scheduleReminders(ctx context.Context) error {
-	log.Println("Starting reminder scheduler...")
-	
 	// Create scheduler with current bot as Notifier
-	b.scheduler = scheduler.New(b)
+	b.scheduler = scheduler.NewWithClock(b, b.clock, b.config.Scheduler)
 	
 	// Start scheduler
 	if err := b.scheduler.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start scheduler: %w", err)
 	}
 	
-	log.Println("Reminder scheduler started successfully")
 	return nil
 }
 
@@ -208,6 +311,15 @@ func (b *Bot) waitForTermination(ctx context.Context) error {
 	return ctx.Err()
 }
 
+// updateContext attaches a logger that tags every line about the update with a request ID
+func updateContext(ctx context.Context, update tgbotapi.Update) context.Context {
+	logger := slog.Default().With("request_id", logging.NewRequestID(), "update_id", update.UpdateID)
+	if from := update.SentFrom(); from != nil {
+		logger = logger.With("telegram_id", from.ID)
+	}
+	return logging.WithLogger(ctx, logger)
+}
+
 // handleUpdate processes incoming updates from Telegram
 func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) error {
 	if update.Message != nil {
@@ -218,35 +330,91 @@ func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) error {
 			return nil
 		}
 
-		log.Printf("Received message from user %d: %s", update.Message.From.ID, update.Message.Text)
+		logging.FromContext(ctx).Debug("received message", "text", update.Message.Text)
 
 		// Handle commands
 		if update.Message.IsCommand() {
 			return b.HandleCommand(ctx, update.Message)
 		}
+
+		// Links, photos and documents sent after /attach are study material of a topic
+		if state, ok := b.states.get(update.Message.From.ID); ok && state.Action == actionAddingAttachment {
+			return b.handleAttachmentMessage(ctx, update.Message)
+		}
+
+		// Files are Anki decks to import
+		if update.Message.Document != nil {
+			return b.handleDocument(ctx, update.Message)
+		}
+
+		// Voice messages answer /quiz pronunciation questions
+		if update.Message.Voice != nil {
+			return b.handleQuizVoiceAnswer(ctx, update.Message)
+		}
 		
 		// Handle text messages based on user state
-		if state, exists := userStates[update.Message.From.ID]; exists {
-			log.Printf("Found user state: %+v", state)
+		if state, exists := b.states.get(update.Message.From.ID); exists {
+			logging.FromContext(ctx).Debug("found user state", "action", state.Action, "step", state.Step)
 			switch state.Action {
-			case "adding_topic":
-				return b.handleAddTopicText(update.Message)
+			case "adding_topic", "confirm_similar_topic":
+				return b.handleAddTopicText(ctx, update.Message)
+			case actionEditingTopic:
+				return b.handleEditTopicText(ctx, update.Message)
+			case actionEditingWord:
+				return b.handleEditWordText(ctx, update.Message)
+			case actionEditingIntervals:
+				return b.handleIntervalsText(ctx, update.Message)
+			case actionEditingTopicIntervals:
+				return b.handleTopicIntervalsText(ctx, update.Message)
+			case actionAddingNote:
+				return b.handleNoteText(ctx, update.Message)
+			case actionBulkCategory:
+				return b.handleBulkCategoryText(ctx, update.Message)
+			case actionBulkSelecting:
+				loc := b.localeFor(ctx, update.Message.From.ID)
+				return b.sendMessage(tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(loc, "state.bulk_selecting")))
+			case actionConfirmBroadcast:
+				loc := b.localeFor(ctx, update.Message.From.ID)
+				return b.sendMessage(tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(loc, "state.confirm_broadcast")))
+			case actionReviewingWord:
+				loc := b.localeFor(ctx, update.Message.From.ID)
+				return b.sendMessage(tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(loc, "state.reviewing_word")))
 			default:
-				log.Printf("Unknown action in user state: %s", state.Action)
-				msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Пожалуйста, используйте команды из меню для взаимодействия с ботом.")
-				msg.ReplyMarkup = createKeyboard(b.MainMenuButtons())
+				logging.FromContext(ctx).Warn("unknown action in user state", "action", state.Action)
+				loc := b.localeFor(ctx, update.Message.From.ID)
+				msg := tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(loc, "state.use_menu"))
+				msg.ReplyMarkup = createKeyboard(b.mainMenuButtons(loc))
 				return b.sendMessage(msg)
 			}
 		}
 
-		log.Printf("No state found for user %d", update.Message.From.ID)
+		// Typed answers of a /quiz test
+		if s := b.quizzes.get(update.Message.From.ID); s != nil && s.typed() {
+			return b.handleQuizTextAnswer(ctx, update.Message)
+		} else if s != nil && s.spoken() {
+			loc := b.localeFor(ctx, update.Message.From.ID)
+			return b.sendMessage(tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(loc, "state.spoken_quiz")))
+		}
+
+		// Messages of a /practice conversation
+		if b.practices.get(update.Message.From.ID) != nil {
+			return b.handlePracticeMessage(ctx, update.Message)
+		}
+
 		// For users without state, show the main menu
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Пожалуйста, используйте команды из меню для взаимодействия с ботом.")
-		msg.ReplyMarkup = createKeyboard(b.MainMenuButtons())
+		loc := b.localeFor(ctx, update.Message.From.ID)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(loc, "state.use_menu"))
+		msg.ReplyMarkup = createKeyboard(b.mainMenuButtons(loc))
 		return b.sendMessage(msg)
 	} else if update.CallbackQuery != nil {
 		// Handle button callbacks
 		return b.HandleCallback(ctx, update.CallbackQuery)
+	} else if update.InlineQuery != nil {
+		// Handle "@bot word" lookups from any chat
+		return b.handleInlineQuery(ctx, update.InlineQuery)
+	} else if update.PollAnswer != nil {
+		// Answers to /quiz questions sent as polls
+		return b.handleQuizPollAnswer(ctx, update.PollAnswer)
 	}
 
 	return nil
@@ -256,70 +424,123 @@ func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) error {
 func (b *Bot) SendReminders(userID int64, count int) error {
 	ctx := context.Background()
 	// Check if user exists
-	_, err := b.userRepo.GetByTelegramID(ctx, userID)
+	user, err := b.userRepo.GetByTelegramID(ctx, userID)
 	if err != nil {
-		log.Printf("Error getting user %d: %v", userID, err)
+		slog.Error("failed to get user", "telegram_id", userID, "error", err)
 		return err
 	}
+	if user != nil && user.BoardEnabled {
+		return b.refreshBoard(ctx, user)
+	}
 
 	chatID := userID
 
-	// Format message based on word count
-	wordForm := "слов"
-	if count == 1 {
-		wordForm = "слово"
-	} else if count > 1 && count < 5 {
-		wordForm = "слова"
+	send := func() error {
+		if user != nil && user.DigestEnabled {
+			return b.sendDigest(ctx, user)
+		}
+		loc := b.userLocale(user)
+		text := i18n.T(loc, "reminder.count", loc.Words(count))
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ReplyMarkup = createKeyboard(b.mainMenuButtons(loc))
+		if user == nil {
+			return b.sendMessage(msg)
+		}
+		if err := b.deliver(ctx, user, "reminder", msg); err != nil {
+			return err
+		}
+		b.notifyChannels(ctx, user, scheduler.Notification{
+			Kind:    "reminder",
+			Subject: i18n.T(loc, "reminder.subject"),
+			Text:    text + "\n" + b.channelFooter(loc),
+		})
+		return nil
+	}
+	if user == nil {
+		return send()
 	}
+	return b.notifyOnce(ctx, user, database.ReminderNotification(b.clock.Now().Hour()), send)
+}
 
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("У вас %d %s для повторения! Откройте список тем, чтобы начать повторение.", count, wordForm))
-	msg.ReplyMarkup = createKeyboard(b.MainMenuButtons())
-	return b.sendMessage(msg)
+// menuCommands are the commands of the menu button, in display order. Their descriptions are
+// the "command.<name>" messages.
+var menuCommands = []string{
+	"start", "add", "addmany", "list", "delete", "edit", "difficulty", "restartall", "history",
+	"maintenance", "topicintervals", "attach", "move", "merge", "describe", "search", "archive",
+	"review", "app", "stats", "export", "anki", "decks", "plans", "goal", "quiz", "cram", "hard",
+	"story", "practice", "wordofday", "notify", "time", "channels", "skipfirst", "overdue", "load",
+	"newwords", "direction", "forecast", "calendar", "report", "intervals", "language", "news", "help",
 }
 
-// MainMenuButtons returns the buttons for the main menu
-func (b *Bot) MainMenuButtons() [][]MenuButton {
+// setCommands sets the menu commands described in loc for the users whose Telegram app uses the
+// language code, or for everyone else when it is empty
+func (b *Bot) setCommands(loc locale.Locale, languageCode string) {
+	commands := make([]tgbotapi.BotCommand, len(menuCommands))
+	for i, name := range menuCommands {
+		commands[i] = tgbotapi.BotCommand{Command: name, Description: i18n.T(loc, "command."+name)}
+	}
+	cmdConfig := tgbotapi.NewSetMyCommandsWithScopeAndLanguage(tgbotapi.NewBotCommandScopeDefault(), languageCode, commands...)
+	if _, err := b.api.Request(cmdConfig); err != nil {
+		slog.Warn("failed to set bot commands menu", "language", languageCode, "error", err)
+	} else {
+		slog.Debug("bot commands menu set", "language", languageCode)
+	}
+}
+
+// mainMenuButtons returns the buttons for the main menu in the given language
+func (b *Bot) mainMenuButtons(loc locale.Locale) [][]MenuButton {
 	buttons := [][]MenuButton{
 		{
-			{Text: "📚 Управление темами", CallbackData: "topics_menu"},
+			{Text: i18n.T(loc, "menu.main.topics"), CallbackData: "topics_menu"},
 		},
 		{
-			{Text: "📊 Статистика", CallbackData: "stats"},
-			{Text: "⚙️ Настройки", CallbackData: "settings"},
+			{Text: i18n.T(loc, "menu.main.stats"), CallbackData: "stats"},
+			{Text: i18n.T(loc, "menu.main.settings"), CallbackData: "settings"},
 		},
 		{
-			{Text: "❓ Помощь", CallbackData: "help"},
+			{Text: i18n.T(loc, "menu.main.help"), CallbackData: "help"},
 		},
 	}
 	return buttons
 }
 
-// TopicsMenuButtons returns the buttons for the topics submenu
-func (b *Bot) TopicsMenuButtons() [][]MenuButton {
+// topicsMenuButtons returns the buttons for the topics submenu in the given language
+func (b *Bot) topicsMenuButtons(loc locale.Locale) [][]MenuButton {
 	buttons := [][]MenuButton{
 		{
-			{Text: "📝 Добавить тему", CallbackData: callbackStartAddTopic},
-			{Text: "📋 Список тем", CallbackData: "list_topics"},
+			{Text: i18n.T(loc, "button.add_topic"), CallbackData: callbackStartAddTopic},
+			{Text: i18n.T(loc, "button.topic_list"), CallbackData: "list_topics"},
+		},
+		{
+			{Text: i18n.T(loc, "button.delete_topic"), CallbackData: "delete_topic"},
+			{Text: i18n.T(loc, "button.bulk"), CallbackData: callbackBulkMenu},
 		},
 		{
-			{Text: "🗑 Удалить тему", CallbackData: "delete_topic"},
+			{Text: i18n.T(loc, "button.archive"), CallbackData: callbackArchiveMenu},
 		},
 		{
-			{Text: "⬅️ Назад в меню", CallbackData: "main_menu"},
+			{Text: i18n.T(loc, "button.back_to_menu"), CallbackData: "main_menu"},
 		},
 	}
 	return buttons
 }
 
-// SettingsMenuButtons returns the buttons for the settings submenu
-func (b *Bot) SettingsMenuButtons() [][]MenuButton {
+// settingsMenuButtons returns the buttons for the settings submenu in the given language
+func (b *Bot) settingsMenuButtons(loc locale.Locale) [][]MenuButton {
 	buttons := [][]MenuButton{
 		{
-			{Text: "🔔 Уведомления", CallbackData: "notifications_settings"},
-			{Text: "🕒 Время уведомлений", CallbackData: "time_settings"},
+			{Text: i18n.T(loc, "button.notifications"), CallbackData: "notifications_settings"},
+			{Text: i18n.T(loc, "button.notification_time"), CallbackData: "time_settings"},
+		},
+		{
+			{Text: i18n.T(loc, "button.digest"), CallbackData: callbackDigestToggle},
+			{Text: i18n.T(loc, "button.board"), CallbackData: callbackBoardToggle},
 		},
 		{
-			{Text: "⬅️ Назад в меню", CallbackData: "main_menu"},
+			{Text: i18n.T(loc, "button.scheduler"), CallbackData: callbackSchedulerToggle},
+		},
+		{
+			{Text: i18n.T(loc, "button.back_to_menu"), CallbackData: "main_menu"},
 		},
 	}
 	return buttons
@@ -333,17 +554,21 @@ func (b *Bot) sendMessage(msg tgbotapi.MessageConfig) error {
 		return fmt.Errorf("cannot send empty message: message text is required")
 	}
 
-	// Add main menu buttons if no other markup is specified
+	// Add main menu buttons if no other markup is specified. Private chats have the ID of
+	// the user, so the menu comes in their language.
 	if msg.ReplyMarkup == nil {
-		msg.ReplyMarkup = createKeyboard(b.MainMenuButtons())
+		msg.ReplyMarkup = createKeyboard(b.mainMenuButtons(b.localeFor(context.Background(), msg.ChatID)))
 	}
 
 	// Set the cleaned text back
 	msg.Text = text
 
 	// Try to send message
-	_, err := b.api.Send(msg)
+	_, err := b.dispatcher.Send(context.Background(), msg.ChatID, msg)
 	if err != nil {
+		if isBlockedError(err) {
+			b.markInactive(context.Background(), msg.ChatID, err)
+		}
 		return fmt.Errorf("failed to send message: %w", err)
 	}
 	return nil
@@ -361,7 +586,7 @@ func (b *Bot) editMessage(msg tgbotapi.EditMessageTextConfig) error {
 	msg.Text = text
 
 	// Try to edit message
-	_, err := b.api.Send(msg)
+	_, err := b.dispatcher.Send(context.Background(), msg.ChatID, msg)
 	if err != nil {
 		// If editing fails, try sending a new message
 		newMsg := tgbotapi.NewMessage(msg.ChatID, text)
@@ -369,25 +594,32 @@ func (b *Bot) editMessage(msg tgbotapi.EditMessageTextConfig) error {
 			newMsg.ReplyMarkup = msg.ReplyMarkup
 		} else {
 			// Add main menu buttons if no other markup is specified
-			newMsg.ReplyMarkup = createKeyboard(b.MainMenuButtons())
+			newMsg.ReplyMarkup = createKeyboard(b.mainMenuButtons(b.localeFor(context.Background(), msg.ChatID)))
 		}
 		return b.sendMessage(newMsg)
 	}
 	return nil
 }
 
-func (b *Bot) handleAddTopicText(message *tgbotapi.Message) error {
+func (b *Bot) handleAddTopicText(ctx context.Context, message *tgbotapi.Message) error {
 	if message == nil || message.From == nil || message.Chat == nil {
 		return fmt.Errorf("invalid message: missing required fields")
 	}
 
+	loc := b.localeFor(ctx, message.From.ID)
 	topicName := strings.TrimSpace(message.Text)
 	if topicName == "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "❌ Название темы не может быть пустым. Пожалуйста, отправьте название темы или нажмите кнопку \"Отмена\".")
-		return b.sendMessage(msg)
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "add.empty")))
 	}
 
-	ctx := context.Background()
+	// A pasted list creates a topic per line
+	if strings.Contains(topicName, "\n") {
+		return b.addTopics(ctx, message, topicName)
+	}
+	topicName, firstReviewDays, err := parseFirstReview(loc, topicName)
+	if err != nil {
+		return err
+	}
 
 	// Создаем или получаем пользователя
 	user, err := b.userRepo.GetByTelegramID(ctx, message.From.ID)
@@ -400,91 +632,113 @@ func (b *Bot) handleAddTopicText(message *tgbotapi.Message) error {
 			LastName:            message.From.LastName,
 			NotificationEnabled: true,
 			NotificationHour:    9,
+			OverduePolicy:       models.OverdueRemind,
+			OverdueDays:         models.DefaultOverdueDays,
+			ReportEnabled:       true,
+			ReportDay:           models.DefaultReportDay,
+			ReportHour:          models.DefaultReportHour,
 		}
 
 		if err := b.userRepo.Create(ctx, newUser); err != nil {
-			log.Printf("Ошибка создания пользователя: %v", err)
-			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, "❌ Не удалось создать профиль пользователя. Попробуйте еще раз."))
+			logging.FromContext(ctx).Error("failed to create user", "error", err)
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "error.create_profile")))
 		}
 
 		// Получаем созданного пользователя для получения его ID
 		user, err = b.userRepo.GetByTelegramID(ctx, message.From.ID)
 		if err != nil {
-			log.Printf("Ошибка получения пользователя после создания: %v", err)
-			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, "❌ Ошибка при получении данных пользователя. Попробуйте еще раз."))
+			logging.FromContext(ctx).Error("failed to get user after creation", "error", err)
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "error.read_profile")))
 		}
 
 		if user == nil || user.ID == 0 {
-			log.Printf("Пользователь не найден или ID = 0 для telegram_id %d", message.From.ID)
-			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, "❌ Ошибка: не удалось получить профиль пользователя"))
+			logging.FromContext(ctx).Error("user not found after creation")
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "error.no_profile")))
 		}
 	}
 
-	// Создаем тему
-	topic := &models.Topic{
-		Name:      topicName,
-		UserID:    user.ID,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	limitReached, err := b.topicLimitReached(ctx, user)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to check topic limit", "error", err)
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, userErrorMessage(loc, err)))
 	}
-
-	if err := b.topicRepo.Create(ctx, topic); err != nil {
-		log.Printf("Ошибка создания темы для пользователя %d (telegram_id %d): %v", user.ID, message.From.ID, err)
-		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, "❌ Не удалось создать тему. Попробуйте еще раз."))
+	if limitReached {
+		b.states.remove(message.From.ID)
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, b.topicLimitText(loc)))
 	}
 
-	if topic.ID == 0 {
-		log.Printf("Тема создана, но ID = 0 для пользователя %d", user.ID)
-		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, "❌ Ошибка при создании темы"))
+	if similar, err := b.topicRepo.FindSimilar(ctx, user.ID, topicName, similarTopicMaxDistance); err != nil {
+		logging.FromContext(ctx).Warn("failed to find similar topics", "error", err)
+	} else if similar != nil {
+		return b.askSimilarTopic(loc, message.Chat.ID, message.From.ID, topicName, firstReviewDays, similar)
 	}
 
-	// Создаем статистику для темы
-	stats := &models.Statistics{
-		UserID:  user.ID,
-		TopicID: topic.ID,
-	}
-	if err := b.statsRepo.Create(ctx, stats); err != nil {
-		log.Printf("Ошибка создания статистики: %v", err)
-		// Если не удалось создать статистику, удаляем тему
-		if delErr := b.topicRepo.Delete(ctx, user.ID, topic.ID); delErr != nil {
-			log.Printf("Ошибка удаления темы после неудачного создания статистики: %v", delErr)
+	return b.createTopic(ctx, message.Chat.ID, message.From.ID, user, topicName, firstReviewDays)
+}
+
+// createTopic creates a topic with its statistics and first repetition and reports the result.
+// The first review comes firstReviewDays from now, or by the user's intervals for
+// scheduledFirstReview.
+func (b *Bot) createTopic(ctx context.Context, chatID, telegramID int64, user *models.User, topicName string, firstReviewDays int) error {
+	loc := b.userLocale(user)
+	firstReview := b.clock.Now().AddDate(0, 0, firstReviewDays)
+	if firstReviewDays == scheduledFirstReview {
+		// Первое повторение назначается по графику интервалов пользователя
+		intervals, err := database.GetUserIntervals(ctx, user.ID)
+		if err != nil {
+			logging.FromContext(ctx).Warn("failed to get user intervals, using defaults", "user_id", user.ID, "error", err)
+			intervals = database.DefaultIntervals
 		}
-		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, "❌ Не удалось создать тему. Попробуйте еще раз."))
+		firstReview = b.spillDate(ctx, user, b.repetitionRepo.CalculateNextReviewDate(0, intervals))
 	}
 
-	// Создаем первое повторение
-	repetition := &models.Repetition{
-		UserID:           user.ID,
-		TopicID:          topic.ID,
-		RepetitionNumber: 1,
-		NextReviewDate:   time.Now().Add(24 * time.Hour),
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
+	// Тема, ее статистика и первое повторение создаются вместе или не создаются вовсе
+	topic := &models.Topic{
+		Name:      topicName,
+		UserID:    user.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}
-
-	if err := b.repetitionRepo.Create(ctx, repetition); err != nil {
-		log.Printf("Ошибка создания повторения: %v", err)
-		// Если не удалось создать повторение, удаляем тему
-		if delErr := b.topicRepo.Delete(ctx, user.ID, topic.ID); delErr != nil {
-			log.Printf("Ошибка удаления темы после неудачного создания повторения: %v", delErr)
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		if err := tx.CreateTopic(ctx, topic); err != nil {
+			return err
+		}
+		if err := tx.CreateStatistics(ctx, &models.Statistics{UserID: user.ID, TopicID: topic.ID}); err != nil {
+			return err
+		}
+		return tx.CreateRepetition(ctx, &models.Repetition{
+			UserID:           user.ID,
+			TopicID:          topic.ID,
+			RepetitionNumber: 1,
+			NextReviewDate:   firstReview,
+			CreatedAt:        time.Now(),
+			UpdatedAt:        time.Now(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrTopicExists) {
+			msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "add.exists", topicName))
+			msg.ReplyMarkup = createKeyboard([][]MenuButton{{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}}})
+			return b.sendMessage(msg)
 		}
-		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, "❌ Не удалось запланировать повторения. Попробуйте еще раз."))
+		logging.FromContext(ctx).Error("failed to create topic", "user_id", user.ID, "error", err)
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "add.failed")))
 	}
 
 	// Очищаем состояние пользователя
-	delete(userStates, message.From.ID)
+	b.states.remove(telegramID)
 
 	// Отправляем сообщение об успехе
-	text := fmt.Sprintf("✅ Тема \"%s\" успешно добавлена!\n\nТеперь вы можете:", topic.Name) +
-		"\n1. Добавить еще одну тему" +
-		"\n2. Посмотреть список всех тем" +
-		"\n3. Вернуться в главное меню"
+	text := newRichText(tgbotapi.ModeHTML).
+		Text(i18n.T(loc, "add.done.before")).Bold(topic.Name).Text(i18n.T(loc, "add.done.after")).
+		Text(i18n.T(loc, "add.done.first_review", firstReviewText(loc, firstReview, b.clock.Now()))).
+		Text(i18n.T(loc, "add.done.next"))
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg := text.Message(chatID)
 	msg.ReplyMarkup = createKeyboard([][]MenuButton{
-		{{Text: "📝 Добавить тему", CallbackData: callbackStartAddTopic}},
-		{{Text: "📋 Список тем", CallbackData: "list_topics"}},
-		{{Text: "⬅️ В меню", CallbackData: "main_menu"}},
+		{{Text: i18n.T(loc, "button.add_topic"), CallbackData: callbackStartAddTopic}},
+		{{Text: i18n.T(loc, "button.topic_list"), CallbackData: "list_topics"}},
+		{{Text: i18n.T(loc, "button.menu"), CallbackData: "main_menu"}},
 	})
 	return b.sendMessage(msg)
 } 
\ No newline at end of file
@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/internal/database/dbtest"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// testBot is a bot on a fresh database that records the messages instead of sending them.
+// Its API client talks to a local server that accepts every request.
+type testBot struct {
+	*Bot
+	clock *clock.Fake
+
+	mu   sync.Mutex
+	sent []tgbotapi.Chattable
+}
+
+func newTestBot(t *testing.T) *testBot {
+	t.Helper()
+	dbtest.Open(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Test","username":"test_bot"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	}))
+	t.Cleanup(server.Close)
+	api, err := tgbotapi.NewBotAPIWithClient("test", server.URL+"/bot%s/%s", server.Client())
+	if err != nil {
+		t.Fatalf("failed to create bot API: %v", err)
+	}
+
+	tb := &testBot{clock: clock.NewFake(time.Now().UTC().Truncate(time.Second))}
+	tb.Bot = newBot(api, "test", tb.clock)
+	tb.dispatcher = newDispatcher(func(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+		tb.mu.Lock()
+		defer tb.mu.Unlock()
+		tb.sent = append(tb.sent, c)
+		return tgbotapi.Message{MessageID: len(tb.sent)}, nil
+	}, 1000)
+	return tb
+}
+
+// texts returns the texts of the messages sent and edited so far
+func (tb *testBot) texts() []string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	var texts []string
+	for _, c := range tb.sent {
+		switch m := c.(type) {
+		case tgbotapi.MessageConfig:
+			texts = append(texts, m.Text)
+		case tgbotapi.EditMessageTextConfig:
+			texts = append(texts, m.Text)
+		}
+	}
+	return texts
+}
+
+// lastText returns the text of the last message sent or edited
+func (tb *testBot) lastText() string {
+	texts := tb.texts()
+	if len(texts) == 0 {
+		return ""
+	}
+	return texts[len(texts)-1]
+}
+
+// message is a text message from the Telegram user in the private chat with the bot
+func message(telegramID int64, text string) *tgbotapi.Message {
+	return &tgbotapi.Message{
+		Text: text,
+		From: &tgbotapi.User{ID: telegramID, FirstName: "Test"},
+		Chat: &tgbotapi.Chat{ID: telegramID, Type: "private"},
+	}
+}
+
+// callback is a button press by the Telegram user under a bot message in the private chat
+func callback(telegramID int64, data string) *tgbotapi.CallbackQuery {
+	return &tgbotapi.CallbackQuery{
+		ID:      "1",
+		Data:    data,
+		From:    &tgbotapi.User{ID: telegramID, FirstName: "Test"},
+		Message: &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: telegramID, Type: "private"}},
+	}
+}
@@ -0,0 +1,335 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data for the bulk actions screen
+const (
+	callbackBulkMenu          = "bulk_menu"
+	callbackBulkTogglePrefix  = "bulk_toggle_"
+	callbackBulkActionPrefix  = "bulk_do_"
+	callbackBulkConfirmDelete = "bulk_confirm_delete"
+)
+
+// User state actions of the bulk actions screen
+const (
+	actionBulkSelecting = "bulk_selecting"
+	actionBulkCategory  = "bulk_category"
+)
+
+// maxCategoryLength limits the category name entered for selected topics
+const maxCategoryLength = 50
+
+// bulkActions are the actions under the topic list with the catalog keys of their buttons, in display order
+var bulkActions = []struct {
+	Key    string
+	Action string
+}{
+	{Key: "bulk.delete", Action: "delete"},
+	{Key: "bulk.archive", Action: "archive"},
+	{Key: "bulk.mute", Action: "mute"},
+	{Key: "bulk.restore", Action: "restore"},
+	{Key: "bulk.reset", Action: "reset"},
+	{Key: "bulk.category", Action: "category"},
+}
+
+// handleBulkMenu opens the bulk actions screen with an empty selection
+func (b *Bot) handleBulkMenu(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	b.states.put(callback.From.ID, &UserState{
+		Action: actionBulkSelecting,
+		Step:   1,
+		Data:   map[string]string{"selected": ""},
+	})
+	return b.renderBulkMenu(ctx, callback, nil)
+}
+
+// handleBulkToggle adds the topic to the selection or removes it from there
+func (b *Bot) handleBulkToggle(ctx context.Context, callback *tgbotapi.CallbackQuery, topicID int64) error {
+	var selected []int64
+	_, ok := b.states.update(callback.From.ID, func(state *UserState) bool {
+		if state.Action != actionBulkSelecting {
+			return false
+		}
+		selected = toggleSelection(parseSelection(state.Data["selected"]), topicID)
+		state.Data["selected"] = formatSelection(selected)
+		return true
+	})
+	if !ok {
+		return &ValidationError{Message: i18n.T(b.localeFor(ctx, callback.From.ID), "bulk.reset_selection")}
+	}
+	return b.renderBulkMenu(ctx, callback, selected)
+}
+
+// handleBulkAction applies the chosen action to the selected topics
+func (b *Bot) handleBulkAction(ctx context.Context, callback *tgbotapi.CallbackQuery, action string) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	state, ok := b.states.get(callback.From.ID)
+	if !ok || state.Action != actionBulkSelecting {
+		return &ValidationError{Message: i18n.T(loc, "bulk.reset_selection")}
+	}
+	selected := parseSelection(state.Data["selected"])
+	if len(selected) == 0 {
+		return &ValidationError{Message: i18n.T(loc, "bulk.select_first")}
+	}
+
+	switch action {
+	case "delete":
+		text := i18n.T(loc, "bulk.confirm_delete", len(selected), int(b.config.UndoWindow.Minutes()))
+		msg := tgbotapi.NewEditMessageTextAndMarkup(
+			callback.Message.Chat.ID,
+			callback.Message.MessageID,
+			text,
+			createKeyboard([][]MenuButton{
+				{{Text: i18n.T(loc, "search.confirm_delete"), CallbackData: callbackBulkConfirmDelete}},
+				{{Text: i18n.T(loc, "bulk.back_to_selection"), CallbackData: callbackBulkActionPrefix + "back"}},
+			}),
+		)
+		return b.editMessage(msg)
+	case "back":
+		return b.renderBulkMenu(ctx, callback, selected)
+	case "category":
+		_, ok := b.states.update(callback.From.ID, func(state *UserState) bool {
+			if state.Action != actionBulkSelecting {
+				return false
+			}
+			state.Action = actionBulkCategory
+			return true
+		})
+		if !ok {
+			return &ValidationError{Message: i18n.T(loc, "bulk.reset_selection")}
+		}
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, i18n.T(loc, "bulk.ask_category"))
+		msg.ReplyMarkup = createKeyboard([][]MenuButton{
+			{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+		})
+		return b.sendMessage(msg)
+	}
+
+	var count int
+	var done string
+	switch action {
+	case "archive":
+		count, err = b.topicRepo.BulkSetArchived(ctx, user.ID, selected, true)
+		done = i18n.T(loc, "bulk.archived")
+	case "mute":
+		count, err = b.topicRepo.BulkSetMuted(ctx, user.ID, selected, true)
+		done = i18n.T(loc, "bulk.muted")
+	case "restore":
+		count, err = b.restoreTopics(ctx, user.ID, selected)
+		done = i18n.T(loc, "bulk.restored")
+	case "reset":
+		count, err = b.repetitionRepo.RestartTopics(ctx, user.ID, selected)
+		done = i18n.T(loc, "bulk.restarted")
+	default:
+		return &ValidationError{Message: i18n.T(loc, "stale.bulk")}
+	}
+	if err != nil {
+		return err
+	}
+
+	return b.finishBulkAction(loc, callback, fmt.Sprintf("%s: %d %s.", done, count, i18n.Plural(loc, "plural.topic", count)))
+}
+
+// handleBulkDeleteConfirm deletes the selected topics after confirmation
+func (b *Bot) handleBulkDeleteConfirm(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	state, ok := b.states.get(callback.From.ID)
+	if !ok || state.Action != actionBulkSelecting {
+		return &ValidationError{Message: i18n.T(loc, "bulk.reset_selection")}
+	}
+
+	count, trashID, err := b.topicRepo.BulkDelete(ctx, user.ID, parseSelection(state.Data["selected"]))
+	if err != nil {
+		return err
+	}
+
+	return b.finishBulkAction(loc, callback, i18n.T(loc, "bulk.deleted", count, i18n.Plural(loc, "plural.topic", count), b.undoHint(loc)),
+		undoDeleteButton(loc, trashID))
+}
+
+// handleBulkCategoryText moves the selected topics to the category from the message
+func (b *Bot) handleBulkCategoryText(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	state, _ := b.states.get(message.From.ID)
+	category := strings.TrimSpace(message.Text)
+	if category == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "bulk.category_empty")))
+	}
+	if category == "-" {
+		category = ""
+	}
+	if len([]rune(category)) > maxCategoryLength {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "bulk.category_too_long", maxCategoryLength)))
+	}
+
+	count, err := b.topicRepo.BulkSetCategory(ctx, user.ID, parseSelection(state.Data["selected"]), category)
+	if err != nil {
+		return err
+	}
+	b.states.remove(message.From.ID)
+
+	text := i18n.T(loc, "bulk.categorized", category, count, i18n.Plural(loc, "plural.topic", count))
+	if category == "" {
+		text = i18n.T(loc, "bulk.uncategorized", count, i18n.Plural(loc, "plural.topic", count))
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyMarkup = createKeyboard(b.topicsMenuButtons(loc))
+	return b.sendMessage(msg)
+}
+
+// restoreTopics takes the topics out of the archive and turns their reminders back on,
+// stalled topics included
+func (b *Bot) restoreTopics(ctx context.Context, userID int64, topicIDs []int64) (int, error) {
+	count, err := b.topicRepo.BulkSetArchived(ctx, userID, topicIDs, false)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := b.topicRepo.BulkSetMuted(ctx, userID, topicIDs, false); err != nil {
+		return 0, err
+	}
+	if _, err := b.topicRepo.BulkSetStalled(ctx, userID, topicIDs, false); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// finishBulkAction clears the selection and replaces the screen with the result
+func (b *Bot) finishBulkAction(loc locale.Locale, callback *tgbotapi.CallbackQuery, text string, rows ...[]MenuButton) error {
+	b.states.remove(callback.From.ID)
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		text,
+		createKeyboard(append(rows, b.topicsMenuButtons(loc)...)),
+	)
+	return b.editMessage(msg)
+}
+
+// renderBulkMenu shows the user's topics as toggle buttons followed by the actions
+func (b *Bot) renderBulkMenu(ctx context.Context, callback *tgbotapi.CallbackQuery, selected []int64) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(topics) == 0 {
+		b.states.remove(callback.From.ID)
+		msg := tgbotapi.NewEditMessageTextAndMarkup(
+			callback.Message.Chat.ID,
+			callback.Message.MessageID,
+			i18n.T(loc, "topics.none"),
+			createKeyboard(b.topicsMenuButtons(loc)),
+		)
+		return b.editMessage(msg)
+	}
+
+	text := i18n.T(loc, "bulk.title", len(selected))
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		text,
+		createKeyboard(bulkMenuButtons(loc, topics, selected)),
+	)
+	return b.editMessage(msg)
+}
+
+// bulkMenuButtons builds one toggle row per topic and the action rows below them
+func bulkMenuButtons(loc locale.Locale, topics []models.Topic, selected []int64) [][]MenuButton {
+	isSelected := make(map[int64]bool, len(selected))
+	for _, id := range selected {
+		isSelected[id] = true
+	}
+
+	var buttons [][]MenuButton
+	for _, topic := range topics {
+		mark := "⬜"
+		if isSelected[topic.ID] {
+			mark = "☑️"
+		}
+		label := fmt.Sprintf("%s %s", mark, topic.Name)
+		if topic.Archived {
+			label += " 📦"
+		}
+		if topic.Muted {
+			label += " 🔕"
+		}
+		buttons = append(buttons, []MenuButton{
+			{Text: label, CallbackData: fmt.Sprintf("%s%d", callbackBulkTogglePrefix, topic.ID)},
+		})
+	}
+
+	var row []MenuButton
+	for _, action := range bulkActions {
+		row = append(row, MenuButton{Text: i18n.T(loc, action.Key), CallbackData: callbackBulkActionPrefix + action.Action})
+		if len(row) == 2 {
+			buttons = append(buttons, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		buttons = append(buttons, row)
+	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "button.back_to_topics"), CallbackData: "topics_menu"}})
+	return buttons
+}
+
+// toggleSelection adds topicID to the selection or removes it if it's already there
+func toggleSelection(selected []int64, topicID int64) []int64 {
+	for i, id := range selected {
+		if id == topicID {
+			return append(selected[:i], selected[i+1:]...)
+		}
+	}
+	return append(selected, topicID)
+}
+
+// parseSelection reads the comma-separated topic IDs kept in the user state
+func parseSelection(value string) []int64 {
+	var ids []int64
+	for _, field := range strings.Split(value, ",") {
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// formatSelection is the inverse of parseSelection
+func formatSelection(ids []int64) string {
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(fields, ",")
+}
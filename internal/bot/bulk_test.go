@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/example/engbot/internal/database/dbtest"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// press sends the button press through the callback router and fails the test on an error
+func (tb *testBot) press(t *testing.T, telegramID int64, data string) {
+	t.Helper()
+	if err := tb.HandleCallback(context.Background(), callback(telegramID, data)); err != nil {
+		t.Fatalf("button %q: %v", data, err)
+	}
+}
+
+// topicsByName returns the user's topics, archived ones included, by name
+func (tb *testBot) topicsByName(t *testing.T, userID int64) map[string]models.Topic {
+	t.Helper()
+	topics, err := tb.topicRepo.GetAllByUserID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("failed to get topics: %v", err)
+	}
+	byName := make(map[string]models.Topic, len(topics))
+	for _, topic := range topics {
+		byName[topic.Name] = topic
+	}
+	return byName
+}
+
+func TestBulkCategory(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	const telegramID = 1000
+
+	user := dbtest.User(t, telegramID)
+	a := dbtest.Topic(t, user.ID, "Articles", 1, tb.clock.Now())
+	b := dbtest.Topic(t, user.ID, "Tenses", 1, tb.clock.Now())
+	dbtest.Topic(t, user.ID, "Phrasal verbs", 1, tb.clock.Now())
+
+	tb.press(t, telegramID, callbackBulkMenu)
+	tb.press(t, telegramID, fmt.Sprintf("%s%d", callbackBulkTogglePrefix, a.ID))
+	tb.press(t, telegramID, fmt.Sprintf("%s%d", callbackBulkTogglePrefix, b.ID))
+	tb.press(t, telegramID, callbackBulkActionPrefix+"category")
+	if state, ok := tb.states.get(telegramID); !ok || state.Action != actionBulkCategory {
+		t.Fatalf("state after the category button: %+v", state)
+	}
+
+	if err := tb.handleUpdate(ctx, tgbotapi.Update{Message: message(telegramID, "Грамматика")}); err != nil {
+		t.Fatalf("category name: %v", err)
+	}
+	topics := tb.topicsByName(t, user.ID)
+	for name, want := range map[string]string{"Articles": "Грамматика", "Tenses": "Грамматика", "Phrasal verbs": ""} {
+		if got := topics[name].Category; got != want {
+			t.Errorf("%s: category %q, want %q", name, got, want)
+		}
+	}
+	if _, ok := tb.states.get(telegramID); ok {
+		t.Error("selection kept after the action")
+	}
+}
+
+func TestBulkSelectAndArchive(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	const telegramID = 1100
+
+	user := dbtest.User(t, telegramID)
+	a := dbtest.Topic(t, user.ID, "Articles", 1, tb.clock.Now())
+	b := dbtest.Topic(t, user.ID, "Tenses", 1, tb.clock.Now())
+	c := dbtest.Topic(t, user.ID, "Phrasal verbs", 1, tb.clock.Now())
+	toggle := func(id int64) string { return fmt.Sprintf("%s%d", callbackBulkTogglePrefix, id) }
+
+	// Nothing selected yet
+	tb.press(t, telegramID, callbackBulkMenu)
+	var validation *ValidationError
+	if err := tb.handleBulkAction(ctx, callback(telegramID, ""), "archive"); !errors.As(err, &validation) {
+		t.Fatalf("archive with nothing selected: %v, want a validation error", err)
+	}
+
+	// A second press takes the topic off the selection
+	tb.press(t, telegramID, toggle(a.ID))
+	tb.press(t, telegramID, toggle(b.ID))
+	tb.press(t, telegramID, toggle(a.ID))
+	tb.press(t, telegramID, toggle(c.ID))
+	state, ok := tb.states.get(telegramID)
+	if !ok {
+		t.Fatal("selection lost")
+	}
+	if got, want := parseSelection(state.Data["selected"]), []int64{b.ID, c.ID}; !slices.Equal(got, want) {
+		t.Fatalf("selected %v, want %v", got, want)
+	}
+	if text := tb.lastText(); !strings.HasSuffix(text, "Выбрано: 2") {
+		t.Errorf("screen %q, want 2 selected", text)
+	}
+
+	tb.press(t, telegramID, callbackBulkActionPrefix+"archive")
+	topics := tb.topicsByName(t, user.ID)
+	for name, want := range map[string]bool{"Articles": false, "Tenses": true, "Phrasal verbs": true} {
+		if got := topics[name].Archived; got != want {
+			t.Errorf("%s: archived %v, want %v", name, got, want)
+		}
+	}
+	if text := tb.lastText(); text != "📦 Перенесено в архив: 2 темы." {
+		t.Errorf("result %q", text)
+	}
+	if _, ok := tb.states.get(telegramID); ok {
+		t.Error("selection kept after the action")
+	}
+
+	// The selection is gone, so a stale action button is refused
+	if err := tb.handleBulkAction(ctx, callback(telegramID, ""), "archive"); !errors.As(err, &validation) {
+		t.Errorf("archive after the selection was cleared: %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleCalendarCommand handles /calendar with the personal link to the feed of upcoming
+// repetitions and /calendar reset, which replaces a leaked link
+func (b *Bot) handleCalendarCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if b.config.APIPublicURL == "" {
+		return &ValidationError{Message: i18n.T(loc, "calendar.disabled")}
+	}
+
+	var token, text string
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "":
+		token, err = b.calendarRepo.Token(ctx, user.ID)
+		text = i18n.T(loc, "calendar.title")
+	case "reset":
+		token, err = b.calendarRepo.ResetToken(ctx, user.ID)
+		text = i18n.T(loc, "calendar.reset")
+	default:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "calendar.usage")))
+	}
+	if err != nil {
+		return err
+	}
+
+	link := b.config.APIPublicURL + "/calendar/" + token + ".ics"
+	text += i18n.T(loc, "calendar.link", link)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.DisableWebPagePreview = true
+	return b.sendMessage(msg)
+}
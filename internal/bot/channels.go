@@ -0,0 +1,201 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/scheduler"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// channelTitles are the message keys naming the notification channels in the bot's messages
+var channelTitles = map[string]string{
+	"email":   "channels.email",
+	"webhook": "channels.webhook",
+}
+
+// channelTitle names the channel, by its own name when it has no title
+func channelTitle(loc locale.Locale, name string) string {
+	if key, ok := channelTitles[name]; ok {
+		return i18n.T(loc, key)
+	}
+	return name
+}
+
+// handleChannelsCommand handles /channels: the list of the user's channels beyond Telegram,
+// /channels <channel> <address> to turn one on, /channels <channel> off and /channels test
+func (b *Bot) handleChannelsCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	registry := b.config.Scheduler.Channels
+	names := registry.Names()
+	if len(names) == 0 {
+		return &ValidationError{Message: i18n.T(loc, "channels.none")}
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	switch {
+	case len(args) == 0:
+		return b.sendChannelList(ctx, message.Chat.ID, user, names)
+	case len(args) == 1 && strings.EqualFold(args[0], "test"):
+		return b.testChannels(ctx, message.Chat.ID, user)
+	case len(args) != 2:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, channelsUsage(loc, names)))
+	}
+
+	name := strings.ToLower(args[0])
+	channel, ok := registry.Get(name)
+	if !ok {
+		return &ValidationError{Message: i18n.T(loc, "channels.unknown", args[0], strings.Join(names, ", "))}
+	}
+	if strings.EqualFold(args[1], "off") {
+		deleted, err := b.channelRepo.Delete(ctx, user.ID, name)
+		if err != nil {
+			return err
+		}
+		text := i18n.T(loc, "channels.off", channelTitle(loc, name))
+		if !deleted {
+			text = i18n.T(loc, "channels.already_off", channelTitle(loc, name))
+		}
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, text))
+	}
+
+	address := args[1]
+	if err := channel.Validate(address); err != nil {
+		return &ValidationError{Message: i18n.T(loc, "channels.invalid_address", channelTitle(loc, name), address)}
+	}
+	if err := b.channelRepo.Set(ctx, user.ID, name, address); err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "channels.on", channelTitle(loc, name), address))
+	msg.DisableWebPagePreview = true
+	return b.sendMessage(msg)
+}
+
+// sendChannelList sends the user's channels and how to change them
+func (b *Bot) sendChannelList(ctx context.Context, chatID int64, user *models.User, names []string) error {
+	channels, err := b.channelRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	addresses := make(map[string]string, len(channels))
+	for _, c := range channels {
+		addresses[c.Channel] = c.Address
+	}
+
+	loc := b.userLocale(user)
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "channels.title"))
+	for _, name := range names {
+		address := addresses[name]
+		if address == "" {
+			address = i18n.T(loc, "channels.disabled")
+		}
+		text.WriteString(fmt.Sprintf("%s: %s\n", channelTitle(loc, name), address))
+	}
+	text.WriteString("\n")
+	text.WriteString(channelsUsage(loc, names))
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.DisableWebPagePreview = true
+	return b.sendMessage(msg)
+}
+
+// channelsUsage explains the /channels arguments for the configured channels
+func channelsUsage(loc locale.Locale, names []string) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "channels.usage"))
+	for _, name := range names {
+		switch name {
+		case "email":
+			text.WriteString(i18n.T(loc, "channels.usage_email"))
+		case "webhook":
+			text.WriteString(i18n.T(loc, "channels.usage_webhook"))
+		default:
+			text.WriteString(i18n.T(loc, "channels.usage_other", name))
+		}
+	}
+	text.WriteString(i18n.T(loc, "channels.usage_off"))
+	text.WriteString(i18n.T(loc, "channels.usage_test"))
+	return text.String()
+}
+
+// testChannels sends a test notification to each of the user's channels and reports how it went
+func (b *Bot) testChannels(ctx context.Context, chatID int64, user *models.User) error {
+	channels, err := b.channelRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if len(channels) == 0 {
+		return &ValidationError{Message: i18n.T(loc, "channels.no_channels")}
+	}
+
+	notification := scheduler.Notification{
+		Kind:    "test",
+		Subject: i18n.T(loc, "reminder.subject"),
+		Text:    i18n.T(loc, "reminder.test") + b.channelFooter(loc),
+	}
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "channels.test_title"))
+	for _, c := range channels {
+		channel, ok := b.config.Scheduler.Channels.Get(c.Channel)
+		if !ok {
+			text.WriteString(i18n.T(loc, "channels.test_disabled", channelTitle(loc, c.Channel)))
+			continue
+		}
+		if err := channel.Send(ctx, c.Address, notification); err != nil {
+			logging.FromContext(ctx).Warn("failed to send test notification", "user_id", user.ID, "channel", c.Channel, "error", err)
+			text.WriteString(i18n.T(loc, "channels.test_failed", channelTitle(loc, c.Channel), err))
+			continue
+		}
+		text.WriteString(i18n.T(loc, "channels.test_sent", channelTitle(loc, c.Channel), c.Address))
+	}
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.DisableWebPagePreview = true
+	return b.sendMessage(msg)
+}
+
+// notifyChannels delivers the notification to the user's channels beyond Telegram. A channel
+// that fails is only logged: the notification already reached Telegram.
+func (b *Bot) notifyChannels(ctx context.Context, user *models.User, n scheduler.Notification) {
+	registry := b.config.Scheduler.Channels
+	if registry == nil {
+		return
+	}
+	channels, err := b.channelRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to get notification channels", "user_id", user.ID, "error", err)
+		return
+	}
+	for _, c := range channels {
+		// The admin may have turned the channel off since the user picked it
+		channel, ok := registry.Get(c.Channel)
+		if !ok {
+			continue
+		}
+		if err := channel.Send(ctx, c.Address, n); err != nil {
+			logging.FromContext(ctx).Warn("failed to deliver notification", "user_id", user.ID, "channel", c.Channel, "error", err)
+		}
+	}
+}
+
+// channelFooter links the notifications sent beyond Telegram to the bot, where repetitions are
+// marked as done
+func (b *Bot) channelFooter(loc locale.Locale) string {
+	b.mu.RLock()
+	username := b.api.Self.UserName
+	b.mu.RUnlock()
+	if username == "" {
+		return ""
+	}
+	return i18n.T(loc, "reminder.channel_footer", username)
+}
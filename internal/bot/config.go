@@ -1,7 +1,20 @@
 package bot
 
 import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/example/engbot/internal/ai"
+	"github.com/example/engbot/internal/anki"
+	"github.com/example/engbot/internal/backup"
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/scheduler"
+	"github.com/example/engbot/internal/tts"
 )
 
 // BotConfig represents the configuration for the bot
@@ -12,6 +25,49 @@ type BotConfig struct {
 	DefaultRepetitions int
 	// Time between sending word batches
 	BatchInterval time.Duration
+	// Topic that receives words imported without a topic
+	DefaultTopicName string
+	// Maximum number of topics per user, 0 means unlimited. Admins are exempt.
+	MaxTopicsPerUser int
+	// Number of topics on one page of the topic list
+	TopicsPerPage int
+	// How long the "↩️ Отменить" button undoes deleting or archiving a topic
+	UndoWindow time.Duration
+	// Telegram IDs of admins from ADMIN_USER_IDS
+	AdminUserIDs map[int64]bool
+	// Maximum number of /broadcast messages sent per second
+	BroadcastRate int
+	// Maximum number of messages the bot sends per second across all chats
+	MessagesPerSecond int
+	// Anki note fields read as the word, translation, description and examples on import
+	AnkiFields anki.FieldMapping
+	// Default interface language from BOT_LOCALE (ru or en), users can pick their own with /language
+	Locale locale.Locale
+	// Public HTTPS URL for Telegram to post updates to, empty means long polling
+	WebhookURL string
+	// Address of the embedded webhook server
+	WebhookListenAddr string
+	// Secret Telegram sends in X-Telegram-Bot-Api-Secret-Token, a random one is generated when empty
+	WebhookSecretToken string
+	// Certificate and key for serving HTTPS directly, leave empty behind a TLS-terminating proxy
+	WebhookCertFile string
+	WebhookKeyFile  string
+	// Public HTTPS URL of the flashcard Mini App served by the API, /app is off when empty
+	WebAppURL string
+	// Public base URL of the API server for the links to calendar feeds, /calendar is off when empty
+	APIPublicURL string
+	// Language model for the /story texts and for the examples of imported words,
+	// nothing configured disables them
+	AI ai.Config
+	// Workers filling in imported words and their requests to the model per minute
+	EnrichWorkers int
+	EnrichRate    int
+	// OpenAI speech model and voice for the pronunciation of words, enabled with OPENAI_API_KEY
+	TTSModel string
+	TTSVoice string
+	// Schedules of the reminder, story and maintenance jobs, the scheduler as a whole
+	// is turned off with ENABLE_SCHEDULER=false
+	Scheduler scheduler.Config
 }
 
 // DefaultConfig returns the default bot configuration
@@ -20,5 +76,158 @@ func DefaultConfig() *BotConfig {
 		DefaultWordsPerBatch: 10,
 		DefaultRepetitions:   5,
 		BatchInterval:        time.Hour * 1,
+		DefaultTopicName:     defaultTopicName(),
+		MaxTopicsPerUser:     envInt("MAX_TOPICS_PER_USER", 100),
+		TopicsPerPage:        envInt("TOPICS_PER_PAGE", 10),
+		UndoWindow:           envDuration("UNDO_WINDOW", 10*time.Minute),
+		AdminUserIDs:         adminUserIDs(),
+		BroadcastRate:        envInt("BROADCAST_RATE", 20),
+		MessagesPerSecond:    envInt("MESSAGES_PER_SECOND", 30),
+		AnkiFields:           ankiFieldMapping(),
+		Locale:               locale.Parse(os.Getenv("BOT_LOCALE")),
+		WebhookURL:           os.Getenv("WEBHOOK_URL"),
+		WebhookListenAddr:    envString("WEBHOOK_LISTEN_ADDR", ":8443"),
+		WebhookSecretToken:   os.Getenv("WEBHOOK_SECRET_TOKEN"),
+		WebhookCertFile:      os.Getenv("WEBHOOK_CERT_FILE"),
+		WebhookKeyFile:       os.Getenv("WEBHOOK_KEY_FILE"),
+		WebAppURL:            os.Getenv("WEBAPP_URL"),
+		APIPublicURL:         strings.TrimRight(os.Getenv("API_PUBLIC_URL"), "/"),
+		AI: ai.Config{
+			Provider:        os.Getenv("AI_PROVIDER"),
+			Model:           os.Getenv("AI_MODEL"),
+			OpenAIAPIKey:    os.Getenv("OPENAI_API_KEY"),
+			AnthropicAPIKey: os.Getenv("ANTHROPIC_API_KEY"),
+			OllamaURL:       os.Getenv("OLLAMA_URL"),
+		},
+		EnrichWorkers: envInt("ENRICH_WORKERS", 2),
+		EnrichRate:    envInt("ENRICH_RATE", 30),
+		TTSModel:      envString("TTS_MODEL", tts.DefaultModel),
+		TTSVoice:      envString("TTS_VOICE", tts.DefaultVoice),
+		Scheduler:     schedulerConfig(),
+	}
+}
+
+// schedulerConfig reads the job schedules and SCHEDULER_JITTER over the scheduler defaults.
+// A job is configured with <JOB>_SCHEDULE and turned off with <JOB>_ENABLED=false.
+func schedulerConfig() scheduler.Config {
+	config := scheduler.DefaultConfig()
+	for prefix, job := range map[string]*scheduler.Job{
+		"REMINDERS":         &config.Reminders,
+		"STORIES":           &config.Stories,
+		"WORD_OF_DAY":       &config.WordOfDay,
+		"STREAK_PROTECTION": &config.StreakProtection,
+		"NOTIFICATION_LOG":  &config.NotificationLogPruning,
+		"TRASH":             &config.TrashPruning,
+		"OVERDUE":           &config.OverduePolicies,
+		"STALLED_PROMPTS":   &config.StalledPrompts,
+		"LOAD_BALANCING":    &config.LoadBalancing,
+		"WEEKLY_REPORTS":    &config.WeeklyReports,
+		"BACKUPS":           &config.Backups,
+		"DELIVERY_RETRIES":  &config.DeliveryRetries,
+		"SM2_TUNING":        &config.SM2Tuning,
+		"WORD_DIFFICULTY":   &config.WordDifficulty,
+	} {
+		job.Enabled = envBool(prefix+"_ENABLED", job.Enabled)
+		job.Schedule = envString(prefix+"_SCHEDULE", job.Schedule)
+	}
+	config.Jitter = envDuration("SCHEDULER_JITTER", config.Jitter)
+	config.TrashRetention = envDuration("UNDO_WINDOW", config.TrashRetention)
+
+	// Several instances behind one webhook share the database, and only the one holding the
+	// scheduler lease runs the jobs
+	if envBool("SCHEDULER_LOCK", true) {
+		config.Locker = database.NewLeaseRepository()
+	}
+	config.Instance = envString("INSTANCE_ID", instanceID())
+	config.LeaseTTL = envDuration("SCHEDULER_LEASE_TTL", config.LeaseTTL)
+
+	backups, err := backup.NewManagerFromEnv()
+	if err != nil {
+		slog.Warn("backups are turned off", "error", err)
+	}
+	config.Backup = backups
+	if backups == nil {
+		config.Backups.Enabled = false
+	}
+
+	channels, err := scheduler.ChannelsFromEnv()
+	if err != nil {
+		slog.Warn("notification channels are turned off", "error", err)
+	}
+	config.Channels = channels
+	return config
+}
+
+// instanceID names this instance by its host and process ID
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// envString reads a string from the environment, falling back to def
+func envString(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// envInt reads a non-negative integer from the environment, falling back to def
+func envInt(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return def
+	}
+	return value
+}
+
+// envBool reads a boolean (true/false, 1/0) from the environment, falling back to def
+func envBool(key string, def bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// envDuration reads a non-negative duration such as 90s or 5m from the environment, falling back to def
+func envDuration(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value < 0 {
+		return def
+	}
+	return value
+}
+
+// adminUserIDs parses the comma-separated ADMIN_USER_IDS list
+func adminUserIDs() map[int64]bool {
+	ids := make(map[int64]bool)
+	for _, field := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err == nil {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// ankiFieldMapping parses ANKI_FIELD_MAP, falling back to the first two fields as word and translation
+func ankiFieldMapping() anki.FieldMapping {
+	mapping, err := anki.ParseFieldMapping(os.Getenv("ANKI_FIELD_MAP"))
+	if err != nil {
+		slog.Warn("invalid ANKI_FIELD_MAP, using default field mapping", "error", err)
+		return anki.DefaultFieldMapping()
+	}
+	return mapping
+}
+
+// defaultTopicName reads the default topic name from DEFAULT_TOPIC_NAME
+func defaultTopicName() string {
+	if name := os.Getenv("DEFAULT_TOPIC_NAME"); name != "" {
+		return name
 	}
+	return database.DefaultGeneralTopicName
 } 
\ No newline at end of file
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	wordtest "github.com/example/engbot/internal/testing"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// cramTestType is the test type /cram results are saved with, apart from the /quiz tests
+const cramTestType = "cram"
+
+// handleCramCommand handles /cram <номер|категория>: a typed test over every word of the topic
+// or of the topics in the category, due or not. The answers bypass SM-2, so cramming before
+// an exam doesn't distort the long-term schedule, only the result is saved.
+func (b *Bot) handleCramCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "cram.usage")))
+	}
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+	picked, title := cramTopics(loc, topics, args)
+	if len(picked) == 0 {
+		return &ValidationError{Message: i18n.T(loc, "cram.not_found", args) + i18n.T(loc, "cram.usage")}
+	}
+
+	all, err := b.wordRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	var words []models.Word
+	for _, topic := range picked {
+		words = append(words, topicWords(all, topic.ID)...)
+	}
+	now := b.clock.Now()
+	test, err := wordtest.CreateTest(words, wordtest.Options{Type: wordtest.TextInput}, rand.New(rand.NewSource(now.UnixNano())))
+	if errors.Is(err, wordtest.ErrNotEnoughWords) {
+		return &ValidationError{Message: i18n.T(loc, "cram.no_words", title)}
+	}
+	if err != nil {
+		return err
+	}
+	test.StartedAt = now
+
+	s := &quizSession{test: test, userID: user.ID, chatID: message.Chat.ID, loc: loc, mode: quizModeText, cram: true}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b.quizzes.put(message.From.ID, s, now)
+	// The answers come in messages, so no other conversation should take them
+	b.states.remove(message.From.ID)
+	logging.FromContext(ctx).Info("cram started", "user_id", user.ID, "words", len(test.Questions))
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "cram.started",
+		title, len(test.Questions), i18n.Plural(loc, "plural.word", len(test.Questions)), quizTextQuestionText(loc, test)))
+	msg.ReplyMarkup = createKeyboard(quizTextButtons(loc, test))
+	return b.sendMessage(msg)
+}
+
+// cramTopics finds the topics to cram: the topic with the number from /list, else the unarchived
+// topics of the category, else the topic with the name. It also returns what was found for the messages.
+func cramTopics(loc locale.Locale, topics []models.Topic, args string) ([]models.Topic, string) {
+	if index, err := strconv.Atoi(args); err == nil {
+		if index < 1 || index > len(topics) {
+			return nil, ""
+		}
+		return topics[index-1 : index], i18n.T(loc, "cram.topic", topics[index-1].Name)
+	}
+
+	var picked []models.Topic
+	for _, topic := range topics {
+		if !topic.Archived && topic.Category != "" && strings.EqualFold(topic.Category, args) {
+			picked = append(picked, topic)
+		}
+	}
+	if len(picked) > 0 {
+		return picked, i18n.T(loc, "cram.category", picked[0].Category)
+	}
+	for _, topic := range topics {
+		if strings.EqualFold(topic.Name, args) {
+			return []models.Topic{topic}, i18n.T(loc, "cram.topic", topic.Name)
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,278 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data of the deck catalog
+const (
+	callbackDecksMenu           = "decks_menu"
+	callbackDeckPreviewPrefix   = "deck_preview_"
+	callbackDeckSubscribePrefix = "deck_subscribe_"
+)
+
+// deckPreviewWords is how many words the deck preview shows
+const deckPreviewWords = 15
+
+// handleDecksCommand handles /decks: without arguments it lists the decks, "/decks <номер>" previews one,
+// "/decks publish <номер темы> [описание]" lets admins share a topic as a deck
+func (b *Bot) handleDecksCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	args := strings.TrimSpace(message.CommandArguments())
+	if command, rest, _ := strings.Cut(args, " "); strings.EqualFold(command, "publish") {
+		return b.publishDeck(ctx, message.Chat.ID, user, strings.TrimSpace(rest))
+	}
+
+	decks, err := b.deckRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	subscribed, err := b.deckRepo.GetSubscribedIDs(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if args == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, decksText(loc, decks, subscribed))
+		msg.ReplyMarkup = createKeyboard(decksButtons(loc, decks, subscribed))
+		return b.sendMessage(msg)
+	}
+
+	index, err := strconv.Atoi(args)
+	if err != nil || index < 1 || index > len(decks) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "decks.usage")))
+	}
+	deck := decks[index-1]
+	words, err := b.deckRepo.GetWords(ctx, deck.ID, deckPreviewWords)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, deckPreviewText(loc, deck, words))
+	msg.ReplyMarkup = createKeyboard(deckPreviewButtons(loc, deck, subscribed[deck.ID]))
+	return b.sendMessage(msg)
+}
+
+// handleDecksMenu shows the deck catalog in place of the current message
+func (b *Bot) handleDecksMenu(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	decks, err := b.deckRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	subscribed, err := b.deckRepo.GetSubscribedIDs(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		decksText(loc, decks, subscribed),
+		createKeyboard(decksButtons(loc, decks, subscribed)),
+	)
+	return b.editMessage(msg)
+}
+
+// handleDeckPreviewCallback shows the first words of the deck with the subscribe button
+func (b *Bot) handleDeckPreviewCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, deckID int64) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	deck, err := b.deckRepo.GetByID(ctx, deckID)
+	if err != nil {
+		return err
+	}
+	if deck == nil {
+		return &ValidationError{Message: i18n.T(loc, "decks.not_found")}
+	}
+	words, err := b.deckRepo.GetWords(ctx, deck.ID, deckPreviewWords)
+	if err != nil {
+		return err
+	}
+	subscribed, err := b.deckRepo.GetSubscribedIDs(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		deckPreviewText(loc, *deck, words),
+		createKeyboard(deckPreviewButtons(loc, *deck, subscribed[deck.ID])),
+	)
+	return b.editMessage(msg)
+}
+
+// handleDeckSubscribeCallback subscribes the user to the deck: its words go to a topic named after
+// the deck and into the flashcard review
+func (b *Bot) handleDeckSubscribeCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, deckID int64) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	deck, err := b.deckRepo.GetByID(ctx, deckID)
+	if err != nil {
+		return err
+	}
+	if deck == nil {
+		return &ValidationError{Message: i18n.T(loc, "decks.not_found")}
+	}
+
+	topic, err := b.topicRepo.GetGeneralTopic(ctx, user.ID, deck.Name, b.topicLimit(user))
+	if errors.Is(err, database.ErrTopicLimit) {
+		return &ValidationError{Message: b.topicLimitText(loc)}
+	}
+	if err != nil {
+		return err
+	}
+	added, err := b.deckRepo.Subscribe(ctx, user.ID, deck.ID, topic.ID)
+	if err != nil {
+		return err
+	}
+
+	text := i18n.T(loc, "decks.subscribed", deck.Name, added, i18n.Plural(loc, "plural.word", added), topic.Name)
+	if added == 0 {
+		text = i18n.T(loc, "decks.subscribed_none", deck.Name)
+	}
+	return b.sendMessage(tgbotapi.NewMessage(callback.Message.Chat.ID, text))
+}
+
+// publishDeck shares the admin's topic as a deck, "<номер темы> [описание]". Publishing the topic
+// again adds its new words to the deck.
+func (b *Bot) publishDeck(ctx context.Context, chatID int64, user *models.User, args string) error {
+	loc := b.userLocale(user)
+	if !b.isAdmin(user) {
+		return &ValidationError{Message: i18n.T(loc, "decks.admins_only")}
+	}
+
+	number, description, _ := strings.Cut(args, " ")
+	index, err := strconv.Atoi(number)
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "decks.publish_usage")))
+	}
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if index < 1 || index > len(topics) {
+		return &ValidationError{Message: i18n.T(loc, "topic.wrong_number")}
+	}
+	topic := topics[index-1]
+
+	count, err := b.wordRepo.CountByTopicID(ctx, user.ID, topic.ID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return &ValidationError{Message: i18n.T(loc, "decks.empty_topic", topic.Name)}
+	}
+
+	deck, added, err := b.deckRepo.Publish(ctx, user.ID, topic.ID, topic.Name, strings.TrimSpace(description))
+	if err != nil {
+		return err
+	}
+
+	text := i18n.T(loc, "decks.published", deck.Name, added, deck.WordCount)
+	return b.sendMessage(tgbotapi.NewMessage(chatID, text))
+}
+
+// decksText lists the decks with their size and the user's subscriptions
+func decksText(loc locale.Locale, decks []models.Deck, subscribed map[int64]bool) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "decks.title"))
+	if len(decks) == 0 {
+		text.WriteString(i18n.T(loc, "decks.none"))
+		return text.String()
+	}
+
+	for i, deck := range decks {
+		mark := ""
+		if subscribed[deck.ID] {
+			mark = " ✅"
+		}
+		text.WriteString(fmt.Sprintf("%d. %s - %d %s%s\n", i+1, deck.Name, deck.WordCount,
+			i18n.Plural(loc, "plural.word", deck.WordCount), mark))
+		if deck.Description != "" {
+			text.WriteString("   " + deck.Description + "\n")
+		}
+	}
+	text.WriteString(i18n.T(loc, "decks.hint"))
+	return text.String()
+}
+
+// decksButtons returns a preview button per deck
+func decksButtons(loc locale.Locale, decks []models.Deck, subscribed map[int64]bool) [][]MenuButton {
+	var buttons [][]MenuButton
+	for _, deck := range decks {
+		text := "📖 " + deck.Name
+		if subscribed[deck.ID] {
+			text = "✅ " + deck.Name
+		}
+		buttons = append(buttons, []MenuButton{{
+			Text:         text,
+			CallbackData: fmt.Sprintf("%s%d", callbackDeckPreviewPrefix, deck.ID),
+		}})
+	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "button.main_menu"), CallbackData: "main_menu"}})
+	return buttons
+}
+
+// deckPreviewText shows the deck's description and first words
+func deckPreviewText(loc locale.Locale, deck models.Deck, words []models.DeckWord) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📖 %s\n", deck.Name))
+	if deck.Description != "" {
+		text.WriteString(deck.Description + "\n")
+	}
+	text.WriteString(fmt.Sprintf("\n%d %s:\n", deck.WordCount, i18n.Plural(loc, "plural.word", deck.WordCount)))
+	for _, w := range words {
+		line := fmt.Sprintf("• %s - %s", w.Word, w.Translation)
+		if w.VerbForms != "" {
+			line += fmt.Sprintf(" (%s)", w.VerbForms)
+		}
+		text.WriteString(line + "\n")
+	}
+	if deck.WordCount > len(words) {
+		text.WriteString(i18n.T(loc, "decks.more", deck.WordCount-len(words)))
+	}
+	return text.String()
+}
+
+// deckPreviewButtons returns the subscribe button, or the one adding new words for subscribers,
+// and the way back
+func deckPreviewButtons(loc locale.Locale, deck models.Deck, subscribed bool) [][]MenuButton {
+	subscribe := MenuButton{Text: i18n.T(loc, "decks.subscribe"), CallbackData: fmt.Sprintf("%s%d", callbackDeckSubscribePrefix, deck.ID)}
+	if subscribed {
+		subscribe.Text = i18n.T(loc, "decks.add_new")
+	}
+	return [][]MenuButton{
+		{subscribe},
+		{{Text: i18n.T(loc, "decks.back"), CallbackData: callbackDecksMenu}},
+	}
+}
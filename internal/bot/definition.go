@@ -0,0 +1,22 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+)
+
+// defineWord has the language model write the English definition of the word for the
+// definition quiz and keeps it, so the word is defined once
+func (b *Bot) defineWord(ctx context.Context, word models.Word) (string, error) {
+	definition, err := b.languageModel.DefineWord(ctx, word.Word, word.Translation)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to define word", "word_id", word.ID, "error", err)
+		return "", err
+	}
+	if err := b.wordRepo.SetDefinition(ctx, word.ID, definition); err != nil {
+		logging.FromContext(ctx).Error("failed to save word definition", "word_id", word.ID, "error", err)
+	}
+	return definition, nil
+}
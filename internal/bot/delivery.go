@@ -0,0 +1,248 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxBlockedDeliveries is how many notifications in a row a user may refuse by blocking the
+	// bot before the bot turns the user's notifications off
+	maxBlockedDeliveries = 3
+	// maxDeliveryAttempts is how many times a queued notification is tried before it is given up
+	maxDeliveryAttempts = 8
+	// deliveryExpiry is how long a queued notification stays worth sending
+	deliveryExpiry = 12 * time.Hour
+	// deliveryBatch is how many queued notifications one run of the retry job sends at most
+	deliveryBatch = 100
+)
+
+// queuedMessage is a notification kept in the delivery queue
+type queuedMessage struct {
+	ChatID                int64           `json:"chat_id"`
+	Text                  string          `json:"text"`
+	ParseMode             string          `json:"parse_mode,omitempty"`
+	DisableWebPagePreview bool            `json:"disable_web_page_preview,omitempty"`
+	ReplyMarkup           json.RawMessage `json:"reply_markup,omitempty"`
+}
+
+// isBlockedError reports whether Telegram refused the message because the user can't be reached
+// at all: the user blocked the bot, deleted the account or never started the chat
+func isBlockedError(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == 403 || (apiErr.Code == 400 && strings.Contains(apiErr.Message, "chat not found"))
+}
+
+// isRetryableError reports whether sending the message again later may succeed: a network error
+// or one on Telegram's side, rather than a rejected message
+func isRetryableError(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	return apiErr.Code == 429 || apiErr.Code >= 500
+}
+
+// deliveryBackoff is the wait before the next attempt after the given number of failed ones
+func deliveryBackoff(attempts int) time.Duration {
+	return min(time.Minute<<min(attempts-1, 6), time.Hour)
+}
+
+// deliver sends the notification. When Telegram or the network fails, the notification is queued
+// and retried with backoff instead of being lost, and deliver reports success.
+func (b *Bot) deliver(ctx context.Context, user *models.User, kind string, msg tgbotapi.MessageConfig) error {
+	err := b.sendMessage(msg)
+	if err == nil || isBlockedError(err) || !isRetryableError(err) {
+		return err
+	}
+
+	markup, marshalErr := json.Marshal(msg.ReplyMarkup)
+	if marshalErr != nil {
+		return err
+	}
+	payload, marshalErr := json.Marshal(queuedMessage{
+		ChatID:                msg.ChatID,
+		Text:                  msg.Text,
+		ParseMode:             msg.ParseMode,
+		DisableWebPagePreview: msg.DisableWebPagePreview,
+		ReplyMarkup:           markup,
+	})
+	if marshalErr != nil {
+		return err
+	}
+	next := b.clock.Now().Add(deliveryBackoff(1))
+	if queueErr := b.deliveryRepo.Enqueue(ctx, user.ID, kind, string(payload), err.Error(), next); queueErr != nil {
+		logging.FromContext(ctx).Warn("failed to queue notification", "user_id", user.ID, "type", kind, "error", queueErr)
+		return err
+	}
+	logging.FromContext(ctx).Warn("notification queued for retry", "user_id", user.ID, "type", kind, "error", err)
+	return nil
+}
+
+// RetryDeliveries sends the queued notifications whose next attempt is due.
+// It implements the scheduler.Notifier interface.
+func (b *Bot) RetryDeliveries(ctx context.Context) error {
+	deliveries, err := b.deliveryRepo.Due(ctx, deliveryBatch)
+	if err != nil {
+		return err
+	}
+	for _, d := range deliveries {
+		if err := b.retryDelivery(ctx, d); err != nil {
+			logging.FromContext(ctx).Error("failed to retry notification", "user_id", d.UserID, "type", d.Kind, "error", err)
+		}
+	}
+	return nil
+}
+
+// retryDelivery makes another attempt at the queued notification, outside the user's quiet hours
+func (b *Bot) retryDelivery(ctx context.Context, d database.QueuedDelivery) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, d.TelegramID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.NotificationEnabled || !d.Active {
+		return b.deliveryRepo.Delete(ctx, d.ID)
+	}
+
+	now := b.clock.Now()
+	if now.Sub(d.CreatedAt) > deliveryExpiry {
+		return b.giveUpDelivery(ctx, user, d, "expired: "+d.LastError)
+	}
+	if database.InQuietHours(user, now.Hour()) {
+		next := time.Date(now.Year(), now.Month(), now.Day(), user.QuietHoursEnd, 0, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		if next.Sub(d.CreatedAt) > deliveryExpiry {
+			return b.giveUpDelivery(ctx, user, d, "expired in quiet hours: "+d.LastError)
+		}
+		return b.deliveryRepo.Postpone(ctx, d.ID, next)
+	}
+
+	var queued queuedMessage
+	if err := json.Unmarshal([]byte(d.Message), &queued); err != nil {
+		return b.giveUpDelivery(ctx, user, d, fmt.Sprintf("invalid message: %v", err))
+	}
+	msg := tgbotapi.NewMessage(queued.ChatID, queued.Text)
+	msg.ParseMode = queued.ParseMode
+	msg.DisableWebPagePreview = queued.DisableWebPagePreview
+	if len(queued.ReplyMarkup) > 0 && string(queued.ReplyMarkup) != "null" {
+		msg.ReplyMarkup = queued.ReplyMarkup
+	}
+
+	sendErr := b.sendMessage(msg)
+	switch {
+	case sendErr == nil:
+		logging.FromContext(ctx).Info("queued notification delivered", "user_id", user.ID, "type", d.Kind, "attempts", d.Attempts+1)
+		b.clearDeliveryFailures(ctx, user)
+		return b.deliveryRepo.Delete(ctx, d.ID)
+	case isBlockedError(sendErr):
+		if err := b.deliveryRepo.Fail(ctx, d.ID, sendErr.Error()); err != nil {
+			return err
+		}
+		b.recordBlocked(ctx, user, sendErr)
+		return nil
+	case !isRetryableError(sendErr) || d.Attempts+1 >= maxDeliveryAttempts:
+		return b.giveUpDelivery(ctx, user, d, sendErr.Error())
+	default:
+		return b.deliveryRepo.Retry(ctx, d.ID, sendErr.Error(), now.Add(deliveryBackoff(d.Attempts+1)))
+	}
+}
+
+// giveUpDelivery stops retrying the notification and counts the user as undeliverable
+func (b *Bot) giveUpDelivery(ctx context.Context, user *models.User, d database.QueuedDelivery, reason string) error {
+	logging.FromContext(ctx).Warn("notification given up", "user_id", user.ID, "type", d.Kind, "attempts", d.Attempts, "reason", reason)
+	if err := b.deliveryRepo.Fail(ctx, d.ID, reason); err != nil {
+		return err
+	}
+	_, err := b.deliveryRepo.RecordFailure(ctx, user.ID, reason, false)
+	return err
+}
+
+// recordBlocked counts a notification refused because the user blocked the bot. After
+// maxBlockedDeliveries in a row the user's notifications are turned off, /notify on turns them
+// back on.
+func (b *Bot) recordBlocked(ctx context.Context, user *models.User, sendErr error) {
+	blocked, err := b.deliveryRepo.RecordFailure(ctx, user.ID, sendErr.Error(), true)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to record blocked delivery", "user_id", user.ID, "error", err)
+		return
+	}
+	if blocked < maxBlockedDeliveries || !user.NotificationEnabled {
+		return
+	}
+
+	user.NotificationEnabled = false
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		logging.FromContext(ctx).Error("failed to turn off notifications", "user_id", user.ID, "error", err)
+		return
+	}
+	if err := b.deliveryRepo.MarkDisabled(ctx, user.ID); err != nil {
+		logging.FromContext(ctx).Warn("failed to mark notifications disabled", "user_id", user.ID, "error", err)
+	}
+	logging.FromContext(ctx).Info("notifications turned off for a user who blocked the bot", "user_id", user.ID, "blocked", blocked)
+}
+
+// markInactive marks the user of the private chat inactive after Telegram refused a message
+// because the user blocked the bot or deleted the account
+func (b *Bot) markInactive(ctx context.Context, chatID int64, sendErr error) {
+	// Group chats have negative IDs and no user behind them
+	if chatID <= 0 {
+		return
+	}
+	marked, err := b.userRepo.SetInactive(ctx, chatID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to mark user inactive", "telegram_id", chatID, "error", err)
+		return
+	}
+	if marked {
+		logging.FromContext(ctx).Info("user marked inactive", "telegram_id", chatID, "reason", sendErr)
+	}
+}
+
+// reactivate marks the user active again after /start. Notifications the bot turned off when the
+// user blocked it are turned back on.
+func (b *Bot) reactivate(ctx context.Context, user *models.User) {
+	reactivated, err := b.userRepo.Reactivate(ctx, user.TelegramID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to reactivate user", "user_id", user.ID, "error", err)
+		return
+	}
+	if !reactivated {
+		return
+	}
+	logging.FromContext(ctx).Info("user reactivated", "user_id", user.ID)
+
+	disabled, err := b.deliveryRepo.DisabledByBot(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to check disabled notifications", "user_id", user.ID, "error", err)
+		return
+	}
+	if disabled && !user.NotificationEnabled {
+		user.NotificationEnabled = true
+		if err := b.userRepo.Update(ctx, user); err != nil {
+			logging.FromContext(ctx).Warn("failed to turn notifications back on", "user_id", user.ID, "error", err)
+			return
+		}
+	}
+	b.clearDeliveryFailures(ctx, user)
+}
+
+// clearDeliveryFailures forgets the user's failed deliveries
+func (b *Bot) clearDeliveryFailures(ctx context.Context, user *models.User) {
+	if err := b.deliveryRepo.ClearFailures(ctx, user.ID); err != nil {
+		logging.FromContext(ctx).Warn("failed to clear delivery failures", "user_id", user.ID, "error", err)
+	}
+}
@@ -0,0 +1,211 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data of the daily digest
+const (
+	callbackDigestToggle     = "digest_toggle"
+	callbackDigestPagePrefix = "digest_page_"
+	callbackDigestWords      = "digest_words"
+)
+
+// digestPageSize is how many due topics one digest page lists
+const digestPageSize = 5
+
+// digest is the combined list of due items rendered into the daily digest
+type digest struct {
+	repetitions []models.Repetition
+	topics      map[int64]models.Topic
+	dueWords    int
+	streak      int
+	loc         locale.Locale
+}
+
+// empty reports whether there is nothing to review
+func (d *digest) empty() bool {
+	return len(d.repetitions) == 0 && d.dueWords == 0
+}
+
+// pages returns the number of digest pages, at least one
+func (d *digest) pages() int {
+	return max(1, (len(d.repetitions)+digestPageSize-1)/digestPageSize)
+}
+
+// sendDigest sends the user one message with their due topics, due words and streak.
+// Nothing is sent when nothing is due.
+func (b *Bot) sendDigest(ctx context.Context, user *models.User) error {
+	d, err := b.loadDigest(ctx, user)
+	if err != nil {
+		return err
+	}
+	if d.empty() {
+		return nil
+	}
+
+	msg := tgbotapi.NewMessage(user.TelegramID, d.text(0))
+	msg.ReplyMarkup = d.keyboard(0)
+	return b.sendMessage(msg)
+}
+
+// handleDigestPage shows another page of the digest in the same message
+func (b *Bot) handleDigestPage(ctx context.Context, callback *tgbotapi.CallbackQuery, page int) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+
+	d, err := b.loadDigest(ctx, user)
+	if err != nil {
+		return err
+	}
+	if d.empty() {
+		msg := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID,
+			i18n.T(d.loc, "digest.all_done"), createKeyboard(b.mainMenuButtons(d.loc)))
+		return b.editMessage(msg)
+	}
+
+	page = max(0, min(page, d.pages()-1))
+	msg := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, d.text(page), d.keyboard(page))
+	return b.editMessage(msg)
+}
+
+// handleDigestWords starts the flashcard review from the digest button
+func (b *Bot) handleDigestWords(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	// Сообщение с кнопкой отправлено ботом, поэтому берем From из callback
+	return b.handleReview(ctx, &tgbotapi.Message{From: callback.From, Chat: callback.Message.Chat})
+}
+
+// handleDigestToggle switches between the daily digest and separate reminders
+func (b *Bot) handleDigestToggle(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+
+	user.DigestEnabled = !user.DigestEnabled
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	text := i18n.T(loc, "digest.off")
+	if user.DigestEnabled {
+		text = i18n.T(loc, "digest.on", hoursText(database.NotificationHours(user)))
+	}
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+	msg.ReplyMarkup = createKeyboard(b.settingsMenuButtons(loc))
+	return b.sendMessage(msg)
+}
+
+// loadDigest collects the user's due repetitions, due words and review streak
+func (b *Bot) loadDigest(ctx context.Context, user *models.User) (*digest, error) {
+	repetitions, err := b.repetitionRepo.GetDueRepetitionsForNotification(ctx, user.ID, user.SkipFirstRepetitions)
+	if err != nil {
+		return nil, err
+	}
+
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	topicMap := make(map[int64]models.Topic, len(topics))
+	for _, t := range topics {
+		topicMap[t.ID] = t
+	}
+
+	words, err := b.dueWords(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	streak, err := b.activityRepo.GetStreak(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to get review streak", "user_id", user.ID, "error", err)
+	}
+
+	return &digest{
+		repetitions: repetitions,
+		topics:      topicMap,
+		dueWords:    len(words),
+		streak:      streak,
+		loc:         b.userLocale(user),
+	}, nil
+}
+
+// text renders one page of the digest
+func (d *digest) text(page int) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(d.loc, "digest.title"))
+	text.WriteString(i18n.T(d.loc, "digest.topics", len(d.repetitions)))
+	text.WriteString(i18n.T(d.loc, "digest.words", d.dueWords))
+	text.WriteString(streakText(d.loc, d.streak))
+
+	if len(d.repetitions) > 0 {
+		text.WriteString("\n")
+		start := page * digestPageSize
+		for i, rep := range d.repetitions[start:min(start+digestPageSize, len(d.repetitions))] {
+			text.WriteString(i18n.T(d.loc, "digest.topic", start+i+1, d.topics[rep.TopicID].Name, rep.RepetitionNumber))
+		}
+		if d.pages() > 1 {
+			text.WriteString(i18n.T(d.loc, "digest.page", page+1, d.pages()))
+		}
+	}
+	return text.String()
+}
+
+// keyboard returns the "done" buttons of the page's topics, the page arrows and the words button
+func (d *digest) keyboard(page int) tgbotapi.InlineKeyboardMarkup {
+	var buttons [][]MenuButton
+	start := page * digestPageSize
+	for _, rep := range d.repetitions[start:min(start+digestPageSize, len(d.repetitions))] {
+		buttons = append(buttons, []MenuButton{{
+			Text:         i18n.T(d.loc, "digest.done_button", d.topics[rep.TopicID].Name),
+			CallbackData: fmt.Sprintf("complete_%d", rep.ID),
+		}})
+	}
+
+	var nav []MenuButton
+	if page > 0 {
+		nav = append(nav, MenuButton{Text: "◀️", CallbackData: callbackDigestPagePrefix + strconv.Itoa(page-1)})
+	}
+	if page < d.pages()-1 {
+		nav = append(nav, MenuButton{Text: "▶️", CallbackData: callbackDigestPagePrefix + strconv.Itoa(page+1)})
+	}
+	if len(nav) > 0 {
+		buttons = append(buttons, nav)
+	}
+
+	if d.dueWords > 0 {
+		buttons = append(buttons, []MenuButton{{Text: i18n.T(d.loc, "digest.words_button", d.dueWords), CallbackData: callbackDigestWords}})
+	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(d.loc, "button.menu"), CallbackData: "main_menu"}})
+	return createKeyboard(buttons)
+}
+
+// digestToggleButton returns the settings button that turns the digest on or off
+func digestToggleButton(loc locale.Locale, enabled bool) MenuButton {
+	if enabled {
+		return MenuButton{Text: i18n.T(loc, "digest.disable"), CallbackData: callbackDigestToggle}
+	}
+	return MenuButton{Text: i18n.T(loc, "digest.enable"), CallbackData: callbackDigestToggle}
+}
+
+// digestStatus describes the digest setting for /settings
+func digestStatus(loc locale.Locale, enabled bool) string {
+	if enabled {
+		return i18n.T(loc, "digest.enabled")
+	}
+	return i18n.T(loc, "digest.disabled")
+}
@@ -0,0 +1,135 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleDirectionCommand handles /direction [<номер темы>] [en|ru|both|default]: without arguments
+// it shows the direction of the flashcards and the topics with their own, with a direction it sets
+// the user's one and with a topic number the topic's. Words of the topics reviewed from Russian
+// get their reverse cards right away.
+func (b *Bot) handleDirectionCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+	direction, err := database.GetReviewDirection(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	args := strings.Fields(strings.ToLower(message.CommandArguments()))
+	switch len(args) {
+	case 0:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, directionText(loc, direction, topics, "")))
+	case 1:
+		direction, ok := parseDirection(args[0])
+		if !ok {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "direction.usage")))
+		}
+		if err := database.SetReviewDirection(ctx, user.ID, direction); err != nil {
+			return err
+		}
+		note := i18n.T(loc, "direction.changed", directionName(loc, direction))
+		return b.sendDirectionChanged(ctx, loc, message.Chat.ID, user.ID, direction, topics, note)
+	case 2:
+		index, err := strconv.Atoi(args[0])
+		if err != nil || index < 1 || index > len(topics) {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "direction.wrong_topic")+i18n.T(loc, "direction.usage")))
+		}
+		topic := &topics[index-1]
+		topicDirection, ok := "", args[1] == "default"
+		if !ok {
+			topicDirection, ok = parseDirection(args[1])
+		}
+		if !ok {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "direction.usage")))
+		}
+		if err := b.topicRepo.SetReviewDirection(ctx, user.ID, topic.ID, topicDirection); err != nil {
+			return err
+		}
+		topic.ReviewDirection = topicDirection
+
+		note := i18n.T(loc, "direction.topic_changed", topic.Name, directionName(loc, direction))
+		if topicDirection != "" {
+			note = i18n.T(loc, "direction.topic_changed", topic.Name, directionName(loc, topicDirection))
+		}
+		return b.sendDirectionChanged(ctx, loc, message.Chat.ID, user.ID, direction, topics, note)
+	default:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "direction.usage")))
+	}
+}
+
+// sendDirectionChanged adds the reverse cards the new direction calls for and shows the directions
+func (b *Bot) sendDirectionChanged(ctx context.Context, loc locale.Locale, chatID, userID int64, direction string, topics []models.Topic, note string) error {
+	added, err := b.progressRepo.AddReverseCards(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if added > 0 {
+		note += i18n.T(loc, "direction.added", added)
+	}
+	return b.sendMessage(tgbotapi.NewMessage(chatID, directionText(loc, direction, topics, note)))
+}
+
+// parseDirection reads a flashcard direction: en for English to Russian, ru for Russian to
+// English or both
+func parseDirection(s string) (string, bool) {
+	switch s {
+	case "en", "en-ru", models.DirectionForward:
+		return models.DirectionForward, true
+	case "ru", "ru-en", models.DirectionReverse:
+		return models.DirectionReverse, true
+	case models.DirectionBoth:
+		return models.DirectionBoth, true
+	}
+	return "", false
+}
+
+// directionName describes a flashcard direction
+func directionName(loc locale.Locale, direction string) string {
+	switch direction {
+	case models.DirectionReverse:
+		return i18n.T(loc, "direction.reverse")
+	case models.DirectionBoth:
+		return i18n.T(loc, "direction.both")
+	default:
+		return i18n.T(loc, "direction.forward")
+	}
+}
+
+// directionText shows the user's flashcard direction and the topics with their own
+func directionText(loc locale.Locale, direction string, topics []models.Topic, note string) string {
+	var text strings.Builder
+	if note != "" {
+		text.WriteString(note + "\n\n")
+	}
+	text.WriteString(i18n.T(loc, "direction.title", directionName(loc, direction)))
+
+	var own []string
+	for i, topic := range topics {
+		if topic.ReviewDirection != "" {
+			own = append(own, fmt.Sprintf("%d. %s: %s", i+1, topic.Name, directionName(loc, topic.ReviewDirection)))
+		}
+	}
+	if len(own) > 0 {
+		text.WriteString(i18n.T(loc, "direction.own") + strings.Join(own, "\n") + "\n")
+	}
+
+	text.WriteString(i18n.T(loc, "direction.hint") + i18n.T(loc, "direction.usage"))
+	return text.String()
+}
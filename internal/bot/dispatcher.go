@@ -0,0 +1,193 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/example/engbot/internal/logging"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Telegram allows about one message per second in a chat, with short bursts
+const (
+	chatInterval = time.Second
+	chatBurst    = 3
+)
+
+// Limits of the outgoing message queue
+const (
+	maxQueuedMessages = 1000
+	maxSendRetries    = 3
+	// chatsPruneThreshold is the number of tracked chats above which idle ones are forgotten
+	chatsPruneThreshold = 1000
+)
+
+// errQueueFull is returned when too many messages are already waiting to be sent
+var errQueueFull = errors.New("outgoing message queue is full")
+
+// DispatchStats counts what happened to outgoing messages since the bot started
+type DispatchStats struct {
+	Sent    int64 // delivered to Telegram
+	Retried int64 // attempts repeated after 429 Too Many Requests
+	Dropped int64 // given up on: queue full, retries exhausted or shutdown
+	Queued  int64 // waiting for their turn right now
+}
+
+// dispatcher sends outgoing messages in order of arrival without exceeding the global and
+// per-chat rate limits. Messages rejected with 429 Too Many Requests are retried with backoff.
+type dispatcher struct {
+	send           func(tgbotapi.Chattable) (tgbotapi.Message, error)
+	globalInterval time.Duration
+
+	mu       sync.Mutex
+	nextSlot time.Time           // earliest time the next message may go out
+	chats    map[int64]time.Time // per chat: when its burst allowance is fully used up
+	queued   int
+
+	sent    atomic.Int64
+	retried atomic.Int64
+	dropped atomic.Int64
+}
+
+// newDispatcher creates a dispatcher that sends through send at most perSecond messages per second
+func newDispatcher(send func(tgbotapi.Chattable) (tgbotapi.Message, error), perSecond int) *dispatcher {
+	return &dispatcher{
+		send:           send,
+		globalInterval: time.Second / time.Duration(max(1, perSecond)),
+		chats:          make(map[int64]time.Time),
+	}
+}
+
+// Send waits for a free slot for the chat and sends the message, retrying on Too Many Requests
+func (d *dispatcher) Send(ctx context.Context, chatID int64, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	slot, err := d.reserve(chatID)
+	if err != nil {
+		d.drop(ctx, chatID, err)
+		return tgbotapi.Message{}, err
+	}
+	defer d.release()
+
+	for attempt := 0; ; attempt++ {
+		if err := sleepUntil(ctx, slot); err != nil {
+			d.drop(ctx, chatID, err)
+			return tgbotapi.Message{}, err
+		}
+
+		message, err := d.send(c)
+		var apiErr *tgbotapi.Error
+		if !errors.As(err, &apiErr) || apiErr.RetryAfter <= 0 {
+			if err == nil {
+				d.sent.Add(1)
+			}
+			return message, err
+		}
+
+		if attempt == maxSendRetries {
+			err = fmt.Errorf("still rate limited after %d retries: %w", maxSendRetries, err)
+			d.drop(ctx, chatID, err)
+			return tgbotapi.Message{}, err
+		}
+
+		// Telegram's flood wait applies to the whole bot, so every queued message waits it out
+		backoff := max(time.Duration(apiErr.RetryAfter)*time.Second, time.Second<<attempt)
+		d.retried.Add(1)
+		logging.FromContext(ctx).Warn("rate limited by Telegram, retrying", "chat_id", chatID, "attempt", attempt+1, "backoff", backoff)
+		slot = d.pause(backoff)
+	}
+}
+
+// Stats returns the dispatch counters
+func (d *dispatcher) Stats() DispatchStats {
+	d.mu.Lock()
+	queued := d.queued
+	d.mu.Unlock()
+
+	return DispatchStats{
+		Sent:    d.sent.Load(),
+		Retried: d.retried.Load(),
+		Dropped: d.dropped.Load(),
+		Queued:  int64(queued),
+	}
+}
+
+// reserve books the earliest send time allowed by both the global and the chat limit
+func (d *dispatcher) reserve(chatID int64) (time.Time, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.queued >= maxQueuedMessages {
+		return time.Time{}, errQueueFull
+	}
+	d.queued++
+
+	now := time.Now()
+	if len(d.chats) > chatsPruneThreshold {
+		for id, full := range d.chats {
+			if full.Before(now) {
+				delete(d.chats, id)
+			}
+		}
+	}
+
+	slot := now
+	if d.nextSlot.After(slot) {
+		slot = d.nextSlot
+	}
+	full := d.chats[chatID]
+	if chatSlot := full.Add(-(chatBurst - 1) * chatInterval); chatSlot.After(slot) {
+		slot = chatSlot
+	}
+
+	d.nextSlot = slot.Add(d.globalInterval)
+	if full.Before(slot) {
+		full = slot
+	}
+	d.chats[chatID] = full.Add(chatInterval)
+	return slot, nil
+}
+
+// pause holds back all messages for the given time and returns when sending may resume
+func (d *dispatcher) pause(wait time.Duration) time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	resume := time.Now().Add(wait)
+	if d.nextSlot.Before(resume) {
+		d.nextSlot = resume.Add(d.globalInterval)
+	}
+	return resume
+}
+
+// release frees the queue place taken by reserve
+func (d *dispatcher) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queued--
+}
+
+// drop counts a message that won't be delivered
+func (d *dispatcher) drop(ctx context.Context, chatID int64, err error) {
+	d.dropped.Add(1)
+	logging.FromContext(ctx).Warn("dropped outgoing message", "chat_id", chatID, "error", err)
+}
+
+// sleepUntil waits for t or until the context is done
+func sleepUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
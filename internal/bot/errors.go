@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"errors"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ValidationError is a permanent failure caused by user input; retrying the same action won't help
+type ValidationError struct {
+	// Message is shown to the user as is
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return "validation failed: " + e.Message
+}
+
+// isTransientError reports whether the action may succeed if the user simply retries it
+func isTransientError(err error) bool {
+	if database.IsTransient(err) {
+		return true
+	}
+
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == 429 || apiErr.Code >= 500
+	}
+	return false
+}
+
+// userErrorMessage maps an error to a message telling the user whether retrying makes sense
+func userErrorMessage(loc locale.Locale, err error) string {
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return "⚠️ " + validationErr.Message
+	case isTransientError(err):
+		return i18n.T(loc, "error.transient")
+	case errors.Is(err, database.ErrTopicExists):
+		return i18n.T(loc, "error.topic_exists")
+	case errors.Is(err, database.ErrNotFound):
+		return i18n.T(loc, "error.not_found")
+	default:
+		return i18n.T(loc, "error.unknown")
+	}
+}
@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/locale"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"sqlite busy", sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{"sqlite locked", sqlite3.Error{Code: sqlite3.ErrLocked}, true},
+		{"wrapped sqlite busy", fmt.Errorf("failed to update topic: %w", sqlite3.Error{Code: sqlite3.ErrBusy}), true},
+		{"sqlite constraint", sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{"postgres serialization failure", &pq.Error{Code: "40001"}, true},
+		{"postgres connection failure", &pq.Error{Code: "08006"}, true},
+		{"postgres unique violation", &pq.Error{Code: "23505"}, false},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), true},
+		{"telegram flood", &tgbotapi.Error{Code: 429, Message: "Too Many Requests"}, true},
+		{"telegram server", &tgbotapi.Error{Code: 502, Message: "Bad Gateway"}, true},
+		{"telegram bad request", &tgbotapi.Error{Code: 400, Message: "Bad Request"}, false},
+		{"validation", &ValidationError{Message: "too long"}, false},
+		{"not found", database.ErrNotFound, false},
+		{"plain", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransientError(tt.err); got != tt.want {
+			t.Errorf("%s: isTransientError = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUserErrorMessage(t *testing.T) {
+	const retry = "⏳ Сервис временно занят. Пожалуйста, попробуйте ещё раз через несколько секунд."
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"busy", sqlite3.Error{Code: sqlite3.ErrBusy}, retry},
+		{"locked", fmt.Errorf("failed to create topic: %w", sqlite3.Error{Code: sqlite3.ErrLocked}), retry},
+		{"validation", &ValidationError{Message: "Название темы слишком длинное"}, "⚠️ Название темы слишком длинное"},
+		{"wrapped validation", fmt.Errorf("add topic: %w", &ValidationError{Message: "Пустое название"}), "⚠️ Пустое название"},
+		{"topic exists", fmt.Errorf("failed to create topic: %w", database.ErrTopicExists), "⚠️ Такая тема уже есть. Найти похожие темы и объединить их: /merge"},
+		{"not found", database.ErrNotFound, "❌ Запись не найдена. Возможно, она уже была удалена. Откройте список тем, чтобы увидеть актуальные данные."},
+		{"other", errors.New("boom"), "❌ Произошла ошибка. Пожалуйста, попробуйте позже."},
+	}
+	for _, tt := range tests {
+		if got := userErrorMessage(locale.Russian, tt.err); got != tt.want {
+			t.Errorf("%s: message %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if got, want := userErrorMessage(locale.English, database.ErrNotFound), "❌ Record not found. It may have been deleted already. Open the topic list to see the current data."; got != want {
+		t.Errorf("english: message %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/example/engbot/internal/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleExportCommand handles /export: it sends the user's data as an .xlsx document
+func (b *Bot) handleExportCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	count, err := b.topicRepo.CountByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "export.empty")))
+	}
+
+	var buf bytes.Buffer
+	if err := b.exporter.Export(ctx, user.ID, &buf); err != nil {
+		return err
+	}
+
+	doc := tgbotapi.NewDocument(message.Chat.ID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("engbot-export-%s.xlsx", b.clock.Now().Format("2006-01-02")),
+		Bytes: buf.Bytes(),
+	})
+	doc.Caption = i18n.T(loc, "export.caption")
+	if _, err := b.dispatcher.Send(ctx, message.Chat.ID, doc); err != nil {
+		return fmt.Errorf("failed to send export: %w", err)
+	}
+	return nil
+}
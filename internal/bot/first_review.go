@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+)
+
+const (
+	// scheduledFirstReview leaves the first review of a new topic to the user's intervals
+	scheduledFirstReview = -1
+	// maxFirstReviewDays is how far the first review of a new topic can be put off
+	maxFirstReviewDays = 365
+)
+
+// firstReviewDaysPattern matches an explicit number of days like "3", "3d" or "3 дня"
+var firstReviewDaysPattern = regexp.MustCompile(`^(\d+)\s*(d|day|days|д|дн|день|дня|дней)?$`)
+
+// parseFirstReview splits "Тема | 3d" into the topic name and the days until its first review:
+// today, tomorrow or a number of days. Without "|" the first review follows the intervals.
+func parseFirstReview(loc locale.Locale, text string) (string, int, error) {
+	i := strings.LastIndex(text, "|")
+	if i < 0 {
+		return text, scheduledFirstReview, nil
+	}
+	name := strings.TrimSpace(text[:i])
+	when := strings.ToLower(strings.TrimSpace(text[i+1:]))
+	if name == "" {
+		return "", 0, &ValidationError{Message: i18n.T(loc, "first_review.no_name")}
+	}
+
+	switch when {
+	case "today", "сегодня":
+		return name, 0, nil
+	case "tomorrow", "завтра":
+		return name, 1, nil
+	}
+	match := firstReviewDaysPattern.FindStringSubmatch(when)
+	if match == nil {
+		return "", 0, &ValidationError{Message: i18n.T(loc, "first_review.unknown", when)}
+	}
+	days, err := strconv.Atoi(match[1])
+	if err != nil || days > maxFirstReviewDays {
+		return "", 0, &ValidationError{Message: i18n.T(loc, "first_review.too_far", maxFirstReviewDays)}
+	}
+	return name, days, nil
+}
+
+// firstReviewText tells when the first review of a new topic is
+func firstReviewText(loc locale.Locale, date, now time.Time) string {
+	switch startOfDay(date).Sub(startOfDay(now)).Round(time.Hour) {
+	case 0:
+		return i18n.T(loc, "day.today")
+	case 24 * time.Hour:
+		return i18n.T(loc, "day.tomorrow")
+	default:
+		return date.Format("02.01.2006")
+	}
+}
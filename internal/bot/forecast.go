@@ -0,0 +1,220 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// forecastDays is how many days /forecast shows, starting with today
+const forecastDays = 30
+
+// forecastBusiestDays is how many of the busiest days /forecast lists
+const forecastBusiestDays = 3
+
+// heatCells are the calendar cells from no repetitions to the busiest days
+var heatCells = []string{"⬜", "🟩", "🟨", "🟧", "🟥"}
+
+// handleForecastCommand handles /forecast: the repetitions of the next 30 days as a calendar,
+// "/forecast chart" sends them as a bar chart picture
+func (b *Bot) handleForecastCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	today := startOfDay(b.clock.Now())
+	dayCounts, err := b.repetitionRepo.CountByDay(ctx, user.ID, today.AddDate(0, 0, forecastDays))
+	if err != nil {
+		return err
+	}
+	counts := forecastCounts(dayCounts, today, forecastDays)
+
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "":
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, forecastText(loc, counts, today, user.DailyReviewLimit)))
+	case "chart":
+		chart, err := forecastChart(counts, user.DailyReviewLimit)
+		if err != nil {
+			return err
+		}
+		photo := tgbotapi.NewPhoto(message.Chat.ID, tgbotapi.FileBytes{Name: "forecast.png", Bytes: chart})
+		photo.Caption = i18n.T(loc, "forecast.chart", forecastDays, today.Format("02.01"), sum(counts))
+		if _, err := b.dispatcher.Send(ctx, message.Chat.ID, photo); err != nil {
+			return fmt.Errorf("failed to send forecast chart: %w", err)
+		}
+		return nil
+	default:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "forecast.usage")))
+	}
+}
+
+// forecastCounts lays the counts out by day starting with today. Overdue days count as today.
+func forecastCounts(dayCounts []database.DayCount, today time.Time, days int) []int {
+	counts := make([]int, days)
+	for _, dc := range dayCounts {
+		day, err := time.ParseInLocation("2006-01-02", dc.Day, today.Location())
+		if err != nil {
+			continue
+		}
+		index := int(math.Round(day.Sub(today).Hours() / 24))
+		if index < days {
+			counts[max(index, 0)] += dc.Count
+		}
+	}
+	return counts
+}
+
+// forecastText draws the counts as a calendar of weeks with a heat cell per day,
+// followed by the busiest days
+func forecastText(loc locale.Locale, counts []int, today time.Time, limit int) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "forecast.title", len(counts)))
+	text.WriteString(i18n.T(loc, "forecast.weekdays") + "\n")
+
+	most := maxCount(counts)
+	// Monday is the first column, the days of the first week before today stay empty
+	offset := (int(today.Weekday()) + 6) % 7
+	cells := make([]string, 0, offset+len(counts))
+	for i := 0; i < offset; i++ {
+		cells = append(cells, "▫️")
+	}
+	for _, count := range counts {
+		cells = append(cells, heatCell(count, most, limit))
+	}
+	for i := 0; i < len(cells); i += 7 {
+		text.WriteString(strings.Join(cells[i:min(i+7, len(cells))], " ") + "\n")
+	}
+
+	text.WriteString(i18n.T(loc, "forecast.legend", heatCells[0], heatCells[1], heatCells[2], heatCells[3], heatCells[4]))
+	if limit > 0 {
+		text.WriteString(i18n.T(loc, "forecast.over_limit", limit))
+	}
+	text.WriteString(i18n.T(loc, "forecast.total", sum(counts), counts[0]))
+
+	if busiest := busiestDays(counts, forecastBusiestDays); len(busiest) > 0 {
+		text.WriteString(i18n.T(loc, "forecast.busiest"))
+		for _, day := range busiest {
+			text.WriteString(fmt.Sprintf("• %s - %d\n", today.AddDate(0, 0, day).Format("02.01"), counts[day]))
+		}
+	}
+	text.WriteString(i18n.T(loc, "forecast.footer"))
+	return text.String()
+}
+
+// heatCell picks the cell for a day relative to the busiest day. Days over the limit are always red.
+func heatCell(count, most, limit int) string {
+	switch {
+	case count == 0:
+		return heatCells[0]
+	case limit > 0 && count > limit:
+		return heatCells[len(heatCells)-1]
+	}
+	level := 1 + (count*(len(heatCells)-1)-1)/most
+	return heatCells[min(level, len(heatCells)-1)]
+}
+
+// busiestDays returns the indexes of up to n days with the most repetitions, busiest first
+func busiestDays(counts []int, n int) []int {
+	var days []int
+	for day, count := range counts {
+		if count > 0 {
+			days = append(days, day)
+		}
+	}
+	sort.SliceStable(days, func(i, j int) bool { return counts[days[i]] > counts[days[j]] })
+	return days[:min(n, len(days))]
+}
+
+// Chart geometry and colors of /forecast chart
+const (
+	chartBarWidth = 20
+	chartGap      = 6
+	chartHeight   = 240
+	chartPadding  = 16
+)
+
+var (
+	chartBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	chartBar        = color.RGBA{R: 0x4c, G: 0xaf, B: 0x50, A: 0xff}
+	chartToday      = color.RGBA{R: 0x21, G: 0x96, B: 0xf3, A: 0xff}
+	chartOverLimit  = color.RGBA{R: 0xf4, G: 0x43, B: 0x36, A: 0xff}
+	chartGrid       = color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+	chartLimit      = color.RGBA{R: 0xff, G: 0x98, B: 0x00, A: 0xff}
+)
+
+// forecastChart draws the counts as a PNG bar chart: today in blue, days over the limit in red,
+// a thin line every seven days and the limit as an orange line
+func forecastChart(counts []int, limit int) ([]byte, error) {
+	width := 2*chartPadding + len(counts)*(chartBarWidth+chartGap) - chartGap
+	height := 2*chartPadding + chartHeight
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: chartBackground}, image.Point{}, draw.Src)
+
+	top := max(maxCount(counts), limit)
+	bottom := chartPadding + chartHeight
+	barHeight := func(count int) int { return count * chartHeight / top }
+
+	for day, count := range counts {
+		x := chartPadding + day*(chartBarWidth+chartGap)
+		if day > 0 && day%7 == 0 {
+			fill(img, image.Rect(x-chartGap/2-1, chartPadding, x-chartGap/2, bottom), chartGrid)
+		}
+		c := chartBar
+		switch {
+		case limit > 0 && count > limit:
+			c = chartOverLimit
+		case day == 0:
+			c = chartToday
+		}
+		fill(img, image.Rect(x, bottom-barHeight(count), x+chartBarWidth, bottom), c)
+	}
+	fill(img, image.Rect(chartPadding, bottom, width-chartPadding, bottom+1), chartGrid)
+	if limit > 0 {
+		y := bottom - barHeight(limit)
+		fill(img, image.Rect(chartPadding, y-1, width-chartPadding, y+1), chartLimit)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode forecast chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fill paints the rectangle of img with c
+func fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+// maxCount returns the largest count, at least 1 so it can divide
+func maxCount(counts []int) int {
+	most := 1
+	for _, count := range counts {
+		most = max(most, count)
+	}
+	return most
+}
+
+// sum adds up the counts
+func sum(counts []int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
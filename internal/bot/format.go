@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// markdownV2Escaper escapes the characters MarkdownV2 reserves. tgbotapi.EscapeText misses the
+// backslash, so a topic name ending with one would escape the markup after it.
+var markdownV2Escaper = strings.NewReplacer(
+	"\\", "\\\\", "_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)",
+	"~", "\\~", "`", "\\`", ">", "\\>", "#", "\\#", "+", "\\+", "-", "\\-", "=", "\\=",
+	"|", "\\|", "{", "\\{", "}", "\\}", ".", "\\.", "!", "\\!",
+)
+
+// markdownV2CodeEscaper escapes the characters MarkdownV2 reserves inside code
+var markdownV2CodeEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+
+// richText builds a message in one of Telegram's parse modes: tgbotapi.ModeHTML,
+// tgbotapi.ModeMarkdownV2 or "" for plain text, where the markup is left out. Everything but the
+// markup is escaped, so topic names and other user text can't break the formatting.
+type richText struct {
+	mode string
+	text strings.Builder
+}
+
+// newRichText starts a message in the parse mode. Legacy Markdown can't escape inside bold text,
+// so it and unknown modes fall back to plain text.
+func newRichText(mode string) *richText {
+	if mode != tgbotapi.ModeHTML && mode != tgbotapi.ModeMarkdownV2 {
+		mode = ""
+	}
+	return &richText{mode: mode}
+}
+
+// escape escapes s for the parse mode
+func (t *richText) escape(s string) string {
+	switch t.mode {
+	case tgbotapi.ModeHTML:
+		return html.EscapeString(s)
+	case tgbotapi.ModeMarkdownV2:
+		return markdownV2Escaper.Replace(s)
+	default:
+		return s
+	}
+}
+
+// wrap adds s escaped between the HTML tag or the MarkdownV2 delimiter
+func (t *richText) wrap(tag, delimiter, s string) *richText {
+	switch t.mode {
+	case tgbotapi.ModeHTML:
+		t.text.WriteString("<" + tag + ">" + html.EscapeString(s) + "</" + tag + ">")
+	case tgbotapi.ModeMarkdownV2:
+		t.text.WriteString(delimiter + markdownV2Escaper.Replace(s) + delimiter)
+	default:
+		t.text.WriteString(s)
+	}
+	return t
+}
+
+// Text adds plain text
+func (t *richText) Text(s string) *richText {
+	t.text.WriteString(t.escape(s))
+	return t
+}
+
+// Textf adds plain text formatted with fmt.Sprintf, the arguments are escaped too
+func (t *richText) Textf(format string, args ...any) *richText {
+	return t.Text(fmt.Sprintf(format, args...))
+}
+
+// Bold adds bold text
+func (t *richText) Bold(s string) *richText {
+	return t.wrap("b", "*", s)
+}
+
+// Italic adds italic text
+func (t *richText) Italic(s string) *richText {
+	return t.wrap("i", "_", s)
+}
+
+// Spoiler adds text hidden until tapped
+func (t *richText) Spoiler(s string) *richText {
+	return t.wrap("tg-spoiler", "||", s)
+}
+
+// Code adds monospace text
+func (t *richText) Code(s string) *richText {
+	switch t.mode {
+	case tgbotapi.ModeHTML:
+		t.text.WriteString("<code>" + html.EscapeString(s) + "</code>")
+	case tgbotapi.ModeMarkdownV2:
+		t.text.WriteString("`" + markdownV2CodeEscaper.Replace(s) + "`")
+	default:
+		t.text.WriteString(s)
+	}
+	return t
+}
+
+// String returns the message text
+func (t *richText) String() string {
+	return t.text.String()
+}
+
+// Message creates the message to the chat with the text and its parse mode
+func (t *richText) Message(chatID int64) tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(chatID, t.String())
+	msg.ParseMode = t.mode
+	return msg
+}
+
+// Edit replaces the text of the message with this one and its parse mode
+func (t *richText) Edit(chatID int64, messageID int, markup tgbotapi.InlineKeyboardMarkup) tgbotapi.EditMessageTextConfig {
+	msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, t.String(), markup)
+	msg.ParseMode = t.mode
+	return msg
+}
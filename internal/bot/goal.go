@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxDailyGoal bounds the daily goal a user can set
+const maxDailyGoal = 500
+
+// goalBarWidth is the number of cells in the daily goal progress bar
+const goalBarWidth = 10
+
+// handleGoalCommand handles /goal: without arguments it shows today's progress and the streak,
+// "/goal <число>" sets the daily goal and "/goal off" removes it
+func (b *Bot) handleGoalCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if args == "" {
+		return b.sendGoalStatus(ctx, message.Chat.ID, user)
+	}
+
+	goal := 0
+	if args != "off" && args != "0" {
+		goal, err = strconv.Atoi(args)
+		if err != nil || goal < 1 || goal > maxDailyGoal {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "goal.usage", maxDailyGoal)))
+		}
+	}
+
+	user.DailyGoal = goal
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	text := i18n.T(loc, "goal.off")
+	if goal > 0 {
+		text = i18n.T(loc, "goal.set", goal, i18n.Plural(loc, "plural.review", goal))
+	}
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, text))
+}
+
+// sendGoalStatus shows the daily goal, today's progress and the streak
+func (b *Bot) sendGoalStatus(ctx context.Context, chatID int64, user *models.User) error {
+	today, err := b.activityRepo.GetToday(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	streak, err := b.activityRepo.GetStreak(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "goal.title"))
+	text.WriteString(goalSummary(loc, user.DailyGoal, today, streak))
+	text.WriteString(i18n.T(loc, "goal.hint", maxDailyGoal))
+	return b.sendMessage(tgbotapi.NewMessage(chatID, text.String()))
+}
+
+// recordReview counts a review toward the user's daily goal. Failures only cost the streak
+// a review, so they are logged instead of failing the review.
+func (b *Bot) recordReview(ctx context.Context, userID int64) {
+	if err := b.activityRepo.RecordReview(ctx, userID); err != nil {
+		logging.FromContext(ctx).Warn("failed to record review", "user_id", userID, "error", err)
+	}
+}
+
+// goalSummary renders today's progress toward the goal and the streak
+func goalSummary(loc locale.Locale, goal int, today *models.DailyActivity, streak int) string {
+	text := goalProgressText(loc, goal, today.Reviews) + streakText(loc, streak)
+	if today.Protected && !today.GoalMet() {
+		text += i18n.T(loc, "goal.protected")
+	}
+	return text
+}
+
+// goalProgressText renders today's reviews against the daily goal
+func goalProgressText(loc locale.Locale, goal, reviews int) string {
+	if goal == 0 {
+		return i18n.T(loc, "goal.no_goal", reviews, i18n.Plural(loc, "plural.review", reviews))
+	}
+
+	filled := min(reviews, goal) * goalBarWidth / goal
+	bar := strings.Repeat("▓", filled) + strings.Repeat("░", goalBarWidth-filled)
+	text := i18n.T(loc, "goal.progress", reviews, goal, bar)
+	if reviews >= goal {
+		text += i18n.T(loc, "goal.met")
+	}
+	return text
+}
+
+// streakText renders the streak line, empty when there is no streak
+func streakText(loc locale.Locale, streak int) string {
+	if streak == 0 {
+		return ""
+	}
+	return i18n.T(loc, "goal.streak", streakEmoji(streak), streak, i18n.Plural(loc, "plural.day", streak))
+}
+
+// streakEmoji grows with the streak
+func streakEmoji(streak int) string {
+	switch {
+	case streak >= 100:
+		return "👑🔥"
+	case streak >= 30:
+		return "🏆🔥"
+	case streak >= 7:
+		return "🔥🔥🔥"
+	case streak >= 3:
+		return "🔥🔥"
+	default:
+		return "🔥"
+	}
+}
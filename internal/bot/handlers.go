@@ -2,30 +2,49 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/scheduler"
+	"github.com/example/engbot/internal/service"
+	"github.com/example/engbot/internal/spaced_repetition"
+	"github.com/example/engbot/internal/textutil"
 	"github.com/example/engbot/pkg/models"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // Constants for callback data
 const (
-	callbackStartAddTopic = "start_add_topic"
-	callbackCancelAction  = "cancel_action"
+	callbackStartAddTopic     = "start_add_topic"
+	callbackCancelAction      = "cancel_action"
+	callbackConfirmRestartAll = "restartall_confirm"
+	callbackSimilarCreate     = "similar_topic_create"
+	callbackSimilarKeep       = "similar_topic_keep"
 )
 
-// UserState represents the current state of user interaction
-type UserState struct {
-	Action    string
-	Step      int
-	Data      map[string]string
-}
+// callbackTopicsPagePrefix opens the topic list page with the number that follows (from 0)
+const callbackTopicsPagePrefix = "topics_page_"
+
+// callbackGradePrefix carries the answer quality for a topic repetition: grade_<repID>_<quality>
+const callbackGradePrefix = "grade_"
 
-var userStates = make(map[int64]*UserState)
+// Repetition notes
+const (
+	callbackAddNotePrefix = "note_"
+	actionAddingNote      = "adding_note"
+	maxNoteLength         = 500
+)
+
+// similarTopicMaxDistance is the edit distance under which topic names are considered near-duplicates
+const similarTopicMaxDistance = 2
 
 // HandleCommand handles bot commands
 func (b *Bot) HandleCommand(ctx context.Context, message *tgbotapi.Message) error {
@@ -36,23 +55,117 @@ func (b *Bot) HandleCommand(ctx context.Context, message *tgbotapi.Message) erro
 	case "help":
 		err = b.handleHelp(message)
 	case "add":
-		err = b.handleAddTopic(message)
+		if args := strings.TrimSpace(message.CommandArguments()); strings.Contains(args, "\n") {
+			err = b.addTopics(ctx, message, args)
+		} else if args != "" {
+			// One topic goes the same way as a name sent after "Добавить тему"
+			quick := *message
+			quick.Text = args
+			err = b.handleAddTopicText(ctx, &quick)
+		} else {
+			err = b.handleAddTopic(message)
+		}
+	case "addmany":
+		err = b.handleAddManyCommand(ctx, message)
 	case "list":
 		err = b.handleListTopics(ctx, message)
 	case "delete":
 		err = b.handleDeleteTopic(ctx, message)
+	case "edit":
+		err = b.handleEditCommand(ctx, message)
+	case "difficulty":
+		err = b.handleDifficultyCommand(ctx, message)
+	case "restartall":
+		err = b.handleRestartAllCommand(message)
+	case "history":
+		err = b.handleHistoryCommand(ctx, message)
+	case "maintenance":
+		err = b.handleMaintenanceCommand(ctx, message)
+	case "topicintervals":
+		err = b.handleTopicIntervalsCommand(ctx, message)
+	case "attach":
+		err = b.handleAttachCommand(ctx, message)
+	case "move":
+		err = b.handleMoveCommand(ctx, message)
+	case "merge":
+		err = b.handleMergeCommand(ctx, message)
+	case "describe":
+		err = b.handleDescribeCommand(ctx, message)
+	case "search":
+		err = b.handleSearchCommand(ctx, message)
+	case "language":
+		err = b.handleLanguageCommand(ctx, message)
+	case "archive":
+		err = b.handleArchiveCommand(ctx, message)
+	case "review":
+		err = b.handleReview(ctx, message)
 	case "stats":
 		err = b.handleStats(ctx, message)
+	case "export":
+		err = b.handleExportCommand(ctx, message)
+	case "anki":
+		err = b.handleAnkiCommand(ctx, message)
+	case "decks":
+		err = b.handleDecksCommand(ctx, message)
+	case "plans":
+		err = b.handlePlansCommand(ctx, message)
+	case "goal":
+		err = b.handleGoalCommand(ctx, message)
+	case "quiz":
+		err = b.handleQuizCommand(ctx, message)
+	case "cram":
+		err = b.handleCramCommand(ctx, message)
+	case "hard":
+		err = b.handleHardCommand(ctx, message)
+	case "story":
+		err = b.handleStoryCommand(ctx, message)
+	case "practice":
+		err = b.handlePracticeCommand(ctx, message)
+	case "wordofday":
+		err = b.handleWordOfDayCommand(ctx, message)
 	case "settings":
 		err = b.handleSettings(ctx, message)
 	case "notify":
 		err = b.handleNotifyCommand(ctx, message)
 	case "time":
 		err = b.handleTimeCommand(ctx, message)
+	case "channels":
+		err = b.handleChannelsCommand(ctx, message)
+	case "skipfirst":
+		err = b.handleSkipFirstCommand(ctx, message)
+	case "overdue":
+		err = b.handleOverdueCommand(ctx, message)
+	case "load":
+		err = b.handleLoadCommand(ctx, message)
+	case "direction":
+		err = b.handleDirectionCommand(ctx, message)
+	case "newwords":
+		err = b.handleNewWordsCommand(ctx, message)
+	case "forecast":
+		err = b.handleForecastCommand(ctx, message)
+	case "report":
+		err = b.handleReportCommand(ctx, message)
+	case "intervals":
+		err = b.handleIntervalsCommand(ctx, message)
+	case "news":
+		err = b.handleNewsCommand(ctx, message)
+	case "broadcast":
+		err = b.handleBroadcastCommand(ctx, message)
+	case "admin":
+		err = b.handleAdminCommand(ctx, message)
+	case "app":
+		err = b.handleAppCommand(ctx, message)
+	case "calendar":
+		err = b.handleCalendarCommand(ctx, message)
 	default:
 		err = b.handleUnknownCommand(message)
 	}
-	return err
+
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to handle command", "command", message.Command(), "error", err)
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, userErrorMessage(b.localeFor(ctx, message.From.ID), err)))
+	}
+	return nil
 }
 
 func (b *Bot) handleStart(message *tgbotapi.Message) error {
@@ -61,87 +174,57 @@ func (b *Bot) handleStart(message *tgbotapi.Message) error {
 	}
 
 	// Создаем пользователя при первом взаимодействии
-	_, err := b.userRepo.GetByTelegramID(context.Background(), message.From.ID)
+	user, err := b.getOrCreateUser(context.Background(), message.From)
 	if err != nil {
-		// Если пользователь не найден, создаем его
-		newUser := &models.User{
-			TelegramID:          message.From.ID,
-			Username:            message.From.UserName,
-			FirstName:           message.From.FirstName,
-			LastName:            message.From.LastName,
-			NotificationEnabled: true,
-			NotificationHour:    9,
-		}
-		
-		if err = b.userRepo.Create(context.Background(), newUser); err != nil {
-			return fmt.Errorf("failed to create user: %w", err)
-		}
+		return err
 	}
+	// Пользователь, заблокировавший бота, снова его запустил
+	b.reactivate(context.Background(), user)
+	loc := b.userLocale(user)
 
-	text := "👋 Добро пожаловать в Spaced Repetition Manager!\n\n" +
-		"Я помогу вам эффективно изучать темы с помощью метода интервального повторения.\n\n" +
-		"🔹 Как это работает:\n" +
-		"1. Добавьте тему для изучения\n" +
-		"2. Получайте уведомления о повторении\n" +
-		"3. Отмечайте выполненные повторения\n" +
-		"4. Отслеживайте свой прогресс"
-
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
-	msg.ReplyMarkup = createKeyboard(b.MainMenuButtons())
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "start.welcome"))
+	msg.ReplyMarkup = createKeyboard(b.mainMenuButtons(loc))
 	return b.sendMessage(msg)
 }
 
 func (b *Bot) handleHelp(message *tgbotapi.Message) error {
-	text := "📖 Справка по использованию бота\n\n" +
-		"🔸 Основные команды:\n" +
-		"/start - Запустить бота и показать главное меню\n" +
-		"/help - Показать эту справку\n\n" +
-		
-		"📚 Управление темами:\n" +
-		"/add - Добавить новую тему\n" +
-		"/list - Показать список всех тем\n" +
-		"/delete - Удалить тему\n\n" +
-		
-		"⚙️ Настройки:\n" +
-		"/notify on|off - Включить/выключить уведомления\n" +
-		"/time - Установить время уведомлений\n\n" +
-		
-		"🔄 Интервалы повторения:\n" +
-		"1️⃣ Через 1 день\n" +
-		"2️⃣ Через 2 дня\n" +
-		"3️⃣ Через 3 дня\n" +
-		"4️⃣ Через 7 дней\n" +
-		"5️⃣ Через 15 дней\n" +
-		"6️⃣ Через 25 дней\n" +
-		"7️⃣ Через 40 дней\n\n" +
-		
-		"💡 Советы:\n" +
-		"• Регулярно отмечайте выполненные повторения\n" +
-		"• Следите за статистикой прогресса\n" +
-		"• Настройте удобное время уведомлений"
+	loc := b.localeFor(context.Background(), message.From.ID)
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "help.text"))
 	msg.ReplyMarkup = createKeyboard([][]MenuButton{
-		{{Text: "⬅️ Вернуться в меню", CallbackData: "main_menu"}},
+		{{Text: i18n.T(loc, "menu.back"), CallbackData: "main_menu"}},
 	})
 	return b.sendMessage(msg)
 }
 
 func (b *Bot) handleAddTopic(message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(context.Background(), message.From)
+	if err != nil {
+		return err
+	}
+	limitReached, err := b.topicLimitReached(context.Background(), user)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if limitReached {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, b.topicLimitText(loc)))
+	}
+
 	// Set user state to adding topic
-	userStates[message.From.ID] = &UserState{
+	b.states.put(message.From.ID, &UserState{
 		Action: "adding_topic",
 		Step:   1,
 		Data:   make(map[string]string),
-	}
+	})
 
-	text := "📝 *Добавление новой темы*\n\n" +
-		"Пожалуйста, отправьте название темы, которую хотите добавить.\n" +
-		"Например: \"Английская грамматика\" или \"Алгоритмы сортировки\""
+	text := newRichText(tgbotapi.ModeHTML).
+		Bold(i18n.T(loc, "add.title")).
+		Text(i18n.T(loc, "add.prompt"))
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg := text.Message(message.Chat.ID)
 	msg.ReplyMarkup = createKeyboard([][]MenuButton{
-		{{Text: "❌ Отмена", CallbackData: "cancel_action"}},
+		{{Text: i18n.T(loc, "button.cancel"), CallbackData: "cancel_action"}},
 	})
 	
 	return b.sendMessage(msg)
@@ -152,61 +235,60 @@ func (b *Bot) handleListTopics(ctx context.Context, message *tgbotapi.Message) e
 		return fmt.Errorf("message.From is nil")
 	}
 
-	log.Printf("Listing topics for telegram_id: %d", message.From.ID)
+	text, markup, err := b.topicListPage(ctx, message.From, 0)
+	if err != nil {
+		return err
+	}
+
+	msg := text.Message(message.Chat.ID)
+	msg.ReplyMarkup = markup
+	return b.sendMessage(msg)
+}
+
+// handleTopicsPage shows another page of the topic list in the same message
+func (b *Bot) handleTopicsPage(ctx context.Context, callback *tgbotapi.CallbackQuery, page int) error {
+	text, markup, err := b.topicListPage(ctx, callback.From, page)
+	if err != nil {
+		return err
+	}
 
+	return b.editMessage(text.Edit(callback.Message.Chat.ID, callback.Message.MessageID, markup))
+}
+
+// topicListPage renders one page of the user's topics as cards with their progress through the
+// cycle and a row of buttons each. Topics keep their position in the whole list, so the numbers
+// match /delete, /edit and /history.
+func (b *Bot) topicListPage(ctx context.Context, from *tgbotapi.User, page int) (*richText, tgbotapi.InlineKeyboardMarkup, error) {
 	// Get or create user first
-	user, err := b.userRepo.GetByTelegramID(ctx, message.From.ID)
-	if err != nil || user == nil {
-		log.Printf("User not found or error: %v", err)
-		// Create new user if not found
-		newUser := &models.User{
-			TelegramID:          message.From.ID,
-			Username:            message.From.UserName,
-			FirstName:           message.From.FirstName,
-			LastName:            message.From.LastName,
-			NotificationEnabled: true,
-			NotificationHour:    9,
-		}
-		
-		if err = b.userRepo.Create(ctx, newUser); err != nil {
-			log.Printf("Failed to create user: %v", err)
-			return fmt.Errorf("failed to create user: %w", err)
-		}
-		
-		// Get the created user to get their ID
-		user, err = b.userRepo.GetByTelegramID(ctx, message.From.ID)
-		if err != nil {
-			log.Printf("Failed to get created user: %v", err)
-			return fmt.Errorf("failed to get created user: %w", err)
-		}
+	user, err := b.getOrCreateUser(ctx, from)
+	if err != nil {
+		return nil, tgbotapi.InlineKeyboardMarkup{}, err
 	}
 
-	if user == nil || user.ID == 0 {
-		log.Printf("User is nil or has ID=0")
-		msg := tgbotapi.NewMessage(message.Chat.ID, "❌ Ошибка: не удалось получить профиль пользователя")
-		return b.sendMessage(msg)
+	if user.ID == 0 {
+		logging.FromContext(ctx).Error("user is nil or has no ID")
+		return nil, tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("user %d has no ID", from.ID)
 	}
 
-	log.Printf("Getting topics for user_id: %d", user.ID)
 	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
 	if err != nil {
-		log.Printf("Failed to get topics: %v", err)
-		return fmt.Errorf("failed to get topics: %w", err)
+		logging.FromContext(ctx).Error("failed to get topics", "user_id", user.ID, "error", err)
+		return nil, tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get topics: %w", err)
 	}
 
-	log.Printf("Found %d topics", len(topics))
+	logging.FromContext(ctx).Debug("listing topics", "user_id", user.ID, "count", len(topics))
 
+	loc := b.userLocale(user)
 	if len(topics) == 0 {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "У вас пока нет добавленных тем. Нажмите кнопку \"📝 Добавить тему\" чтобы начать.")
-		msg.ReplyMarkup = createKeyboard(b.MainMenuButtons())
-		return b.sendMessage(msg)
+		return newRichText(tgbotapi.ModeHTML).Text(i18n.T(loc, "topics.none")),
+			createKeyboard(b.mainMenuButtons(loc)), nil
 	}
 
 	// Получаем все повторения для пользователя одним запросом
-	repetitions, err := b.repetitionRepo.GetDueRepetitions(ctx, user.ID)
+	repetitions, err := b.repetitionRepo.GetAllByUserID(ctx, user.ID)
 	if err != nil {
-		log.Printf("Failed to get repetitions: %v", err)
-		return fmt.Errorf("failed to get repetitions: %w", err)
+		logging.FromContext(ctx).Error("failed to get repetitions", "user_id", user.ID, "error", err)
+		return nil, tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get repetitions: %w", err)
 	}
 
 	// Создаем мапу для быстрого доступа к повторениям по ID темы
@@ -215,48 +297,87 @@ func (b *Bot) handleListTopics(ctx context.Context, message *tgbotapi.Message) e
 		topicRepetitions[rep.TopicID] = append(topicRepetitions[rep.TopicID], rep)
 	}
 
-	var text strings.Builder
-	text.WriteString("📋 Ваши темы:\n\n")
-	
-	var keyboard [][]tgbotapi.InlineKeyboardButton
-	for i, topic := range topics {
-		// Добавляем информацию о теме
-		text.WriteString(fmt.Sprintf("%d. %s\n", i+1, topic.Name))
-
-		// Проверяем, есть ли активные повторения для этой темы
-		if reps, ok := topicRepetitions[topic.ID]; ok && len(reps) > 0 {
-			text.WriteString("🔄 Требует повторения!\n")
-			// Добавляем кнопку для отметки повторения
-			button := tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("✅ Повторил тему \"%s\"", topic.Name),
-				fmt.Sprintf("complete_%d", reps[0].ID),
-			)
-			keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{button})
-		} else {
-			text.WriteString("✅ Нет активных повторений\n")
+	pageSize := max(1, b.config.TopicsPerPage)
+	pages := (len(topics) + pageSize - 1) / pageSize
+	page = max(0, min(page, pages-1))
+	start := page * pageSize
+	now := b.clock.Now()
+
+	text := newRichText(tgbotapi.ModeHTML).Text(i18n.T(loc, "topics.title"))
+
+	var keyboard [][]MenuButton
+	for i, topic := range topics[start:min(start+pageSize, len(topics))] {
+		number := start + i + 1
+		done, pending := topicProgress(topicRepetitions[topic.ID])
+		due := isDue(pending, now)
+
+		// Добавляем карточку темы
+		text.Textf("%d. %s", number, strings.TrimSuffix(topicLabel(topic), topic.Name)).Bold(topic.Name).Text("\n")
+		text.Text(progressBar(done, service.CycleRepetitions))
+		switch {
+		case pending != nil && pending.Maintenance && !topic.Archived:
+			text.Text(" · ♾ " + nextReviewText(loc, pending.NextReviewDate, now))
+		case pending != nil && !topic.Archived:
+			text.Text(" · " + nextReviewText(loc, pending.NextReviewDate, now))
+		case done == service.CycleRepetitions:
+			text.Text(i18n.T(loc, "topics.cycle_done"))
 		}
-		text.WriteString("\n")
+		text.Text("\n")
+		if end := subtreeEnd(topics, start+i); end > start+i+1 {
+			subDue := 0
+			for _, sub := range topics[start+i+1 : end] {
+				if _, subPending := topicProgress(topicRepetitions[sub.ID]); !sub.Archived && isDue(subPending, now) {
+					subDue++
+				}
+			}
+			text.Text(i18n.T(loc, "topics.subtopics", end-start-i-1, subDue))
+		}
+		text.Text(i18n.T(loc, "topics.difficulty", difficultyLabel(loc, topic.Difficulty), topic.Difficulty))
+		if topic.Category != "" {
+			text.Text(i18n.T(loc, "topics.category", topic.Category))
+		}
+		switch {
+		case topic.Archived:
+			text.Text(i18n.T(loc, "topics.archived"))
+		case topic.Muted:
+			text.Text(i18n.T(loc, "topics.muted"))
+		}
+		if due && !topic.Archived {
+			text.Text(i18n.T(loc, "topics.due"))
+		}
+		text.Text("\n")
+
+		keyboard = append(keyboard, topicCardButtons(number, topic, pending, due && !topic.Archived))
 	}
+	text.Text(i18n.T(loc, "topics.legend"))
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
-	if len(keyboard) > 0 {
-		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
-	} else {
-		msg.ReplyMarkup = createKeyboard(b.MainMenuButtons())
+	if pages > 1 {
+		text.Text(i18n.T(loc, "topics.page", page+1, pages))
+
+		var nav []MenuButton
+		if page > 0 {
+			nav = append(nav, MenuButton{Text: "◀️", CallbackData: fmt.Sprintf("%s%d", callbackTopicsPagePrefix, page-1)})
+		}
+		if page < pages-1 {
+			nav = append(nav, MenuButton{Text: "▶️", CallbackData: fmt.Sprintf("%s%d", callbackTopicsPagePrefix, page+1)})
+		}
+		keyboard = append(keyboard, nav)
 	}
-	return b.sendMessage(msg)
+
+	return text, createKeyboard(keyboard), nil
 }
 
 func (b *Bot) handleDeleteTopic(ctx context.Context, message *tgbotapi.Message) error {
+	loc := b.localeFor(ctx, message.From.ID)
 	args := message.CommandArguments()
 	if args == "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Пожалуйста, укажите номер темы для удаления: /delete <номер>")
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "delete.usage"))
 		return b.sendMessage(msg)
 	}
 
 	index, err := strconv.Atoi(args)
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Пожалуйста, укажите корректный номер темы")
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topic.invalid_number"))
 		return b.sendMessage(msg)
 	}
 
@@ -271,62 +392,169 @@ func (b *Bot) handleDeleteTopic(ctx context.Context, message *tgbotapi.Message)
 	}
 
 	if index < 1 || index > len(topics) {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Указан неверный номер темы")
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topic.wrong_number"))
 		return b.sendMessage(msg)
 	}
 
 	topic := topics[index-1]
-	if err := b.topicRepo.Delete(ctx, user.ID, topic.ID); err != nil {
+	trashID, err := b.topicRepo.Delete(ctx, user.ID, topic.ID)
+	if err != nil {
 		return fmt.Errorf("failed to delete topic: %w", err)
 	}
 
-	text := fmt.Sprintf("Тема \"%s\" удалена", topic.Name)
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	text := i18n.T(loc, "delete.done", topic.Name)
+	if subtopics := subtreeEnd(topics, index-1) - index; subtopics > 0 {
+		text += i18n.T(loc, "delete.done_subtopics", subtopics)
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, text+"\n\n"+b.undoHint(loc))
+	msg.ReplyMarkup = createKeyboard(append([][]MenuButton{undoDeleteButton(loc, trashID)}, b.topicsMenuButtons(loc)...))
 	return b.sendMessage(msg)
 }
 
+func (b *Bot) handleDifficultyCommand(ctx context.Context, message *tgbotapi.Message) error {
+	loc := b.localeFor(ctx, message.From.ID)
+	usage := i18n.T(loc, "difficulty.usage")
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topic.invalid_number")))
+	}
+
+	difficulty, err := strconv.Atoi(args[1])
+	if err != nil || difficulty < models.MinTopicDifficulty || difficulty > models.MaxTopicDifficulty {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+
+	user, err := b.userRepo.GetByTelegramID(ctx, message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topics.none")))
+	}
+
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+
+	if index < 1 || index > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topic.wrong_number")))
+	}
+
+	topic := topics[index-1]
+	topic.Difficulty = difficulty
+	if err := b.topicRepo.Update(ctx, &topic); err != nil {
+		return fmt.Errorf("failed to update topic difficulty: %w", err)
+	}
+
+	text := i18n.T(loc, "difficulty.done", topic.Name, difficultyLabel(loc, difficulty), difficulty)
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, text))
+}
+
+func (b *Bot) handleRestartAllCommand(message *tgbotapi.Message) error {
+	loc := b.localeFor(context.Background(), message.From.ID)
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "restartall.confirm"))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "restartall.button"), CallbackData: callbackConfirmRestartAll}},
+		{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+	})
+	return b.sendMessage(msg)
+}
+
+func (b *Bot) handleRestartAllConfirm(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	loc := b.userLocale(user)
+	if user == nil {
+		return b.sendMessage(tgbotapi.NewMessage(callback.Message.Chat.ID, i18n.T(loc, "topics.none")))
+	}
+
+	count, err := b.repetitionRepo.RestartAll(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to restart repetitions: %w", err)
+	}
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		i18n.T(loc, "restartall.done", count),
+		createKeyboard(b.mainMenuButtons(loc)),
+	)
+	return b.editMessage(msg)
+}
+
 func (b *Bot) handleStats(ctx context.Context, message *tgbotapi.Message) error {
 	// Get user by telegram ID first
-	user, err := b.userRepo.GetByTelegramID(ctx, message.From.ID)
-	if err != nil || user == nil {
-		// Create new user if not found
-		newUser := &models.User{
-			TelegramID:          message.From.ID,
-			Username:            message.From.UserName,
-			FirstName:           message.From.FirstName,
-			LastName:            message.From.LastName,
-			NotificationEnabled: true,
-			NotificationHour:    9,
-		}
-		
-		if err = b.userRepo.Create(ctx, newUser); err != nil {
-			return fmt.Errorf("failed to create user: %w", err)
-		}
-		
-		// Get the created user to get their ID
-		user, err = b.userRepo.GetByTelegramID(ctx, message.From.ID)
-		if err != nil {
-			return fmt.Errorf("failed to get created user: %w", err)
-		}
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
 	}
 	
-	if user == nil || user.ID == 0 {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "❌ Ошибка: не удалось получить профиль пользователя")
+	loc := b.userLocale(user)
+	if user.ID == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "error.no_profile"))
 		return b.sendMessage(msg)
 	}
 
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if args == "charts" {
+		return b.sendStatsCharts(ctx, message.Chat.ID, user)
+	}
+	if index, err := strconv.Atoi(args); err == nil {
+		return b.handleTopicStatsCommand(ctx, message, user, index)
+	}
+
 	stats, err := b.statsRepo.GetUserStatistics(ctx, user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to get statistics: %w", err)
 	}
+	today, err := b.activityRepo.GetToday(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	streak, err := b.activityRepo.GetStreak(ctx, user.ID)
+	if err != nil {
+		return err
+	}
 
-	if len(stats) == 0 {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "У вас пока нет статистики. Добавьте темы для повторения!")
+	if len(stats) == 0 && today.Reviews == 0 && streak == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "stats.none"))
 		return b.sendMessage(msg)
 	}
 
 	var text strings.Builder
-	text.WriteString("📊 Ваша статистика\n\n")
+	text.WriteString(i18n.T(loc, "stats.title"))
+	text.WriteString(goalSummary(loc, user.DailyGoal, today, streak))
+	text.WriteString("\n")
+
+	// Фразы и словосочетания считаются отдельно от слов
+	kinds, err := b.wordRepo.CountByKind(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if kindsText := wordKindsText(loc, kinds); kindsText != "" {
+		text.WriteString(kindsText)
+		text.WriteString("\n")
+	}
+
+	// Родительские темы показывают еще и итог вместе с подтемами
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if user.Scheduler == models.SchedulerLeitner {
+		text.WriteString(leitnerBoxesText(loc, topics))
+		text.WriteString("\n")
+	}
+	rolled := rollUpStatistics(topics, stats)
 
 	for _, stat := range stats {
 		completionRate := 0.0
@@ -334,12 +562,23 @@ func (b *Bot) handleStats(ctx context.Context, message *tgbotapi.Message) error
 			completionRate = float64(stat.CompletedRepetitions) / float64(stat.TotalRepetitions) * 100
 		}
 
-		text.WriteString(fmt.Sprintf("Тема: %s\n", stat.TopicName))
-		text.WriteString(fmt.Sprintf("Всего повторений: %d\n", stat.TotalRepetitions))
-		text.WriteString(fmt.Sprintf("Выполнено: %d (%.1f%%)\n\n", stat.CompletedRepetitions, completionRate))
+		text.WriteString(i18n.T(loc, "stats.topic", stat.TopicName, stat.TotalRepetitions, stat.CompletedRepetitions, completionRate))
+		if total, ok := rolled[stat.TopicID]; ok && total.TotalRepetitions > 0 {
+			rate := float64(total.CompletedRepetitions) / float64(total.TotalRepetitions) * 100
+			text.WriteString(i18n.T(loc, "stats.subtopics", total.CompletedRepetitions, total.TotalRepetitions, rate))
+		}
+		text.WriteString("\n")
+	}
+
+	if len(stats) > 0 {
+		text.WriteString(i18n.T(loc, "stats.details"))
 	}
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
+	msg.ReplyMarkup = createKeyboard(append(topicStatsButtons(stats),
+		[]MenuButton{{Text: i18n.T(loc, "stats.charts_button"), CallbackData: callbackStatsCharts}},
+		[]MenuButton{{Text: i18n.T(loc, "button.menu"), CallbackData: "main_menu"}},
+	))
 	return b.sendMessage(msg)
 }
 
@@ -349,70 +588,55 @@ func (b *Bot) handleSettings(ctx context.Context, message *tgbotapi.Message) err
 	}
 
 	// Создаем пользователя при первом взаимодействии
-	user, err := b.userRepo.GetByTelegramID(ctx, message.From.ID)
+	user, err := b.getOrCreateUser(ctx, message.From)
 	if err != nil {
-		user = &models.User{
-			TelegramID:          message.From.ID,
-			Username:            message.From.UserName,
-			FirstName:           message.From.FirstName,
-			LastName:            message.From.LastName,
-			NotificationEnabled: true,
-			NotificationHour:    9,
-		}
-		err = b.userRepo.Create(ctx, user)
-		if err != nil {
-			return fmt.Errorf("failed to create user: %w", err)
-		}
+		return err
 	}
 
-	text := fmt.Sprintf(
-		`Текущие настройки:
-
-Уведомления: %s
-Время уведомлений: %d:00
-
-Для изменения настроек используйте команды:
-/notify on|off - Включить/выключить уведомления
-/time <час> - Установить время уведомлений (0-23)`,
-		boolToEnabledString(user.NotificationEnabled),
-		user.NotificationHour,
+	loc := b.userLocale(user)
+	sm2, err := b.repetitions.SM2For(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	config, err := database.GetUserConfig(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user config: %w", err)
+	}
+	text := i18n.T(loc, "settings.text",
+		enabledString(loc, user.NotificationEnabled),
+		hoursText(database.NotificationHours(user)),
+		quietHoursText(loc, user),
+		user.SkipFirstRepetitions,
+		digestStatus(loc, user.DigestEnabled),
+		schedulerName(loc, user.Scheduler),
+		sm2ParamsText(loc, sm2, config),
+		languageName(loc),
 	)
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{digestToggleButton(loc, user.DigestEnabled)},
+		{boardToggleButton(loc, user.BoardEnabled)},
+		{schedulerToggleButton(loc, user.Scheduler)},
+		{{Text: i18n.T(loc, "button.menu"), CallbackData: "main_menu"}},
+	})
 	return b.sendMessage(msg)
 }
 
 func (b *Bot) handleNotifyCommand(ctx context.Context, message *tgbotapi.Message) error {
-	args := strings.TrimSpace(strings.TrimPrefix(message.Text, "/notify"))
-	if args == "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Пожалуйста, укажите on или off: /notify <on|off>")
-		return b.sendMessage(msg)
-	}
-
-	user, err := b.userRepo.GetByTelegramID(ctx, message.From.ID)
+	user, err := b.getOrCreateUser(ctx, message.From)
 	if err != nil {
-		// Если пользователь не найден, создаем его с дефолтными настройками
-		user = &models.User{
-			TelegramID:          message.From.ID,
-			Username:            message.From.UserName,
-			FirstName:           message.From.FirstName,
-			LastName:            message.From.LastName,
-			NotificationEnabled: true,
-			NotificationHour:    9,
-		}
-		err = b.userRepo.Create(ctx, user)
-		if err != nil {
-			return fmt.Errorf("failed to create user: %w", err)
-		}
+		return err
 	}
+	loc := b.userLocale(user)
 
-	switch strings.ToLower(args) {
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
 	case "on":
 		user.NotificationEnabled = true
 	case "off":
 		user.NotificationEnabled = false
 	default:
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Пожалуйста, укажите on или off: /notify <on|off>")
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "notify.usage"))
 		return b.sendMessage(msg)
 	}
 
@@ -420,70 +644,164 @@ func (b *Bot) handleNotifyCommand(ctx context.Context, message *tgbotapi.Message
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
+	if user.NotificationEnabled {
+		// Notifications turned off after the user blocked the bot start over
+		b.clearDeliveryFailures(ctx, user)
+	}
 
-	text := fmt.Sprintf("✅ Уведомления %s", boolToEnabledString(user.NotificationEnabled))
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "notify.done", enabledString(loc, user.NotificationEnabled)))
 	return b.sendMessage(msg)
 }
 
 func (b *Bot) handleTimeCommand(ctx context.Context, message *tgbotapi.Message) error {
-	args := strings.TrimSpace(strings.TrimPrefix(message.Text, "/time"))
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	args := strings.TrimSpace(message.CommandArguments())
 	if args == "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Пожалуйста, укажите час (0-23): /time <час>")
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "time.usage"))
 		return b.sendMessage(msg)
 	}
 
-	hour, err := strconv.Atoi(args)
-	if err != nil || hour < 0 || hour > 23 {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Пожалуйста, укажите корректный час (0-23)")
+	hours, err := database.ParseHours(args)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "time.invalid", database.MaxNotificationHours))
 		return b.sendMessage(msg)
 	}
 
-	user, err := b.userRepo.GetByTelegramID(ctx, message.From.ID)
+	database.SetNotificationHours(user, hours)
+	err = b.userRepo.Update(ctx, user)
 	if err != nil {
-		// Если пользователь не найден, создаем его с дефолтными настройками
-		user = &models.User{
-			TelegramID:          message.From.ID,
-			Username:            message.From.UserName,
-			FirstName:           message.From.FirstName,
-			LastName:            message.From.LastName,
-			NotificationEnabled: true,
-			NotificationHour:    9,
-		}
-		err = b.userRepo.Create(ctx, user)
-		if err != nil {
-			return fmt.Errorf("failed to create user: %w", err)
-		}
+		return fmt.Errorf("failed to update user: %w", err)
 	}
 
-	user.NotificationHour = hour
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "time.done", hoursText(hours)))
+	return b.sendMessage(msg)
+}
+
+func (b *Bot) handleSkipFirstCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "skipfirst.usage"))
+		return b.sendMessage(msg)
+	}
+
+	skip, err := strconv.Atoi(args)
+	if err != nil || skip < 0 || skip > 6 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "skipfirst.invalid"))
+		return b.sendMessage(msg)
+	}
+
+	user.SkipFirstRepetitions = skip
 	err = b.userRepo.Update(ctx, user)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
-	text := fmt.Sprintf("✅ Время уведомлений установлено на %d:00", hour)
+	text := i18n.T(loc, "skipfirst.all")
+	if skip > 0 {
+		text = i18n.T(loc, "skipfirst.from", loc.Ordinal(skip+1))
+	}
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	return b.sendMessage(msg)
 }
 
 func (b *Bot) handleUnknownCommand(message *tgbotapi.Message) error {
-	text := "Неизвестная команда. Используйте /help для просмотра списка доступных команд."
+	text := i18n.T(b.localeFor(context.Background(), message.From.ID), "command.unknown")
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	return b.sendMessage(msg)
 }
 
-// boolToEnabledString converts a boolean to a human-readable enabled/disabled string
-func boolToEnabledString(enabled bool) string {
-	if enabled {
-		return "включены"
+// getOrCreateUser returns the user for a Telegram account, registering it on first contact.
+// It never returns a nil user without an error.
+func (b *Bot) getOrCreateUser(ctx context.Context, from *tgbotapi.User) (*models.User, error) {
+	if from == nil {
+		return nil, fmt.Errorf("telegram user is missing")
+	}
+
+	user, err := b.userRepo.GetByTelegramID(ctx, from.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
-	return "выключены"
+	if user != nil {
+		return user, nil
+	}
+
+	user = &models.User{
+		TelegramID:          from.ID,
+		Username:            from.UserName,
+		FirstName:           from.FirstName,
+		LastName:            from.LastName,
+		NotificationEnabled: true,
+		NotificationHour:    9,
+		OverduePolicy:       models.OverdueRemind,
+		OverdueDays:         models.DefaultOverdueDays,
+		ReportEnabled:       true,
+		ReportDay:           models.DefaultReportDay,
+		ReportHour:          models.DefaultReportHour,
+	}
+	// Новые пользователи получают язык своего клиента Telegram, если бот его поддерживает
+	if loc, ok := supportedLocale(strings.ToLower(from.LanguageCode)); ok {
+		user.Language = string(loc)
+	}
+	if err := b.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// isAdmin reports whether the user has admin rights, either in the database or via ADMIN_USER_IDS
+func (b *Bot) isAdmin(user *models.User) bool {
+	return user.IsAdmin || b.config.AdminUserIDs[user.TelegramID]
+}
+
+// topicLimit returns the maximum number of topics of the user, 0 for no limit
+func (b *Bot) topicLimit(user *models.User) int {
+	if b.isAdmin(user) {
+		return 0
+	}
+	return b.config.MaxTopicsPerUser
+}
+
+// topicLimitReached reports whether the user already has the maximum number of topics
+func (b *Bot) topicLimitReached(ctx context.Context, user *models.User) (bool, error) {
+	limit := b.topicLimit(user)
+	if limit == 0 {
+		return false, nil
+	}
+
+	count, err := b.topicRepo.CountByUserID(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	return count >= limit, nil
+}
+
+// topicLimitText returns the message shown when the topic limit is reached
+func (b *Bot) topicLimitText(loc locale.Locale) string {
+	return i18n.T(loc, "topics.limit", b.config.MaxTopicsPerUser)
+}
+
+// difficultyLabel returns a human-readable name for a topic difficulty
+func difficultyLabel(loc locale.Locale, difficulty int) string {
+	if difficulty < models.MinTopicDifficulty || difficulty > models.MaxTopicDifficulty {
+		difficulty = models.DefaultTopicDifficulty
+	}
+	return i18n.T(loc, fmt.Sprintf("difficulty.%d", difficulty))
 }
 
 // CheckDueRepetitions проверяет и отправляет уведомления о повторениях
 func (b *Bot) CheckDueRepetitions(ctx context.Context) error {
-	currentHour := time.Now().Hour()
+	currentHour := b.clock.Now().Hour()
 	
 	// Получаем пользователей, у которых сейчас время уведомлений
 	users, err := b.userRepo.GetUsersForNotification(ctx, currentHour)
@@ -491,62 +809,104 @@ func (b *Bot) CheckDueRepetitions(ctx context.Context) error {
 		return fmt.Errorf("failed to get users for notification: %w", err)
 	}
 
-	for _, user := range users {
-		// Получаем повторения, которые нужно выполнить
-		repetitions, err := b.repetitionRepo.GetDueRepetitions(ctx, user.ID)
-		if err != nil {
-			log.Printf("Failed to get due repetitions for user %d: %v", user.ID, err)
-			continue
-		}
+	// Повторения для напоминаний, с названиями тем и числом материалов, одним запросом на всех
+	due, err := b.repetitionRepo.GetDueForNotification(ctx, currentHour)
+	if err != nil {
+		return fmt.Errorf("failed to get due repetitions: %w", err)
+	}
 
-		if len(repetitions) == 0 {
+	for i := range users {
+		user := &users[i]
+		if user.BoardEnabled {
+			if err := b.refreshBoard(ctx, user); err != nil {
+				logging.FromContext(ctx).Error("failed to refresh board", "user_id", user.ID, "error", err)
+			}
 			continue
 		}
-
-		// Получаем темы пользователя
-		topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
-		if err != nil {
-			log.Printf("Failed to get topics for user %d: %v", user.ID, err)
+		if user.DigestEnabled {
+			err := b.notifyOnce(ctx, user, database.ReminderNotification(currentHour), func() error {
+				return b.sendDigest(ctx, user)
+			})
+			if err != nil {
+				logging.FromContext(ctx).Error("failed to send digest", "user_id", user.ID, "error", err)
+			}
 			continue
 		}
 
-		// Создаем мапу для быстрого доступа к темам
-		topicMap := make(map[int64]models.Topic)
-		for _, t := range topics {
-			topicMap[t.ID] = t
+		if len(due[user.ID]) == 0 {
+			continue
 		}
 
-		var text strings.Builder
-		text.WriteString("🔔 Напоминание о повторении:\n\n")
-
-		for _, rep := range repetitions {
-			text.WriteString(fmt.Sprintf("📚 Тема: %s\n", topicMap[rep.TopicID].Name))
-			text.WriteString(fmt.Sprintf("🔄 Повторение №%d\n\n", rep.RepetitionNumber))
+		repetitions := make([]models.Repetition, len(due[user.ID]))
+		for i, rep := range due[user.ID] {
+			repetitions[i] = rep.Repetition
 		}
 
-		text.WriteString("\nПосле повторения отметьте его как выполненное, нажав на соответствующую кнопку.")
+		loc := b.userLocale(user)
+		msg := tgbotapi.NewMessage(user.TelegramID, reminderText(loc, repetitions))
 
-		msg := tgbotapi.NewMessage(user.TelegramID, text.String())
-		
-		// Добавляем кнопки для каждого повторения
+		// Добавляем кнопки для каждого повторения; темы с прикрепленными материалами
+		// получают кнопку, которая их присылает
 		var keyboard [][]tgbotapi.InlineKeyboardButton
-		for _, rep := range repetitions {
+		for _, rep := range due[user.ID] {
 			button := tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("✅ Повторил тему \"%s\"", topicMap[rep.TopicID].Name),
+				reminderButtonText(loc, rep.TopicName),
 				fmt.Sprintf("complete_%d", rep.ID),
 			)
-			keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{button})
+			row := []tgbotapi.InlineKeyboardButton{button}
+			if rep.Materials > 0 {
+				m := materialsButton(rep.TopicID, i18n.T(loc, "button.materials"))
+				row = append(row, tgbotapi.NewInlineKeyboardButtonData(m.Text, m.CallbackData))
+			}
+			keyboard = append(keyboard, row)
 		}
 		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
 
-		if err := b.sendMessage(msg); err != nil {
-			log.Printf("Failed to send notification to user %d: %v", user.ID, err)
+		err = b.notifyOnce(ctx, user, database.ReminderNotification(currentHour), func() error {
+			if err := b.deliver(ctx, user, "reminder", msg); err != nil {
+				return err
+			}
+			b.notifyChannels(ctx, user, scheduler.Notification{
+				Kind:    "reminder",
+				Subject: i18n.T(loc, "reminder.subject"),
+				Text:    reminderTopicsText(loc, repetitions) + b.channelFooter(loc),
+			})
+			return nil
+		})
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to send notification", "user_id", user.ID, "error", err)
 		}
 	}
 
 	return nil
 }
 
+// reminderText builds the reminder about due repetitions in the given locale. The repetitions
+// come with their topic names.
+func reminderText(loc locale.Locale, repetitions []models.Repetition) string {
+	return reminderTopicsText(loc, repetitions) + i18n.T(loc, "reminder.footer")
+}
+
+// reminderTopicsText lists the due topics of the reminder, without the footer about the buttons
+func reminderTopicsText(loc locale.Locale, repetitions []models.Repetition) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "reminder.header"))
+	for _, rep := range repetitions {
+		text.WriteString(i18n.T(loc, "reminder.topic", rep.TopicName))
+		if rep.Maintenance {
+			text.WriteString(i18n.T(loc, "reminder.maintenance") + "\n\n")
+		} else {
+			text.WriteString(fmt.Sprintf("🔄 %s\n\n", loc.Repetition(rep.RepetitionNumber)))
+		}
+	}
+	return text.String()
+}
+
+// reminderButtonText is the label of the "done" button under a reminder
+func reminderButtonText(loc locale.Locale, topicName string) string {
+	return i18n.T(loc, "reminder.button", topicName)
+}
+
 // HandleCallback обрабатывает нажатия на inline-кнопки
 func (b *Bot) HandleCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
 	if callback == nil || callback.Message == nil || callback.From == nil {
@@ -556,10 +916,11 @@ func (b *Bot) HandleCallback(ctx context.Context, callback *tgbotapi.CallbackQue
 	// Always send an answer to the callback query to remove the loading state
 	answer := tgbotapi.NewCallback(callback.ID, "")
 	if _, err := b.api.Request(answer); err != nil {
-		log.Printf("Warning: Failed to answer callback: %v", err)
+		logging.FromContext(ctx).Warn("failed to answer callback", "error", err)
 	}
 
 	message := callback.Message
+	loc := b.localeFor(ctx, callback.From.ID)
 	var err error
 
 	switch callback.Data {
@@ -570,13 +931,21 @@ func (b *Bot) HandleCallback(ctx context.Context, callback *tgbotapi.CallbackQue
 	case "settings":
 		err = b.handleSettingsMenu(callback)
 	case "help":
-		err = b.handleHelp(message)
+		// Язык справки определяется по пользователю, а не по боту-отправителю сообщения
+		err = b.handleHelp(&tgbotapi.Message{From: callback.From, Chat: message.Chat})
 	case "stats":
-		err = b.handleStats(ctx, message)
+		// Сообщение с кнопкой отправлено ботом, поэтому берем From из callback
+		msg := &tgbotapi.Message{
+			From: callback.From,
+			Chat: callback.Message.Chat,
+		}
+		err = b.handleStats(ctx, msg)
+	case callbackStatsCharts:
+		err = b.handleStatsChartsCallback(ctx, callback)
 	case "notifications_settings":
 		err = b.handleNotificationsSettings(callback)
-	case "time_settings":
-		err = b.handleTimeSettings(callback)
+	case callbackTimeSettings:
+		err = b.handleTimeSettings(ctx, callback)
 	case "delete_topic":
 		err = b.handleDeleteTopicMenu(callback)
 	case "list_topics":
@@ -590,23 +959,154 @@ func (b *Bot) HandleCallback(ctx context.Context, callback *tgbotapi.CallbackQue
 		err = b.handleStartAddTopic(callback)
 	case callbackCancelAction:
 		err = b.handleCancelAction(callback)
+	case callbackAttachDone:
+		err = b.handleAttachDone(callback)
+	case callbackConfirmRestartAll:
+		err = b.handleRestartAllConfirm(ctx, callback)
+	case callbackSimilarCreate:
+		err = b.handleSimilarTopicCreate(ctx, callback)
+	case callbackSimilarKeep:
+		err = b.handleSimilarTopicKeep(callback)
+	case callbackBulkMenu:
+		err = b.handleBulkMenu(ctx, callback)
+	case callbackBulkConfirmDelete:
+		err = b.handleBulkDeleteConfirm(ctx, callback)
+	case callbackArchiveMenu:
+		err = b.handleArchiveMenu(ctx, callback)
+	case callbackBoardToggle:
+		err = b.handleBoardToggle(ctx, callback)
+	case callbackSchedulerToggle:
+		err = b.handleSchedulerToggle(ctx, callback)
+	case callbackDigestToggle:
+		err = b.handleDigestToggle(ctx, callback)
+	case callbackBroadcastSend:
+		err = b.handleBroadcastSend(ctx, callback)
+	case callbackDigestWords:
+		err = b.handleDigestWords(ctx, callback)
+	case callbackDecksMenu:
+		err = b.handleDecksMenu(ctx, callback)
+	case callbackPlansMenu:
+		err = b.handlePlansMenu(ctx, callback)
+	case callbackPracticeStop:
+		err = b.handlePracticeStop(callback)
 	default:
 		// Обработка complete_* должна идти после точных совпадений
 		if strings.HasPrefix(callback.Data, "complete_") {
-			repID, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, "complete_"), 10, 64)
-			if err != nil {
-				return fmt.Errorf("invalid repetition ID in callback data: %w", err)
+			repID, parseErr := strconv.ParseInt(strings.TrimPrefix(callback.Data, "complete_"), 10, 64)
+			if parseErr != nil {
+				err = &ValidationError{Message: i18n.T(loc, "stale.topics")}
+			} else {
+				err = b.handleTopicGradePrompt(ctx, callback, repID)
 			}
-			if err = b.handleTopicComplete(ctx, callback.From.ID, callback.Message.Chat.ID, repID); err != nil {
-				return err
+		} else if strings.HasPrefix(callback.Data, callbackArchiveTopicPrefix) || strings.HasPrefix(callback.Data, callbackRestoreTopicPrefix) {
+			archive := strings.HasPrefix(callback.Data, callbackArchiveTopicPrefix)
+			idText := strings.TrimPrefix(strings.TrimPrefix(callback.Data, callbackArchiveTopicPrefix), callbackRestoreTopicPrefix)
+			topicID, parseErr := strconv.ParseInt(idText, 10, 64)
+			if parseErr != nil {
+				err = &ValidationError{Message: i18n.T(loc, "stale.topics")}
+			} else {
+				err = b.handleArchiveTopicCallback(ctx, callback, topicID, archive)
+			}
+		} else if strings.HasPrefix(callback.Data, callbackLanguagePrefix) {
+			err = b.handleLanguageCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackTopicsPagePrefix) {
+			page, parseErr := strconv.Atoi(strings.TrimPrefix(callback.Data, callbackTopicsPagePrefix))
+			if parseErr != nil {
+				err = &ValidationError{Message: i18n.T(loc, "stale.topics")}
+			} else {
+				err = b.handleTopicsPage(ctx, callback, page)
+			}
+		} else if strings.HasPrefix(callback.Data, callbackDigestPagePrefix) {
+			page, parseErr := strconv.Atoi(strings.TrimPrefix(callback.Data, callbackDigestPagePrefix))
+			if parseErr != nil {
+				err = &ValidationError{Message: i18n.T(loc, "stale.digest")}
+			} else {
+				err = b.handleDigestPage(ctx, callback, page)
+			}
+		} else if strings.HasPrefix(callback.Data, callbackTimeTogglePrefix) || strings.HasPrefix(callback.Data, callbackQuietPrefix) {
+			err = b.handleTimePickerCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackIntervalsPrefix) {
+			err = b.handleIntervalsCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackGradePrefix) {
+			err = b.handleGradeCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackAddNotePrefix) {
+			repID, parseErr := strconv.ParseInt(strings.TrimPrefix(callback.Data, callbackAddNotePrefix), 10, 64)
+			if parseErr != nil {
+				err = &ValidationError{Message: i18n.T(loc, "stale.history")}
+			} else {
+				err = b.handleAddNoteStart(callback, repID)
+			}
+		} else if strings.HasPrefix(callback.Data, callbackEditTopicPrefix) {
+			topicID, parseErr := strconv.ParseInt(strings.TrimPrefix(callback.Data, callbackEditTopicPrefix), 10, 64)
+			if parseErr != nil {
+				err = &ValidationError{Message: i18n.T(loc, "stale.topics")}
+			} else {
+				err = b.handleEditTopicCallback(ctx, callback, topicID)
+			}
+		} else if strings.HasPrefix(callback.Data, callbackMaterialsPrefix) || strings.HasPrefix(callback.Data, callbackClearMaterialsPrefix) {
+			clearAll := strings.HasPrefix(callback.Data, callbackClearMaterialsPrefix)
+			topicID, parseErr := strconv.ParseInt(strings.TrimPrefix(strings.TrimPrefix(callback.Data, callbackClearMaterialsPrefix), callbackMaterialsPrefix), 10, 64)
+			if parseErr != nil {
+				err = &ValidationError{Message: i18n.T(loc, "stale.topics")}
+			} else if clearAll {
+				err = b.handleClearMaterials(ctx, callback, topicID)
+			} else {
+				err = b.handleShowMaterials(ctx, callback, topicID)
+			}
+		} else if strings.HasPrefix(callback.Data, callbackReviewWordPrefix) || strings.HasPrefix(callback.Data, callbackEditWordPrefix) ||
+			strings.HasPrefix(callback.Data, callbackAskDeleteWordPrefix) || strings.HasPrefix(callback.Data, callbackDeleteWordPrefix) ||
+			strings.HasPrefix(callback.Data, callbackAskDeleteTopicPrefix) || strings.HasPrefix(callback.Data, callbackDeleteTopicPrefix) ||
+			strings.HasPrefix(callback.Data, callbackWordKindPrefix) {
+			err = b.handleSearchCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackUndoDeletePrefix) || strings.HasPrefix(callback.Data, callbackUndoArchivePrefix) {
+			err = b.handleUndoCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackTopicStatsPrefix) {
+			err = b.handleTopicStatsCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackMaintenancePrefix) {
+			err = b.handleMaintenanceCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackTopicIntervalsPrefix) {
+			err = b.handleTopicIntervalsCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackReviveTopicPrefix) || strings.HasPrefix(callback.Data, callbackStalledArchivePrefix) {
+			err = b.handleStalledCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackAskMergePrefix) || strings.HasPrefix(callback.Data, callbackMergePrefix) {
+			err = b.handleMergeCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackBulkTogglePrefix) {
+			topicID, parseErr := strconv.ParseInt(strings.TrimPrefix(callback.Data, callbackBulkTogglePrefix), 10, 64)
+			if parseErr != nil {
+				err = &ValidationError{Message: i18n.T(loc, "stale.bulk")}
+			} else {
+				err = b.handleBulkToggle(ctx, callback, topicID)
 			}
+		} else if strings.HasPrefix(callback.Data, callbackBulkActionPrefix) {
+			err = b.handleBulkAction(ctx, callback, strings.TrimPrefix(callback.Data, callbackBulkActionPrefix))
+		} else if strings.HasPrefix(callback.Data, callbackFlipPrefix) || strings.HasPrefix(callback.Data, callbackRatePrefix) ||
+			strings.HasPrefix(callback.Data, callbackSayPrefix) {
+			err = b.handleFlashcardCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackDeckPreviewPrefix) || strings.HasPrefix(callback.Data, callbackDeckSubscribePrefix) {
+			preview := strings.HasPrefix(callback.Data, callbackDeckPreviewPrefix)
+			idText := strings.TrimPrefix(strings.TrimPrefix(callback.Data, callbackDeckPreviewPrefix), callbackDeckSubscribePrefix)
+			deckID, parseErr := strconv.ParseInt(idText, 10, 64)
+			if parseErr != nil {
+				err = &ValidationError{Message: i18n.T(loc, "stale.decks")}
+			} else if preview {
+				err = b.handleDeckPreviewCallback(ctx, callback, deckID)
+			} else {
+				err = b.handleDeckSubscribeCallback(ctx, callback, deckID)
+			}
+		} else if strings.HasPrefix(callback.Data, callbackPlanPreviewPrefix) || strings.HasPrefix(callback.Data, callbackPlanCreatePrefix) {
+			err = b.handlePlanCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackWordOfDayPrefix) {
+			err = b.handleWordOfDayCallback(ctx, callback)
+		} else if strings.HasPrefix(callback.Data, callbackQuizPrefix) {
+			err = b.handleQuizCallback(ctx, callback)
 		} else {
-			return b.sendMessage(tgbotapi.NewMessage(callback.Message.Chat.ID, "⚠️ Неизвестное действие"))
+			return b.sendMessage(tgbotapi.NewMessage(callback.Message.Chat.ID, i18n.T(loc, "callback.unknown")))
 		}
 	}
 
 	if err != nil {
-		errorMsg := tgbotapi.NewMessage(callback.Message.Chat.ID, "❌ Произошла ошибка. Пожалуйста, попробуйте позже.")
+		logging.FromContext(ctx).Error("failed to handle callback", "data", callback.Data, "error", err)
+		errorMsg := tgbotapi.NewMessage(callback.Message.Chat.ID, userErrorMessage(loc, err))
 		return b.sendMessage(errorMsg)
 	}
 
@@ -614,115 +1114,81 @@ func (b *Bot) HandleCallback(ctx context.Context, callback *tgbotapi.CallbackQue
 }
 
 func (b *Bot) handleMainMenu(callback *tgbotapi.CallbackQuery, chatID ...int64) error {
-	text := "🤖 Главное меню\n\n" +
-		"Выберите нужный раздел:\n" +
-		"📚 Управление темами - добавление, просмотр и удаление тем\n" +
-		"📊 Статистика - ваш прогресс в изучении\n" +
-		"⚙️ Настройки - настройка уведомлений\n" +
-		"❓ Помощь - информация о командах"
+	loc := b.config.Locale
+	if callback != nil && callback.From != nil {
+		loc = b.localeFor(context.Background(), callback.From.ID)
+	} else if len(chatID) > 0 {
+		// В личном чате ID чата совпадает с Telegram ID пользователя
+		loc = b.localeFor(context.Background(), chatID[0])
+	}
+	text := i18n.T(loc, "menu.main.text")
 
 	if callback == nil {
 		if len(chatID) == 0 {
-			log.Printf("Error: chat ID is required when callback is nil")
+			slog.Error("chat ID is required when callback is nil")
 			return fmt.Errorf("chat ID is required when callback is nil")
 		}
-		log.Printf("Sending main menu to chat %d (no callback)", chatID[0])
 		msg := tgbotapi.NewMessage(chatID[0], text)
-		msg.ReplyMarkup = createKeyboard(b.MainMenuButtons())
+		msg.ReplyMarkup = createKeyboard(b.mainMenuButtons(loc))
 		return b.sendMessage(msg)
 	}
 
 	if callback.Message == nil {
-		log.Printf("Error: callback message is nil for user %d", callback.From.ID)
+		slog.Error("callback message is nil", "telegram_id", callback.From.ID)
 		return fmt.Errorf("callback message is nil")
 	}
 
-	log.Printf("Editing message to show main menu for user %d", callback.From.ID)
 	msg := tgbotapi.NewEditMessageTextAndMarkup(
 		callback.Message.Chat.ID,
 		callback.Message.MessageID,
 		text,
-		createKeyboard(b.MainMenuButtons()),
+		createKeyboard(b.mainMenuButtons(loc)),
 	)
 	return b.editMessage(msg)
 }
 
 func (b *Bot) handleTopicsMenu(callback *tgbotapi.CallbackQuery) error {
-	text := "📚 Управление темами\n\n" +
-		"Выберите действие:\n" +
-		"📝 Добавить тему - создать новую тему для повторения\n" +
-		"📋 Список тем - просмотреть все ваши темы\n" +
-		"🗑 Удалить тему - удалить существующую тему"
-
+	loc := b.localeFor(context.Background(), callback.From.ID)
 	msg := tgbotapi.NewEditMessageTextAndMarkup(
 		callback.Message.Chat.ID,
 		callback.Message.MessageID,
-		text,
-		createKeyboard(b.TopicsMenuButtons()),
+		i18n.T(loc, "topics.menu"),
+		createKeyboard(b.topicsMenuButtons(loc)),
 	)
 	return b.editMessage(msg)
 }
 
 func (b *Bot) handleSettingsMenu(callback *tgbotapi.CallbackQuery) error {
-	text := "⚙️ Настройки\n\n" +
-		"Выберите, что хотите настроить:\n" +
-		"🔔 Уведомления - включение/выключение уведомлений\n" +
-		"🕒 Время уведомлений - установка времени для напоминаний"
-
+	loc := b.localeFor(context.Background(), callback.From.ID)
 	msg := tgbotapi.NewEditMessageTextAndMarkup(
 		callback.Message.Chat.ID,
 		callback.Message.MessageID,
-		text,
-		createKeyboard(b.SettingsMenuButtons()),
+		i18n.T(loc, "settings.menu"),
+		createKeyboard(b.settingsMenuButtons(loc)),
 	)
 	return b.editMessage(msg)
 }
 
 func (b *Bot) handleNotificationsSettings(callback *tgbotapi.CallbackQuery) error {
-	user, err := b.userRepo.GetByTelegramID(context.Background(), callback.From.ID)
+	user, err := b.getOrCreateUser(context.Background(), callback.From)
 	if err != nil {
 		return err
 	}
+	loc := b.userLocale(user)
 
 	var buttons [][]MenuButton
 	if user.NotificationEnabled {
 		buttons = [][]MenuButton{
-			{{Text: "🔕 Выключить уведомления", CallbackData: "notify_off"}},
+			{{Text: i18n.T(loc, "notifications.off_button"), CallbackData: "notify_off"}},
 		}
 	} else {
 		buttons = [][]MenuButton{
-			{{Text: "🔔 Включить уведомления", CallbackData: "notify_on"}},
+			{{Text: i18n.T(loc, "notifications.on_button"), CallbackData: "notify_on"}},
 		}
 	}
-	buttons = append(buttons, []MenuButton{{Text: "⬅️ Назад в настройки", CallbackData: "settings_menu"}})
-
-	text := fmt.Sprintf("🔔 Настройки уведомлений\n\n"+
-		"Текущий статус: %s\n\n"+
-		"Выберите действие:", boolToEnabledString(user.NotificationEnabled))
-
-	msg := tgbotapi.NewEditMessageTextAndMarkup(
-		callback.Message.Chat.ID,
-		callback.Message.MessageID,
-		text,
-		createKeyboard(buttons),
-	)
-	return b.editMessage(msg)
-}
-
-func (b *Bot) handleTimeSettings(callback *tgbotapi.CallbackQuery) error {
-	user, err := b.userRepo.GetByTelegramID(context.Background(), callback.From.ID)
-	if err != nil {
-		return err
-	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "button.back_to_settings"), CallbackData: "settings_menu"}})
 
-	text := fmt.Sprintf("🕒 Настройка времени уведомлений\n\n"+
-		"Текущее время: %d:00\n\n"+
-		"Отправьте команду /time <час> для установки нового времени\n"+
-		"Пример: /time 9 для установки времени на 9:00", user.NotificationHour)
-
-	buttons := [][]MenuButton{
-		{{Text: "⬅️ Назад в настройки", CallbackData: "settings_menu"}},
-	}
+	text := i18n.T(loc, "notifications.title", enabledString(loc, user.NotificationEnabled))
 
 	msg := tgbotapi.NewEditMessageTextAndMarkup(
 		callback.Message.Chat.ID,
@@ -736,11 +1202,12 @@ func (b *Bot) handleTimeSettings(callback *tgbotapi.CallbackQuery) error {
 func (b *Bot) handleDeleteTopicMenu(callback *tgbotapi.CallbackQuery) error {
 	// First get the user by Telegram ID
 	user, err := b.userRepo.GetByTelegramID(context.Background(), callback.From.ID)
+	loc := b.userLocale(user)
 	if err != nil || user == nil {
-		log.Printf("Error getting user or user not found: %v", err)
-		text := "❌ Ошибка: не удалось получить профиль пользователя"
+		slog.Error("failed to get user", "telegram_id", callback.From.ID, "error", err)
+		text := i18n.T(loc, "error.no_profile")
 		buttons := [][]MenuButton{
-			{{Text: "⬅️ Назад к темам", CallbackData: "topics_menu"}},
+			{{Text: i18n.T(loc, "button.back_to_topics"), CallbackData: "topics_menu"}},
 		}
 		msg := tgbotapi.NewEditMessageTextAndMarkup(
 			callback.Message.Chat.ID,
@@ -754,14 +1221,14 @@ func (b *Bot) handleDeleteTopicMenu(callback *tgbotapi.CallbackQuery) error {
 	// Now use the correct user.ID to get topics
 	topics, err := b.topicRepo.GetAllByUserID(context.Background(), user.ID)
 	if err != nil {
-		log.Printf("Error getting topics: %v", err)
+		slog.Error("failed to get topics", "user_id", user.ID, "error", err)
 		return err
 	}
 
 	if len(topics) == 0 {
-		text := "❌ У вас пока нет тем для удаления.\n\nСначала добавьте темы с помощью кнопки \"📝 Добавить тему\""
+		text := i18n.T(loc, "delete.none")
 		buttons := [][]MenuButton{
-			{{Text: "⬅️ Назад к темам", CallbackData: "topics_menu"}},
+			{{Text: i18n.T(loc, "button.back_to_topics"), CallbackData: "topics_menu"}},
 		}
 
 		msg := tgbotapi.NewEditMessageTextAndMarkup(
@@ -774,18 +1241,16 @@ func (b *Bot) handleDeleteTopicMenu(callback *tgbotapi.CallbackQuery) error {
 	}
 
 	var text strings.Builder
-	text.WriteString("🗑 Удаление темы\n\n")
-	text.WriteString("Для удаления темы отправьте команду:\n")
-	text.WriteString("/delete <номер>\n\n")
-	text.WriteString("Ваши темы:\n")
+	text.WriteString(i18n.T(loc, "delete.menu"))
 
+	var buttons [][]MenuButton
 	for i, topic := range topics {
-		text.WriteString(fmt.Sprintf("%d. %s\n", i+1, topic.Name))
-	}
-
-	buttons := [][]MenuButton{
-		{{Text: "⬅️ Назад к темам", CallbackData: "topics_menu"}},
+		text.WriteString(fmt.Sprintf("%d. %s\n", i+1, topicLabel(topic)))
+		if !topic.Archived {
+			buttons = append(buttons, []MenuButton{archiveTopicButton(loc, topic.ID, topic.Name)})
+		}
 	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "button.back_to_topics"), CallbackData: "topics_menu"}})
 
 	msg := tgbotapi.NewEditMessageTextAndMarkup(
 		callback.Message.Chat.ID,
@@ -796,55 +1261,251 @@ func (b *Bot) handleDeleteTopicMenu(callback *tgbotapi.CallbackQuery) error {
 	return b.editMessage(msg)
 }
 
-// handleTopicComplete handles the completion of a topic
-func (b *Bot) handleTopicComplete(ctx context.Context, userID int64, chatID int64, repID int64) error {
-	// Get the repetition
-	rep, err := b.repetitionRepo.GetByID(ctx, userID, repID)
-	if err != nil || rep == nil {
-		log.Printf("Error getting repetition: %v", err)
-		msg := tgbotapi.NewMessage(chatID, "❌ Ошибка обновления прогресса. Попробуйте позже.")
-		return b.sendMessage(msg)
+// handleTopicGradePrompt asks how the review went before the repetition is marked as done
+func (b *Bot) handleTopicGradePrompt(ctx context.Context, callback *tgbotapi.CallbackQuery, repID int64) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
 	}
 
-	// Mark current repetition as completed
-	rep.Completed = true
-	now := time.Now()
-	rep.LastReviewDate = &now
-	err = b.repetitionRepo.Update(ctx, rep)
+	rep, err := b.repetitionRepo.GetByID(ctx, user.ID, repID)
 	if err != nil {
-		log.Printf("Error updating repetition: %v", err)
-		msg := tgbotapi.NewMessage(chatID, "❌ Ошибка обновления прогресса. Попробуйте позже.")
+		return err
+	}
+	loc := b.userLocale(user)
+	if rep.Completed {
+		return &ValidationError{Message: i18n.T(loc, "repetition.already_done")}
+	}
+
+	msg := newRichText(tgbotapi.ModeHTML).
+		Text(i18n.T(loc, "grade.topic")).Bold(rep.TopicName).
+		Text(i18n.T(loc, "grade.prompt")).
+		Message(callback.Message.Chat.ID)
+	msg.ReplyMarkup = gradeKeyboard(loc, rep.ID)
+	return b.sendMessage(msg)
+}
+
+// handleTopicComplete marks the repetition as done and schedules the next one with SM-2
+// from the answer quality. A failed answer repeats the same step instead of advancing.
+// Reviewing a stalled topic brings it back to the reminders.
+func (b *Bot) handleTopicComplete(ctx context.Context, telegramID int64, chatID int64, repID int64, quality spaced_repetition.QualityResponse) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, telegramID)
+	loc := b.userLocale(user)
+	if err != nil || user == nil {
+		logging.FromContext(ctx).Error("failed to get user", "telegram_id", telegramID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "error.no_profile"))
 		return b.sendMessage(msg)
 	}
+	userID := user.ID
+
+	result, err := b.repetitions.Complete(ctx, user, repID, quality)
+	switch {
+	case errors.Is(err, database.ErrAlreadyCompleted):
+		return &ValidationError{Message: i18n.T(loc, "repetition.already_done")}
+	case errors.Is(err, service.ErrTopicNotFound):
+		return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+	case err != nil:
+		return err
+	}
+	rep := result.Repetition
+	b.recordReview(ctx, userID)
+	b.refreshBoardAfterReview(ctx, user)
 
-	// Schedule next repetition if not the last one
-	if rep.RepetitionNumber < 7 {
-		nextRep := &models.Repetition{
-			UserID:           userID,
-			TopicID:          rep.TopicID,
-			RepetitionNumber: rep.RepetitionNumber + 1,
-			NextReviewDate:   b.repetitionRepo.CalculateNextReviewDate(rep.RepetitionNumber),
-			CreatedAt:        time.Now(),
-			UpdatedAt:        time.Now(),
+	if result.Next == nil {
+		// If this was the last repetition
+		reps, err := b.repetitionRepo.GetByTopic(ctx, userID, rep.TopicID)
+		if err != nil {
+			logging.FromContext(ctx).Warn("failed to get topic repetitions for summary", "topic_id", rep.TopicID, "error", err)
+			reps = []models.Repetition{*rep}
 		}
-		err = b.repetitionRepo.Create(ctx, nextRep)
+		streak, err := b.activityRepo.GetStreak(ctx, userID)
 		if err != nil {
-			log.Printf("Error creating next repetition: %v", err)
-			msg := tgbotapi.NewMessage(chatID, "❌ Ошибка планирования следующего повторения. Попробуйте позже.")
-			return b.sendMessage(msg)
+			logging.FromContext(ctx).Warn("failed to get review streak", "user_id", userID, "error", err)
 		}
 
-		// Send success message with next repetition date
-		text := fmt.Sprintf("✅ Отлично! Повторение выполнено.\nСледующее повторение запланировано на %s",
-			nextRep.NextReviewDate.Format("02.01.2006"))
-		msg := tgbotapi.NewMessage(chatID, text)
-		return b.sendMessage(msg)
-	} else {
-		// If this was the last repetition
-		text := "🎉 Поздравляем! Вы завершили все повторения этой темы!"
-		msg := tgbotapi.NewMessage(chatID, text)
+		msg := tgbotapi.NewMessage(chatID, formatCompletionSummary(loc, rep.TopicName, reps, streak))
+		msg.ReplyMarkup = noteKeyboard(loc, rep.ID)
 		return b.sendMessage(msg)
 	}
+
+	// Send success message with next repetition date
+	text := i18n.T(loc, "complete.next", result.Next.NextReviewDate.Format("02.01.2006"))
+	if result.Passed && result.Next.Maintenance {
+		text = i18n.T(loc, "complete.maintenance", result.Next.NextReviewDate.Format("02.01.2006"))
+	}
+	if !result.Passed {
+		text = i18n.T(loc, "complete.again", result.Next.NextReviewDate.Format("02.01.2006"))
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = noteKeyboard(loc, rep.ID)
+	return b.sendMessage(msg)
+}
+
+// gradeKeyboard returns the answer quality buttons for a topic repetition
+func gradeKeyboard(loc locale.Locale, repID int64) tgbotapi.InlineKeyboardMarkup {
+	var row []MenuButton
+	for _, rating := range qualityRatings {
+		row = append(row, MenuButton{
+			Text:         i18n.T(loc, rating.Key),
+			CallbackData: fmt.Sprintf("%s%d_%d", callbackGradePrefix, repID, rating.Quality),
+		})
+	}
+	return createKeyboard([][]MenuButton{row})
+}
+
+// handleGradeCallback parses grade_<repID>_<quality> and completes the repetition
+func (b *Bot) handleGradeCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	loc := b.localeFor(ctx, callback.From.ID)
+	parts := strings.Split(strings.TrimPrefix(callback.Data, callbackGradePrefix), "_")
+	if len(parts) != 2 {
+		return &ValidationError{Message: i18n.T(loc, "stale.topics")}
+	}
+	repID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return &ValidationError{Message: i18n.T(loc, "stale.topics")}
+	}
+	quality, err := strconv.Atoi(parts[1])
+	if err != nil || quality < int(spaced_repetition.QualityBlackout) || quality > int(spaced_repetition.QualityPerfect) {
+		return &ValidationError{Message: i18n.T(loc, "stale.topics")}
+	}
+	return b.handleTopicComplete(ctx, callback.From.ID, callback.Message.Chat.ID, repID, spaced_repetition.QualityResponse(quality))
+}
+
+// noteKeyboard offers to attach a note to a just completed repetition
+func noteKeyboard(loc locale.Locale, repID int64) tgbotapi.InlineKeyboardMarkup {
+	return createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "note.button"), CallbackData: fmt.Sprintf("%s%d", callbackAddNotePrefix, repID)}},
+		{{Text: i18n.T(loc, "button.menu"), CallbackData: "main_menu"}},
+	})
+}
+
+// handleAddNoteStart waits for the next text message to store it as the repetition note
+func (b *Bot) handleAddNoteStart(callback *tgbotapi.CallbackQuery, repID int64) error {
+	b.states.put(callback.From.ID, &UserState{
+		Action: actionAddingNote,
+		Step:   1,
+		Data:   map[string]string{"repetition_id": strconv.FormatInt(repID, 10)},
+	})
+
+	loc := b.localeFor(context.Background(), callback.From.ID)
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, i18n.T(loc, "note.prompt"))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+	})
+	return b.sendMessage(msg)
+}
+
+// handleNoteText stores the message text as the note of the repetition from the user state
+func (b *Bot) handleNoteText(ctx context.Context, message *tgbotapi.Message) error {
+	state, _ := b.states.get(message.From.ID)
+	repID, err := strconv.ParseInt(state.Data["repetition_id"], 10, 64)
+	if err != nil {
+		b.states.remove(message.From.ID)
+		return fmt.Errorf("invalid repetition ID in note state: %w", err)
+	}
+
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	note := strings.TrimSpace(message.Text)
+	if note == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "note.empty")))
+	}
+	if len([]rune(note)) > maxNoteLength {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "note.too_long", maxNoteLength)))
+	}
+
+	if err := b.repetitionRepo.UpdateNotes(ctx, user.ID, repID, note); err != nil {
+		return err
+	}
+	b.states.remove(message.From.ID)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "note.saved"))
+	return b.sendMessage(msg)
+}
+
+func (b *Bot) handleHistoryCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	index, err := strconv.Atoi(strings.TrimSpace(message.CommandArguments()))
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "history.usage")))
+	}
+
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+	if index < 1 || index > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topic.wrong_number")))
+	}
+	topic := topics[index-1]
+
+	reps, err := b.repetitionRepo.GetByTopic(ctx, user.ID, topic.ID)
+	if err != nil {
+		return err
+	}
+
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "history.title", topic.Name))
+	for _, rep := range reps {
+		if rep.Completed && rep.LastReviewDate != nil {
+			text.WriteString(i18n.T(loc, "history.done", loc.Repetition(rep.RepetitionNumber), rep.LastReviewDate.Format("02.01.2006")))
+		} else {
+			text.WriteString(i18n.T(loc, "history.planned", loc.Repetition(rep.RepetitionNumber), rep.NextReviewDate.Format("02.01.2006")))
+		}
+		if rep.Notes != "" {
+			text.WriteString(fmt.Sprintf("📝 %s\n", rep.Notes))
+		}
+	}
+
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, text.String()))
+}
+
+// topicCompletionStats returns the first and last review dates and the number of completed
+// repetitions of a topic
+func topicCompletionStats(reps []models.Repetition) (first, last time.Time, completed int) {
+	for _, r := range reps {
+		if !r.Completed || r.LastReviewDate == nil {
+			continue
+		}
+		completed++
+		if first.IsZero() || r.LastReviewDate.Before(first) {
+			first = *r.LastReviewDate
+		}
+		if r.LastReviewDate.After(last) {
+			last = *r.LastReviewDate
+		}
+	}
+	return first, last, completed
+}
+
+// formatCompletionSummary builds the congratulation message sent after the last repetition of a topic
+func formatCompletionSummary(loc locale.Locale, topicName string, reps []models.Repetition, streak int) string {
+	first, last, completed := topicCompletionStats(reps)
+	days := 0
+	if completed > 0 {
+		days = int(last.Sub(first).Hours()/24) + 1
+	}
+
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "completion.title", topicName))
+	if completed > 0 {
+		text.WriteString(i18n.T(loc, "completion.took",
+			days, i18n.Plural(loc, "plural.day", days), first.Format("02.01.2006"), last.Format("02.01.2006")))
+	}
+	text.WriteString(i18n.T(loc, "completion.reviews", completed))
+	text.WriteString(streakText(loc, streak))
+	text.WriteString(i18n.T(loc, "completion.share",
+		topicName, completed, i18n.Plural(loc, "plural.review", completed), days, i18n.Plural(loc, "plural.day", days)))
+	return text.String()
 }
 
 func (b *Bot) handleStartAddTopic(callback *tgbotapi.CallbackQuery) error {
@@ -852,41 +1513,128 @@ func (b *Bot) handleStartAddTopic(callback *tgbotapi.CallbackQuery) error {
 		return fmt.Errorf("invalid callback data: Message or From is nil")
 	}
 
-	log.Printf("Starting add topic for user %d", callback.From.ID)
+	user, err := b.getOrCreateUser(context.Background(), callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	limitReached, err := b.topicLimitReached(context.Background(), user)
+	if err != nil {
+		return err
+	}
+	if limitReached {
+		return b.sendMessage(tgbotapi.NewMessage(callback.Message.Chat.ID, b.topicLimitText(loc)))
+	}
 
 	userID := callback.From.ID
-	userStates[userID] = &UserState{
+	b.states.put(userID, &UserState{
 		Action: "adding_topic",
 		Step:   1,
 		Data:   make(map[string]string),
-	}
-
-	log.Printf("Set user state: %+v", userStates[userID])
+	})
 
-	text := "Пожалуйста, введите название новой темы для повторения.\n" +
-		"Например: \"Алгоритмы сортировки\" или \"Паттерны проектирования\""
+	slog.Debug("set user state", "telegram_id", userID, "action", "adding_topic")
 
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, i18n.T(loc, "add.start"))
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", callbackCancelAction),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(loc, "button.cancel"), callbackCancelAction),
 		),
 	)
 	return b.sendMessage(msg)
 }
 
+// askSimilarTopic asks whether to create a topic whose name is close to an existing one.
+// Exact matches (ignoring case and spaces) are rejected right away.
+func (b *Bot) askSimilarTopic(loc locale.Locale, chatID, telegramID int64, topicName string, firstReviewDays int, similar *models.Topic) error {
+	if textutil.Normalize(similar.Name) == textutil.Normalize(topicName) {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "add.exists", similar.Name))
+		msg.ReplyMarkup = createKeyboard([][]MenuButton{
+			{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+		})
+		return b.sendMessage(msg)
+	}
+
+	b.states.put(telegramID, &UserState{
+		Action: "confirm_similar_topic",
+		Step:   2,
+		Data: map[string]string{
+			"name":         topicName,
+			"similar_name": similar.Name,
+			"first_review": strconv.Itoa(firstReviewDays),
+		},
+	})
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "similar.ask", similar.Name, topicName))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "similar.create"), CallbackData: callbackSimilarCreate}},
+		{{Text: i18n.T(loc, "similar.keep", similar.Name), CallbackData: callbackSimilarKeep}},
+		{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+	})
+	return b.sendMessage(msg)
+}
+
+// handleSimilarTopicCreate creates the pending topic after the user confirmed it isn't a duplicate
+func (b *Bot) handleSimilarTopicCreate(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	state, ok := b.states.get(callback.From.ID)
+	if !ok || state.Action != "confirm_similar_topic" {
+		return &ValidationError{Message: i18n.T(loc, "similar.stale_create")}
+	}
+
+	limitReached, err := b.topicLimitReached(ctx, user)
+	if err != nil {
+		return err
+	}
+	if limitReached {
+		b.states.remove(callback.From.ID)
+		return b.sendMessage(tgbotapi.NewMessage(callback.Message.Chat.ID, b.topicLimitText(loc)))
+	}
+
+	firstReviewDays, err := strconv.Atoi(state.Data["first_review"])
+	if err != nil {
+		firstReviewDays = scheduledFirstReview
+	}
+	return b.createTopic(ctx, callback.Message.Chat.ID, callback.From.ID, user, state.Data["name"], firstReviewDays)
+}
+
+// handleSimilarTopicKeep drops the pending topic in favour of the existing one
+func (b *Bot) handleSimilarTopicKeep(callback *tgbotapi.CallbackQuery) error {
+	loc := b.localeFor(context.Background(), callback.From.ID)
+	state, ok := b.states.get(callback.From.ID)
+	if !ok || state.Action != "confirm_similar_topic" {
+		return &ValidationError{Message: i18n.T(loc, "stale.action")}
+	}
+	b.states.remove(callback.From.ID)
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		i18n.T(loc, "similar.kept", state.Data["similar_name"]),
+		createKeyboard([][]MenuButton{
+			{{Text: i18n.T(loc, "button.topic_list"), CallbackData: "list_topics"}},
+			{{Text: i18n.T(loc, "button.menu"), CallbackData: "main_menu"}},
+		}),
+	)
+	return b.editMessage(msg)
+}
+
 func (b *Bot) handleCancelAction(callback *tgbotapi.CallbackQuery) error {
 	if callback.Message == nil || callback.From == nil {
 		return fmt.Errorf("invalid callback data: Message or From is nil")
 	}
 
 	userID := callback.From.ID
-	log.Printf("Canceling action for user %d, previous state: %+v", userID, userStates[userID])
-	delete(userStates, userID)
-	log.Printf("State after deletion: %+v", userStates[userID])
+	if state, ok := b.states.take(userID); ok {
+		slog.Debug("canceling action", "telegram_id", userID, "action", state.Action)
+	}
 
-	text := "Действие отменено. Выберите другую команду:"
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
-	msg.ReplyMarkup = createKeyboard(b.MainMenuButtons())
+	loc := b.localeFor(context.Background(), userID)
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, i18n.T(loc, "cancel.done"))
+	msg.ReplyMarkup = createKeyboard(b.mainMenuButtons(loc))
 	return b.sendMessage(msg)
 } 
\ No newline at end of file
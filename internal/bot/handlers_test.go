@@ -0,0 +1,321 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/database/dbtest"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// reviewedRep is a repetition reviewed at the time, completed or not
+func reviewedRep(number int, at time.Time, completed bool) models.Repetition {
+	return models.Repetition{RepetitionNumber: number, Completed: completed, LastReviewDate: &at}
+}
+
+func TestFormatCompletionSummary(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2026, 1, d, hour, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string
+		reps    []models.Repetition
+		streak  int
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "ten days",
+			reps: []models.Repetition{
+				reviewedRep(1, day(1, 10), true),
+				reviewedRep(2, day(5, 20), true),
+				reviewedRep(3, day(11, 9), true),
+				reviewedRep(4, day(20, 9), false),
+				{RepetitionNumber: 5, Completed: true},
+			},
+			streak: 3,
+			want: []string{
+				"🎉 Поздравляем! Вы завершили все повторения темы \"Go\"!",
+				"📅 Изучение заняло: 10 дней (с 01.01.2026 по 11.01.2026)",
+				"🔄 Выполнено повторений: 3\n",
+				"Серия: 3 дня подряд",
+				"«Я закрепил тему \"Go\" методом интервальных повторений: 3 повторения за 10 дней! 💪»",
+			},
+		},
+		{
+			name:   "one day",
+			reps:   []models.Repetition{reviewedRep(1, day(2, 8), true)},
+			streak: 21,
+			want: []string{
+				"📅 Изучение заняло: 1 день (с 02.01.2026 по 02.01.2026)",
+				"🔄 Выполнено повторений: 1\n",
+				"Серия: 21 день подряд",
+				"1 повторение за 1 день!",
+			},
+		},
+		{
+			name: "twenty two days without a streak",
+			reps: []models.Repetition{
+				reviewedRep(1, day(1, 12), true),
+				reviewedRep(2, day(3, 12), true),
+				reviewedRep(3, day(7, 12), true),
+				reviewedRep(4, day(14, 12), true),
+				reviewedRep(5, day(22, 12), true),
+			},
+			want:    []string{"📅 Изучение заняло: 22 дня", "5 повторений за 22 дня!"},
+			notWant: []string{"Серия"},
+		},
+		{
+			name:    "nothing completed",
+			reps:    []models.Repetition{reviewedRep(1, day(1, 12), false)},
+			want:    []string{"🔄 Выполнено повторений: 0\n", "0 повторений за 0 дней!"},
+			notWant: []string{"📅", "Серия"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := formatCompletionSummary(locale.Russian, "Go", tt.reps, tt.streak)
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("summary lacks %q:\n%s", want, text)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(text, notWant) {
+					t.Errorf("summary has %q:\n%s", notWant, text)
+				}
+			}
+		})
+	}
+}
+
+func TestGetOrCreateUserFromCallback(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	const telegramID = 700
+
+	// A button under an old message pressed by someone the bot has no user for
+	press := callback(telegramID, callbackStartAddTopic)
+	press.From.UserName = "newcomer"
+	press.From.LastName = "Smith"
+	press.From.LanguageCode = "EN"
+	if err := tb.handleStartAddTopic(press); err != nil {
+		t.Fatalf("add topic button: %v", err)
+	}
+
+	user, err := tb.userRepo.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		t.Fatalf("no user created: %v", err)
+	}
+	if user.Username != "newcomer" || user.FirstName != "Test" || user.LastName != "Smith" {
+		t.Errorf("user %q %q %q, want the callback's sender", user.Username, user.FirstName, user.LastName)
+	}
+	if user.Language != "en" {
+		t.Errorf("language %q, want the client's en", user.Language)
+	}
+	if !user.NotificationEnabled || user.NotificationHour != 9 || !user.ReportEnabled ||
+		user.ReportDay != models.DefaultReportDay || user.ReportHour != models.DefaultReportHour ||
+		user.OverduePolicy != models.OverdueRemind || user.OverdueDays != models.DefaultOverdueDays {
+		t.Errorf("new user defaults: %+v", user)
+	}
+	if state, ok := tb.states.get(telegramID); !ok || state.Action != "adding_topic" {
+		t.Errorf("state after the button: %+v", state)
+	}
+
+	// The next press finds the same user
+	again, err := tb.getOrCreateUser(ctx, press.From)
+	if err != nil {
+		t.Fatalf("second lookup: %v", err)
+	}
+	if again.ID != user.ID {
+		t.Errorf("second lookup created user %d, want %d", again.ID, user.ID)
+	}
+
+	// An unsupported client language leaves the bot default
+	other, err := tb.getOrCreateUser(ctx, &tgbotapi.User{ID: telegramID + 1, FirstName: "Hans", LanguageCode: "de"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if other.Language != "" {
+		t.Errorf("language %q, want the bot default", other.Language)
+	}
+
+	if _, err := tb.getOrCreateUser(ctx, nil); err == nil {
+		t.Error("no error for a callback without a sender")
+	}
+}
+
+func TestTopicLimitReached(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	tb.config.MaxTopicsPerUser = 3
+	tb.config.AdminUserIDs = map[int64]bool{802: true}
+
+	user := dbtest.User(t, 800)
+	admin := dbtest.User(t, 801)
+	admin.IsAdmin = true
+	envAdmin := dbtest.User(t, 802)
+
+	tests := []struct {
+		topics int
+		want   bool
+	}{
+		{0, false},
+		{2, false}, // limit - 1
+		{3, true},  // the limit itself
+		{4, true},
+	}
+	created := 0
+	for _, tt := range tests {
+		for ; created < tt.topics; created++ {
+			for _, u := range []*models.User{user, admin, envAdmin} {
+				dbtest.Topic(t, u.ID, fmt.Sprintf("topic %d", created), 1, tb.clock.Now())
+			}
+		}
+		reached, err := tb.topicLimitReached(ctx, user)
+		if err != nil {
+			t.Fatalf("%d topics: %v", tt.topics, err)
+		}
+		if reached != tt.want {
+			t.Errorf("%d topics of 3: limit reached %v, want %v", tt.topics, reached, tt.want)
+		}
+		for _, a := range []*models.User{admin, envAdmin} {
+			if reached, err := tb.topicLimitReached(ctx, a); err != nil || reached {
+				t.Errorf("%d topics: admin %d limit reached %v (%v), admins are exempt", tt.topics, a.TelegramID, reached, err)
+			}
+		}
+	}
+
+	// 0 turns the limit off
+	tb.config.MaxTopicsPerUser = 0
+	if reached, err := tb.topicLimitReached(ctx, user); err != nil || reached {
+		t.Errorf("without a limit: reached %v (%v)", reached, err)
+	}
+}
+
+func TestAddNoteStoresNote(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	const telegramID = 900
+
+	user := dbtest.User(t, telegramID)
+	topic := dbtest.Topic(t, user.ID, "Present Perfect", 2, tb.clock.Now())
+	reps, err := tb.repetitionRepo.GetByTopic(ctx, user.ID, topic.ID)
+	if err != nil || len(reps) != 1 {
+		t.Fatalf("repetitions of the topic: %v, %v", reps, err)
+	}
+	repID := reps[0].ID
+	noteOf := func() string {
+		t.Helper()
+		rep, err := tb.repetitionRepo.GetByID(ctx, user.ID, repID)
+		if err != nil {
+			t.Fatalf("failed to get repetition: %v", err)
+		}
+		return rep.Notes
+	}
+	send := func(telegramID int64, text string) {
+		t.Helper()
+		if err := tb.handleUpdate(ctx, tgbotapi.Update{Message: message(telegramID, text)}); err != nil {
+			t.Fatalf("message %q: %v", text, err)
+		}
+	}
+
+	if err := tb.HandleCallback(ctx, callback(telegramID, fmt.Sprintf("%s%d", callbackAddNotePrefix, repID))); err != nil {
+		t.Fatalf("note button: %v", err)
+	}
+	if state, ok := tb.states.get(telegramID); !ok || state.Action != actionAddingNote {
+		t.Fatalf("state after the note button: %+v", state)
+	}
+
+	// Empty and too long notes are refused and the bot keeps waiting for the note
+	send(telegramID, "   ")
+	send(telegramID, strings.Repeat("я", maxNoteLength+1))
+	if got := noteOf(); got != "" {
+		t.Errorf("refused note stored: %q", got)
+	}
+	if _, ok := tb.states.get(telegramID); !ok {
+		t.Fatal("the bot stopped waiting for the note after a refused one")
+	}
+
+	note := strings.Repeat("я", maxNoteLength-9) + " и всё ок"
+	send(telegramID, "  "+note+"\n")
+	if got := noteOf(); got != note {
+		t.Errorf("stored note %q, want %q", got, note)
+	}
+	if _, ok := tb.states.get(telegramID); ok {
+		t.Error("the bot still waits for a note after storing it")
+	}
+	if text := tb.lastText(); !strings.HasPrefix(text, "✅ Заметка сохранена") {
+		t.Errorf("reply %q, want the confirmation", text)
+	}
+
+	// Someone else can't write a note on the user's repetition
+	const otherID = 901
+	dbtest.User(t, otherID)
+	if err := tb.HandleCallback(ctx, callback(otherID, fmt.Sprintf("%s%d", callbackAddNotePrefix, repID))); err != nil {
+		t.Fatalf("note button: %v", err)
+	}
+	if err := tb.handleUpdate(ctx, tgbotapi.Update{Message: message(otherID, "чужая заметка")}); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("note on someone else's repetition: %v, want not found", err)
+	}
+	if got := noteOf(); got != note {
+		t.Errorf("another user changed the note to %q", got)
+	}
+}
+
+func TestCheckDueRepetitions(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	now := time.Date(2026, 2, 9, 9, 0, 0, 0, time.UTC)
+	tb.clock.Set(now)
+
+	user := dbtest.User(t, 500)
+	verbs := dbtest.Topic(t, user.ID, "Irregular verbs", 2, now.Add(-2*time.Hour))
+	dbtest.Topic(t, user.ID, "Phrasal verbs", 4, now.Add(-time.Hour))
+	dbtest.Topic(t, user.ID, "Tomorrow", 3, now.Add(24*time.Hour))
+	err := database.NewTopicAttachmentRepository().Create(ctx, &models.TopicAttachment{
+		UserID: user.ID, TopicID: verbs.ID, Kind: models.AttachmentLink, Content: "https://example.com/verbs",
+	})
+	if err != nil {
+		t.Fatalf("failed to attach: %v", err)
+	}
+	// Nothing due, no reminder
+	dbtest.User(t, 501)
+
+	if err := tb.CheckDueRepetitions(ctx); err != nil {
+		t.Fatalf("CheckDueRepetitions: %v", err)
+	}
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if len(tb.sent) != 1 {
+		t.Fatalf("sent %d messages, want the reminder to user 500 only", len(tb.sent))
+	}
+	msg, ok := tb.sent[0].(tgbotapi.MessageConfig)
+	if !ok || msg.ChatID != 500 {
+		t.Fatalf("sent %+v, want a message to user 500", tb.sent[0])
+	}
+	if i, j := strings.Index(msg.Text, "Irregular verbs"), strings.Index(msg.Text, "Phrasal verbs"); i < 0 || j < i {
+		t.Errorf("reminder doesn't list the due topics by due date:\n%s", msg.Text)
+	}
+	if strings.Contains(msg.Text, "Tomorrow") {
+		t.Errorf("reminder lists a topic due tomorrow:\n%s", msg.Text)
+	}
+
+	keyboard, ok := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if !ok || len(keyboard.InlineKeyboard) != 2 {
+		t.Fatalf("reminder keyboard %+v, want a row per due topic", msg.ReplyMarkup)
+	}
+	if got := len(keyboard.InlineKeyboard[0]); got != 2 {
+		t.Errorf("%d buttons for the topic with materials, want done and materials", got)
+	}
+	if got := len(keyboard.InlineKeyboard[1]); got != 1 {
+		t.Errorf("%d buttons for the topic without materials, want done only", got)
+	}
+}
@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/spaced_repetition"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// hardestWordsCount is how many words /hard lists and drills
+const hardestWordsCount = 10
+
+// handleHardCommand handles /hard: it lists the words the user forgets most, with a button that
+// drills them in a test right away
+func (b *Bot) handleHardCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	words, err := b.progressRepo.GetHardestWords(ctx, user.ID, hardestWordsCount)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if len(words) == 0 {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "hard.none")))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, hardestWordsText(loc, words))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{
+			Text:         i18n.T(loc, "hard.drill"),
+			CallbackData: fmt.Sprintf("%s%d_%d_%s", callbackQuizModePrefix, quizHardestWords, len(words), quizModeButtons),
+		}},
+		{{Text: i18n.T(loc, "button.menu"), CallbackData: "main_menu"}},
+	})
+	return b.sendMessage(msg)
+}
+
+// hardestWords returns the words /hard lists, for the drill
+func (b *Bot) hardestWords(ctx context.Context, userID int64) ([]database.HardWord, error) {
+	return b.progressRepo.GetHardestWords(ctx, userID, hardestWordsCount)
+}
+
+// hardestWordsText lists the hardest words with their easiness factor and how the last answer went
+func hardestWordsText(loc locale.Locale, words []database.HardWord) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "hard.title"))
+	for i, w := range words {
+		text.WriteString(fmt.Sprintf("%d. %s - %s\n   EF %.2f", i+1, w.Word.Word, w.Translation, w.EasinessFactor))
+		if w.LastQuality < int(spaced_repetition.QualityCorrectDifficult) {
+			text.WriteString(i18n.T(loc, "hard.forgotten"))
+		}
+		text.WriteString("\n")
+	}
+	text.WriteString(i18n.T(loc, "hard.footer"))
+	return text.String()
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Inline word lookup limits
+const (
+	maxInlineResults = 20
+	inlineCacheTime  = 300 // seconds Telegram may cache the answer for
+)
+
+// handleInlineQuery answers "@bot word" with the matching words from the user's dictionary.
+// Users who haven't started the bot yet get the button that opens it.
+func (b *Bot) handleInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, query.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var words []models.Word
+	if user != nil {
+		words, err = b.wordRepo.SearchWords(ctx, user.ID, query.Query, maxInlineResults)
+		if err != nil {
+			return err
+		}
+	}
+
+	results := make([]interface{}, 0, len(words))
+	for i := range words {
+		word := &words[i]
+		article := tgbotapi.NewInlineQueryResultArticle(strconv.Itoa(word.ID), word.Word, strings.TrimSpace(wordDetails(b.userLocale(user), word)))
+		article.Description = word.Translation
+		results = append(results, article)
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+	}
+	if len(results) == 0 && strings.TrimSpace(query.Query) != "" {
+		answer.SwitchPMText = i18n.T(b.userLocale(user), "inline.not_found")
+		answer.SwitchPMParameter = "inline"
+	}
+
+	_, err = b.api.Request(answer)
+	return err
+}
@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data of the /intervals screen
+const (
+	callbackIntervalsPrefix = "intervals_"
+	callbackIntervalsCustom = "intervals_custom"
+)
+
+// actionEditingIntervals is the user state while the bot waits for a custom interval ladder
+const actionEditingIntervals = "editing_intervals"
+
+// intervalPresetNames are the presets offered on the /intervals screen, in display order
+var intervalPresetNames = []struct {
+	Preset string
+	Key    string
+}{
+	{Preset: database.IntervalPresetIntensive, Key: "intervals.intensive"},
+	{Preset: database.IntervalPresetStandard, Key: "intervals.standard"},
+	{Preset: database.IntervalPresetRelaxed, Key: "intervals.relaxed"},
+}
+
+// intervalsUsage explains the custom ladder format
+func intervalsUsage(loc locale.Locale) string {
+	return i18n.T(loc, "intervals.usage", database.MaxCustomIntervals, database.MaxIntervalDays)
+}
+
+// handleIntervalsCommand handles /intervals: without arguments it shows the current ladder and
+// the presets, otherwise it takes a preset name or a comma-separated ladder
+func (b *Bot) handleIntervalsCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		text, err := b.intervalsText(ctx, loc, user.ID)
+		if err != nil {
+			return err
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		msg.ReplyMarkup = createKeyboard(intervalsButtons(loc))
+		return b.sendMessage(msg)
+	}
+
+	preset := strings.ToLower(args)
+	if _, ok := database.IntervalPresets[preset]; ok {
+		return b.saveIntervals(ctx, loc, message.Chat.ID, user.ID, preset, nil)
+	}
+	return b.saveCustomIntervals(ctx, loc, message.Chat.ID, user.ID, args)
+}
+
+// handleIntervalsCallback applies a preset button or asks for a custom ladder
+func (b *Bot) handleIntervalsCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if callback.Data == callbackIntervalsCustom {
+		b.states.put(callback.From.ID, &UserState{
+			Action: actionEditingIntervals,
+			Step:   1,
+			Data:   make(map[string]string),
+		})
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, i18n.T(loc, "intervals.ask"))
+		msg.ReplyMarkup = createKeyboard([][]MenuButton{
+			{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+		})
+		return b.sendMessage(msg)
+	}
+
+	preset := strings.TrimPrefix(callback.Data, callbackIntervalsPrefix)
+	if _, ok := database.IntervalPresets[preset]; !ok {
+		return &ValidationError{Message: i18n.T(loc, "stale.intervals")}
+	}
+	return b.saveIntervals(ctx, loc, callback.Message.Chat.ID, user.ID, preset, nil)
+}
+
+// handleIntervalsText saves the custom ladder sent after the "Свой график" button
+func (b *Bot) handleIntervalsText(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	intervals, err := database.ParseIntervals(message.Text)
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "intervals.retry")+intervalsUsage(loc)))
+	}
+	if err := b.saveIntervals(ctx, loc, message.Chat.ID, user.ID, database.IntervalPresetCustom, intervals); err != nil {
+		return err
+	}
+	b.states.remove(message.From.ID)
+	return nil
+}
+
+// saveCustomIntervals validates a comma-separated ladder and stores it as the custom preset
+func (b *Bot) saveCustomIntervals(ctx context.Context, loc locale.Locale, chatID, userID int64, text string) error {
+	intervals, err := database.ParseIntervals(text)
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "intervals.invalid")+intervalsUsage(loc)))
+	}
+	return b.saveIntervals(ctx, loc, chatID, userID, database.IntervalPresetCustom, intervals)
+}
+
+// saveIntervals stores the user's choice and confirms the new ladder
+func (b *Bot) saveIntervals(ctx context.Context, loc locale.Locale, chatID, userID int64, preset string, custom []int) error {
+	if err := database.SetUserIntervals(ctx, userID, preset, custom); err != nil {
+		return err
+	}
+	intervals := custom
+	if preset != database.IntervalPresetCustom {
+		intervals = database.IntervalPresets[preset]
+	}
+	text := i18n.T(loc, "intervals.saved", intervalPresetName(loc, preset), database.FormatIntervals(intervals))
+	return b.sendMessage(tgbotapi.NewMessage(chatID, text))
+}
+
+// intervalsText describes the user's current ladder and the available presets
+func (b *Bot) intervalsText(ctx context.Context, loc locale.Locale, userID int64) (string, error) {
+	config, err := database.GetUserConfig(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config: %w", err)
+	}
+
+	preset := database.IntervalPresetStandard
+	intervals := database.DefaultIntervals
+	if config != nil {
+		preset = config.IntervalPreset
+		intervals = config.Intervals()
+	}
+
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "intervals.title"))
+	text.WriteString(i18n.T(loc, "intervals.current", intervalPresetName(loc, preset), database.FormatIntervals(intervals)))
+	for _, p := range intervalPresetNames {
+		text.WriteString(fmt.Sprintf("%s: %s\n", i18n.T(loc, p.Key), database.FormatIntervals(database.IntervalPresets[p.Preset])))
+	}
+	text.WriteString(i18n.T(loc, "intervals.hint"))
+	text.WriteString(intervalsUsage(loc))
+	return text.String(), nil
+}
+
+// intervalPresetName returns the display name of a preset
+func intervalPresetName(loc locale.Locale, preset string) string {
+	for _, p := range intervalPresetNames {
+		if p.Preset == preset {
+			return i18n.T(loc, p.Key)
+		}
+	}
+	return i18n.T(loc, "intervals.custom")
+}
+
+// intervalsButtons returns one button per preset and the custom ladder button
+func intervalsButtons(loc locale.Locale) [][]MenuButton {
+	var buttons [][]MenuButton
+	for _, p := range intervalPresetNames {
+		buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, p.Key), CallbackData: callbackIntervalsPrefix + p.Preset}})
+	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "intervals.custom_button"), CallbackData: callbackIntervalsCustom}})
+	return buttons
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackLanguagePrefix switches the interface to the language code that follows
+const callbackLanguagePrefix = "language_"
+
+// handleLanguageCommand handles /language <ru|en>, or offers the languages as buttons
+func (b *Bot) handleLanguageCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	code := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if code == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(b.userLocale(user), "language.choose"))
+		msg.ReplyMarkup = createKeyboard(languageButtons())
+		return b.sendMessage(msg)
+	}
+
+	loc, ok := supportedLocale(code)
+	if !ok {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(b.userLocale(user), "language.unknown")))
+	}
+	return b.setLanguage(ctx, message.Chat.ID, user, loc)
+}
+
+// handleLanguageCallback switches the language from an inline button
+func (b *Bot) handleLanguageCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+
+	loc, ok := supportedLocale(strings.TrimPrefix(callback.Data, callbackLanguagePrefix))
+	if !ok {
+		return &ValidationError{Message: i18n.T(b.userLocale(user), "language.unknown")}
+	}
+	return b.setLanguage(ctx, callback.Message.Chat.ID, user, loc)
+}
+
+// setLanguage stores the user's language and confirms it in that language
+func (b *Bot) setLanguage(ctx context.Context, chatID int64, user *models.User, loc locale.Locale) error {
+	user.Language = string(loc)
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "language.done"))
+	msg.ReplyMarkup = createKeyboard(b.mainMenuButtons(loc))
+	return b.sendMessage(msg)
+}
+
+// userLocale returns the user's interface language, or the bot default if they haven't chosen one
+func (b *Bot) userLocale(user *models.User) locale.Locale {
+	if user == nil || user.Language == "" {
+		return b.config.Locale
+	}
+	return locale.Parse(user.Language)
+}
+
+// localeFor looks up the interface language of a Telegram user
+func (b *Bot) localeFor(ctx context.Context, telegramID int64) locale.Locale {
+	user, err := b.userRepo.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get user for locale", "telegram_id", telegramID, "error", err)
+	}
+	return b.userLocale(user)
+}
+
+// supportedLocale returns the locale for a language code offered by /language
+func supportedLocale(code string) (locale.Locale, bool) {
+	for _, lang := range i18n.Languages {
+		if string(lang.Locale) == code {
+			return lang.Locale, true
+		}
+	}
+	return "", false
+}
+
+// languageButtons returns one button per interface language
+func languageButtons() [][]MenuButton {
+	var row []MenuButton
+	for _, lang := range i18n.Languages {
+		row = append(row, MenuButton{Text: lang.Name, CallbackData: callbackLanguagePrefix + string(lang.Locale)})
+	}
+	return [][]MenuButton{row}
+}
+
+// languageName returns the display name of a locale
+func languageName(loc locale.Locale) string {
+	for _, lang := range i18n.Languages {
+		if lang.Locale == loc {
+			return lang.Name
+		}
+	}
+	return string(loc)
+}
+
+// enabledString describes whether notifications are on in the given locale
+func enabledString(loc locale.Locale, enabled bool) string {
+	if enabled {
+		return i18n.T(loc, "notifications.enabled")
+	}
+	return i18n.T(loc, "notifications.disabled")
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/spaced_repetition"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackSchedulerToggle switches the user between SM-2 and Leitner boxes in the settings
+const callbackSchedulerToggle = "scheduler_toggle"
+
+// handleSchedulerToggle switches the user's scheduler. Topics keep their repetitions, the new
+// scheduler picks the dates from the next graded answer on.
+func (b *Bot) handleSchedulerToggle(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	text := i18n.T(loc, "scheduler.sm2_on")
+	if user.Scheduler == models.SchedulerLeitner {
+		user.Scheduler = models.SchedulerSM2
+	} else {
+		user.Scheduler = models.SchedulerLeitner
+		text = leitnerIntroText(loc)
+	}
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+	msg.ReplyMarkup = createKeyboard(b.settingsMenuButtons(loc))
+	return b.sendMessage(msg)
+}
+
+// leitnerIntroText explains how the Leitner boxes work
+func leitnerIntroText(loc locale.Locale) string {
+	intervals := spaced_repetition.NewLeitner().BoxIntervals
+	days := make([]string, len(intervals))
+	for i, interval := range intervals {
+		days[i] = fmt.Sprint(interval)
+	}
+	return i18n.T(loc, "scheduler.leitner_on", spaced_repetition.LeitnerBoxes, strings.Join(days, ", "))
+}
+
+// leitnerBoxesText shows how many active topics sit in each Leitner box
+func leitnerBoxesText(loc locale.Locale, topics []models.Topic) string {
+	var boxes [spaced_repetition.LeitnerBoxes]int
+	for _, topic := range topics {
+		if !topic.Archived {
+			boxes[max(1, min(topic.LeitnerBox, spaced_repetition.LeitnerBoxes))-1]++
+		}
+	}
+	most := 0
+	for _, count := range boxes {
+		most = max(most, count)
+	}
+
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "scheduler.boxes"))
+	for i, count := range boxes {
+		// Полоса не длиннее 10 клеток, непустая коробка получает хотя бы одну
+		cells := 0
+		if count > 0 {
+			cells = max(1, count*10/most)
+		}
+		text.WriteString(fmt.Sprintf("%d %s %d\n", i+1, strings.Repeat("▓", cells), count))
+	}
+	return text.String()
+}
+
+// schedulerName returns the display name of the scheduler
+func schedulerName(loc locale.Locale, scheduler string) string {
+	if scheduler == models.SchedulerLeitner {
+		return i18n.T(loc, "scheduler.leitner")
+	}
+	return i18n.T(loc, "scheduler.sm2")
+}
+
+// sm2ParamsText describes the SM-2 parameters the user's reviews run with. They are tuned
+// from the recall history by a scheduled job and can't be changed by hand.
+func sm2ParamsText(loc locale.Locale, sm2 *spaced_repetition.SM2, config *database.UserConfig) string {
+	if config == nil || !config.SM2TunedAt.Valid {
+		return i18n.T(loc, "sm2.default", spaced_repetition.MinTuningReviews)
+	}
+	// The first interval is the same day, the next few show how fast the reviews spread out
+	n := len(sm2.InitialIntervals)
+	return i18n.T(loc, "sm2.tuned", sm2.PassThreshold, sm2.MinEasiness,
+		database.FormatIntervals(sm2.InitialIntervals[min(1, n):min(5, n)]),
+		config.SM2TunedAt.Time.Format("02.01.2006"))
+}
+
+// schedulerToggleButton returns the settings button that switches to the other scheduler
+func schedulerToggleButton(loc locale.Locale, scheduler string) MenuButton {
+	if scheduler == models.SchedulerLeitner {
+		return MenuButton{Text: i18n.T(loc, "scheduler.to_sm2"), CallbackData: callbackSchedulerToggle}
+	}
+	return MenuButton{Text: i18n.T(loc, "scheduler.to_leitner"), CallbackData: callbackSchedulerToggle}
+}
@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// loadForecastDays is how many days /load shows
+const loadForecastDays = 14
+
+// maxDailyReviewLimit bounds the daily review limit a user can set
+const maxDailyReviewLimit = 200
+
+// loadBarWidth is the number of cells of the longest bar in the forecast
+const loadBarWidth = 12
+
+// handleLoadCommand handles /load: without arguments it shows the review forecast,
+// "/load <число>" caps the repetitions per day and "/load off" removes the cap
+func (b *Bot) handleLoadCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if args == "" {
+		return b.sendLoadForecast(ctx, message.Chat.ID, user, "")
+	}
+
+	limit := 0
+	if args != "off" && args != "0" {
+		limit, err = strconv.Atoi(args)
+		if err != nil || limit < 1 || limit > maxDailyReviewLimit {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "load.usage", maxDailyReviewLimit)))
+		}
+	}
+
+	user.DailyReviewLimit = limit
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	if limit == 0 {
+		return b.sendLoadForecast(ctx, message.Chat.ID, user, i18n.T(loc, "load.off"))
+	}
+
+	moved, err := b.repetitionRepo.Rebalance(ctx, user.ID, limit, database.LoadBalanceDays)
+	if err != nil {
+		return err
+	}
+	note := i18n.T(loc, "load.limit", limit, i18n.Plural(loc, "plural.review_genitive", limit))
+	if moved > 0 {
+		note += i18n.T(loc, "load.moved", moved)
+	}
+	return b.sendLoadForecast(ctx, message.Chat.ID, user, note)
+}
+
+// sendLoadForecast shows how many repetitions come due on each of the next days
+func (b *Bot) sendLoadForecast(ctx context.Context, chatID int64, user *models.User, note string) error {
+	counts, err := b.repetitionRepo.Forecast(ctx, user.ID, loadForecastDays)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	var text strings.Builder
+	if note != "" {
+		text.WriteString(note + "\n\n")
+	}
+	text.WriteString(i18n.T(loc, "load.title", loadForecastDays))
+	text.WriteString(loadForecastText(loc, counts, b.clock.Now(), user.DailyReviewLimit))
+	if user.DailyReviewLimit > 0 {
+		text.WriteString(i18n.T(loc, "load.current", user.DailyReviewLimit, maxDailyReviewLimit))
+	} else {
+		text.WriteString(i18n.T(loc, "load.hint", maxDailyReviewLimit))
+	}
+	return b.sendMessage(tgbotapi.NewMessage(chatID, text.String()))
+}
+
+// loadForecastText draws one bar per day starting with today, marking the days over the limit
+func loadForecastText(loc locale.Locale, counts []int, today time.Time, limit int) string {
+	most := maxCount(counts)
+	var text strings.Builder
+	total := 0
+	for day, count := range counts {
+		total += count
+		label := today.AddDate(0, 0, day).Format("02.01")
+		if day == 0 {
+			label = i18n.T(loc, "load.today")
+		}
+		bar := strings.Repeat("▇", (count*loadBarWidth+most-1)/most)
+		mark := ""
+		if limit > 0 && count > limit {
+			mark = " ⚠️"
+		}
+		text.WriteString(fmt.Sprintf("%s: %d %s%s\n", label, count, bar, mark))
+	}
+	text.WriteString(i18n.T(loc, "load.total", total))
+	return text.String()
+}
+
+// spillDate moves the date of a new repetition past the days the user has already filled up.
+// Failures only cost the balance, so they are logged and the date is kept.
+func (b *Bot) spillDate(ctx context.Context, user *models.User, date time.Time) time.Time {
+	spilled, err := b.repetitionRepo.SpillDate(ctx, user.ID, date, user.DailyReviewLimit)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to balance review date", "user_id", user.ID, "error", err)
+		return date
+	}
+	return spilled
+}
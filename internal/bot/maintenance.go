@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/service"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackMaintenancePrefix turns maintenance reviews of the topic with the ID that follows on or off
+const callbackMaintenancePrefix = "maintenance_"
+
+// handleMaintenanceCommand handles /maintenance <номер>: turns maintenance reviews of the topic
+// on or off. Without a number it lists the topics that have them.
+func (b *Bot) handleMaintenanceCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+
+	loc := b.userLocale(user)
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, maintenanceListText(loc, topics)))
+	}
+	index, err := strconv.Atoi(args)
+	if err != nil || index < 1 || index > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "maintenance.usage")))
+	}
+	topic := topics[index-1]
+	if err := b.setMaintenance(ctx, user, &topic, !topic.Maintenance); err != nil {
+		return err
+	}
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, maintenanceText(loc, topic)))
+}
+
+// handleMaintenanceCallback turns maintenance reviews on or off from the button under the topic
+// statistics
+func (b *Bot) handleMaintenanceCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	topicID, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, callbackMaintenancePrefix), 10, 64)
+	if err != nil {
+		return &ValidationError{Message: i18n.T(loc, "stale.topic")}
+	}
+	topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+	}
+	if err := b.setMaintenance(ctx, user, topic, !topic.Maintenance); err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, maintenanceText(loc, *topic))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "button.topic_stats"), CallbackData: fmt.Sprintf("%s%d", callbackTopicStatsPrefix, topic.ID)}},
+		{{Text: i18n.T(loc, "button.menu"), CallbackData: "main_menu"}},
+	})
+	return b.sendMessage(msg)
+}
+
+// setMaintenance turns maintenance reviews of the topic on or off. A topic that has already
+// finished its cycle gets its first maintenance review scheduled at once.
+func (b *Bot) setMaintenance(ctx context.Context, user *models.User, topic *models.Topic, enabled bool) error {
+	next := b.clock.Now().AddDate(0, 0, service.MaintenanceIntervals[0])
+	if err := b.topicRepo.SetMaintenance(ctx, user.ID, topic.ID, enabled, next); err != nil {
+		return err
+	}
+	topic.Maintenance = enabled
+	return nil
+}
+
+// maintenanceText confirms the maintenance reviews of the topic were turned on or off
+func maintenanceText(loc locale.Locale, topic models.Topic) string {
+	if topic.Maintenance {
+		return i18n.T(loc, "maintenance.on", topic.Name, service.CycleRepetitions,
+			service.MaintenanceIntervals[0], service.MaintenanceIntervals[len(service.MaintenanceIntervals)-1])
+	}
+	return i18n.T(loc, "maintenance.off", topic.Name, service.CycleRepetitions)
+}
+
+// maintenanceListText lists the topics with maintenance reviews by their numbers in /list
+func maintenanceListText(loc locale.Locale, topics []models.Topic) string {
+	var text strings.Builder
+	for i, topic := range topics {
+		if topic.Maintenance {
+			text.WriteString(fmt.Sprintf("%d. %s\n", i+1, topic.Name))
+		}
+	}
+	if text.Len() == 0 {
+		return i18n.T(loc, "maintenance.none", service.CycleRepetitions)
+	}
+	return i18n.T(loc, "maintenance.list") + text.String() + i18n.T(loc, "maintenance.toggle_hint")
+}
+
+// maintenanceButton turns maintenance reviews of the topic on or off
+func maintenanceButton(loc locale.Locale, topic models.Topic) MenuButton {
+	text := i18n.T(loc, "maintenance.enable")
+	if topic.Maintenance {
+		text = i18n.T(loc, "maintenance.disable")
+	}
+	return MenuButton{Text: text, CallbackData: fmt.Sprintf("%s%d", callbackMaintenancePrefix, topic.ID)}
+}
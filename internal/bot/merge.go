@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/textutil"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data prefixes of /merge, followed by "<source ID>_<target ID>"
+const (
+	callbackAskMergePrefix = "ask_merge_" // asks to confirm merging
+	callbackMergePrefix    = "do_merge_"  // merges
+)
+
+// maxMergeSuggestions limits the duplicate pairs /merge offers
+const maxMergeSuggestions = 10
+
+// handleMergeCommand handles /merge <номер> <номер>: the first topic is merged into the second.
+// Without arguments it lists the topics that look like duplicates of each other.
+func (b *Bot) handleMergeCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+
+	loc := b.userLocale(user)
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		return b.suggestMerges(loc, message.Chat.ID, topics)
+	}
+
+	usage := i18n.T(loc, "merge.usage")
+	if len(args) != 2 {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+	sourceIndex, err := strconv.Atoi(args[0])
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+	targetIndex, err := strconv.Atoi(args[1])
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+	if sourceIndex < 1 || sourceIndex > len(topics) || targetIndex < 1 || targetIndex > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topic.wrong_number")))
+	}
+	if sourceIndex == targetIndex {
+		return &ValidationError{Message: i18n.T(loc, "merge.same")}
+	}
+
+	source, target := topics[sourceIndex-1], topics[targetIndex-1]
+	if err := b.checkMerge(ctx, loc, user.ID, source.ID, target.ID); err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, mergeConfirmText(loc, source, target))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "merge.button"), CallbackData: fmt.Sprintf("%s%d_%d", callbackMergePrefix, source.ID, target.ID)}},
+		{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+	})
+	return b.sendMessage(msg)
+}
+
+// suggestMerges lists pairs of topics whose names differ only in case, spaces or a typo,
+// with a button to merge each pair
+func (b *Bot) suggestMerges(loc locale.Locale, chatID int64, topics []models.Topic) error {
+	var text strings.Builder
+	var buttons [][]MenuButton
+	for i := 0; i < len(topics) && len(buttons) < maxMergeSuggestions; i++ {
+		for j := i + 1; j < len(topics) && len(buttons) < maxMergeSuggestions; j++ {
+			if !textutil.IsSimilar(topics[i].Name, topics[j].Name, similarTopicMaxDistance) {
+				continue
+			}
+			// The newer topic goes into the older one
+			text.WriteString(fmt.Sprintf("• %d. %s  ↔  %d. %s\n", i+1, topics[i].Name, j+1, topics[j].Name))
+			buttons = append(buttons, []MenuButton{{
+				Text:         fmt.Sprintf("🔀 %d → %d", j+1, i+1),
+				CallbackData: fmt.Sprintf("%s%d_%d", callbackAskMergePrefix, topics[j].ID, topics[i].ID),
+			}})
+		}
+	}
+
+	if len(buttons) == 0 {
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "merge.none")))
+	}
+	msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "merge.suggest", text.String()))
+	msg.ReplyMarkup = createKeyboard(buttons)
+	return b.sendMessage(msg)
+}
+
+// handleMergeCallback asks to confirm a suggested merge or runs a confirmed one
+func (b *Bot) handleMergeCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	confirmed := strings.HasPrefix(callback.Data, callbackMergePrefix)
+	ids := strings.Split(strings.TrimPrefix(strings.TrimPrefix(callback.Data, callbackMergePrefix), callbackAskMergePrefix), "_")
+	stale := &ValidationError{Message: i18n.T(loc, "stale.merge")}
+	if len(ids) != 2 {
+		return stale
+	}
+	sourceID, err := strconv.ParseInt(ids[0], 10, 64)
+	if err != nil {
+		return stale
+	}
+	targetID, err := strconv.ParseInt(ids[1], 10, 64)
+	if err != nil {
+		return stale
+	}
+
+	source, err := b.topicRepo.GetByID(ctx, user.ID, sourceID)
+	if err != nil {
+		return err
+	}
+	target, err := b.topicRepo.GetByID(ctx, user.ID, targetID)
+	if err != nil {
+		return err
+	}
+	if source == nil || target == nil {
+		return &ValidationError{Message: i18n.T(loc, "merge.not_found")}
+	}
+
+	chatID := callback.Message.Chat.ID
+	if !confirmed {
+		msg := tgbotapi.NewMessage(chatID, mergeConfirmText(loc, *source, *target))
+		msg.ReplyMarkup = createKeyboard([][]MenuButton{
+			{{Text: i18n.T(loc, "merge.button"), CallbackData: fmt.Sprintf("%s%d_%d", callbackMergePrefix, source.ID, target.ID)}},
+			{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+		})
+		return b.sendMessage(msg)
+	}
+
+	if err := b.checkMerge(ctx, loc, user.ID, source.ID, target.ID); err != nil {
+		return err
+	}
+	if err := b.topicRepo.Merge(ctx, user.ID, source.ID, target.ID); err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "merge.done", source.Name, target.Name))
+	msg.ReplyMarkup = createKeyboard(b.topicsMenuButtons(loc))
+	return b.sendMessage(msg)
+}
+
+// checkMerge makes sure the subtopics of the source can move under the target: the target
+// mustn't be one of them, and the nesting has to stay within models.MaxTopicDepth
+func (b *Bot) checkMerge(ctx context.Context, loc locale.Locale, userID, sourceID, targetID int64) error {
+	topics, err := b.topicRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+
+	var source, target = -1, -1
+	for i, t := range topics {
+		switch t.ID {
+		case sourceID:
+			source = i
+		case targetID:
+			target = i
+		}
+	}
+	if source < 0 || target < 0 {
+		return &ValidationError{Message: i18n.T(loc, "merge.not_found")}
+	}
+
+	end := subtreeEnd(topics, source)
+	if target > source && target < end {
+		return &ValidationError{Message: i18n.T(loc, "merge.subtopic")}
+	}
+	height := 0
+	for _, t := range topics[source+1 : end] {
+		height = max(height, t.Depth-topics[source].Depth)
+	}
+	if topics[target].Depth+height+1 > models.MaxTopicDepth {
+		return &ValidationError{Message: i18n.T(loc, "merge.too_deep", models.MaxTopicDepth)}
+	}
+	return nil
+}
+
+// mergeConfirmText describes what merging source into target does
+func mergeConfirmText(loc locale.Locale, source, target models.Topic) string {
+	return i18n.T(loc, "merge.confirm", source.Name, target.Name, target.Name, source.Name)
+}
@@ -0,0 +1,114 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleNewWordsCommand handles /newwords: without arguments it shows the daily number of new
+// words and how many are waiting, "/newwords <число>" sets it and "/newwords off" removes the cap
+func (b *Bot) handleNewWordsCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if args == "" {
+		return b.sendNewWordsStatus(ctx, message.Chat.ID, user, "")
+	}
+
+	limit := database.NoNewWordsLimit
+	if args != "off" {
+		limit, err = strconv.Atoi(args)
+		if err != nil || limit < 0 || limit > database.MaxNewWordsPerDay {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "newwords.usage", database.MaxNewWordsPerDay)))
+		}
+	}
+	if err := database.SetNewWordsPerDay(ctx, user.ID, limit); err != nil {
+		return err
+	}
+
+	note := i18n.T(loc, "newwords.all")
+	switch {
+	case limit == 0:
+		note = i18n.T(loc, "newwords.none")
+	case limit > 0:
+		note = i18n.T(loc, "newwords.limit", limit, i18n.Plural(loc, "plural.new_word", limit))
+	}
+	return b.sendNewWordsStatus(ctx, message.Chat.ID, user, note)
+}
+
+// sendNewWordsStatus shows the daily number of new words, how many came today and how many are waiting
+func (b *Bot) sendNewWordsStatus(ctx context.Context, chatID int64, user *models.User, note string) error {
+	limit, err := database.GetNewWordsPerDay(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	introduced, err := b.progressRepo.CountIntroducedSince(ctx, user.ID, startOfDay(b.clock.Now()))
+	if err != nil {
+		return err
+	}
+	progress, err := b.progressRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	waiting := 0
+	for _, p := range progress {
+		if p.IntroducedAt == nil {
+			waiting++
+		}
+	}
+
+	loc := b.userLocale(user)
+	var text strings.Builder
+	if note != "" {
+		text.WriteString(note + "\n\n")
+	}
+	text.WriteString(i18n.T(loc, "newwords.title"))
+	if limit == database.NoNewWordsLimit {
+		text.WriteString(i18n.T(loc, "newwords.per_day_unlimited"))
+	} else {
+		text.WriteString(i18n.T(loc, "newwords.per_day", limit))
+	}
+	text.WriteString(i18n.T(loc, "newwords.status", introduced, waiting))
+	text.WriteString(i18n.T(loc, "newwords.footer", database.MaxNewWordsPerDay))
+	return b.sendMessage(tgbotapi.NewMessage(chatID, text.String()))
+}
+
+// dueWords returns the user's words due for review in the order to review them, with only as
+// many brand-new words as are left for today
+func (b *Bot) dueWords(ctx context.Context, userID int64) ([]models.UserProgress, error) {
+	due, err := b.progressRepo.GetDueWordsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	left, err := b.newWordsLeft(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return b.sm2.GetNextWords(due, len(due), left), nil
+}
+
+// newWordsLeft returns how many brand-new words the user can still get today, -1 without a cap
+func (b *Bot) newWordsLeft(ctx context.Context, userID int64) (int, error) {
+	limit, err := database.GetNewWordsPerDay(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if limit == database.NoNewWordsLimit {
+		return -1, nil
+	}
+	introduced, err := b.progressRepo.CountIntroducedSince(ctx, userID, startOfDay(b.clock.Now()))
+	if err != nil {
+		return 0, err
+	}
+	return max(limit-introduced, 0), nil
+}
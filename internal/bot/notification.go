@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+)
+
+// notifyOnce sends the notification of the kind unless the user already got it today.
+// The reminder job runs at start and may run more often than hourly, the log keeps
+// the user from getting a reminder twice. A failed send is forgotten, so the next run retries it,
+// unless the user blocked the bot: that is counted towards turning the notifications off.
+func (b *Bot) notifyOnce(ctx context.Context, user *models.User, kind string, send func() error) error {
+	claimed, err := b.notificationRepo.Claim(ctx, user.ID, kind)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		logging.FromContext(ctx).Debug("notification already sent today", "user_id", user.ID, "type", kind)
+		return nil
+	}
+
+	if err := send(); err != nil {
+		if isBlockedError(err) {
+			b.recordBlocked(ctx, user, err)
+			return err
+		}
+		if releaseErr := b.notificationRepo.Release(ctx, user.ID, kind); releaseErr != nil {
+			logging.FromContext(ctx).Warn("failed to release notification", "user_id", user.ID, "type", kind, "error", releaseErr)
+		}
+		return err
+	}
+	b.clearDeliveryFailures(ctx, user)
+	return nil
+}
@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/scheduler"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data of the reminder time picker
+const (
+	callbackTimeSettings     = "time_settings"
+	callbackTimeTogglePrefix = "time_toggle_"
+	callbackQuietPrefix      = "quiet_"
+	callbackQuietMenu        = "quiet_menu"
+	callbackQuietOff         = "quiet_off"
+	callbackQuietDefault     = "quiet_default"
+	callbackQuietFromPrefix  = "quiet_from_"
+	callbackQuietToPrefix    = "quiet_to_"
+)
+
+// hoursPerRow is the width of the hour grid in the picker keyboards
+const hoursPerRow = 6
+
+// handleTimeSettings shows the reminder time picker in place of the settings message
+func (b *Bot) handleTimeSettings(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	return b.showTimePicker(callback, user)
+}
+
+// handleTimePickerCallback handles the hour toggles and the quiet hours buttons of the picker
+func (b *Bot) handleTimePickerCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	data := callback.Data
+	switch {
+	case data == callbackQuietMenu:
+		return b.showQuietStartPicker(loc, callback)
+	case data == callbackQuietOff:
+		return b.setQuietHours(ctx, callback, user, 0, 0)
+	case data == callbackQuietDefault:
+		return b.setQuietHours(ctx, callback, user, scheduler.DefaultNotificationEndHour, scheduler.DefaultNotificationStartHour)
+	case strings.HasPrefix(data, callbackQuietFromPrefix):
+		start, err := strconv.Atoi(strings.TrimPrefix(data, callbackQuietFromPrefix))
+		if err != nil || start < 0 || start > 23 {
+			return &ValidationError{Message: i18n.T(loc, "stale.time")}
+		}
+		return b.showQuietEndPicker(loc, callback, start)
+	case strings.HasPrefix(data, callbackQuietToPrefix):
+		var start, end int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(data, callbackQuietToPrefix), "%d_%d", &start, &end); err != nil ||
+			start < 0 || start > 23 || end < 0 || end > 23 {
+			return &ValidationError{Message: i18n.T(loc, "stale.time")}
+		}
+		return b.setQuietHours(ctx, callback, user, start, end)
+	case strings.HasPrefix(data, callbackTimeTogglePrefix):
+		hour, err := strconv.Atoi(strings.TrimPrefix(data, callbackTimeTogglePrefix))
+		if err != nil || hour < 0 || hour > 23 {
+			return &ValidationError{Message: i18n.T(loc, "stale.time")}
+		}
+		return b.toggleNotificationHour(ctx, callback, user, hour)
+	}
+	return &ValidationError{Message: i18n.T(loc, "stale.time")}
+}
+
+// toggleNotificationHour adds or removes one reminder time, keeping at least one
+func (b *Bot) toggleNotificationHour(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User, hour int) error {
+	loc := b.userLocale(user)
+	hours := database.NotificationHours(user)
+	if i := slices.Index(hours, hour); i >= 0 {
+		if len(hours) == 1 {
+			return &ValidationError{Message: i18n.T(loc, "time.last_hour")}
+		}
+		hours = slices.Delete(hours, i, i+1)
+	} else {
+		if len(hours) >= database.MaxNotificationHours {
+			return &ValidationError{Message: i18n.T(loc, "time.too_many", database.MaxNotificationHours)}
+		}
+		hours = append(hours, hour)
+	}
+
+	database.SetNotificationHours(user, hours)
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	return b.showTimePicker(callback, user)
+}
+
+// setQuietHours stores the quiet hours window and returns to the time picker
+func (b *Bot) setQuietHours(ctx context.Context, callback *tgbotapi.CallbackQuery, user *models.User, start, end int) error {
+	user.QuietHoursStart = start
+	user.QuietHoursEnd = end
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	return b.showTimePicker(callback, user)
+}
+
+// showTimePicker renders the reminder hours grid
+func (b *Bot) showTimePicker(callback *tgbotapi.CallbackQuery, user *models.User) error {
+	loc := b.userLocale(user)
+	hours := database.NotificationHours(user)
+
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "time.title"))
+	text.WriteString(i18n.T(loc, "time.hours", hoursText(hours)))
+	text.WriteString(i18n.T(loc, "time.quiet", quietHoursText(loc, user)))
+	text.WriteString(i18n.T(loc, "time.hint", database.MaxNotificationHours))
+
+	buttons := hourGrid(func(hour int) MenuButton {
+		label := strconv.Itoa(hour)
+		if slices.Contains(hours, hour) {
+			label = "✅" + label
+		} else if database.InQuietHours(user, hour) {
+			label = "🌙" + label
+		}
+		return MenuButton{Text: label, CallbackData: callbackTimeTogglePrefix + strconv.Itoa(hour)}
+	})
+	buttons = append(buttons,
+		[]MenuButton{{Text: i18n.T(loc, "time.quiet_button"), CallbackData: callbackQuietMenu}},
+		[]MenuButton{{Text: i18n.T(loc, "button.back_to_settings"), CallbackData: "settings"}},
+	)
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text.String(), createKeyboard(buttons))
+	return b.editMessage(msg)
+}
+
+// showQuietStartPicker asks when the quiet hours begin
+func (b *Bot) showQuietStartPicker(loc locale.Locale, callback *tgbotapi.CallbackQuery) error {
+	buttons := hourGrid(func(hour int) MenuButton {
+		return MenuButton{Text: strconv.Itoa(hour), CallbackData: callbackQuietFromPrefix + strconv.Itoa(hour)}
+	})
+	buttons = append(buttons,
+		[]MenuButton{{
+			Text:         fmt.Sprintf("🌙 %d:00-%d:00", scheduler.DefaultNotificationEndHour, scheduler.DefaultNotificationStartHour),
+			CallbackData: callbackQuietDefault,
+		}},
+		[]MenuButton{{Text: i18n.T(loc, "time.quiet_off_button"), CallbackData: callbackQuietOff}},
+		[]MenuButton{{Text: i18n.T(loc, "button.back"), CallbackData: callbackTimeSettings}},
+	)
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID,
+		i18n.T(loc, "time.quiet_start"), createKeyboard(buttons))
+	return b.editMessage(msg)
+}
+
+// showQuietEndPicker asks when the quiet hours that begin at start are over
+func (b *Bot) showQuietEndPicker(loc locale.Locale, callback *tgbotapi.CallbackQuery, start int) error {
+	buttons := hourGrid(func(hour int) MenuButton {
+		if hour == start {
+			return MenuButton{Text: "▶️" + strconv.Itoa(hour), CallbackData: callbackQuietMenu}
+		}
+		return MenuButton{Text: strconv.Itoa(hour), CallbackData: fmt.Sprintf("%s%d_%d", callbackQuietToPrefix, start, hour)}
+	})
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "button.back"), CallbackData: callbackQuietMenu}})
+
+	text := i18n.T(loc, "time.quiet_end", start)
+	msg := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, createKeyboard(buttons))
+	return b.editMessage(msg)
+}
+
+// hourGrid lays out one button per hour of the day, hoursPerRow per row
+func hourGrid(button func(hour int) MenuButton) [][]MenuButton {
+	var buttons [][]MenuButton
+	for start := 0; start < 24; start += hoursPerRow {
+		var row []MenuButton
+		for hour := start; hour < start+hoursPerRow; hour++ {
+			row = append(row, button(hour))
+		}
+		buttons = append(buttons, row)
+	}
+	return buttons
+}
+
+// hoursText lists reminder hours like "9:00, 14:00, 20:00"
+func hoursText(hours []int) string {
+	fields := make([]string, len(hours))
+	for i, hour := range hours {
+		fields[i] = fmt.Sprintf("%d:00", hour)
+	}
+	return strings.Join(fields, ", ")
+}
+
+// quietHoursText describes the user's quiet hours window
+func quietHoursText(loc locale.Locale, user *models.User) string {
+	if user.QuietHoursStart == user.QuietHoursEnd {
+		return i18n.T(loc, "quiet.off")
+	}
+	return fmt.Sprintf("%d:00-%d:00", user.QuietHoursStart, user.QuietHoursEnd)
+}
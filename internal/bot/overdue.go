@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data prefixes of the weekly prompt about stalled topics, followed by the topic ID
+const (
+	callbackReviveTopicPrefix    = "revive_topic_"    // starts the topic over and turns reminders back on
+	callbackStalledArchivePrefix = "stalled_archive_" // moves the topic to the archive
+)
+
+// maxOverdueDays bounds how many days overdue the user can let a repetition get
+const maxOverdueDays = 90
+
+// overduePolicyNames are the catalog keys describing the overdue policies in messages
+var overduePolicyNames = map[string]string{
+	models.OverdueRemind:     "overdue.remind",
+	models.OverdueReschedule: "overdue.reschedule",
+	models.OverdueReset:      "overdue.reset",
+	models.OverdueStall:      "overdue.stall",
+}
+
+// handleOverdueCommand handles /overdue: without arguments it shows the current policy,
+// "/overdue <remind|reschedule|reset|stall> [дней]" changes it
+func (b *Bot) handleOverdueCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	args := strings.Fields(strings.ToLower(message.CommandArguments()))
+	if len(args) == 0 {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, overdueStatusText(loc, user)))
+	}
+
+	usage := i18n.T(loc, "overdue.usage", maxOverdueDays)
+	if _, ok := overduePolicyNames[args[0]]; !ok || len(args) > 2 {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+	if len(args) == 2 {
+		days, err := strconv.Atoi(args[1])
+		if err != nil || days < 1 || days > maxOverdueDays {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+		}
+		user.OverdueDays = days
+	}
+	user.OverduePolicy = args[0]
+
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "overdue.saved")+overdueStatusText(loc, user)))
+}
+
+// overdueStatusText describes what happens to the user's overdue repetitions
+func overdueStatusText(loc locale.Locale, user *models.User) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "overdue.title"))
+	if user.OverduePolicy == models.OverdueRemind {
+		text.WriteString(i18n.T(loc, "overdue.current_remind", i18n.T(loc, overduePolicyNames[models.OverdueRemind])))
+	} else {
+		text.WriteString(i18n.T(loc, "overdue.current", user.OverdueDays, i18n.Plural(loc, "plural.day", user.OverdueDays),
+			i18n.T(loc, overduePolicyNames[user.OverduePolicy])))
+	}
+	text.WriteString(i18n.T(loc, "overdue.options"))
+	for _, policy := range []string{models.OverdueRemind, models.OverdueReschedule, models.OverdueReset, models.OverdueStall} {
+		text.WriteString(fmt.Sprintf("• /overdue %s - %s\n", policy, i18n.T(loc, overduePolicyNames[policy])))
+	}
+	text.WriteString(i18n.T(loc, "overdue.days_hint", maxOverdueDays))
+	return text.String()
+}
+
+// ApplyOverduePolicy handles the user's repetitions overdue by more than the user's number of days
+// according to the user's policy and tells the user what was done.
+// It implements the scheduler.Notifier interface.
+func (b *Bot) ApplyOverduePolicy(ctx context.Context, telegramID int64) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || user.OverduePolicy == models.OverdueRemind {
+		return err
+	}
+
+	overdue, err := b.repetitionRepo.GetOverdue(ctx, user.ID, b.clock.Now().AddDate(0, 0, -user.OverdueDays))
+	if err != nil || len(overdue) == 0 {
+		return err
+	}
+
+	var names []string
+	var topicIDs []int64
+	for _, rep := range overdue {
+		names = append(names, rep.TopicName)
+		topicIDs = append(topicIDs, rep.TopicID)
+	}
+
+	loc := b.userLocale(user)
+	var text string
+	switch user.OverduePolicy {
+	case models.OverdueReschedule:
+		intervals, err := database.GetUserIntervals(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get topics: %w", err)
+		}
+		byID := make(map[int64]*models.Topic, len(topics))
+		for i := range topics {
+			byID[topics[i].ID] = &topics[i]
+		}
+		for _, rep := range overdue {
+			ladder := database.TopicIntervals(byID[rep.TopicID], intervals)
+			next := b.spillDate(ctx, user, b.repetitionRepo.CalculateNextReviewDate(rep.RepetitionNumber-1, ladder))
+			if err := b.repetitionRepo.Reschedule(ctx, user.ID, rep.ID, next); err != nil {
+				return err
+			}
+		}
+		text = i18n.T(loc, "overdue.rescheduled")
+	case models.OverdueReset:
+		if _, err := b.repetitionRepo.RestartTopics(ctx, user.ID, topicIDs); err != nil {
+			return err
+		}
+		text = i18n.T(loc, "overdue.restarted")
+	case models.OverdueStall:
+		if _, err := b.topicRepo.BulkSetStalled(ctx, user.ID, topicIDs, true); err != nil {
+			return err
+		}
+		text = i18n.T(loc, "overdue.stalled")
+	default:
+		logging.FromContext(ctx).Warn("unknown overdue policy", "user_id", user.ID, "policy", user.OverduePolicy)
+		return nil
+	}
+
+	text += "\n\n• " + strings.Join(names, "\n• ") + i18n.T(loc, "overdue.settings")
+	return b.sendMessage(tgbotapi.NewMessage(telegramID, text))
+}
+
+// SendStalledPrompt asks the user to revive or archive each stalled topic. The scheduler
+// sends it weekly, the notification log keeps a rerun on the same day from repeating it.
+// It implements the scheduler.Notifier interface.
+func (b *Bot) SendStalledPrompt(ctx context.Context, telegramID int64) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		return err
+	}
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	var buttons [][]MenuButton
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "overdue.stalled_title"))
+	for _, topic := range topics {
+		if !topic.Stalled || topic.Archived {
+			continue
+		}
+		text.WriteString(fmt.Sprintf("• %s\n", topic.Name))
+		buttons = append(buttons, []MenuButton{
+			{Text: "🔄 " + topic.Name, CallbackData: fmt.Sprintf("%s%d", callbackReviveTopicPrefix, topic.ID)},
+			{Text: i18n.T(loc, "overdue.archive_button"), CallbackData: fmt.Sprintf("%s%d", callbackStalledArchivePrefix, topic.ID)},
+		})
+	}
+	if len(buttons) == 0 {
+		return nil
+	}
+	text.WriteString(i18n.T(loc, "overdue.stalled_hint"))
+
+	return b.notifyOnce(ctx, user, database.NotificationStalledPrompt, func() error {
+		msg := tgbotapi.NewMessage(telegramID, text.String())
+		msg.ReplyMarkup = createKeyboard(buttons)
+		return b.sendMessage(msg)
+	})
+}
+
+// handleStalledCallback revives a stalled topic or moves it to the archive
+func (b *Bot) handleStalledCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	revive := strings.HasPrefix(callback.Data, callbackReviveTopicPrefix)
+	topicID, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimPrefix(callback.Data, callbackReviveTopicPrefix), callbackStalledArchivePrefix), 10, 64)
+	if err != nil {
+		return &ValidationError{Message: i18n.T(loc, "stale.topic")}
+	}
+
+	topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+	}
+	if !topic.Stalled {
+		return &ValidationError{Message: i18n.T(loc, "overdue.not_stalled", topic.Name)}
+	}
+
+	if _, err := b.topicRepo.BulkSetStalled(ctx, user.ID, []int64{topic.ID}, false); err != nil {
+		return err
+	}
+	if !revive {
+		return b.setTopicArchived(ctx, callback.Message.Chat.ID, user, *topic, true)
+	}
+	if _, err := b.repetitionRepo.RestartTopics(ctx, user.ID, []int64{topic.ID}); err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, i18n.T(loc, "overdue.revived", topic.Name))
+	msg.ReplyMarkup = createKeyboard(b.topicsMenuButtons(loc))
+	return b.sendMessage(msg)
+}
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackWordKindPrefix switches the phrase with the ID that follows between a phrase and
+// a collocation
+const callbackWordKindPrefix = "word_kind_"
+
+// kindName names the kind of a word in a sentence
+func kindName(loc locale.Locale, kind string) string {
+	switch kind {
+	case models.KindPhrase:
+		return i18n.T(loc, "kind.phrase")
+	case models.KindCollocation:
+		return i18n.T(loc, "kind.collocation")
+	default:
+		return i18n.T(loc, "kind.word")
+	}
+}
+
+// wordKindButton returns the button switching a phrase to a collocation and back
+func wordKindButton(loc locale.Locale, word models.Word) MenuButton {
+	data := fmt.Sprintf("%s%d", callbackWordKindPrefix, word.ID)
+	if word.Kind == models.KindCollocation {
+		return MenuButton{Text: i18n.T(loc, "kind.to_phrase"), CallbackData: data}
+	}
+	return MenuButton{Text: i18n.T(loc, "kind.to_collocation"), CallbackData: data}
+}
+
+// switchWordKind makes the phrase a collocation or the collocation a phrase
+func (b *Bot) switchWordKind(ctx context.Context, loc locale.Locale, chatID, userID int64, word *models.Word) error {
+	if !word.IsPhrase() {
+		return &ValidationError{Message: i18n.T(loc, "kind.single_word", word.Word)}
+	}
+	kind := models.KindCollocation
+	if word.Kind == models.KindCollocation {
+		kind = models.KindPhrase
+	}
+	if err := b.wordRepo.SetKind(ctx, userID, word.ID, kind); err != nil {
+		return err
+	}
+	word.Kind = kind
+	msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "kind.switched", word.Word, kindName(loc, kind)))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{{wordKindButton(loc, *word)}})
+	return b.sendMessage(msg)
+}
+
+// wordKindsText is the part of /stats about words, phrases and collocations, each counted apart.
+// Users with single words only see one line.
+func wordKindsText(loc locale.Locale, counts []database.KindCount) string {
+	var text strings.Builder
+	for _, kind := range []struct{ kind, title string }{
+		{models.KindWord, "kind.words"},
+		{models.KindPhrase, "kind.phrases"},
+		{models.KindCollocation, "kind.collocations"},
+	} {
+		for _, count := range counts {
+			if count.Kind != kind.kind || count.Total == 0 {
+				continue
+			}
+			text.WriteString(i18n.T(loc, "kind.stats", i18n.T(loc, kind.title), count.Total, count.Studied, count.Learned))
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,245 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/plans"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data of the grammar plans
+const (
+	callbackPlansMenu         = "plans_menu"
+	callbackPlanPreviewPrefix = "plan_preview_" // shows the plan with the key that follows
+	callbackPlanCreatePrefix  = "plan_create_"  // creates its topic
+)
+
+// handlePlansCommand handles /plans: without arguments it lists the grammar plans, "/plans <номер>"
+// previews one
+func (b *Bot) handlePlansCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	created, err := b.createdPlans(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, plansText(loc, created))
+		msg.ReplyMarkup = createKeyboard(plansButtons(loc, created))
+		return b.sendMessage(msg)
+	}
+
+	index, err := strconv.Atoi(args)
+	if err != nil || index < 1 || index > len(plans.Grammar) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "plans.usage")))
+	}
+	plan := plans.Grammar[index-1]
+	msg := tgbotapi.NewMessage(message.Chat.ID, planPreviewText(loc, plan))
+	msg.ReplyMarkup = createKeyboard(planPreviewButtons(loc, plan, created[plan.Key]))
+	return b.sendMessage(msg)
+}
+
+// handlePlansMenu shows the list of plans in place of the current message
+func (b *Bot) handlePlansMenu(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	created, err := b.createdPlans(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		plansText(loc, created),
+		createKeyboard(plansButtons(loc, created)),
+	)
+	return b.editMessage(msg)
+}
+
+// handlePlanCallback previews a plan or creates its topic
+func (b *Bot) handlePlanCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	key, create := strings.CutPrefix(callback.Data, callbackPlanCreatePrefix)
+	if !create {
+		key = strings.TrimPrefix(callback.Data, callbackPlanPreviewPrefix)
+	}
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	plan, ok := plans.ByKey(key)
+	if !ok {
+		return &ValidationError{Message: i18n.T(loc, "plans.not_found")}
+	}
+	if create {
+		return b.createPlanTopic(ctx, callback.Message.Chat.ID, user, plan)
+	}
+
+	created, err := b.createdPlans(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		planPreviewText(loc, plan),
+		createKeyboard(planPreviewButtons(loc, plan, created[plan.Key])),
+	)
+	return b.editMessage(msg)
+}
+
+// createPlanTopic creates the plan's topic with its description, category and intervals, its
+// statistics, first repetition and the examples as notes, all together or nothing
+func (b *Bot) createPlanTopic(ctx context.Context, chatID int64, user *models.User, plan plans.Plan) error {
+	loc := b.userLocale(user)
+	limitReached, err := b.topicLimitReached(ctx, user)
+	if err != nil {
+		return err
+	}
+	if limitReached {
+		return &ValidationError{Message: b.topicLimitText(loc)}
+	}
+
+	firstReview := b.spillDate(ctx, user, b.repetitionRepo.CalculateNextReviewDate(0, plan.Intervals))
+	topic := &models.Topic{
+		UserID:          user.ID,
+		Name:            plan.Name,
+		Description:     plan.Description,
+		Category:        plans.Category,
+		Difficulty:      plan.Difficulty,
+		CustomIntervals: database.EncodeIntervals(plan.Intervals),
+	}
+	err = database.WithTx(ctx, func(tx *database.Tx) error {
+		if err := tx.CreateTopic(ctx, topic); err != nil {
+			return err
+		}
+		if err := tx.CreateStatistics(ctx, &models.Statistics{UserID: user.ID, TopicID: topic.ID}); err != nil {
+			return err
+		}
+		if err := tx.CreateRepetition(ctx, &models.Repetition{
+			UserID:           user.ID,
+			TopicID:          topic.ID,
+			RepetitionNumber: 1,
+			NextReviewDate:   firstReview,
+			CreatedAt:        b.clock.Now(),
+			UpdatedAt:        b.clock.Now(),
+		}); err != nil {
+			return err
+		}
+		for _, example := range plan.Examples {
+			note := &models.TopicAttachment{UserID: user.ID, TopicID: topic.ID, Kind: models.AttachmentNote, Content: example}
+			if err := tx.CreateAttachment(ctx, note); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, database.ErrTopicExists) {
+		return &ValidationError{Message: i18n.T(loc, "plans.exists", plan.Name)}
+	}
+	if err != nil {
+		return err
+	}
+
+	text := newRichText(tgbotapi.ModeHTML).
+		Text(i18n.T(loc, "plans.created_prefix")).Bold(topic.Name).Text(i18n.T(loc, "plans.created_suffix")).
+		Text(i18n.T(loc, "plans.first_review", firstReviewText(loc, firstReview, b.clock.Now()))).
+		Text(i18n.T(loc, "plans.schedule", database.FormatIntervals(plan.Intervals))).
+		Text(i18n.T(loc, "plans.examples", len(plan.Examples))).
+		Text(i18n.T(loc, "plans.examples_hint"))
+	msg := text.Message(chatID)
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{materialsButton(topic.ID, i18n.T(loc, "button.materials"))},
+		{{Text: i18n.T(loc, "plans.back"), CallbackData: callbackPlansMenu}},
+		{{Text: i18n.T(loc, "button.topic_list"), CallbackData: "list_topics"}},
+	})
+	return b.sendMessage(msg)
+}
+
+// createdPlans returns the keys of the plans the user already has a topic for
+func (b *Bot) createdPlans(ctx context.Context, userID int64) (map[string]bool, error) {
+	topics, err := b.topicRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics: %w", err)
+	}
+	created := make(map[string]bool)
+	for _, plan := range plans.Grammar {
+		for _, topic := range topics {
+			if strings.EqualFold(topic.Name, plan.Name) {
+				created[plan.Key] = true
+				break
+			}
+		}
+	}
+	return created, nil
+}
+
+// plansText lists the grammar plans, marking the ones the user already studies
+func plansText(loc locale.Locale, created map[string]bool) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "plans.title"))
+	for i, plan := range plans.Grammar {
+		mark := ""
+		if created[plan.Key] {
+			mark = " ✅"
+		}
+		text.WriteString(fmt.Sprintf("%d. %s - %s%s\n", i+1, plan.Name, difficultyLabel(loc, plan.Difficulty), mark))
+	}
+	text.WriteString(i18n.T(loc, "plans.hint"))
+	return text.String()
+}
+
+// plansButtons returns a preview button per plan
+func plansButtons(loc locale.Locale, created map[string]bool) [][]MenuButton {
+	var buttons [][]MenuButton
+	for _, plan := range plans.Grammar {
+		text := "📖 " + plan.Name
+		if created[plan.Key] {
+			text = "✅ " + plan.Name
+		}
+		buttons = append(buttons, []MenuButton{{Text: text, CallbackData: callbackPlanPreviewPrefix + plan.Key}})
+	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "button.main_menu"), CallbackData: "main_menu"}})
+	return buttons
+}
+
+// planPreviewText shows the plan's rule, examples and schedule
+func planPreviewText(loc locale.Locale, plan plans.Plan) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "plans.preview", plan.Name, difficultyLabel(loc, plan.Difficulty), plan.Description))
+	for _, example := range plan.Examples {
+		text.WriteString("• " + example + "\n")
+	}
+	text.WriteString(i18n.T(loc, "plans.preview_schedule", database.FormatIntervals(plan.Intervals)))
+	return text.String()
+}
+
+// planPreviewButtons returns the button creating the plan's topic unless the user has it, and the
+// way back
+func planPreviewButtons(loc locale.Locale, plan plans.Plan, created bool) [][]MenuButton {
+	back := []MenuButton{{Text: i18n.T(loc, "plans.back"), CallbackData: callbackPlansMenu}}
+	if created {
+		return [][]MenuButton{back}
+	}
+	return [][]MenuButton{
+		{{Text: i18n.T(loc, "plans.create"), CallbackData: callbackPlanCreatePrefix + plan.Key}},
+		back,
+	}
+}
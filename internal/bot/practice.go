@@ -0,0 +1,262 @@
+package bot
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/example/engbot/internal/ai"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/spaced_repetition"
+	wordtest "github.com/example/engbot/internal/testing"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackPracticeStop ends the conversation
+const callbackPracticeStop = "practice_stop"
+
+// practiceWordCount is how many of the user's words a conversation practices
+const practiceWordCount = 5
+
+// maxPracticeTurns is how many messages of the user a conversation lasts at most
+const maxPracticeTurns = 10
+
+// maxPracticeAge is how long an abandoned conversation is kept
+const maxPracticeAge = 2 * time.Hour
+
+// practiceSession is a conversation a user is having
+type practiceSession struct {
+	mu        sync.Mutex // serializes the messages
+	userID    int64
+	loc       locale.Locale // the user's interface language
+	words     []models.Word
+	dialogue  []ai.Turn
+	used      map[int]bool // IDs of the words used and credited as reviews
+	turns     int          // messages of the user so far
+	startedAt time.Time
+	done      bool
+}
+
+// practiceWords returns the words the conversation practices as the model is given them
+func (s *practiceSession) practiceWords() []string {
+	words := make([]string, len(s.words))
+	for i, w := range s.words {
+		words[i] = w.Word
+	}
+	return words
+}
+
+// practiceSessions keeps the conversations by Telegram user ID. Updates are handled
+// concurrently, so like quizSessions it is guarded.
+type practiceSessions struct {
+	mu       sync.Mutex
+	sessions map[int64]*practiceSession
+}
+
+func newPracticeSessions() *practiceSessions {
+	return &practiceSessions{sessions: make(map[int64]*practiceSession)}
+}
+
+// get returns the user's conversation, or nil if there is none
+func (p *practiceSessions) get(telegramID int64) *practiceSession {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sessions[telegramID]
+}
+
+// put starts the user's conversation, replacing the one the user was having, and forgets
+// abandoned ones
+func (p *practiceSessions) put(telegramID int64, s *practiceSession, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, old := range p.sessions {
+		if now.Sub(old.startedAt) > maxPracticeAge {
+			delete(p.sessions, id)
+		}
+	}
+	p.sessions[telegramID] = s
+}
+
+// remove ends the user's conversation if it is still s
+func (p *practiceSessions) remove(telegramID int64, s *practiceSession) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sessions[telegramID] == s {
+		delete(p.sessions, telegramID)
+	}
+}
+
+// handlePracticeCommand handles /practice: it starts a conversation with the language model
+// around the words to review, "/practice stop" ends it
+func (b *Bot) handlePracticeCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if b.languageModel == nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "practice.unavailable")))
+	}
+
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "":
+	case "stop":
+		return b.stopPractice(loc, message.From.ID, message.Chat.ID)
+	default:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "practice.usage")))
+	}
+
+	words, err := b.storyWords(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "practice.no_words")))
+	}
+	s := &practiceSession{
+		userID:    user.ID,
+		loc:       loc,
+		words:     words[:min(practiceWordCount, len(words))],
+		used:      make(map[int]bool),
+		startedAt: b.clock.Now(),
+	}
+
+	reply, err := b.languageModel.Converse(ctx, s.practiceWords(), nil)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to start conversation", "user_id", user.ID, "error", err)
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "practice.start_failed")))
+	}
+	s.dialogue = append(s.dialogue, ai.Turn{Text: reply.Text})
+	b.practices.put(message.From.ID, s, s.startedAt)
+
+	text := newRichText(tgbotapi.ModeHTML).Text("💬 ").Bold(i18n.T(loc, "practice.title")).
+		Text(i18n.T(loc, "practice.intro"))
+	for _, w := range s.words {
+		text.Text("• ").Bold(w.Word).Text(" - " + w.Translation + "\n")
+	}
+	text.Text(i18n.T(loc, "practice.credit")).Text(reply.Text)
+	msg := text.Message(message.Chat.ID)
+	msg.ReplyMarkup = practiceKeyboard(loc)
+	return b.sendMessage(msg)
+}
+
+// handlePracticeMessage answers the user's message in the conversation and credits the words
+// it used correctly as reviews
+func (b *Bot) handlePracticeMessage(ctx context.Context, message *tgbotapi.Message) error {
+	s := b.practices.get(message.From.ID)
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return nil
+	}
+	said := strings.TrimSpace(message.Text)
+	if said == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(s.loc, "practice.text_only")))
+	}
+
+	dialogue := append(s.dialogue, ai.Turn{Learner: true, Text: said})
+	reply, err := b.languageModel.Converse(ctx, s.practiceWords(), dialogue)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to continue conversation", "user_id", s.userID, "error", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(s.loc, "practice.reply_failed"))
+		msg.ReplyMarkup = practiceKeyboard(s.loc)
+		return b.sendMessage(msg)
+	}
+	s.dialogue = append(dialogue, ai.Turn{Text: reply.Text})
+	s.turns++
+
+	// The model decides whether a word was used correctly, the message has to contain it
+	var credited []string
+	for _, w := range s.words {
+		if s.used[w.ID] || !slices.Contains(reply.Used, w.Word) || !wordtest.UsesWord(said, w.Word, w.VerbForms) {
+			continue
+		}
+		if err := b.gradeWord(ctx, s.userID, w.ID, spaced_repetition.QualityCorrectHesitation); err != nil {
+			return err
+		}
+		s.used[w.ID] = true
+		credited = append(credited, w.Word)
+	}
+
+	text := newRichText(tgbotapi.ModeHTML)
+	if reply.Correction != "" {
+		text.Text("✏️ ").Italic(reply.Correction).Text("\n\n")
+	}
+	if len(credited) > 0 {
+		text.Text(i18n.T(s.loc, "practice.credited")).Bold(strings.Join(credited, ", ")).Text("\n\n")
+	}
+	if len(s.used) == len(s.words) || s.turns >= maxPracticeTurns {
+		text.Text(reply.Text + "\n\n")
+		return b.sendMessage(b.finishPractice(message.From.ID, s, text).Message(message.Chat.ID))
+	}
+
+	text.Text(reply.Text)
+	var left []string
+	for _, w := range s.words {
+		if !s.used[w.ID] {
+			left = append(left, w.Word)
+		}
+	}
+	text.Text(i18n.T(s.loc, "practice.left") + strings.Join(left, ", "))
+	msg := text.Message(message.Chat.ID)
+	msg.ReplyMarkup = practiceKeyboard(s.loc)
+	return b.sendMessage(msg)
+}
+
+// handlePracticeStop ends the conversation from its button
+func (b *Bot) handlePracticeStop(callback *tgbotapi.CallbackQuery) error {
+	loc := b.localeFor(context.Background(), callback.From.ID)
+	return b.stopPractice(loc, callback.From.ID, callback.Message.Chat.ID)
+}
+
+// stopPractice ends the user's conversation and sends its result
+func (b *Bot) stopPractice(loc locale.Locale, telegramID, chatID int64) error {
+	s := b.practices.get(telegramID)
+	if s == nil {
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "practice.none")))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return nil
+	}
+	return b.sendMessage(b.finishPractice(telegramID, s, newRichText(tgbotapi.ModeHTML)).Message(chatID))
+}
+
+// finishPractice ends the conversation and adds its result to the text. s must be locked and
+// not done yet.
+func (b *Bot) finishPractice(telegramID int64, s *practiceSession, text *richText) *richText {
+	s.done = true
+	b.practices.remove(telegramID, s)
+
+	var used, missed []string
+	for _, w := range s.words {
+		if s.used[w.ID] {
+			used = append(used, w.Word)
+		} else {
+			missed = append(missed, w.Word)
+		}
+	}
+	text.Text("🏁 ").Bold(i18n.T(s.loc, "practice.over")).
+		Text(i18n.T(s.loc, "practice.used", len(used), len(s.words)))
+	if len(used) > 0 {
+		text.Text(i18n.T(s.loc, "practice.used_words") + strings.Join(used, ", "))
+	}
+	if len(missed) > 0 {
+		text.Text(i18n.T(s.loc, "practice.missed", strings.Join(missed, ", ")))
+	}
+	return text.Text(i18n.T(s.loc, "practice.more"))
+}
+
+// practiceKeyboard returns the button ending the conversation
+func practiceKeyboard(loc locale.Locale) tgbotapi.InlineKeyboardMarkup {
+	return createKeyboard([][]MenuButton{{{Text: i18n.T(loc, "practice.stop"), CallbackData: callbackPracticeStop}}})
+}
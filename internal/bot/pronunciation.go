@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackSayPrefix is the callback data prefix of the pronunciation button on flashcards
+const callbackSayPrefix = "say_"
+
+// pronunciationButton returns the button that sends the word's pronunciation
+func pronunciationButton(loc locale.Locale, wordID int) MenuButton {
+	return MenuButton{Text: i18n.T(loc, "pronunciation.button"), CallbackData: fmt.Sprintf("%s%d", callbackSayPrefix, wordID)}
+}
+
+// handlePronunciation sends the pronunciation of the user's word as a voice message
+func (b *Bot) handlePronunciation(ctx context.Context, callback *tgbotapi.CallbackQuery, wordID int) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	loc := b.userLocale(user)
+	if b.speech == nil {
+		return &ValidationError{Message: i18n.T(loc, "pronunciation.disabled")}
+	}
+	if user == nil {
+		return &ValidationError{Message: i18n.T(loc, "error.start")}
+	}
+	word, err := b.wordRepo.GetByID(ctx, user.ID, wordID)
+	if err != nil {
+		return err
+	}
+	return b.sendPronunciation(ctx, loc, callback.Message.Chat.ID, word.Word)
+}
+
+// sendPronunciation sends the text spoken as a voice message, captioned with the text
+func (b *Bot) sendPronunciation(ctx context.Context, loc locale.Locale, chatID int64, text string) error {
+	return b.sendSpeech(ctx, loc, chatID, text, "🔊 "+text)
+}
+
+// sendSpeech sends the text spoken as a voice message with the caption, none if empty.
+// A clip is synthesized and uploaded once, later the cached Telegram file ID is sent.
+func (b *Bot) sendSpeech(ctx context.Context, loc locale.Locale, chatID int64, text, caption string) error {
+	fileID, err := b.pronunciationRepo.GetFileID(ctx, text)
+	if err != nil {
+		return err
+	}
+	if fileID != "" {
+		voice := tgbotapi.NewVoice(chatID, tgbotapi.FileID(fileID))
+		voice.Caption = caption
+		_, err := b.dispatcher.Send(ctx, chatID, voice)
+		return voiceError(loc, err)
+	}
+
+	audio, err := b.speech.Synthesize(ctx, text)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to synthesize pronunciation", "text", text, "error", err)
+		return &ValidationError{Message: i18n.T(loc, "pronunciation.failed")}
+	}
+	voice := tgbotapi.NewVoice(chatID, tgbotapi.FileBytes{Name: "pronunciation.ogg", Bytes: audio})
+	voice.Caption = caption
+	sent, err := b.dispatcher.Send(ctx, chatID, voice)
+	if err != nil {
+		return voiceError(loc, err)
+	}
+	if sent.Voice != nil {
+		if err := b.pronunciationRepo.SaveFileID(ctx, text, sent.Voice.FileID); err != nil {
+			logging.FromContext(ctx).Warn("failed to cache pronunciation", "text", text, "error", err)
+		}
+	}
+	return nil
+}
+
+// voiceError explains to the user that their privacy settings block voice messages
+func voiceError(loc locale.Locale, err error) error {
+	if err != nil && strings.Contains(err.Error(), "VOICE_MESSAGES_FORBIDDEN") {
+		return &ValidationError{Message: i18n.T(loc, "pronunciation.forbidden")}
+	}
+	return err
+}
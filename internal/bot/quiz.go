@@ -0,0 +1,1044 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/example/engbot/internal/ai"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/spaced_repetition"
+	wordtest "github.com/example/engbot/internal/testing"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data of /quiz. Every step carries the choices made before it, so the setup
+// needs no conversation state.
+const (
+	callbackQuizPrefix       = "quiz_"
+	callbackQuizMenu         = "quiz_menu"
+	callbackQuizTopicPrefix  = "quiz_topic_"  // quiz_topic_<topic ID, 0 for all words or quizHardWords>
+	callbackQuizCountPrefix  = "quiz_count_"  // quiz_count_<topic ID>_<questions>
+	callbackQuizModePrefix   = "quiz_mode_"   // quiz_mode_<topic ID>_<questions>_<mode>
+	callbackQuizAnswerPrefix = "quiz_answer_" // quiz_answer_<question number>_<option>
+	callbackQuizSkipPrefix   = "quiz_skip_"   // quiz_skip_<question number>
+	callbackQuizTilePrefix   = "quiz_tile_"   // quiz_tile_<question number>_<tile>
+	callbackQuizErasePrefix  = "quiz_erase_"  // quiz_erase_<question number>
+	callbackQuizStop         = "quiz_stop"
+)
+
+// How the questions of a test are delivered
+const (
+	quizModeButtons   = "buttons"
+	quizModePoll      = "poll"
+	quizModeText      = "text"
+	quizModeContext   = "context"
+	quizModeVoice     = "voice"
+	quizModeSynonym   = "synonym"
+	quizModeSpelling  = "spelling"
+	quizModeListening = "listening"
+	quizModeWordOrder = "word_order"
+	quizModeSentence  = "sentence"
+	// Definition tests are answered with the buttons or typed
+	quizModeDefinition     = "definition"
+	quizModeDefinitionText = "definition_text"
+)
+
+// quizHardWords stands in for the topic ID in the callbacks of a hard words drill: all the words,
+// the ones the user finds hard asked far more often
+const quizHardWords int64 = -1
+
+// quizHardestWords stands in for the topic ID in the callbacks of the /hard drill: only the words
+// the user forgets most
+const quizHardestWords int64 = -2
+
+// hardWordDifficulty is the difficulty from which a word counts as hard for the user
+const hardWordDifficulty = 4
+
+// maxQuizVoiceDuration is the longest voice answer transcribed, in seconds
+const maxQuizVoiceDuration = 30
+
+// quizQuestionCounts are the test sizes offered besides all the words
+var quizQuestionCounts = []int{5, 10, 20}
+
+// maxQuizQuestions bounds the size of a test
+const maxQuizQuestions = 50
+
+// maxQuizAge is how long an abandoned test is kept
+const maxQuizAge = 24 * time.Hour
+
+// Telegram limits on polls
+const (
+	maxPollQuestionLength = 300
+	maxPollOptionLength   = 100
+)
+
+// quizSession is a test a user is taking
+type quizSession struct {
+	mu     sync.Mutex // serializes the answers
+	test   *wordtest.Test
+	userID int64 // the user the result is saved for
+	chatID int64
+	loc    locale.Locale // the user's interface language
+	mode   string
+	pollID string // the poll with the current question
+	cram   bool   // a /cram run: the answers leave the word schedules alone
+	// tiles are the tiles of the current spelling or word order question tapped so far,
+	// as indexes into its Tiles
+	tiles []int
+}
+
+// chosen reports whether the answers of the test are chosen with the buttons under the message
+func (s *quizSession) chosen() bool {
+	return s.mode == quizModeButtons || s.mode == quizModeSynonym || s.mode == quizModeListening ||
+		s.mode == quizModeDefinition
+}
+
+// typed reports whether the answers of the test are typed in messages
+func (s *quizSession) typed() bool {
+	return s.mode == quizModeText || s.mode == quizModeContext || s.mode == quizModeSentence ||
+		s.mode == quizModeDefinitionText
+}
+
+// spoken reports whether the answers of the test are voice messages
+func (s *quizSession) spoken() bool {
+	return s.mode == quizModeVoice
+}
+
+// tiled reports whether the answers of the test are put together with the letter or word buttons
+func (s *quizSession) tiled() bool {
+	return s.mode == quizModeSpelling || s.mode == quizModeWordOrder
+}
+
+// quizSessions keeps the tests being taken by Telegram user ID. Updates are handled
+// concurrently, so like userStates it is guarded.
+type quizSessions struct {
+	mu       sync.Mutex
+	sessions map[int64]*quizSession
+	polls    map[string]int64 // poll ID to the Telegram ID of the user taking the test
+}
+
+func newQuizSessions() *quizSessions {
+	return &quizSessions{
+		sessions: make(map[int64]*quizSession),
+		polls:    make(map[string]int64),
+	}
+}
+
+// get returns the user's test, or nil if there is none
+func (q *quizSessions) get(telegramID int64) *quizSession {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.sessions[telegramID]
+}
+
+// put starts the user's test, replacing the one the user was taking, and forgets abandoned tests
+func (q *quizSessions) put(telegramID int64, s *quizSession, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, old := range q.sessions {
+		if now.Sub(old.test.StartedAt) > maxQuizAge {
+			delete(q.sessions, id)
+		}
+	}
+	for pollID, id := range q.polls {
+		if _, ok := q.sessions[id]; !ok || id == telegramID {
+			delete(q.polls, pollID)
+		}
+	}
+	q.sessions[telegramID] = s
+}
+
+// remove ends the user's test if it is still s
+func (q *quizSessions) remove(telegramID int64, s *quizSession) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.sessions[telegramID] == s {
+		delete(q.sessions, telegramID)
+	}
+	delete(q.polls, s.pollID)
+}
+
+// trackPoll remembers whose question the poll is
+func (q *quizSessions) trackPoll(pollID string, telegramID int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.polls[pollID] = telegramID
+}
+
+// takePoll returns the user the poll was sent to and forgets the poll
+func (q *quizSessions) takePoll(pollID string) (int64, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	telegramID, ok := q.polls[pollID]
+	delete(q.polls, pollID)
+	return telegramID, ok
+}
+
+// handleQuizCommand handles /quiz: it starts choosing the words for a test
+func (b *Bot) handleQuizCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	text, buttons, err := b.quizTopicsScreen(ctx, user)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	if buttons != nil {
+		msg.ReplyMarkup = createKeyboard(buttons)
+	}
+	return b.sendMessage(msg)
+}
+
+// quizTopicsScreen offers the topics with words to test, and all the words at once
+func (b *Bot) quizTopicsScreen(ctx context.Context, user *models.User) (string, [][]MenuButton, error) {
+	loc := b.userLocale(user)
+	words, err := b.wordRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(words) < 2 {
+		return i18n.T(loc, "quiz.need_words"), nil, nil
+	}
+
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	counts := make(map[int64]int)
+	hard := 0
+	for _, w := range words {
+		counts[w.TopicID]++
+		if w.Difficulty >= hardWordDifficulty {
+			hard++
+		}
+	}
+
+	buttons := [][]MenuButton{{{
+		Text:         i18n.T(loc, "quiz.all_words", len(words)),
+		CallbackData: callbackQuizTopicPrefix + "0",
+	}}}
+	if hard > 0 {
+		buttons = append(buttons, []MenuButton{{
+			Text:         i18n.T(loc, "quiz.hard_words", hard),
+			CallbackData: fmt.Sprintf("%s%d", callbackQuizTopicPrefix, quizHardWords),
+		}})
+	}
+	for _, t := range topics {
+		if t.Archived || counts[t.ID] == 0 {
+			continue
+		}
+		buttons = append(buttons, []MenuButton{{
+			Text:         fmt.Sprintf("📁 %s (%d)", t.Name, counts[t.ID]),
+			CallbackData: fmt.Sprintf("%s%d", callbackQuizTopicPrefix, t.ID),
+		}})
+	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "button.main_menu"), CallbackData: "main_menu"}})
+	text := i18n.T(loc, "quiz.title")
+	if hard > 0 {
+		text += i18n.T(loc, "quiz.hard_hint")
+	}
+	return text, buttons, nil
+}
+
+// handleQuizMenu shows the topics to test in place of the current message
+func (b *Bot) handleQuizMenu(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	text, buttons, err := b.quizTopicsScreen(ctx, user)
+	if err != nil {
+		return err
+	}
+	if buttons == nil {
+		buttons = b.mainMenuButtons(b.userLocale(user))
+	}
+	msg := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, createKeyboard(buttons))
+	return b.editMessage(msg)
+}
+
+// handleQuizCallback handles the buttons of /quiz: the setup steps, the answers and stopping the test
+func (b *Bot) handleQuizCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	data := callback.Data
+	switch {
+	case data == callbackQuizMenu:
+		return b.handleQuizMenu(ctx, callback)
+	case data == callbackQuizStop:
+		return b.handleQuizStop(ctx, callback)
+	case strings.HasPrefix(data, callbackQuizAnswerPrefix):
+		args, ok := quizCallbackArgs(data, callbackQuizAnswerPrefix, 2)
+		if !ok {
+			break
+		}
+		return b.handleQuizAnswer(ctx, callback, int(args[0]), int(args[1]))
+	case strings.HasPrefix(data, callbackQuizSkipPrefix):
+		args, ok := quizCallbackArgs(data, callbackQuizSkipPrefix, 1)
+		if !ok {
+			break
+		}
+		return b.handleQuizSkip(ctx, callback, int(args[0]))
+	case strings.HasPrefix(data, callbackQuizTilePrefix):
+		args, ok := quizCallbackArgs(data, callbackQuizTilePrefix, 2)
+		if !ok {
+			break
+		}
+		return b.handleQuizTile(ctx, callback, int(args[0]), int(args[1]))
+	case strings.HasPrefix(data, callbackQuizErasePrefix):
+		args, ok := quizCallbackArgs(data, callbackQuizErasePrefix, 1)
+		if !ok {
+			break
+		}
+		return b.handleQuizErase(callback, int(args[0]))
+	case strings.HasPrefix(data, callbackQuizTopicPrefix):
+		args, ok := quizCallbackArgs(data, callbackQuizTopicPrefix, 1)
+		if !ok {
+			break
+		}
+		return b.handleQuizTopic(ctx, callback, args[0])
+	case strings.HasPrefix(data, callbackQuizCountPrefix):
+		args, ok := quizCallbackArgs(data, callbackQuizCountPrefix, 2)
+		if !ok {
+			break
+		}
+		return b.handleQuizCount(ctx, callback, args[0], int(args[1]))
+	case strings.HasPrefix(data, callbackQuizModePrefix):
+		// Mode names may have "_" in them, like word_order
+		parts := strings.SplitN(strings.TrimPrefix(data, callbackQuizModePrefix), "_", 3)
+		if len(parts) != 3 {
+			break
+		}
+		args, ok := quizCallbackArgs(parts[0]+"_"+parts[1], "", 2)
+		if !ok {
+			break
+		}
+		return b.handleQuizStart(ctx, callback, args[0], int(args[1]), parts[2])
+	}
+	return &ValidationError{Message: i18n.T(b.localeFor(ctx, callback.From.ID), "stale.quiz")}
+}
+
+// quizCallbackArgs parses the n numbers after the prefix, separated by "_"
+func quizCallbackArgs(data, prefix string, n int) ([]int64, bool) {
+	parts := strings.Split(strings.TrimPrefix(data, prefix), "_")
+	if len(parts) != n {
+		return nil, false
+	}
+	args := make([]int64, n)
+	for i, part := range parts {
+		arg, err := strconv.ParseInt(part, 10, 64)
+		// IDs and numbers are never negative, quizHardWords and quizHardestWords are the exceptions
+		if err != nil || arg < quizHardestWords {
+			return nil, false
+		}
+		args[i] = arg
+	}
+	return args, true
+}
+
+// handleQuizTopic asks how many questions the test should have
+func (b *Bot) handleQuizTopic(ctx context.Context, callback *tgbotapi.CallbackQuery, topicID int64) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	all, err := b.wordRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	words := topicWords(all, topicID)
+	if len(words) == 0 {
+		return &ValidationError{Message: i18n.T(loc, "quiz.topic_empty")}
+	}
+
+	var row []MenuButton
+	for _, count := range quizQuestionCounts {
+		if count < len(words) {
+			row = append(row, MenuButton{Text: strconv.Itoa(count), CallbackData: fmt.Sprintf("%s%d_%d", callbackQuizCountPrefix, topicID, count)})
+		}
+	}
+	most := min(len(words), maxQuizQuestions)
+	mostText := i18n.T(loc, "quiz.count_all", most)
+	if most < len(words) {
+		mostText = strconv.Itoa(most)
+	}
+	row = append(row, MenuButton{Text: mostText, CallbackData: fmt.Sprintf("%s%d_%d", callbackQuizCountPrefix, topicID, most)})
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		i18n.T(loc, "quiz.ask_count"),
+		createKeyboard([][]MenuButton{row, {{Text: i18n.T(loc, "button.back"), CallbackData: callbackQuizMenu}}}),
+	)
+	return b.editMessage(msg)
+}
+
+// handleQuizCount asks how to answer the questions
+func (b *Bot) handleQuizCount(ctx context.Context, callback *tgbotapi.CallbackQuery, topicID int64, count int) error {
+	loc := b.localeFor(ctx, callback.From.ID)
+	mode := func(mode string) string {
+		return fmt.Sprintf("%s%d_%d_%s", callbackQuizModePrefix, topicID, count, mode)
+	}
+	text := i18n.T(loc, "quiz.modes")
+	buttons := [][]MenuButton{
+		{{Text: i18n.T(loc, "quiz.mode_buttons"), CallbackData: mode(quizModeButtons)}},
+		{{Text: i18n.T(loc, "quiz.mode_poll"), CallbackData: mode(quizModePoll)}},
+		{{Text: i18n.T(loc, "quiz.mode_text"), CallbackData: mode(quizModeText)}},
+		{{Text: i18n.T(loc, "quiz.mode_context"), CallbackData: mode(quizModeContext)}},
+		{{Text: i18n.T(loc, "quiz.mode_synonym"), CallbackData: mode(quizModeSynonym)}},
+		{{Text: i18n.T(loc, "quiz.mode_spelling"), CallbackData: mode(quizModeSpelling)}},
+		{{Text: i18n.T(loc, "quiz.mode_word_order"), CallbackData: mode(quizModeWordOrder)}},
+	}
+	if b.transcriber != nil {
+		text += i18n.T(loc, "quiz.modes_voice")
+		buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "quiz.mode_voice"), CallbackData: mode(quizModeVoice)}})
+	}
+	if b.speech != nil {
+		text += i18n.T(loc, "quiz.modes_listening")
+		buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "quiz.mode_listening"), CallbackData: mode(quizModeListening)}})
+	}
+	if b.languageModel != nil {
+		text += i18n.T(loc, "quiz.modes_sentence")
+		buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "quiz.mode_sentence"), CallbackData: mode(quizModeSentence)}})
+		text += i18n.T(loc, "quiz.modes_definition")
+		buttons = append(buttons, []MenuButton{
+			{Text: i18n.T(loc, "quiz.mode_definition"), CallbackData: mode(quizModeDefinition)},
+			{Text: i18n.T(loc, "quiz.mode_definition_text"), CallbackData: mode(quizModeDefinitionText)},
+		})
+	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "button.back"), CallbackData: fmt.Sprintf("%s%d", callbackQuizTopicPrefix, topicID)}})
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, createKeyboard(buttons))
+	return b.editMessage(msg)
+}
+
+// handleQuizStart creates the test and asks the first question
+func (b *Bot) handleQuizStart(ctx context.Context, callback *tgbotapi.CallbackQuery, topicID int64, count int, mode string) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	testType := wordtest.MultipleChoice
+	switch mode {
+	case quizModeButtons, quizModePoll:
+	case quizModeText:
+		testType = wordtest.TextInput
+	case quizModeContext:
+		testType = wordtest.Context
+	case quizModeSynonym:
+		testType = wordtest.Synonym
+	case quizModeSpelling:
+		testType = wordtest.Spelling
+	case quizModeWordOrder:
+		testType = wordtest.WordOrder
+	case quizModeVoice:
+		if b.transcriber == nil {
+			return &ValidationError{Message: i18n.T(loc, "quiz.voice_off")}
+		}
+		testType = wordtest.Pronunciation
+	case quizModeListening:
+		if b.speech == nil {
+			return &ValidationError{Message: i18n.T(loc, "quiz.listening_off")}
+		}
+		testType = wordtest.Listening
+	case quizModeSentence:
+		if b.languageModel == nil {
+			return &ValidationError{Message: i18n.T(loc, "quiz.sentence_off")}
+		}
+		testType = wordtest.Sentence
+	case quizModeDefinition, quizModeDefinitionText:
+		if b.languageModel == nil {
+			return &ValidationError{Message: i18n.T(loc, "quiz.definition_off")}
+		}
+		testType = wordtest.Definition
+	default:
+		return &ValidationError{Message: i18n.T(loc, "stale.quiz")}
+	}
+	all, err := b.wordRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	words := topicWords(all, topicID)
+	if topicID == quizHardestWords {
+		hard, err := b.hardestWords(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		words = make([]models.Word, len(hard))
+		for i, w := range hard {
+			words[i] = w.Word
+		}
+	}
+	opts := wordtest.Options{
+		Type:        testType,
+		Count:       min(count, maxQuizQuestions),
+		Distractors: all,
+		HardFirst:   topicID == quizHardWords,
+	}
+	if testType == wordtest.Definition {
+		// Definitions are written the first time a word is asked, which takes a while
+		wait := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, i18n.T(loc, "quiz.preparing_definitions"))
+		if err := b.editMessage(wait); err != nil {
+			return err
+		}
+		opts.Define = func(word models.Word) (string, error) {
+			return b.defineWord(ctx, word)
+		}
+	}
+	now := b.clock.Now()
+	test, err := wordtest.CreateTest(words, opts, rand.New(rand.NewSource(now.UnixNano())))
+	if errors.Is(err, wordtest.ErrNotEnoughWords) && testType == wordtest.Context {
+		return &ValidationError{Message: i18n.T(loc, "quiz.no_context")}
+	}
+	if errors.Is(err, wordtest.ErrNotEnoughWords) && testType == wordtest.Synonym {
+		return &ValidationError{Message: i18n.T(loc, "quiz.no_synonyms")}
+	}
+	if errors.Is(err, wordtest.ErrNotEnoughWords) && testType == wordtest.Spelling {
+		return &ValidationError{Message: i18n.T(loc, "quiz.no_spelling", wordtest.MaxSpellingLetters)}
+	}
+	if errors.Is(err, wordtest.ErrNotEnoughWords) && testType == wordtest.WordOrder {
+		return &ValidationError{Message: i18n.T(loc, "quiz.no_word_order", wordtest.MinWordOrderWords, wordtest.MaxWordOrderWords)}
+	}
+	if errors.Is(err, wordtest.ErrNotEnoughWords) && testType == wordtest.Definition {
+		return &ValidationError{Message: i18n.T(loc, "quiz.no_definitions")}
+	}
+	if errors.Is(err, wordtest.ErrNotEnoughWords) {
+		return &ValidationError{Message: i18n.T(loc, "quiz.not_enough")}
+	}
+	if err != nil {
+		return err
+	}
+	test.StartedAt = now
+
+	s := &quizSession{test: test, userID: user.ID, chatID: callback.Message.Chat.ID, loc: loc, mode: mode}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b.quizzes.put(callback.From.ID, s, now)
+	logging.FromContext(ctx).Info("quiz started", "user_id", user.ID, "questions", len(test.Questions), "mode", mode)
+
+	if mode == quizModePoll {
+		text := i18n.T(loc, "quiz.poll_started", len(test.Questions), i18n.Plural(loc, "plural.question", len(test.Questions)))
+		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+		if err := b.editMessage(edit); err != nil {
+			return err
+		}
+		return b.sendQuizPoll(ctx, callback.From.ID, s)
+	}
+	if mode == quizModeListening {
+		text := i18n.T(loc, "quiz.listening_started", len(test.Questions), i18n.Plural(loc, "plural.question", len(test.Questions)))
+		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+		if err := b.editMessage(edit); err != nil {
+			return err
+		}
+		return b.sendListeningQuestion(ctx, s)
+	}
+	if s.typed() || s.spoken() || s.tiled() {
+		if !s.tiled() {
+			// The answers come in messages, so no other conversation should take them
+			b.states.remove(callback.From.ID)
+		}
+		msg := tgbotapi.NewEditMessageTextAndMarkup(
+			callback.Message.Chat.ID,
+			callback.Message.MessageID,
+			quizTextQuestionText(loc, test),
+			createKeyboard(quizTextButtons(loc, test)),
+		)
+		return b.editMessage(msg)
+	}
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		quizQuestionText(loc, test),
+		createKeyboard(quizAnswerButtons(loc, test)),
+	)
+	return b.editMessage(msg)
+}
+
+// handleQuizAnswer checks the option chosen with the buttons and asks the next question in place
+func (b *Bot) handleQuizAnswer(ctx context.Context, callback *tgbotapi.CallbackQuery, number, option int) error {
+	s := b.quizzes.get(callback.From.ID)
+	if s == nil || !s.chosen() {
+		return &ValidationError{Message: i18n.T(b.localeFor(ctx, callback.From.ID), "quiz.finished")}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A second tap on the same question changes nothing
+	if s.test.Done() || s.test.Number() != number {
+		return nil
+	}
+
+	q := s.test.Current()
+	text := quizFeedbackText(s.loc, q.Word, s.test.Choose(option))
+	if s.test.Type == wordtest.Synonym {
+		text += "\n" + i18n.T(s.loc, "word.synonyms", q.Word.Synonyms)
+	}
+	if s.test.Type == wordtest.Definition {
+		text += "\n📘 " + q.Word.Definition
+	}
+	if q.Sentence != "" {
+		text += "\n📖 " + q.Sentence
+	}
+	if s.test.Done() {
+		summary, err := b.finishQuiz(ctx, callback.From.ID, s)
+		if err != nil {
+			return err
+		}
+		msg := tgbotapi.NewEditMessageTextAndMarkup(
+			callback.Message.Chat.ID,
+			callback.Message.MessageID,
+			text+"\n\n"+summary,
+			createKeyboard(b.mainMenuButtons(s.loc)),
+		)
+		return b.editMessage(msg)
+	}
+	if s.mode == quizModeListening {
+		// The next question has to come after its clip, so it takes a new message
+		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+		if err := b.editMessage(edit); err != nil {
+			return err
+		}
+		return b.sendListeningQuestion(ctx, s)
+	}
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		text+"\n\n"+quizQuestionText(s.loc, s.test),
+		createKeyboard(quizAnswerButtons(s.loc, s.test)),
+	)
+	return b.editMessage(msg)
+}
+
+// handleQuizTextAnswer grades the translation the user typed and asks the next question
+func (b *Bot) handleQuizTextAnswer(ctx context.Context, message *tgbotapi.Message) error {
+	s := b.quizzes.get(message.From.ID)
+	if s == nil || !s.typed() {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.test.Done() {
+		return nil
+	}
+
+	text, buttons, err := b.answerQuizText(ctx, message.From.ID, s, message.Text)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyMarkup = createKeyboard(buttons)
+	return b.sendMessage(msg)
+}
+
+// handleQuizVoiceAnswer transcribes the voice message, grades it as the answer to the
+// pronunciation question and asks the next one
+func (b *Bot) handleQuizVoiceAnswer(ctx context.Context, message *tgbotapi.Message) error {
+	s := b.quizzes.get(message.From.ID)
+	if s == nil || !s.spoken() {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(b.localeFor(ctx, message.From.ID), "quiz.voice_only")))
+	}
+	if message.Voice.Duration > maxQuizVoiceDuration {
+		return &ValidationError{Message: i18n.T(s.loc, "quiz.voice_too_long", maxQuizVoiceDuration)}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.test.Done() {
+		return nil
+	}
+
+	audio, err := b.downloadFile(ctx, s.loc, message.Voice.FileID)
+	if err != nil {
+		return err
+	}
+	transcript, err := b.transcriber.Transcribe(ctx, audio, "answer.ogg")
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to transcribe voice answer", "error", err)
+		return &ValidationError{Message: i18n.T(s.loc, "quiz.transcribe_failed")}
+	}
+	if transcript == "" {
+		return &ValidationError{Message: i18n.T(s.loc, "quiz.silence")}
+	}
+
+	text, buttons, err := b.answerQuizText(ctx, message.From.ID, s, transcript)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(s.loc, "quiz.recognized", transcript, text))
+	msg.ReplyMarkup = createKeyboard(buttons)
+	return b.sendMessage(msg)
+}
+
+// handleQuizSkip gives up on the typed question, showing the translation in place of the question
+func (b *Bot) handleQuizSkip(ctx context.Context, callback *tgbotapi.CallbackQuery, number int) error {
+	s := b.quizzes.get(callback.From.ID)
+	if s == nil || !(s.typed() || s.spoken() || s.tiled()) {
+		return &ValidationError{Message: i18n.T(b.localeFor(ctx, callback.From.ID), "quiz.finished")}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.test.Done() || s.test.Number() != number {
+		return nil
+	}
+
+	text, buttons, err := b.answerQuizText(ctx, callback.From.ID, s, "")
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, createKeyboard(buttons))
+	return b.editMessage(msg)
+}
+
+// answerQuizText grades the typed answer, feeds the grade into the word's SM-2 progress unless
+// cramming and returns the feedback with the next question, or with the summary when the test
+// is over. Putting a sentence in order practices the sentence more than recalling the word,
+// so word order answers leave the schedule alone. Written sentences are graded by the language
+// model. The caller holds s.mu.
+func (b *Bot) answerQuizText(ctx context.Context, telegramID int64, s *quizSession, answer string) (string, [][]MenuButton, error) {
+	q := *s.test.Current()
+	var grade wordtest.Grade
+	var check *ai.SentenceCheck
+	if s.test.Type == wordtest.Sentence && strings.TrimSpace(answer) != "" {
+		var err error
+		if check, err = b.languageModel.CheckSentence(ctx, q.Word.Word, q.Word.Translation, answer); err != nil {
+			logging.FromContext(ctx).Error("failed to check sentence", "user_id", s.userID, "error", err)
+			return "", nil, &ValidationError{Message: i18n.T(s.loc, "quiz.check_failed")}
+		}
+		grade = s.test.Record(wordtest.GradeSentence(answer, check.UsesWord, check.RightMeaning, len(check.Mistakes)))
+	} else {
+		grade = s.test.Answer(answer)
+	}
+	s.tiles = nil
+	if !s.cram && s.test.Type != wordtest.WordOrder {
+		if err := b.gradeWord(ctx, s.userID, q.Word.ID, grade.Quality); err != nil {
+			return "", nil, err
+		}
+	}
+
+	text := quizGradeText(s.loc, s.test.Type, q, grade)
+	if check != nil {
+		text += sentenceCheckText(s.loc, check, answer)
+	}
+	if !s.test.Done() {
+		return text + "\n\n" + quizTextQuestionText(s.loc, s.test), quizTextButtons(s.loc, s.test), nil
+	}
+	summary, err := b.finishQuiz(ctx, telegramID, s)
+	if err != nil {
+		return "", nil, err
+	}
+	return text + "\n\n" + summary, b.mainMenuButtons(s.loc), nil
+}
+
+// gradeWord runs the answer through SM-2 like a rating of the word's forward card. Words from
+// before the flashcard review have no progress yet and get it now.
+func (b *Bot) gradeWord(ctx context.Context, userID int64, wordID int, quality spaced_repetition.QualityResponse) error {
+	progress, err := b.progressRepo.GetByUserAndWord(userID, wordID, models.DirectionForward)
+	if errors.Is(err, sql.ErrNoRows) {
+		progress, err = &models.UserProgress{UserID: userID, WordID: wordID, EasinessFactor: 2.5}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sm2, err := b.repetitions.SM2For(ctx, userID)
+	if err != nil {
+		return err
+	}
+	sm2.Process(progress, quality)
+	progress.IsLearned = sm2.IsWordMastered(progress)
+	if err := b.progressRepo.CreateOrUpdate(progress); err != nil {
+		return err
+	}
+	b.recordReview(ctx, userID)
+	return nil
+}
+
+// handleQuizPollAnswer takes the answer to a poll question and sends the next one.
+// Errors are reported in the chat, there is no message to answer.
+func (b *Bot) handleQuizPollAnswer(ctx context.Context, answer *tgbotapi.PollAnswer) error {
+	if len(answer.OptionIDs) == 0 {
+		return nil
+	}
+	telegramID, ok := b.quizzes.takePoll(answer.PollID)
+	if !ok {
+		return nil
+	}
+	s := b.quizzes.get(telegramID)
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pollID != answer.PollID || s.test.Done() {
+		return nil
+	}
+
+	s.test.Choose(answer.OptionIDs[0])
+	var err error
+	if s.test.Done() {
+		var summary string
+		if summary, err = b.finishQuiz(ctx, telegramID, s); err == nil {
+			return b.sendMessage(tgbotapi.NewMessage(s.chatID, summary))
+		}
+	} else {
+		err = b.sendQuizPoll(ctx, telegramID, s)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to continue quiz", "user_id", s.userID, "error", err)
+		return b.sendMessage(tgbotapi.NewMessage(s.chatID, userErrorMessage(s.loc, err)))
+	}
+	return nil
+}
+
+// handleQuizStop ends the test early, saving the questions answered so far
+func (b *Bot) handleQuizStop(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	s := b.quizzes.get(callback.From.ID)
+	if s == nil {
+		return &ValidationError{Message: i18n.T(b.localeFor(ctx, callback.From.ID), "quiz.finished")}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary, err := b.finishQuiz(ctx, callback.From.ID, s)
+	if err != nil {
+		return err
+	}
+	if s.mode == quizModePoll {
+		return b.sendMessage(tgbotapi.NewMessage(s.chatID, summary))
+	}
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		summary,
+		createKeyboard(b.mainMenuButtons(s.loc)),
+	)
+	return b.editMessage(msg)
+}
+
+// sendQuizPoll sends the current question as a Telegram quiz poll. The caller holds s.mu.
+func (b *Bot) sendQuizPoll(ctx context.Context, telegramID int64, s *quizSession) error {
+	q := s.test.Current()
+	options := make([]string, len(q.Options))
+	for i, option := range q.Options {
+		options[i] = truncateRunes(option, maxPollOptionLength)
+	}
+	loc := s.loc
+	question := i18n.T(loc, "quiz.poll_question", s.test.Number(), len(s.test.Questions), q.Word.Word)
+
+	poll := tgbotapi.NewPoll(s.chatID, truncateRunes(question, maxPollQuestionLength), options...)
+	poll.Type = "quiz"
+	poll.IsAnonymous = false
+	poll.CorrectOptionID = int64(q.Correct)
+	poll.ReplyMarkup = createKeyboard([][]MenuButton{{{Text: i18n.T(loc, "quiz.stop"), CallbackData: callbackQuizStop}}})
+
+	sent, err := b.dispatcher.Send(ctx, s.chatID, poll)
+	if err != nil {
+		return fmt.Errorf("failed to send quiz poll: %w", err)
+	}
+	if sent.Poll == nil {
+		return fmt.Errorf("failed to send quiz poll: no poll in the sent message")
+	}
+	s.pollID = sent.Poll.ID
+	b.quizzes.trackPoll(sent.Poll.ID, telegramID)
+	return nil
+}
+
+// sendListeningQuestion plays the current question as a voice message with no caption, so the
+// text doesn't give the answer away, and asks it with the answer buttons. The caller holds s.mu.
+func (b *Bot) sendListeningQuestion(ctx context.Context, s *quizSession) error {
+	if err := b.sendSpeech(ctx, s.loc, s.chatID, s.test.Current().Audio, ""); err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(s.chatID, quizQuestionText(s.loc, s.test))
+	msg.ReplyMarkup = createKeyboard(quizAnswerButtons(s.loc, s.test))
+	return b.sendMessage(msg)
+}
+
+// finishQuiz ends the test, saves the result if anything was answered and sums it up.
+// The caller holds s.mu.
+func (b *Bot) finishQuiz(ctx context.Context, telegramID int64, s *quizSession) (string, error) {
+	b.quizzes.remove(telegramID, s)
+	if len(s.test.Answers) == 0 {
+		return i18n.T(s.loc, "quiz.stopped"), nil
+	}
+
+	result := s.test.Result(s.userID, b.clock.Now())
+	if s.cram {
+		result.TestType = cramTestType
+	}
+	if err := b.testResultRepo.SaveTestResult(ctx, &result); err != nil {
+		return "", err
+	}
+	return quizSummaryText(s.loc, result, s.test.Mistakes()), nil
+}
+
+// quizQuestionText asks the current multiple choice, synonym, listening or definition question
+func quizQuestionText(loc locale.Locale, test *wordtest.Test) string {
+	question := i18n.T(loc, "quiz.question", test.Number(), len(test.Questions))
+	switch {
+	case test.Type == wordtest.Definition:
+		return question + i18n.T(loc, "quiz.ask_definition", test.Current().Definition)
+	case test.Type == wordtest.Synonym:
+		return question + i18n.T(loc, "quiz.ask_synonym", test.Current().Word.Word)
+	case test.Type == wordtest.Listening && test.Current().Sentence != "":
+		return question + i18n.T(loc, "quiz.ask_heard_phrase")
+	case test.Type == wordtest.Listening:
+		return question + i18n.T(loc, "quiz.ask_heard_word")
+	}
+	return question + i18n.T(loc, "quiz.ask_translation", test.Current().Word.Word)
+}
+
+// quizTextQuestionText asks to type the translation of the current word, the word missing
+// from the example for context questions, to say the word for pronunciation questions,
+// to spell it for spelling ones, to put the sentence with it in order for word order ones,
+// to write a sentence with it for sentence ones or to type it by its definition
+func quizTextQuestionText(loc locale.Locale, test *wordtest.Test) string {
+	q := test.Current()
+	question := i18n.T(loc, "quiz.question", test.Number(), len(test.Questions))
+	switch test.Type {
+	case wordtest.Spelling, wordtest.WordOrder:
+		return quizTilesText(loc, test, nil)
+	case wordtest.Context:
+		return question + i18n.T(loc, "quiz.ask_cloze", q.Word.Translation, q.Cloze)
+	case wordtest.Pronunciation:
+		return question + i18n.T(loc, "quiz.ask_say", q.Word.Translation)
+	case wordtest.Sentence:
+		return question + i18n.T(loc, "quiz.ask_sentence", q.Word.Word, q.Word.Translation)
+	case wordtest.Definition:
+		return question + i18n.T(loc, "quiz.ask_definition_text", q.Definition)
+	}
+	return question + i18n.T(loc, "quiz.ask_typed", q.Word.Word)
+}
+
+// quizTextButtons returns the buttons under a typed question, the tiles under a spelling or
+// word order one
+func quizTextButtons(loc locale.Locale, test *wordtest.Test) [][]MenuButton {
+	if test.Type == wordtest.Spelling || test.Type == wordtest.WordOrder {
+		return quizTileButtons(loc, test, nil)
+	}
+	return [][]MenuButton{
+		{{Text: i18n.T(loc, "quiz.skip"), CallbackData: fmt.Sprintf("%s%d", callbackQuizSkipPrefix, test.Number())}},
+		{{Text: i18n.T(loc, "quiz.stop"), CallbackData: callbackQuizStop}},
+	}
+}
+
+// quizAnswerButtons returns a button per option of the current question and the stop button
+func quizAnswerButtons(loc locale.Locale, test *wordtest.Test) [][]MenuButton {
+	var buttons [][]MenuButton
+	for i, option := range test.Current().Options {
+		buttons = append(buttons, []MenuButton{{
+			Text:         option,
+			CallbackData: fmt.Sprintf("%s%d_%d", callbackQuizAnswerPrefix, test.Number(), i),
+		}})
+	}
+	buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "quiz.stop"), CallbackData: callbackQuizStop}})
+	return buttons
+}
+
+// quizFeedbackText tells whether the answer was right, with the right translation
+func quizFeedbackText(loc locale.Locale, word models.Word, correct bool) string {
+	if correct {
+		return i18n.T(loc, "quiz.right", word.Word, word.Translation)
+	}
+	return i18n.T(loc, "quiz.wrong", word.Word, word.Translation)
+}
+
+// quizGradeText tells how the typed or spoken answer was graded, with the whole example
+// for context and word order questions
+func quizGradeText(loc locale.Locale, testType wordtest.TestType, q wordtest.Question, grade wordtest.Grade) string {
+	var text string
+	switch grade.Verdict {
+	case wordtest.Correct:
+		text = quizFeedbackText(loc, q.Word, true)
+	case wordtest.Typo:
+		switch testType {
+		case wordtest.Spelling:
+			text = i18n.T(loc, "quiz.almost_letters", grade.InPlace, len(q.Tiles), grade.Expected)
+		case wordtest.WordOrder:
+			text = i18n.T(loc, "quiz.almost_words", grade.InPlace, len(q.Tiles))
+		case wordtest.Pronunciation:
+			text = i18n.T(loc, "quiz.unclear", grade.Expected)
+		case wordtest.Sentence:
+			text = i18n.T(loc, "quiz.with_mistakes", q.Word.Word, q.Word.Translation)
+		default:
+			text = i18n.T(loc, "quiz.typo", grade.Expected)
+		}
+	default:
+		text = quizFeedbackText(loc, q.Word, false)
+		switch {
+		case grade.InPlace > 0 && testType == wordtest.WordOrder:
+			text += i18n.T(loc, "quiz.words_in_place", grade.InPlace, len(q.Tiles))
+		case grade.InPlace > 0:
+			text += i18n.T(loc, "quiz.letters_in_place", grade.InPlace, len(q.Tiles))
+		}
+	}
+	if q.Sentence != "" {
+		text += "\n📖 " + q.Sentence
+	}
+	return text
+}
+
+// quizSummaryText sums up the test with the words to repeat
+func quizSummaryText(loc locale.Locale, result models.TestResult, mistakes []models.Word) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "quiz.summary"))
+	text.WriteString(i18n.T(loc, "quiz.summary_correct",
+		result.CorrectWords, result.TotalWords, result.CorrectWords*100/result.TotalWords))
+	text.WriteString(i18n.T(loc, "quiz.summary_time", quizDurationText(loc, result.Duration)))
+	if len(mistakes) > 0 {
+		text.WriteString(i18n.T(loc, "quiz.summary_mistakes"))
+		for _, w := range mistakes {
+			text.WriteString(fmt.Sprintf("• %s - %s\n", w.Word, w.Translation))
+		}
+	}
+	if result.TestType == cramTestType {
+		text.WriteString(i18n.T(loc, "quiz.summary_cram"))
+	} else {
+		text.WriteString(i18n.T(loc, "quiz.summary_again"))
+	}
+	return text.String()
+}
+
+// quizDurationText formats the duration of a test in minutes and seconds
+func quizDurationText(loc locale.Locale, seconds int) string {
+	if seconds < 60 {
+		return i18n.T(loc, "quiz.seconds", seconds)
+	}
+	return i18n.T(loc, "quiz.minutes", seconds/60, seconds%60)
+}
+
+// topicWords returns the words of the topic, all the words for topic 0 and quizHardWords
+func topicWords(words []models.Word, topicID int64) []models.Word {
+	if topicID == 0 || topicID == quizHardWords {
+		return words
+	}
+	var result []models.Word
+	for _, w := range words {
+		if w.TopicID == topicID {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+// truncateRunes cuts s to at most n runes, marking the cut with an ellipsis
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
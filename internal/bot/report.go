@@ -0,0 +1,199 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// reportTopics is how many of the best and of the worst topics the weekly report lists
+const reportTopics = 3
+
+// reportDays are the names of the days of the week /report accepts, by time.Weekday
+var reportDays = map[string]time.Weekday{
+	"пн": time.Monday, "вт": time.Tuesday, "ср": time.Wednesday, "чт": time.Thursday,
+	"пт": time.Friday, "сб": time.Saturday, "вс": time.Sunday,
+	"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+}
+
+// reportDayName describes when the report comes on the day; the catalog lists the days from
+// Sunday, separated by "|"
+func reportDayName(loc locale.Locale, day int) string {
+	names := strings.Split(i18n.T(loc, "report.days"), "|")
+	return names[day%len(names)]
+}
+
+// handleReportCommand handles /report: without arguments it shows the report settings,
+// "/report now" sends the report of the last week right away, "/report on|off" turns the weekly
+// report on or off and "/report <день> <час>" picks when it comes
+func (b *Bot) handleReportCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	args := strings.Fields(strings.ToLower(message.CommandArguments()))
+	switch {
+	case len(args) == 0:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, reportStatusText(loc, user)))
+	case len(args) == 1 && args[0] == "now":
+		return b.sendWeeklyReport(ctx, message.Chat.ID, user, false)
+	case len(args) == 1 && (args[0] == "on" || args[0] == "off"):
+		user.ReportEnabled = args[0] == "on"
+	case len(args) == 2:
+		day, ok := reportDays[args[0]]
+		hour, err := strconv.Atoi(args[1])
+		if !ok || err != nil || hour < 0 || hour > 23 {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "report.usage")))
+		}
+		user.ReportEnabled = true
+		user.ReportDay = int(day)
+		user.ReportHour = hour
+	default:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "report.usage")))
+	}
+
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "report.saved")+reportStatusText(loc, user)))
+}
+
+// reportStatusText describes when the user gets the weekly report
+func reportStatusText(loc locale.Locale, user *models.User) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "report.title"))
+	if user.ReportEnabled {
+		text.WriteString(i18n.T(loc, "report.on", reportDayName(loc, user.ReportDay), user.ReportHour))
+	} else {
+		text.WriteString(i18n.T(loc, "report.off"))
+	}
+	text.WriteString(i18n.T(loc, "report.hint"))
+	return text.String()
+}
+
+// SendWeeklyReport sends the user the report of the last week. Users who did nothing in the last
+// two weeks don't get it. It implements the scheduler.Notifier interface.
+func (b *Bot) SendWeeklyReport(ctx context.Context, telegramID int64) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil || !user.ReportEnabled {
+		return err
+	}
+	return b.notifyOnce(ctx, user, database.NotificationWeeklyReport, func() error {
+		return b.sendWeeklyReport(ctx, telegramID, user, true)
+	})
+}
+
+// sendWeeklyReport sends the report of the seven days ending today. The scheduled report is
+// skipped when there is nothing to report.
+func (b *Bot) sendWeeklyReport(ctx context.Context, chatID int64, user *models.User, scheduled bool) error {
+	end := startOfDay(b.clock.Now()).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -7)
+
+	week, err := b.statsRepo.GetPeriodSummary(ctx, user.ID, start, end)
+	if err != nil {
+		return err
+	}
+	previous, err := b.statsRepo.GetPeriodSummary(ctx, user.ID, start.AddDate(0, 0, -7), start)
+	if err != nil {
+		return err
+	}
+	if scheduled && *week == (database.PeriodSummary{}) && *previous == (database.PeriodSummary{}) {
+		return nil
+	}
+	results, err := b.statsRepo.GetTopicResults(ctx, user.ID, start, end)
+	if err != nil {
+		return err
+	}
+	streak, err := b.activityRepo.GetStreak(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	text := weeklyReportText(loc, start, end.AddDate(0, 0, -1), week, previous, streak, results)
+	if scheduled {
+		text += i18n.T(loc, "report.settings")
+	}
+	return b.sendMessage(tgbotapi.NewMessage(chatID, text))
+}
+
+// weeklyReportText renders the report of the week from first to last day compared with the week before
+func weeklyReportText(loc locale.Locale, first, last time.Time, week, previous *database.PeriodSummary, streak int, results []database.TopicResult) string {
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "report.week", first.Format("02.01"), last.Format("02.01")))
+	text.WriteString(i18n.T(loc, "report.completed", week.Completed, weekDelta(week.Completed, previous.Completed)))
+	text.WriteString(i18n.T(loc, "report.reviews", week.Reviews, weekDelta(week.Reviews, previous.Reviews)))
+	text.WriteString(i18n.T(loc, "report.learned", week.WordsLearned, weekDelta(week.WordsLearned, previous.WordsLearned)))
+	text.WriteString(i18n.T(loc, "report.streak", streak, i18n.Plural(loc, "plural.day", streak)))
+
+	best, worst := bestAndWorstTopics(results, reportTopics)
+	if len(best) > 0 {
+		text.WriteString(i18n.T(loc, "report.best"))
+		for _, r := range best {
+			text.WriteString(i18n.T(loc, "report.topic", r.TopicName, r.Completed, r.Due))
+		}
+	}
+	if len(worst) > 0 {
+		text.WriteString(i18n.T(loc, "report.worst"))
+		for _, r := range worst {
+			text.WriteString(i18n.T(loc, "report.topic", r.TopicName, r.Completed, r.Due))
+		}
+	}
+
+	text.WriteString(i18n.T(loc, "report.delta_hint"))
+	return strings.TrimRight(text.String(), "\n")
+}
+
+// weekDelta shows the change from the previous week in parentheses
+func weekDelta(current, previous int) string {
+	switch {
+	case current > previous:
+		return fmt.Sprintf("(⬆️ +%d)", current-previous)
+	case current < previous:
+		return fmt.Sprintf("(⬇️ %d)", current-previous)
+	}
+	return "(=)"
+}
+
+// bestAndWorstTopics returns up to n topics with the lowest share of completed repetitions, among
+// the ones with repetitions left undone, and up to n other topics with the highest share
+func bestAndWorstTopics(results []database.TopicResult, n int) (best, worst []database.TopicResult) {
+	sorted := make([]database.TopicResult, 0, len(results))
+	for _, r := range results {
+		if r.Due > 0 {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Rate() != sorted[j].Rate() {
+			return sorted[i].Rate() > sorted[j].Rate()
+		}
+		return sorted[i].Completed > sorted[j].Completed
+	})
+
+	for i := len(sorted) - 1; i >= 0 && len(worst) < n; i-- {
+		if sorted[i].Completed == sorted[i].Due {
+			break
+		}
+		worst = append(worst, sorted[i])
+	}
+	for _, r := range sorted[:len(sorted)-len(worst)] {
+		if len(best) == n || r.Completed == 0 {
+			break
+		}
+		best = append(best, r)
+	}
+	return best, worst
+}
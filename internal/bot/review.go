@@ -0,0 +1,322 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/spaced_repetition"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data prefixes for the flashcard review
+const (
+	callbackFlipPrefix = "flip_"
+	callbackRatePrefix = "rate_"
+)
+
+// actionReviewingWord is the user state while a flashcard is on screen
+const actionReviewingWord = "reviewing_word"
+
+// qualityRatings are the answer buttons for flashcards and graded topic repetitions
+var qualityRatings = []struct {
+	Key     string
+	Quality spaced_repetition.QualityResponse
+}{
+	{Key: "rating.forgot", Quality: spaced_repetition.QualityIncorrect},
+	{Key: "rating.hard", Quality: spaced_repetition.QualityCorrectDifficult},
+	{Key: "rating.good", Quality: spaced_repetition.QualityCorrectHesitation},
+	{Key: "rating.easy", Quality: spaced_repetition.QualityPerfect},
+}
+
+// handleReview starts a flashcard review with the next due word
+func (b *Bot) handleReview(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	loc := b.userLocale(user)
+	if user == nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "review.nothing_due")))
+	}
+
+	// Words added since the last review get their reverse cards now
+	if _, err := b.progressRepo.AddReverseCards(ctx, user.ID); err != nil {
+		return err
+	}
+
+	word, direction, err := b.nextDueWord(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if word == nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "review.nothing_due")))
+	}
+
+	b.setReviewState(message.From.ID, word.ID, direction)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, flashcardFront(loc, word, direction))
+	msg.ReplyMarkup = flipKeyboard(loc, word.ID, direction, b.speech != nil)
+	return b.sendMessage(msg)
+}
+
+// handleFlashcardFlip turns the card over in place, revealing the translation and rating buttons
+func (b *Bot) handleFlashcardFlip(ctx context.Context, loc locale.Locale, callback *tgbotapi.CallbackQuery, wordID int) error {
+	state, ok := b.states.get(callback.From.ID)
+	if !ok || !state.showsCard(wordID) {
+		return &ValidationError{Message: i18n.T(loc, "review.inactive")}
+	}
+
+	user, err := b.userRepo.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return &ValidationError{Message: i18n.T(loc, "error.start")}
+	}
+
+	word, err := b.wordRepo.GetByID(ctx, user.ID, wordID)
+	if err != nil {
+		return err
+	}
+
+	// The card may have changed while the word was loaded
+	state, ok = b.states.update(callback.From.ID, func(state *UserState) bool {
+		if !state.showsCard(wordID) {
+			return false
+		}
+		state.Data["flipped"] = "true"
+		return true
+	})
+	if !ok {
+		return &ValidationError{Message: i18n.T(loc, "review.inactive")}
+	}
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		flashcardBack(loc, word, state.Data["direction"]),
+		ratingKeyboard(loc, word.ID, b.speech != nil),
+	)
+	return b.editMessage(msg)
+}
+
+// handleFlashcardRate records the answer through SM-2 and flips the same message to the next card
+func (b *Bot) handleFlashcardRate(ctx context.Context, loc locale.Locale, callback *tgbotapi.CallbackQuery, wordID int, quality spaced_repetition.QualityResponse) error {
+	// Turning the card face down again takes the answer once, a second tap finds it inactive
+	state, ok := b.states.update(callback.From.ID, func(state *UserState) bool {
+		if !state.showsCard(wordID) || state.Data["flipped"] != "true" {
+			return false
+		}
+		state.Data["flipped"] = "false"
+		return true
+	})
+	if !ok {
+		return &ValidationError{Message: i18n.T(loc, "review.inactive")}
+	}
+	// Until the answer is saved the card stays open to rate it again
+	saved := false
+	defer func() {
+		if saved {
+			return
+		}
+		b.states.update(callback.From.ID, func(state *UserState) bool {
+			if !state.showsCard(wordID) {
+				return false
+			}
+			state.Data["flipped"] = "true"
+			return true
+		})
+	}()
+
+	user, err := b.userRepo.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return &ValidationError{Message: i18n.T(loc, "error.start")}
+	}
+
+	progress, err := b.progressRepo.GetByUserAndWord(user.ID, wordID, state.Data["direction"])
+	if err != nil {
+		return err
+	}
+
+	sm2, err := b.repetitions.SM2For(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	sm2.Process(progress, quality)
+	progress.IsLearned = sm2.IsWordMastered(progress)
+	if err := b.progressRepo.Update(progress); err != nil {
+		return err
+	}
+	saved = true
+	b.recordReview(ctx, user.ID)
+
+	next, direction, err := b.nextDueWord(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+	if next == nil {
+		b.states.remove(callback.From.ID)
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID,
+			i18n.T(loc, "review.all_done"),
+			createKeyboard(b.mainMenuButtons(loc)),
+		)
+		return b.editMessage(msg)
+	}
+
+	b.setReviewState(callback.From.ID, next.ID, direction)
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID,
+		flashcardFront(loc, next, direction), flipKeyboard(loc, next.ID, direction, b.speech != nil))
+	return b.editMessage(msg)
+}
+
+// handleFlashcardCallback routes flip_*, say_* and rate_* callbacks
+func (b *Bot) handleFlashcardCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	loc := b.localeFor(ctx, callback.From.ID)
+	if strings.HasPrefix(callback.Data, callbackSayPrefix) {
+		wordID, err := strconv.Atoi(strings.TrimPrefix(callback.Data, callbackSayPrefix))
+		if err != nil {
+			return &ValidationError{Message: i18n.T(loc, "stale.review")}
+		}
+		return b.handlePronunciation(ctx, callback, wordID)
+	}
+	if strings.HasPrefix(callback.Data, callbackFlipPrefix) {
+		wordID, err := strconv.Atoi(strings.TrimPrefix(callback.Data, callbackFlipPrefix))
+		if err != nil {
+			return &ValidationError{Message: i18n.T(loc, "stale.review")}
+		}
+		return b.handleFlashcardFlip(ctx, loc, callback, wordID)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(callback.Data, callbackRatePrefix), "_")
+	if len(parts) != 2 {
+		return &ValidationError{Message: i18n.T(loc, "stale.review")}
+	}
+	wordID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return &ValidationError{Message: i18n.T(loc, "stale.review")}
+	}
+	quality, err := strconv.Atoi(parts[1])
+	if err != nil || quality < int(spaced_repetition.QualityBlackout) || quality > int(spaced_repetition.QualityPerfect) {
+		return &ValidationError{Message: i18n.T(loc, "stale.review")}
+	}
+	return b.handleFlashcardRate(ctx, loc, callback, wordID, spaced_repetition.QualityResponse(quality))
+}
+
+// nextDueWord returns the highest priority due word for the user with the direction of its card,
+// or nil if nothing is due
+func (b *Bot) nextDueWord(ctx context.Context, userID int64) (*models.Word, string, error) {
+	next, err := b.dueWords(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(next) == 0 {
+		return nil, "", nil
+	}
+
+	word, err := b.wordRepo.GetByID(ctx, userID, next[0].WordID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get word for review", "word_id", next[0].WordID, "error", err)
+		return nil, "", err
+	}
+	return word, next[0].Direction, nil
+}
+
+// setReviewState remembers which card is on screen, in which direction, and that it hasn't
+// been flipped yet
+func (b *Bot) setReviewState(telegramID int64, wordID int, direction string) {
+	b.states.put(telegramID, &UserState{
+		Action: actionReviewingWord,
+		Step:   1,
+		Data: map[string]string{
+			"word_id":   strconv.Itoa(wordID),
+			"direction": direction,
+			"flipped":   "false",
+		},
+	})
+}
+
+// showsCard reports whether the state is a review with the word's card on screen
+func (s *UserState) showsCard(wordID int) bool {
+	return s.Action == actionReviewingWord && s.Data["word_id"] == strconv.Itoa(wordID)
+}
+
+// flashcardFront renders the question side of a card: the word for a forward card, the
+// translation for a reverse one
+func flashcardFront(loc locale.Locale, word *models.Word, direction string) string {
+	if direction == models.DirectionReverse {
+		return i18n.T(loc, "review.front_reverse", word.Translation)
+	}
+	return i18n.T(loc, "review.front", word.Word)
+}
+
+// flashcardBack renders the answer side of a card
+func flashcardBack(loc locale.Locale, word *models.Word, direction string) string {
+	if direction == models.DirectionReverse {
+		return i18n.T(loc, "review.card") + wordDetails(loc, word) + i18n.T(loc, "review.back_reverse")
+	}
+	return i18n.T(loc, "review.card") + wordDetails(loc, word) + i18n.T(loc, "review.back")
+}
+
+// wordDetails renders the word with its translation, pronunciation, verb forms, synonyms,
+// antonyms and examples
+func wordDetails(loc locale.Locale, word *models.Word) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🇬🇧 %s\n🇷🇺 %s\n", word.Word, word.Translation))
+	if word.Pronunciation != "" {
+		text.WriteString(fmt.Sprintf("🔊 %s\n", word.Pronunciation))
+	}
+	if word.VerbForms != "" {
+		text.WriteString(i18n.T(loc, "word.forms", word.VerbForms) + "\n")
+	}
+	if word.Synonyms != "" {
+		text.WriteString(i18n.T(loc, "word.synonyms", word.Synonyms) + "\n")
+	}
+	if word.Antonyms != "" {
+		text.WriteString(i18n.T(loc, "word.antonyms", word.Antonyms) + "\n")
+	}
+	if word.Examples != "" {
+		text.WriteString(i18n.T(loc, "word.examples", word.Examples) + "\n")
+	}
+	return text.String()
+}
+
+// flipKeyboard returns the keyboard for the front of a card, with the pronunciation button if
+// speak. A reverse card has no pronunciation button on the front, it would give the answer away.
+func flipKeyboard(loc locale.Locale, wordID int, direction string, speak bool) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]MenuButton{
+		{{Text: i18n.T(loc, "review.flip"), CallbackData: fmt.Sprintf("%s%d", callbackFlipPrefix, wordID)}},
+	}
+	if speak && direction != models.DirectionReverse {
+		rows = append(rows, []MenuButton{pronunciationButton(loc, wordID)})
+	}
+	return createKeyboard(rows)
+}
+
+// ratingKeyboard returns the answer buttons for the back of a card, with the pronunciation button if speak
+func ratingKeyboard(loc locale.Locale, wordID int, speak bool) tgbotapi.InlineKeyboardMarkup {
+	var row []MenuButton
+	for _, rating := range qualityRatings {
+		row = append(row, MenuButton{
+			Text:         i18n.T(loc, rating.Key),
+			CallbackData: fmt.Sprintf("%s%d_%d", callbackRatePrefix, wordID, rating.Quality),
+		})
+	}
+	rows := [][]MenuButton{row}
+	if speak {
+		rows = append(rows, []MenuButton{pronunciationButton(loc, wordID)})
+	}
+	return createKeyboard(rows)
+}
@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/example/engbot/internal/database/dbtest"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/spaced_repetition"
+	"github.com/example/engbot/pkg/models"
+)
+
+func TestFlashcardFlipThenRate(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	const telegramID = 500
+
+	user := dbtest.User(t, telegramID)
+	topic := dbtest.Topic(t, user.ID, "Fruit", 1, tb.clock.Now())
+	_, err := tb.wordRepo.ImportWords(ctx, user.ID, []models.Word{
+		{Word: "apple", Translation: "яблоко", Examples: "An apple a day.", TopicID: topic.ID},
+		{Word: "pear", Translation: "груша", Examples: "A ripe pear.", TopicID: topic.ID},
+	})
+	if err != nil {
+		t.Fatalf("failed to import words: %v", err)
+	}
+
+	if err := tb.handleReview(ctx, message(telegramID, "/review")); err != nil {
+		t.Fatalf("review: %v", err)
+	}
+	state, ok := tb.states.get(telegramID)
+	if !ok || state.Action != actionReviewingWord || state.Data["flipped"] != "false" {
+		t.Fatalf("state after /review: %+v", state)
+	}
+	wordID, _ := strconv.Atoi(state.Data["word_id"])
+	direction := state.Data["direction"]
+
+	// The answer can't be rated before the card is turned over
+	var validation *ValidationError
+	err = tb.handleFlashcardRate(ctx, locale.Russian, callback(telegramID, "rate"), wordID, spaced_repetition.QualityPerfect)
+	if !errors.As(err, &validation) {
+		t.Fatalf("rating a card face down: %v, want a validation error", err)
+	}
+
+	if err := tb.handleFlashcardFlip(ctx, locale.Russian, callback(telegramID, "flip"), wordID); err != nil {
+		t.Fatalf("flip: %v", err)
+	}
+	if state, _ := tb.states.get(telegramID); state.Data["flipped"] != "true" {
+		t.Fatalf("card not flipped in the stored state: %+v", state)
+	}
+
+	before, err := tb.progressRepo.GetByUserAndWord(user.ID, wordID, direction)
+	if err != nil {
+		t.Fatalf("failed to get progress: %v", err)
+	}
+	if err := tb.handleFlashcardRate(ctx, locale.Russian, callback(telegramID, "rate"), wordID, spaced_repetition.QualityPerfect); err != nil {
+		t.Fatalf("rate: %v", err)
+	}
+
+	progress, err := tb.progressRepo.GetByUserAndWord(user.ID, wordID, direction)
+	if err != nil {
+		t.Fatalf("failed to get progress: %v", err)
+	}
+	want := *before
+	spaced_repetition.NewSM2().ProcessAt(&want, spaced_repetition.QualityPerfect, tb.clock.Now())
+	if progress.Repetitions != want.Repetitions || progress.Interval != want.Interval ||
+		progress.EasinessFactor != want.EasinessFactor || progress.LastQuality != int(spaced_repetition.QualityPerfect) {
+		t.Errorf("progress %d repetitions, %d days, EF %v, quality %d; want %d, %d, %v, %d",
+			progress.Repetitions, progress.Interval, progress.EasinessFactor, progress.LastQuality,
+			want.Repetitions, want.Interval, want.EasinessFactor, spaced_repetition.QualityPerfect)
+	}
+	if !progress.LastReviewDate.Equal(tb.clock.Now()) || !progress.NextReviewDate.Equal(want.NextReviewDate) {
+		t.Errorf("reviewed %v, due %v; want %v and %v", progress.LastReviewDate, progress.NextReviewDate, tb.clock.Now(), want.NextReviewDate)
+	}
+	if progress.IntroducedAt == nil {
+		t.Error("the word is still new after its first review")
+	}
+
+	// A second tap on the same rating doesn't count the answer twice
+	err = tb.handleFlashcardRate(ctx, locale.Russian, callback(telegramID, "rate"), wordID, spaced_repetition.QualityPerfect)
+	if !errors.As(err, &validation) {
+		t.Fatalf("second rating: %v, want it refused", err)
+	}
+	again, err := tb.progressRepo.GetByUserAndWord(user.ID, wordID, direction)
+	if err != nil {
+		t.Fatalf("failed to get progress: %v", err)
+	}
+	if again.Repetitions != progress.Repetitions || again.EasinessFactor != progress.EasinessFactor {
+		t.Errorf("second tap changed the progress: %d repetitions, EF %v", again.Repetitions, again.EasinessFactor)
+	}
+
+	// The next card is on screen face down
+	if state, ok := tb.states.get(telegramID); !ok || state.Action != actionReviewingWord || state.Data["flipped"] != "false" {
+		t.Errorf("state after rating: %+v", state)
+	}
+}
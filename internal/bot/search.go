@@ -0,0 +1,261 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Search limits
+const (
+	maxSearchResults     = 5   // topics and words each
+	maxSearchQueryLength = 100 // runes
+	searchSnippetLength  = 60  // runes of a description or an example
+)
+
+// Callback data prefixes of the search result actions
+const (
+	callbackReviewWordPrefix     = "review_word_"
+	callbackEditWordPrefix       = "edit_word_"
+	callbackAskDeleteWordPrefix  = "ask_del_word_"
+	callbackDeleteWordPrefix     = "del_word_"
+	callbackAskDeleteTopicPrefix = "ask_del_topic_"
+	callbackDeleteTopicPrefix    = "del_topic_"
+)
+
+// actionEditingWord is the user state while the bot waits for a new translation of a word
+const actionEditingWord = "editing_word"
+
+// handleSearchCommand handles /search <запрос>: the user's topics and words matching the query,
+// best matches first, with buttons to review, edit or delete them
+func (b *Bot) handleSearchCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	query := strings.TrimSpace(message.CommandArguments())
+	if query == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "search.usage")))
+	}
+	if len([]rune(query)) > maxSearchQueryLength {
+		return &ValidationError{Message: i18n.T(loc, "search.too_long", maxSearchQueryLength)}
+	}
+
+	results, err := b.searchRepo.Search(ctx, user.ID, query, maxSearchResults)
+	if err != nil {
+		return err
+	}
+	if len(results.Topics) == 0 && len(results.Words) == 0 {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "search.nothing", query)))
+	}
+
+	// Topics that are due get the review button
+	due, err := b.repetitionRepo.GetDueRepetitions(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	dueByTopic := make(map[int64]int64)
+	for _, rep := range due {
+		if _, ok := dueByTopic[rep.TopicID]; !ok {
+			dueByTopic[rep.TopicID] = rep.ID
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(i18n.T(loc, "search.title", query))
+	var buttons [][]MenuButton
+	if len(results.Topics) > 0 {
+		text.WriteString(i18n.T(loc, "search.topics"))
+		for i, topic := range results.Topics {
+			text.WriteString(fmt.Sprintf("%d. %s", i+1, topic.Name))
+			if topic.Description != "" {
+				text.WriteString(" - " + snippet(topic.Description))
+			}
+			if topic.Archived {
+				text.WriteString(i18n.T(loc, "search.archived"))
+			}
+			text.WriteString("\n")
+			buttons = append(buttons, searchTopicButtons(topic, dueByTopic[topic.ID]))
+		}
+	}
+	if len(results.Words) > 0 {
+		text.WriteString(i18n.T(loc, "search.words"))
+		for i, word := range results.Words {
+			text.WriteString(fmt.Sprintf("%d. %s - %s", i+1, word.Word, word.Translation))
+			if word.IsPhrase() {
+				text.WriteString(" (" + kindName(loc, word.Kind) + ")")
+			}
+			text.WriteString("\n")
+			if word.Examples != "" {
+				text.WriteString("   💬 " + snippet(strings.SplitN(word.Examples, "\n", 2)[0]) + "\n")
+			}
+			buttons = append(buttons, searchWordButtons(loc, word)...)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
+	msg.ReplyMarkup = createKeyboard(buttons)
+	return b.sendMessage(msg)
+}
+
+// searchTopicButtons returns the actions on a found topic: review it if it's due, rename, delete
+func searchTopicButtons(topic models.Topic, dueRepID int64) []MenuButton {
+	var row []MenuButton
+	if dueRepID != 0 {
+		row = append(row, MenuButton{Text: "✅ " + topic.Name, CallbackData: fmt.Sprintf("complete_%d", dueRepID)})
+	}
+	return append(row,
+		MenuButton{Text: "✏️ " + topic.Name, CallbackData: fmt.Sprintf("%s%d", callbackEditTopicPrefix, topic.ID)},
+		MenuButton{Text: "🗑", CallbackData: fmt.Sprintf("%s%d", callbackAskDeleteTopicPrefix, topic.ID)},
+	)
+}
+
+// searchWordButtons returns the actions on a found word: review it now, edit the translation, delete,
+// and for a phrase switch it to a collocation or back
+func searchWordButtons(loc locale.Locale, word models.Word) [][]MenuButton {
+	buttons := [][]MenuButton{{
+		{Text: "🃏 " + word.Word, CallbackData: fmt.Sprintf("%s%d", callbackReviewWordPrefix, word.ID)},
+		{Text: "✏️", CallbackData: fmt.Sprintf("%s%d", callbackEditWordPrefix, word.ID)},
+		{Text: "🗑", CallbackData: fmt.Sprintf("%s%d", callbackAskDeleteWordPrefix, word.ID)},
+	}}
+	if word.IsPhrase() {
+		buttons = append(buttons, []MenuButton{wordKindButton(loc, word)})
+	}
+	return buttons
+}
+
+// snippet shortens text to searchSnippetLength runes on one line
+func snippet(text string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= searchSnippetLength {
+		return string(runes)
+	}
+	return string(runes[:searchSnippetLength-1]) + "…"
+}
+
+// handleSearchCallback runs the action of a search result button
+func (b *Bot) handleSearchCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	prefix := ""
+	for _, p := range []string{
+		callbackReviewWordPrefix, callbackEditWordPrefix, callbackAskDeleteWordPrefix, callbackDeleteWordPrefix,
+		callbackAskDeleteTopicPrefix, callbackDeleteTopicPrefix, callbackWordKindPrefix,
+	} {
+		if strings.HasPrefix(callback.Data, p) {
+			prefix = p
+			break
+		}
+	}
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	id, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, prefix), 10, 64)
+	if prefix == "" || err != nil {
+		return &ValidationError{Message: i18n.T(loc, "search.stale")}
+	}
+	chatID := callback.Message.Chat.ID
+
+	switch prefix {
+	case callbackAskDeleteTopicPrefix:
+		topic, err := b.topicRepo.GetByID(ctx, user.ID, id)
+		if err != nil {
+			return err
+		}
+		if topic == nil {
+			return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+		}
+		return b.confirmDeletion(loc, chatID, i18n.T(loc, "search.confirm_topic", topic.Name), callbackDeleteTopicPrefix, id)
+	case callbackDeleteTopicPrefix:
+		topic, err := b.topicRepo.GetByID(ctx, user.ID, id)
+		if err != nil {
+			return err
+		}
+		if topic == nil {
+			return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+		}
+		trashID, err := b.topicRepo.Delete(ctx, user.ID, topic.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete topic: %w", err)
+		}
+		msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "search.topic_deleted", topic.Name, b.undoHint(loc)))
+		msg.ReplyMarkup = createKeyboard(append([][]MenuButton{undoDeleteButton(loc, trashID)}, b.topicsMenuButtons(loc)...))
+		return b.sendMessage(msg)
+	}
+
+	word, err := b.wordRepo.GetByID(ctx, user.ID, int(id))
+	if err != nil {
+		return err
+	}
+	switch prefix {
+	case callbackReviewWordPrefix:
+		b.setReviewState(callback.From.ID, word.ID, models.DirectionForward)
+		msg := tgbotapi.NewMessage(chatID, flashcardFront(loc, word, models.DirectionForward))
+		msg.ReplyMarkup = flipKeyboard(loc, word.ID, models.DirectionForward, b.speech != nil)
+		return b.sendMessage(msg)
+	case callbackEditWordPrefix:
+		b.states.put(callback.From.ID, &UserState{
+			Action: actionEditingWord,
+			Step:   1,
+			Data:   map[string]string{"word_id": strconv.Itoa(word.ID)},
+		})
+		msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "search.edit_word", word.Word, word.Translation))
+		msg.ReplyMarkup = createKeyboard([][]MenuButton{{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}}})
+		return b.sendMessage(msg)
+	case callbackWordKindPrefix:
+		return b.switchWordKind(ctx, loc, chatID, user.ID, word)
+	case callbackAskDeleteWordPrefix:
+		return b.confirmDeletion(loc, chatID, i18n.T(loc, "search.confirm_word", word.Word), callbackDeleteWordPrefix, id)
+	default:
+		if err := b.wordRepo.Delete(ctx, user.ID, word.ID); err != nil {
+			return err
+		}
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "search.word_deleted", word.Word)))
+	}
+}
+
+// confirmDeletion asks to confirm a deletion with the button that runs prefix+id
+func (b *Bot) confirmDeletion(loc locale.Locale, chatID int64, text, prefix string, id int64) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "search.confirm_delete"), CallbackData: fmt.Sprintf("%s%d", prefix, id)}},
+		{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+	})
+	return b.sendMessage(msg)
+}
+
+// handleEditWordText stores the message text as the new translation of the word from the user state
+func (b *Bot) handleEditWordText(ctx context.Context, message *tgbotapi.Message) error {
+	state, _ := b.states.get(message.From.ID)
+	wordID, err := strconv.Atoi(state.Data["word_id"])
+	if err != nil {
+		b.states.remove(message.From.ID)
+		return fmt.Errorf("invalid word ID in edit state: %w", err)
+	}
+
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	translation := strings.TrimSpace(message.Text)
+	if translation == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "search.empty_translation")))
+	}
+
+	if err := b.wordRepo.UpdateTranslation(ctx, user.ID, wordID, translation); err != nil {
+		b.states.remove(message.From.ID)
+		return err
+	}
+	b.states.remove(message.From.ID)
+
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "search.translation_saved", translation)))
+}
@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/example/engbot/internal/ai"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+)
+
+// sentenceCheckText explains what the language model found in the sentence the user wrote:
+// whether the word was used as asked, the mistakes and the corrected sentence
+func sentenceCheckText(loc locale.Locale, check *ai.SentenceCheck, sentence string) string {
+	var text strings.Builder
+	switch {
+	case !check.UsesWord:
+		text.WriteString(i18n.T(loc, "sentence.no_word"))
+	case !check.RightMeaning:
+		text.WriteString(i18n.T(loc, "sentence.wrong_meaning"))
+	case len(check.Mistakes) == 0:
+		text.WriteString(i18n.T(loc, "sentence.no_mistakes"))
+	}
+	for _, m := range check.Mistakes {
+		text.WriteString("\n✏️ ")
+		switch {
+		case m.Wrong == "":
+			text.WriteString("+ " + m.Right)
+		case m.Right == "":
+			text.WriteString(m.Wrong + i18n.T(loc, "sentence.remove"))
+		default:
+			text.WriteString(m.Wrong + " → " + m.Right)
+		}
+		if m.Explanation != "" {
+			text.WriteString(": " + m.Explanation)
+		}
+	}
+	if !strings.EqualFold(strings.TrimSpace(check.Corrected), strings.TrimSpace(sentence)) {
+		text.WriteString(i18n.T(loc, "sentence.corrected") + check.Corrected)
+	}
+	return text.String()
+}
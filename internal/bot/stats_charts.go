@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/example/engbot/internal/charts"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackStatsCharts sends the statistics charts from the /stats message
+const callbackStatsCharts = "stats_charts"
+
+// Periods and sizes of the statistics charts
+const (
+	chartWeeks     = 12 // weeks of the completion rate chart
+	chartDays      = 30 // days of the reviews per day chart
+	maxChartTopics = 15 // topics of the topic progress chart
+)
+
+// statsChart is a rendered chart with its caption
+type statsChart struct {
+	name    string
+	caption string
+	png     []byte
+}
+
+// sendStatsCharts sends the user's completion rate, reviews per day and topic progress as pictures.
+// Charts without enough data yet are left out.
+func (b *Bot) sendStatsCharts(ctx context.Context, chatID int64, user *models.User) error {
+	now := b.clock.Now()
+	loc := b.userLocale(user)
+	var list []statsChart
+
+	reps, err := b.repetitionRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if points := completionRatePoints(reps, startOfDay(now), chartWeeks); len(points) >= 2 {
+		png, err := charts.CompletionRate(points)
+		if err != nil {
+			return err
+		}
+		list = append(list, statsChart{"completion.png", i18n.T(loc, "charts.completion", chartWeeks), png})
+	}
+
+	since := startOfDay(now).AddDate(0, 0, -(chartDays - 1))
+	activity, err := b.activityRepo.GetSince(ctx, user.ID, since)
+	if err != nil {
+		return err
+	}
+	if len(activity) > 0 {
+		points := reviewPoints(activity, since, chartDays)
+		png, err := charts.ReviewsPerDay(points, user.DailyGoal)
+		if err != nil {
+			return err
+		}
+		caption := i18n.T(loc, "charts.reviews", chartDays, sumPoints(points))
+		if user.DailyGoal > 0 {
+			caption += i18n.T(loc, "charts.goal", user.DailyGoal)
+		}
+		list = append(list, statsChart{"reviews.png", caption, png})
+	}
+
+	stats, err := b.statsRepo.GetUserStatistics(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get statistics: %w", err)
+	}
+	var bars []charts.Bar
+	for _, stat := range stats {
+		if stat.TotalRepetitions == 0 || len(bars) == maxChartTopics {
+			continue
+		}
+		bars = append(bars, charts.Bar{
+			Label: stat.TopicName,
+			Value: float64(stat.CompletedRepetitions) / float64(stat.TotalRepetitions) * 100,
+		})
+	}
+	if len(bars) > 0 {
+		png, err := charts.TopicProgress(bars)
+		if err != nil {
+			return err
+		}
+		caption := i18n.T(loc, "charts.topics")
+		if len(stats) > maxChartTopics {
+			caption += i18n.T(loc, "charts.topics_first", maxChartTopics)
+		}
+		list = append(list, statsChart{"topics.png", caption, png})
+	}
+
+	if len(list) == 0 {
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "charts.no_data")))
+	}
+	for _, c := range list {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: c.name, Bytes: c.png})
+		photo.Caption = c.caption
+		if _, err := b.dispatcher.Send(ctx, chatID, photo); err != nil {
+			return fmt.Errorf("failed to send statistics chart: %w", err)
+		}
+	}
+	return nil
+}
+
+// handleStatsChartsCallback sends the charts from the button of the /stats message
+func (b *Bot) handleStatsChartsCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	return b.sendStatsCharts(ctx, callback.Message.Chat.ID, user)
+}
+
+// completionRatePoints returns the share of completed repetitions, in percent, among the ones due
+// in each of the last weeks ending today. Weeks with nothing due are left out.
+func completionRatePoints(reps []models.Repetition, today time.Time, weeks int) []charts.Point {
+	end := today.AddDate(0, 0, 1)
+	var points []charts.Point
+	for week := weeks - 1; week >= 0; week-- {
+		to := end.AddDate(0, 0, -7*week)
+		from := to.AddDate(0, 0, -7)
+		due, completed := 0, 0
+		for _, rep := range reps {
+			if rep.NextReviewDate.Before(from) || !rep.NextReviewDate.Before(to) {
+				continue
+			}
+			due++
+			if rep.Completed {
+				completed++
+			}
+		}
+		if due > 0 {
+			points = append(points, charts.Point{
+				Time:  to.AddDate(0, 0, -1),
+				Value: float64(completed) / float64(due) * 100,
+			})
+		}
+	}
+	return points
+}
+
+// reviewPoints lays the activity out by day from since on, with no reviews on the missing days
+func reviewPoints(activity []models.DailyActivity, since time.Time, days int) []charts.Point {
+	reviews := make(map[string]int, len(activity))
+	for _, a := range activity {
+		reviews[a.Day] = a.Reviews
+	}
+	points := make([]charts.Point, days)
+	for i := range points {
+		day := since.AddDate(0, 0, i)
+		points[i] = charts.Point{Time: day, Value: float64(reviews[day.Format("2006-01-02")])}
+	}
+	return points
+}
+
+// sumPoints adds up the values of the points
+func sumPoints(points []charts.Point) int {
+	total := 0.0
+	for _, p := range points {
+		total += p.Value
+	}
+	return int(total)
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// storyWordCount is how many of the user's words a story uses
+const storyWordCount = 6
+
+// handleStoryCommand handles /story: without arguments it writes a story with the words to review,
+// "/story on|off" turns the daily story on or off
+func (b *Bot) handleStoryCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if b.languageModel == nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "story.unavailable")))
+	}
+
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "":
+		return b.sendStory(ctx, message.Chat.ID, user, false)
+	case "on":
+		user.StoryEnabled = true
+	case "off":
+		user.StoryEnabled = false
+	default:
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "story.usage")))
+	}
+
+	if err := b.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	text := i18n.T(loc, "story.off")
+	if user.StoryEnabled {
+		text = i18n.T(loc, "story.on", database.NotificationHours(user)[0])
+	}
+	return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, text))
+}
+
+// SendDailyStory sends the daily story to the user, skipping users with no words.
+// It implements the scheduler.Notifier interface.
+func (b *Bot) SendDailyStory(ctx context.Context, telegramID int64) error {
+	if b.languageModel == nil {
+		return nil
+	}
+	user, err := b.userRepo.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		return err
+	}
+	return b.notifyOnce(ctx, user, database.NotificationStory, func() error {
+		return b.sendStory(ctx, telegramID, user, true)
+	})
+}
+
+// sendStory generates a story with the user's words to review and sends it with the translation
+// hidden under a spoiler
+func (b *Bot) sendStory(ctx context.Context, chatID int64, user *models.User, daily bool) error {
+	loc := b.userLocale(user)
+	words, err := b.storyWords(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		if daily {
+			return nil
+		}
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "story.no_words")))
+	}
+
+	prompt := make([]string, len(words))
+	for i, w := range words {
+		prompt[i] = w.Word
+	}
+	story, err := b.languageModel.GenerateText(ctx, prompt)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to generate story", "user_id", user.ID, "error", err)
+		if daily {
+			return err
+		}
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "story.failed")))
+	}
+
+	return b.sendMessage(storyText(loc, words, story.Text, story.Translation, daily).Message(chatID))
+}
+
+// storyWords picks the words for a story: the ones due for review first, then the ones
+// coming up next
+func (b *Bot) storyWords(ctx context.Context, userID int64) ([]models.Word, error) {
+	due, err := b.dueWords(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var picked []models.UserProgress
+	for _, p := range due {
+		if len(picked) == storyWordCount {
+			break
+		}
+		// A word can be due in both directions
+		if !slices.ContainsFunc(picked, func(q models.UserProgress) bool { return q.WordID == p.WordID }) {
+			picked = append(picked, p)
+		}
+	}
+
+	if len(picked) < storyWordCount {
+		progress, err := b.progressRepo.GetAllByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		slices.SortFunc(progress, func(a, b models.UserProgress) int {
+			return a.NextReviewDate.Compare(b.NextReviewDate)
+		})
+		for _, p := range progress {
+			if len(picked) == storyWordCount {
+				break
+			}
+			if p.IsLearned || slices.ContainsFunc(picked, func(q models.UserProgress) bool { return q.WordID == p.WordID }) {
+				continue
+			}
+			picked = append(picked, p)
+		}
+	}
+
+	all, err := b.wordRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.Word, len(all))
+	for _, w := range all {
+		byID[w.ID] = w
+	}
+	var words []models.Word
+	for _, p := range picked {
+		if w, ok := byID[p.WordID]; ok {
+			words = append(words, w)
+		}
+	}
+	return words, nil
+}
+
+// storyText renders the story with the words it practices and the translation under a spoiler
+func storyText(loc locale.Locale, words []models.Word, text, translation string, daily bool) *richText {
+	out := newRichText(tgbotapi.ModeHTML).Text("📖 ")
+	if daily {
+		out.Bold(i18n.T(loc, "story.daily_title"))
+	} else {
+		out.Bold(i18n.T(loc, "story.title"))
+	}
+	out.Text("\n\n🔤 ")
+	for i, w := range words {
+		if i > 0 {
+			out.Text(", ")
+		}
+		out.Bold(w.Word).Text(" - " + w.Translation)
+	}
+	out.Text("\n\n" + text)
+	if translation != "" {
+		out.Text(i18n.T(loc, "story.translation")).Spoiler(translation)
+	}
+	return out.Text(i18n.T(loc, "story.more"))
+}
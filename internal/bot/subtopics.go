@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleMoveCommand handles /move <номер> <номер родителя>: the topic becomes a subtopic of the
+// other one, /move <номер> 0 makes it top-level again
+func (b *Bot) handleMoveCommand(ctx context.Context, message *tgbotapi.Message) error {
+	loc := b.localeFor(ctx, message.From.ID)
+	usage := i18n.T(loc, "move.usage")
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+	parentIndex, err := strconv.Atoi(args[1])
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+	if index < 1 || index > len(topics) || parentIndex < 0 || parentIndex > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topic.wrong_number")))
+	}
+
+	topic := topics[index-1]
+	end := subtreeEnd(topics, index-1)
+	var parent *models.Topic
+	depth := 0
+	if parentIndex > 0 {
+		if parentIndex-1 >= index-1 && parentIndex-1 < end {
+			return &ValidationError{Message: i18n.T(loc, "move.into_itself")}
+		}
+		parent = &topics[parentIndex-1]
+		depth = parent.Depth + 1
+	}
+
+	// The deepest subtopic moves along with the topic
+	height := 0
+	for _, t := range topics[index-1 : end] {
+		height = max(height, t.Depth-topic.Depth+1)
+	}
+	if depth+height > models.MaxTopicDepth {
+		return &ValidationError{Message: i18n.T(loc, "move.too_deep", models.MaxTopicDepth)}
+	}
+
+	parentID := int64(0)
+	text := i18n.T(loc, "move.top_level", topic.Name)
+	if parent != nil {
+		parentID = parent.ID
+		text = i18n.T(loc, "move.done", topic.Name, parent.Name)
+	}
+	if err := b.topicRepo.SetParent(ctx, user.ID, topic.ID, parentID); err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyMarkup = createKeyboard(b.topicsMenuButtons(loc))
+	return b.sendMessage(msg)
+}
+
+// subtreeEnd returns the index right after the last subtopic of topics[i]. The topics are in
+// the order of GetAllByUserID, where subtopics follow their parent.
+func subtreeEnd(topics []models.Topic, i int) int {
+	end := i + 1
+	for end < len(topics) && topics[end].Depth > topics[i].Depth {
+		end++
+	}
+	return end
+}
+
+// topicLabel returns the topic name indented by its nesting level
+func topicLabel(topic models.Topic) string {
+	if topic.Depth == 0 {
+		return topic.Name
+	}
+	return strings.Repeat("    ", topic.Depth-1) + "↳ " + topic.Name
+}
+
+// rollUpStatistics sums the statistics of each topic with subtopics over its whole subtree
+func rollUpStatistics(topics []models.Topic, stats []models.Statistics) map[int64]models.Statistics {
+	byTopic := make(map[int64]models.Statistics, len(stats))
+	for _, s := range stats {
+		byTopic[s.TopicID] = s
+	}
+
+	rolled := make(map[int64]models.Statistics)
+	for i, topic := range topics {
+		end := subtreeEnd(topics, i)
+		if end == i+1 {
+			continue
+		}
+		total := models.Statistics{TopicID: topic.ID, TopicName: topic.Name}
+		for _, t := range topics[i:end] {
+			total.TotalRepetitions += byTopic[t.ID].TotalRepetitions
+			total.CompletedRepetitions += byTopic[t.ID].CompletedRepetitions
+		}
+		rolled[topic.ID] = total
+	}
+	return rolled
+}
@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	wordtest "github.com/example/engbot/internal/testing"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// How many tile buttons fit a row on a phone screen: letters are narrow, words wide
+const (
+	letterTilesPerRow = 8
+	wordTilesPerRow   = 3
+)
+
+// handleQuizTile adds the tapped letter or word to the answer being put together. Once all
+// the tiles are placed the answer is graded and the next question takes the message.
+func (b *Bot) handleQuizTile(ctx context.Context, callback *tgbotapi.CallbackQuery, number, tile int) error {
+	s := b.quizzes.get(callback.From.ID)
+	if s == nil || !s.tiled() {
+		return &ValidationError{Message: i18n.T(b.localeFor(ctx, callback.From.ID), "quiz.finished")}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Taps on a finished question or a tile already placed change nothing
+	if s.test.Done() || s.test.Number() != number {
+		return nil
+	}
+	q := s.test.Current()
+	if tile >= len(q.Tiles) || slices.Contains(s.tiles, tile) {
+		return nil
+	}
+	s.tiles = append(s.tiles, tile)
+
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+	if len(s.tiles) < len(q.Tiles) {
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID,
+			quizTilesText(s.loc, s.test, s.tiles), createKeyboard(quizTileButtons(s.loc, s.test, s.tiles)))
+		return b.editMessage(msg)
+	}
+
+	text, buttons, err := b.answerQuizText(ctx, callback.From.ID, s, tilesAnswer(s.test, s.tiles))
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, createKeyboard(buttons))
+	return b.editMessage(msg)
+}
+
+// handleQuizErase takes back the last tile placed
+func (b *Bot) handleQuizErase(callback *tgbotapi.CallbackQuery, number int) error {
+	s := b.quizzes.get(callback.From.ID)
+	if s == nil || !s.tiled() {
+		return &ValidationError{Message: i18n.T(b.localeFor(context.Background(), callback.From.ID), "quiz.finished")}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.test.Done() || s.test.Number() != number || len(s.tiles) == 0 {
+		return nil
+	}
+	s.tiles = s.tiles[:len(s.tiles)-1]
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID,
+		quizTilesText(s.loc, s.test, s.tiles), createKeyboard(quizTileButtons(s.loc, s.test, s.tiles)))
+	return b.editMessage(msg)
+}
+
+// tilesAnswer joins the tiles placed in the order they were tapped: letters into a word,
+// words into a sentence
+func tilesAnswer(test *wordtest.Test, tiles []int) string {
+	q := test.Current()
+	placed := make([]string, len(tiles))
+	for i, tile := range tiles {
+		placed[i] = q.Tiles[tile]
+	}
+	if test.Type == wordtest.WordOrder {
+		return strings.Join(placed, " ")
+	}
+	return strings.Join(placed, "")
+}
+
+// quizTilesText asks to spell the current word by its translation or to put the sentence with
+// it in order, with the tiles placed so far and a blank for each one left
+func quizTilesText(loc locale.Locale, test *wordtest.Test, tiles []int) string {
+	q := test.Current()
+	slots := make([]string, len(q.Tiles))
+	for i := range slots {
+		slots[i] = "_"
+		if i < len(tiles) {
+			slots[i] = tileText(test, q.Tiles[tiles[i]])
+		}
+	}
+	if test.Type == wordtest.WordOrder {
+		return i18n.T(loc, "quiz.question", test.Number(), len(test.Questions)) +
+			i18n.T(loc, "quiz.word_order", q.Word.Word, q.Word.Translation, strings.Join(slots, " "))
+	}
+	return i18n.T(loc, "quiz.question", test.Number(), len(test.Questions)) +
+		i18n.T(loc, "quiz.spelling", q.Word.Translation, strings.Join(slots, " "))
+}
+
+// tileText shows a tile: letters in capitals to read better on the buttons, words as written
+func tileText(test *wordtest.Test, tile string) string {
+	if test.Type == wordtest.WordOrder {
+		return tile
+	}
+	return strings.ToUpper(tile)
+}
+
+// quizTileButtons returns the shuffled tiles of the current question, the ones placed already
+// dimmed, with the buttons to erase a tile, give up and stop the test
+func quizTileButtons(loc locale.Locale, test *wordtest.Test, tiles []int) [][]MenuButton {
+	perRow := letterTilesPerRow
+	if test.Type == wordtest.WordOrder {
+		perRow = wordTilesPerRow
+	}
+	var buttons [][]MenuButton
+	var row []MenuButton
+	for i, tile := range test.Current().Tiles {
+		text := tileText(test, tile)
+		if slices.Contains(tiles, i) {
+			text = "·"
+		}
+		row = append(row, MenuButton{
+			Text:         text,
+			CallbackData: fmt.Sprintf("%s%d_%d", callbackQuizTilePrefix, test.Number(), i),
+		})
+		if len(row) == perRow {
+			buttons = append(buttons, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		buttons = append(buttons, row)
+	}
+
+	controls := []MenuButton{{Text: i18n.T(loc, "quiz.skip"), CallbackData: fmt.Sprintf("%s%d", callbackQuizSkipPrefix, test.Number())}}
+	if len(tiles) > 0 {
+		controls = append([]MenuButton{{Text: i18n.T(loc, "quiz.erase"), CallbackData: fmt.Sprintf("%s%d", callbackQuizErasePrefix, test.Number())}}, controls...)
+	}
+	return append(buttons, controls, []MenuButton{{Text: i18n.T(loc, "quiz.stop"), CallbackData: callbackQuizStop}})
+}
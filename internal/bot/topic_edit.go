@@ -0,0 +1,200 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackEditTopicPrefix starts renaming the topic with the ID that follows
+const callbackEditTopicPrefix = "edit_topic_"
+
+// actionEditingTopic is the user state while the bot waits for a new topic name
+const actionEditingTopic = "editing_topic"
+
+// handleEditCommand handles /edit <номер>, or lists topics with edit buttons without a number
+func (b *Bot) handleEditCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(topics) == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topics.none"))
+		msg.ReplyMarkup = createKeyboard(b.mainMenuButtons(loc))
+		return b.sendMessage(msg)
+	}
+
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		var buttons [][]MenuButton
+		for _, topic := range topics {
+			buttons = append(buttons, []MenuButton{editTopicButton(loc, topic.ID, topic.Name)})
+		}
+		buttons = append(buttons, []MenuButton{{Text: i18n.T(loc, "button.back_to_topics"), CallbackData: "topics_menu"}})
+
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "edit.choose"))
+		msg.ReplyMarkup = createKeyboard(buttons)
+		return b.sendMessage(msg)
+	}
+
+	index, err := strconv.Atoi(args)
+	if err != nil || index < 1 || index > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "edit.usage")))
+	}
+
+	topic := topics[index-1]
+	return b.startEditTopic(loc, message.Chat.ID, message.From.ID, topic.ID, topic.Name)
+}
+
+// handleEditTopicCallback starts renaming the topic from an inline button
+func (b *Bot) handleEditTopicCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, topicID int64) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+
+	topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if topic == nil {
+		return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+	}
+
+	return b.startEditTopic(loc, callback.Message.Chat.ID, callback.From.ID, topic.ID, topic.Name)
+}
+
+// startEditTopic asks for the new name and remembers which topic is being renamed
+func (b *Bot) startEditTopic(loc locale.Locale, chatID, telegramID, topicID int64, currentName string) error {
+	b.states.put(telegramID, &UserState{
+		Action: actionEditingTopic,
+		Step:   1,
+		Data:   map[string]string{"topic_id": strconv.FormatInt(topicID, 10)},
+	})
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(loc, "edit.ask", currentName))
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+	})
+	return b.sendMessage(msg)
+}
+
+// handleEditTopicText validates the new name and renames the topic
+func (b *Bot) handleEditTopicText(ctx context.Context, message *tgbotapi.Message) error {
+	state, _ := b.states.get(message.From.ID)
+	topicID, err := strconv.ParseInt(state.Data["topic_id"], 10, 64)
+	if err != nil {
+		b.states.remove(message.From.ID)
+		return fmt.Errorf("invalid topic ID in edit state: %w", err)
+	}
+
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+
+	name := strings.TrimSpace(message.Text)
+	if name == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "edit.empty")))
+	}
+
+	topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		b.states.remove(message.From.ID)
+		return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+	}
+
+	taken, err := b.topicRepo.NameTaken(ctx, user.ID, name, topic.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "edit.taken", name)))
+	}
+
+	oldName := topic.Name
+	topic.Name = name
+	if err := b.topicRepo.Update(ctx, topic); err != nil {
+		return err
+	}
+	b.states.remove(message.From.ID)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "edit.done", oldName, name))
+	msg.ReplyMarkup = createKeyboard(b.topicsMenuButtons(loc))
+	return b.sendMessage(msg)
+}
+
+// maxTopicDescriptionLength limits /describe, in runes
+const maxTopicDescriptionLength = 500
+
+// handleDescribeCommand handles /describe <номер> <описание>: a note about the topic that /search
+// also looks through. "/describe <номер> -" removes it.
+func (b *Bot) handleDescribeCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	usage := i18n.T(loc, "describe.usage")
+	args := strings.SplitN(strings.TrimSpace(message.CommandArguments()), " ", 2)
+	if len(args) != 2 || strings.TrimSpace(args[1]) == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, usage))
+	}
+	description := strings.TrimSpace(args[1])
+	if description == "-" {
+		description = ""
+	}
+	if len([]rune(description)) > maxTopicDescriptionLength {
+		return &ValidationError{Message: i18n.T(loc, "describe.too_long", maxTopicDescriptionLength)}
+	}
+
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+	if index < 1 || index > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topic.wrong_number")))
+	}
+
+	topic := topics[index-1]
+	topic.Description = description
+	if err := b.topicRepo.Update(ctx, &topic); err != nil {
+		return err
+	}
+
+	text := i18n.T(loc, "describe.saved", topic.Name)
+	if description == "" {
+		text = i18n.T(loc, "describe.removed", topic.Name)
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyMarkup = createKeyboard(b.topicsMenuButtons(loc))
+	return b.sendMessage(msg)
+}
+
+// editTopicButton returns the inline button that starts renaming a topic
+func editTopicButton(loc locale.Locale, topicID int64, name string) MenuButton {
+	return MenuButton{
+		Text:         i18n.T(loc, "edit.button", name),
+		CallbackData: fmt.Sprintf("%s%d", callbackEditTopicPrefix, topicID),
+	}
+}
@@ -0,0 +1,221 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackTopicIntervalsPrefix asks for the own ladder of the topic with the ID that follows
+const callbackTopicIntervalsPrefix = "topic_intervals_"
+
+// actionEditingTopicIntervals is the user state while the bot waits for the ladder of one topic
+const actionEditingTopicIntervals = "editing_topic_intervals"
+
+// topicIntervalsUsage explains what the ladder of one topic can be set to
+func topicIntervalsUsage(loc locale.Locale) string {
+	return i18n.T(loc, "topic_intervals.usage", database.MaxCustomIntervals, database.MaxIntervalDays)
+}
+
+// handleTopicIntervalsCommand handles /topicintervals <номер> [интервалы | экзамен <дней> | off]:
+// shows or sets the ladder of one topic. Without a number it lists the topics with their own ladder.
+func (b *Bot) handleTopicIntervalsCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+
+	loc := b.userLocale(user)
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, topicIntervalsListText(loc, topics)))
+	}
+	number, rest, _ := strings.Cut(args, " ")
+	index, err := strconv.Atoi(number)
+	if err != nil || index < 1 || index > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "topic_intervals.wrong_number")))
+	}
+	topic := topics[index-1]
+	userIntervals, err := database.GetUserIntervals(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return b.sendMessage(topicIntervalsText(loc, topic, userIntervals).Message(message.Chat.ID))
+	}
+	intervals, err := parseTopicIntervals(loc, rest, userIntervals)
+	if err != nil {
+		return err
+	}
+	return b.saveTopicIntervals(ctx, message.Chat.ID, user, &topic, intervals)
+}
+
+// handleTopicIntervalsCallback shows the ladder of the topic and waits for a new one
+func (b *Bot) handleTopicIntervalsCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	topicID, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, callbackTopicIntervalsPrefix), 10, 64)
+	if err != nil {
+		return &ValidationError{Message: i18n.T(loc, "stale.topic")}
+	}
+	topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+	}
+	userIntervals, err := database.GetUserIntervals(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	b.states.put(callback.From.ID, &UserState{
+		Action: actionEditingTopicIntervals,
+		Step:   1,
+		Data:   map[string]string{"topic_id": strconv.FormatInt(topic.ID, 10)},
+	})
+	msg := topicIntervalsText(loc, *topic, userIntervals).Text(i18n.T(loc, "topic_intervals.ask")).Message(callback.Message.Chat.ID)
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{{Text: i18n.T(loc, "button.cancel"), CallbackData: callbackCancelAction}},
+	})
+	return b.sendMessage(msg)
+}
+
+// handleTopicIntervalsText saves the ladder sent after the "Свой график повторений" button
+func (b *Bot) handleTopicIntervalsText(ctx context.Context, message *tgbotapi.Message) error {
+	state, _ := b.states.get(message.From.ID)
+	topicID, err := strconv.ParseInt(state.Data["topic_id"], 10, 64)
+	if err != nil {
+		b.states.remove(message.From.ID)
+		return fmt.Errorf("invalid topic ID in intervals state: %w", err)
+	}
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		b.states.remove(message.From.ID)
+		return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+	}
+	userIntervals, err := database.GetUserIntervals(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	intervals, err := parseTopicIntervals(loc, message.Text, userIntervals)
+	if err != nil {
+		return err
+	}
+	if err := b.saveTopicIntervals(ctx, message.Chat.ID, user, topic, intervals); err != nil {
+		return err
+	}
+	b.states.remove(message.From.ID)
+	return nil
+}
+
+// parseTopicIntervals reads the ladder of a topic: a comma-separated ladder, "экзамен <дней>" for
+// the user's ladder squeezed into that many days, or "off" for nil, going back to the user's ladder
+func parseTopicIntervals(loc locale.Locale, text string, userIntervals []int) ([]int, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	switch text {
+	case "off", "reset", "выкл", "сброс":
+		return nil, nil
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 2 && (fields[0] == "exam" || fields[0] == "экзамен") {
+		days, err := strconv.Atoi(fields[1])
+		if err != nil || days < 1 || days > database.MaxIntervalDays {
+			return nil, &ValidationError{Message: i18n.T(loc, "topic_intervals.exam", database.MaxIntervalDays)}
+		}
+		return database.CompressIntervals(userIntervals, days), nil
+	}
+
+	intervals, err := database.ParseIntervals(text)
+	if err != nil {
+		return nil, &ValidationError{Message: i18n.T(loc, "topic_intervals.invalid") + topicIntervalsUsage(loc)}
+	}
+	return intervals, nil
+}
+
+// saveTopicIntervals stores the ladder of the topic and confirms it. The pending repetition is
+// brought forward when the new ladder puts it earlier, so a compressed schedule starts at once.
+func (b *Bot) saveTopicIntervals(ctx context.Context, chatID int64, user *models.User, topic *models.Topic, intervals []int) error {
+	if err := b.topicRepo.SetIntervals(ctx, user.ID, topic.ID, intervals); err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	text := newRichText(tgbotapi.ModeHTML)
+	if len(intervals) == 0 {
+		text.Text(i18n.T(loc, "topic_intervals.reset_prefix")).Bold(topic.Name).Text(i18n.T(loc, "topic_intervals.reset_suffix"))
+		return b.sendMessage(text.Message(chatID))
+	}
+	text.Text(i18n.T(loc, "topic_intervals.saved_prefix")).Bold(topic.Name).Text(i18n.T(loc, "topic_intervals.saved_suffix", database.FormatIntervals(intervals)))
+
+	reps, err := b.repetitionRepo.GetByTopic(ctx, user.ID, topic.ID)
+	if err != nil {
+		return err
+	}
+	if _, pending := topicProgress(reps); pending != nil && !pending.Maintenance {
+		next := b.repetitionRepo.CalculateNextReviewDate(pending.RepetitionNumber-1, intervals)
+		if next.Before(pending.NextReviewDate) {
+			if err := b.repetitionRepo.Reschedule(ctx, user.ID, pending.ID, next); err != nil {
+				return err
+			}
+			text.Text(i18n.T(loc, "topic_intervals.moved", pending.RepetitionNumber, next.Format("02.01.2006")))
+		}
+	}
+	return b.sendMessage(text.Message(chatID))
+}
+
+// topicIntervalsText describes the ladder the topic is reviewed on and how to change it
+func topicIntervalsText(loc locale.Locale, topic models.Topic, userIntervals []int) *richText {
+	text := newRichText(tgbotapi.ModeHTML).Text(i18n.T(loc, "topic_intervals.title")).Bold(topic.Name).Text("\n\n")
+	if topic.CustomIntervals == "" && topic.Difficulty != models.DefaultTopicDifficulty {
+		text.Text(i18n.T(loc, "topic_intervals.current_difficulty",
+			difficultyLabel(loc, topic.Difficulty), database.FormatIntervals(database.TopicIntervals(&topic, userIntervals))))
+	} else if topic.CustomIntervals == "" {
+		text.Text(i18n.T(loc, "topic_intervals.current_common", database.FormatIntervals(userIntervals)))
+	} else {
+		text.Text(i18n.T(loc, "topic_intervals.current_own", database.FormatIntervals(database.TopicIntervals(&topic, userIntervals))))
+	}
+	return text.Text(topicIntervalsUsage(loc))
+}
+
+// topicIntervalsListText lists the topics with their own ladder by their numbers in /list
+func topicIntervalsListText(loc locale.Locale, topics []models.Topic) string {
+	var text strings.Builder
+	for i, topic := range topics {
+		if topic.CustomIntervals != "" {
+			text.WriteString(i18n.T(loc, "topic_intervals.item", i+1, topic.Name,
+				database.FormatIntervals(database.TopicIntervals(&topic, nil))))
+		}
+	}
+	if text.Len() == 0 {
+		return i18n.T(loc, "topic_intervals.none")
+	}
+	return i18n.T(loc, "topic_intervals.list") + text.String() + i18n.T(loc, "topic_intervals.list_hint")
+}
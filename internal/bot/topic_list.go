@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/service"
+	"github.com/example/engbot/pkg/models"
+)
+
+// progressBar draws how many of the total repetitions are done, one cell per repetition
+func progressBar(done, total int) string {
+	done = max(0, min(done, total))
+	return strings.Repeat("▓", done) + strings.Repeat("░", total-done) + fmt.Sprintf(" %d/%d", done, total)
+}
+
+// topicProgress returns how many repetitions of the cycle the topic has passed and its pending
+// repetition, nil once the cycle is over. A failed review repeats the same step, so the passed
+// ones are the ones before the pending repetition rather than every completed row.
+func topicProgress(reps []models.Repetition) (int, *models.Repetition) {
+	completed := false
+	for i := range reps {
+		if !reps[i].Completed {
+			return min(reps[i].RepetitionNumber-1, service.CycleRepetitions), &reps[i]
+		}
+		completed = true
+	}
+	if completed {
+		return service.CycleRepetitions, nil
+	}
+	return 0, nil
+}
+
+// isDue reports whether the pending repetition is due for review
+func isDue(pending *models.Repetition, now time.Time) bool {
+	return pending != nil && !pending.NextReviewDate.After(now)
+}
+
+// nextReviewText tells in calendar days when the next review is
+func nextReviewText(loc locale.Locale, next, now time.Time) string {
+	days := int(math.Round(startOfDay(next.In(now.Location())).Sub(startOfDay(now)).Hours() / 24))
+	switch {
+	case days < 0:
+		return i18n.T(loc, "next_review.overdue", -days, i18n.Plural(loc, "plural.day", -days))
+	case days == 0:
+		return i18n.T(loc, "next_review.today")
+	case days == 1:
+		return i18n.T(loc, "next_review.tomorrow")
+	default:
+		return i18n.T(loc, "next_review.in", days, i18n.Plural(loc, "plural.day", days))
+	}
+}
+
+// topicCardButtons returns the menu of one topic in the list, the buttons numbered like the
+// topic: review when it is due, history, edit and archive, or history and restore for an
+// archived topic
+func topicCardButtons(number int, topic models.Topic, pending *models.Repetition, due bool) []MenuButton {
+	history := MenuButton{Text: fmt.Sprintf("📊 %d", number), CallbackData: fmt.Sprintf("%s%d", callbackTopicStatsPrefix, topic.ID)}
+	if topic.Archived {
+		return []MenuButton{history, {
+			Text:         fmt.Sprintf("♻️ %d", number),
+			CallbackData: fmt.Sprintf("%s%d", callbackRestoreTopicPrefix, topic.ID),
+		}}
+	}
+
+	var row []MenuButton
+	if due {
+		row = append(row, MenuButton{Text: fmt.Sprintf("🔄 %d", number), CallbackData: fmt.Sprintf("complete_%d", pending.ID)})
+	}
+	return append(row, history,
+		MenuButton{Text: fmt.Sprintf("✏️ %d", number), CallbackData: fmt.Sprintf("%s%d", callbackEditTopicPrefix, topic.ID)},
+		MenuButton{Text: fmt.Sprintf("📦 %d", number), CallbackData: fmt.Sprintf("%s%d", callbackArchiveTopicPrefix, topic.ID)},
+	)
+}
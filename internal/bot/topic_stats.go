@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/service"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackTopicStatsPrefix opens the statistics of one topic from the /stats message, followed by the topic ID
+const callbackTopicStatsPrefix = "topic_stats_"
+
+// maxTopicStatsButtons is how many topics get a button under the /stats message
+const maxTopicStatsButtons = 10
+
+// handleTopicStatsCommand handles "/stats <номер>": the statistics of one topic by its number in /list
+func (b *Bot) handleTopicStatsCommand(ctx context.Context, message *tgbotapi.Message, user *models.User, index int) error {
+	topics, err := b.topicRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+	if index < 1 || index > len(topics) {
+		return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(b.userLocale(user), "topic.wrong_number")))
+	}
+	return b.sendTopicStats(ctx, message.Chat.ID, user, topics[index-1])
+}
+
+// handleTopicStatsCallback shows the statistics of the topic of a button under the /stats message
+func (b *Bot) handleTopicStatsCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	topicID, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, callbackTopicStatsPrefix), 10, 64)
+	if err != nil {
+		return &ValidationError{Message: i18n.T(loc, "stale.stats")}
+	}
+	topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+	}
+	return b.sendTopicStats(ctx, callback.Message.Chat.ID, user, *topic)
+}
+
+// sendTopicStats sends the statistics of one topic
+func (b *Bot) sendTopicStats(ctx context.Context, chatID int64, user *models.User, topic models.Topic) error {
+	reps, err := b.repetitionRepo.GetByTopic(ctx, user.ID, topic.ID)
+	if err != nil {
+		return err
+	}
+	intervals, err := database.GetUserIntervals(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	loc := b.userLocale(user)
+	msg := topicStatsText(loc, topic, reps, database.TopicIntervals(&topic, intervals), b.clock.Now()).Message(chatID)
+	msg.ReplyMarkup = createKeyboard([][]MenuButton{
+		{maintenanceButton(loc, topic)},
+		{{Text: i18n.T(loc, "topic_stats.intervals_button"), CallbackData: fmt.Sprintf("%s%d", callbackTopicIntervalsPrefix, topic.ID)}},
+		{{Text: i18n.T(loc, "topic_stats.all_button"), CallbackData: "stats"}},
+		{{Text: i18n.T(loc, "button.menu"), CallbackData: "main_menu"}},
+	})
+	return b.sendMessage(msg)
+}
+
+// topicStatsText describes how the topic goes: the current repetition, the average lateness, the
+// timeline of the repetitions and when the cycle is likely to end
+func topicStatsText(loc locale.Locale, topic models.Topic, reps []models.Repetition, intervals []int, now time.Time) *richText {
+	text := newRichText(tgbotapi.ModeHTML).Text(i18n.T(loc, "topic_stats.title")).Bold(topic.Name).Text("\n\n")
+	if len(reps) == 0 {
+		return text.Text(i18n.T(loc, "topic_stats.no_reviews"))
+	}
+
+	var pending *models.Repetition
+	for i := range reps {
+		if !reps[i].Completed {
+			pending = &reps[i]
+		}
+	}
+	lateness, reviewed := averageLateness(reps)
+
+	switch {
+	case pending != nil && pending.Maintenance:
+		text.Text(i18n.T(loc, "topic_stats.maintenance", pending.NextReviewDate.Format("02.01.2006")))
+	case pending != nil:
+		text.Text(i18n.T(loc, "topic_stats.current", pending.RepetitionNumber, service.CycleRepetitions))
+		next := pending.NextReviewDate.Format("02.01.2006")
+		if pending.NextReviewDate.Before(now) {
+			next += i18n.T(loc, "topic_stats.overdue")
+		}
+		text.Text(i18n.T(loc, "topic_stats.next", next))
+	default:
+		text.Text(i18n.T(loc, "topic_stats.finished"))
+	}
+	if reviewed > 0 {
+		text.Text(i18n.T(loc, "topic_stats.lateness", latenessText(loc, lateness)))
+	}
+	if topic.CustomIntervals != "" {
+		text.Text(i18n.T(loc, "topic_stats.own_intervals", database.FormatIntervals(intervals)))
+	}
+	switch {
+	case topic.Archived:
+		text.Text(i18n.T(loc, "topic_stats.archived"))
+	case topic.Stalled:
+		text.Text(i18n.T(loc, "topic_stats.stalled"))
+	case topic.Muted:
+		text.Text(i18n.T(loc, "topic_stats.muted"))
+	case pending != nil && !pending.Maintenance:
+		finish := estimateFinish(*pending, lateness, intervals, now)
+		text.Text(i18n.T(loc, "topic_stats.finish", service.CycleRepetitions, finish.Format("02.01.2006")))
+	}
+
+	text.Text(i18n.T(loc, "topic_stats.reviews"))
+	for _, rep := range reps {
+		if rep.Completed && rep.LastReviewDate != nil {
+			text.Text(i18n.T(loc, "topic_stats.done", rep.RepetitionNumber, rep.LastReviewDate.Format("02.01.2006"),
+				latenessText(loc, lateDays(rep))))
+		} else if !rep.Completed {
+			text.Text(i18n.T(loc, "topic_stats.planned", rep.RepetitionNumber, rep.NextReviewDate.Format("02.01.2006")))
+		}
+	}
+	return text
+}
+
+// lateDays returns how many days after its date the repetition was done, 0 for one done in time
+func lateDays(rep models.Repetition) float64 {
+	if rep.LastReviewDate == nil {
+		return 0
+	}
+	return max(0, rep.LastReviewDate.Sub(rep.NextReviewDate).Hours()/24)
+}
+
+// averageLateness returns the average lateness of the completed repetitions in days and their number
+func averageLateness(reps []models.Repetition) (float64, int) {
+	total, count := 0.0, 0
+	for _, rep := range reps {
+		if rep.Completed && rep.LastReviewDate != nil {
+			total += lateDays(rep)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return total / float64(count), count
+}
+
+// latenessText describes a lateness in days
+func latenessText(loc locale.Locale, days float64) string {
+	if days < 1 {
+		return i18n.T(loc, "topic_stats.on_time")
+	}
+	whole := int(days + 0.5)
+	return i18n.T(loc, "topic_stats.late", whole, i18n.Plural(loc, "plural.day", whole))
+}
+
+// estimateFinish estimates when the last repetition of the cycle will be done: the pending one and
+// each of the following ones come after the interval of the user's ladder, and every repetition is
+// done as late as the ones done so far on average
+func estimateFinish(pending models.Repetition, lateness float64, intervals []int, now time.Time) time.Time {
+	if len(intervals) == 0 {
+		intervals = database.DefaultIntervals
+	}
+	delay := time.Duration(lateness * 24 * float64(time.Hour))
+
+	done := pending.NextReviewDate
+	if done.Before(now) {
+		done = now
+	}
+	done = done.Add(delay)
+	for number := pending.RepetitionNumber; number < service.CycleRepetitions; number++ {
+		done = done.AddDate(0, 0, intervals[min(number, len(intervals)-1)]).Add(delay)
+	}
+	return done
+}
+
+// topicStatsButtons opens the statistics of the first topics of the /stats message, two in a row
+func topicStatsButtons(stats []models.Statistics) [][]MenuButton {
+	var rows [][]MenuButton
+	for i, stat := range stats {
+		if i == maxTopicStatsButtons {
+			break
+		}
+		button := MenuButton{Text: "🔍 " + stat.TopicName, CallbackData: fmt.Sprintf("%s%d", callbackTopicStatsPrefix, stat.TopicID)}
+		if i%2 == 0 {
+			rows = append(rows, []MenuButton{button})
+		} else {
+			rows[len(rows)-1] = append(rows[len(rows)-1], button)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data prefixes of the "↩️ Отменить" button
+const (
+	callbackUndoDeletePrefix  = "undo_delete_"  // followed by the trash entry ID
+	callbackUndoArchivePrefix = "undo_archive_" // followed by "<topic ID>_<Unix time of archiving>"
+)
+
+// undoDeleteButton returns the button that restores the topics of a trash entry
+func undoDeleteButton(loc locale.Locale, trashID int64) []MenuButton {
+	return []MenuButton{{Text: i18n.T(loc, "undo.button"), CallbackData: fmt.Sprintf("%s%d", callbackUndoDeletePrefix, trashID)}}
+}
+
+// undoArchiveButton returns the button that takes a topic archived at the given time back out of the archive
+func undoArchiveButton(loc locale.Locale, topicID int64, archivedAt time.Time) []MenuButton {
+	return []MenuButton{{Text: i18n.T(loc, "undo.button"), CallbackData: fmt.Sprintf("%s%d_%d", callbackUndoArchivePrefix, topicID, archivedAt.Unix())}}
+}
+
+// undoHint tells how long the undo button works
+func (b *Bot) undoHint(loc locale.Locale) string {
+	return i18n.T(loc, "undo.hint", int(b.config.UndoWindow.Minutes()))
+}
+
+// handleUndoCallback restores deleted topics or takes an archived topic back out of the archive,
+// as long as the undo window isn't over
+func (b *Bot) handleUndoCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.getOrCreateUser(ctx, callback.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	expired := &ValidationError{Message: i18n.T(loc, "undo.expired")}
+	now := b.clock.Now()
+
+	var text string
+	if strings.HasPrefix(callback.Data, callbackUndoDeletePrefix) {
+		trashID, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, callbackUndoDeletePrefix), 10, 64)
+		if err != nil {
+			return expired
+		}
+		restored, err := b.topicRepo.Restore(ctx, user.ID, trashID, now.Add(-b.config.UndoWindow))
+		switch {
+		case errors.Is(err, database.ErrNotFound):
+			return expired
+		case errors.Is(err, database.ErrTopicExists):
+			return &ValidationError{Message: i18n.T(loc, "undo.exists")}
+		case err != nil:
+			return err
+		}
+		text = i18n.T(loc, "undo.restored", restored)
+	} else {
+		parts := strings.Split(strings.TrimPrefix(callback.Data, callbackUndoArchivePrefix), "_")
+		if len(parts) != 2 {
+			return expired
+		}
+		topicID, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return expired
+		}
+		archivedAt, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || now.Sub(time.Unix(archivedAt, 0)) > b.config.UndoWindow {
+			return expired
+		}
+		topic, err := b.topicRepo.GetByID(ctx, user.ID, topicID)
+		if err != nil {
+			return err
+		}
+		if topic == nil {
+			return &ValidationError{Message: i18n.T(loc, "topic.not_found")}
+		}
+		if !topic.Archived {
+			return &ValidationError{Message: i18n.T(loc, "undo.not_archived", topic.Name)}
+		}
+		if _, err := b.topicRepo.BulkSetArchived(ctx, user.ID, []int64{topic.ID}, false); err != nil {
+			return err
+		}
+		text = i18n.T(loc, "undo.unarchived", topic.Name)
+	}
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text,
+		createKeyboard(b.topicsMenuButtons(loc)))
+	return b.editMessage(msg)
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"maps"
+	"sync"
+)
+
+// UserState represents the current state of user interaction
+type UserState struct {
+	Action string
+	Step   int
+	Data   map[string]string
+}
+
+// clone returns a copy of the state that doesn't share its data
+func (s *UserState) clone() *UserState {
+	c := *s
+	c.Data = maps.Clone(s.Data)
+	if c.Data == nil {
+		c.Data = make(map[string]string)
+	}
+	return &c
+}
+
+// userStates keeps the multi-step dialogs by Telegram user ID. Updates are handled
+// concurrently, so like quizSessions it is guarded, and the states it hands out are copies:
+// a change goes back through put or update.
+type userStates struct {
+	mu     sync.Mutex
+	states map[int64]*UserState
+}
+
+func newUserStates() *userStates {
+	return &userStates{states: make(map[int64]*UserState)}
+}
+
+// get returns a copy of the user's state
+func (u *userStates) get(telegramID int64) (*UserState, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	state, ok := u.states[telegramID]
+	if !ok {
+		return nil, false
+	}
+	return state.clone(), true
+}
+
+// put starts a dialog with the user, replacing the one the user was in
+func (u *userStates) put(telegramID int64, state *UserState) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.states[telegramID] = state.clone()
+}
+
+// update changes the user's state in place with fn, which reports whether the state is the one
+// it expected. It returns a copy of the changed state, or false when the user has no state or fn
+// refused it; the check and the change can't interleave with another update.
+func (u *userStates) update(telegramID int64, fn func(state *UserState) bool) (*UserState, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	state, ok := u.states[telegramID]
+	if !ok {
+		return nil, false
+	}
+	changed := state.clone()
+	if !fn(changed) {
+		return nil, false
+	}
+	u.states[telegramID] = changed
+	return changed.clone(), true
+}
+
+// remove ends the user's dialog
+func (u *userStates) remove(telegramID int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.states, telegramID)
+}
+
+// take ends the user's dialog and returns its state
+func (u *userStates) take(telegramID int64) (*UserState, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	state, ok := u.states[telegramID]
+	delete(u.states, telegramID)
+	return state, ok
+}
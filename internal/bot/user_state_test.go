@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestUserStatesHandOutCopies(t *testing.T) {
+	states := newUserStates()
+	states.put(1, &UserState{Action: actionBulkSelecting, Data: map[string]string{"selected": ""}})
+
+	state, ok := states.get(1)
+	if !ok {
+		t.Fatal("no state after put")
+	}
+	state.Data["selected"] = "7"
+	if again, _ := states.get(1); again.Data["selected"] != "" {
+		t.Errorf("a change to a copy reached the store: %q", again.Data["selected"])
+	}
+
+	if _, ok := states.update(1, func(state *UserState) bool { return state.Action == actionReviewingWord }); ok {
+		t.Error("update went through although fn refused the state")
+	}
+	if _, ok := states.update(2, func(*UserState) bool { return true }); ok {
+		t.Error("update of a user without a state went through")
+	}
+
+	if taken, ok := states.take(1); !ok || taken.Action != actionBulkSelecting {
+		t.Errorf("take = %v, %v", taken, ok)
+	}
+	if _, ok := states.get(1); ok {
+		t.Error("state left after take")
+	}
+}
+
+func TestUserStatesConcurrentUpdates(t *testing.T) {
+	states := newUserStates()
+	states.put(1, &UserState{Action: actionBulkSelecting, Data: map[string]string{"selected": ""}})
+
+	const toggles = 50
+	var wg sync.WaitGroup
+	for i := 1; i <= toggles; i++ {
+		wg.Add(1)
+		go func(topicID int64) {
+			defer wg.Done()
+			states.update(1, func(state *UserState) bool {
+				state.Data["selected"] = formatSelection(toggleSelection(parseSelection(state.Data["selected"]), topicID))
+				return true
+			})
+			states.get(1)
+		}(int64(i))
+	}
+	wg.Wait()
+
+	state, _ := states.get(1)
+	if got := len(parseSelection(state.Data["selected"])); got != toggles {
+		t.Errorf("%d topics selected after %d concurrent toggles, want all of them: %s", got, toggles, state.Data["selected"])
+	}
+	for i := 1; i <= toggles; i++ {
+		if !slices.Contains(parseSelection(state.Data["selected"]), int64(i)) {
+			t.Errorf("topic %d lost", i)
+		}
+	}
+}
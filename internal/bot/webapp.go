@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/example/engbot/internal/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// webAppButton is an inline button that opens a Mini App. tgbotapi v5.5.1 predates Mini Apps,
+// so the keyboard is built here and sent as the reply markup as is.
+type webAppButton struct {
+	Text   string     `json:"text"`
+	WebApp webAppInfo `json:"web_app"`
+}
+
+// webAppInfo is the page of the Mini App
+type webAppInfo struct {
+	URL string `json:"url"`
+}
+
+// webAppKeyboard is an inline keyboard with a Mini App button
+type webAppKeyboard struct {
+	InlineKeyboard [][]webAppButton `json:"inline_keyboard"`
+}
+
+// handleAppCommand handles /app: it sends the button opening the flashcard review Mini App
+func (b *Bot) handleAppCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	if b.config.WebAppURL == "" {
+		return &ValidationError{Message: i18n.T(loc, "app.disabled")}
+	}
+
+	due, err := b.repetitionRepo.GetDueRepetitions(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	text := i18n.T(loc, "app.nothing_due")
+	if len(due) > 0 {
+		text = i18n.T(loc, "app.due", len(due))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyMarkup = webAppKeyboard{InlineKeyboard: [][]webAppButton{
+		{{Text: i18n.T(loc, "app.button"), WebApp: webAppInfo{URL: b.config.WebAppURL}}},
+	}}
+	return b.sendMessage(msg)
+}
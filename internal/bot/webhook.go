@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// secretTokenHeader carries the secret_token passed to setWebhook
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// webhookShutdownTimeout bounds how long in-flight webhook requests may take on shutdown
+const webhookShutdownTimeout = 5 * time.Second
+
+// receiveUpdates returns the update channel: from the webhook server when WEBHOOK_URL is set,
+// otherwise (or if the webhook can't be started) from long polling
+func (b *Bot) receiveUpdates(ctx context.Context) tgbotapi.UpdatesChannel {
+	if b.config.WebhookURL != "" {
+		updates, err := b.startWebhook(ctx)
+		if err == nil {
+			return updates
+		}
+		slog.Warn("failed to start webhook, falling back to long polling", "error", err)
+	}
+
+	// getUpdates is rejected by Telegram while a webhook is registered
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		slog.Warn("failed to delete webhook", "error", err)
+	}
+
+	updateConfig := tgbotapi.NewUpdate(0)
+	updateConfig.Timeout = 60
+	return b.api.GetUpdatesChan(updateConfig)
+}
+
+// startWebhook binds the HTTP server, registers the webhook with Telegram and
+// serves updates until ctx is cancelled
+func (b *Bot) startWebhook(ctx context.Context) (tgbotapi.UpdatesChannel, error) {
+	webhookURL, err := url.Parse(b.config.WebhookURL)
+	if err != nil || webhookURL.Host == "" {
+		return nil, fmt.Errorf("invalid WEBHOOK_URL %q", b.config.WebhookURL)
+	}
+	path := webhookURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	// Bind before calling setWebhook, so a busy port falls back to polling
+	// without Telegram sending updates nowhere
+	listener, err := net.Listen("tcp", b.config.WebhookListenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", b.config.WebhookListenAddr, err)
+	}
+
+	// Without a secret anyone reaching the port could post updates on behalf of any user
+	secret := b.config.WebhookSecretToken
+	if secret == "" {
+		if secret, err = randomSecret(); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+	}
+
+	params := tgbotapi.Params{"url": webhookURL.String()}
+	params.AddNonEmpty("secret_token", secret)
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set webhook: %w", err)
+	}
+
+	updates := make(chan tgbotapi.Update, b.api.Buffer)
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, b.webhookHandler(ctx, secret, updates))
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	safeGoroutine(func() {
+		var err error
+		if b.config.WebhookCertFile != "" && b.config.WebhookKeyFile != "" {
+			err = server.ServeTLS(listener, b.config.WebhookCertFile, b.config.WebhookKeyFile)
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("webhook server stopped", "error", err)
+		}
+	})
+
+	safeGoroutine(func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+		defer cancel()
+		// Only a clean shutdown has no handler left that could still send an update. After a
+		// timeout the channel stays open, the update loop stops on ctx instead.
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("webhook server shutdown", "error", err)
+			return
+		}
+		close(updates)
+	})
+
+	slog.Info("receiving updates via webhook", "addr", b.config.WebhookListenAddr, "path", path)
+	return updates, nil
+}
+
+// randomSecret returns a secret_token for setWebhook, which allows letters, digits, _ and -
+func randomSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// webhookHandler checks the secret token and forwards the decoded update to updates.
+// Requests are refused when the secret is empty.
+func (b *Bot) webhookHandler(ctx context.Context, secret string, updates chan<- tgbotapi.Update) http.HandlerFunc {
+	want := []byte(secret)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(want) == 0 || subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), want) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		update, err := b.api.HandleUpdate(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case updates <- *update:
+			w.WriteHeader(http.StatusOK)
+		case <-ctx.Done():
+			// Telegram retries the update after a non-2xx answer
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	tb := newTestBot(t)
+
+	post := func(handler http.HandlerFunc, secret string) int {
+		req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(`{"update_id":7,"message":{"message_id":1,"text":"hi"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		if secret != "" {
+			req.Header.Set(secretTokenHeader, secret)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan tgbotapi.Update, 1)
+	handler := tb.webhookHandler(ctx, "s3cret", updates)
+
+	if code := post(handler, "wrong"); code != http.StatusForbidden {
+		t.Errorf("wrong secret: status %d, want %d", code, http.StatusForbidden)
+	}
+	if code := post(handler, ""); code != http.StatusForbidden {
+		t.Errorf("no secret: status %d, want %d", code, http.StatusForbidden)
+	}
+	if code := post(tb.webhookHandler(ctx, "", updates), ""); code != http.StatusForbidden {
+		t.Errorf("no secret configured: status %d, want %d", code, http.StatusForbidden)
+	}
+	if code := post(handler, "s3cret"); code != http.StatusOK {
+		t.Fatalf("update: status %d, want %d", code, http.StatusOK)
+	}
+	if update := <-updates; update.UpdateID != 7 {
+		t.Errorf("forwarded update %d, want 7", update.UpdateID)
+	}
+
+	// Nobody reads the updates any more: the handler gives up on shutdown instead of
+	// blocking, and Telegram is told to retry
+	updates <- tgbotapi.Update{}
+	cancel()
+	if code := post(handler, "s3cret"); code != http.StatusServiceUnavailable {
+		t.Errorf("after shutdown: status %d, want %d", code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,385 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/i18n"
+	"github.com/example/engbot/internal/locale"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackWordOfDayPrefix is the callback data prefix of the quiz answers under the word of
+// the day: wod_<word of the day ID>_<1 for the right answer, 0 otherwise>
+const callbackWordOfDayPrefix = "wod_"
+
+// wordOfDayWrongOptions is how many wrong translations the quiz under the word of the day offers
+const wordOfDayWrongOptions = 3
+
+// wordOfDayPick is the word of the day with the words the wrong quiz options come from
+type wordOfDayPick struct {
+	word models.Word
+	deck *models.Deck // nil for a word of the user's own
+	pool []models.Word
+}
+
+// handleWordOfDayCommand handles /wordofday: without arguments it shows the settings, "now" sends
+// a word right away, "on|off" turns the daily word on or off, "new" takes it from the user's
+// upcoming new words and a number from the deck with that number in /decks
+func (b *Bot) handleWordOfDayCommand(ctx context.Context, message *tgbotapi.Message) error {
+	user, err := b.getOrCreateUser(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	loc := b.userLocale(user)
+	enabled, deckID, err := database.GetWordOfDay(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	switch args {
+	case "":
+		return b.sendWordOfDayStatus(ctx, message.Chat.ID, user, "")
+	case "now":
+		return b.sendWordOfDay(ctx, message.Chat.ID, user, false)
+	case "on", "off":
+		enabled = args == "on"
+	case "new":
+		enabled, deckID = true, 0
+	default:
+		n, err := strconv.Atoi(args)
+		if err != nil {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "wod.usage")))
+		}
+		decks, err := b.deckRepo.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		if n < 1 || n > len(decks) {
+			return b.sendMessage(tgbotapi.NewMessage(message.Chat.ID, i18n.T(loc, "wod.no_deck", n)))
+		}
+		enabled, deckID = true, decks[n-1].ID
+	}
+
+	if err := database.SetWordOfDay(ctx, user.ID, enabled, deckID); err != nil {
+		return err
+	}
+	return b.sendWordOfDayStatus(ctx, message.Chat.ID, user, i18n.T(loc, "wod.saved"))
+}
+
+// sendWordOfDayStatus shows whether the word of the day comes, when and where from
+func (b *Bot) sendWordOfDayStatus(ctx context.Context, chatID int64, user *models.User, note string) error {
+	enabled, deckID, err := database.GetWordOfDay(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	var deck *models.Deck
+	if deckID != 0 {
+		if deck, err = b.deckRepo.GetByID(ctx, deckID); err != nil {
+			return err
+		}
+	}
+
+	loc := b.userLocale(user)
+	var text strings.Builder
+	if note != "" {
+		text.WriteString(note + "\n\n")
+	}
+	text.WriteString(i18n.T(loc, "wod.title"))
+	if enabled {
+		text.WriteString(i18n.T(loc, "wod.enabled", database.NotificationHours(user)[0]))
+	} else {
+		text.WriteString(i18n.T(loc, "wod.disabled"))
+	}
+	if deck != nil {
+		text.WriteString(i18n.T(loc, "wod.from_deck", deck.Name))
+	} else {
+		text.WriteString(i18n.T(loc, "wod.from_own"))
+	}
+	text.WriteString(i18n.T(loc, "wod.about") + i18n.T(loc, "wod.usage"))
+	return b.sendMessage(tgbotapi.NewMessage(chatID, text.String()))
+}
+
+// SendWordOfDay sends the word of the day to the user, skipping users with no words left.
+// It implements the scheduler.Notifier interface.
+func (b *Bot) SendWordOfDay(ctx context.Context, telegramID int64) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		return err
+	}
+	return b.notifyOnce(ctx, user, database.NotificationWordOfDay, func() error {
+		return b.sendWordOfDay(ctx, telegramID, user, true)
+	})
+}
+
+// sendWordOfDay picks a word the user hasn't got before and sends it with the quiz, then its
+// pronunciation
+func (b *Bot) sendWordOfDay(ctx context.Context, chatID int64, user *models.User, daily bool) error {
+	loc := b.userLocale(user)
+	_, deckID, err := database.GetWordOfDay(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	pick, err := b.pickWordOfDay(ctx, user.ID, deckID)
+	if err != nil {
+		return err
+	}
+	if pick == nil {
+		if daily {
+			return nil
+		}
+		return b.sendMessage(tgbotapi.NewMessage(chatID, i18n.T(loc, "wod.none_left")))
+	}
+
+	pickDeckID := int64(0)
+	if pick.deck != nil {
+		pickDeckID = pick.deck.ID
+	}
+	id, err := b.wordOfDayRepo.Save(ctx, user.ID, pickDeckID, int64(pick.word.ID), pick.word.Word, pick.word.Translation)
+	if err != nil {
+		return err
+	}
+
+	options := wordOfDayOptions(pick.word, pick.pool, rand.New(rand.NewSource(b.clock.Now().UnixNano())))
+	quiz := len(options) > 1
+	msg := wordOfDayText(loc, pick.word, pick.deck, quiz, sql.NullBool{}).Message(chatID)
+	if quiz {
+		msg.ReplyMarkup = wordOfDayKeyboard(loc, id, pick.word, options)
+	}
+	if err := b.sendMessage(msg); err != nil {
+		return err
+	}
+
+	if b.speech != nil {
+		if err := b.sendPronunciation(ctx, loc, chatID, pick.word.Word); err != nil {
+			logging.FromContext(ctx).Warn("failed to send word of the day pronunciation", "user_id", user.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// pickWordOfDay picks a random word of the deck the user has neither got as the word of the day
+// nor among their own words. Without a deck, or when the deck has no such words left, it is
+// the user's next new word to come up for review, or their next word not learned yet.
+// Returns nil if there is no word left to send.
+func (b *Bot) pickWordOfDay(ctx context.Context, userID, deckID int64) (*wordOfDayPick, error) {
+	sent, err := b.wordOfDayRepo.GetSentWords(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	own, err := b.wordRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if deckID != 0 {
+		deck, err := b.deckRepo.GetByID(ctx, deckID)
+		if err != nil {
+			return nil, err
+		}
+		if deck != nil {
+			deckWords, err := b.deckRepo.GetWords(ctx, deckID, 0)
+			if err != nil {
+				return nil, err
+			}
+			known := make(map[string]bool, len(own))
+			for _, w := range own {
+				known[strings.ToLower(w.Word)] = true
+			}
+			pool := make([]models.Word, len(deckWords))
+			var fresh []models.Word
+			for i, dw := range deckWords {
+				pool[i] = deckWordOfDay(dw)
+				if !sent[strings.ToLower(dw.Word)] && !known[strings.ToLower(dw.Word)] {
+					fresh = append(fresh, pool[i])
+				}
+			}
+			if len(fresh) > 0 {
+				word := fresh[rand.New(rand.NewSource(b.clock.Now().UnixNano())).Intn(len(fresh))]
+				return &wordOfDayPick{word: word, deck: deck, pool: pool}, nil
+			}
+		}
+	}
+
+	progress, err := b.progressRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(progress, func(a, b models.UserProgress) int {
+		return a.NextReviewDate.Compare(b.NextReviewDate)
+	})
+	byID := make(map[int]models.Word, len(own))
+	for _, w := range own {
+		byID[w.ID] = w
+	}
+	// Upcoming new words first, then the rest of the words not learned yet
+	for _, upcoming := range []bool{true, false} {
+		for _, p := range progress {
+			w, ok := byID[p.WordID]
+			if !ok || p.IsLearned || sent[strings.ToLower(w.Word)] || upcoming && p.IntroducedAt != nil {
+				continue
+			}
+			return &wordOfDayPick{word: w, pool: own}, nil
+		}
+	}
+	return nil, nil
+}
+
+// deckWordOfDay turns a deck word into a word to show, with the deck word's ID
+func deckWordOfDay(dw models.DeckWord) models.Word {
+	return models.Word{
+		ID:          int(dw.ID),
+		Word:        dw.Word,
+		Translation: dw.Translation,
+		Description: dw.Description,
+		Examples:    dw.Examples,
+		VerbForms:   dw.VerbForms,
+	}
+}
+
+// wordOfDayOptions returns the translation of the word and up to wordOfDayWrongOptions other
+// translations from the pool in random order, or just the word's own if the pool has no others
+func wordOfDayOptions(word models.Word, pool []models.Word, rng *rand.Rand) []string {
+	seen := map[string]bool{strings.ToLower(word.Translation): true}
+	var wrong []string
+	for _, w := range pool {
+		key := strings.ToLower(w.Translation)
+		if w.Translation == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		wrong = append(wrong, w.Translation)
+	}
+	rng.Shuffle(len(wrong), func(i, j int) { wrong[i], wrong[j] = wrong[j], wrong[i] })
+
+	options := append(wrong[:min(len(wrong), wordOfDayWrongOptions)], word.Translation)
+	rng.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	return options
+}
+
+// wordOfDayKeyboard returns a button per translation and one for not knowing the word
+func wordOfDayKeyboard(loc locale.Locale, id int64, word models.Word, options []string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]MenuButton
+	for _, option := range options {
+		right := 0
+		if option == word.Translation {
+			right = 1
+		}
+		rows = append(rows, []MenuButton{{
+			Text:         option,
+			CallbackData: fmt.Sprintf("%s%d_%d", callbackWordOfDayPrefix, id, right),
+		}})
+	}
+	rows = append(rows, []MenuButton{{Text: i18n.T(loc, "wod.dont_know"), CallbackData: fmt.Sprintf("%s%d_0", callbackWordOfDayPrefix, id)}})
+	return createKeyboard(rows)
+}
+
+// handleWordOfDayCallback records the quiz answer to the word of the day and opens the whole card
+func (b *Bot) handleWordOfDayCallback(ctx context.Context, callback *tgbotapi.CallbackQuery) error {
+	user, err := b.userRepo.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	loc := b.userLocale(user)
+	if user == nil {
+		return &ValidationError{Message: i18n.T(loc, "error.start")}
+	}
+	parts := strings.Split(strings.TrimPrefix(callback.Data, callbackWordOfDayPrefix), "_")
+	if len(parts) != 2 {
+		return &ValidationError{Message: i18n.T(loc, "wod.stale")}
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return &ValidationError{Message: i18n.T(loc, "wod.stale")}
+	}
+
+	wod, err := b.wordOfDayRepo.GetByID(ctx, user.ID, id)
+	if err != nil {
+		return err
+	}
+	if wod == nil {
+		return &ValidationError{Message: i18n.T(loc, "wod.stale")}
+	}
+	// A second tap, e.g. on another device, shows the first answer
+	if !wod.Correct.Valid {
+		correct := parts[1] == "1"
+		if _, err := b.wordOfDayRepo.SaveAnswer(ctx, user.ID, id, correct); err != nil {
+			return err
+		}
+		wod.Correct = sql.NullBool{Bool: correct, Valid: true}
+	}
+
+	word := models.Word{Word: wod.Word, Translation: wod.Translation}
+	var deck *models.Deck
+	if wod.DeckID != 0 {
+		if deck, err = b.deckRepo.GetByID(ctx, wod.DeckID); err != nil {
+			return err
+		}
+		dw, err := b.deckRepo.GetWord(ctx, wod.DeckID, wod.WordID)
+		if err != nil {
+			return err
+		}
+		if dw != nil {
+			word = deckWordOfDay(*dw)
+		}
+	} else if w, err := b.wordRepo.GetByID(ctx, user.ID, int(wod.WordID)); err == nil {
+		word = *w
+	} else if !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+
+	// Without a keyboard the edit removes the quiz buttons
+	text := wordOfDayText(loc, word, deck, true, wod.Correct)
+	msg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
+	msg.ParseMode = tgbotapi.ModeHTML
+	return b.editMessage(msg)
+}
+
+// wordOfDayText renders the word of the day. While the quiz waits for an answer the
+// translation, the description and the examples are hidden, answer is null then.
+func wordOfDayText(loc locale.Locale, word models.Word, deck *models.Deck, quiz bool, answer sql.NullBool) *richText {
+	out := newRichText(tgbotapi.ModeHTML).Text("🌅 ").Bold(i18n.T(loc, "wod.name"))
+	if deck != nil {
+		out.Text(i18n.T(loc, "wod.of_deck", deck.Name))
+	}
+	out.Text("\n\n🇬🇧 ").Bold(word.Word)
+	if word.VerbForms != "" {
+		out.Text("\n" + i18n.T(loc, "word.forms", word.VerbForms))
+	}
+	if word.Synonyms != "" {
+		out.Text("\n" + i18n.T(loc, "word.synonyms", word.Synonyms))
+	}
+	if word.Antonyms != "" {
+		out.Text("\n" + i18n.T(loc, "word.antonyms", word.Antonyms))
+	}
+
+	if quiz && !answer.Valid {
+		return out.Text(i18n.T(loc, "wod.question_prefix")).Bold(word.Word).Text("?")
+	}
+
+	out.Text("\n🇷🇺 " + word.Translation)
+	if word.Description != "" {
+		out.Text("\n\n💡 " + word.Description)
+	}
+	if word.Examples != "" {
+		out.Text("\n" + i18n.T(loc, "word.examples", word.Examples))
+	}
+	if answer.Valid {
+		if answer.Bool {
+			out.Text(i18n.T(loc, "wod.right"))
+		} else {
+			out.Text(i18n.T(loc, "wod.wrong", word.Translation))
+		}
+	}
+	return out.Text(i18n.T(loc, "wod.settings"))
+}
@@ -0,0 +1,135 @@
+// Package cache keeps the results of frequent lookups, such as the user of every update,
+// so they don't go to the database each time
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+)
+
+// Cache stores values by string key. Keys and the context are all a shared store such as
+// Redis needs, so several instances of the bot can later share one cache by implementing it.
+type Cache[V any] interface {
+	// Get returns the value stored under key and whether there was one
+	Get(ctx context.Context, key string) (V, bool)
+	// Set stores the value under key, replacing the old one
+	Set(ctx context.Context, key string, value V)
+	// Delete removes the values of the keys, if any
+	Delete(ctx context.Context, keys ...string)
+}
+
+// Nop stores nothing, every Get misses. It is used when caching is turned off.
+type Nop[V any] struct{}
+
+// Get always misses
+func (Nop[V]) Get(context.Context, string) (V, bool) {
+	var zero V
+	return zero, false
+}
+
+// Set does nothing
+func (Nop[V]) Set(context.Context, string, V) {}
+
+// Delete does nothing
+func (Nop[V]) Delete(context.Context, ...string) {}
+
+// LRU is an in-process cache of up to a fixed number of values. Values expire ttl after they
+// were stored, and when it is full the least recently used one makes room for a new one.
+// It is safe for concurrent use.
+type LRU[V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	clock    clock.Clock
+	order    *list.List // of *entry[V], the most recently used first
+	entries  map[string]*list.Element
+}
+
+// entry is a stored value with the time it expires at
+type entry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+// NewLRU creates a cache of up to capacity values that expire after ttl
+func NewLRU[V any](capacity int, ttl time.Duration) *LRU[V] {
+	return NewLRUWithClock[V](capacity, ttl, clock.System{})
+}
+
+// NewLRUWithClock creates a cache that reads the current time from c
+func NewLRUWithClock[V any](capacity int, ttl time.Duration, c clock.Clock) *LRU[V] {
+	return &LRU[V]{
+		capacity: max(capacity, 1),
+		ttl:      ttl,
+		clock:    c,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored under key unless it has expired
+func (c *LRU[V]) Get(_ context.Context, key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	e := element.Value.(*entry[V])
+	if !c.clock.Now().Before(e.expires) {
+		c.remove(element)
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return e.value, true
+}
+
+// Set stores the value under key, evicting the least recently used value when the cache is full
+func (c *LRU[V]) Set(_ context.Context, key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.clock.Now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry[V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		c.remove(c.order.Back())
+	}
+	c.entries[key] = c.order.PushFront(&entry[V]{key: key, value: value, expires: expires})
+}
+
+// Delete removes the values of the keys
+func (c *LRU[V]) Delete(_ context.Context, keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := c.entries[key]; ok {
+			c.remove(element)
+		}
+	}
+}
+
+// Len returns how many values are stored, the expired ones not yet evicted included
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU[V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry[V]).key)
+}
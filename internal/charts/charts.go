@@ -0,0 +1,183 @@
+// Package charts draws the statistics charts the bot sends as PNG pictures
+package charts
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+
+	chart "github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+// Size of every chart in pixels
+const (
+	width  = 1024
+	height = 512
+)
+
+// maxLabelLength shortens the topic names under the bars
+const maxLabelLength = 14
+
+var (
+	barColor  = drawing.ColorFromHex("4caf50")
+	lineColor = drawing.ColorFromHex("2196f3")
+	goalColor = drawing.ColorFromHex("ff9800")
+)
+
+// Point is a value at a moment of time
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Bar is a labeled value
+type Bar struct {
+	Label string
+	Value float64
+}
+
+// CompletionRate draws the share of completed repetitions over time as a line, in percent
+func CompletionRate(points []Point) ([]byte, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("completion rate chart needs at least 2 points, got %d", len(points))
+	}
+	series := chart.TimeSeries{
+		Name:  "Выполнено",
+		Style: chart.Style{StrokeColor: lineColor, StrokeWidth: 3, DotColor: lineColor, DotWidth: 4},
+	}
+	for _, p := range points {
+		series.XValues = append(series.XValues, p.Time)
+		series.YValues = append(series.YValues, p.Value)
+	}
+
+	graph := chart.Chart{
+		Title:      "Доля выполненных повторений по неделям",
+		Width:      width,
+		Height:     height,
+		Background: chart.Style{Padding: chart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20}},
+		XAxis:      chart.XAxis{ValueFormatter: chart.TimeValueFormatterWithFormat("02.01")},
+		YAxis:      chart.YAxis{Range: &chart.ContinuousRange{Min: 0, Max: 100}, Ticks: percentTicks},
+		Series:     []chart.Series{series},
+	}
+	return render(graph)
+}
+
+// ReviewsPerDay draws the reviews of each day as a filled line with the daily goal as an orange
+// line, a goal of 0 draws no goal line
+func ReviewsPerDay(points []Point, goal int) ([]byte, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("reviews chart needs at least 2 days, got %d", len(points))
+	}
+	top := max(float64(goal), 1)
+	reviews := chart.TimeSeries{
+		Name:  "Повторения",
+		Style: chart.Style{StrokeColor: barColor, StrokeWidth: 2, FillColor: barColor.WithAlpha(96)},
+	}
+	for _, p := range points {
+		top = max(top, p.Value)
+		reviews.XValues = append(reviews.XValues, p.Time)
+		reviews.YValues = append(reviews.YValues, p.Value)
+	}
+
+	ticks := countTicks(top)
+	graph := chart.Chart{
+		Title:      "Повторения по дням",
+		Width:      width,
+		Height:     height,
+		Background: chart.Style{Padding: chart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20}},
+		XAxis:      chart.XAxis{ValueFormatter: chart.TimeValueFormatterWithFormat("02.01")},
+		YAxis:      chart.YAxis{Range: &chart.ContinuousRange{Min: 0, Max: ticks[len(ticks)-1].Value}, Ticks: ticks},
+		Series:     []chart.Series{reviews},
+	}
+	if goal > 0 {
+		first, last := points[0].Time, points[len(points)-1].Time
+		graph.Series = append(graph.Series, chart.TimeSeries{
+			Name:    "Цель",
+			Style:   chart.Style{StrokeColor: goalColor, StrokeWidth: 2, StrokeDashArray: []float64{6, 4}},
+			XValues: []time.Time{first, last},
+			YValues: []float64{float64(goal), float64(goal)},
+		})
+	}
+	return render(graph)
+}
+
+// TopicProgress draws the completion of each topic as bars, in percent
+func TopicProgress(bars []Bar) ([]byte, error) {
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("topic progress chart needs at least one topic")
+	}
+	values := make([]chart.Value, len(bars))
+	for i, bar := range bars {
+		values[i] = chart.Value{
+			Label: shorten(bar.Label),
+			Value: bar.Value,
+			Style: chart.Style{FillColor: barColor, StrokeColor: barColor},
+		}
+	}
+
+	// Long rows of labels are turned to fit, which takes more room below the bars
+	xAxis := chart.Style{}
+	padding := chart.Box{Top: 50, Left: 20, Right: 20, Bottom: 40}
+	if len(bars) > 6 {
+		xAxis.TextRotationDegrees = 45
+		padding.Bottom = 70
+	}
+	graph := chart.BarChart{
+		Title:      "Выполнено по темам",
+		Width:      width,
+		Height:     height,
+		BarWidth:   min(80, (width-120)/len(bars)*3/4),
+		Background: chart.Style{Padding: padding},
+		XAxis:      xAxis,
+		YAxis:      chart.YAxis{Range: &chart.ContinuousRange{Min: 0, Max: 100}, Ticks: percentTicks},
+		Bars:       values,
+	}
+	return renderBars(graph)
+}
+
+// countTicks splits a count axis from 0 to at least top into about 5 whole steps
+func countTicks(top float64) []chart.Tick {
+	step := max(int(math.Ceil(top/5)), 1)
+	var ticks []chart.Tick
+	for v := 0; float64(v) < top+float64(step); v += step {
+		ticks = append(ticks, chart.Tick{Value: float64(v), Label: fmt.Sprintf("%d", v)})
+	}
+	return ticks
+}
+
+// percentTicks are the ticks of the percent axes
+var percentTicks = []chart.Tick{
+	{Value: 0, Label: "0%"},
+	{Value: 20, Label: "20%"},
+	{Value: 40, Label: "40%"},
+	{Value: 60, Label: "60%"},
+	{Value: 80, Label: "80%"},
+	{Value: 100, Label: "100%"},
+}
+
+// shorten cuts a label down to maxLabelLength characters
+func shorten(label string) string {
+	runes := []rune(label)
+	if len(runes) <= maxLabelLength {
+		return label
+	}
+	return string(runes[:maxLabelLength-1]) + "…"
+}
+
+func render(graph chart.Chart) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := graph.Render(chart.PNG, &buf); err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func renderBars(graph chart.BarChart) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := graph.Render(chart.PNG, &buf); err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/pkg/models"
+)
+
+// dayLayout formats the days of daily_activity
+const dayLayout = "2006-01-02"
+
+// ActivityRepository handles the daily review counters behind the daily goal and the streak
+type ActivityRepository struct {
+	clock clock.Clock
+}
+
+// NewActivityRepository creates a new repository instance
+func NewActivityRepository() *ActivityRepository {
+	return NewActivityRepositoryWithClock(clock.System{})
+}
+
+// NewActivityRepositoryWithClock creates a repository that reads the current time from c
+func NewActivityRepositoryWithClock(c clock.Clock) *ActivityRepository {
+	return &ActivityRepository{clock: c}
+}
+
+// RecordReview counts one review for the user today, remembering the user's current daily goal
+func (r *ActivityRepository) RecordReview(ctx context.Context, userID int64) error {
+	_, err := DB.ExecContext(ctx, `
+		INSERT INTO daily_activity (user_id, day, reviews, goal)
+		SELECT id, ?, 1, COALESCE(daily_goal, 0) FROM users WHERE id = ?
+		ON CONFLICT (user_id, day) DO UPDATE SET
+			reviews = daily_activity.reviews + 1,
+			goal = excluded.goal
+	`, r.clock.Now().Format(dayLayout), userID)
+	if err != nil {
+		return fmt.Errorf("failed to record review: %w", err)
+	}
+	return nil
+}
+
+// GetToday returns the user's activity today, with no reviews if there is none yet
+func (r *ActivityRepository) GetToday(ctx context.Context, userID int64) (*models.DailyActivity, error) {
+	today := r.clock.Now().Format(dayLayout)
+	activity := models.DailyActivity{UserID: userID, Day: today}
+	err := readDB.GetContext(ctx, &activity, `
+		SELECT user_id, day, reviews, goal, protected
+		FROM daily_activity
+		WHERE user_id = ? AND day = ?
+	`, userID, today)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get today's activity: %w", err)
+	}
+	return &activity, nil
+}
+
+// GetStreak returns the number of consecutive days, ending today or yesterday, on which the user
+// reached the daily goal. Protected days keep the streak going without adding to it.
+func (r *ActivityRepository) GetStreak(ctx context.Context, userID int64) (int, error) {
+	var activity []models.DailyActivity
+	err := readDB.SelectContext(ctx, &activity, `
+		SELECT user_id, day, reviews, goal, protected
+		FROM daily_activity
+		WHERE user_id = ?
+	`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get daily activity: %w", err)
+	}
+	return streak(activity, r.clock.Now()), nil
+}
+
+// GetSince returns the user's activity from the day of since on, oldest first.
+// Days without reviews have no rows.
+func (r *ActivityRepository) GetSince(ctx context.Context, userID int64, since time.Time) ([]models.DailyActivity, error) {
+	var activity []models.DailyActivity
+	err := readDB.SelectContext(ctx, &activity, `
+		SELECT user_id, day, reviews, goal, protected
+		FROM daily_activity
+		WHERE user_id = ? AND day >= ?
+		ORDER BY day ASC
+	`, userID, since.Format(dayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily activity: %w", err)
+	}
+	return activity, nil
+}
+
+// streak counts the days with the goal met back from today. Today doesn't break the streak
+// before it is over.
+func streak(activity []models.DailyActivity, now time.Time) int {
+	days := make(map[string]models.DailyActivity, len(activity))
+	for _, a := range activity {
+		days[a.Day] = a
+	}
+
+	day := now
+	if !days[day.Format(dayLayout)].GoalMet() {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	count := 0
+	for {
+		a, ok := days[day.Format(dayLayout)]
+		if !ok {
+			return count
+		}
+		if a.GoalMet() {
+			count++
+		} else if !a.Protected {
+			return count
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+}
+
+// ProtectIdleDay marks today as protected for the users with a streak going who have nothing
+// due for review, so a day without anything to repeat doesn't break their streak.
+// Returns the number of users whose day was protected.
+func (r *ActivityRepository) ProtectIdleDay(ctx context.Context) (int, error) {
+	now := r.clock.Now()
+	result, err := DB.ExecContext(ctx, `
+		INSERT INTO daily_activity (user_id, day, reviews, goal, protected)
+		SELECT u.id, ?, 0, COALESCE(u.daily_goal, 0), true
+		FROM users u
+		WHERE EXISTS (
+			SELECT 1 FROM daily_activity da WHERE da.user_id = u.id AND da.day = ?
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM repetitions r
+			JOIN topics t ON t.id = r.topic_id
+			WHERE r.user_id = u.id AND r.completed = false AND r.next_review_date <= ? AND t.archived = false
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM user_progress up
+			JOIN words w ON w.id = up.word_id AND w.user_id = up.user_id
+			JOIN topics t ON t.id = w.topic_id
+			LEFT JOIN user_configs c ON c.user_id = up.user_id
+			WHERE up.user_id = u.id AND up.is_learned = false AND up.next_review_date <= ? AND `+cardDirectionOn+`
+		)
+		ON CONFLICT (user_id, day) DO UPDATE SET protected = true
+	`, now.Format(dayLayout), now.AddDate(0, 0, -1).Format(dayLayout), now, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to protect idle day: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to protect idle day: %w", err)
+	}
+	return int(n), nil
+}
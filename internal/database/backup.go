@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrBackupUnsupported is returned for Postgres, whose own pg_dump makes better backups
+var ErrBackupUnsupported = errors.New("backups are supported for SQLite only, use pg_dump for Postgres")
+
+// restoreSuffix is added to the name of the database file a restore replaces
+const restoreSuffix = ".before-restore"
+
+// Backup writes a consistent copy of the open SQLite database to path, which must not exist yet.
+// The bot keeps working meanwhile: VACUUM INTO reads a snapshot and leaves the WAL alone.
+func Backup(ctx context.Context, path string) error {
+	if isPostgres() {
+		return ErrBackupUnsupported
+	}
+	if _, err := DB.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the SQLite database selected by DATABASE_URL or DB_PATH with the backup at
+// backupPath. The bot must be stopped, and the database must not be open. The backup is checked
+// first, and the replaced database is kept next to it with the .before-restore suffix. Pending
+// migrations of an older backup are applied on the next start.
+func Restore(ctx context.Context, backupPath string) error {
+	driverName, dsn, err := parseDatabaseURL(os.Getenv("DATABASE_URL"), os.Getenv("DB_PATH"))
+	if err != nil {
+		return err
+	}
+	if driverName == driverPostgres {
+		return ErrBackupUnsupported
+	}
+	dbPath := strings.TrimPrefix(dsn, "file:")
+	if i := strings.Index(dbPath, "?"); i >= 0 {
+		dbPath = dbPath[:i]
+	}
+	if sqliteInMemory(dsn) {
+		return fmt.Errorf("cannot restore an in-memory database")
+	}
+
+	if err := checkBackup(ctx, backupPath); err != nil {
+		return err
+	}
+
+	restoring := dbPath + ".restoring"
+	if err := copyFile(backupPath, restoring); err != nil {
+		return fmt.Errorf("failed to copy backup: %w", err)
+	}
+	defer os.Remove(restoring)
+
+	if _, err := os.Stat(dbPath); err == nil {
+		// The replaced database keeps what only its WAL had
+		if err := checkpoint(ctx, dbPath); err != nil {
+			return err
+		}
+		if err := os.Rename(dbPath, dbPath+restoreSuffix); err != nil {
+			return fmt.Errorf("failed to keep current database: %w", err)
+		}
+		slog.Info("current database kept", "path", dbPath+restoreSuffix)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", dbPath+suffix, err)
+		}
+	}
+	if err := os.Rename(restoring, dbPath); err != nil {
+		return fmt.Errorf("failed to put backup in place: %w", err)
+	}
+	return nil
+}
+
+// checkBackup makes sure the file is an intact SQLite database of the bot
+func checkBackup(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	db, err := sqlx.Open(driverSQLite, "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.GetContext(ctx, &result, "PRAGMA integrity_check"); err != nil {
+		return fmt.Errorf("backup is not a SQLite database: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("backup is damaged: %s", result)
+	}
+	var users int
+	if err := db.GetContext(ctx, &users, "SELECT COUNT(*) FROM users"); err != nil {
+		return fmt.Errorf("backup is not a database of the bot: %w", err)
+	}
+	return nil
+}
+
+// checkpoint moves everything from the WAL of the SQLite file into the file itself
+func checkpoint(ctx context.Context, path string) error {
+	db, err := sqlx.Open(driverSQLite, path)
+	if err != nil {
+		return fmt.Errorf("failed to open current database: %w", err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("failed to checkpoint current database: %w", err)
+	}
+	return nil
+}
+
+// copyFile copies the file at src to dst, replacing dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+)
+
+// CalendarRepository keeps the secret tokens of the users' calendar feeds. Whoever knows the
+// token can read the feed, so a user can replace it to cut off a leaked link.
+type CalendarRepository struct{}
+
+// NewCalendarRepository creates a new repository instance
+func NewCalendarRepository() *CalendarRepository {
+	return &CalendarRepository{}
+}
+
+// Token returns the token of the user's feed, creating it on first use
+func (r *CalendarRepository) Token(ctx context.Context, userID int64) (string, error) {
+	token, err := newCalendarToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = DB.ExecContext(ctx, `
+		INSERT INTO calendar_feeds (user_id, token, created_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO NOTHING
+	`, userID, token)
+	if err != nil {
+		return "", fmt.Errorf("failed to create calendar token: %w", err)
+	}
+
+	// Read from the writer: the token may have been created just now
+	if err := DB.GetContext(ctx, &token, "SELECT token FROM calendar_feeds WHERE user_id = ?", userID); err != nil {
+		return "", fmt.Errorf("failed to get calendar token: %w", err)
+	}
+	return token, nil
+}
+
+// ResetToken gives the user's feed a new token, the old link stops working
+func (r *CalendarRepository) ResetToken(ctx context.Context, userID int64) (string, error) {
+	token, err := newCalendarToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = DB.ExecContext(ctx, `
+		INSERT INTO calendar_feeds (user_id, token, created_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE SET token = excluded.token, created_at = excluded.created_at
+	`, userID, token)
+	if err != nil {
+		return "", fmt.Errorf("failed to reset calendar token: %w", err)
+	}
+	return token, nil
+}
+
+// GetTelegramID returns the Telegram ID of the user whose feed has the token, ErrNotFound for an
+// unknown one
+func (r *CalendarRepository) GetTelegramID(ctx context.Context, token string) (int64, error) {
+	var telegramID int64
+	err := readDB.GetContext(ctx, &telegramID, `
+		SELECT u.telegram_id
+		FROM calendar_feeds f
+		JOIN users u ON u.id = f.user_id
+		WHERE f.token = ?
+	`, token)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("calendar feed %w", ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return telegramID, nil
+}
+
+// newCalendarToken returns a random token that can't be guessed
+func newCalendarToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -1,129 +1,234 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"database/sql"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/example/engbot/internal/database/migrations"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 // DB is the global database connection
 var DB *sqlx.DB
 
-// Connect establishes a connection to the database
+// readDB serves the queries that only read. For a SQLite file it is a pool of read-only
+// connections, so reads go on in parallel with each other and with the single writer
+// connection of DB; otherwise it is DB itself.
+var readDB *sqlx.DB
+
+// SQLite connection settings
+const (
+	sqliteBusyTimeout   = 5000 // milliseconds a connection waits for a lock before failing
+	defaultSQLiteReader = 4    // read-only connections unless DB_READ_CONNS says otherwise
+)
+
+// Connect opens the database, applies pending schema migrations and prepares the search index
 func Connect() error {
-	// Create data directory if it doesn't exist
-	dataDir := "data"
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %v", err)
+	if err := Open(); err != nil {
+		return err
+	}
+
+	if _, err := migrations.NewRunner(DB).Up(context.Background()); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
 	}
+	if err := setupSearch(context.Background()); err != nil {
+		return err
+	}
+	slog.Info("database schema initialized")
+	return nil
+}
 
-	// Open database connection
-	dbPath := filepath.Join(dataDir, "engbot.db")
-	db, err := sqlx.Connect("sqlite3", dbPath)
+// Open establishes a connection to the database selected by DATABASE_URL
+// (Postgres or SQLite), defaulting to the SQLite file from DB_PATH or data/engbot.db.
+// It doesn't touch the schema, see Connect and Migrate.
+func Open() error {
+	driverName, dsn, err := parseDatabaseURL(os.Getenv("DATABASE_URL"), os.Getenv("DB_PATH"))
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %v", err)
+		return err
+	}
+
+	var db *sqlx.DB
+	var reader *sqlx.DB
+	if driverName == driverPostgres {
+		db, err = openPostgres(dsn)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %v", err)
+		}
+	} else {
+		db, err = connectSQLite(dsn)
+		if err != nil {
+			return err
+		}
+		if !sqliteInMemory(dsn) {
+			reader, err = connectSQLiteReader(dsn)
+			if err != nil {
+				db.Close()
+				return err
+			}
+		}
+	}
+
+	DB = db
+	readDB = db
+	if reader != nil {
+		readDB = reader
+	}
+	if err := setupCache(); err != nil {
+		Close()
+		return err
+	}
+	slog.Info("connected to database", "driver", driverName)
+	return nil
+}
+
+// Migrate runs a migration command against the open database: "up" applies all
+// pending migrations, "down" rolls back the last steps ones, "status" lists them
+func Migrate(ctx context.Context, command string, steps int) error {
+	runner := migrations.NewRunner(DB)
+	switch command {
+	case "up":
+		count, err := runner.Up(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("migrations applied", "count", count)
+	case "down":
+		count, err := runner.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		slog.Info("migrations rolled back", "count", count)
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			slog.Info("migration", "version", s.Version, "name", s.Name, "state", state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+	return nil
+}
+
+// connectSQLite opens the SQLite file at dbPath, creating its directory if needed
+func connectSQLite(dbPath string) (*sqlx.DB, error) {
+	// Create data directory if it doesn't exist
+	if dir := filepath.Dir(dbPath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create data directory: %v", err)
+		}
 	}
 
-	// Enable foreign keys
-	_, err = db.Exec("PRAGMA foreign_keys = ON")
+	// Open database connection. WAL lets the readers work while the writer writes, and the
+	// transactions take the write lock right away instead of failing when they first write.
+	db, err := openSQLite(sqliteDSN(dbPath,
+		"_foreign_keys=on",
+		"_journal_mode=WAL",
+		"_synchronous=NORMAL",
+		"_txlock=immediate",
+		fmt.Sprintf("_busy_timeout=%d", sqliteBusyTimeout),
+	))
 	if err != nil {
-		return fmt.Errorf("failed to enable foreign keys: %v", err)
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
 	// Set connection pool settings
 	db.SetMaxOpenConns(1) // SQLite doesn't support multiple writers
 	db.SetMaxIdleConns(1)
 
-	DB = db
+	return db, nil
+}
+
+// connectSQLiteReader opens the pool of read-only connections to the SQLite file at dbPath,
+// sized by DB_READ_CONNS
+func connectSQLiteReader(dbPath string) (*sqlx.DB, error) {
+	conns := defaultSQLiteReader
+	if value := os.Getenv("DB_READ_CONNS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid DB_READ_CONNS %q: expected a positive number", value)
+		}
+		conns = n
+	}
+
+	db, err := openSQLite(sqliteDSN(dbPath,
+		"_foreign_keys=on",
+		"_query_only=true",
+		fmt.Sprintf("_busy_timeout=%d", sqliteBusyTimeout),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read connections: %v", err)
+	}
+
+	db.SetMaxOpenConns(conns)
+	db.SetMaxIdleConns(conns)
+	return db, nil
+}
 
-	// Initialize schema
-	return initializeSchema()
+// openSQLite opens a SQLite pool whose connections cache their prepared statements
+func openSQLite(dsn string) (*sqlx.DB, error) {
+	db := sqlx.NewDb(sql.OpenDB(sqliteConnector{driver: &sqlite3.SQLiteDriver{}, dsn: dsn}), driverSQLite)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
 }
 
-// Close closes the database connection
+// sqliteDSN adds the connection parameters to the SQLite path, keeping the ones it already has
+func sqliteDSN(dbPath string, params ...string) string {
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	for _, param := range params {
+		if name := param[:strings.Index(param, "=")+1]; strings.Contains(dbPath, name) {
+			continue
+		}
+		dbPath += separator + param
+		separator = "&"
+	}
+	return dbPath
+}
+
+// sqliteInMemory reports whether the SQLite database lives in memory, where every connection
+// gets a database of its own and a separate read pool would see none of the data
+func sqliteInMemory(dsn string) bool {
+	return strings.HasPrefix(dsn, ":memory:") || strings.Contains(dsn, "mode=memory")
+}
+
+// Close closes the database connections
 func Close() error {
+	if readDB != nil && readDB != DB {
+		readDB.Close()
+	}
 	if DB != nil {
 		return DB.Close()
 	}
 	return nil
 }
 
-// initializeSchema creates necessary tables if they don't exist
-func initializeSchema() error {
-	// Create users table
-	_, err := DB.Exec(`
-		CREATE TABLE IF NOT EXISTS users (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			telegram_id INTEGER UNIQUE NOT NULL,
-			username TEXT,
-			first_name TEXT,
-			last_name TEXT,
-			notification_enabled BOOLEAN DEFAULT true,
-			notification_hour INTEGER DEFAULT 9,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create users table: %v", err)
-	}
-
-	// Create topics table
-	_, err = DB.Exec(`
-		CREATE TABLE IF NOT EXISTS topics (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			user_id INTEGER NOT NULL,
-			name TEXT NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (user_id) REFERENCES users(id)
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create topics table: %v", err)
-	}
-
-	// Create repetitions table
-	_, err = DB.Exec(`
-		CREATE TABLE IF NOT EXISTS repetitions (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			user_id INTEGER NOT NULL,
-			topic_id INTEGER NOT NULL,
-			repetition_number INTEGER NOT NULL,
-			completed BOOLEAN DEFAULT false,
-			next_review_date TIMESTAMP NOT NULL,
-			last_review_date TIMESTAMP,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (user_id) REFERENCES users(id),
-			FOREIGN KEY (topic_id) REFERENCES topics(id)
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create repetitions table: %v", err)
-	}
-
-	// Create statistics table
-	_, err = DB.Exec(`
-		CREATE TABLE IF NOT EXISTS statistics (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			user_id INTEGER NOT NULL,
-			topic_id INTEGER NOT NULL,
-			total_repetitions INTEGER DEFAULT 0,
-			completed_repetitions INTEGER DEFAULT 0,
-			FOREIGN KEY (user_id) REFERENCES users(id),
-			FOREIGN KEY (topic_id) REFERENCES topics(id)
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create statistics table: %v", err)
+// Ping checks that the database connection is alive
+func Ping(ctx context.Context) error {
+	if DB == nil {
+		return fmt.Errorf("database is not connected")
+	}
+	if err := DB.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
 	}
-
-	log.Println("Database schema initialized successfully")
 	return nil
 }
 
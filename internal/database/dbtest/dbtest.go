@@ -0,0 +1,69 @@
+// Package dbtest gives tests a fresh database with the current schema and the records they
+// need to start from
+package dbtest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/pkg/models"
+)
+
+// Open connects the database package to a new SQLite file in the test's temporary directory,
+// migrates it and closes it when the test ends. Tests using it share the global connection,
+// so they must not run in parallel.
+func Open(t testing.TB) {
+	t.Helper()
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "test.db"))
+	if err := database.Connect(); err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+}
+
+// User creates a user with the Telegram ID and reminders turned on
+func User(t testing.TB, telegramID int64) *models.User {
+	t.Helper()
+	user := &models.User{
+		TelegramID:          telegramID,
+		FirstName:           "Test",
+		NotificationEnabled: true,
+		NotificationHour:    9,
+	}
+	if err := database.NewUserRepository().Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+// Topic creates the user's topic with its statistics and the repetition with the number, due
+// at nextReview, the way a new topic is created
+func Topic(t testing.TB, userID int64, name string, number int, nextReview time.Time) *models.Topic {
+	t.Helper()
+	ctx := context.Background()
+	topic := &models.Topic{UserID: userID, Name: name, Difficulty: 3}
+	err := database.WithTx(ctx, func(tx *database.Tx) error {
+		if err := tx.CreateTopic(ctx, topic); err != nil {
+			return err
+		}
+		if err := tx.CreateStatistics(ctx, &models.Statistics{UserID: userID, TopicID: topic.ID}); err != nil {
+			return err
+		}
+		return tx.CreateRepetition(ctx, &models.Repetition{
+			UserID:           userID,
+			TopicID:          topic.ID,
+			RepetitionNumber: number,
+			NextReviewDate:   nextReview,
+			CreatedAt:        nextReview,
+			UpdatedAt:        nextReview,
+		})
+	})
+	if err != nil {
+		t.Fatalf("failed to create topic %q: %v", name, err)
+	}
+	return topic
+}
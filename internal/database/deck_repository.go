@@ -0,0 +1,201 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/example/engbot/pkg/models"
+)
+
+// DeckRepository handles database operations for shared decks and subscriptions
+type DeckRepository struct{}
+
+// NewDeckRepository creates a new repository instance
+func NewDeckRepository() *DeckRepository {
+	return &DeckRepository{}
+}
+
+// deckColumns selects a deck with the number of its words
+const deckColumns = `
+	SELECT d.id, d.name, d.description, d.created_at, d.updated_at,
+		(SELECT COUNT(*) FROM deck_words dw WHERE dw.deck_id = d.id) AS word_count
+	FROM decks d
+`
+
+// GetAll returns all decks ordered by name
+func (r *DeckRepository) GetAll(ctx context.Context) ([]models.Deck, error) {
+	var decks []models.Deck
+	if err := readDB.SelectContext(ctx, &decks, deckColumns+" ORDER BY d.name"); err != nil {
+		return nil, fmt.Errorf("failed to get decks: %w", err)
+	}
+	return decks, nil
+}
+
+// GetByID returns a deck by its ID, or nil if it doesn't exist
+func (r *DeckRepository) GetByID(ctx context.Context, deckID int64) (*models.Deck, error) {
+	var deck models.Deck
+	err := readDB.GetContext(ctx, &deck, deckColumns+" WHERE d.id = ?", deckID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deck: %w", err)
+	}
+	return &deck, nil
+}
+
+// GetWords returns up to limit words of the deck in alphabetical order, all of them if limit is 0
+func (r *DeckRepository) GetWords(ctx context.Context, deckID int64, limit int) ([]models.DeckWord, error) {
+	query := `
+		SELECT id, deck_id, word, translation, description, examples, verb_forms
+		FROM deck_words
+		WHERE deck_id = ?
+		ORDER BY word
+	`
+	args := []interface{}{deckID}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	var words []models.DeckWord
+	if err := readDB.SelectContext(ctx, &words, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get deck words: %w", err)
+	}
+	return words, nil
+}
+
+// GetWord returns a word of the deck by its ID, or nil if it doesn't exist
+func (r *DeckRepository) GetWord(ctx context.Context, deckID, wordID int64) (*models.DeckWord, error) {
+	var word models.DeckWord
+	err := readDB.GetContext(ctx, &word, `
+		SELECT id, deck_id, word, translation, description, examples, verb_forms
+		FROM deck_words
+		WHERE id = ? AND deck_id = ?
+	`, wordID, deckID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deck word: %w", err)
+	}
+	return &word, nil
+}
+
+// GetSubscribedIDs returns the IDs of the decks the user is subscribed to
+func (r *DeckRepository) GetSubscribedIDs(ctx context.Context, userID int64) (map[int64]bool, error) {
+	var ids []int64
+	if err := readDB.SelectContext(ctx, &ids, "SELECT deck_id FROM deck_subscriptions WHERE user_id = ?", userID); err != nil {
+		return nil, fmt.Errorf("failed to get deck subscriptions: %w", err)
+	}
+	subscribed := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		subscribed[id] = true
+	}
+	return subscribed, nil
+}
+
+// Publish creates the deck named after the user's topic, or updates the deck with that name,
+// and adds the topic's words the deck doesn't have yet. An empty description keeps the old one.
+// Returns the deck and the number of words added.
+func (r *DeckRepository) Publish(ctx context.Context, userID, topicID int64, name, description string) (*models.Deck, int, error) {
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO decks (name, description, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (name) DO UPDATE SET
+			description = CASE WHEN excluded.description = '' THEN decks.description ELSE excluded.description END,
+			updated_at = CURRENT_TIMESTAMP
+	`, name, description, userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to save deck: %w", err)
+	}
+
+	var deckID int64
+	if err := tx.GetContext(ctx, &deckID, "SELECT id FROM decks WHERE name = ?", name); err != nil {
+		return nil, 0, fmt.Errorf("failed to get deck: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO deck_words (deck_id, word, kind, translation, description, examples, verb_forms)
+		SELECT ?, word, kind, translation, COALESCE(description, ''), COALESCE(examples, ''), COALESCE(verb_forms, '')
+		FROM words
+		WHERE topic_id = ? AND user_id = ?
+		ON CONFLICT (deck_id, word) DO NOTHING
+	`, deckID, topicID, userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to add deck words: %w", err)
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to add deck words: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	deck, err := r.GetByID(ctx, deckID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return deck, int(added), nil
+}
+
+// Subscribe subscribes the user to the deck: the deck's words are copied into the user's topic
+// and put into the user's flashcard review, due right away. Subscribing again adds the words
+// the deck got since. Returns the number of words added to the review.
+func (r *DeckRepository) Subscribe(ctx context.Context, userID, deckID, topicID int64) (int, error) {
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO deck_subscriptions (user_id, deck_id, created_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id, deck_id) DO NOTHING
+	`, userID, deckID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to subscribe to deck: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO words (word, kind, translation, description, examples, verb_forms, topic_id, user_id, created_at, updated_at)
+		SELECT word, kind, translation, description, examples, verb_forms, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		FROM deck_words
+		WHERE deck_id = ?
+		ON CONFLICT (word, topic_id) DO NOTHING
+	`, topicID, userID, deckID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy deck words: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO user_progress (user_id, word_id, next_review_date)
+		SELECT w.user_id, w.id, CURRENT_TIMESTAMP
+		FROM words w
+		JOIN deck_words dw ON dw.word = w.word
+		WHERE dw.deck_id = ? AND w.topic_id = ? AND w.user_id = ?
+		ON CONFLICT (user_id, word_id, direction) DO NOTHING
+	`, deckID, topicID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add deck words to review: %w", err)
+	}
+	seeded, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to add deck words to review: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return int(seeded), nil
+}
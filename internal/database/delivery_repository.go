@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+)
+
+// QueuedDelivery is a notification that failed to send and waits for another attempt
+type QueuedDelivery struct {
+	ID         int64  `db:"id"`
+	UserID     int64  `db:"user_id"`
+	TelegramID int64  `db:"telegram_id"`
+	Kind       string `db:"kind"`
+	// Message is the notification as the bot encoded it
+	Message   string    `db:"message"`
+	Attempts  int       `db:"attempts"`
+	LastError string    `db:"last_error"`
+	CreatedAt time.Time `db:"created_at"`
+	// Active is false once the user blocked the bot, see UserRepository.SetInactive
+	Active bool `db:"active"`
+}
+
+// UndeliverableUser is a user the bot failed to reach
+type UndeliverableUser struct {
+	TelegramID int64  `db:"telegram_id"`
+	Username   string `db:"username"`
+	FirstName  string `db:"first_name"`
+	// Blocked counts the deliveries in a row refused because the user blocked the bot
+	Blocked      int       `db:"blocked"`
+	LastError    string    `db:"last_error"`
+	LastFailedAt time.Time `db:"last_failed_at"`
+	// Disabled is set when the bot turned the user's notifications off
+	Disabled bool `db:"disabled"`
+	// Failed counts the notifications given up on after all the retries
+	Failed int `db:"failed"`
+	// Inactive is set while the user is marked inactive, see UserRepository.SetInactive
+	Inactive bool `db:"inactive"`
+}
+
+// DeliveryStats counts the notifications waiting for a retry and the users the bot can't reach
+type DeliveryStats struct {
+	Pending       int `db:"pending"`
+	Undeliverable int `db:"undeliverable"`
+}
+
+// DeliveryRepository keeps the notifications waiting to be sent again and the users the bot
+// failed to reach. A delivery that succeeds clears the user's failures.
+type DeliveryRepository struct {
+	clock clock.Clock
+}
+
+// NewDeliveryRepository creates a new repository instance
+func NewDeliveryRepository() *DeliveryRepository {
+	return NewDeliveryRepositoryWithClock(clock.System{})
+}
+
+// NewDeliveryRepositoryWithClock creates a repository that reads the current time from c
+func NewDeliveryRepositoryWithClock(c clock.Clock) *DeliveryRepository {
+	return &DeliveryRepository{clock: c}
+}
+
+// Enqueue queues the notification whose first attempt failed with errText for another one at next
+func (r *DeliveryRepository) Enqueue(ctx context.Context, userID int64, kind, message, errText string, next time.Time) error {
+	_, err := DB.ExecContext(ctx, `
+		INSERT INTO notification_queue (user_id, kind, message, attempts, last_error, next_attempt_at, created_at)
+		VALUES (?, ?, ?, 1, ?, ?, ?)
+	`, userID, kind, message, errText, next.UTC(), r.clock.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to queue notification: %w", err)
+	}
+	return nil
+}
+
+// Due returns up to limit queued notifications whose next attempt is due, the oldest first
+func (r *DeliveryRepository) Due(ctx context.Context, limit int) ([]QueuedDelivery, error) {
+	var deliveries []QueuedDelivery
+	err := DB.SelectContext(ctx, &deliveries, `
+		SELECT q.id, q.user_id, u.telegram_id, q.kind, q.message, q.attempts, q.last_error, q.created_at,
+			u.inactive_since IS NULL AS active
+		FROM notification_queue q
+		JOIN users u ON u.id = q.user_id
+		WHERE q.failed = false AND q.next_attempt_at <= ?
+		ORDER BY q.next_attempt_at
+		LIMIT ?
+	`, r.clock.Now().UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queued notifications: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Retry counts another failed attempt and schedules the next one
+func (r *DeliveryRepository) Retry(ctx context.Context, id int64, errText string, next time.Time) error {
+	_, err := DB.ExecContext(ctx, `
+		UPDATE notification_queue SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?
+		WHERE id = ?
+	`, errText, next.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule notification: %w", err)
+	}
+	return nil
+}
+
+// Postpone moves the next attempt without counting one, e.g. past the user's quiet hours
+func (r *DeliveryRepository) Postpone(ctx context.Context, id int64, next time.Time) error {
+	_, err := DB.ExecContext(ctx, "UPDATE notification_queue SET next_attempt_at = ? WHERE id = ?", next.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to postpone notification: %w", err)
+	}
+	return nil
+}
+
+// Fail gives up on the notification. It stays in the queue for the admin report until pruned.
+func (r *DeliveryRepository) Fail(ctx context.Context, id int64, errText string) error {
+	_, err := DB.ExecContext(ctx, "UPDATE notification_queue SET failed = true, last_error = ? WHERE id = ?", errText, id)
+	if err != nil {
+		return fmt.Errorf("failed to give up on notification: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the notification from the queue, once sent or no longer wanted
+func (r *DeliveryRepository) Delete(ctx context.Context, id int64) error {
+	if _, err := DB.ExecContext(ctx, "DELETE FROM notification_queue WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete queued notification: %w", err)
+	}
+	return nil
+}
+
+// RecordFailure notes that a notification to the user failed and returns how many deliveries
+// in a row the user has blocked, counting this one when blocked is set
+func (r *DeliveryRepository) RecordFailure(ctx context.Context, userID int64, errText string, blocked bool) (int, error) {
+	increment := 0
+	if blocked {
+		increment = 1
+	}
+	_, err := DB.ExecContext(ctx, `
+		INSERT INTO delivery_failures (user_id, blocked, last_error, last_failed_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			blocked = delivery_failures.blocked + excluded.blocked,
+			last_error = excluded.last_error,
+			last_failed_at = excluded.last_failed_at
+	`, userID, increment, errText, r.clock.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to record delivery failure: %w", err)
+	}
+
+	var count int
+	if err := DB.GetContext(ctx, &count, "SELECT blocked FROM delivery_failures WHERE user_id = ?", userID); err != nil {
+		return 0, fmt.Errorf("failed to get delivery failures: %w", err)
+	}
+	return count, nil
+}
+
+// MarkDisabled records that the bot turned the user's notifications off
+func (r *DeliveryRepository) MarkDisabled(ctx context.Context, userID int64) error {
+	if _, err := DB.ExecContext(ctx, "UPDATE delivery_failures SET disabled = true WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to mark notifications disabled: %w", err)
+	}
+	return nil
+}
+
+// DisabledByBot reports whether the bot turned the user's notifications off
+func (r *DeliveryRepository) DisabledByBot(ctx context.Context, userID int64) (bool, error) {
+	var disabled bool
+	err := DB.GetContext(ctx, &disabled, "SELECT disabled FROM delivery_failures WHERE user_id = ?", userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get delivery failures: %w", err)
+	}
+	return disabled, nil
+}
+
+// ClearFailures forgets the user's failures after a notification got through or the user turned
+// notifications back on
+func (r *DeliveryRepository) ClearFailures(ctx context.Context, userID int64) error {
+	if _, err := DB.ExecContext(ctx, "DELETE FROM delivery_failures WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to clear delivery failures: %w", err)
+	}
+	return nil
+}
+
+// GetUndeliverable returns up to limit users the bot failed to reach, the latest failures first
+func (r *DeliveryRepository) GetUndeliverable(ctx context.Context, limit int) ([]UndeliverableUser, error) {
+	var users []UndeliverableUser
+	err := readDB.SelectContext(ctx, &users, `
+		SELECT u.telegram_id, u.username, u.first_name, f.blocked, f.last_error, f.last_failed_at, f.disabled,
+			(SELECT COUNT(*) FROM notification_queue q WHERE q.user_id = f.user_id AND q.failed = true) AS failed,
+			u.inactive_since IS NOT NULL AS inactive
+		FROM delivery_failures f
+		JOIN users u ON u.id = f.user_id
+		ORDER BY f.last_failed_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get undeliverable users: %w", err)
+	}
+	return users, nil
+}
+
+// GetStats counts the notifications waiting for a retry and the users the bot can't reach
+func (r *DeliveryRepository) GetStats(ctx context.Context) (*DeliveryStats, error) {
+	var stats DeliveryStats
+	err := readDB.GetContext(ctx, &stats, `
+		SELECT
+			(SELECT COUNT(*) FROM notification_queue WHERE failed = false) AS pending,
+			(SELECT COUNT(*) FROM delivery_failures) AS undeliverable
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// Prune deletes the notifications given up on more than keepDays ago and returns how many
+func (r *DeliveryRepository) Prune(ctx context.Context, keepDays int) (int64, error) {
+	before := r.clock.Now().AddDate(0, 0, -keepDays).UTC()
+	result, err := DB.ExecContext(ctx, "DELETE FROM notification_queue WHERE failed = true AND created_at < ?", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune notification queue: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune notification queue: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Driver names as reported by DB.DriverName()
+const (
+	driverSQLite   = "sqlite3"
+	driverPostgres = "postgres"
+)
+
+// defaultSQLitePath is used when neither DATABASE_URL nor DB_PATH is set
+var defaultSQLitePath = filepath.Join("data", "engbot.db")
+
+// parseDatabaseURL picks the driver and DSN from DATABASE_URL:
+// postgres://... or postgresql://... connect to Postgres, sqlite://path or a
+// plain file path open SQLite. An empty URL falls back to sqlitePath.
+func parseDatabaseURL(rawURL, sqlitePath string) (driverName, dsn string, err error) {
+	if rawURL == "" {
+		if sqlitePath == "" {
+			sqlitePath = defaultSQLitePath
+		}
+		return driverSQLite, sqlitePath, nil
+	}
+
+	if !strings.Contains(rawURL, "://") {
+		return driverSQLite, rawURL, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid DATABASE_URL: %v", err)
+	}
+
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		return driverPostgres, rawURL, nil
+	case "sqlite", "sqlite3":
+		return driverSQLite, strings.TrimPrefix(rawURL, u.Scheme+"://"), nil
+	default:
+		return "", "", fmt.Errorf("unsupported DATABASE_URL scheme %q", u.Scheme)
+	}
+}
+
+// openPostgres connects to Postgres through a driver wrapper that rewrites the
+// "?" placeholders used across the repositories into "$1", "$2", ...
+func openPostgres(dsn string) (*sqlx.DB, error) {
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sqlx.NewDb(sql.OpenDB(rebindConnector{connector}), driverPostgres)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// isPostgres reports whether the current connection is a Postgres one
+func isPostgres() bool {
+	return DB != nil && DB.DriverName() == driverPostgres
+}
+
+// insertID runs an INSERT and returns the ID of the new row. Postgres has no
+// LastInsertId, so there the query gets a RETURNING clause instead.
+func insertID(ctx context.Context, db sqlx.ExtContext, query string, args ...interface{}) (int64, error) {
+	var id int64
+	if isPostgres() {
+		err := db.QueryRowxContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// rebindPlaceholders turns "?" placeholders into "$n", leaving string
+// literals, quoted identifiers and comments untouched
+func rebindPlaceholders(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	var quote rune
+	inComment := false
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case inComment:
+			if c == '\n' {
+				inComment = false
+			}
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			inComment = true
+		case c == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// rebindConnector wraps a driver.Connector so that every query is rebound
+type rebindConnector struct {
+	driver.Connector
+}
+
+func (c rebindConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rebindConn{conn}, nil
+}
+
+// rebindConn forwards to the wrapped connection after rebinding the query text
+type rebindConn struct {
+	driver.Conn
+}
+
+func (c *rebindConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(rebindPlaceholders(query))
+}
+
+func (c *rebindConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, rebindPlaceholders(query))
+	}
+	return c.Prepare(query)
+}
+
+func (c *rebindConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, rebindPlaceholders(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *rebindConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, rebindPlaceholders(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *rebindConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *rebindConn) CheckNamedValue(v *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+func (c *rebindConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *rebindConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
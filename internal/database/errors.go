@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrNotFound is returned when a record doesn't exist or belongs to another user
+var ErrNotFound = errors.New("record not found")
+
+// ErrTopicExists is returned when the user already has a topic with the same name,
+// ignoring case and extra spaces
+var ErrTopicExists = errors.New("topic already exists")
+
+// ErrTopicLimit is returned when creating a topic would take the user past their topic limit
+var ErrTopicLimit = errors.New("topic limit reached")
+
+// ErrAlreadyCompleted is returned when a repetition was completed before, e.g. by a second tap
+// on the same button
+var ErrAlreadyCompleted = errors.New("repetition already completed")
+
+// IsTransient reports whether err is a temporary database failure that is worth retrying,
+// such as a locked SQLite database, a Postgres serialization failure or an expired deadline
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "40", "08", "53": // transaction rollback, connection exception, insufficient resources
+			return true
+		}
+		return pqErr.Code == "57P03" // cannot_connect_now
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
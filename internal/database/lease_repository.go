@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+)
+
+// LeaseRepository hands out leases: a named right to do a job that only one of several bot
+// instances should do, such as running the scheduler. A lease ends when its holder releases it
+// or stops renewing it, so a crashed instance can't keep it. The instances' clocks should agree
+// to well under the length of a lease.
+type LeaseRepository struct {
+	clock clock.Clock
+}
+
+// NewLeaseRepository creates a new repository instance
+func NewLeaseRepository() *LeaseRepository {
+	return NewLeaseRepositoryWithClock(clock.System{})
+}
+
+// NewLeaseRepositoryWithClock creates a repository that reads the current time from c
+func NewLeaseRepositoryWithClock(c clock.Clock) *LeaseRepository {
+	return &LeaseRepository{clock: c}
+}
+
+// Acquire takes the lease for holder for ttl, or renews it if holder already has it, and
+// reports whether holder has it now. Another holder's lease is taken only once it has expired.
+func (r *LeaseRepository) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := r.clock.Now().UTC()
+	expires := now.Add(ttl)
+
+	result, err := DB.ExecContext(ctx, `
+		UPDATE leases SET holder = ?, expires_at = ?
+		WHERE name = ? AND (holder = ? OR expires_at < ?)
+	`, holder, expires, name, holder, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lease %s: %w", name, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows > 0 {
+		return true, nil
+	}
+
+	result, err = DB.ExecContext(ctx, `
+		INSERT INTO leases (name, holder, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO NOTHING
+	`, name, holder, expires)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	}
+	rows, err = result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// Release gives up the lease if holder has it, so another instance can take it right away
+func (r *LeaseRepository) Release(ctx context.Context, name, holder string) error {
+	_, err := DB.ExecContext(ctx, "DELETE FROM leases WHERE name = ? AND holder = ?", name, holder)
+	if err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/example/engbot/internal/cache"
+	"github.com/example/engbot/pkg/models"
+)
+
+// Caches of the lookups made on nearly every update: users by Telegram ID, the topics of a
+// user and user configs. Every change of a cached record drops it from its cache once the
+// change is committed; a read racing with a change may still store the old record, which then
+// lives until it expires. They cache nothing until setupCache turns them on.
+var (
+	userCache   cache.Cache[models.User]    = cache.Nop[models.User]{}
+	topicCache  cache.Cache[[]models.Topic] = cache.Nop[[]models.Topic]{}
+	configCache cache.Cache[*UserConfig]    = cache.Nop[*UserConfig]{} // nil for users without a config
+)
+
+// Cache settings unless CACHE_SIZE and CACHE_TTL say otherwise
+const (
+	defaultCacheSize = 10000
+	defaultCacheTTL  = 5 * time.Minute
+)
+
+// setupCache turns on the in-process caches, each keeping up to CACHE_SIZE records for
+// CACHE_TTL. CACHE_SIZE=0 turns caching off.
+func setupCache() error {
+	size := defaultCacheSize
+	if value := os.Getenv("CACHE_SIZE"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid CACHE_SIZE %q: expected a number of records", value)
+		}
+		size = n
+	}
+	ttl := defaultCacheTTL
+	if value := os.Getenv("CACHE_TTL"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid CACHE_TTL %q: expected a duration like 5m", value)
+		}
+		ttl = d
+	}
+
+	if size == 0 {
+		userCache = cache.Nop[models.User]{}
+		topicCache = cache.Nop[[]models.Topic]{}
+		configCache = cache.Nop[*UserConfig]{}
+		return nil
+	}
+	userCache = cache.NewLRU[models.User](size, ttl)
+	topicCache = cache.NewLRU[[]models.Topic](size, ttl)
+	configCache = cache.NewLRU[*UserConfig](size, ttl)
+	return nil
+}
+
+func userKey(telegramID int64) string {
+	return "user:" + strconv.FormatInt(telegramID, 10)
+}
+
+func topicsKey(userID int64) string {
+	return "topics:" + strconv.FormatInt(userID, 10)
+}
+
+func configKey(userID int64) string {
+	return "config:" + strconv.FormatInt(userID, 10)
+}
+
+// cachedTopics returns a copy of the cached topics of the user, so callers can change it
+func cachedTopics(ctx context.Context, userID int64) ([]models.Topic, bool) {
+	topics, ok := topicCache.Get(ctx, topicsKey(userID))
+	return slices.Clone(topics), ok
+}
+
+// invalidateUser drops the cached user with the Telegram ID
+func invalidateUser(ctx context.Context, telegramID int64) {
+	userCache.Delete(ctx, userKey(telegramID))
+}
+
+// invalidateTopics drops the cached topics of the user
+func invalidateTopics(ctx context.Context, userID int64) {
+	topicCache.Delete(ctx, topicsKey(userID))
+}
+
+// invalidateConfig drops the cached config of the user
+func invalidateConfig(ctx context.Context, userID int64) {
+	configCache.Delete(ctx, configKey(userID))
+}
@@ -0,0 +1,848 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// All lists the schema migrations in version order. Never edit an applied
+// migration, add a new one instead.
+var All = []Migration{
+	{
+		Version: 1,
+		Name:    "initial_schema",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS users (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				telegram_id INTEGER UNIQUE NOT NULL,
+				username TEXT,
+				first_name TEXT,
+				last_name TEXT,
+				notification_enabled BOOLEAN DEFAULT true,
+				notification_hour INTEGER DEFAULT 9,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS topics (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (user_id) REFERENCES users(id)
+			)`,
+			`CREATE TABLE IF NOT EXISTS repetitions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				topic_id INTEGER NOT NULL,
+				repetition_number INTEGER NOT NULL,
+				completed BOOLEAN DEFAULT false,
+				next_review_date TIMESTAMP NOT NULL,
+				last_review_date TIMESTAMP,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (user_id) REFERENCES users(id),
+				FOREIGN KEY (topic_id) REFERENCES topics(id)
+			)`,
+			`CREATE TABLE IF NOT EXISTS statistics (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				topic_id INTEGER NOT NULL,
+				total_repetitions INTEGER DEFAULT 0,
+				completed_repetitions INTEGER DEFAULT 0,
+				FOREIGN KEY (user_id) REFERENCES users(id),
+				FOREIGN KEY (topic_id) REFERENCES topics(id)
+			)`,
+		),
+		Down: exec(
+			"DROP TABLE IF EXISTS statistics",
+			"DROP TABLE IF EXISTS repetitions",
+			"DROP TABLE IF EXISTS topics",
+			"DROP TABLE IF EXISTS users",
+		),
+	},
+	{
+		Version: 2,
+		Name:    "topic_difficulty",
+		Up:      addColumns("topics", [2]string{"difficulty", "INTEGER DEFAULT 3"}),
+		Down:    dropColumns("topics", "difficulty"),
+	},
+	{
+		Version: 3,
+		Name:    "user_skip_first_repetitions",
+		Up:      addColumns("users", [2]string{"skip_first_repetitions", "INTEGER DEFAULT 0"}),
+		Down:    dropColumns("users", "skip_first_repetitions"),
+	},
+	{
+		Version: 4,
+		Name:    "words_and_user_progress",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS words (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				word TEXT NOT NULL,
+				translation TEXT NOT NULL,
+				description TEXT,
+				examples TEXT,
+				verb_forms TEXT,
+				topic_id INTEGER NOT NULL,
+				difficulty INTEGER DEFAULT 1,
+				pronunciation TEXT,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (topic_id) REFERENCES topics(id),
+				UNIQUE(word, topic_id)
+			)`,
+			`CREATE TABLE IF NOT EXISTS user_progress (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				word_id INTEGER NOT NULL,
+				easiness_factor REAL DEFAULT 2.5,
+				interval INTEGER DEFAULT 1,
+				repetitions INTEGER DEFAULT 0,
+				last_quality INTEGER DEFAULT 3,
+				consecutive_right INTEGER DEFAULT 0,
+				is_learned BOOLEAN DEFAULT FALSE,
+				last_review_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				next_review_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (user_id) REFERENCES users(id),
+				FOREIGN KEY (word_id) REFERENCES words(id),
+				UNIQUE(user_id, word_id)
+			)`,
+		),
+		Down: exec(
+			"DROP TABLE IF EXISTS user_progress",
+			"DROP TABLE IF EXISTS words",
+		),
+	},
+	{
+		Version: 5,
+		Name:    "user_is_admin",
+		Up:      addColumns("users", [2]string{"is_admin", "BOOLEAN DEFAULT false"}),
+		Down:    dropColumns("users", "is_admin"),
+	},
+	{
+		Version: 6,
+		Name:    "repetition_notes",
+		Up:      addColumns("repetitions", [2]string{"notes", "TEXT NOT NULL DEFAULT ''"}),
+		Down:    dropColumns("repetitions", "notes"),
+	},
+	{
+		Version: 7,
+		Name:    "topic_archive_mute_category",
+		Up: addColumns("topics",
+			[2]string{"archived", "BOOLEAN DEFAULT false"},
+			[2]string{"muted", "BOOLEAN DEFAULT false"},
+			[2]string{"category", "TEXT NOT NULL DEFAULT ''"},
+		),
+		Down: dropColumns("topics", "archived", "muted", "category"),
+	},
+	{
+		Version: 8,
+		Name:    "topic_sm2_state",
+		Up: addColumns("topics",
+			[2]string{"easiness_factor", "REAL DEFAULT 2.5"},
+			[2]string{"review_interval", "INTEGER DEFAULT 0"},
+			[2]string{"review_count", "INTEGER DEFAULT 0"},
+		),
+		Down: dropColumns("topics", "easiness_factor", "review_interval", "review_count"),
+	},
+	{
+		Version: 9,
+		Name:    "user_configs",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS user_configs (
+				user_id INTEGER PRIMARY KEY,
+				words_per_batch INTEGER NOT NULL DEFAULT 5,
+				repetitions INTEGER NOT NULL DEFAULT 3,
+				is_active BOOLEAN NOT NULL DEFAULT TRUE,
+				notification_hour INTEGER NOT NULL DEFAULT 9,
+				interval_preset TEXT NOT NULL DEFAULT 'standard',
+				custom_intervals TEXT NOT NULL DEFAULT '',
+				last_batch_time TIMESTAMP,
+				created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (user_id) REFERENCES users(id)
+			)`,
+		),
+		Down: exec("DROP TABLE IF EXISTS user_configs"),
+	},
+	{
+		Version: 10,
+		Name:    "user_digest_enabled",
+		Up:      addColumns("users", [2]string{"digest_enabled", "BOOLEAN DEFAULT false"}),
+		Down:    dropColumns("users", "digest_enabled"),
+	},
+	{
+		Version: 11,
+		Name:    "user_language",
+		Up:      addColumns("users", [2]string{"language", "TEXT NOT NULL DEFAULT ''"}),
+		Down:    dropColumns("users", "language"),
+	},
+	{
+		Version: 12,
+		Name:    "user_broadcast_opt_out",
+		Up:      addColumns("users", [2]string{"broadcast_opt_out", "BOOLEAN DEFAULT false"}),
+		Down:    dropColumns("users", "broadcast_opt_out"),
+	},
+	{
+		Version: 13,
+		Name:    "user_notification_hours_quiet_hours",
+		Up: addColumns("users",
+			[2]string{"notification_hours", "TEXT NOT NULL DEFAULT ''"},
+			[2]string{"quiet_hours_start", "INTEGER DEFAULT 0"},
+			[2]string{"quiet_hours_end", "INTEGER DEFAULT 0"},
+		),
+		Down: dropColumns("users", "notification_hours", "quiet_hours_start", "quiet_hours_end"),
+	},
+	{
+		// Words belong to the owner of their topic, so one user's vocabulary
+		// never shows up in another user's search, review or export
+		Version: 14,
+		Name:    "word_owner",
+		Up: steps(
+			addColumns("words", [2]string{"user_id", "INTEGER REFERENCES users(id)"}),
+			exec(
+				`UPDATE words SET user_id = (SELECT user_id FROM topics WHERE topics.id = words.topic_id)
+				WHERE user_id IS NULL`,
+				"CREATE INDEX IF NOT EXISTS idx_words_user_id ON words(user_id)",
+			),
+		),
+		Down: steps(
+			exec("DROP INDEX IF EXISTS idx_words_user_id"),
+			dropColumns("words", "user_id"),
+		),
+	},
+	{
+		Version: 15,
+		Name:    "decks",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS decks (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE,
+				description TEXT NOT NULL DEFAULT '',
+				created_by INTEGER REFERENCES users(id),
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS deck_words (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				deck_id INTEGER NOT NULL,
+				word TEXT NOT NULL,
+				translation TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				examples TEXT NOT NULL DEFAULT '',
+				verb_forms TEXT NOT NULL DEFAULT '',
+				FOREIGN KEY (deck_id) REFERENCES decks(id),
+				UNIQUE(deck_id, word)
+			)`,
+			`CREATE TABLE IF NOT EXISTS deck_subscriptions (
+				user_id INTEGER NOT NULL,
+				deck_id INTEGER NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (user_id, deck_id),
+				FOREIGN KEY (user_id) REFERENCES users(id),
+				FOREIGN KEY (deck_id) REFERENCES decks(id)
+			)`,
+		),
+		Down: exec(
+			"DROP TABLE IF EXISTS deck_subscriptions",
+			"DROP TABLE IF EXISTS deck_words",
+			"DROP TABLE IF EXISTS decks",
+		),
+	},
+	{
+		Version: 16,
+		Name:    "daily_goal_activity",
+		Up: steps(
+			addColumns("users", [2]string{"daily_goal", "INTEGER DEFAULT 0"}),
+			exec(
+				`CREATE TABLE IF NOT EXISTS daily_activity (
+					user_id INTEGER NOT NULL,
+					day TEXT NOT NULL,
+					reviews INTEGER NOT NULL DEFAULT 0,
+					goal INTEGER NOT NULL DEFAULT 0,
+					protected BOOLEAN NOT NULL DEFAULT false,
+					PRIMARY KEY (user_id, day),
+					FOREIGN KEY (user_id) REFERENCES users(id)
+				)`,
+			),
+			backfillDailyActivity,
+		),
+		Down: steps(
+			exec("DROP TABLE IF EXISTS daily_activity"),
+			dropColumns("users", "daily_goal"),
+		),
+	},
+	{
+		Version: 17,
+		Name:    "test_results",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS test_results (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				test_type TEXT NOT NULL,
+				total_words INTEGER NOT NULL DEFAULT 0,
+				correct_words INTEGER NOT NULL DEFAULT 0,
+				topics TEXT NOT NULL DEFAULT '',
+				test_date TIMESTAMP NOT NULL,
+				duration INTEGER NOT NULL DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (user_id) REFERENCES users(id)
+			)`,
+			"CREATE INDEX IF NOT EXISTS idx_test_results_user_id ON test_results(user_id)",
+		),
+		Down: exec(
+			"DROP INDEX IF EXISTS idx_test_results_user_id",
+			"DROP TABLE IF EXISTS test_results",
+		),
+	},
+	{
+		Version: 18,
+		Name:    "user_story_enabled",
+		Up:      addColumns("users", [2]string{"story_enabled", "BOOLEAN DEFAULT false"}),
+		Down:    dropColumns("users", "story_enabled"),
+	},
+	{
+		Version: 19,
+		Name:    "word_enrichment_status",
+		Up: steps(
+			addColumns("words", [2]string{"enrichment_status", "TEXT NOT NULL DEFAULT ''"}),
+			exec("CREATE INDEX IF NOT EXISTS idx_words_enrichment_status ON words(enrichment_status)"),
+		),
+		Down: steps(
+			exec("DROP INDEX IF EXISTS idx_words_enrichment_status"),
+			dropColumns("words", "enrichment_status"),
+		),
+	},
+	{
+		Version: 20,
+		Name:    "pronunciations",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS pronunciations (
+				text TEXT PRIMARY KEY,
+				file_id TEXT NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+		),
+		Down: exec("DROP TABLE IF EXISTS pronunciations"),
+	},
+	{
+		Version: 21,
+		Name:    "user_daily_board",
+		Up: addColumns("users",
+			[2]string{"board_enabled", "BOOLEAN DEFAULT false"},
+			[2]string{"board_message_id", "INTEGER DEFAULT 0"},
+		),
+		Down: dropColumns("users", "board_enabled", "board_message_id"),
+	},
+	{
+		Version: 22,
+		Name:    "notification_log",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS notification_log (
+				user_id INTEGER NOT NULL,
+				day TEXT NOT NULL,
+				type TEXT NOT NULL,
+				sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (user_id, day, type),
+				FOREIGN KEY (user_id) REFERENCES users(id)
+			)`,
+		),
+		Down: exec("DROP TABLE IF EXISTS notification_log"),
+	},
+	{
+		Version: 23,
+		Name:    "topic_attachments",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS topic_attachments (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				topic_id INTEGER NOT NULL,
+				kind TEXT NOT NULL,
+				content TEXT NOT NULL,
+				caption TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (user_id) REFERENCES users(id),
+				FOREIGN KEY (topic_id) REFERENCES topics(id)
+			)`,
+			"CREATE INDEX IF NOT EXISTS idx_topic_attachments_topic_id ON topic_attachments(topic_id)",
+		),
+		Down: exec(
+			"DROP INDEX IF EXISTS idx_topic_attachments_topic_id",
+			"DROP TABLE IF EXISTS topic_attachments",
+		),
+	},
+	{
+		Version: 24,
+		Name:    "topic_parent",
+		Up: steps(
+			addColumns("topics", [2]string{"parent_id", "INTEGER NOT NULL DEFAULT 0"}),
+			exec("CREATE INDEX IF NOT EXISTS idx_topics_parent_id ON topics(parent_id)"),
+		),
+		Down: steps(
+			exec("DROP INDEX IF EXISTS idx_topics_parent_id"),
+			dropColumns("topics", "parent_id"),
+		),
+	},
+	{
+		// SQLite searches through FTS5 tables, which need a driver built with FTS5 and are
+		// set up at startup (see database.setupSearch). Postgres gets expression indexes here.
+		Version: 25,
+		Name:    "search_index",
+		Up: steps(
+			addColumns("topics", [2]string{"description", "TEXT NOT NULL DEFAULT ''"}),
+			postgresOnly(exec(
+				`CREATE INDEX IF NOT EXISTS idx_words_search ON words USING GIN
+					(to_tsvector('simple', word || ' ' || translation || ' ' || COALESCE(examples, '')))`,
+				`CREATE INDEX IF NOT EXISTS idx_topics_search ON topics USING GIN
+					(to_tsvector('simple', name || ' ' || COALESCE(description, '')))`,
+			)),
+		),
+		Down: steps(
+			postgresOnly(exec(
+				"DROP INDEX IF EXISTS idx_words_search",
+				"DROP INDEX IF EXISTS idx_topics_search",
+			)),
+			dropColumns("topics", "description"),
+		),
+	},
+	{
+		Version: 26,
+		Name:    "topic_trash",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS topic_trash (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				data TEXT NOT NULL,
+				deleted_at TIMESTAMP NOT NULL,
+				FOREIGN KEY (user_id) REFERENCES users(id)
+			)`,
+			"CREATE INDEX IF NOT EXISTS idx_topic_trash_deleted_at ON topic_trash(deleted_at)",
+		),
+		Down: exec(
+			"DROP INDEX IF EXISTS idx_topic_trash_deleted_at",
+			"DROP TABLE IF EXISTS topic_trash",
+		),
+	},
+	{
+		Version: 27,
+		Name:    "overdue_policy",
+		Up: steps(
+			addColumns("users",
+				[2]string{"overdue_policy", "TEXT NOT NULL DEFAULT 'remind'"},
+				[2]string{"overdue_days", "INTEGER NOT NULL DEFAULT 7"},
+			),
+			addColumns("topics", [2]string{"stalled", "BOOLEAN DEFAULT false"}),
+		),
+		Down: steps(
+			dropColumns("topics", "stalled"),
+			dropColumns("users", "overdue_policy", "overdue_days"),
+		),
+	},
+	{
+		Version: 28,
+		Name:    "daily_review_limit",
+		Up:      addColumns("users", [2]string{"daily_review_limit", "INTEGER NOT NULL DEFAULT 0"}),
+		Down:    dropColumns("users", "daily_review_limit"),
+	},
+	{
+		Version: 29,
+		Name:    "weekly_report",
+		Up: addColumns("users",
+			[2]string{"report_enabled", "BOOLEAN DEFAULT true"},
+			[2]string{"report_day", "INTEGER NOT NULL DEFAULT 0"},
+			[2]string{"report_hour", "INTEGER NOT NULL DEFAULT 19"},
+		),
+		Down: dropColumns("users", "report_enabled", "report_day", "report_hour"),
+	},
+	{
+		// The counters were never updated: every completed repetition counts as a review, and
+		// every repetition number the topic moved on from counts as completed
+		Version: 30,
+		Name:    "statistics_backfill",
+		Up: exec(
+			`INSERT INTO statistics (user_id, topic_id, total_repetitions, completed_repetitions)
+			SELECT t.user_id, t.id, 0, 0 FROM topics t
+			WHERE NOT EXISTS (SELECT 1 FROM statistics s WHERE s.topic_id = t.id)`,
+			`UPDATE statistics SET
+				total_repetitions = (
+					SELECT COUNT(*) FROM repetitions r
+					WHERE r.topic_id = statistics.topic_id AND r.completed = true
+				),
+				completed_repetitions = (
+					SELECT COUNT(DISTINCT r.repetition_number) FROM repetitions r
+					WHERE r.topic_id = statistics.topic_id AND r.completed = true
+					AND (
+						r.repetition_number < (SELECT MAX(r2.repetition_number) FROM repetitions r2 WHERE r2.topic_id = r.topic_id)
+						OR NOT EXISTS (SELECT 1 FROM repetitions r3 WHERE r3.topic_id = r.topic_id AND r3.completed = false)
+					)
+				)`,
+		),
+		// The counters match the history, there is nothing to undo
+		Down: exec(),
+	},
+	{
+		Version: 31,
+		Name:    "leases",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS leases (
+				name TEXT PRIMARY KEY,
+				holder TEXT NOT NULL,
+				expires_at TIMESTAMP NOT NULL
+			)`,
+		),
+		Down: exec("DROP TABLE IF EXISTS leases"),
+	},
+	{
+		Version: 32,
+		Name:    "calendar_feeds",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS calendar_feeds (
+				user_id INTEGER PRIMARY KEY,
+				token TEXT NOT NULL UNIQUE,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (user_id) REFERENCES users(id)
+			)`,
+		),
+		Down: exec("DROP TABLE IF EXISTS calendar_feeds"),
+	},
+	{
+		Version: 33,
+		Name:    "notification_channels",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS notification_channels (
+				user_id INTEGER NOT NULL,
+				channel TEXT NOT NULL,
+				address TEXT NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (user_id, channel),
+				FOREIGN KEY (user_id) REFERENCES users(id)
+			)`,
+		),
+		Down: exec("DROP TABLE IF EXISTS notification_channels"),
+	},
+	{
+		Version: 34,
+		Name:    "delivery_queue",
+		Up: exec(
+			`CREATE TABLE IF NOT EXISTS notification_queue (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				kind TEXT NOT NULL,
+				message TEXT NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 1,
+				last_error TEXT NOT NULL DEFAULT '',
+				next_attempt_at TIMESTAMP NOT NULL,
+				failed BOOLEAN NOT NULL DEFAULT false,
+				created_at TIMESTAMP NOT NULL,
+				FOREIGN KEY (user_id) REFERENCES users(id)
+			)`,
+			"CREATE INDEX IF NOT EXISTS idx_notification_queue_next_attempt ON notification_queue(failed, next_attempt_at)",
+			`CREATE TABLE IF NOT EXISTS delivery_failures (
+				user_id INTEGER PRIMARY KEY,
+				blocked INTEGER NOT NULL DEFAULT 0,
+				last_error TEXT NOT NULL,
+				last_failed_at TIMESTAMP NOT NULL,
+				disabled BOOLEAN NOT NULL DEFAULT false,
+				FOREIGN KEY (user_id) REFERENCES users(id)
+			)`,
+		),
+		Down: exec(
+			"DROP TABLE IF EXISTS delivery_failures",
+			"DROP INDEX IF EXISTS idx_notification_queue_next_attempt",
+			"DROP TABLE IF EXISTS notification_queue",
+		),
+	},
+	{
+		// Set when Telegram refuses messages to the user: the bot was blocked or the account deleted
+		Version: 35,
+		Name:    "user_inactive_since",
+		Up: addColumns("users",
+			[2]string{"inactive_since", "TIMESTAMP"},
+		),
+		Down: dropColumns("users", "inactive_since"),
+	},
+	{
+		// Topics with maintenance on get a long-interval review after the cycle instead of ending
+		Version: 36,
+		Name:    "maintenance_reviews",
+		Up: steps(
+			addColumns("topics", [2]string{"maintenance", "BOOLEAN NOT NULL DEFAULT false"}),
+			addColumns("repetitions", [2]string{"maintenance", "BOOLEAN NOT NULL DEFAULT false"}),
+		),
+		Down: steps(
+			dropColumns("repetitions", "maintenance"),
+			dropColumns("topics", "maintenance"),
+		),
+	},
+	{
+		// A JSON array of days that replaces the user's ladder for one topic, empty for none
+		Version: 37,
+		Name:    "topic_custom_intervals",
+		Up: addColumns("topics",
+			[2]string{"custom_intervals", "TEXT NOT NULL DEFAULT ''"},
+		),
+		Down: dropColumns("topics", "custom_intervals"),
+	},
+	{
+		// The scheduler each user picked and the Leitner box of every topic
+		Version: 38,
+		Name:    "leitner_scheduler",
+		Up: steps(
+			addColumns("users", [2]string{"scheduler", "TEXT NOT NULL DEFAULT 'sm2'"}),
+			addColumns("topics", [2]string{"leitner_box", "INTEGER NOT NULL DEFAULT 1"}),
+		),
+		Down: steps(
+			dropColumns("topics", "leitner_box"),
+			dropColumns("users", "scheduler"),
+		),
+	},
+	{
+		// Word progress dates used to be written as RFC 3339 text, which SQLite compares as text
+		// and the driver can't always read back as a time. Postgres converted them on insert into
+		// its TIMESTAMP columns already. Rewrites them in UTC as the driver writes times.
+		Version: 39,
+		Name:    "user_progress_timestamps",
+		Up: sqliteOnly(exec(
+			`UPDATE user_progress SET
+				last_review_date = COALESCE(strftime('%Y-%m-%d %H:%M:%S+00:00', last_review_date),
+					strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+				next_review_date = COALESCE(strftime('%Y-%m-%d %H:%M:%S+00:00', next_review_date),
+					strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+				created_at = COALESCE(strftime('%Y-%m-%d %H:%M:%S+00:00', created_at),
+					strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+				updated_at = COALESCE(strftime('%Y-%m-%d %H:%M:%S+00:00', updated_at),
+					strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))`,
+		)),
+		Down: sqliteOnly(exec(
+			`UPDATE user_progress SET
+				last_review_date = strftime('%Y-%m-%dT%H:%M:%SZ', last_review_date),
+				next_review_date = strftime('%Y-%m-%dT%H:%M:%SZ', next_review_date)`,
+		)),
+	},
+	{
+		// SM-2 parameters tuned from each user's recall history, zero until the first tuning
+		Version: 40,
+		Name:    "user_sm2_params",
+		Up: addColumns("user_configs",
+			[2]string{"sm2_pass_threshold", "INTEGER NOT NULL DEFAULT 0"},
+			[2]string{"sm2_initial_intervals", "TEXT NOT NULL DEFAULT ''"},
+			[2]string{"sm2_min_easiness", "REAL NOT NULL DEFAULT 0"},
+			[2]string{"sm2_tuned_at", "TIMESTAMP"},
+		),
+		Down: dropColumns("user_configs", "sm2_pass_threshold", "sm2_initial_intervals", "sm2_min_easiness", "sm2_tuned_at"),
+	},
+	{
+		// A daily cap on brand-new words, so subscribing to a large deck doesn't make every word
+		// due at once. The words reviewed so far count as introduced on their last review.
+		Version: 41,
+		Name:    "new_words_per_day",
+		Up: steps(
+			addColumns("user_configs", [2]string{"new_words_per_day", "INTEGER NOT NULL DEFAULT 20"}),
+			addColumns("user_progress", [2]string{"introduced_at", "TIMESTAMP"}),
+			exec(
+				`UPDATE user_progress SET introduced_at = last_review_date
+				WHERE repetitions > 0 OR last_review_date > created_at`,
+				"CREATE INDEX IF NOT EXISTS idx_user_progress_introduced ON user_progress(user_id, introduced_at)",
+			),
+		),
+		Down: steps(
+			exec("DROP INDEX IF EXISTS idx_user_progress_introduced"),
+			dropColumns("user_progress", "introduced_at"),
+			dropColumns("user_configs", "new_words_per_day"),
+		),
+	},
+	{
+		// Synonyms and antonyms of the words, filled in by the enricher. synonyms_checked marks
+		// the words the model has been asked about, so the ones enriched before get backfilled.
+		Version: 42,
+		Name:    "word_synonyms",
+		Up: addColumns("words",
+			[2]string{"synonyms", "TEXT NOT NULL DEFAULT ''"},
+			[2]string{"antonyms", "TEXT NOT NULL DEFAULT ''"},
+			[2]string{"synonyms_checked", "BOOLEAN NOT NULL DEFAULT false"},
+		),
+		Down: dropColumns("words", "synonyms", "antonyms", "synonyms_checked"),
+	},
+	{
+		// The opt-in daily word of the day: from a deck, or from the user's upcoming new words
+		// with deck 0. word_of_day keeps the words sent, so none comes twice, and the quiz answers.
+		// word_id is the ID in deck_words for a deck word and in words otherwise.
+		Version: 43,
+		Name:    "word_of_day",
+		Up: steps(
+			addColumns("user_configs",
+				[2]string{"word_of_day_enabled", "BOOLEAN NOT NULL DEFAULT false"},
+				[2]string{"word_of_day_deck_id", "INTEGER NOT NULL DEFAULT 0"},
+			),
+			exec(
+				`CREATE TABLE IF NOT EXISTS word_of_day (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					user_id INTEGER NOT NULL,
+					deck_id INTEGER NOT NULL DEFAULT 0,
+					word_id INTEGER NOT NULL,
+					word TEXT NOT NULL,
+					translation TEXT NOT NULL,
+					correct BOOLEAN,
+					sent_at TIMESTAMP NOT NULL,
+					FOREIGN KEY (user_id) REFERENCES users(id)
+				)`,
+				"CREATE INDEX IF NOT EXISTS idx_word_of_day_user ON word_of_day(user_id, word)",
+			),
+		),
+		Down: steps(
+			exec(
+				"DROP INDEX IF EXISTS idx_word_of_day_user",
+				"DROP TABLE IF EXISTS word_of_day",
+			),
+			dropColumns("user_configs", "word_of_day_enabled", "word_of_day_deck_id"),
+		),
+	},
+	{
+		// Flashcards in both directions: a word gets a progress row per direction it is reviewed in.
+		// SQLite can't change the unique key of a table, so it rebuilds user_progress.
+		Version: 44,
+		Name:    "review_directions",
+		Up: steps(
+			addColumns("user_configs", [2]string{"review_direction", "TEXT NOT NULL DEFAULT 'en_ru'"}),
+			addColumns("topics", [2]string{"review_direction", "TEXT NOT NULL DEFAULT ''"}),
+			sqliteOnly(exec(
+				userProgressTable("user_progress_directions", true),
+				`INSERT INTO user_progress_directions (`+userProgressColumns+`)
+				SELECT `+userProgressColumns+` FROM user_progress`,
+				"DROP INDEX IF EXISTS idx_user_progress_introduced",
+				"DROP TABLE user_progress",
+				"ALTER TABLE user_progress_directions RENAME TO user_progress",
+				"CREATE INDEX IF NOT EXISTS idx_user_progress_introduced ON user_progress(user_id, introduced_at)",
+			)),
+			postgresOnly(steps(
+				addColumns("user_progress", [2]string{"direction", "TEXT NOT NULL DEFAULT 'en_ru'"}),
+				exec(
+					"ALTER TABLE user_progress DROP CONSTRAINT IF EXISTS user_progress_user_id_word_id_key",
+					"ALTER TABLE user_progress ADD CONSTRAINT user_progress_user_id_word_id_direction_key UNIQUE (user_id, word_id, direction)",
+				),
+			)),
+		),
+		Down: steps(
+			exec("DELETE FROM user_progress WHERE direction <> 'en_ru'"),
+			sqliteOnly(exec(
+				userProgressTable("user_progress_directions", false),
+				`INSERT INTO user_progress_directions (`+userProgressColumns+`)
+				SELECT `+userProgressColumns+` FROM user_progress`,
+				"DROP INDEX IF EXISTS idx_user_progress_introduced",
+				"DROP TABLE user_progress",
+				"ALTER TABLE user_progress_directions RENAME TO user_progress",
+				"CREATE INDEX IF NOT EXISTS idx_user_progress_introduced ON user_progress(user_id, introduced_at)",
+			)),
+			postgresOnly(steps(
+				exec(
+					"ALTER TABLE user_progress DROP CONSTRAINT IF EXISTS user_progress_user_id_word_id_direction_key",
+					"ALTER TABLE user_progress ADD CONSTRAINT user_progress_user_id_word_id_key UNIQUE (user_id, word_id)",
+				),
+				dropColumns("user_progress", "direction"),
+			)),
+			dropColumns("topics", "review_direction"),
+			dropColumns("user_configs", "review_direction"),
+		),
+	},
+	{
+		// English definitions of the words for the definition quiz, written by a language model
+		// the first time a word is asked and kept
+		Version: 45,
+		Name:    "word_definitions",
+		Up:      addColumns("words", [2]string{"definition", "TEXT NOT NULL DEFAULT ''"}),
+		Down:    dropColumns("words", "definition"),
+	},
+	{
+		// Phrases and collocations are learned as a unit. Words with spaces are phrases until
+		// the user says they are collocations.
+		Version: 46,
+		Name:    "word_kinds",
+		Up: steps(
+			addColumns("words", [2]string{"kind", "TEXT NOT NULL DEFAULT 'word'"}),
+			addColumns("deck_words", [2]string{"kind", "TEXT NOT NULL DEFAULT 'word'"}),
+			exec(
+				"UPDATE words SET kind = 'phrase' WHERE TRIM(word) LIKE '% %'",
+				"UPDATE deck_words SET kind = 'phrase' WHERE TRIM(word) LIKE '% %'",
+			),
+		),
+		Down: steps(
+			dropColumns("deck_words", "kind"),
+			dropColumns("words", "kind"),
+		),
+	},
+}
+
+// userProgressColumns are the columns of user_progress before the review directions
+const userProgressColumns = `id, user_id, word_id, easiness_factor, interval, repetitions, last_quality,
+	consecutive_right, is_learned, last_review_date, next_review_date, introduced_at, created_at, updated_at`
+
+// userProgressTable creates user_progress under the name, with the direction in the unique key
+// or without the direction column at all
+func userProgressTable(name string, directions bool) string {
+	direction, unique := "", "UNIQUE(user_id, word_id)"
+	if directions {
+		direction, unique = "direction TEXT NOT NULL DEFAULT 'en_ru',", "UNIQUE(user_id, word_id, direction)"
+	}
+	return `CREATE TABLE ` + name + ` (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		word_id INTEGER NOT NULL,
+		` + direction + `
+		easiness_factor REAL DEFAULT 2.5,
+		interval INTEGER DEFAULT 1,
+		repetitions INTEGER DEFAULT 0,
+		last_quality INTEGER DEFAULT 3,
+		consecutive_right INTEGER DEFAULT 0,
+		is_learned BOOLEAN DEFAULT FALSE,
+		last_review_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		next_review_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		introduced_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id),
+		FOREIGN KEY (word_id) REFERENCES words(id),
+		` + unique + `
+	)`
+}
+
+// backfillDailyActivity counts the completed repetitions per day, so the review streaks
+// users had before daily_activity existed carry over
+func backfillDailyActivity(ctx context.Context, tx *sqlx.Tx) error {
+	var reviews []struct {
+		UserID int64     `db:"user_id"`
+		Date   time.Time `db:"last_review_date"`
+	}
+	err := tx.SelectContext(ctx, &reviews, `
+		SELECT user_id, last_review_date
+		FROM repetitions
+		WHERE completed = true AND last_review_date IS NOT NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to get review dates: %w", err)
+	}
+
+	type userDay struct {
+		userID int64
+		day    string
+	}
+	counts := make(map[userDay]int)
+	for _, r := range reviews {
+		counts[userDay{r.UserID, r.Date.Local().Format("2006-01-02")}]++
+	}
+	for key, count := range counts {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO daily_activity (user_id, day, reviews) VALUES (?, ?, ?)
+			ON CONFLICT (user_id, day) DO NOTHING
+		`, key.userID, key.day, count)
+		if err != nil {
+			return fmt.Errorf("failed to backfill daily activity: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Migration is one versioned schema change. Up and Down run inside a transaction.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, tx *sqlx.Tx) error
+	Down    func(ctx context.Context, tx *sqlx.Tx) error
+}
+
+// Status describes a migration and whether it has been applied
+type Status struct {
+	Migration
+	Applied bool
+}
+
+// Runner applies and rolls back migrations, recording applied versions in schema_migrations
+type Runner struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// NewRunner creates a runner for all known migrations
+func NewRunner(db *sqlx.DB) *Runner {
+	return NewRunnerWithMigrations(db, All)
+}
+
+// NewRunnerWithMigrations creates a runner for the given migrations
+func NewRunnerWithMigrations(db *sqlx.DB, migrations []Migration) *Runner {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return &Runner{db: db, migrations: sorted}
+}
+
+// Up applies all pending migrations in version order and returns how many were applied
+func (r *Runner) Up(ctx context.Context) (int, error) {
+	applied, err := r.appliedVersions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range r.migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if err := r.run(ctx, m, true); err != nil {
+			return count, err
+		}
+		slog.InfoContext(ctx, "applied migration", "version", m.Version, "name", m.Name)
+		count++
+	}
+	return count, nil
+}
+
+// Down rolls back the last steps applied migrations and returns how many were rolled back
+func (r *Runner) Down(ctx context.Context, steps int) (int, error) {
+	applied, err := r.appliedVersions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(r.migrations) - 1; i >= 0 && count < steps; i-- {
+		m := r.migrations[i]
+		if !applied[m.Version] {
+			continue
+		}
+		if err := r.run(ctx, m, false); err != nil {
+			return count, err
+		}
+		slog.InfoContext(ctx, "rolled back migration", "version", m.Version, "name", m.Name)
+		count++
+	}
+	return count, nil
+}
+
+// Status lists all known migrations with their applied state
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(r.migrations))
+	for i, m := range r.migrations {
+		statuses[i] = Status{Migration: m, Applied: applied[m.Version]}
+	}
+	return statuses, nil
+}
+
+// run applies or rolls back one migration together with its schema_migrations row
+func (r *Runner) run(ctx context.Context, m Migration, up bool) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if up {
+		if err := m.Up(ctx, tx); err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.Version, m.Name)
+	} else {
+		if m.Down == nil {
+			return fmt.Errorf("migration %d_%s can't be rolled back", m.Version, m.Name)
+		}
+		if err := m.Down(ctx, tx); err != nil {
+			return fmt.Errorf("rollback of migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", m.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+	}
+	return nil
+}
+
+// appliedVersions creates schema_migrations if needed and returns the applied versions
+func (r *Runner) appliedVersions(ctx context.Context) (map[int]bool, error) {
+	_, err := r.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	var versions []int
+	if err := r.db.SelectContext(ctx, &versions, "SELECT version FROM schema_migrations"); err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	applied := make(map[int]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+// exec returns a migration step that runs the statements in order
+func exec(statements ...string) func(ctx context.Context, tx *sqlx.Tx) error {
+	return func(ctx context.Context, tx *sqlx.Tx) error {
+		for _, stmt := range statements {
+			if _, err := tx.ExecContext(ctx, ddl(tx, stmt)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// steps runs the migration steps one after another
+func steps(fns ...func(ctx context.Context, tx *sqlx.Tx) error) func(ctx context.Context, tx *sqlx.Tx) error {
+	return func(ctx context.Context, tx *sqlx.Tx) error {
+		for _, fn := range fns {
+			if err := fn(ctx, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// postgresOnly runs the migration step on Postgres only
+func postgresOnly(fn func(ctx context.Context, tx *sqlx.Tx) error) func(ctx context.Context, tx *sqlx.Tx) error {
+	return func(ctx context.Context, tx *sqlx.Tx) error {
+		if tx.DriverName() != "postgres" {
+			return nil
+		}
+		return fn(ctx, tx)
+	}
+}
+
+// sqliteOnly runs the migration step on SQLite only
+func sqliteOnly(fn func(ctx context.Context, tx *sqlx.Tx) error) func(ctx context.Context, tx *sqlx.Tx) error {
+	return func(ctx context.Context, tx *sqlx.Tx) error {
+		if tx.DriverName() == "postgres" {
+			return nil
+		}
+		return fn(ctx, tx)
+	}
+}
+
+// addColumns returns a migration step that adds the columns that are still missing.
+// Databases created before migrations already got some of them at startup.
+func addColumns(table string, columns ...[2]string) func(ctx context.Context, tx *sqlx.Tx) error {
+	return func(ctx context.Context, tx *sqlx.Tx) error {
+		for _, column := range columns {
+			exists, err := columnExists(ctx, tx, table, column[0])
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+			stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column[0], column[1])
+			if _, err := tx.ExecContext(ctx, ddl(tx, stmt)); err != nil {
+				return fmt.Errorf("failed to add %s.%s column: %w", table, column[0], err)
+			}
+		}
+		return nil
+	}
+}
+
+// dropColumns returns a migration step that removes the columns
+func dropColumns(table string, columns ...string) func(ctx context.Context, tx *sqlx.Tx) error {
+	return func(ctx context.Context, tx *sqlx.Tx) error {
+		for _, column := range columns {
+			stmt := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column)
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("failed to drop %s.%s column: %w", table, column, err)
+			}
+		}
+		return nil
+	}
+}
+
+// columnExists checks the table schema for the column
+func columnExists(ctx context.Context, tx *sqlx.Tx, table, column string) (bool, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = ?", table)
+	if tx.DriverName() == "postgres" {
+		query = fmt.Sprintf("SELECT COUNT(*) FROM information_schema.columns WHERE table_name = '%s' AND column_name = ?", table)
+	}
+
+	var count int
+	if err := tx.GetContext(ctx, &count, query, column); err != nil {
+		return false, fmt.Errorf("failed to inspect %s table: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// ddl adapts a statement written for SQLite to Postgres: wide integer columns and serial keys
+func ddl(tx *sqlx.Tx, stmt string) string {
+	if tx.DriverName() != "postgres" {
+		return stmt
+	}
+	stmt = strings.ReplaceAll(stmt, "INTEGER", "BIGINT")
+	stmt = strings.ReplaceAll(stmt, "BIGINT PRIMARY KEY AUTOINCREMENT", "BIGSERIAL PRIMARY KEY")
+	return stmt
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// NotificationChannel is where else than Telegram a user gets the reminders
+type NotificationChannel struct {
+	// Channel is the name of the channel, like "email"
+	Channel string `db:"channel"`
+	// Address is the email address, webhook URL, etc. the channel delivers to
+	Address string `db:"address"`
+}
+
+// NotificationChannelRepository keeps the users' notification channels beyond Telegram
+type NotificationChannelRepository struct{}
+
+// NewNotificationChannelRepository creates a new repository instance
+func NewNotificationChannelRepository() *NotificationChannelRepository {
+	return &NotificationChannelRepository{}
+}
+
+// GetByUserID returns the user's channels by name
+func (r *NotificationChannelRepository) GetByUserID(ctx context.Context, userID int64) ([]NotificationChannel, error) {
+	var channels []NotificationChannel
+	err := readDB.SelectContext(ctx, &channels, `
+		SELECT channel, address FROM notification_channels
+		WHERE user_id = ?
+		ORDER BY channel
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification channels: %w", err)
+	}
+	return channels, nil
+}
+
+// Set makes the channel deliver to the address, replacing the user's previous address for it
+func (r *NotificationChannelRepository) Set(ctx context.Context, userID int64, channel, address string) error {
+	_, err := DB.ExecContext(ctx, `
+		INSERT INTO notification_channels (user_id, channel, address, created_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id, channel) DO UPDATE SET address = excluded.address, created_at = excluded.created_at
+	`, userID, channel, address)
+	if err != nil {
+		return fmt.Errorf("failed to set notification channel: %w", err)
+	}
+	return nil
+}
+
+// Delete turns the channel off for the user and reports whether it was on
+func (r *NotificationChannelRepository) Delete(ctx context.Context, userID int64, channel string) (bool, error) {
+	result, err := DB.ExecContext(ctx, "DELETE FROM notification_channels WHERE user_id = ? AND channel = ?", userID, channel)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete notification channel: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete notification channel: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/example/engbot/internal/clock"
+)
+
+// Kinds of notifications in notification_log
+const (
+	NotificationStory         = "story"
+	NotificationStalledPrompt = "stalled_prompt"
+	NotificationWeeklyReport  = "weekly_report"
+	NotificationWordOfDay     = "word_of_day"
+)
+
+// ReminderNotification is the kind of the reminder sent at the hour. Every reminder time of
+// a user is its own kind, so users with several reminder times get each of them.
+func ReminderNotification(hour int) string {
+	return fmt.Sprintf("reminder_%02d", hour)
+}
+
+// NotificationRepository logs the notifications sent to users, so each kind is delivered
+// at most once a day no matter how many jobs try to send it
+type NotificationRepository struct {
+	clock clock.Clock
+}
+
+// NewNotificationRepository creates a new repository instance
+func NewNotificationRepository() *NotificationRepository {
+	return NewNotificationRepositoryWithClock(clock.System{})
+}
+
+// NewNotificationRepositoryWithClock creates a repository that reads the current time from c
+func NewNotificationRepositoryWithClock(c clock.Clock) *NotificationRepository {
+	return &NotificationRepository{clock: c}
+}
+
+// Claim records that the notification of the kind is being sent to the user today and
+// reports whether it is the first time. Only the caller that gets true should send it.
+func (r *NotificationRepository) Claim(ctx context.Context, userID int64, kind string) (bool, error) {
+	result, err := DB.ExecContext(ctx, `
+		INSERT INTO notification_log (user_id, day, type, sent_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id, day, type) DO NOTHING
+	`, userID, r.clock.Now().Format(dayLayout), kind)
+	if err != nil {
+		return false, fmt.Errorf("failed to log notification: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to log notification: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Release forgets today's claim, so a notification that failed to send can be sent again
+func (r *NotificationRepository) Release(ctx context.Context, userID int64, kind string) error {
+	_, err := DB.ExecContext(ctx, "DELETE FROM notification_log WHERE user_id = ? AND day = ? AND type = ?",
+		userID, r.clock.Now().Format(dayLayout), kind)
+	if err != nil {
+		return fmt.Errorf("failed to release notification: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes the log of the days more than keepDays ago and returns the number of entries deleted
+func (r *NotificationRepository) Prune(ctx context.Context, keepDays int) (int64, error) {
+	before := r.clock.Now().AddDate(0, 0, -keepDays).Format(dayLayout)
+	result, err := DB.ExecContext(ctx, "DELETE FROM notification_log WHERE day < ?", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune notification log: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune notification log: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// PronunciationRepository caches the Telegram file IDs of the generated pronunciation clips,
+// so each text is synthesized and uploaded once
+type PronunciationRepository struct{}
+
+// NewPronunciationRepository creates a new repository instance
+func NewPronunciationRepository() *PronunciationRepository {
+	return &PronunciationRepository{}
+}
+
+// GetFileID returns the file ID of the clip for the text, or "" if there is none yet
+func (r *PronunciationRepository) GetFileID(ctx context.Context, text string) (string, error) {
+	var fileID string
+	err := readDB.GetContext(ctx, &fileID, "SELECT file_id FROM pronunciations WHERE text = ?", pronunciationKey(text))
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get pronunciation: %w", err)
+	}
+	return fileID, nil
+}
+
+// SaveFileID remembers the file ID of the clip for the text
+func (r *PronunciationRepository) SaveFileID(ctx context.Context, text, fileID string) error {
+	_, err := DB.ExecContext(ctx, `
+		INSERT INTO pronunciations (text, file_id, created_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (text) DO UPDATE SET file_id = excluded.file_id
+	`, pronunciationKey(text), fileID)
+	if err != nil {
+		return fmt.Errorf("failed to save pronunciation: %w", err)
+	}
+	return nil
+}
+
+// pronunciationKey folds the spellings that sound the same into one cache key
+func pronunciationKey(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
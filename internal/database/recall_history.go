@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Recall is one completed repetition: when it was due, when it was done and whether the topic
+// was remembered. A forgotten topic repeats the same repetition number, so a repetition was
+// remembered unless a later one of the topic has its number.
+type Recall struct {
+	NextReviewDate time.Time `db:"next_review_date"`
+	LastReviewDate time.Time `db:"last_review_date"`
+	Passed         bool      `db:"passed"`
+}
+
+// GetRecallHistory returns the user's repetitions completed since the moment, the oldest first
+func (r *RepetitionRepository) GetRecallHistory(ctx context.Context, userID int64, since time.Time) ([]Recall, error) {
+	var history []Recall
+	err := readDB.SelectContext(ctx, &history, `
+		SELECT r.next_review_date, r.last_review_date,
+			NOT EXISTS (
+				SELECT 1 FROM repetitions n
+				WHERE n.user_id = r.user_id AND n.topic_id = r.topic_id
+					AND n.repetition_number = r.repetition_number AND n.id > r.id
+			) AS passed
+		FROM repetitions r
+		WHERE r.user_id = ? AND r.completed = true AND r.last_review_date >= ?
+		ORDER BY r.last_review_date
+	`, userID, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recall history: %w", err)
+	}
+	return history, nil
+}
+
+// GetUsersWithRecallHistory returns the users with at least minReviews repetitions completed
+// since the moment
+func (r *RepetitionRepository) GetUsersWithRecallHistory(ctx context.Context, since time.Time, minReviews int) ([]int64, error) {
+	var userIDs []int64
+	err := readDB.SelectContext(ctx, &userIDs, `
+		SELECT r.user_id
+		FROM repetitions r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.completed = true AND r.last_review_date >= ? AND u.inactive_since IS NULL
+		GROUP BY r.user_id
+		HAVING COUNT(*) >= ?
+	`, since.UTC(), minReviews)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with recall history: %w", err)
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/example/engbot/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// Completion is everything a graded review of a topic changes
+type Completion struct {
+	Repetition *models.Repetition // the reviewed repetition
+	Topic      *models.Topic      // the topic with its new SM-2 state
+	ReviewedAt time.Time
+	Passed     bool               // counts as a completed repetition in the statistics
+	Next       *models.Repetition // the repetition to schedule, nil after the last one of the cycle
+}
+
+// Complete marks the repetition of c as completed, saves the SM-2 state of the topic and brings
+// a stalled topic back, schedules the next repetition and counts the review in the topic
+// statistics, all in one transaction. A repetition that was already completed is left alone
+// with ErrAlreadyCompleted.
+func (r *RepetitionRepository) Complete(ctx context.Context, c *Completion) error {
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE repetitions SET completed = true, last_review_date = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ? AND completed = false
+	`, c.ReviewedAt, c.Repetition.ID, c.Repetition.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to complete repetition %d: %w", c.Repetition.ID, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrAlreadyCompleted
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE topics
+		SET easiness_factor = ?, review_interval = ?, review_count = ?, leitner_box = ?, stalled = false,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ?
+	`, c.Topic.EasinessFactor, c.Topic.ReviewInterval, c.Topic.ReviewCount, c.Topic.LeitnerBox, c.Topic.ID, c.Topic.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update topic schedule: %w", err)
+	}
+
+	if c.Next != nil {
+		c.Next.ID, err = insertID(ctx, tx, `
+			INSERT INTO repetitions (user_id, topic_id, repetition_number, next_review_date, completed, maintenance)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, c.Next.UserID, c.Next.TopicID, c.Next.RepetitionNumber, c.Next.NextReviewDate, false, c.Next.Maintenance)
+		if err != nil {
+			return fmt.Errorf("failed to create next repetition: %w", err)
+		}
+	}
+
+	if err := incrementRepetitions(ctx, tx, c.Repetition.UserID, c.Repetition.TopicID, c.Passed); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	invalidateTopics(ctx, c.Topic.UserID)
+	c.Repetition.Completed = true
+	c.Repetition.LastReviewDate = &c.ReviewedAt
+	c.Topic.Stalled = false
+	return nil
+}
+
+// incrementRepetitions counts one review of the topic, and one completed repetition when
+// completed is set, creating the statistics of the topic if it has none
+func incrementRepetitions(ctx context.Context, db sqlx.ExtContext, userID, topicID int64, completed bool) error {
+	done := 0
+	if completed {
+		done = 1
+	}
+	result, err := db.ExecContext(ctx, `
+		UPDATE statistics
+		SET total_repetitions = total_repetitions + 1, completed_repetitions = completed_repetitions + ?
+		WHERE user_id = ? AND topic_id = ?
+	`, done, userID, topicID)
+	if err != nil {
+		return fmt.Errorf("failed to update statistics: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows > 0 {
+		return nil
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO statistics (user_id, topic_id, total_repetitions, completed_repetitions) VALUES (?, ?, 1, ?)
+	`, userID, topicID, done)
+	if err != nil {
+		return fmt.Errorf("failed to create statistics: %w", err)
+	}
+	return nil
+}
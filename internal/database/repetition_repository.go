@@ -2,47 +2,34 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"math"
 	"time"
 
+	"github.com/example/engbot/internal/clock"
 	"github.com/example/engbot/pkg/models"
+	"github.com/jmoiron/sqlx"
 )
 
 // RepetitionRepository handles database operations for repetitions
-type RepetitionRepository struct{}
+type RepetitionRepository struct {
+    clock clock.Clock
+}
 
 // NewRepetitionRepository creates a new repository instance
 func NewRepetitionRepository() *RepetitionRepository {
-    return &RepetitionRepository{}
+    return NewRepetitionRepositoryWithClock(clock.System{})
+}
+
+// NewRepetitionRepositoryWithClock creates a repository that reads the current time from c
+func NewRepetitionRepositoryWithClock(c clock.Clock) *RepetitionRepository {
+    return &RepetitionRepository{clock: c}
 }
 
 // Create inserts a new repetition
 func (r *RepetitionRepository) Create(ctx context.Context, rep *models.Repetition) error {
-    query := `
-        INSERT INTO repetitions (
-            user_id, topic_id, repetition_number,
-            next_review_date, last_review_date, completed
-        ) VALUES (?, ?, ?, ?, ?, ?)
-    `
-    result, err := DB.ExecContext(ctx, query,
-        rep.UserID,
-        rep.TopicID,
-        rep.RepetitionNumber,
-        rep.NextReviewDate,
-        rep.LastReviewDate,
-        rep.Completed,
-    )
-    if err != nil {
-        return fmt.Errorf("failed to create repetition: %v", err)
-    }
-
-    id, err := result.LastInsertId()
-    if err != nil {
-        return fmt.Errorf("failed to get last insert ID: %v", err)
-    }
-    rep.ID = id
-
-    return nil
+    return createRepetition(ctx, DB, rep)
 }
 
 // Update modifies an existing repetition
@@ -65,15 +52,38 @@ func (r *RepetitionRepository) Update(ctx context.Context, rep *models.Repetitio
         rep.UserID,
     )
     if err != nil {
-        return fmt.Errorf("failed to update repetition: %v", err)
+        return fmt.Errorf("failed to update repetition: %w", err)
     }
 
     rows, err := result.RowsAffected()
     if err != nil {
-        return fmt.Errorf("failed to get rows affected: %v", err)
+        return fmt.Errorf("failed to get rows affected: %w", err)
     }
     if rows == 0 {
-        return fmt.Errorf("repetition not found or user doesn't have permission")
+        return fmt.Errorf("repetition %w or user doesn't have permission", ErrNotFound)
+    }
+
+    return nil
+}
+
+// UpdateNotes stores the user's note for a repetition
+func (r *RepetitionRepository) UpdateNotes(ctx context.Context, userID, repID int64, notes string) error {
+    result, err := DB.ExecContext(ctx, `
+        UPDATE repetitions SET
+            notes = ?,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id = ? AND user_id = ?
+    `, notes, repID, userID)
+    if err != nil {
+        return fmt.Errorf("failed to update repetition notes: %w", err)
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    if rows == 0 {
+        return fmt.Errorf("repetition %w or user doesn't have permission", ErrNotFound)
     }
 
     return nil
@@ -91,9 +101,143 @@ func (r *RepetitionRepository) GetDueRepetitions(ctx context.Context, userID int
         ORDER BY r.next_review_date ASC
     `
     var repetitions []models.Repetition
-    err := DB.SelectContext(ctx, &repetitions, query, userID, time.Now())
+    err := readDB.SelectContext(ctx, &repetitions, query, userID, r.clock.Now())
+    if err != nil {
+        return nil, fmt.Errorf("failed to get due repetitions: %w", err)
+    }
+    return repetitions, nil
+}
+
+// dueForNotification is the filter of the repetitions r, of topic t, that should trigger a
+// reminder: due by the time given as its argument, pending and of a topic that gets reminders
+const dueForNotification = `
+        r.next_review_date <= ?
+        AND r.completed = false
+        AND t.archived = false
+        AND t.muted = false
+        AND t.stalled = false
+`
+
+// GetDueRepetitionsForNotification returns due repetitions that should trigger a reminder,
+// skipping the first skipFirst repetition numbers the user doesn't want to be reminded about
+func (r *RepetitionRepository) GetDueRepetitionsForNotification(ctx context.Context, userID int64, skipFirst int) ([]models.Repetition, error) {
+    query := `
+        SELECT r.*, t.name as topic_name
+        FROM repetitions r
+        JOIN topics t ON r.topic_id = t.id
+        WHERE r.user_id = ?
+        AND r.repetition_number > ?
+        AND ` + dueForNotification + `
+        ORDER BY r.next_review_date ASC
+    `
+    var repetitions []models.Repetition
+    err := readDB.SelectContext(ctx, &repetitions, query, userID, skipFirst, r.clock.Now())
+    if err != nil {
+        return nil, fmt.Errorf("failed to get due repetitions for notification: %w", err)
+    }
+    return repetitions, nil
+}
+
+// DueRepetition is a repetition a reminder is about, with the number of its topic's materials
+type DueRepetition struct {
+    models.Repetition
+    Materials int `db:"materials"`
+}
+
+// GetDueForNotification returns, in one query for all users, the due repetitions that should
+// trigger a reminder of the users whose reminder hour is hour, with the same filters as
+// GetDueRepetitionsForNotification and the user's own number of repetitions to skip. The
+// repetitions of each user come by due date, users with nothing due are left out of the map.
+func (r *RepetitionRepository) GetDueForNotification(ctx context.Context, hour int) (map[int64][]DueRepetition, error) {
+    query := `
+        SELECT r.*, t.name as topic_name,
+            (SELECT COUNT(*) FROM topic_attachments a
+                WHERE a.user_id = r.user_id AND a.topic_id = r.topic_id) AS materials
+        FROM repetitions r
+        JOIN topics t ON r.topic_id = t.id
+        JOIN users u ON r.user_id = u.id
+        WHERE u.notification_enabled = true
+        AND u.inactive_since IS NULL
+        AND ((u.notification_hours = '' AND u.notification_hour = ?)
+            OR ',' || u.notification_hours || ',' LIKE ?)
+        AND r.repetition_number > u.skip_first_repetitions
+        AND ` + dueForNotification + `
+        ORDER BY r.user_id, r.next_review_date ASC, r.id
+    `
+    var rows []DueRepetition
+    err := readDB.SelectContext(ctx, &rows, query, hour, fmt.Sprintf("%%,%d,%%", hour), r.clock.Now())
+    if err != nil {
+        return nil, fmt.Errorf("failed to get due repetitions for notification: %w", err)
+    }
+
+    due := make(map[int64][]DueRepetition)
+    for _, row := range rows {
+        due[row.UserID] = append(due[row.UserID], row)
+    }
+    return due, nil
+}
+
+// GetOverdue returns the pending repetitions that were due before the given time, leaving out
+// topics that get no reminders anyway: archived, muted or already stalled
+func (r *RepetitionRepository) GetOverdue(ctx context.Context, userID int64, before time.Time) ([]models.Repetition, error) {
+    query := `
+        SELECT r.*, t.name as topic_name
+        FROM repetitions r
+        JOIN topics t ON r.topic_id = t.id
+        WHERE r.user_id = ?
+        AND r.next_review_date < ?
+        AND r.completed = false
+        AND t.archived = false
+        AND t.muted = false
+        AND t.stalled = false
+        ORDER BY r.next_review_date ASC
+    `
+    var repetitions []models.Repetition
+    err := readDB.SelectContext(ctx, &repetitions, query, userID, before)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get overdue repetitions: %w", err)
+    }
+    return repetitions, nil
+}
+
+// Reschedule moves a pending repetition to a new review date
+func (r *RepetitionRepository) Reschedule(ctx context.Context, userID, repID int64, next time.Time) error {
+    result, err := DB.ExecContext(ctx, `
+        UPDATE repetitions SET
+            next_review_date = ?,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id = ? AND user_id = ? AND completed = false
+    `, next, repID, userID)
+    if err != nil {
+        return fmt.Errorf("failed to reschedule repetition: %w", err)
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    if rows == 0 {
+        return fmt.Errorf("repetition %w or user doesn't have permission", ErrNotFound)
+    }
+
+    return nil
+}
+
+// GetCompletedSince returns the repetitions the user completed since the given time
+func (r *RepetitionRepository) GetCompletedSince(ctx context.Context, userID int64, since time.Time) ([]models.Repetition, error) {
+    query := `
+        SELECT r.*, t.name as topic_name
+        FROM repetitions r
+        JOIN topics t ON r.topic_id = t.id
+        WHERE r.user_id = ?
+        AND r.completed = true
+        AND r.last_review_date >= ?
+        ORDER BY r.last_review_date ASC
+    `
+    var repetitions []models.Repetition
+    err := readDB.SelectContext(ctx, &repetitions, query, userID, since)
     if err != nil {
-        return nil, fmt.Errorf("failed to get due repetitions: %v", err)
+        return nil, fmt.Errorf("failed to get completed repetitions: %w", err)
     }
     return repetitions, nil
 }
@@ -108,25 +252,58 @@ func (r *RepetitionRepository) GetByID(ctx context.Context, userID, repID int64)
         LIMIT 1
     `
     var rep models.Repetition
-    err := DB.GetContext(ctx, &rep, query, userID, repID)
+    err := readDB.GetContext(ctx, &rep, query, userID, repID)
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("failed to get repetition: %w", ErrNotFound)
+    }
     if err != nil {
-        return nil, fmt.Errorf("failed to get repetition: %v", err)
+        return nil, fmt.Errorf("failed to get repetition: %w", err)
     }
     return &rep, nil
 }
 
+// DefaultIntervals is the standard repetition ladder in days
+var DefaultIntervals = []int{1, 2, 3, 7, 15, 25, 40}
+
+// difficultyScale stretches or shrinks the repetition ladder by topic difficulty:
+// harder topics come back sooner, easier ones are spaced out further
+var difficultyScale = map[int]float64{
+    1: 1.5,
+    2: 1.25,
+    4: 0.75,
+    5: 0.5,
+}
+
+// IntervalsForDifficulty scales the ladder for a topic difficulty, every interval staying at
+// least a day. The default difficulty 3 and unknown ones keep the ladder as is.
+// Example: 1, 2, 3, 7, 15 becomes 1, 1, 2, 4, 8 for difficulty 5 and 2, 3, 5, 11, 23 for difficulty 1
+func IntervalsForDifficulty(intervals []int, difficulty int) []int {
+    scale, ok := difficultyScale[difficulty]
+    if !ok {
+        return intervals
+    }
+    scaled := make([]int, len(intervals))
+    for i, days := range intervals {
+        scaled[i] = max(1, int(math.Round(float64(days)*scale)))
+    }
+    return scaled
+}
+
 // CalculateNextReviewDate calculates the next review date based on the repetition number
-func (r *RepetitionRepository) CalculateNextReviewDate(repetitionNumber int) time.Time {
-    // Интервалы повторения в днях: 1, 2, 3, 7, 15, 25, 40
-    intervals := []int{1, 2, 3, 7, 15, 25, 40}
-    
+// and the interval ladder: the user's one (see GetUserIntervals) or the topic's own one when it
+// has it, see TopicIntervals
+func (r *RepetitionRepository) CalculateNextReviewDate(repetitionNumber int, intervals []int) time.Time {
+    if len(intervals) == 0 {
+        intervals = DefaultIntervals
+    }
+
     // Если номер повторения больше количества интервалов, используем последний интервал
     if repetitionNumber >= len(intervals) {
         repetitionNumber = len(intervals) - 1
     }
     
     // Вычисляем следующую дату повторения
-    nextDate := time.Now().AddDate(0, 0, intervals[repetitionNumber])
+    nextDate := r.clock.Now().AddDate(0, 0, intervals[repetitionNumber])
     
     return nextDate
 }
@@ -140,9 +317,127 @@ func (r *RepetitionRepository) GetAllByUserID(ctx context.Context, userID int64)
         ORDER BY next_review_date ASC
     `
     var repetitions []models.Repetition
-    err := DB.SelectContext(ctx, &repetitions, query, userID)
+    err := readDB.SelectContext(ctx, &repetitions, query, userID)
     if err != nil {
         return nil, fmt.Errorf("failed to get repetitions: %w", err)
     }
     return repetitions, nil
-} 
\ No newline at end of file
+} 
+// RestartAll resets every topic of a user to repetition #1 due tomorrow and zeroes
+// completed statistics in a single transaction. Returns the number of topics reset.
+func (r *RepetitionRepository) RestartAll(ctx context.Context, userID int64) (int, error) {
+    defer invalidateTopics(ctx, userID)
+
+    tx, err := DB.BeginTxx(ctx, nil)
+    if err != nil {
+        return 0, fmt.Errorf("failed to start transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    var topicIDs []int64
+    err = tx.SelectContext(ctx, &topicIDs, "SELECT id FROM topics WHERE user_id = ?", userID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to get topics: %w", err)
+    }
+
+    if err := r.restartTopics(ctx, tx, userID, topicIDs); err != nil {
+        return 0, err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit transaction: %w", err)
+    }
+
+    return len(topicIDs), nil
+}
+
+// RestartTopics resets the given topics to their first repetition in one transaction.
+// Topics that don't belong to the user are skipped; returns the number of topics reset.
+func (r *RepetitionRepository) RestartTopics(ctx context.Context, userID int64, topicIDs []int64) (int, error) {
+    defer invalidateTopics(ctx, userID)
+
+    tx, err := DB.BeginTxx(ctx, nil)
+    if err != nil {
+        return 0, fmt.Errorf("failed to start transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    var owned []int64
+    for _, topicID := range topicIDs {
+        var count int
+        err = tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM topics WHERE id = ? AND user_id = ?", topicID, userID)
+        if err != nil {
+            return 0, fmt.Errorf("failed to check topic %d: %w", topicID, err)
+        }
+        if count > 0 {
+            owned = append(owned, topicID)
+        }
+    }
+
+    if err := r.restartTopics(ctx, tx, userID, owned); err != nil {
+        return 0, err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit transaction: %w", err)
+    }
+
+    return len(owned), nil
+}
+
+// restartTopics replaces the repetitions of each topic with a fresh first one due tomorrow
+// and zeroes its completed counter
+func (r *RepetitionRepository) restartTopics(ctx context.Context, tx *sqlx.Tx, userID int64, topicIDs []int64) error {
+    nextReview := r.clock.Now().Add(24 * time.Hour)
+    for _, topicID := range topicIDs {
+        _, err := tx.ExecContext(ctx, "DELETE FROM repetitions WHERE user_id = ? AND topic_id = ?", userID, topicID)
+        if err != nil {
+            return fmt.Errorf("failed to delete repetitions for topic %d: %w", topicID, err)
+        }
+
+        _, err = tx.ExecContext(ctx, `
+            INSERT INTO repetitions (
+                user_id, topic_id, repetition_number, next_review_date, completed
+            ) VALUES (?, ?, 1, ?, false)
+        `, userID, topicID, nextReview)
+        if err != nil {
+            return fmt.Errorf("failed to create repetition for topic %d: %w", topicID, err)
+        }
+
+        _, err = tx.ExecContext(ctx, `
+            UPDATE statistics SET completed_repetitions = 0
+            WHERE user_id = ? AND topic_id = ?
+        `, userID, topicID)
+        if err != nil {
+            return fmt.Errorf("failed to reset statistics for topic %d: %w", topicID, err)
+        }
+
+        // review_count = 0 makes the next graded review start SM-2 from scratch, and the
+        // topic goes back to the first Leitner box
+        _, err = tx.ExecContext(ctx, `
+            UPDATE topics SET review_interval = 0, review_count = 0, leitner_box = 1
+            WHERE user_id = ? AND id = ?
+        `, userID, topicID)
+        if err != nil {
+            return fmt.Errorf("failed to reset schedule for topic %d: %w", topicID, err)
+        }
+    }
+    return nil
+}
+
+// GetByTopic returns all repetitions of a topic ordered by repetition number
+func (r *RepetitionRepository) GetByTopic(ctx context.Context, userID, topicID int64) ([]models.Repetition, error) {
+    query := `
+        SELECT r.*, t.name as topic_name
+        FROM repetitions r
+        JOIN topics t ON r.topic_id = t.id
+        WHERE r.user_id = ? AND r.topic_id = ?
+        ORDER BY r.repetition_number ASC
+    `
+    var repetitions []models.Repetition
+    err := readDB.SelectContext(ctx, &repetitions, query, userID, topicID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get topic repetitions: %w", err)
+    }
+    return repetitions, nil
+}
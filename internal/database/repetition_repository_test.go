@@ -0,0 +1,282 @@
+package database_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/database/dbtest"
+	"github.com/example/engbot/pkg/models"
+)
+
+func TestIntervalsForDifficulty(t *testing.T) {
+	for _, base := range [][]int{database.DefaultIntervals, {1, 3, 7, 14, 30, 60, 120}, {2, 2, 5}} {
+		for _, difficulty := range []int{3, 0, 6} {
+			if got := database.IntervalsForDifficulty(base, difficulty); !slices.Equal(got, base) {
+				t.Errorf("difficulty %d of %v = %v, want the ladder as is", difficulty, base, got)
+			}
+		}
+
+		tests := []struct {
+			difficulty int
+			tighter    bool
+		}{
+			{1, false},
+			{2, false},
+			{4, true},
+			{5, true},
+		}
+		for _, tt := range tests {
+			got := database.IntervalsForDifficulty(base, tt.difficulty)
+			if len(got) != len(base) {
+				t.Fatalf("difficulty %d of %v: %d intervals, want %d", tt.difficulty, base, len(got), len(base))
+			}
+			total, baseTotal := 0, 0
+			for i := range got {
+				if tt.tighter && got[i] > base[i] || !tt.tighter && got[i] < base[i] || got[i] < 1 {
+					t.Errorf("difficulty %d of %v: interval %d is %d days", tt.difficulty, base, i+1, got[i])
+				}
+				total += got[i]
+				baseTotal += base[i]
+			}
+			if tt.tighter && total >= baseTotal || !tt.tighter && total <= baseTotal {
+				t.Errorf("difficulty %d of %v: %d days in all, %d without scaling", tt.difficulty, base, total, baseTotal)
+			}
+		}
+
+		// Harder topics never come back later than easier ones
+		for difficulty := 2; difficulty <= 5; difficulty++ {
+			easier, harder := database.IntervalsForDifficulty(base, difficulty-1), database.IntervalsForDifficulty(base, difficulty)
+			for i := range harder {
+				if harder[i] > easier[i] {
+					t.Errorf("interval %d of %v: difficulty %d waits %d days, difficulty %d only %d",
+						i+1, base, difficulty, harder[i], difficulty-1, easier[i])
+				}
+			}
+		}
+	}
+
+	if got, want := database.IntervalsForDifficulty([]int{1, 2, 3, 7, 15}, 5), []int{1, 1, 2, 4, 8}; !slices.Equal(got, want) {
+		t.Errorf("difficulty 5 = %v, want %v", got, want)
+	}
+	if got, want := database.IntervalsForDifficulty([]int{1, 2, 3, 7, 15}, 1), []int{2, 3, 5, 11, 23}; !slices.Equal(got, want) {
+		t.Errorf("difficulty 1 = %v, want %v", got, want)
+	}
+}
+
+func TestTopicIntervals(t *testing.T) {
+	user := []int{1, 3, 7, 14, 30}
+	tests := []struct {
+		name  string
+		topic *models.Topic
+		want  []int
+	}{
+		{"no topic", nil, user},
+		{"default difficulty", &models.Topic{Difficulty: 3}, user},
+		{"hard topic", &models.Topic{Difficulty: 5}, []int{1, 2, 4, 7, 15}},
+		{"easy topic", &models.Topic{Difficulty: 1}, []int{2, 5, 11, 21, 45}},
+		{"own ladder", &models.Topic{Difficulty: 5, CustomIntervals: "[2,4,8]"}, []int{2, 4, 8}},
+		{"broken own ladder", &models.Topic{Difficulty: 4, CustomIntervals: "[2,"}, []int{1, 2, 5, 11, 23}},
+	}
+	for _, tt := range tests {
+		if got := database.TopicIntervals(tt.topic, user); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateNextReviewDate(t *testing.T) {
+	start := time.Date(2026, 3, 28, 22, 15, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	repo := database.NewRepetitionRepositoryWithClock(fake)
+
+	tests := []struct {
+		name      string
+		number    int
+		intervals []int
+		want      int
+	}{
+		{"first step", 0, []int{1, 3, 8}, 1},
+		{"middle step", 1, []int{1, 3, 8}, 3},
+		{"past the ladder", 7, []int{1, 3, 8}, 8},
+		{"default ladder", 3, nil, database.DefaultIntervals[3]},
+		{"past the default ladder", 50, nil, database.DefaultIntervals[len(database.DefaultIntervals)-1]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := repo.CalculateNextReviewDate(tt.number, tt.intervals), start.AddDate(0, 0, tt.want); !got.Equal(want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+
+	// The date follows the clock, across the end of the month
+	fake.Advance(72 * time.Hour)
+	if got, want := repo.CalculateNextReviewDate(0, []int{5}), time.Date(2026, 4, 5, 22, 15, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("after 3 days: got %v, want %v", got, want)
+	}
+}
+
+func TestRestartAll(t *testing.T) {
+	dbtest.Open(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := database.NewRepetitionRepositoryWithClock(clock.NewFake(now))
+	stats := database.NewStatisticsRepository()
+
+	user := dbtest.User(t, 100)
+	other := dbtest.User(t, 200)
+	var topics []*models.Topic
+	for i, number := range []int{3, 5, 7} {
+		topic := dbtest.Topic(t, user.ID, fmt.Sprintf("Topic %d", i+1), number, now.AddDate(0, 0, -i))
+		if err := stats.IncrementRepetitions(ctx, user.ID, topic.ID, true); err != nil {
+			t.Fatal(err)
+		}
+		topics = append(topics, topic)
+	}
+	untouched := dbtest.Topic(t, other.ID, "Someone else's", 4, now)
+
+	count, err := repo.RestartAll(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("RestartAll: %v", err)
+	}
+	if count != len(topics) {
+		t.Errorf("RestartAll reset %d topics, want %d", count, len(topics))
+	}
+	for _, topic := range topics {
+		reps, err := repo.GetByTopic(ctx, user.ID, topic.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reps) != 1 {
+			t.Fatalf("%s: %d repetitions after the restart, want 1", topic.Name, len(reps))
+		}
+		if reps[0].RepetitionNumber != 1 || reps[0].Completed || !reps[0].NextReviewDate.Equal(now.Add(24*time.Hour)) {
+			t.Errorf("%s: repetition #%d due %v, completed %v; want #1 due %v", topic.Name,
+				reps[0].RepetitionNumber, reps[0].NextReviewDate, reps[0].Completed, now.Add(24*time.Hour))
+		}
+		s, err := stats.GetByUserAndTopic(ctx, user.ID, topic.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.CompletedRepetitions != 0 {
+			t.Errorf("%s: %d completed repetitions after the restart, want 0", topic.Name, s.CompletedRepetitions)
+		}
+	}
+	reps, err := repo.GetByTopic(ctx, other.ID, untouched.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reps) != 1 || reps[0].RepetitionNumber != 4 {
+		t.Errorf("the topic of another user was restarted: %+v", reps)
+	}
+}
+
+func TestRestartAllIsOneTransaction(t *testing.T) {
+	dbtest.Open(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := database.NewRepetitionRepositoryWithClock(clock.NewFake(now))
+
+	user := dbtest.User(t, 100)
+	first := dbtest.Topic(t, user.ID, "First", 3, now)
+	last := dbtest.Topic(t, user.ID, "Last", 5, now)
+
+	// The last topic fails to restart after the first one has been reset
+	_, err := database.DB.Exec(fmt.Sprintf(`
+		CREATE TRIGGER fail_restart BEFORE INSERT ON repetitions
+		WHEN NEW.topic_id = %d
+		BEGIN SELECT RAISE(ABORT, 'restart failed'); END`, last.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.RestartAll(ctx, user.ID); err == nil {
+		t.Fatal("RestartAll succeeded, want the error of the last topic")
+	}
+	for topic, number := range map[*models.Topic]int{first: 3, last: 5} {
+		reps, err := repo.GetByTopic(ctx, user.ID, topic.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reps) != 1 || reps[0].RepetitionNumber != number {
+			t.Errorf("%s: repetitions %+v after a failed restart, want #%d kept", topic.Name, reps, number)
+		}
+	}
+}
+
+func TestGetDueRepetitionsForNotificationSkipsFirst(t *testing.T) {
+	dbtest.Open(t)
+	ctx := context.Background()
+	now := time.Date(2026, 2, 9, 9, 0, 0, 0, time.UTC)
+
+	user := dbtest.User(t, 42)
+	var topics []*models.Topic
+	for number := 1; number <= 5; number++ {
+		topics = append(topics, dbtest.Topic(t, user.ID, fmt.Sprintf("due %d", number), number, now.Add(-time.Hour)))
+	}
+	dbtest.Topic(t, user.ID, "tomorrow", 4, now.Add(24*time.Hour))
+	other := dbtest.User(t, 43)
+	dbtest.Topic(t, other.ID, "someone else's", 1, now.Add(-time.Hour))
+	attachments := database.NewTopicAttachmentRepository()
+	for _, content := range []string{"https://example.com/a", "https://example.com/b"} {
+		err := attachments.Create(ctx, &models.TopicAttachment{UserID: user.ID, TopicID: topics[4].ID, Kind: models.AttachmentLink, Content: content})
+		if err != nil {
+			t.Fatalf("failed to attach: %v", err)
+		}
+	}
+
+	repo := database.NewRepetitionRepositoryWithClock(clock.NewFake(now))
+	tests := []struct {
+		skipFirst int
+		want      []int
+	}{
+		{0, []int{1, 2, 3, 4, 5}},
+		{1, []int{2, 3, 4, 5}},
+		{2, []int{3, 4, 5}},
+		{4, []int{5}},
+		{5, nil},
+		{7, nil},
+	}
+	for _, tt := range tests {
+		reps, err := repo.GetDueRepetitionsForNotification(ctx, user.ID, tt.skipFirst)
+		if err != nil {
+			t.Fatalf("skip %d: %v", tt.skipFirst, err)
+		}
+		var got []int
+		for _, rep := range reps {
+			if rep.RepetitionNumber <= tt.skipFirst {
+				t.Errorf("skip %d: reminder for repetition #%d of %q", tt.skipFirst, rep.RepetitionNumber, rep.TopicName)
+			}
+			got = append(got, rep.RepetitionNumber)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("skip %d: repetitions %v, want %v", tt.skipFirst, got, tt.want)
+		}
+	}
+
+	// The sweep over all users reads the user's setting
+	user.SkipFirstRepetitions = 3
+	if err := database.NewUserRepository().Update(ctx, user); err != nil {
+		t.Fatalf("failed to update user: %v", err)
+	}
+	due, err := repo.GetDueForNotification(ctx, user.NotificationHour)
+	if err != nil {
+		t.Fatalf("failed to get due repetitions: %v", err)
+	}
+	var got []string
+	for _, rep := range due[user.ID] {
+		got = append(got, fmt.Sprintf("%s #%d, %d materials", rep.TopicName, rep.RepetitionNumber, rep.Materials))
+	}
+	if want := []string{"due 4 #4, 0 materials", "due 5 #5, 2 materials"}; !slices.Equal(got, want) {
+		t.Errorf("sweep finds %q, want %q", got, want)
+	}
+	if len(due[other.ID]) != 1 || due[other.ID][0].TopicName != "someone else's" {
+		t.Errorf("sweep finds %+v for the other user, want their topic", due[other.ID])
+	}
+}
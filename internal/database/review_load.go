@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/example/engbot/pkg/models"
+)
+
+// LoadBalanceDays is how many days ahead the review calendar is kept within the daily limit
+const LoadBalanceDays = 60
+
+// maxSpillDays is how far a repetition can be pushed past a full day
+const maxSpillDays = 30
+
+// pendingReview is a pending repetition that counts toward the daily review load
+type pendingReview struct {
+	ID             int64     `db:"id"`
+	NextReviewDate time.Time `db:"next_review_date"`
+}
+
+// dayStart returns the midnight the day of t starts at
+func dayStart(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// pendingReviews returns the user's pending repetitions due before the given time, oldest first.
+// Repetitions of archived, muted and stalled topics don't come up for review and don't count.
+func pendingReviews(ctx context.Context, userID int64, before time.Time) ([]pendingReview, error) {
+	var reviews []pendingReview
+	err := readDB.SelectContext(ctx, &reviews, `
+		SELECT r.id, r.next_review_date
+		FROM repetitions r
+		JOIN topics t ON r.topic_id = t.id
+		WHERE r.user_id = ?
+		AND r.completed = false
+		AND r.next_review_date < ?
+		AND t.archived = false
+		AND t.muted = false
+		AND t.stalled = false
+		ORDER BY r.next_review_date ASC, r.id ASC
+	`, userID, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending repetitions: %w", err)
+	}
+	return reviews, nil
+}
+
+// Forecast returns how many repetitions come due on each of the next days, starting with today.
+// Today also counts the overdue ones.
+func (r *RepetitionRepository) Forecast(ctx context.Context, userID int64, days int) ([]int, error) {
+	today := dayStart(r.clock.Now())
+	reviews, err := pendingReviews(ctx, userID, today.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int, days)
+	for _, review := range reviews {
+		day := 0
+		for day < days-1 && !review.NextReviewDate.Before(today.AddDate(0, 0, day+1)) {
+			day++
+		}
+		counts[day]++
+	}
+	return counts, nil
+}
+
+// SpillDate returns the date a new repetition due at date should get with at most limit
+// repetitions a day: the same time on the first day from date on that isn't full yet.
+// A limit of 0 means no limit.
+func (r *RepetitionRepository) SpillDate(ctx context.Context, userID int64, date time.Time, limit int) (time.Time, error) {
+	if limit <= 0 {
+		return date, nil
+	}
+	reviews, err := pendingReviews(ctx, userID, dayStart(date).AddDate(0, 0, maxSpillDays+1))
+	if err != nil {
+		return date, err
+	}
+
+	for i := 0; i < maxSpillDays; i++ {
+		start := dayStart(date)
+		count := 0
+		for _, review := range reviews {
+			if !review.NextReviewDate.Before(start) && review.NextReviewDate.Before(start.AddDate(0, 0, 1)) {
+				count++
+			}
+		}
+		if count < limit {
+			return date, nil
+		}
+		date = date.AddDate(0, 0, 1)
+	}
+	return date, nil
+}
+
+// Rebalance spreads the user's review calendar for the next days so that no day has more than
+// limit repetitions. The repetitions over the limit move to the start of the next day, the ones
+// due earliest stay in place. Returns the number of repetitions moved.
+func (r *RepetitionRepository) Rebalance(ctx context.Context, userID int64, limit, days int) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+	today := dayStart(r.clock.Now())
+	reviews, err := pendingReviews(ctx, userID, today.AddDate(0, 0, days))
+	if err != nil {
+		return 0, err
+	}
+
+	moves := make(map[int64]time.Time)
+	var carried []pendingReview
+	for day := 0; day < days; day++ {
+		end := today.AddDate(0, 0, day+1)
+		queue := carried
+		for len(reviews) > 0 && reviews[0].NextReviewDate.Before(end) {
+			queue = append(queue, reviews[0])
+			reviews = reviews[1:]
+		}
+		if len(queue) <= limit {
+			carried = nil
+			continue
+		}
+		carried = queue[limit:]
+		for _, review := range carried {
+			moves[review.ID] = end
+		}
+	}
+	if len(moves) == 0 {
+		return 0, nil
+	}
+
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for id, date := range moves {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE repetitions SET next_review_date = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND user_id = ?
+		`, date, id, userID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to move repetition %d: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(moves), nil
+}
+
+// DayCount is the number of repetitions due on a day in dayLayout
+type DayCount struct {
+	Day   string `db:"day"`
+	Count int    `db:"count"`
+}
+
+// CountByDay groups the user's pending repetitions due before the given time by the day they
+// are due on, in ascending order. Overdue repetitions keep their past days.
+func (r *RepetitionRepository) CountByDay(ctx context.Context, userID int64, before time.Time) ([]DayCount, error) {
+	var counts []DayCount
+	err := readDB.SelectContext(ctx, &counts, `
+		SELECT CAST(DATE(r.next_review_date) AS TEXT) AS day, COUNT(*) AS count
+		FROM repetitions r
+		JOIN topics t ON r.topic_id = t.id
+		WHERE r.user_id = ?
+		AND r.completed = false
+		AND r.next_review_date < ?
+		AND t.archived = false
+		AND t.muted = false
+		AND t.stalled = false
+		GROUP BY CAST(DATE(r.next_review_date) AS TEXT)
+		ORDER BY day ASC
+	`, userID, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count repetitions by day: %w", err)
+	}
+	return counts, nil
+}
+
+// GetUpcoming returns the user's pending repetitions with their topic names, the earliest first.
+// Like the review load, it leaves out archived, muted and stalled topics.
+func (r *RepetitionRepository) GetUpcoming(ctx context.Context, userID int64) ([]models.Repetition, error) {
+	var repetitions []models.Repetition
+	err := readDB.SelectContext(ctx, &repetitions, `
+		SELECT r.*, t.name AS topic_name
+		FROM repetitions r
+		JOIN topics t ON r.topic_id = t.id
+		WHERE r.user_id = ?
+		AND r.completed = false
+		AND t.archived = false
+		AND t.muted = false
+		AND t.stalled = false
+		ORDER BY r.next_review_date ASC, r.id ASC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upcoming repetitions: %w", err)
+	}
+	return repetitions, nil
+}
@@ -2,30 +2,88 @@
 CREATE TABLE IF NOT EXISTS topics (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     user_id INTEGER NOT NULL,
+    parent_id INTEGER NOT NULL DEFAULT 0,
     name TEXT NOT NULL,
-    description TEXT,
+    description TEXT NOT NULL DEFAULT '',
+    difficulty INTEGER DEFAULT 3,
+    archived BOOLEAN DEFAULT false,
+    muted BOOLEAN DEFAULT false,
+    stalled BOOLEAN DEFAULT false,
+    maintenance BOOLEAN NOT NULL DEFAULT false,
+    category TEXT NOT NULL DEFAULT '',
+    custom_intervals TEXT NOT NULL DEFAULT '',
+    review_direction TEXT NOT NULL DEFAULT '',
+    easiness_factor REAL DEFAULT 2.5,
+    review_interval INTEGER DEFAULT 0,
+    review_count INTEGER DEFAULT 0,
+    leitner_box INTEGER NOT NULL DEFAULT 1,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id),
     UNIQUE(user_id, name)
 );
 
+CREATE INDEX IF NOT EXISTS idx_topics_parent_id ON topics(parent_id);
+
 -- Create words table
 CREATE TABLE IF NOT EXISTS words (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     word TEXT NOT NULL,
+    kind TEXT NOT NULL DEFAULT 'word',
     translation TEXT NOT NULL,
     description TEXT,
     examples TEXT,
+    verb_forms TEXT,
     topic_id INTEGER NOT NULL,
+    user_id INTEGER REFERENCES users(id),
     difficulty INTEGER DEFAULT 1,
     pronunciation TEXT,
+    enrichment_status TEXT NOT NULL DEFAULT '',
+    synonyms TEXT NOT NULL DEFAULT '',
+    antonyms TEXT NOT NULL DEFAULT '',
+    synonyms_checked BOOLEAN NOT NULL DEFAULT false,
+    definition TEXT NOT NULL DEFAULT '',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (topic_id) REFERENCES topics(id),
     UNIQUE(word, topic_id)
 );
 
+CREATE INDEX IF NOT EXISTS idx_words_user_id ON words(user_id);
+CREATE INDEX IF NOT EXISTS idx_words_enrichment_status ON words(enrichment_status);
+
+-- Create decks tables: curated word collections users can subscribe to
+CREATE TABLE IF NOT EXISTS decks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS deck_words (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    deck_id INTEGER NOT NULL,
+    word TEXT NOT NULL,
+    kind TEXT NOT NULL DEFAULT 'word',
+    translation TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    examples TEXT NOT NULL DEFAULT '',
+    verb_forms TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (deck_id) REFERENCES decks(id),
+    UNIQUE(deck_id, word)
+);
+
+CREATE TABLE IF NOT EXISTS deck_subscriptions (
+    user_id INTEGER NOT NULL,
+    deck_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, deck_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (deck_id) REFERENCES decks(id)
+);
+
 -- Create learned_words table to track word learning progress
 CREATE TABLE IF NOT EXISTS learned_words (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -42,6 +100,7 @@ CREATE TABLE IF NOT EXISTS user_progress (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     user_id INTEGER NOT NULL,
     word_id INTEGER NOT NULL,
+    direction TEXT NOT NULL DEFAULT 'en_ru',
     easiness_factor REAL DEFAULT 2.5,
     interval INTEGER DEFAULT 1,
     repetitions INTEGER DEFAULT 0,
@@ -50,12 +109,14 @@ CREATE TABLE IF NOT EXISTS user_progress (
     is_learned BOOLEAN DEFAULT FALSE,
     last_review_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     next_review_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    introduced_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id),
     FOREIGN KEY (word_id) REFERENCES words(id),
-    UNIQUE(user_id, word_id)
+    UNIQUE(user_id, word_id, direction)
 );
+CREATE INDEX IF NOT EXISTS idx_user_progress_introduced ON user_progress(user_id, introduced_at);
 
 -- Create user_configs table to store user preferences
 CREATE TABLE IF NOT EXISTS user_configs (
@@ -64,6 +125,16 @@ CREATE TABLE IF NOT EXISTS user_configs (
     repetitions INTEGER NOT NULL DEFAULT 3,
     is_active BOOLEAN NOT NULL DEFAULT TRUE,
     notification_hour INTEGER NOT NULL DEFAULT 9,
+    interval_preset TEXT NOT NULL DEFAULT 'standard',
+    custom_intervals TEXT NOT NULL DEFAULT '',
+    sm2_pass_threshold INTEGER NOT NULL DEFAULT 0,
+    sm2_initial_intervals TEXT NOT NULL DEFAULT '',
+    sm2_min_easiness REAL NOT NULL DEFAULT 0,
+    sm2_tuned_at DATETIME,
+    new_words_per_day INTEGER NOT NULL DEFAULT 20,
+    word_of_day_enabled BOOLEAN NOT NULL DEFAULT false,
+    word_of_day_deck_id INTEGER NOT NULL DEFAULT 0,
+    review_direction TEXT NOT NULL DEFAULT 'en_ru',
     last_batch_time DATETIME,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -79,6 +150,26 @@ CREATE TABLE IF NOT EXISTS users (
     last_name TEXT,
     notification_enabled BOOLEAN DEFAULT true,
     notification_hour INTEGER DEFAULT 9,
+    skip_first_repetitions INTEGER DEFAULT 0,
+    digest_enabled BOOLEAN DEFAULT false,
+    language TEXT NOT NULL DEFAULT '',
+    broadcast_opt_out BOOLEAN DEFAULT false,
+    notification_hours TEXT NOT NULL DEFAULT '',
+    quiet_hours_start INTEGER DEFAULT 0,
+    quiet_hours_end INTEGER DEFAULT 0,
+    daily_goal INTEGER DEFAULT 0,
+    story_enabled BOOLEAN DEFAULT false,
+    board_enabled BOOLEAN DEFAULT false,
+    board_message_id INTEGER DEFAULT 0,
+    overdue_policy TEXT NOT NULL DEFAULT 'remind',
+    overdue_days INTEGER NOT NULL DEFAULT 7,
+    daily_review_limit INTEGER NOT NULL DEFAULT 0,
+    report_enabled BOOLEAN DEFAULT true,
+    report_day INTEGER NOT NULL DEFAULT 0,
+    report_hour INTEGER NOT NULL DEFAULT 19,
+    scheduler TEXT NOT NULL DEFAULT 'sm2',
+    inactive_since TIMESTAMP,
+    is_admin BOOLEAN DEFAULT false,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -92,12 +183,41 @@ CREATE TABLE IF NOT EXISTS repetitions (
     next_review_date TIMESTAMP NOT NULL,
     last_review_date TIMESTAMP,
     completed BOOLEAN DEFAULT false,
+    maintenance BOOLEAN NOT NULL DEFAULT false,
+    notes TEXT NOT NULL DEFAULT '',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id),
     FOREIGN KEY (topic_id) REFERENCES topics(id)
 );
 
+-- Create daily_activity table: reviews per day for the daily goal and the streak
+CREATE TABLE IF NOT EXISTS daily_activity (
+    user_id INTEGER NOT NULL,
+    day TEXT NOT NULL,
+    reviews INTEGER NOT NULL DEFAULT 0,
+    goal INTEGER NOT NULL DEFAULT 0,
+    protected BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (user_id, day),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Create test_results table: finished /quiz tests, topics as comma-separated topic IDs
+CREATE TABLE IF NOT EXISTS test_results (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    test_type TEXT NOT NULL,
+    total_words INTEGER NOT NULL DEFAULT 0,
+    correct_words INTEGER NOT NULL DEFAULT 0,
+    topics TEXT NOT NULL DEFAULT '',
+    test_date TIMESTAMP NOT NULL,
+    duration INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_results_user_id ON test_results(user_id);
+
 -- Create statistics table
 CREATE TABLE IF NOT EXISTS statistics (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -110,4 +230,113 @@ CREATE TABLE IF NOT EXISTS statistics (
     FOREIGN KEY (user_id) REFERENCES users(id),
     FOREIGN KEY (topic_id) REFERENCES topics(id),
     UNIQUE(user_id, topic_id)
-); 
\ No newline at end of file
+); 
+
+-- Create pronunciations table: Telegram file IDs of the generated audio, shared by all users
+CREATE TABLE IF NOT EXISTS pronunciations (
+    text TEXT PRIMARY KEY,
+    file_id TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create notification_log table: each kind of notification is sent at most once a day
+CREATE TABLE IF NOT EXISTS notification_log (
+    user_id INTEGER NOT NULL,
+    day TEXT NOT NULL,
+    type TEXT NOT NULL,
+    sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, day, type),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Create topic_attachments table: links, Telegram files and notes with the study material of a topic
+CREATE TABLE IF NOT EXISTS topic_attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    topic_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    content TEXT NOT NULL,
+    caption TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (topic_id) REFERENCES topics(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_topic_attachments_topic_id ON topic_attachments(topic_id);
+
+-- Create topic_trash table: deleted topics with their rows, restorable for a short while
+CREATE TABLE IF NOT EXISTS topic_trash (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    data TEXT NOT NULL,
+    deleted_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_topic_trash_deleted_at ON topic_trash(deleted_at);
+
+-- Create leases table: a lease lets one of several bot instances do a job, e.g. run the scheduler
+CREATE TABLE IF NOT EXISTS leases (
+    name TEXT PRIMARY KEY,
+    holder TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Create calendar_feeds table: the secret token in the URL of a user's calendar of upcoming reviews
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id INTEGER PRIMARY KEY,
+    token TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Create notification_channels table: where else than Telegram a user gets the reminders, e.g. email
+CREATE TABLE IF NOT EXISTS notification_channels (
+    user_id INTEGER NOT NULL,
+    channel TEXT NOT NULL,
+    address TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, channel),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Create notification_queue table: notifications that failed to send and are retried with backoff
+CREATE TABLE IF NOT EXISTS notification_queue (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    message TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP NOT NULL,
+    failed BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_queue_next_attempt ON notification_queue(failed, next_attempt_at);
+
+-- Create delivery_failures table: users the bot can't reach, e.g. because they blocked it
+CREATE TABLE IF NOT EXISTS delivery_failures (
+    user_id INTEGER PRIMARY KEY,
+    blocked INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL,
+    last_failed_at TIMESTAMP NOT NULL,
+    disabled BOOLEAN NOT NULL DEFAULT false,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Create word_of_day table: the words of the day sent to users and how their quizzes were answered
+CREATE TABLE IF NOT EXISTS word_of_day (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    deck_id INTEGER NOT NULL DEFAULT 0,
+    word_id INTEGER NOT NULL,
+    word TEXT NOT NULL,
+    translation TEXT NOT NULL,
+    correct BOOLEAN,
+    sent_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_word_of_day_user ON word_of_day(user_id, word);
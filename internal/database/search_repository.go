@@ -0,0 +1,224 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"github.com/example/engbot/pkg/models"
+)
+
+// ftsEnabled reports whether SQLite searches the FTS5 index, see setupSearch
+var ftsEnabled bool
+
+// searchColumns are the word columns every search returns
+const searchColumns = `
+	w.id, w.word, w.kind, w.translation, COALESCE(w.description, '') AS description, w.topic_id,
+	COALESCE(w.user_id, 0) AS user_id, w.difficulty, COALESCE(w.pronunciation, '') AS pronunciation,
+	COALESCE(w.examples, '') AS examples, COALESCE(w.verb_forms, '') AS verb_forms,
+	w.created_at, w.updated_at`
+
+// topicSearchColumns are the topic columns every search returns
+const topicSearchColumns = `
+	t.id, t.user_id, t.parent_id, t.name, COALESCE(t.description, '') AS description, t.difficulty,
+	t.archived, t.muted, t.stalled, t.maintenance, t.category, t.custom_intervals, t.review_direction, t.easiness_factor, t.review_interval, t.review_count, t.leitner_box,
+	t.created_at, t.updated_at`
+
+// ftsStatements create the FTS5 tables over words and topics and the triggers that keep them in sync
+var ftsStatements = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS words_fts USING fts5(
+		word, translation, examples, content='words', content_rowid='id', tokenize='unicode61 remove_diacritics 2')`,
+	`CREATE VIRTUAL TABLE IF NOT EXISTS topics_fts USING fts5(
+		name, description, content='topics', content_rowid='id', tokenize='unicode61 remove_diacritics 2')`,
+	`CREATE TRIGGER IF NOT EXISTS words_fts_insert AFTER INSERT ON words BEGIN
+		INSERT INTO words_fts (rowid, word, translation, examples) VALUES (new.id, new.word, new.translation, new.examples);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS words_fts_delete AFTER DELETE ON words BEGIN
+		INSERT INTO words_fts (words_fts, rowid, word, translation, examples) VALUES ('delete', old.id, old.word, old.translation, old.examples);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS words_fts_update AFTER UPDATE ON words BEGIN
+		INSERT INTO words_fts (words_fts, rowid, word, translation, examples) VALUES ('delete', old.id, old.word, old.translation, old.examples);
+		INSERT INTO words_fts (rowid, word, translation, examples) VALUES (new.id, new.word, new.translation, new.examples);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS topics_fts_insert AFTER INSERT ON topics BEGIN
+		INSERT INTO topics_fts (rowid, name, description) VALUES (new.id, new.name, new.description);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS topics_fts_delete AFTER DELETE ON topics BEGIN
+		INSERT INTO topics_fts (topics_fts, rowid, name, description) VALUES ('delete', old.id, old.name, old.description);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS topics_fts_update AFTER UPDATE ON topics BEGIN
+		INSERT INTO topics_fts (topics_fts, rowid, name, description) VALUES ('delete', old.id, old.name, old.description);
+		INSERT INTO topics_fts (rowid, name, description) VALUES (new.id, new.name, new.description);
+	END`,
+}
+
+// ftsTriggers are the names of the triggers from ftsStatements
+var ftsTriggers = []string{
+	"words_fts_insert", "words_fts_delete", "words_fts_update",
+	"topics_fts_insert", "topics_fts_delete", "topics_fts_update",
+}
+
+// setupSearch prepares the SQLite full-text index. FTS5 is only there when the driver is built
+// with -tags sqlite_fts5; without it the sync triggers are dropped, so writes keep working,
+// and search falls back to LIKE. The index is rebuilt whenever the triggers had to be created,
+// so it catches up with the changes made while they were missing.
+func setupSearch(ctx context.Context) error {
+	ftsEnabled = false
+	if isPostgres() {
+		return nil
+	}
+
+	var available bool
+	if err := DB.GetContext(ctx, &available, "SELECT sqlite_compileoption_used('ENABLE_FTS5')"); err != nil {
+		return fmt.Errorf("failed to check FTS5 support: %w", err)
+	}
+	if !available {
+		for _, trigger := range ftsTriggers {
+			if _, err := DB.ExecContext(ctx, "DROP TRIGGER IF EXISTS "+trigger); err != nil {
+				return fmt.Errorf("failed to drop search trigger: %w", err)
+			}
+		}
+		slog.Info("SQLite is built without FTS5, /search scans the tables; build with -tags sqlite_fts5 for the full-text index")
+		return nil
+	}
+
+	var triggers int
+	err := DB.GetContext(ctx, &triggers, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN ("+
+		placeholders(len(ftsTriggers))+")", stringArgs(ftsTriggers)...)
+	if err != nil {
+		return fmt.Errorf("failed to check search triggers: %w", err)
+	}
+	if triggers < len(ftsTriggers) {
+		tx, err := DB.BeginTxx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to start transaction: %w", err)
+		}
+		defer tx.Rollback()
+		for _, stmt := range ftsStatements {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("failed to create search index: %w", err)
+			}
+		}
+		for _, table := range []string{"words_fts", "topics_fts"} {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES ('rebuild')", table, table)); err != nil {
+				return fmt.Errorf("failed to build search index: %w", err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		slog.Info("full-text search index built")
+	}
+
+	ftsEnabled = true
+	return nil
+}
+
+// SearchResults are the topics and words matching a search, best matches first
+type SearchResults struct {
+	Topics []models.Topic
+	Words  []models.Word
+}
+
+// SearchRepository searches the user's topics and words
+type SearchRepository struct{}
+
+// NewSearchRepository creates a new repository instance
+func NewSearchRepository() *SearchRepository {
+	return &SearchRepository{}
+}
+
+// Search finds up to limit topics by name and description and up to limit words by spelling,
+// translation and examples. Every term of the query has to match, as a word prefix.
+func (r *SearchRepository) Search(ctx context.Context, userID int64, query string, limit int) (*SearchResults, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return &SearchResults{}, nil
+	}
+
+	var topicsQuery, wordsQuery string
+	var topicArgs, wordArgs []interface{}
+	switch {
+	case isPostgres():
+		// Prefix matching of every term, ranked by ts_rank over the indexed expressions
+		tsquery := strings.Join(terms, ":* & ") + ":*"
+		topicsQuery = `SELECT ` + topicSearchColumns + ` FROM topics t
+			WHERE t.user_id = ? AND to_tsvector('simple', t.name || ' ' || COALESCE(t.description, '')) @@ to_tsquery('simple', ?)
+			ORDER BY ts_rank(to_tsvector('simple', t.name || ' ' || COALESCE(t.description, '')), to_tsquery('simple', ?)) DESC, t.name
+			LIMIT ?`
+		wordsQuery = `SELECT ` + searchColumns + ` FROM words w
+			WHERE w.user_id = ? AND to_tsvector('simple', w.word || ' ' || w.translation || ' ' || COALESCE(w.examples, '')) @@ to_tsquery('simple', ?)
+			ORDER BY ts_rank(to_tsvector('simple', w.word || ' ' || w.translation || ' ' || COALESCE(w.examples, '')), to_tsquery('simple', ?)) DESC, w.word
+			LIMIT ?`
+		topicArgs = []interface{}{userID, tsquery, tsquery, limit}
+		wordArgs = []interface{}{userID, tsquery, tsquery, limit}
+	case ftsEnabled:
+		// bm25 ranks matches in the spelling and the name above the ones in examples and descriptions
+		match := `"` + strings.Join(terms, `"* "`) + `"*`
+		topicsQuery = `SELECT ` + topicSearchColumns + ` FROM topics_fts f JOIN topics t ON t.id = f.rowid
+			WHERE topics_fts MATCH ? AND t.user_id = ?
+			ORDER BY bm25(topics_fts, 10.0, 1.0), t.name
+			LIMIT ?`
+		wordsQuery = `SELECT ` + searchColumns + ` FROM words_fts f JOIN words w ON w.id = f.rowid
+			WHERE words_fts MATCH ? AND w.user_id = ?
+			ORDER BY bm25(words_fts, 10.0, 5.0, 1.0), w.word
+			LIMIT ?`
+		topicArgs = []interface{}{match, userID, limit}
+		wordArgs = []interface{}{match, userID, limit}
+	default:
+		// Every term somewhere in the text; exact and prefix matches of the whole query first
+		phrase := strings.Join(terms, " ")
+		topicsQuery, topicArgs = likeSearch(`SELECT `+topicSearchColumns+` FROM topics t WHERE t.user_id = ?`,
+			"LOWER(t.name)", "LOWER(t.name || ' ' || COALESCE(t.description, ''))", "t.name", userID, terms, phrase, limit)
+		wordsQuery, wordArgs = likeSearch(`SELECT `+searchColumns+` FROM words w WHERE w.user_id = ?`,
+			"LOWER(w.word)", "LOWER(w.word || ' ' || w.translation || ' ' || COALESCE(w.examples, ''))", "w.word", userID, terms, phrase, limit)
+	}
+
+	results := &SearchResults{}
+	if err := readDB.SelectContext(ctx, &results.Topics, topicsQuery, topicArgs...); err != nil {
+		return nil, fmt.Errorf("failed to search topics: %w", err)
+	}
+	if err := readDB.SelectContext(ctx, &results.Words, wordsQuery, wordArgs...); err != nil {
+		return nil, fmt.Errorf("failed to search words: %w", err)
+	}
+	return results, nil
+}
+
+// likeSearch completes the base query with a LIKE condition per term over text and the ranking
+// of the whole phrase against title
+func likeSearch(base, title, text, order string, userID int64, terms []string, phrase string, limit int) (string, []interface{}) {
+	var query strings.Builder
+	query.WriteString(base)
+	args := []interface{}{userID}
+	for _, term := range terms {
+		query.WriteString(" AND " + text + ` LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(term)+"%")
+	}
+	query.WriteString(fmt.Sprintf(` ORDER BY CASE WHEN %s = ? THEN 0 WHEN %s LIKE ? ESCAPE '\' THEN 1 ELSE 2 END, %s LIMIT ?`, title, title, order))
+	args = append(args, phrase, escapeLike(phrase)+"%", limit)
+	return query.String(), args
+}
+
+// searchTerms splits the query into lowercase words of letters and digits. Everything else
+// is dropped, so the terms are safe inside FTS5 and tsquery expressions.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// placeholders returns n comma-separated "?" placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// stringArgs converts strings to query arguments
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
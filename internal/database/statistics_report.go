@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PeriodSummary is what a user did over a period of time
+type PeriodSummary struct {
+	Completed    int // repetitions of topics completed
+	Reviews      int // reviews counted toward the daily goal: completed repetitions and rated flashcards
+	WordsLearned int // words that became learned
+}
+
+// TopicResult is how a user kept up with the repetitions of one topic over a period of time
+type TopicResult struct {
+	TopicID   int64  `db:"topic_id"`
+	TopicName string `db:"topic_name"`
+	Due       int    `db:"due"`       // repetitions that came due in the period
+	Completed int    `db:"completed"` // of them, the completed ones
+}
+
+// Rate returns the share of the due repetitions that were completed, from 0 to 1
+func (t TopicResult) Rate() float64 {
+	if t.Due == 0 {
+		return 0
+	}
+	return float64(t.Completed) / float64(t.Due)
+}
+
+// GetPeriodSummary counts what the user did from from up to to
+func (r *StatisticsRepository) GetPeriodSummary(ctx context.Context, userID int64, from, to time.Time) (*PeriodSummary, error) {
+	var summary PeriodSummary
+
+	err := readDB.GetContext(ctx, &summary.Completed, `
+		SELECT COUNT(*) FROM repetitions
+		WHERE user_id = ? AND completed = true
+		AND last_review_date >= ? AND last_review_date < ?
+	`, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count completed repetitions: %w", err)
+	}
+
+	err = readDB.GetContext(ctx, &summary.Reviews, `
+		SELECT COALESCE(SUM(reviews), 0) FROM daily_activity
+		WHERE user_id = ? AND day >= ? AND day < ?
+	`, userID, from.Format(dayLayout), to.Format(dayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reviews: %w", err)
+	}
+
+	err = readDB.GetContext(ctx, &summary.WordsLearned, `
+		SELECT COUNT(DISTINCT word_id) FROM user_progress
+		WHERE user_id = ? AND is_learned = true
+		AND updated_at >= ? AND updated_at < ?
+	`, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count learned words: %w", err)
+	}
+
+	return &summary, nil
+}
+
+// GetTopicResults returns, for each topic with repetitions due from from up to to, how many came
+// due and how many of them were completed. Archived topics are left out.
+func (r *StatisticsRepository) GetTopicResults(ctx context.Context, userID int64, from, to time.Time) ([]TopicResult, error) {
+	var results []TopicResult
+	err := readDB.SelectContext(ctx, &results, `
+		SELECT t.id AS topic_id, t.name AS topic_name,
+			COUNT(*) AS due,
+			SUM(CASE WHEN r.completed = true THEN 1 ELSE 0 END) AS completed
+		FROM repetitions r
+		JOIN topics t ON r.topic_id = t.id
+		WHERE r.user_id = ?
+		AND r.next_review_date >= ? AND r.next_review_date < ?
+		AND t.archived = false
+		GROUP BY t.id, t.name
+		ORDER BY t.name ASC
+	`, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic results: %w", err)
+	}
+	return results, nil
+}
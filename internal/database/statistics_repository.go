@@ -19,13 +19,12 @@ func NewStatisticsRepository() *StatisticsRepository {
 // GetByUserAndTopic returns statistics for a specific user and topic
 func (r *StatisticsRepository) GetByUserAndTopic(ctx context.Context, userID, topicID int64) (*models.Statistics, error) {
     query := `
-        SELECT id, user_id, topic_id, total_repetitions, completed_repetitions,
-               created_at, updated_at
+        SELECT id, user_id, topic_id, total_repetitions, completed_repetitions
         FROM statistics
         WHERE user_id = ? AND topic_id = ?
     `
     var stats models.Statistics
-    err := DB.GetContext(ctx, &stats, query, userID, topicID)
+    err := readDB.GetContext(ctx, &stats, query, userID, topicID)
     if err == sql.ErrNoRows {
         // Если статистики нет, создаем новую
         stats = models.Statistics{
@@ -34,40 +33,19 @@ func (r *StatisticsRepository) GetByUserAndTopic(ctx context.Context, userID, to
         }
         err = r.Create(ctx, &stats)
         if err != nil {
-            return nil, fmt.Errorf("failed to create statistics: %v", err)
+            return nil, fmt.Errorf("failed to create statistics: %w", err)
         }
         return &stats, nil
     }
     if err != nil {
-        return nil, fmt.Errorf("failed to get statistics: %v", err)
+        return nil, fmt.Errorf("failed to get statistics: %w", err)
     }
     return &stats, nil
 }
 
 // Create inserts new statistics
 func (r *StatisticsRepository) Create(ctx context.Context, stats *models.Statistics) error {
-    query := `
-        INSERT INTO statistics (
-            user_id, topic_id, total_repetitions, completed_repetitions
-        ) VALUES (?, ?, ?, ?)
-    `
-    result, err := DB.ExecContext(ctx, query,
-        stats.UserID,
-        stats.TopicID,
-        stats.TotalRepetitions,
-        stats.CompletedRepetitions,
-    )
-    if err != nil {
-        return fmt.Errorf("failed to create statistics: %v", err)
-    }
-
-    id, err := result.LastInsertId()
-    if err != nil {
-        return fmt.Errorf("failed to get last insert ID: %v", err)
-    }
-    stats.ID = id
-
-    return nil
+    return createStatistics(ctx, DB, stats)
 }
 
 // Update modifies existing statistics
@@ -75,8 +53,7 @@ func (r *StatisticsRepository) Update(ctx context.Context, stats *models.Statist
     query := `
         UPDATE statistics SET
             total_repetitions = ?,
-            completed_repetitions = ?,
-            updated_at = CURRENT_TIMESTAMP
+            completed_repetitions = ?
         WHERE id = ? AND user_id = ?
     `
     result, err := DB.ExecContext(ctx, query,
@@ -86,15 +63,15 @@ func (r *StatisticsRepository) Update(ctx context.Context, stats *models.Statist
         stats.UserID,
     )
     if err != nil {
-        return fmt.Errorf("failed to update statistics: %v", err)
+        return fmt.Errorf("failed to update statistics: %w", err)
     }
 
     rows, err := result.RowsAffected()
     if err != nil {
-        return fmt.Errorf("failed to get rows affected: %v", err)
+        return fmt.Errorf("failed to get rows affected: %w", err)
     }
     if rows == 0 {
-        return fmt.Errorf("statistics not found or user doesn't have permission")
+        return fmt.Errorf("statistics %w or user doesn't have permission", ErrNotFound)
     }
 
     return nil
@@ -110,24 +87,15 @@ func (r *StatisticsRepository) GetUserStatistics(ctx context.Context, userID int
         ORDER BY s.total_repetitions DESC
     `
     var stats []models.Statistics
-    err := DB.SelectContext(ctx, &stats, query, userID)
+    err := readDB.SelectContext(ctx, &stats, query, userID)
     if err != nil {
-        return nil, fmt.Errorf("failed to get user statistics: %v", err)
+        return nil, fmt.Errorf("failed to get user statistics: %w", err)
     }
     return stats, nil
 }
 
-// IncrementRepetitions increments the repetition counters
+// IncrementRepetitions increments the repetition counters. Reviews of topics are counted by
+// RepetitionRepository.Complete in the same transaction as the review itself.
 func (r *StatisticsRepository) IncrementRepetitions(ctx context.Context, userID, topicID int64, completed bool) error {
-    stats, err := r.GetByUserAndTopic(ctx, userID, topicID)
-    if err != nil {
-        return err
-    }
-
-    stats.TotalRepetitions++
-    if completed {
-        stats.CompletedRepetitions++
-    }
-
-    return r.Update(ctx, stats)
+    return incrementRepetitions(ctx, DB, userID, topicID, completed)
 } 
\ No newline at end of file
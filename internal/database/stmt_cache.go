@@ -0,0 +1,212 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+)
+
+// maxCachedStatements is how many prepared statements each SQLite connection keeps
+const maxCachedStatements = 128
+
+// sqliteConnector opens SQLite connections that keep their prepared statements
+type sqliteConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &stmtCacheConn{Conn: conn, stmts: make(map[string]*list.Element), lru: list.New()}, nil
+}
+
+func (c sqliteConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// stmtCacheConn runs every query through a statement prepared once per connection instead of
+// preparing and finalizing it on each call. Beyond maxCachedStatements the least recently used
+// statement is finalized, so queries built with their values inline don't hold the cache for
+// good. Queries with several statements, like the schema migrations, go to the connection as
+// they are. database/sql never uses a connection from two goroutines at once, so the cache
+// needs no lock.
+type stmtCacheConn struct {
+	driver.Conn
+	stmts map[string]*list.Element // of lru
+	lru   *list.List               // *cachedStmt, the most recently used first
+}
+
+// cachedStmt is a prepared statement with the rows currently read from it, if any
+type cachedStmt struct {
+	driver.Stmt
+	query string
+	busy  bool
+	// evicted statements are finalized as soon as their rows are closed
+	evicted bool
+}
+
+// statement returns the cached statement of the query, preparing it on first use. It returns
+// nil when the query isn't cached or its statement is still busy with open rows.
+func (c *stmtCacheConn) statement(ctx context.Context, query string) (*cachedStmt, error) {
+	if elem, ok := c.stmts[query]; ok {
+		stmt := elem.Value.(*cachedStmt)
+		if stmt.busy {
+			return nil, nil
+		}
+		c.lru.MoveToFront(elem)
+		return stmt, nil
+	}
+	if multipleStatements(query) {
+		return nil, nil
+	}
+
+	prepared, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	stmt := &cachedStmt{Stmt: prepared, query: query}
+	c.stmts[query] = c.lru.PushFront(stmt)
+	if c.lru.Len() > maxCachedStatements {
+		c.evict(c.lru.Back())
+	}
+	return stmt, nil
+}
+
+// evict drops the statement from the cache and finalizes it, once its rows are closed if it is busy
+func (c *stmtCacheConn) evict(elem *list.Element) {
+	stmt := c.lru.Remove(elem).(*cachedStmt)
+	delete(c.stmts, stmt.query)
+	if stmt.busy {
+		stmt.evicted = true
+		return
+	}
+	stmt.Stmt.Close()
+}
+
+func (c *stmtCacheConn) prepare(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *stmtCacheConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	stmt, err := c.statement(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		if e, ok := c.Conn.(driver.ExecerContext); ok {
+			return e.ExecContext(ctx, query, args)
+		}
+		return nil, driver.ErrSkip
+	}
+	return stmt.Stmt.(driver.StmtExecContext).ExecContext(ctx, args)
+}
+
+func (c *stmtCacheConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	stmt, err := c.statement(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		if q, ok := c.Conn.(driver.QueryerContext); ok {
+			return q.QueryContext(ctx, query, args)
+		}
+		return nil, driver.ErrSkip
+	}
+
+	rows, err := stmt.Stmt.(driver.StmtQueryContext).QueryContext(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	stmt.busy = true
+	return &cachedRows{Rows: rows, stmt: stmt}, nil
+}
+
+func (c *stmtCacheConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.prepare(ctx, query)
+}
+
+func (c *stmtCacheConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *stmtCacheConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *stmtCacheConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *stmtCacheConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// Close finalizes the cached statements before closing the connection
+func (c *stmtCacheConn) Close() error {
+	for c.lru.Len() > 0 {
+		c.evict(c.lru.Front())
+	}
+	return c.Conn.Close()
+}
+
+// cachedRows frees its statement for the next query when closed
+type cachedRows struct {
+	driver.Rows
+	stmt *cachedStmt
+}
+
+func (r *cachedRows) Close() error {
+	err := r.Rows.Close()
+	r.stmt.busy = false
+	if r.stmt.evicted {
+		r.stmt.Stmt.Close()
+	}
+	return err
+}
+
+func (r *cachedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if t, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return t.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *cachedRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if t, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return t.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *cachedRows) ColumnTypeScanType(index int) reflect.Type {
+	if t, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return t.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// multipleStatements reports whether the query has more than one statement. A semicolon inside a
+// string literal counts too, which only means such a query isn't cached.
+func multipleStatements(query string) bool {
+	return strings.Contains(strings.TrimRight(strings.TrimSpace(query), ";"), ";")
+}
@@ -0,0 +1,237 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// openCacheTestDB opens a SQLite file with a table of numbers 1 to 10
+func openCacheTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := openSQLite(sqliteDSN(filepath.Join(t.TempDir(), "cache.db"), "_busy_timeout=5000"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec("CREATE TABLE numbers (n INTEGER PRIMARY KEY, seen INTEGER NOT NULL DEFAULT 0)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	for n := 1; n <= 10; n++ {
+		if _, err := db.Exec("INSERT INTO numbers (n) VALUES (?)", n); err != nil {
+			t.Fatalf("failed to insert: %v", err)
+		}
+	}
+	return db.DB
+}
+
+// cachedQueries returns the queries cached by the connection, the most recently used first
+func cachedQueries(t *testing.T, conn *sql.Conn) []string {
+	t.Helper()
+	var queries []string
+	err := conn.Raw(func(driverConn any) error {
+		c := driverConn.(*stmtCacheConn)
+		for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+			queries = append(queries, elem.Value.(*cachedStmt).query)
+		}
+		if len(queries) != len(c.stmts) {
+			t.Errorf("%d statements in the list, %d in the map", len(queries), len(c.stmts))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to get the connection: %v", err)
+	}
+	return queries
+}
+
+// sumNumbers reads the numbers from the rows and adds them up. It only reports errors, as it
+// runs in other goroutines too.
+func sumNumbers(t *testing.T, rows *sql.Rows) int {
+	t.Helper()
+	defer rows.Close()
+	sum := 0
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			t.Errorf("failed to scan: %v", err)
+		}
+		sum += n
+	}
+	if err := rows.Err(); err != nil {
+		t.Errorf("failed to read rows: %v", err)
+	}
+	return sum
+}
+
+func TestStmtCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	conn, err := openCacheTestDB(t).Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get a connection: %v", err)
+	}
+	defer conn.Close()
+
+	const kept = "SELECT COUNT(*) FROM numbers"
+	var count int
+	for i := 0; i < 3*maxCachedStatements; i++ {
+		// Like the queries built with Sprintf, each one new
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("UPDATE numbers SET seen = %d WHERE n = 1", i)); err != nil {
+			t.Fatalf("update %d: %v", i, err)
+		}
+		if i%10 == 0 {
+			if err := conn.QueryRowContext(ctx, kept).Scan(&count); err != nil {
+				t.Fatalf("count: %v", err)
+			}
+		}
+	}
+
+	queries := cachedQueries(t, conn)
+	if len(queries) != maxCachedStatements {
+		t.Fatalf("%d statements cached, want %d", len(queries), maxCachedStatements)
+	}
+	if queries[0] != fmt.Sprintf("UPDATE numbers SET seen = %d WHERE n = 1", 3*maxCachedStatements-1) {
+		t.Errorf("most recent statement is %q", queries[0])
+	}
+	if !slices.Contains(queries, kept) {
+		t.Errorf("the statement used all along was evicted")
+	}
+	if slices.Contains(queries, "UPDATE numbers SET seen = 0 WHERE n = 1") {
+		t.Errorf("the first statement is still cached")
+	}
+
+	// An evicted statement is prepared again
+	if _, err := conn.ExecContext(ctx, "UPDATE numbers SET seen = 0 WHERE n = 1"); err != nil {
+		t.Fatalf("update again: %v", err)
+	}
+	if queries := cachedQueries(t, conn); queries[0] != "UPDATE numbers SET seen = 0 WHERE n = 1" {
+		t.Errorf("most recent statement is %q", queries[0])
+	}
+}
+
+func TestStmtCacheConcurrentQueries(t *testing.T) {
+	ctx := context.Background()
+	db := openCacheTestDB(t)
+	db.SetMaxOpenConns(4)
+
+	const query = "SELECT n FROM numbers WHERE n <= ? ORDER BY n"
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				limit := (g+i)%10 + 1
+				rows, err := db.QueryContext(ctx, query, limit)
+				if err != nil {
+					t.Errorf("query: %v", err)
+					return
+				}
+				if sum := sumNumbers(t, rows); sum != limit*(limit+1)/2 {
+					t.Errorf("sum of 1 to %d is %d", limit, sum)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// Two rows of the same statement open at once on one connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get a connection: %v", err)
+	}
+	defer conn.Close()
+	first, err := conn.QueryContext(ctx, query, 10)
+	if err != nil {
+		t.Fatalf("first query: %v", err)
+	}
+	defer first.Close()
+	if !first.Next() {
+		t.Fatalf("first query has no rows: %v", first.Err())
+	}
+	second, err := conn.QueryContext(ctx, query, 3)
+	if err != nil {
+		t.Fatalf("second query: %v", err)
+	}
+	if sum := sumNumbers(t, second); sum != 6 {
+		t.Errorf("second query: sum %d, want 6", sum)
+	}
+	var n int
+	if err := first.Scan(&n); err != nil || n != 1 {
+		t.Fatalf("first query: %d, %v", n, err)
+	}
+	if sum := n + sumNumbers(t, first); sum != 55 {
+		t.Errorf("first query: sum %d, want 55", sum)
+	}
+}
+
+func TestStmtCacheExecWithOpenRows(t *testing.T) {
+	ctx := context.Background()
+	conn, err := openCacheTestDB(t).Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get a connection: %v", err)
+	}
+	defer conn.Close()
+
+	const (
+		query  = "SELECT n FROM numbers ORDER BY n"
+		update = "UPDATE numbers SET seen = seen + 1 WHERE n = ?"
+	)
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	sum := 0
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		sum += n
+		if _, err := conn.ExecContext(ctx, update, n); err != nil {
+			t.Fatalf("update %d: %v", n, err)
+		}
+		// The busy statement itself runs past the cache
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			t.Fatalf("exec of the open query: %v", err)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if sum != 55 {
+		t.Errorf("sum %d, want 55", sum)
+	}
+	var seen int
+	if err := conn.QueryRowContext(ctx, "SELECT SUM(seen) FROM numbers").Scan(&seen); err != nil || seen != 10 {
+		t.Errorf("%d rows updated, want 10 (%v)", seen, err)
+	}
+
+	// A statement evicted while its rows are open is finalized once they are closed
+	rows, err = conn.QueryContext(ctx, query)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	for i := 0; i < maxCachedStatements; i++ {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("UPDATE numbers SET seen = %d WHERE n = 1", i)); err != nil {
+			t.Fatalf("update %d: %v", i, err)
+		}
+	}
+	if slices.Contains(cachedQueries(t, conn), query) {
+		t.Fatalf("the open statement wasn't evicted")
+	}
+	if sum := sumNumbers(t, rows); sum != 55 {
+		t.Errorf("rows of the evicted statement: sum %d, want 55", sum)
+	}
+	rows, err = conn.QueryContext(ctx, query)
+	if err != nil {
+		t.Fatalf("query after eviction: %v", err)
+	}
+	if sum := sumNumbers(t, rows); sum != 55 {
+		t.Errorf("query after eviction: sum %d, want 55", sum)
+	}
+}
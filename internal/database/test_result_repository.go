@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/engbot/pkg/models"
+)
+
+// TestResultRepository handles database operations for test results
+type TestResultRepository struct{}
+
+// NewTestResultRepository creates a new repository instance
+func NewTestResultRepository() *TestResultRepository {
+	return &TestResultRepository{}
+}
+
+// SaveTestResult saves the result of a finished test and sets its ID
+func (r *TestResultRepository) SaveTestResult(ctx context.Context, result *models.TestResult) error {
+	topics := make([]string, len(result.Topics))
+	for i, id := range result.Topics {
+		topics[i] = strconv.FormatInt(id, 10)
+	}
+
+	id, err := insertID(ctx, DB, `
+		INSERT INTO test_results (user_id, test_type, total_words, correct_words, topics, test_date, duration, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, result.UserID, result.TestType, result.TotalWords, result.CorrectWords,
+		strings.Join(topics, ","), result.TestDate, result.Duration)
+	if err != nil {
+		return fmt.Errorf("failed to save test result: %w", err)
+	}
+	result.ID = int(id)
+	return nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/example/engbot/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// TopicAttachmentRepository handles the study material attached to topics
+type TopicAttachmentRepository struct{}
+
+// NewTopicAttachmentRepository creates a new repository instance
+func NewTopicAttachmentRepository() *TopicAttachmentRepository {
+	return &TopicAttachmentRepository{}
+}
+
+// Create adds an attachment to a topic and sets its ID
+func (r *TopicAttachmentRepository) Create(ctx context.Context, attachment *models.TopicAttachment) error {
+	return createAttachment(ctx, DB, attachment)
+}
+
+// createAttachment adds an attachment to a topic and sets its ID
+func createAttachment(ctx context.Context, db sqlx.ExtContext, attachment *models.TopicAttachment) error {
+	id, err := insertID(ctx, db, `
+		INSERT INTO topic_attachments (user_id, topic_id, kind, content, caption, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, attachment.UserID, attachment.TopicID, attachment.Kind, attachment.Content, attachment.Caption)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	attachment.ID = id
+	return nil
+}
+
+// GetByTopic returns the attachments of the user's topic in the order they were added
+func (r *TopicAttachmentRepository) GetByTopic(ctx context.Context, userID, topicID int64) ([]models.TopicAttachment, error) {
+	var attachments []models.TopicAttachment
+	err := readDB.SelectContext(ctx, &attachments, `
+		SELECT id, user_id, topic_id, kind, content, caption, created_at
+		FROM topic_attachments
+		WHERE user_id = ? AND topic_id = ?
+		ORDER BY id
+	`, userID, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// DeleteByTopic removes all attachments of the user's topic and returns how many there were
+func (r *TopicAttachmentRepository) DeleteByTopic(ctx context.Context, userID, topicID int64) (int, error) {
+	result, err := DB.ExecContext(ctx, "DELETE FROM topic_attachments WHERE user_id = ? AND topic_id = ?", userID, topicID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete attachments: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rows), nil
+}
@@ -3,129 +3,223 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/internal/textutil"
 	"github.com/example/engbot/pkg/models"
+	"github.com/jmoiron/sqlx"
 )
 
 // TopicRepository handles database operations for topics
-type TopicRepository struct{}
+type TopicRepository struct {
+	clock clock.Clock
+}
 
 // NewTopicRepository creates a new repository instance
 func NewTopicRepository() *TopicRepository {
-	return &TopicRepository{}
+	return NewTopicRepositoryWithClock(clock.System{})
+}
+
+// NewTopicRepositoryWithClock creates a repository that reads the current time from c
+func NewTopicRepositoryWithClock(c clock.Clock) *TopicRepository {
+	return &TopicRepository{clock: c}
 }
 
-// GetAllTopics retrieves all topics from the database
-func GetAllTopics() ([]*models.Topic, error) {
-	topics := []*models.Topic{}
-	
-	// Используем совместимый с SQLite и PostgreSQL запрос
-	query := "SELECT id, name FROM topics ORDER BY name"
-	
-	err := DB.Select(&topics, query)
+// GetAllByUserID returns all topics for a given user, every topic followed by its subtopics
+func (r *TopicRepository) GetAllByUserID(ctx context.Context, userID int64) ([]models.Topic, error) {
+	if topics, ok := cachedTopics(ctx, userID); ok {
+		return topics, nil
+	}
+
+	var topics []models.Topic
+
+	query := `
+		SELECT id, user_id, parent_id, name, COALESCE(description, '') AS description, difficulty, archived, muted, stalled, maintenance, category, custom_intervals, review_direction,
+			easiness_factor, review_interval, review_count, leitner_box, created_at, updated_at
+		FROM topics
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+	`
+
+	err := readDB.SelectContext(ctx, &topics, query, userID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get topics: %w", err)
 	}
-	
+
+	topics = topicTree(topics)
+	topicCache.Set(ctx, topicsKey(userID), slices.Clone(topics))
 	return topics, nil
 }
 
-// GetTopicByID retrieves a topic by its ID
-func GetTopicByID(topicID int64) (*models.Topic, error) {
-	topic := &models.Topic{}
-	
-	// Используем совместимый с SQLite и PostgreSQL запрос
-	query := "SELECT id, name FROM topics WHERE id = ?"
-	
-	// Replace ? with $ for PostgreSQL if needed
-	if DB.DriverName() == "postgres" {
-		query = strings.Replace(query, "?", "$1", -1)
+// topicTree orders the topics depth-first: each topic is followed by its subtopics, siblings
+// keep their order. It sets the depth of every topic; a topic whose parent is gone is top-level.
+func topicTree(topics []models.Topic) []models.Topic {
+	known := make(map[int64]bool, len(topics))
+	for _, t := range topics {
+		known[t.ID] = true
 	}
-	
-	err := DB.Get(topic, query, topicID)
-	if err != nil {
-		return nil, err
+	children := make(map[int64][]models.Topic)
+	for _, t := range topics {
+		parent := t.ParentID
+		if !known[parent] || parent == t.ID {
+			parent = 0
+		}
+		children[parent] = append(children[parent], t)
+	}
+
+	ordered := make([]models.Topic, 0, len(topics))
+	visited := make(map[int64]bool, len(topics))
+	var walk func(parent int64, depth int)
+	walk = func(parent int64, depth int) {
+		for _, t := range children[parent] {
+			if visited[t.ID] {
+				continue
+			}
+			visited[t.ID] = true
+			t.Depth = depth
+			ordered = append(ordered, t)
+			walk(t.ID, depth+1)
+		}
 	}
-	
-	return topic, nil
+	walk(0, 0)
+
+	// Topics stuck in a cycle, which SetParent never creates, are listed at the top level
+	for _, t := range topics {
+		if !visited[t.ID] {
+			visited[t.ID] = true
+			t.Depth = 0
+			ordered = append(ordered, t)
+		}
+	}
+	return ordered
 }
 
-// GetTopicByName retrieves a topic by its name
-func GetTopicByName(name string) (*models.Topic, error) {
-	topic := &models.Topic{}
-	
-	// Используем совместимый с SQLite и PostgreSQL запрос
-	query := "SELECT id, name FROM topics WHERE name = ?"
-	
-	// Replace ? with $ for PostgreSQL if needed
-	if DB.DriverName() == "postgres" {
-		query = strings.Replace(query, "?", "$1", -1)
+// withSubtopics returns the given topics of the user together with all their subtopics
+func withSubtopics(ctx context.Context, q sqlx.QueryerContext, userID int64, topicIDs []int64) ([]int64, error) {
+	var rows []struct {
+		ID       int64 `db:"id"`
+		ParentID int64 `db:"parent_id"`
 	}
-	
-	err := DB.Get(topic, query, name)
-	if err != nil {
-		return nil, err
+	if err := sqlx.SelectContext(ctx, q, &rows, "SELECT id, parent_id FROM topics WHERE user_id = ?", userID); err != nil {
+		return nil, fmt.Errorf("failed to get subtopics: %w", err)
+	}
+	children := make(map[int64][]int64)
+	for _, row := range rows {
+		children[row.ParentID] = append(children[row.ParentID], row.ID)
 	}
-	
-	return topic, nil
+
+	seen := make(map[int64]bool)
+	var ids []int64
+	queue := append([]int64(nil), topicIDs...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+		queue = append(queue, children[id]...)
+	}
+	return ids, nil
 }
 
-// GetAll returns all topics
-func (r *TopicRepository) GetAll() ([]models.Topic, error) {
-	var topics []struct {
-		ID        int64     `db:"id"`
-		Name      string    `db:"name"`
-		CreatedAt time.Time `db:"created_at"`
-		UpdatedAt time.Time `db:"updated_at"`
+// SetParent makes the topic a subtopic of parentID, 0 makes it top-level. The caller
+// makes sure the parent is not the topic itself or one of its subtopics.
+func (r *TopicRepository) SetParent(ctx context.Context, userID, topicID, parentID int64) error {
+	defer invalidateTopics(ctx, userID)
+
+	result, err := DB.ExecContext(ctx,
+		"UPDATE topics SET parent_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ?",
+		parentID, topicID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set topic parent: %w", err)
 	}
-	
-	err := DB.Select(&topics, "SELECT id, name, created_at, updated_at FROM topics ORDER BY name")
+	rows, err := result.RowsAffected()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get topics: %v", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("topic %w or user not authorized", ErrNotFound)
 	}
-	
-	result := make([]models.Topic, len(topics))
-	for i, t := range topics {
-		result[i].ID = t.ID
-		result[i].Name = t.Name
-		result[i].CreatedAt = t.CreatedAt
-		result[i].UpdatedAt = t.UpdatedAt
+	return nil
+}
+
+// CountByUserID returns the number of topics a user has
+func (r *TopicRepository) CountByUserID(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := readDB.GetContext(ctx, &count, "SELECT COUNT(*) FROM topics WHERE user_id = ?", userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count topics: %w", err)
 	}
-	
-	return result, nil
+	return count, nil
 }
 
-// GetAllByUserID returns all topics for a given user
-func (r *TopicRepository) GetAllByUserID(ctx context.Context, userID int64) ([]models.Topic, error) {
-	var topics []models.Topic
+// FindSimilar returns the user's topic whose name is closest to name within maxDistance edits
+// (case and extra spaces are ignored), or nil if there is none
+func (r *TopicRepository) FindSimilar(ctx context.Context, userID int64, name string, maxDistance int) (*models.Topic, error) {
+	topics, err := r.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	query := `
-		SELECT id, user_id, name, created_at, updated_at
-		FROM topics
-		WHERE user_id = ?
-		ORDER BY created_at DESC
-	`
+	var closest *models.Topic
+	closestDistance := maxDistance + 1
+	normalized := textutil.Normalize(name)
+	for i := range topics {
+		if !textutil.IsSimilar(topics[i].Name, name, maxDistance) {
+			continue
+		}
+		distance := textutil.Levenshtein(textutil.Normalize(topics[i].Name), normalized)
+		if distance < closestDistance {
+			closest = &topics[i]
+			closestDistance = distance
+		}
+	}
+	return closest, nil
+}
 
-	err := DB.SelectContext(ctx, &topics, query, userID)
+// NameTaken reports whether the user already has another topic with this name,
+// ignoring case and extra spaces. exceptID excludes the topic being renamed.
+func (r *TopicRepository) NameTaken(ctx context.Context, userID int64, name string, exceptID int64) (bool, error) {
+	topics, err := r.GetAllByUserID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get topics: %w", err)
+		return false, err
 	}
 
-	return topics, nil
+	normalized := textutil.Normalize(name)
+	for _, topic := range topics {
+		if topic.ID != exceptID && textutil.Normalize(topic.Name) == normalized {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // GetByID returns a topic by ID
 func (r *TopicRepository) GetByID(ctx context.Context, userID, topicID int64) (*models.Topic, error) {
+	if topics, ok := cachedTopics(ctx, userID); ok {
+		for _, topic := range topics {
+			if topic.ID == topicID {
+				return &topic, nil
+			}
+		}
+		return nil, nil
+	}
+
 	var topic models.Topic
 	query := `
-		SELECT id, user_id, name, created_at, updated_at
+		SELECT id, user_id, parent_id, name, COALESCE(description, '') AS description, difficulty, archived, muted, stalled, maintenance, category, custom_intervals, review_direction,
+			easiness_factor, review_interval, review_count, leitner_box, created_at, updated_at
 		FROM topics
 		WHERE id = ? AND user_id = ?
 	`
-	err := DB.GetContext(ctx, &topic, query, topicID, userID)
+	err := readDB.GetContext(ctx, &topic, query, topicID, userID)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -135,44 +229,171 @@ func (r *TopicRepository) GetByID(ctx context.Context, userID, topicID int64) (*
 	return &topic, nil
 }
 
-// Create creates a new topic
+// Create creates a new topic. Returns ErrTopicExists when the user already has a topic with
+// this name, ignoring case and extra spaces.
 func (r *TopicRepository) Create(ctx context.Context, topic *models.Topic) error {
-	query := `
-		INSERT INTO topics (user_id, name, created_at, updated_at)
-		VALUES (?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-	`
+	defer invalidateTopics(ctx, topic.UserID)
+	return createTopic(ctx, DB, topic)
+}
 
-	result, err := DB.ExecContext(ctx, query,
-		topic.UserID,
-		topic.Name,
-	)
+// CreateMany creates a topic with its statistics and first repetition, due at firstReview, for
+// every name in one transaction. Names the user already has, ignoring case and extra spaces, and
+// names repeated in the list are skipped and returned.
+func (r *TopicRepository) CreateMany(ctx context.Context, userID int64, names []string, firstReview time.Time) ([]models.Topic, []string, error) {
+	defer invalidateTopics(ctx, userID)
+
+	tx, err := DB.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create topic: %w", err)
+		return nil, nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	id, err := result.LastInsertId()
+	var existing []string
+	if err := tx.SelectContext(ctx, &existing, "SELECT name FROM topics WHERE user_id = ?", userID); err != nil {
+		return nil, nil, fmt.Errorf("failed to get topic names: %w", err)
+	}
+	taken := make(map[string]bool, len(existing)+len(names))
+	for _, name := range existing {
+		taken[textutil.Normalize(name)] = true
+	}
+
+	var created []models.Topic
+	var skipped []string
+	for _, name := range names {
+		normalized := textutil.Normalize(name)
+		if taken[normalized] {
+			skipped = append(skipped, name)
+			continue
+		}
+		taken[normalized] = true
+
+		topic := models.Topic{UserID: userID, Name: name, Difficulty: models.DefaultTopicDifficulty}
+		topic.ID, err = insertID(ctx, tx, `
+			INSERT INTO topics (user_id, name, difficulty, created_at, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		`, userID, name, topic.Difficulty)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create topic: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO statistics (user_id, topic_id, total_repetitions, completed_repetitions) VALUES (?, ?, 0, 0)
+		`, userID, topic.ID); err != nil {
+			return nil, nil, fmt.Errorf("failed to create statistics: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO repetitions (user_id, topic_id, repetition_number, next_review_date, completed) VALUES (?, ?, 1, ?, ?)
+		`, userID, topic.ID, firstReview, false); err != nil {
+			return nil, nil, fmt.Errorf("failed to create repetition: %w", err)
+		}
+		topic.CreatedAt = r.clock.Now()
+		topic.UpdatedAt = topic.CreatedAt
+		created = append(created, topic)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return created, skipped, nil
+}
+
+// Merge moves everything of the source topic into the target one and deletes the source:
+// words the target doesn't have yet, the review history, attachments and subtopics. The source
+// statistics are added to the target ones, and its upcoming repetitions are dropped when the
+// target has its own.
+func (r *TopicRepository) Merge(ctx context.Context, userID, sourceID, targetID int64) error {
+	defer invalidateTopics(ctx, userID)
+
+	if sourceID == targetID {
+		return fmt.Errorf("cannot merge topic %d into itself", sourceID)
+	}
+
+	tx, err := DB.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get last insert ID: %w", err)
+		return fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	topic.ID = id
-	topic.CreatedAt = time.Now()
-	topic.UpdatedAt = time.Now()
+	var owned int
+	if err := tx.GetContext(ctx, &owned, "SELECT COUNT(*) FROM topics WHERE user_id = ? AND id IN (?, ?)", userID, sourceID, targetID); err != nil {
+		return fmt.Errorf("failed to check topics: %w", err)
+	}
+	if owned != 2 {
+		return fmt.Errorf("topic %w or user doesn't have permission", ErrNotFound)
+	}
 
+	// Words are unique per topic, so the ones the target already has are dropped
+	duplicateWords := `SELECT s.id FROM words s JOIN words t ON t.topic_id = ? AND t.word = s.word WHERE s.topic_id = ?`
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_progress WHERE word_id IN ("+duplicateWords+")", targetID, sourceID); err != nil {
+		return fmt.Errorf("failed to delete duplicate word progress: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM words WHERE id IN ("+duplicateWords+")", targetID, sourceID); err != nil {
+		return fmt.Errorf("failed to delete duplicate words: %w", err)
+	}
+
+	var targetPending int
+	if err := tx.GetContext(ctx, &targetPending, "SELECT COUNT(*) FROM repetitions WHERE user_id = ? AND topic_id = ? AND completed = ?", userID, targetID, false); err != nil {
+		return fmt.Errorf("failed to count repetitions: %w", err)
+	}
+	if targetPending > 0 {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM repetitions WHERE user_id = ? AND topic_id = ? AND completed = ?", userID, sourceID, false); err != nil {
+			return fmt.Errorf("failed to delete repetitions: %w", err)
+		}
+	}
+
+	var source models.Statistics
+	err = tx.GetContext(ctx, &source, "SELECT total_repetitions, completed_repetitions FROM statistics WHERE user_id = ? AND topic_id = ?", userID, sourceID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get statistics: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE statistics
+		SET total_repetitions = total_repetitions + ?, completed_repetitions = completed_repetitions + ?
+		WHERE user_id = ? AND topic_id = ?
+	`, source.TotalRepetitions, source.CompletedRepetitions, userID, targetID); err != nil {
+		return fmt.Errorf("failed to update statistics: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM statistics WHERE user_id = ? AND topic_id = ?", userID, sourceID); err != nil {
+		return fmt.Errorf("failed to delete statistics: %w", err)
+	}
+
+	for _, stmt := range []struct{ query, what string }{
+		{"UPDATE words SET topic_id = ? WHERE topic_id = ? AND user_id = ?", "words"},
+		{"UPDATE repetitions SET topic_id = ? WHERE topic_id = ? AND user_id = ?", "repetitions"},
+		{"UPDATE topic_attachments SET topic_id = ? WHERE topic_id = ? AND user_id = ?", "attachments"},
+		{"UPDATE topics SET parent_id = ? WHERE parent_id = ? AND user_id = ?", "subtopics"},
+	} {
+		if _, err := tx.ExecContext(ctx, stmt.query, targetID, sourceID, userID); err != nil {
+			return fmt.Errorf("failed to move %s: %w", stmt.what, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM topics WHERE id = ? AND user_id = ?", sourceID, userID); err != nil {
+		return fmt.Errorf("failed to delete topic: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
 // Update updates an existing topic
 func (r *TopicRepository) Update(ctx context.Context, topic *models.Topic) error {
+	defer invalidateTopics(ctx, topic.UserID)
+
 	query := `
 		UPDATE topics
 		SET name = ?,
+			description = ?,
+			difficulty = ?,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = ? AND user_id = ?
 	`
 
 	result, err := DB.ExecContext(ctx, query,
 		topic.Name,
+		topic.Description,
+		topic.Difficulty,
 		topic.ID,
 		topic.UserID,
 	)
@@ -187,63 +408,388 @@ func (r *TopicRepository) Update(ctx context.Context, topic *models.Topic) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("topic not found or user not authorized")
+		return fmt.Errorf("topic %w or user not authorized", ErrNotFound)
+	}
+
+	return nil
+}
+
+// UpdateSchedule stores the SM-2 state of a topic after a graded review
+func (r *TopicRepository) UpdateSchedule(ctx context.Context, topic *models.Topic) error {
+	defer invalidateTopics(ctx, topic.UserID)
+
+	query := `
+		UPDATE topics
+		SET easiness_factor = ?,
+			review_interval = ?,
+			review_count = ?,
+			leitner_box = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ?
+	`
+
+	result, err := DB.ExecContext(ctx, query,
+		topic.EasinessFactor,
+		topic.ReviewInterval,
+		topic.ReviewCount,
+		topic.LeitnerBox,
+		topic.ID,
+		topic.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update topic schedule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("topic %w or user not authorized", ErrNotFound)
 	}
 
 	return nil
 }
 
-// Delete removes a topic
-func (r *TopicRepository) Delete(ctx context.Context, userID, topicID int64) error {
+// Delete removes a topic together with its subtopics. The removed rows go to the trash first;
+// returns the ID of the trash entry that restores them.
+func (r *TopicRepository) Delete(ctx context.Context, userID, topicID int64) (int64, error) {
+	defer invalidateTopics(ctx, userID)
+
 	tx, err := DB.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	topicIDs, err := withSubtopics(ctx, tx, userID, []int64{topicID})
+	if err != nil {
+		return 0, err
+	}
+	trashID, err := moveToTrash(ctx, tx, userID, topicIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	// The topic itself goes last, after its subtopics
+	for i := len(topicIDs) - 1; i >= 0; i-- {
+		rows, err := deleteTopicTx(ctx, tx, userID, topicIDs[i])
+		if err != nil {
+			return 0, err
+		}
+		if topicIDs[i] == topicID && rows == 0 {
+			return 0, fmt.Errorf("topic %w or user doesn't have permission", ErrNotFound)
+		}
 	}
 
-	// Delete related repetitions
-	_, err = tx.ExecContext(ctx, "DELETE FROM repetitions WHERE user_id = ? AND topic_id = ?", userID, topicID)
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return trashID, nil
+}
+
+// BulkDelete removes the given topics and their subtopics with their repetitions and statistics
+// in one transaction. Topics that don't belong to the user are skipped. Returns the number of
+// deleted topics and the ID of the trash entry that restores them.
+func (r *TopicRepository) BulkDelete(ctx context.Context, userID int64, topicIDs []int64) (int, int64, error) {
+	defer invalidateTopics(ctx, userID)
+
+	tx, err := DB.BeginTxx(ctx, nil)
 	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to delete repetitions: %w", err)
+		return 0, 0, fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Delete related statistics
+	topicIDs, err = withSubtopics(ctx, tx, userID, topicIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+	trashID, err := moveToTrash(ctx, tx, userID, topicIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	deleted := 0
+	for _, topicID := range topicIDs {
+		rows, err := deleteTopicTx(ctx, tx, userID, topicID)
+		if err != nil {
+			return 0, 0, err
+		}
+		deleted += int(rows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deleted, trashID, nil
+}
+
+// deleteTopicTx removes a topic of the user with its repetitions, statistics and attachments
+// and returns the number of deleted topics, 0 if it isn't the user's
+func deleteTopicTx(ctx context.Context, tx *sqlx.Tx, userID, topicID int64) (int64, error) {
+	_, err := tx.ExecContext(ctx, "DELETE FROM repetitions WHERE user_id = ? AND topic_id = ?", userID, topicID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete repetitions of topic %d: %w", topicID, err)
+	}
 	_, err = tx.ExecContext(ctx, "DELETE FROM statistics WHERE user_id = ? AND topic_id = ?", userID, topicID)
 	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to delete statistics: %w", err)
+		return 0, fmt.Errorf("failed to delete statistics of topic %d: %w", topicID, err)
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM topic_attachments WHERE user_id = ? AND topic_id = ?", userID, topicID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete attachments of topic %d: %w", topicID, err)
 	}
 
-	// Delete the topic
 	result, err := tx.ExecContext(ctx, "DELETE FROM topics WHERE id = ? AND user_id = ?", topicID, userID)
 	if err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to delete topic: %w", err)
+		return 0, fmt.Errorf("failed to delete topic %d: %w", topicID, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
 	}
+	return rows, nil
+}
+
+// SetMaintenance turns maintenance reviews of the topic on or off. Turning them on for a topic
+// that has finished its cycle schedules a maintenance review at next, turning them off drops the
+// pending maintenance review.
+func (r *TopicRepository) SetMaintenance(ctx context.Context, userID, topicID int64, enabled bool, next time.Time) error {
+	defer invalidateTopics(ctx, userID)
 
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE topics SET maintenance = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ?
+	`, enabled, topicID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update topic maintenance: %w", err)
+	}
 	rows, err := result.RowsAffected()
 	if err != nil {
-		tx.Rollback()
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 	if rows == 0 {
-		tx.Rollback()
-		return fmt.Errorf("topic not found or user doesn't have permission")
+		return fmt.Errorf("topic %w or user not authorized", ErrNotFound)
+	}
+
+	if enabled {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO repetitions (user_id, topic_id, repetition_number, next_review_date, completed, maintenance)
+			SELECT ?, ?, MAX(repetition_number) + 1, ?, false, true
+			FROM repetitions
+			WHERE user_id = ? AND topic_id = ?
+			HAVING COUNT(*) > 0 AND SUM(CASE WHEN completed = false THEN 1 ELSE 0 END) = 0
+		`, userID, topicID, next, userID, topicID)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM repetitions WHERE user_id = ? AND topic_id = ? AND completed = false AND maintenance = true
+		`, userID, topicID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to schedule maintenance review: %w", err)
 	}
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	return nil
+}
+
+// SetIntervals stores the ladder that replaces the user's intervals for the topic, nil goes back
+// to the user's intervals
+func (r *TopicRepository) SetIntervals(ctx context.Context, userID, topicID int64, intervals []int) error {
+	defer invalidateTopics(ctx, userID)
+
+	result, err := DB.ExecContext(ctx, `
+		UPDATE topics SET custom_intervals = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ?
+	`, EncodeIntervals(intervals), topicID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update topic intervals: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("topic %w or user not authorized", ErrNotFound)
+	}
+	return nil
+}
+
+// SetReviewDirection stores the direction the topic's flashcards are reviewed in, one of the
+// models.Direction* values, "" goes back to the user's direction
+func (r *TopicRepository) SetReviewDirection(ctx context.Context, userID, topicID int64, direction string) error {
+	defer invalidateTopics(ctx, userID)
 
+	result, err := DB.ExecContext(ctx, `
+		UPDATE topics SET review_direction = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ?
+	`, direction, topicID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update topic review direction: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("topic %w or user not authorized", ErrNotFound)
+	}
 	return nil
 }
 
-// GetGeneralTopic returns the general topic
-func (r *TopicRepository) GetGeneralTopic() (*models.Topic, error) {
-	topic := &models.Topic{
-		Name:      "General",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+// EncodeIntervals turns a ladder into the form Topic.CustomIntervals keeps it in, "" for none
+func EncodeIntervals(intervals []int) string {
+	if len(intervals) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(intervals) // a slice of ints always encodes
+	return string(data)
+}
+
+// TopicIntervals returns the ladder the topic is reviewed on: its own one when set, otherwise
+// the user's intervals scaled by the topic's difficulty, see IntervalsForDifficulty
+func TopicIntervals(topic *models.Topic, userIntervals []int) []int {
+	if topic == nil {
+		return userIntervals
+	}
+	var intervals []int
+	if topic.CustomIntervals == "" || json.Unmarshal([]byte(topic.CustomIntervals), &intervals) != nil || len(intervals) == 0 {
+		return IntervalsForDifficulty(userIntervals, topic.Difficulty)
 	}
-	return topic, nil
-} 
\ No newline at end of file
+	return intervals
+}
+
+// BulkSetArchived archives or restores the given topics with their subtopics
+func (r *TopicRepository) BulkSetArchived(ctx context.Context, userID int64, topicIDs []int64, archived bool) (int, error) {
+	topicIDs, err := withSubtopics(ctx, DB, userID, topicIDs)
+	if err != nil {
+		return 0, err
+	}
+	return r.bulkSet(ctx, userID, topicIDs, "archived", archived)
+}
+
+// BulkSetMuted turns reminders off or on for the given topics with their subtopics
+func (r *TopicRepository) BulkSetMuted(ctx context.Context, userID int64, topicIDs []int64, muted bool) (int, error) {
+	topicIDs, err := withSubtopics(ctx, DB, userID, topicIDs)
+	if err != nil {
+		return 0, err
+	}
+	return r.bulkSet(ctx, userID, topicIDs, "muted", muted)
+}
+
+// BulkSetStalled marks the given topics as stalled or takes the mark off. Stalled topics get
+// no reminders, only the weekly prompt to revive or archive them.
+func (r *TopicRepository) BulkSetStalled(ctx context.Context, userID int64, topicIDs []int64, stalled bool) (int, error) {
+	return r.bulkSet(ctx, userID, topicIDs, "stalled", stalled)
+}
+
+// BulkSetCategory moves the given topics to category, an empty category removes it
+func (r *TopicRepository) BulkSetCategory(ctx context.Context, userID int64, topicIDs []int64, category string) (int, error) {
+	return r.bulkSet(ctx, userID, topicIDs, "category", strings.TrimSpace(category))
+}
+
+// bulkSet updates one column of the user's topics in a single transaction and returns the number
+// of updated topics. column is never user input.
+func (r *TopicRepository) bulkSet(ctx context.Context, userID int64, topicIDs []int64, column string, value interface{}) (int, error) {
+	defer invalidateTopics(ctx, userID)
+
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("UPDATE topics SET %s = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ?", column)
+	updated := 0
+	for _, topicID := range topicIDs {
+		result, err := tx.ExecContext(ctx, query, value, topicID, userID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update %s of topic %d: %w", column, topicID, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		updated += int(rows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DefaultGeneralTopicName is the name of the topic that collects items without a topic
+const DefaultGeneralTopicName = "Без темы"
+
+// GetGeneralTopic returns the user's topic for items without a topic, creating it if needed.
+// An empty name falls back to DefaultGeneralTopicName. Like NameTaken, the lookup ignores case
+// and extra spaces. Lookup and creation run in one transaction, so repeated calls always return
+// the same persisted topic. A new topic isn't created when the user already has maxTopics
+// topics, ErrTopicLimit is returned instead; 0 means no limit.
+func (r *TopicRepository) GetGeneralTopic(ctx context.Context, userID int64, name string, maxTopics int) (*models.Topic, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = DefaultGeneralTopicName
+	}
+
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var topics []models.Topic
+	query := `
+		SELECT id, user_id, parent_id, name, COALESCE(description, '') AS description, difficulty, archived, muted, stalled, maintenance, category, custom_intervals, review_direction,
+			easiness_factor, review_interval, review_count, leitner_box, created_at, updated_at
+		FROM topics
+		WHERE user_id = ?
+		ORDER BY id
+	`
+	if err := tx.SelectContext(ctx, &topics, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get general topic: %w", err)
+	}
+	normalized := textutil.Normalize(name)
+	for _, topic := range topics {
+		if textutil.Normalize(topic.Name) == normalized {
+			return &topic, nil
+		}
+	}
+	if maxTopics > 0 && len(topics) >= maxTopics {
+		return nil, ErrTopicLimit
+	}
+
+	now := r.clock.Now().UTC()
+	id, err := insertID(ctx, tx, `
+		INSERT INTO topics (user_id, name, difficulty, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, userID, name, models.DefaultTopicDifficulty, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create general topic: %w", err)
+	}
+	if id == 0 {
+		return nil, fmt.Errorf("general topic was created without an ID")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	invalidateTopics(ctx, userID)
+
+	return &models.Topic{
+		ID:         id,
+		UserID:     userID,
+		Name:       name,
+		Difficulty: models.DefaultTopicDifficulty,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
@@ -0,0 +1,140 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/database/dbtest"
+	"github.com/example/engbot/pkg/models"
+)
+
+func TestBulkSetArchived(t *testing.T) {
+	dbtest.Open(t)
+	ctx := context.Background()
+	repo := database.NewTopicRepository()
+	now := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	user := dbtest.User(t, 1)
+	other := dbtest.User(t, 2)
+	grammar := dbtest.Topic(t, user.ID, "Grammar", 1, now)
+	tenses := dbtest.Topic(t, user.ID, "Tenses", 1, now)
+	perfect := dbtest.Topic(t, user.ID, "Perfect", 1, now)
+	words := dbtest.Topic(t, user.ID, "Words", 1, now)
+	foreign := dbtest.Topic(t, other.ID, "Not mine", 1, now)
+	// Grammar > Tenses > Perfect
+	if err := repo.SetParent(ctx, user.ID, tenses.ID, grammar.ID); err != nil {
+		t.Fatalf("failed to set parent: %v", err)
+	}
+	if err := repo.SetParent(ctx, user.ID, perfect.ID, tenses.ID); err != nil {
+		t.Fatalf("failed to set parent: %v", err)
+	}
+
+	archived := func() map[int64]bool {
+		t.Helper()
+		state := make(map[int64]bool)
+		for _, owner := range []int64{user.ID, other.ID} {
+			topics, err := repo.GetAllByUserID(ctx, owner)
+			if err != nil {
+				t.Fatalf("failed to get topics: %v", err)
+			}
+			for _, topic := range topics {
+				state[topic.ID] = topic.Archived
+			}
+		}
+		return state
+	}
+
+	// The subtopics go along, someone else's topic is left alone
+	count, err := repo.BulkSetArchived(ctx, user.ID, []int64{grammar.ID, foreign.ID}, true)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("archived %d topics, want Grammar with its 2 subtopics", count)
+	}
+	got := archived()
+	for id, want := range map[int64]bool{grammar.ID: true, tenses.ID: true, perfect.ID: true, words.ID: false, foreign.ID: false} {
+		if got[id] != want {
+			t.Errorf("topic %d archived %v, want %v", id, got[id], want)
+		}
+	}
+
+	// Taking a subtopic out leaves its parent in the archive
+	count, err = repo.BulkSetArchived(ctx, user.ID, []int64{tenses.ID}, false)
+	if err != nil {
+		t.Fatalf("unarchive: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("took %d topics out of the archive, want Tenses and Perfect", count)
+	}
+	got = archived()
+	for id, want := range map[int64]bool{grammar.ID: true, tenses.ID: false, perfect.ID: false} {
+		if got[id] != want {
+			t.Errorf("topic %d archived %v, want %v", id, got[id], want)
+		}
+	}
+
+	if count, err := repo.BulkSetArchived(ctx, user.ID, nil, true); err != nil || count != 0 {
+		t.Errorf("empty selection: %d topics, %v", count, err)
+	}
+}
+
+func TestGetGeneralTopic(t *testing.T) {
+	dbtest.Open(t)
+	ctx := context.Background()
+	now := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	repo := database.NewTopicRepositoryWithClock(clock.NewFake(now))
+	user := dbtest.User(t, 1)
+
+	first, err := repo.GetGeneralTopic(ctx, user.ID, "", 0)
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	second, err := repo.GetGeneralTopic(ctx, user.ID, "", 0)
+	if err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+	if first.ID == 0 || second.ID != first.ID {
+		t.Errorf("topics %d and %d, want the same one", first.ID, second.ID)
+	}
+	if first.Name != database.DefaultGeneralTopicName {
+		t.Errorf("name %q, want %q", first.Name, database.DefaultGeneralTopicName)
+	}
+	// Both the new topic and the stored one carry the repository's time
+	for _, topic := range []*models.Topic{first, second} {
+		if !topic.CreatedAt.Equal(now) {
+			t.Errorf("topic created at %v, want %v", topic.CreatedAt, now)
+		}
+	}
+	if count, err := repo.CountByUserID(ctx, user.ID); err != nil || count != 1 {
+		t.Errorf("%d topics, want 1 (%v)", count, err)
+	}
+
+	// A topic differing only in case and spaces is the same topic
+	grammar := dbtest.Topic(t, user.ID, "  grammar ", 1, now)
+	topic, err := repo.GetGeneralTopic(ctx, user.ID, "Grammar", 0)
+	if err != nil {
+		t.Fatalf("get Grammar: %v", err)
+	}
+	if topic.ID != grammar.ID {
+		t.Errorf("got topic %d %q, want the existing %d", topic.ID, topic.Name, grammar.ID)
+	}
+	if count, err := repo.CountByUserID(ctx, user.ID); err != nil || count != 2 {
+		t.Errorf("%d topics, want 2 (%v)", count, err)
+	}
+
+	// At the limit an existing topic is still found, a new one isn't created
+	if topic, err := repo.GetGeneralTopic(ctx, user.ID, "GRAMMAR", 2); err != nil || topic.ID != grammar.ID {
+		t.Errorf("existing topic at the limit: %v, %v", topic, err)
+	}
+	if _, err := repo.GetGeneralTopic(ctx, user.ID, "Verbs", 2); !errors.Is(err, database.ErrTopicLimit) {
+		t.Errorf("new topic at the limit: %v, want ErrTopicLimit", err)
+	}
+	if count, err := repo.CountByUserID(ctx, user.ID); err != nil || count != 2 {
+		t.Errorf("%d topics after hitting the limit, want 2 (%v)", count, err)
+	}
+}
@@ -0,0 +1,197 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/example/engbot/internal/textutil"
+	"github.com/example/engbot/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// trashedTopic is a deleted topic with the rows that belonged to it, as kept in topic_trash
+type trashedTopic struct {
+	Topic       models.Topic             `json:"topic"`
+	Repetitions []models.Repetition      `json:"repetitions"`
+	Statistics  []models.Statistics      `json:"statistics"`
+	Attachments []models.TopicAttachment `json:"attachments"`
+}
+
+// moveToTrash saves the topics of the user with their repetitions, statistics and attachments
+// as one trash entry before they are deleted, and returns the entry ID. Words stay in place,
+// their topic ID comes back with the restored topic.
+func moveToTrash(ctx context.Context, tx *sqlx.Tx, userID int64, topicIDs []int64) (int64, error) {
+	var trashed []trashedTopic
+	for _, topicID := range topicIDs {
+		var t trashedTopic
+		err := tx.GetContext(ctx, &t.Topic, `
+			SELECT id, user_id, parent_id, name, COALESCE(description, '') AS description, difficulty, archived, muted, stalled, maintenance, category, custom_intervals, review_direction,
+				easiness_factor, review_interval, review_count, leitner_box, created_at, updated_at
+			FROM topics
+			WHERE id = ? AND user_id = ?
+		`, topicID, userID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get topic %d: %w", topicID, err)
+		}
+		err = tx.SelectContext(ctx, &t.Repetitions, `
+			SELECT id, user_id, topic_id, repetition_number, next_review_date, last_review_date, completed, maintenance, notes,
+				created_at, updated_at
+			FROM repetitions
+			WHERE user_id = ? AND topic_id = ?
+		`, userID, topicID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get repetitions of topic %d: %w", topicID, err)
+		}
+		err = tx.SelectContext(ctx, &t.Statistics, `
+			SELECT id, user_id, topic_id, total_repetitions, completed_repetitions
+			FROM statistics
+			WHERE user_id = ? AND topic_id = ?
+		`, userID, topicID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get statistics of topic %d: %w", topicID, err)
+		}
+		err = tx.SelectContext(ctx, &t.Attachments, `
+			SELECT id, user_id, topic_id, kind, content, caption, created_at
+			FROM topic_attachments
+			WHERE user_id = ? AND topic_id = ?
+		`, userID, topicID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get attachments of topic %d: %w", topicID, err)
+		}
+		trashed = append(trashed, t)
+	}
+
+	data, err := json.Marshal(trashed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode deleted topics: %w", err)
+	}
+	id, err := insertID(ctx, tx, "INSERT INTO topic_trash (user_id, data, deleted_at) VALUES (?, ?, ?)",
+		userID, string(data), time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to move topics to trash: %w", err)
+	}
+	return id, nil
+}
+
+// Restore brings back the topics of a trash entry deleted after deletedAfter, with their
+// repetitions, statistics and attachments, and returns how many topics were restored.
+// Returns ErrNotFound when the entry is gone or older, and ErrTopicExists when the user
+// has created a topic with the same name since.
+func (r *TopicRepository) Restore(ctx context.Context, userID, trashID int64, deletedAfter time.Time) (int, error) {
+	defer invalidateTopics(ctx, userID)
+
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var entry struct {
+		Data      string    `db:"data"`
+		DeletedAt time.Time `db:"deleted_at"`
+	}
+	err = tx.GetContext(ctx, &entry, "SELECT data, deleted_at FROM topic_trash WHERE id = ? AND user_id = ?", trashID, userID)
+	if err == sql.ErrNoRows || (err == nil && entry.DeletedAt.Before(deletedAfter)) {
+		return 0, fmt.Errorf("trash entry %d %w", trashID, ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get trash entry: %w", err)
+	}
+	var trashed []trashedTopic
+	if err := json.Unmarshal([]byte(entry.Data), &trashed); err != nil {
+		return 0, fmt.Errorf("failed to decode trash entry %d: %w", trashID, err)
+	}
+
+	var rows []struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	if err := tx.SelectContext(ctx, &rows, "SELECT id, name FROM topics WHERE user_id = ?", userID); err != nil {
+		return 0, fmt.Errorf("failed to get topics: %w", err)
+	}
+	exists := make(map[int64]bool, len(rows)+len(trashed))
+	taken := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		exists[row.ID] = true
+		taken[textutil.Normalize(row.Name)] = true
+	}
+	for _, t := range trashed {
+		if taken[textutil.Normalize(t.Topic.Name)] {
+			return 0, fmt.Errorf("failed to restore topic %q: %w", t.Topic.Name, ErrTopicExists)
+		}
+		exists[t.Topic.ID] = true
+	}
+
+	for _, t := range trashed {
+		topic := t.Topic
+		// A parent deleted on its own meanwhile is gone, the topic comes back at the top level
+		if !exists[topic.ParentID] {
+			topic.ParentID = 0
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO topics (id, user_id, parent_id, name, description, difficulty, archived, muted, stalled, maintenance, category, custom_intervals, review_direction,
+				easiness_factor, review_interval, review_count, leitner_box, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, topic.ID, topic.UserID, topic.ParentID, topic.Name, topic.Description, topic.Difficulty, topic.Archived, topic.Muted,
+			topic.Stalled, topic.Maintenance, topic.Category, topic.CustomIntervals, topic.ReviewDirection, topic.EasinessFactor, topic.ReviewInterval,
+			topic.ReviewCount, max(topic.LeitnerBox, 1), topic.CreatedAt, topic.UpdatedAt)
+		if err != nil {
+			return 0, fmt.Errorf("failed to restore topic %d: %w", topic.ID, err)
+		}
+		for _, rep := range t.Repetitions {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO repetitions (id, user_id, topic_id, repetition_number, next_review_date, last_review_date,
+					completed, maintenance, notes, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, rep.ID, rep.UserID, rep.TopicID, rep.RepetitionNumber, rep.NextReviewDate, rep.LastReviewDate,
+				rep.Completed, rep.Maintenance, rep.Notes, rep.CreatedAt, rep.UpdatedAt)
+			if err != nil {
+				return 0, fmt.Errorf("failed to restore repetition %d: %w", rep.ID, err)
+			}
+		}
+		for _, s := range t.Statistics {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO statistics (id, user_id, topic_id, total_repetitions, completed_repetitions) VALUES (?, ?, ?, ?, ?)
+			`, s.ID, s.UserID, s.TopicID, s.TotalRepetitions, s.CompletedRepetitions)
+			if err != nil {
+				return 0, fmt.Errorf("failed to restore statistics %d: %w", s.ID, err)
+			}
+		}
+		for _, a := range t.Attachments {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO topic_attachments (id, user_id, topic_id, kind, content, caption, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+			`, a.ID, a.UserID, a.TopicID, a.Kind, a.Content, a.Caption, a.CreatedAt)
+			if err != nil {
+				return 0, fmt.Errorf("failed to restore attachment %d: %w", a.ID, err)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM topic_trash WHERE id = ?", trashID); err != nil {
+		return 0, fmt.Errorf("failed to delete trash entry: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(trashed), nil
+}
+
+// PruneTrash permanently deletes the trash entries of topics deleted before the given time
+// and returns how many there were
+func (r *TopicRepository) PruneTrash(ctx context.Context, before time.Time) (int64, error) {
+	result, err := DB.ExecContext(ctx, "DELETE FROM topic_trash WHERE deleted_at < ?", before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune trash: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/example/engbot/internal/textutil"
+	"github.com/example/engbot/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// Tx is a unit of work: the records created through it are saved together when WithTx commits
+// or not at all
+type Tx struct {
+	tx        *sqlx.Tx
+	committed []func() // run once the transaction is committed
+}
+
+// WithTx runs fn in one transaction, committing it when fn returns nil and rolling everything
+// back when fn fails or panics. fn must write only through tx: SQLite has a single writer
+// connection, so a write on DB inside fn waits for the transaction forever. Reads made outside tx
+// don't see what fn wrote until WithTx commits.
+func WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	t := &Tx{tx: tx}
+	if err := fn(t); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	for _, f := range t.committed {
+		f()
+	}
+	return nil
+}
+
+// CreateTopic creates a topic like TopicRepository.Create
+func (t *Tx) CreateTopic(ctx context.Context, topic *models.Topic) error {
+	t.committed = append(t.committed, func() { invalidateTopics(ctx, topic.UserID) })
+	return createTopic(ctx, t.tx, topic)
+}
+
+// CreateStatistics creates the statistics of a topic like StatisticsRepository.Create
+func (t *Tx) CreateStatistics(ctx context.Context, stats *models.Statistics) error {
+	return createStatistics(ctx, t.tx, stats)
+}
+
+// CreateAttachment adds an attachment to a topic like TopicAttachmentRepository.Create
+func (t *Tx) CreateAttachment(ctx context.Context, attachment *models.TopicAttachment) error {
+	return createAttachment(ctx, t.tx, attachment)
+}
+
+// CreateRepetition creates a repetition like RepetitionRepository.Create
+func (t *Tx) CreateRepetition(ctx context.Context, rep *models.Repetition) error {
+	return createRepetition(ctx, t.tx, rep)
+}
+
+// createTopic creates a topic with its description, category and own intervals unless the user
+// already has one with the same name, ignoring case and extra spaces, in which case it returns
+// ErrTopicExists
+func createTopic(ctx context.Context, db sqlx.ExtContext, topic *models.Topic) error {
+	if topic.Difficulty == 0 {
+		topic.Difficulty = models.DefaultTopicDifficulty
+	}
+
+	var names []string
+	if err := sqlx.SelectContext(ctx, db, &names, "SELECT name FROM topics WHERE user_id = ?", topic.UserID); err != nil {
+		return fmt.Errorf("failed to get topic names: %w", err)
+	}
+	normalized := textutil.Normalize(topic.Name)
+	for _, name := range names {
+		if textutil.Normalize(name) == normalized {
+			return fmt.Errorf("failed to create topic %q: %w", topic.Name, ErrTopicExists)
+		}
+	}
+
+	id, err := insertID(ctx, db, `
+		INSERT INTO topics (user_id, name, description, difficulty, category, custom_intervals, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, topic.UserID, topic.Name, topic.Description, topic.Difficulty, topic.Category, topic.CustomIntervals)
+	if err != nil {
+		return fmt.Errorf("failed to create topic: %w", err)
+	}
+
+	topic.ID = id
+	topic.CreatedAt = time.Now()
+	topic.UpdatedAt = topic.CreatedAt
+	return nil
+}
+
+// createStatistics creates the statistics of a topic
+func createStatistics(ctx context.Context, db sqlx.ExtContext, stats *models.Statistics) error {
+	id, err := insertID(ctx, db, `
+		INSERT INTO statistics (user_id, topic_id, total_repetitions, completed_repetitions)
+		VALUES (?, ?, ?, ?)
+	`, stats.UserID, stats.TopicID, stats.TotalRepetitions, stats.CompletedRepetitions)
+	if err != nil {
+		return fmt.Errorf("failed to create statistics: %w", err)
+	}
+	stats.ID = id
+	return nil
+}
+
+// createRepetition creates a repetition
+func createRepetition(ctx context.Context, db sqlx.ExtContext, rep *models.Repetition) error {
+	id, err := insertID(ctx, db, `
+		INSERT INTO repetitions (user_id, topic_id, repetition_number, next_review_date, last_review_date, completed)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, rep.UserID, rep.TopicID, rep.RepetitionNumber, rep.NextReviewDate, rep.LastReviewDate, rep.Completed)
+	if err != nil {
+		return fmt.Errorf("failed to create repetition: %w", err)
+	}
+	rep.ID = id
+	return nil
+}
@@ -11,7 +11,18 @@ type UserConfig struct {
 	Repetitions      int
 	IsActive         bool
 	NotificationHour int
-	LastBatchTime    sql.NullTime
-	CreatedAt        sql.NullTime
-	UpdatedAt        sql.NullTime
-} 
\ No newline at end of file
+	IntervalPreset   string // One of the IntervalPreset* names
+	CustomIntervals  string // Comma-separated ladder used with IntervalPresetCustom
+	// SM-2 parameters tuned from the user's recall history, zero values until the first tuning
+	SM2PassThreshold    int
+	SM2InitialIntervals string // Comma-separated, like CustomIntervals
+	SM2MinEasiness      float64
+	SM2TunedAt          sql.NullTime
+	NewWordsPerDay      int // Brand-new words a day, NoNewWordsLimit for no cap
+	WordOfDayEnabled    bool
+	WordOfDayDeckID     int64  // Deck the word of the day comes from, 0 for the user's upcoming new words
+	ReviewDirection     string // Direction of the flashcards, one of the models.Direction* values
+	LastBatchTime       sql.NullTime
+	CreatedAt           sql.NullTime
+	UpdatedAt           sql.NullTime
+}
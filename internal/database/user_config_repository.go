@@ -3,40 +3,101 @@ package database
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/example/engbot/pkg/models"
+)
+
+// Repetition interval presets selectable with /intervals
+const (
+	IntervalPresetIntensive = "intensive"
+	IntervalPresetStandard  = "standard"
+	IntervalPresetRelaxed   = "relaxed"
+	IntervalPresetCustom    = "custom"
+)
+
+// IntervalPresets maps the built-in preset names to their repetition ladders in days
+var IntervalPresets = map[string][]int{
+	IntervalPresetIntensive: {1, 1, 2, 4, 7, 12, 20},
+	IntervalPresetStandard:  DefaultIntervals,
+	IntervalPresetRelaxed:   {1, 3, 5, 10, 20, 35, 60},
+}
+
+// Limits for a custom repetition ladder
+const (
+	MaxCustomIntervals = 10
+	MaxIntervalDays    = 365
 )
 
+// DefaultNewWordsPerDay is how many brand-new words a day come up for review until the user
+// sets their own number
+const DefaultNewWordsPerDay = 20
+
+// NoNewWordsLimit is the NewWordsPerDay of a user who takes every new word at once
+const NoNewWordsLimit = -1
+
+// MaxNewWordsPerDay bounds the daily number of new words a user can set
+const MaxNewWordsPerDay = 200
+
 // GetUserConfig retrieves user configuration
 func GetUserConfig(ctx context.Context, userID int64) (*UserConfig, error) {
+	if config, ok := configCache.Get(ctx, configKey(userID)); ok {
+		if config == nil {
+			return nil, nil
+		}
+		copied := *config
+		return &copied, nil
+	}
+
 	query := `
-		SELECT user_id, words_per_batch, repetitions, is_active, last_batch_time, created_at, updated_at
+		SELECT user_id, words_per_batch, repetitions, is_active, interval_preset, custom_intervals,
+			sm2_pass_threshold, sm2_initial_intervals, sm2_min_easiness, sm2_tuned_at,
+			new_words_per_day, word_of_day_enabled, word_of_day_deck_id, review_direction, last_batch_time, created_at, updated_at
 		FROM user_configs
 		WHERE user_id = ?
 	`
 
 	config := &UserConfig{}
-	err := DB.QueryRowContext(ctx, query, userID).Scan(
+	err := readDB.QueryRowContext(ctx, query, userID).Scan(
 		&config.UserID,
 		&config.WordsPerBatch,
 		&config.Repetitions,
 		&config.IsActive,
+		&config.IntervalPreset,
+		&config.CustomIntervals,
+		&config.SM2PassThreshold,
+		&config.SM2InitialIntervals,
+		&config.SM2MinEasiness,
+		&config.SM2TunedAt,
+		&config.NewWordsPerDay,
+		&config.WordOfDayEnabled,
+		&config.WordOfDayDeckID,
+		&config.ReviewDirection,
 		&config.LastBatchTime,
 		&config.CreatedAt,
 		&config.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
+		configCache.Set(ctx, configKey(userID), nil)
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
 
+	copied := *config
+	configCache.Set(ctx, configKey(userID), &copied)
 	return config, nil
 }
 
 // UpdateUserConfig updates user configuration
 func UpdateUserConfig(ctx context.Context, config *UserConfig) error {
+	defer invalidateConfig(ctx, config.UserID)
+
 	query := `
 		UPDATE user_configs
 		SET words_per_batch = ?, repetitions = ?, is_active = ?, updated_at = ?
@@ -56,6 +117,8 @@ func UpdateUserConfig(ctx context.Context, config *UserConfig) error {
 
 // UpdateLastBatchTime updates the last batch time for a user
 func UpdateLastBatchTime(ctx context.Context, userID int64) error {
+	defer invalidateConfig(ctx, userID)
+
 	query := `
 		UPDATE user_configs
 		SET last_batch_time = ?
@@ -64,4 +127,228 @@ func UpdateLastBatchTime(ctx context.Context, userID int64) error {
 
 	_, err := DB.ExecContext(ctx, query, time.Now(), userID)
 	return err
-} 
\ No newline at end of file
+} 
+// Intervals returns the repetition ladder selected in the config
+func (c *UserConfig) Intervals() []int {
+	if c.IntervalPreset == IntervalPresetCustom {
+		if intervals, err := ParseIntervals(c.CustomIntervals); err == nil {
+			return intervals
+		}
+	}
+	if intervals, ok := IntervalPresets[c.IntervalPreset]; ok {
+		return intervals
+	}
+	return DefaultIntervals
+}
+
+// GetUserIntervals returns the user's repetition ladder, DefaultIntervals if none was chosen
+func GetUserIntervals(ctx context.Context, userID int64) ([]int, error) {
+	config, err := GetUserConfig(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user config: %w", err)
+	}
+	if config == nil {
+		return DefaultIntervals, nil
+	}
+	return config.Intervals(), nil
+}
+
+// SetUserIntervals stores the preset, and the ladder itself for IntervalPresetCustom,
+// creating the user's config row if needed
+func SetUserIntervals(ctx context.Context, userID int64, preset string, custom []int) error {
+	defer invalidateConfig(ctx, userID)
+
+	query := `
+		INSERT INTO user_configs (user_id, interval_preset, custom_intervals, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			interval_preset = excluded.interval_preset,
+			custom_intervals = excluded.custom_intervals,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := DB.ExecContext(ctx, query, userID, preset, FormatIntervals(custom), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save intervals: %w", err)
+	}
+	return nil
+}
+
+// SetSM2Params stores the SM-2 parameters tuned for the user at tunedAt, creating the user's
+// config row if needed
+func SetSM2Params(ctx context.Context, userID int64, passThreshold int, initialIntervals []int, minEasiness float64, tunedAt time.Time) error {
+	defer invalidateConfig(ctx, userID)
+
+	query := `
+		INSERT INTO user_configs (user_id, sm2_pass_threshold, sm2_initial_intervals, sm2_min_easiness, sm2_tuned_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			sm2_pass_threshold = excluded.sm2_pass_threshold,
+			sm2_initial_intervals = excluded.sm2_initial_intervals,
+			sm2_min_easiness = excluded.sm2_min_easiness,
+			sm2_tuned_at = excluded.sm2_tuned_at,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := DB.ExecContext(ctx, query, userID, passThreshold, FormatIntervals(initialIntervals), minEasiness,
+		tunedAt.UTC(), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save SM-2 parameters: %w", err)
+	}
+	return nil
+}
+
+// GetNewWordsPerDay returns how many brand-new words a day the user takes, DefaultNewWordsPerDay
+// if they haven't chosen
+func GetNewWordsPerDay(ctx context.Context, userID int64) (int, error) {
+	config, err := GetUserConfig(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user config: %w", err)
+	}
+	if config == nil {
+		return DefaultNewWordsPerDay, nil
+	}
+	return config.NewWordsPerDay, nil
+}
+
+// SetNewWordsPerDay stores how many brand-new words a day the user takes, creating the user's
+// config row if needed
+func SetNewWordsPerDay(ctx context.Context, userID int64, limit int) error {
+	defer invalidateConfig(ctx, userID)
+
+	query := `
+		INSERT INTO user_configs (user_id, new_words_per_day, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			new_words_per_day = excluded.new_words_per_day,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := DB.ExecContext(ctx, query, userID, limit, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save new words per day: %w", err)
+	}
+	return nil
+}
+
+// GetReviewDirection returns the direction of the user's flashcards, models.DirectionForward
+// if they haven't chosen
+func GetReviewDirection(ctx context.Context, userID int64) (string, error) {
+	config, err := GetUserConfig(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config: %w", err)
+	}
+	if config == nil {
+		return models.DirectionForward, nil
+	}
+	return config.ReviewDirection, nil
+}
+
+// SetReviewDirection stores the direction of the user's flashcards, creating the user's config
+// row if needed
+func SetReviewDirection(ctx context.Context, userID int64, direction string) error {
+	defer invalidateConfig(ctx, userID)
+
+	query := `
+		INSERT INTO user_configs (user_id, review_direction, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			review_direction = excluded.review_direction,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := DB.ExecContext(ctx, query, userID, direction, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save review direction: %w", err)
+	}
+	return nil
+}
+
+// GetWordOfDay reports whether the user gets the word of the day and the deck it comes from,
+// 0 for the user's upcoming new words
+func GetWordOfDay(ctx context.Context, userID int64) (bool, int64, error) {
+	config, err := GetUserConfig(ctx, userID)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to get user config: %w", err)
+	}
+	if config == nil {
+		return false, 0, nil
+	}
+	return config.WordOfDayEnabled, config.WordOfDayDeckID, nil
+}
+
+// SetWordOfDay turns the user's word of the day on or off and sets the deck it comes from,
+// creating the user's config row if needed
+func SetWordOfDay(ctx context.Context, userID int64, enabled bool, deckID int64) error {
+	defer invalidateConfig(ctx, userID)
+
+	query := `
+		INSERT INTO user_configs (user_id, word_of_day_enabled, word_of_day_deck_id, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			word_of_day_enabled = excluded.word_of_day_enabled,
+			word_of_day_deck_id = excluded.word_of_day_deck_id,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := DB.ExecContext(ctx, query, userID, enabled, deckID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save word of the day: %w", err)
+	}
+	return nil
+}
+
+// ParseIntervals reads a comma-separated ladder like "1, 3, 7, 14". Every interval is
+// between 1 and MaxIntervalDays days and none is shorter than the one before it.
+func ParseIntervals(s string) ([]int, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) > MaxCustomIntervals {
+		return nil, fmt.Errorf("too many intervals: %d, at most %d", len(fields), MaxCustomIntervals)
+	}
+
+	intervals := make([]int, 0, len(fields))
+	for _, field := range fields {
+		days, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q", strings.TrimSpace(field))
+		}
+		if days < 1 || days > MaxIntervalDays {
+			return nil, fmt.Errorf("interval %d is out of range 1-%d", days, MaxIntervalDays)
+		}
+		if len(intervals) > 0 && days < intervals[len(intervals)-1] {
+			return nil, fmt.Errorf("interval %d is shorter than the previous one", days)
+		}
+		intervals = append(intervals, days)
+	}
+	return intervals, nil
+}
+
+// CompressIntervals scales the ladder down so its steps add up to about days, e.g. to get through
+// a topic before an exam. No step gets shorter than a day, so a very short deadline is overshot.
+func CompressIntervals(intervals []int, days int) []int {
+	total := 0
+	for _, step := range intervals {
+		total += step
+	}
+	if total <= days {
+		return intervals
+	}
+
+	compressed := make([]int, len(intervals))
+	for i, step := range intervals {
+		compressed[i] = max(1, step*days/total)
+		if i > 0 {
+			compressed[i] = max(compressed[i], compressed[i-1])
+		}
+	}
+	return compressed
+}
+
+// FormatIntervals is the inverse of ParseIntervals
+func FormatIntervals(intervals []int) string {
+	fields := make([]string, len(intervals))
+	for i, days := range intervals {
+		fields[i] = strconv.Itoa(days)
+	}
+	return strings.Join(fields, ", ")
+}
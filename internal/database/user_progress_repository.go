@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/example/engbot/pkg/models"
 )
@@ -14,70 +16,137 @@ func NewUserProgressRepository() *UserProgressRepository {
 	return &UserProgressRepository{}
 }
 
-// GetByUserAndWord returns progress for a specific user and word
-func (r *UserProgressRepository) GetByUserAndWord(userID int64, wordID int) (*models.UserProgress, error) {
+// cardDirectionOn holds for the progress rows up of the directions turned on for the word's topic t:
+// the topic's own direction, otherwise the one of the user's config c
+const cardDirectionOn = `COALESCE(NULLIF(t.review_direction, ''), c.review_direction, 'en_ru') IN ('both', up.direction)`
+
+// GetByUserAndWord returns progress for a specific user, word and direction
+func (r *UserProgressRepository) GetByUserAndWord(userID int64, wordID int, direction string) (*models.UserProgress, error) {
 	var progress models.UserProgress
-	err := DB.Get(&progress, "SELECT * FROM user_progress WHERE user_id = $1 AND word_id = $2", userID, wordID)
+	err := readDB.Get(&progress, "SELECT * FROM user_progress WHERE user_id = $1 AND word_id = $2 AND direction = $3", userID, wordID, direction)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user progress: %v", err)
+		return nil, fmt.Errorf("failed to get user progress: %w", err)
 	}
 	return &progress, nil
 }
 
-// GetDueWordsForUser returns words due for review for a specific user
+// GetDueWordsForUser returns the user's own cards due for review in the directions turned on
 func (r *UserProgressRepository) GetDueWordsForUser(userID int64) ([]models.UserProgress, error) {
 	var progress []models.UserProgress
 	
 	query := `
-		SELECT * FROM user_progress
-		WHERE user_id = $1 AND next_review_date <= datetime('now') AND is_learned = FALSE
-		ORDER BY next_review_date ASC
+		SELECT up.* FROM user_progress up
+		JOIN words w ON w.id = up.word_id AND w.user_id = up.user_id
+		JOIN topics t ON t.id = w.topic_id
+		LEFT JOIN user_configs c ON c.user_id = up.user_id
+		WHERE up.user_id = $1 AND up.next_review_date <= $2 AND up.is_learned = FALSE
+			AND ` + cardDirectionOn + `
+		ORDER BY up.next_review_date ASC
 	`
 	
-	err := DB.Select(&progress, query, userID)
+	err := readDB.Select(&progress, query, userID, time.Now().UTC())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get due words: %v", err)
+		return nil, fmt.Errorf("failed to get due words: %w", err)
 	}
 	return progress, nil
 }
 
+// GetAllByUserID returns the user's progress on every word of theirs they have reviewed, a row
+// per direction
+func (r *UserProgressRepository) GetAllByUserID(ctx context.Context, userID int64) ([]models.UserProgress, error) {
+	var progress []models.UserProgress
+	err := readDB.SelectContext(ctx, &progress, `
+		SELECT up.* FROM user_progress up
+		JOIN words w ON w.id = up.word_id AND w.user_id = up.user_id
+		WHERE up.user_id = ?
+		ORDER BY up.word_id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user progress: %w", err)
+	}
+	return progress, nil
+}
+
+// CountIntroducedSince returns how many words the user has seen for the first time since the moment
+func (r *UserProgressRepository) CountIntroducedSince(ctx context.Context, userID int64, since time.Time) (int, error) {
+	var count int
+	err := readDB.GetContext(ctx, &count, `
+		SELECT COUNT(*) FROM user_progress
+		WHERE user_id = ? AND introduced_at >= ?
+	`, userID, since.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to count new words: %w", err)
+	}
+	return count, nil
+}
+
+// AddReverseCards gives the user's words in the topics reviewed in the reverse direction, their
+// own setting or the user's, a reverse card each, due right away like any new card. Returns how
+// many cards were added.
+func (r *UserProgressRepository) AddReverseCards(ctx context.Context, userID int64) (int, error) {
+	result, err := DB.ExecContext(ctx, `
+		INSERT INTO user_progress (user_id, word_id, direction, next_review_date)
+		SELECT w.user_id, w.id, ?, CURRENT_TIMESTAMP
+		FROM words w
+		JOIN topics t ON t.id = w.topic_id
+		LEFT JOIN user_configs c ON c.user_id = w.user_id
+		WHERE w.user_id = ? AND COALESCE(NULLIF(t.review_direction, ''), c.review_direction, 'en_ru') IN ('both', ?)
+		ON CONFLICT (user_id, word_id, direction) DO NOTHING
+	`, models.DirectionReverse, userID, models.DirectionReverse)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add reverse cards: %w", err)
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to add reverse cards: %w", err)
+	}
+	return int(added), nil
+}
+
+// utcTime stores an optional moment in UTC like the other dates, nil as NULL
+func utcTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
 // Create inserts a new progress record
 func (r *UserProgressRepository) Create(progress *models.UserProgress) error {
 	query := `
 		INSERT INTO user_progress (
-			user_id, word_id, last_review_date, next_review_date, 
+			user_id, word_id, direction, last_review_date, next_review_date, 
 			interval, easiness_factor, repetitions, last_quality, consecutive_right, is_learned,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+			introduced_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 	`
+	if progress.Direction == "" {
+		progress.Direction = models.DirectionForward
+	}
 	
-	result, err := DB.Exec(
+	id, err := insertID(context.Background(), DB,
 		query,
 		progress.UserID,
 		progress.WordID,
-		progress.LastReviewDate,
-		progress.NextReviewDate,
+		progress.Direction,
+		progress.LastReviewDate.UTC(),
+		progress.NextReviewDate.UTC(),
 		progress.Interval,
 		progress.EasinessFactor,
 		progress.Repetitions,
 		progress.LastQuality,
 		progress.ConsecutiveRight,
 		progress.IsLearned,
+		utcTime(progress.IntroducedAt),
 	)
 	
 	if err != nil {
-		return fmt.Errorf("failed to create progress: %v", err)
-	}
-	
-	// Получаем ID новой записи
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get last insert id: %v", err)
+		return fmt.Errorf("failed to create progress: %w", err)
 	}
 	progress.ID = int(id)
 	
 	// Получаем created_at и updated_at
-	return DB.QueryRow("SELECT created_at, updated_at FROM user_progress WHERE id = $1", 
+	return readDB.QueryRow("SELECT created_at, updated_at FROM user_progress WHERE id = $1", 
 		progress.ID).Scan(&progress.CreatedAt, &progress.UpdatedAt)
 }
 
@@ -93,29 +162,31 @@ func (r *UserProgressRepository) Update(progress *models.UserProgress) error {
 			last_quality = $6,
 			consecutive_right = $7,
 			is_learned = $8,
+			introduced_at = $9,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $9
+		WHERE id = $10
 	`
 	
 	_, err := DB.Exec(
 		query,
-		progress.LastReviewDate,
-		progress.NextReviewDate,
+		progress.LastReviewDate.UTC(),
+		progress.NextReviewDate.UTC(),
 		progress.Interval,
 		progress.EasinessFactor,
 		progress.Repetitions,
 		progress.LastQuality,
 		progress.ConsecutiveRight,
 		progress.IsLearned,
+		utcTime(progress.IntroducedAt),
 		progress.ID,
 	)
 	
 	if err != nil {
-		return fmt.Errorf("failed to update progress: %v", err)
+		return fmt.Errorf("failed to update progress: %w", err)
 	}
 	
 	// Получаем обновленное значение updated_at
-	return DB.QueryRow("SELECT updated_at FROM user_progress WHERE id = $1", 
+	return readDB.QueryRow("SELECT updated_at FROM user_progress WHERE id = $1", 
 		progress.ID).Scan(&progress.UpdatedAt)
 }
 
@@ -129,9 +200,12 @@ func (r *UserProgressRepository) Delete(id int) error {
 func (r *UserProgressRepository) CreateOrUpdate(progress *models.UserProgress) error {
 	// Проверяем, существует ли запись
 	var existingID int
-	err := DB.QueryRow(
-		"SELECT id FROM user_progress WHERE user_id = $1 AND word_id = $2", 
-		progress.UserID, progress.WordID,
+	if progress.Direction == "" {
+		progress.Direction = models.DirectionForward
+	}
+	err := readDB.QueryRow(
+		"SELECT id FROM user_progress WHERE user_id = $1 AND word_id = $2 AND direction = $3", 
+		progress.UserID, progress.WordID, progress.Direction,
 	).Scan(&existingID)
 	
 	if err == nil {
@@ -148,9 +222,9 @@ func (r *UserProgressRepository) CreateOrUpdate(progress *models.UserProgress) e
 func (r *UserProgressRepository) GetUserStatistics(userID int64) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 	
-	// Get total words in the system
+	// Get total words the user owns
 	var totalWords int
-	err := DB.Get(&totalWords, "SELECT COUNT(*) FROM words")
+	err := readDB.Get(&totalWords, "SELECT COUNT(*) FROM words WHERE user_id = $1", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -158,7 +232,7 @@ func (r *UserProgressRepository) GetUserStatistics(userID int64) (map[string]int
 	
 	// Get words in progress (started learning)
 	var wordsInProgress int
-	err = DB.Get(&wordsInProgress, "SELECT COUNT(*) FROM user_progress WHERE user_id = $1", userID)
+	err = readDB.Get(&wordsInProgress, "SELECT COUNT(*) FROM user_progress WHERE user_id = $1", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -166,9 +240,9 @@ func (r *UserProgressRepository) GetUserStatistics(userID int64) (map[string]int
 	
 	// Get words due today
 	var dueToday int
-	err = DB.Get(&dueToday, 
-		"SELECT COUNT(*) FROM user_progress WHERE user_id = $1 AND next_review_date <= datetime('now', '+1 day')", 
-		userID)
+	err = readDB.Get(&dueToday, 
+		"SELECT COUNT(*) FROM user_progress WHERE user_id = $1 AND next_review_date <= $2", 
+		userID, time.Now().Add(24*time.Hour).UTC())
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +250,7 @@ func (r *UserProgressRepository) GetUserStatistics(userID int64) (map[string]int
 	
 	// Get words mastered (reviewed at least 5 times with high rating)
 	var mastered int
-	err = DB.Get(&mastered, 
+	err = readDB.Get(&mastered, 
 		"SELECT COUNT(*) FROM user_progress WHERE user_id = $1 AND repetitions >= 5 AND last_quality >= 4", 
 		userID)
 	if err != nil {
@@ -186,7 +260,7 @@ func (r *UserProgressRepository) GetUserStatistics(userID int64) (map[string]int
 	
 	// Get average easiness factor
 	var avgEF float64
-	err = DB.Get(&avgEF, 
+	err = readDB.Get(&avgEF, 
 		"SELECT COALESCE(AVG(easiness_factor), 2.5) FROM user_progress WHERE user_id = $1", 
 		userID)
 	if err != nil {
@@ -204,7 +278,7 @@ func (r *UserProgressRepository) GetTopicCompletionStats(userID int64, topicID i
 	
 	// Get total words in the topic
 	var totalWordsInTopic int
-	err := DB.Get(&totalWordsInTopic, "SELECT COUNT(*) FROM words WHERE topic_id = $1", topicID)
+	err := readDB.Get(&totalWordsInTopic, "SELECT COUNT(*) FROM words WHERE topic_id = $1 AND user_id = $2", topicID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -212,7 +286,7 @@ func (r *UserProgressRepository) GetTopicCompletionStats(userID int64, topicID i
 	
 	// Get words from topic that user has started learning
 	var wordsInProgress int
-	err = DB.Get(&wordsInProgress, `
+	err = readDB.Get(&wordsInProgress, `
 		SELECT COUNT(*) FROM user_progress up
 		JOIN words w ON up.word_id = w.id
 		WHERE up.user_id = $1 AND w.topic_id = $2
@@ -224,7 +298,7 @@ func (r *UserProgressRepository) GetTopicCompletionStats(userID int64, topicID i
 	
 	// Get words from topic that user has mastered
 	var masteredWords int
-	err = DB.Get(&masteredWords, `
+	err = readDB.Get(&masteredWords, `
 		SELECT COUNT(*) FROM user_progress up
 		JOIN words w ON up.word_id = w.id
 		WHERE up.user_id = $1 AND w.topic_id = $2
@@ -244,7 +318,7 @@ func (r *UserProgressRepository) GetTopicCompletionStats(userID int64, topicID i
 	
 	// Get topic name
 	var topicName string
-	err = DB.Get(&topicName, "SELECT name FROM topics WHERE id = $1", topicID)
+	err = readDB.Get(&topicName, "SELECT name FROM topics WHERE id = $1 AND user_id = $2", topicID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -253,21 +327,55 @@ func (r *UserProgressRepository) GetTopicCompletionStats(userID int64, topicID i
 	return stats, nil
 }
 
-// GetLearnedWords returns all words marked as learned for a specific user
+// GetLearnedWords returns all words marked as learned for a specific user in any direction
 func (r *UserProgressRepository) GetLearnedWords(userID int64) ([]models.Word, error) {
 	var words []models.Word
 	
 	query := `
-		SELECT w.*
+		SELECT DISTINCT w.id, w.word, w.translation, COALESCE(w.description, '') AS description, w.topic_id,
+			   w.user_id, w.difficulty, COALESCE(w.pronunciation, '') AS pronunciation,
+			   COALESCE(w.examples, '') AS examples, COALESCE(w.verb_forms, '') AS verb_forms,
+			   w.created_at, w.updated_at
 		FROM words w
-		JOIN user_progress up ON w.id = up.word_id
+		JOIN user_progress up ON w.id = up.word_id AND w.user_id = up.user_id
 		WHERE up.user_id = $1 AND up.is_learned = TRUE
 		ORDER BY w.word
 	`
 	
-	err := DB.Select(&words, query, userID)
+	err := readDB.Select(&words, query, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get learned words: %v", err)
+		return nil, fmt.Errorf("failed to get learned words: %w", err)
 	}
 	return words, nil
-} 
\ No newline at end of file
+}
+
+// HardWord is a word with the SM-2 progress that makes it hard for the user
+type HardWord struct {
+	models.Word
+	EasinessFactor float64 `db:"easiness_factor"`
+	LastQuality    int     `db:"last_quality"`
+}
+
+// GetHardestWords returns the user's words not learned yet that have been forgotten at least once,
+// the lowest easiness factor first and the ones forgotten last time first among equals. A word
+// reviewed in both directions counts with its harder one.
+func (r *UserProgressRepository) GetHardestWords(ctx context.Context, userID int64, limit int) ([]HardWord, error) {
+	var words []HardWord
+	err := readDB.SelectContext(ctx, &words, `
+		SELECT w.id, w.word, w.translation, COALESCE(w.description, '') AS description, w.topic_id,
+			w.user_id, COALESCE(w.difficulty, 1) AS difficulty, COALESCE(w.pronunciation, '') AS pronunciation,
+			COALESCE(w.examples, '') AS examples, COALESCE(w.verb_forms, '') AS verb_forms,
+			w.created_at, w.updated_at, MIN(up.easiness_factor) AS easiness_factor, MIN(up.last_quality) AS last_quality
+		FROM words w
+		JOIN user_progress up ON w.id = up.word_id AND w.user_id = up.user_id
+		WHERE up.user_id = ? AND up.introduced_at IS NOT NULL AND up.is_learned = FALSE
+			AND (up.easiness_factor < 2.5 OR up.last_quality < 3)
+		GROUP BY w.id
+		ORDER BY easiness_factor, last_quality, w.word
+		LIMIT ?
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hardest words: %w", err)
+	}
+	return words, nil
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/example/engbot/pkg/models"
 )
@@ -18,27 +22,38 @@ func NewUserRepository() *UserRepository {
 
 // Create inserts a new user
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	defer invalidateUser(ctx, user.TelegramID)
+
 	query := `
 		INSERT INTO users (
 			telegram_id, username, first_name, last_name,
-			notification_enabled, notification_hour
-		) VALUES (?, ?, ?, ?, ?, ?)
+			notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out,
+			notification_hours, quiet_hours_start, quiet_hours_end, overdue_policy, overdue_days,
+			report_enabled, report_day, report_hour
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	result, err := DB.ExecContext(ctx, query,
+	id, err := insertID(ctx, DB, query,
 		user.TelegramID,
 		user.Username,
 		user.FirstName,
 		user.LastName,
 		user.NotificationEnabled,
 		user.NotificationHour,
+		user.SkipFirstRepetitions,
+		user.DigestEnabled,
+		user.Language,
+		user.BroadcastOptOut,
+		user.NotificationHours,
+		user.QuietHoursStart,
+		user.QuietHoursEnd,
+		user.OverduePolicy,
+		user.OverdueDays,
+		user.ReportEnabled,
+		user.ReportDay,
+		user.ReportHour,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create user: %v", err)
-	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get last insert ID: %v", err)
+		return fmt.Errorf("failed to create user: %w", err)
 	}
 	user.ID = id
 
@@ -47,6 +62,8 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 
 // Update modifies an existing user
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+	defer invalidateUser(ctx, user.TelegramID)
+
 	query := `
 		UPDATE users SET
 			username = ?,
@@ -54,6 +71,23 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 			last_name = ?,
 			notification_enabled = ?,
 			notification_hour = ?,
+			skip_first_repetitions = ?,
+			digest_enabled = ?,
+			language = ?,
+			broadcast_opt_out = ?,
+			notification_hours = ?,
+			quiet_hours_start = ?,
+			quiet_hours_end = ?,
+			daily_goal = ?,
+			story_enabled = ?,
+			board_enabled = ?,
+			overdue_policy = ?,
+			overdue_days = ?,
+			daily_review_limit = ?,
+			report_enabled = ?,
+			report_day = ?,
+			report_hour = ?,
+			scheduler = ?,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
@@ -63,46 +97,368 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 		user.LastName,
 		user.NotificationEnabled,
 		user.NotificationHour,
+		user.SkipFirstRepetitions,
+		user.DigestEnabled,
+		user.Language,
+		user.BroadcastOptOut,
+		user.NotificationHours,
+		user.QuietHoursStart,
+		user.QuietHoursEnd,
+		user.DailyGoal,
+		user.StoryEnabled,
+		user.BoardEnabled,
+		user.OverduePolicy,
+		user.OverdueDays,
+		user.DailyReviewLimit,
+		user.ReportEnabled,
+		user.ReportDay,
+		user.ReportHour,
+		user.Scheduler,
 		user.ID,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to update user: %v", err)
+		return fmt.Errorf("failed to update user: %w", err)
 	}
 	return nil
 }
 
-// GetUsersForNotification returns all users who should receive notifications at the current hour
+// GetUsersForNotification returns all users who should receive notifications at the current hour.
+// Users whose quiet hours cover the hour are left out.
 func (r *UserRepository) GetUsersForNotification(ctx context.Context, hour int) ([]models.User, error) {
 	query := `
 		SELECT id, telegram_id, username, first_name, last_name,
-			   notification_enabled, notification_hour, created_at, updated_at
+			   notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out, notification_hours, quiet_hours_start, quiet_hours_end, daily_goal, story_enabled, board_enabled, board_message_id, overdue_policy, overdue_days, daily_review_limit, report_enabled, report_day, report_hour, scheduler, is_admin, created_at, updated_at
 		FROM users
-		WHERE notification_enabled = true AND notification_hour = ?
+		WHERE notification_enabled = true AND inactive_since IS NULL
+			AND ((notification_hours = '' AND notification_hour = ?)
+				OR ',' || notification_hours || ',' LIKE ?)
 	`
 	var users []models.User
-	err := DB.SelectContext(ctx, &users, query, hour)
+	err := readDB.SelectContext(ctx, &users, query, hour, fmt.Sprintf("%%,%d,%%", hour))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get users for notification: %v", err)
+		return nil, fmt.Errorf("failed to get users for notification: %w", err)
+	}
+
+	awake := users[:0]
+	for _, user := range users {
+		if !InQuietHours(&user, hour) {
+			awake = append(awake, user)
+		}
+	}
+	return awake, nil
+}
+
+// GetUsersForStory returns the users who get the daily story at this hour, their first reminder hour
+func (r *UserRepository) GetUsersForStory(ctx context.Context, hour int) ([]models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name,
+			   notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out, notification_hours, quiet_hours_start, quiet_hours_end, daily_goal, story_enabled, board_enabled, board_message_id, overdue_policy, overdue_days, daily_review_limit, report_enabled, report_day, report_hour, scheduler, is_admin, created_at, updated_at
+		FROM users
+		WHERE story_enabled = true AND notification_hour = ? AND inactive_since IS NULL
+	`
+	var users []models.User
+	if err := readDB.SelectContext(ctx, &users, query, hour); err != nil {
+		return nil, fmt.Errorf("failed to get users for story: %w", err)
+	}
+	return users, nil
+}
+
+// GetUsersForWordOfDay returns the users who get the word of the day at this hour, their first reminder hour
+func (r *UserRepository) GetUsersForWordOfDay(ctx context.Context, hour int) ([]models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name,
+			   notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out, notification_hours, quiet_hours_start, quiet_hours_end, daily_goal, story_enabled, board_enabled, board_message_id, overdue_policy, overdue_days, daily_review_limit, report_enabled, report_day, report_hour, scheduler, is_admin, created_at, updated_at
+		FROM users
+		WHERE notification_hour = ? AND inactive_since IS NULL
+			AND id IN (SELECT user_id FROM user_configs WHERE word_of_day_enabled = true)
+	`
+	var users []models.User
+	if err := readDB.SelectContext(ctx, &users, query, hour); err != nil {
+		return nil, fmt.Errorf("failed to get users for word of the day: %w", err)
 	}
 	return users, nil
 }
 
+// GetUsersWithOverduePolicy returns the users who want overdue repetitions handled by something
+// other than daily reminders
+func (r *UserRepository) GetUsersWithOverduePolicy(ctx context.Context) ([]models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name,
+			   notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out, notification_hours, quiet_hours_start, quiet_hours_end, daily_goal, story_enabled, board_enabled, board_message_id, overdue_policy, overdue_days, daily_review_limit, report_enabled, report_day, report_hour, scheduler, is_admin, created_at, updated_at
+		FROM users
+		WHERE overdue_policy <> ? AND inactive_since IS NULL
+	`
+	var users []models.User
+	if err := readDB.SelectContext(ctx, &users, query, models.OverdueRemind); err != nil {
+		return nil, fmt.Errorf("failed to get users with overdue policy: %w", err)
+	}
+	return users, nil
+}
+
+// GetUsersWithStalledTopics returns the users who have stalled topics outside the archive
+func (r *UserRepository) GetUsersWithStalledTopics(ctx context.Context) ([]models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name,
+			   notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out, notification_hours, quiet_hours_start, quiet_hours_end, daily_goal, story_enabled, board_enabled, board_message_id, overdue_policy, overdue_days, daily_review_limit, report_enabled, report_day, report_hour, scheduler, is_admin, created_at, updated_at
+		FROM users
+		WHERE inactive_since IS NULL
+			AND EXISTS (SELECT 1 FROM topics t WHERE t.user_id = users.id AND t.stalled = true AND t.archived = false)
+	`
+	var users []models.User
+	if err := readDB.SelectContext(ctx, &users, query); err != nil {
+		return nil, fmt.Errorf("failed to get users with stalled topics: %w", err)
+	}
+	return users, nil
+}
+
+// GetUsersWithReviewLimit returns the users who capped how many repetitions can come due per day
+func (r *UserRepository) GetUsersWithReviewLimit(ctx context.Context) ([]models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name,
+			   notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out, notification_hours, quiet_hours_start, quiet_hours_end, daily_goal, story_enabled, board_enabled, board_message_id, overdue_policy, overdue_days, daily_review_limit, report_enabled, report_day, report_hour, scheduler, is_admin, created_at, updated_at
+		FROM users
+		WHERE daily_review_limit > 0 AND inactive_since IS NULL
+	`
+	var users []models.User
+	if err := readDB.SelectContext(ctx, &users, query); err != nil {
+		return nil, fmt.Errorf("failed to get users with review limit: %w", err)
+	}
+	return users, nil
+}
+
+// GetUsersForWeeklyReport returns the users who get the weekly report on this day of the week
+// at this hour
+func (r *UserRepository) GetUsersForWeeklyReport(ctx context.Context, weekday time.Weekday, hour int) ([]models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name,
+			   notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out, notification_hours, quiet_hours_start, quiet_hours_end, daily_goal, story_enabled, board_enabled, board_message_id, overdue_policy, overdue_days, daily_review_limit, report_enabled, report_day, report_hour, scheduler, is_admin, created_at, updated_at
+		FROM users
+		WHERE report_enabled = true AND report_day = ? AND report_hour = ? AND inactive_since IS NULL
+	`
+	var users []models.User
+	if err := readDB.SelectContext(ctx, &users, query, int(weekday), hour); err != nil {
+		return nil, fmt.Errorf("failed to get users for weekly report: %w", err)
+	}
+	return users, nil
+}
+
+// SetInactive marks the user inactive because Telegram refuses messages to the user, who blocked
+// the bot or deleted the account, and reports whether the user was active. Inactive users are left
+// out of the scheduler's sweeps and of broadcasts.
+func (r *UserRepository) SetInactive(ctx context.Context, telegramID int64) (bool, error) {
+	result, err := DB.ExecContext(ctx, `
+		UPDATE users SET inactive_since = CURRENT_TIMESTAMP
+		WHERE telegram_id = ? AND inactive_since IS NULL
+	`, telegramID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark user inactive: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark user inactive: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Reactivate marks the user active again, e.g. after the user unblocked the bot and sent /start,
+// and reports whether the user was inactive
+func (r *UserRepository) Reactivate(ctx context.Context, telegramID int64) (bool, error) {
+	result, err := DB.ExecContext(ctx, `
+		UPDATE users SET inactive_since = NULL
+		WHERE telegram_id = ? AND inactive_since IS NOT NULL
+	`, telegramID)
+	if err != nil {
+		return false, fmt.Errorf("failed to reactivate user: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to reactivate user: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SetBoardMessageID remembers the message of the user's daily board. It is kept out of Update,
+// so saving a user loaded before the board was sent doesn't lose it.
+func (r *UserRepository) SetBoardMessageID(ctx context.Context, userID int64, messageID int) error {
+	_, err := DB.ExecContext(ctx, "UPDATE users SET board_message_id = ? WHERE id = ?", messageID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to save board message: %w", err)
+	}
+
+	var telegramID int64
+	if err := readDB.GetContext(ctx, &telegramID, "SELECT telegram_id FROM users WHERE id = ?", userID); err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	invalidateUser(ctx, telegramID)
+	return nil
+}
+
+// MaxNotificationHours limits how many reminder times a user can have per day
+const MaxNotificationHours = 6
+
+// NotificationHours returns the hours of day the user gets reminders at, in ascending order.
+// Users who never picked several times have just NotificationHour.
+func NotificationHours(user *models.User) []int {
+	hours, err := ParseHours(user.NotificationHours)
+	if err != nil || len(hours) == 0 {
+		return []int{user.NotificationHour}
+	}
+	return hours
+}
+
+// SetNotificationHours stores the reminder hours on the user, the earliest one doubling as NotificationHour
+func SetNotificationHours(user *models.User, hours []int) {
+	hours = normalizeHours(hours)
+	if len(hours) == 0 {
+		return
+	}
+	user.NotificationHour = hours[0]
+	user.NotificationHours = FormatHours(hours)
+}
+
+// InQuietHours reports whether the hour falls into the user's quiet hours. The window runs from
+// QuietHoursStart up to QuietHoursEnd and may wrap past midnight; equal bounds mean no quiet hours.
+func InQuietHours(user *models.User, hour int) bool {
+	start, end := user.QuietHoursStart, user.QuietHoursEnd
+	if start == end {
+		return false
+	}
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// ParseHours reads a comma-separated list of hours like "9, 14, 20". Every hour is
+// between 0 and 23, duplicates are dropped and the result is sorted.
+func ParseHours(s string) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var hours []int
+	for _, field := range strings.Split(s, ",") {
+		hour, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid hour %q", strings.TrimSpace(field))
+		}
+		if hour < 0 || hour > 23 {
+			return nil, fmt.Errorf("hour %d is out of range 0-23", hour)
+		}
+		hours = append(hours, hour)
+	}
+
+	hours = normalizeHours(hours)
+	if len(hours) > MaxNotificationHours {
+		return nil, fmt.Errorf("too many hours: %d, at most %d", len(hours), MaxNotificationHours)
+	}
+	return hours, nil
+}
+
+// FormatHours is the inverse of ParseHours, in the form stored in notification_hours
+func FormatHours(hours []int) string {
+	fields := make([]string, len(hours))
+	for i, hour := range hours {
+		fields[i] = strconv.Itoa(hour)
+	}
+	return strings.Join(fields, ",")
+}
+
+// normalizeHours returns the hours sorted and without duplicates
+func normalizeHours(hours []int) []int {
+	sorted := append([]int(nil), hours...)
+	sort.Ints(sorted)
+
+	unique := sorted[:0]
+	for i, hour := range sorted {
+		if i == 0 || hour != sorted[i-1] {
+			unique = append(unique, hour)
+		}
+	}
+	return unique
+}
+
 // GetAdminUsers returns all admin users
 func (r *UserRepository) GetAdminUsers(ctx context.Context) ([]models.User, error) {
 	query := `
 		SELECT id, telegram_id, username, first_name, last_name,
-			   notification_enabled, notification_hour, created_at, updated_at
+			   notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out, notification_hours, quiet_hours_start, quiet_hours_end, daily_goal, story_enabled, board_enabled, board_message_id, overdue_policy, overdue_days, daily_review_limit, report_enabled, report_day, report_hour, scheduler, is_admin, created_at, updated_at
 		FROM users
 		WHERE is_admin = true
 	`
 	var users []models.User
-	err := DB.SelectContext(ctx, &users, query)
+	err := readDB.SelectContext(ctx, &users, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get admin users: %v", err)
+		return nil, fmt.Errorf("failed to get admin users: %w", err)
 	}
 	return users, nil
 }
 
+// GetBroadcastRecipients returns all users who haven't opted out of admin broadcasts
+func (r *UserRepository) GetBroadcastRecipients(ctx context.Context) ([]models.User, error) {
+	query := `
+		SELECT id, telegram_id, username, first_name, last_name,
+			   notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out, notification_hours, quiet_hours_start, quiet_hours_end, daily_goal, story_enabled, board_enabled, board_message_id, overdue_policy, overdue_days, daily_review_limit, report_enabled, report_day, report_hour, scheduler, is_admin, created_at, updated_at
+		FROM users
+		WHERE broadcast_opt_out = false AND inactive_since IS NULL
+		ORDER BY id
+	`
+	var users []models.User
+	err := readDB.SelectContext(ctx, &users, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast recipients: %w", err)
+	}
+	return users, nil
+}
+
+// AdminStats holds bot-wide totals for the /admin command
+type AdminStats struct {
+	Users                int
+	NotificationsEnabled int
+	BroadcastOptOut      int
+	Inactive             int // Blocked the bot or deleted the account
+	BroadcastRecipients  int // Active and not opted out
+	Topics               int
+	ArchivedTopics       int
+	Repetitions          int
+	CompletedRepetitions int
+}
+
+// GetAdminStats counts users, topics and repetitions across the whole bot
+func (r *UserRepository) GetAdminStats(ctx context.Context) (*AdminStats, error) {
+	stats := &AdminStats{}
+
+	err := readDB.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COUNT(CASE WHEN notification_enabled = true THEN 1 END),
+			COUNT(CASE WHEN broadcast_opt_out = true THEN 1 END),
+			COUNT(CASE WHEN inactive_since IS NOT NULL THEN 1 END),
+			COUNT(CASE WHEN broadcast_opt_out = false AND inactive_since IS NULL THEN 1 END)
+		FROM users
+	`).Scan(&stats.Users, &stats.NotificationsEnabled, &stats.BroadcastOptOut, &stats.Inactive, &stats.BroadcastRecipients)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	err = readDB.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(CASE WHEN archived = true THEN 1 END)
+		FROM topics
+	`).Scan(&stats.Topics, &stats.ArchivedTopics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count topics: %w", err)
+	}
+
+	err = readDB.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(CASE WHEN completed = true THEN 1 END)
+		FROM repetitions
+	`).Scan(&stats.Repetitions, &stats.CompletedRepetitions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count repetitions: %w", err)
+	}
+
+	return stats, nil
+}
+
 // UserStats represents user's learning statistics
 type UserStats struct {
 	TotalWords      int
@@ -114,32 +470,34 @@ type UserStats struct {
 // GetUserStats retrieves user's learning statistics
 func (r *UserRepository) GetUserStats(ctx context.Context, userID int64) (*UserStats, error) {
 	stats := &UserStats{}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 
 	// Get total learned words
-	err := DB.QueryRowContext(ctx, `
+	err := readDB.QueryRowContext(ctx, `
 		SELECT COUNT(*) 
 		FROM learned_words lw
 		JOIN users u ON u.id = lw.user_id
 		WHERE u.telegram_id = ?
 	`, userID).Scan(&stats.TotalLearned)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total learned words: %v", err)
+		return nil, fmt.Errorf("failed to get total learned words: %w", err)
 	}
 
 	// Get words learned today
-	err = DB.QueryRowContext(ctx, `
+	err = readDB.QueryRowContext(ctx, `
 		SELECT COUNT(*) 
 		FROM learned_words lw
 		JOIN users u ON u.id = lw.user_id
 		WHERE u.telegram_id = ? 
-		AND date(lw.learned_at) = date('now')
-	`, userID).Scan(&stats.LearnedToday)
+		AND lw.learned_at >= ? AND lw.learned_at < ?
+	`, userID, today, today.AddDate(0, 0, 1)).Scan(&stats.LearnedToday)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get today's learned words: %v", err)
+		return nil, fmt.Errorf("failed to get today's learned words: %w", err)
 	}
 
 	// Get learning streak (consecutive days with learned words)
-	err = DB.QueryRowContext(ctx, `
+	err = readDB.QueryRowContext(ctx, `
 		WITH RECURSIVE dates(date) AS (
 			SELECT date('now', '-30 days')
 			UNION ALL
@@ -169,12 +527,15 @@ func (r *UserRepository) GetUserStats(ctx context.Context, userID int64) (*UserS
 		stats.LearningStreak = 0
 	}
 
-	// Get total available words
-	err = DB.QueryRowContext(ctx, `
-		SELECT COUNT(*) FROM words
-	`).Scan(&stats.TotalWords)
+	// Get total words the user owns
+	err = readDB.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM words w
+		JOIN users u ON u.id = w.user_id
+		WHERE u.telegram_id = ?
+	`, userID).Scan(&stats.TotalWords)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total words: %v", err)
+		return nil, fmt.Errorf("failed to get total words: %w", err)
 	}
 
 	return stats, nil
@@ -182,20 +543,24 @@ func (r *UserRepository) GetUserStats(ctx context.Context, userID int64) (*UserS
 
 // GetByTelegramID returns a user by Telegram ID
 func (r *UserRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	if user, ok := userCache.Get(ctx, userKey(telegramID)); ok {
+		return &user, nil
+	}
+
 	query := `
 		SELECT id, telegram_id, username, first_name, last_name, 
-			   notification_enabled, notification_hour, created_at, updated_at
+			   notification_enabled, notification_hour, skip_first_repetitions, digest_enabled, language, broadcast_opt_out, notification_hours, quiet_hours_start, quiet_hours_end, daily_goal, story_enabled, board_enabled, board_message_id, overdue_policy, overdue_days, daily_review_limit, report_enabled, report_day, report_hour, scheduler, is_admin, created_at, updated_at
 		FROM users 
 		WHERE telegram_id = ?
 	`
 	
 	user := &models.User{}
-	err := DB.GetContext(ctx, user, query, telegramID)
+	err := readDB.GetContext(ctx, user, query, telegramID)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %v", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 	
 	// Verify that we got a valid user
@@ -203,5 +568,6 @@ func (r *UserRepository) GetByTelegramID(ctx context.Context, telegramID int64)
 		return nil, nil
 	}
 	
+	userCache.Set(ctx, userKey(telegramID), *user)
 	return user, nil
 } 
\ No newline at end of file
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WordEasiness is the SM-2 state of a word the user has reviewed, with its current difficulty
+type WordEasiness struct {
+	WordID         int     `db:"word_id"`
+	TopicID        int64   `db:"topic_id"`
+	Difficulty     int     `db:"difficulty"`
+	EasinessFactor float64 `db:"easiness_factor"`
+}
+
+// TopicAnswers counts the answers to the test questions about a topic
+type TopicAnswers struct {
+	Total int
+	Wrong int
+}
+
+// GetReviewedWordEasiness returns the easiness factors of the user's words reviewed at least once,
+// the lower one for a word reviewed in both directions
+func (r *WordRepository) GetReviewedWordEasiness(ctx context.Context, userID int64) ([]WordEasiness, error) {
+	var words []WordEasiness
+	err := readDB.SelectContext(ctx, &words, `
+		SELECT w.id AS word_id, w.topic_id, COALESCE(w.difficulty, 1) AS difficulty, MIN(up.easiness_factor) AS easiness_factor
+		FROM words w
+		JOIN user_progress up ON up.word_id = w.id AND up.user_id = w.user_id
+		WHERE w.user_id = ? AND up.introduced_at IS NOT NULL
+		GROUP BY w.id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get word easiness: %w", err)
+	}
+	return words, nil
+}
+
+// GetUsersWithReviewedWords returns the active users who have reviewed at least one word
+func (r *WordRepository) GetUsersWithReviewedWords(ctx context.Context) ([]int64, error) {
+	var userIDs []int64
+	err := readDB.SelectContext(ctx, &userIDs, `
+		SELECT DISTINCT up.user_id
+		FROM user_progress up
+		JOIN users u ON u.id = up.user_id
+		WHERE up.introduced_at IS NOT NULL AND u.inactive_since IS NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with reviewed words: %w", err)
+	}
+	return userIDs, nil
+}
+
+// SetDifficulties stores the difficulties of the user's words by word ID
+func (r *WordRepository) SetDifficulties(ctx context.Context, userID int64, difficulties map[int]int) error {
+	if len(difficulties) == 0 {
+		return nil
+	}
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for wordID, difficulty := range difficulties {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE words SET difficulty = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND user_id = ?
+		`, difficulty, wordID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update word difficulty: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit word difficulties: %w", err)
+	}
+	return nil
+}
+
+// GetTopicAnswers sums up the user's tests since the moment by topic. A test over several topics
+// doesn't say which of them the mistakes were in, so all its answers count for each of them.
+func (r *TestResultRepository) GetTopicAnswers(ctx context.Context, userID int64, since time.Time) (map[int64]TopicAnswers, error) {
+	var results []struct {
+		Topics       string `db:"topics"`
+		TotalWords   int    `db:"total_words"`
+		CorrectWords int    `db:"correct_words"`
+	}
+	err := readDB.SelectContext(ctx, &results, `
+		SELECT topics, total_words, correct_words
+		FROM test_results
+		WHERE user_id = ? AND test_date >= ?
+	`, userID, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test results: %w", err)
+	}
+
+	answers := make(map[int64]TopicAnswers)
+	for _, result := range results {
+		for _, field := range strings.Split(result.Topics, ",") {
+			topicID, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+			if err != nil {
+				continue
+			}
+			a := answers[topicID]
+			a.Total += result.TotalWords
+			a.Wrong += result.TotalWords - result.CorrectWords
+			answers[topicID] = a
+		}
+	}
+	return answers, nil
+}
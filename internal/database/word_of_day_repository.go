@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WordOfDay is a word of the day sent to a user
+type WordOfDay struct {
+	ID          int64        `db:"id"`
+	UserID      int64        `db:"user_id"`
+	DeckID      int64        `db:"deck_id"` // 0 for a word of the user's own
+	WordID      int64        `db:"word_id"` // ID of the deck word, or of the user's word with no deck
+	Word        string       `db:"word"`
+	Translation string       `db:"translation"`
+	Correct     sql.NullBool `db:"correct"` // The quiz answer, null until answered
+	SentAt      time.Time    `db:"sent_at"`
+}
+
+// WordOfDayRepository keeps the words of the day sent to the users
+type WordOfDayRepository struct{}
+
+// NewWordOfDayRepository creates a new repository instance
+func NewWordOfDayRepository() *WordOfDayRepository {
+	return &WordOfDayRepository{}
+}
+
+// Save records the word sent to the user as the word of the day and returns its ID
+func (r *WordOfDayRepository) Save(ctx context.Context, userID, deckID, wordID int64, word, translation string) (int64, error) {
+	id, err := insertID(ctx, DB, `
+		INSERT INTO word_of_day (user_id, deck_id, word_id, word, translation, sent_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, userID, deckID, wordID, word, translation)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save word of the day: %w", err)
+	}
+	return id, nil
+}
+
+// GetSentWords returns the lowercased words the user has already got as the word of the day
+func (r *WordOfDayRepository) GetSentWords(ctx context.Context, userID int64) (map[string]bool, error) {
+	var words []string
+	if err := readDB.SelectContext(ctx, &words, "SELECT word FROM word_of_day WHERE user_id = ?", userID); err != nil {
+		return nil, fmt.Errorf("failed to get words of the day: %w", err)
+	}
+	sent := make(map[string]bool, len(words))
+	for _, w := range words {
+		sent[strings.ToLower(w)] = true
+	}
+	return sent, nil
+}
+
+// GetByID returns the user's word of the day by its ID, or nil if there is none
+func (r *WordOfDayRepository) GetByID(ctx context.Context, userID, id int64) (*WordOfDay, error) {
+	var word WordOfDay
+	err := readDB.GetContext(ctx, &word, `
+		SELECT id, user_id, deck_id, word_id, word, translation, correct, sent_at
+		FROM word_of_day
+		WHERE id = ? AND user_id = ?
+	`, id, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get word of the day: %w", err)
+	}
+	return &word, nil
+}
+
+// SaveAnswer records the quiz answer to the word of the day and reports whether it is the
+// first one: a word is answered once
+func (r *WordOfDayRepository) SaveAnswer(ctx context.Context, userID, id int64, correct bool) (bool, error) {
+	result, err := DB.ExecContext(ctx, `
+		UPDATE word_of_day SET correct = ?
+		WHERE id = ? AND user_id = ? AND correct IS NULL
+	`, correct, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to save word of the day answer: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to save word of the day answer: %w", err)
+	}
+	return updated > 0, nil
+}
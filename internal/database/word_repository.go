@@ -0,0 +1,332 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/example/engbot/pkg/models"
+)
+
+// WordRepository handles database operations for words
+type WordRepository struct{}
+
+// NewWordRepository creates a new repository instance
+func NewWordRepository() *WordRepository {
+	return &WordRepository{}
+}
+
+// GetByID returns the user's word by its ID
+func (r *WordRepository) GetByID(ctx context.Context, userID int64, wordID int) (*models.Word, error) {
+	query := `
+		SELECT id, word, kind, translation, COALESCE(description, '') AS description, topic_id,
+			   COALESCE(user_id, 0) AS user_id, difficulty, COALESCE(pronunciation, '') AS pronunciation,
+			   COALESCE(examples, '') AS examples, COALESCE(verb_forms, '') AS verb_forms,
+			   synonyms, antonyms, definition, created_at, updated_at
+		FROM words
+		WHERE id = ? AND user_id = ?
+	`
+	var word models.Word
+	err := readDB.GetContext(ctx, &word, query, wordID, userID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get word %d: %w", wordID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get word: %w", err)
+	}
+	return &word, nil
+}
+
+// GetByUserID returns the user's words ordered by topic and spelling
+func (r *WordRepository) GetByUserID(ctx context.Context, userID int64) ([]models.Word, error) {
+	query := `
+		SELECT id, word, kind, translation, COALESCE(description, '') AS description, topic_id,
+			   COALESCE(user_id, 0) AS user_id, difficulty, COALESCE(pronunciation, '') AS pronunciation,
+			   COALESCE(examples, '') AS examples, COALESCE(verb_forms, '') AS verb_forms,
+			   synonyms, antonyms, definition, created_at, updated_at
+		FROM words
+		WHERE user_id = ?
+		ORDER BY topic_id, word
+	`
+	var words []models.Word
+	if err := readDB.SelectContext(ctx, &words, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get user words: %w", err)
+	}
+	return words, nil
+}
+
+// CountByTopicID returns the number of words in the user's topic
+func (r *WordRepository) CountByTopicID(ctx context.Context, userID, topicID int64) (int, error) {
+	var count int
+	err := readDB.GetContext(ctx, &count, "SELECT COUNT(*) FROM words WHERE topic_id = ? AND user_id = ?", topicID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count words: %w", err)
+	}
+	return count, nil
+}
+
+// ImportWords adds words to the user's topics in one transaction and puts them into the user's
+// flashcard review, due right away. Words their topic already has are not added twice, words
+// without examples are queued for enrichment, words with spaces become phrases. Returns the
+// number of new words.
+func (r *WordRepository) ImportWords(ctx context.Context, userID int64, words []models.Word) (int, error) {
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO words (word, kind, translation, description, examples, topic_id, user_id, enrichment_status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (word, topic_id) DO NOTHING
+	`
+	progressQuery := `
+		INSERT INTO user_progress (user_id, word_id, next_review_date)
+		SELECT user_id, id, CURRENT_TIMESTAMP FROM words WHERE word = ? AND topic_id = ? AND user_id = ?
+		ON CONFLICT (user_id, word_id, direction) DO NOTHING
+	`
+	added := 0
+	for _, w := range words {
+		status := ""
+		if strings.TrimSpace(w.Examples) == "" {
+			status = models.EnrichmentPending
+		}
+		result, err := tx.ExecContext(ctx, query, w.Word, models.KindOf(w.Word), w.Translation, w.Description, w.Examples, w.TopicID, userID, status)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create word: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to create word: %w", err)
+		}
+		added += int(n)
+
+		if _, err := tx.ExecContext(ctx, progressQuery, w.Word, w.TopicID, userID); err != nil {
+			return 0, fmt.Errorf("failed to add word to review: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return added, nil
+}
+
+// GetPendingEnrichment returns up to limit words waiting for enrichment, oldest first
+func (r *WordRepository) GetPendingEnrichment(ctx context.Context, limit int) ([]models.Word, error) {
+	query := `
+		SELECT id, word, translation, COALESCE(description, '') AS description, topic_id,
+			   COALESCE(user_id, 0) AS user_id, difficulty, COALESCE(pronunciation, '') AS pronunciation,
+			   COALESCE(examples, '') AS examples, COALESCE(verb_forms, '') AS verb_forms,
+			   enrichment_status, created_at, updated_at
+		FROM words
+		WHERE enrichment_status = ?
+		ORDER BY id
+		LIMIT ?
+	`
+	var words []models.Word
+	if err := readDB.SelectContext(ctx, &words, query, models.EnrichmentPending, limit); err != nil {
+		return nil, fmt.Errorf("failed to get words to enrich: %w", err)
+	}
+	return words, nil
+}
+
+// GetSynonymsBackfill returns up to limit words enriched, or imported with examples, before the
+// model was asked about synonyms, oldest first
+func (r *WordRepository) GetSynonymsBackfill(ctx context.Context, limit int) ([]models.Word, error) {
+	query := `
+		SELECT id, word, translation, COALESCE(description, '') AS description, topic_id,
+			   COALESCE(user_id, 0) AS user_id, difficulty, COALESCE(pronunciation, '') AS pronunciation,
+			   COALESCE(examples, '') AS examples, COALESCE(verb_forms, '') AS verb_forms,
+			   enrichment_status, created_at, updated_at
+		FROM words
+		WHERE synonyms_checked = false AND enrichment_status IN ('', ?)
+		ORDER BY id
+		LIMIT ?
+	`
+	var words []models.Word
+	if err := readDB.SelectContext(ctx, &words, query, models.EnrichmentDone, limit); err != nil {
+		return nil, fmt.Errorf("failed to get words to backfill synonyms: %w", err)
+	}
+	return words, nil
+}
+
+// SaveEnrichment fills in the word's empty description, examples, verb forms, synonyms and
+// antonyms and marks it as enriched. What the user already has is kept.
+func (r *WordRepository) SaveEnrichment(ctx context.Context, wordID int, description, examples, verbForms, synonyms, antonyms string) error {
+	query := `
+		UPDATE words SET
+			description = CASE WHEN COALESCE(description, '') = '' THEN ? ELSE description END,
+			examples = CASE WHEN COALESCE(examples, '') = '' THEN ? ELSE examples END,
+			verb_forms = CASE WHEN COALESCE(verb_forms, '') = '' THEN ? ELSE verb_forms END,
+			synonyms = CASE WHEN synonyms = '' THEN ? ELSE synonyms END,
+			antonyms = CASE WHEN antonyms = '' THEN ? ELSE antonyms END,
+			synonyms_checked = true,
+			enrichment_status = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+	_, err := DB.ExecContext(ctx, query, description, examples, verbForms, synonyms, antonyms, models.EnrichmentDone, wordID)
+	if err != nil {
+		return fmt.Errorf("failed to save word enrichment: %w", err)
+	}
+	return nil
+}
+
+// SetDefinition keeps the English definition of the word
+func (r *WordRepository) SetDefinition(ctx context.Context, wordID int, definition string) error {
+	_, err := DB.ExecContext(ctx, "UPDATE words SET definition = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", definition, wordID)
+	if err != nil {
+		return fmt.Errorf("failed to save word definition: %w", err)
+	}
+	return nil
+}
+
+// SetKind sets whether the user's word is a phrase or a collocation. Single words stay words.
+func (r *WordRepository) SetKind(ctx context.Context, userID int64, wordID int, kind string) error {
+	result, err := DB.ExecContext(ctx, "UPDATE words SET kind = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ? AND kind <> ?",
+		kind, wordID, userID, models.KindWord)
+	if err != nil {
+		return fmt.Errorf("failed to set word kind: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to set word kind: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("failed to set word kind of %d: %w", wordID, ErrNotFound)
+	}
+	return nil
+}
+
+// KindCount is how many of the user's words of a kind there are and how far the user got with them
+type KindCount struct {
+	Kind    string `db:"kind"`
+	Total   int    `db:"total"`
+	Studied int    `db:"studied"` // reviewed at least once
+	Learned int    `db:"learned"` // learned in a direction
+}
+
+// CountByKind counts the user's words, phrases and collocations apart, with how many of each
+// are studied and learned
+func (r *WordRepository) CountByKind(ctx context.Context, userID int64) ([]KindCount, error) {
+	var counts []KindCount
+	err := readDB.SelectContext(ctx, &counts, `
+		SELECT w.kind, COUNT(*) AS total,
+			SUM(CASE WHEN EXISTS (
+				SELECT 1 FROM user_progress up WHERE up.word_id = w.id AND up.user_id = w.user_id AND up.repetitions > 0
+			) THEN 1 ELSE 0 END) AS studied,
+			SUM(CASE WHEN EXISTS (
+				SELECT 1 FROM user_progress up WHERE up.word_id = w.id AND up.user_id = w.user_id AND up.is_learned = true
+			) THEN 1 ELSE 0 END) AS learned
+		FROM words w
+		WHERE w.user_id = ?
+		GROUP BY w.kind
+		ORDER BY w.kind
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count words by kind: %w", err)
+	}
+	return counts, nil
+}
+
+// SetEnrichmentStatus sets the word's enrichment status
+func (r *WordRepository) SetEnrichmentStatus(ctx context.Context, wordID int, status string) error {
+	if _, err := DB.ExecContext(ctx, "UPDATE words SET enrichment_status = ? WHERE id = ?", status, wordID); err != nil {
+		return fmt.Errorf("failed to set word enrichment status: %w", err)
+	}
+	return nil
+}
+
+// UpdateTranslation changes the translation of the user's word
+func (r *WordRepository) UpdateTranslation(ctx context.Context, userID int64, wordID int, translation string) error {
+	result, err := DB.ExecContext(ctx, "UPDATE words SET translation = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ?",
+		translation, wordID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update word: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("word %d %w", wordID, ErrNotFound)
+	}
+	return nil
+}
+
+// Delete removes the user's word together with its review progress
+func (r *WordRepository) Delete(ctx context.Context, userID int64, wordID int) error {
+	tx, err := DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_progress WHERE user_id = ? AND word_id = ?", userID, wordID); err != nil {
+		return fmt.Errorf("failed to delete word progress: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM words WHERE id = ? AND user_id = ?", wordID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete word: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("word %d %w", wordID, ErrNotFound)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SearchWords finds the user's words whose spelling or translation contains the query, case-insensitively
+// (SQLite's LOWER only folds ASCII letters, so Cyrillic matching there is case-sensitive).
+// Exact matches come first, then words starting with the query, then the rest alphabetically.
+func (r *WordRepository) SearchWords(ctx context.Context, userID int64, query string, limit int) ([]models.Word, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, nil
+	}
+	pattern := escapeLike(query)
+
+	sqlQuery := `
+		SELECT id, word, kind, translation, COALESCE(description, '') AS description, topic_id,
+			   COALESCE(user_id, 0) AS user_id, difficulty, COALESCE(pronunciation, '') AS pronunciation,
+			   COALESCE(examples, '') AS examples, COALESCE(verb_forms, '') AS verb_forms,
+			   created_at, updated_at
+		FROM words
+		WHERE user_id = ? AND (LOWER(word) LIKE ? ESCAPE '\' OR LOWER(translation) LIKE ? ESCAPE '\')
+		ORDER BY
+			CASE
+				WHEN LOWER(word) = ? OR LOWER(translation) = ? THEN 0
+				WHEN LOWER(word) LIKE ? ESCAPE '\' OR LOWER(translation) LIKE ? ESCAPE '\' THEN 1
+				ELSE 2
+			END,
+			word
+		LIMIT ?
+	`
+	var words []models.Word
+	err := readDB.SelectContext(ctx, &words, sqlQuery,
+		userID,
+		"%"+pattern+"%", "%"+pattern+"%",
+		query, query,
+		pattern+"%", pattern+"%",
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search words: %w", err)
+	}
+	return words, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s, using \ as the escape character
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,139 @@
+// Package enrichment fills in the examples, descriptions, verb forms, synonyms and antonyms
+// of imported words with a language model in the background
+package enrichment
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/example/engbot/internal/ai"
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/pkg/models"
+)
+
+// batchSize is how many pending words are taken from the database at once
+const batchSize = 50
+
+// retryInterval is how long the enricher waits after failing to read the pending words
+const retryInterval = time.Minute
+
+// Enricher asks a language model about the words waiting for enrichment with a pool of workers,
+// keeping to a rate limit. Words stay pending in the database, so the work survives restarts.
+type Enricher struct {
+	provider ai.Provider
+	words    *database.WordRepository
+	workers  int
+	interval time.Duration // between two requests to the model
+	wake     chan struct{}
+}
+
+// New creates an enricher with the given number of workers that sends at most perMinute
+// requests a minute
+func New(provider ai.Provider, words *database.WordRepository, workers, perMinute int) *Enricher {
+	return &Enricher{
+		provider: provider,
+		words:    words,
+		workers:  max(1, workers),
+		interval: time.Minute / time.Duration(max(1, perMinute)),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes the enricher up after new words were queued. It never blocks.
+func (e *Enricher) Notify() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run enriches the pending words until ctx is done, waiting for Notify when there are none
+func (e *Enricher) Run(ctx context.Context) {
+	limiter := time.NewTicker(e.interval)
+	defer limiter.Stop()
+
+	for {
+		words, err := e.words.GetPendingEnrichment(ctx, batchSize)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to get words to enrich", "error", err)
+		}
+		if len(words) == 0 && err == nil {
+			// Nothing new to enrich: backfill the synonyms of the words enriched before they existed
+			words, err = e.words.GetSynonymsBackfill(ctx, batchSize)
+			if err != nil {
+				logging.FromContext(ctx).Error("failed to get words to backfill synonyms", "error", err)
+			}
+		}
+		if len(words) == 0 {
+			var retry <-chan time.Time
+			if err != nil {
+				retry = time.After(retryInterval)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-e.wake:
+			case <-retry:
+			}
+			continue
+		}
+
+		e.enrichBatch(ctx, words, limiter.C)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// enrichBatch hands the words out to the workers, one per tick of the limiter
+func (e *Enricher) enrichBatch(ctx context.Context, words []models.Word, tick <-chan time.Time) {
+	jobs := make(chan models.Word)
+	var wg sync.WaitGroup
+	for i := 0; i < min(e.workers, len(words)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for w := range jobs {
+				e.enrich(ctx, w)
+			}
+		}()
+	}
+
+feed:
+	for _, w := range words {
+		select {
+		case <-ctx.Done():
+			break feed
+		case <-tick:
+			jobs <- w
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// enrich asks the model about the word and saves the answer. A word the model fails on is
+// marked as failed, unless the enricher is stopping: then it stays pending for the next run.
+func (e *Enricher) enrich(ctx context.Context, w models.Word) {
+	details, err := e.provider.DescribeWord(ctx, w.Word, w.Translation)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		logging.FromContext(ctx).Warn("failed to enrich word", "word_id", w.ID, "error", err)
+		if err := e.words.SetEnrichmentStatus(ctx, w.ID, models.EnrichmentFailed); err != nil {
+			logging.FromContext(ctx).Error("failed to mark word enrichment as failed", "word_id", w.ID, "error", err)
+		}
+		return
+	}
+
+	examples := strings.Join(details.Examples, "\n")
+	err = e.words.SaveEnrichment(ctx, w.ID, details.Description, examples, details.VerbForms,
+		strings.Join(details.Synonyms, ", "), strings.Join(details.Antonyms, ", "))
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to save word enrichment", "word_id", w.ID, "error", err)
+	}
+}
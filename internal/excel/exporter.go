@@ -0,0 +1,142 @@
+package excel
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/pkg/models"
+)
+
+// Exporter writes everything the bot stores about a user into one workbook:
+// topics, repetition history, words with review progress and per-topic statistics
+type Exporter struct {
+	topicRepo      *database.TopicRepository
+	repetitionRepo *database.RepetitionRepository
+	statsRepo      *database.StatisticsRepository
+	wordRepo       *database.WordRepository
+	progressRepo   *database.UserProgressRepository
+}
+
+// NewExporter creates an exporter reading from the global database connection
+func NewExporter() *Exporter {
+	return &Exporter{
+		topicRepo:      database.NewTopicRepository(),
+		repetitionRepo: database.NewRepetitionRepository(),
+		statsRepo:      database.NewStatisticsRepository(),
+		wordRepo:       database.NewWordRepository(),
+		progressRepo:   database.NewUserProgressRepository(),
+	}
+}
+
+// Export writes the user's data to w as an .xlsx file
+func (e *Exporter) Export(ctx context.Context, userID int64, w io.Writer) error {
+	workbook, err := e.Workbook(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := workbook.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// Workbook builds the export workbook of the user
+func (e *Exporter) Workbook(ctx context.Context, userID int64) (*Workbook, error) {
+	topics, err := e.topicRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	topicNames := make(map[int64]string, len(topics))
+	for _, t := range topics {
+		topicNames[t.ID] = t.Name
+	}
+
+	repetitions, err := e.repetitionRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	words, err := e.wordRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := e.progressRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := e.statsRepo.GetUserStatistics(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workbook := NewWorkbook()
+	addTopicsSheet(workbook, topics)
+	addRepetitionsSheet(workbook, repetitions, topicNames)
+	addWordsSheet(workbook, words, progress, topicNames)
+	addStatisticsSheet(workbook, stats)
+	return workbook, nil
+}
+
+// addTopicsSheet lists the topics, archived ones included
+func addTopicsSheet(workbook *Workbook, topics []models.Topic) {
+	sheet := workbook.AddSheet("Темы", "Тема", "Категория", "Сложность", "Статус", "Успешных повторений", "Интервал, дней", "Создана")
+	for _, t := range topics {
+		sheet.AddRow(t.Name, t.Category, t.Difficulty, topicStatus(t), t.ReviewCount, t.ReviewInterval, t.CreatedAt)
+	}
+}
+
+// addRepetitionsSheet lists the done and scheduled repetitions with their notes
+func addRepetitionsSheet(workbook *Workbook, repetitions []models.Repetition, topicNames map[int64]string) {
+	sheet := workbook.AddSheet("Повторения", "Тема", "Повторение", "Запланировано", "Выполнено", "Дата повторения", "Заметка")
+	for _, rep := range repetitions {
+		sheet.AddRow(topicNames[rep.TopicID], rep.RepetitionNumber, rep.NextReviewDate, rep.Completed, rep.LastReviewDate, rep.Notes)
+	}
+}
+
+// addWordsSheet lists the words with the user's flashcard progress
+func addWordsSheet(workbook *Workbook, words []models.Word, progress []models.UserProgress, topicNames map[int64]string) {
+	byWord := make(map[int]models.UserProgress, len(progress))
+	for _, p := range progress {
+		// The forward card stands for the word, the reverse one only when there is no other
+		if _, ok := byWord[p.WordID]; !ok || p.Direction == models.DirectionForward {
+			byWord[p.WordID] = p
+		}
+	}
+
+	sheet := workbook.AddSheet("Слова", "Слово", "Перевод", "Тема", "Описание", "Примеры",
+		"Повторений", "Следующее повторение", "Выучено")
+	for _, w := range words {
+		p, ok := byWord[w.ID]
+		if !ok {
+			sheet.AddRow(w.Word, w.Translation, topicNames[w.TopicID], w.Description, w.Examples)
+			continue
+		}
+		sheet.AddRow(w.Word, w.Translation, topicNames[w.TopicID], w.Description, w.Examples,
+			p.Repetitions, p.NextReviewDate, p.IsLearned)
+	}
+}
+
+// addStatisticsSheet lists the repetition counters per topic
+func addStatisticsSheet(workbook *Workbook, stats []models.Statistics) {
+	sheet := workbook.AddSheet("Статистика", "Тема", "Всего повторений", "Выполнено")
+	total, completed := 0, 0
+	for _, s := range stats {
+		sheet.AddRow(s.TopicName, s.TotalRepetitions, s.CompletedRepetitions)
+		total += s.TotalRepetitions
+		completed += s.CompletedRepetitions
+	}
+	sheet.AddRow("Итого", total, completed)
+}
+
+// topicStatus describes whether the topic is active, muted or archived
+func topicStatus(t models.Topic) string {
+	switch {
+	case t.Archived:
+		return "в архиве"
+	case t.Muted:
+		return "без напоминаний"
+	default:
+		return "активна"
+	}
+}
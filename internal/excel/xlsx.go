@@ -0,0 +1,276 @@
+// Package excel writes user data to .xlsx workbooks. The workbook writer covers what the
+// exports need - plain sheets with a bold header row - so no spreadsheet library is required.
+package excel
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Column widths in characters
+const (
+	minColumnWidth = 8
+	maxColumnWidth = 60
+)
+
+// dateLayout is how dates are written to cells
+const dateLayout = "2006-01-02 15:04"
+
+// Workbook is an .xlsx workbook built in memory
+type Workbook struct {
+	sheets []*Sheet
+}
+
+// Sheet is one worksheet of a workbook. The first row is the header.
+type Sheet struct {
+	name   string
+	rows   [][]cell
+	widths []int
+}
+
+// cell is a rendered value, numeric cells are stored as numbers so Excel can sum them
+type cell struct {
+	value   string
+	numeric bool
+}
+
+// NewWorkbook creates an empty workbook
+func NewWorkbook() *Workbook {
+	return &Workbook{}
+}
+
+// AddSheet appends a sheet with a header row. Excel limits sheet names to 31 characters.
+func (w *Workbook) AddSheet(name string, header ...string) *Sheet {
+	if utf8.RuneCountInString(name) > 31 {
+		name = string([]rune(name)[:31])
+	}
+	sheet := &Sheet{name: name}
+	values := make([]any, len(header))
+	for i, h := range header {
+		values[i] = h
+	}
+	sheet.AddRow(values...)
+	w.sheets = append(w.sheets, sheet)
+	return sheet
+}
+
+// AddRow appends a row. Strings, numbers, bools, times and *time.Time are supported,
+// other values are written with fmt.Sprint.
+func (s *Sheet) AddRow(values ...any) {
+	row := make([]cell, len(values))
+	for i, v := range values {
+		row[i] = newCell(v)
+		if i >= len(s.widths) {
+			s.widths = append(s.widths, minColumnWidth)
+		}
+		s.widths[i] = max(s.widths[i], min(utf8.RuneCountInString(row[i].value)+2, maxColumnWidth))
+	}
+	s.rows = append(s.rows, row)
+}
+
+// newCell renders a value for a cell
+func newCell(v any) cell {
+	switch v := v.(type) {
+	case nil:
+		return cell{}
+	case string:
+		return cell{value: v}
+	case int:
+		return cell{value: strconv.Itoa(v), numeric: true}
+	case int64:
+		return cell{value: strconv.FormatInt(v, 10), numeric: true}
+	case float64:
+		return cell{value: strconv.FormatFloat(v, 'f', -1, 64), numeric: true}
+	case bool:
+		if v {
+			return cell{value: "да"}
+		}
+		return cell{value: "нет"}
+	case time.Time:
+		if v.IsZero() {
+			return cell{}
+		}
+		return cell{value: v.Format(dateLayout)}
+	case *time.Time:
+		if v == nil {
+			return cell{}
+		}
+		return newCell(*v)
+	default:
+		return cell{value: fmt.Sprint(v)}
+	}
+}
+
+// WriteTo writes the workbook as an .xlsx file
+func (w *Workbook) WriteTo(out io.Writer) (int64, error) {
+	counter := &countingWriter{w: out}
+	zw := zip.NewWriter(counter)
+
+	parts := []part{
+		{"[Content_Types].xml", w.contentTypes()},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", w.workbook()},
+		{"xl/_rels/workbook.xml.rels", w.workbookRels()},
+		{"xl/styles.xml", styles},
+	}
+	for i, sheet := range w.sheets {
+		parts = append(parts, part{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.xml()})
+	}
+
+	for _, p := range parts {
+		fw, err := zw.Create(p.name)
+		if err != nil {
+			return counter.n, fmt.Errorf("failed to add %s: %w", p.name, err)
+		}
+		if _, err := io.WriteString(fw, p.content); err != nil {
+			return counter.n, fmt.Errorf("failed to write %s: %w", p.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return counter.n, fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return counter.n, nil
+}
+
+// part is one file inside the .xlsx zip package
+type part struct {
+	name    string
+	content string
+}
+
+// Package parts that don't depend on the content
+const (
+	xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+	rootRels = xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	// Style 0 is the default, style 1 is the bold header
+	styles = xmlHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`
+)
+
+// contentTypes lists the parts of the package
+func (w *Workbook) contentTypes() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range w.sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+// workbook lists the sheets
+func (w *Workbook) workbook() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range w.sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(sheet.name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+// workbookRels links the workbook to its sheets and styles
+func (w *Workbook) workbookRels() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range w.sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(w.sheets)+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// xml renders the sheet with a frozen, bold header row
+func (s *Sheet) xml() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+		`</sheetView></sheetViews>`)
+
+	if len(s.widths) > 0 {
+		b.WriteString(`<cols>`)
+		for i, width := range s.widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	for r, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			if cell.value == "" {
+				continue
+			}
+			ref := columnName(c) + strconv.Itoa(r+1)
+			style := ""
+			if r == 0 {
+				style = ` s="1"`
+			}
+			if cell.numeric {
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%s</v></c>`, ref, style, cell.value)
+			} else {
+				fmt.Fprintf(&b, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(cell.value))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// columnName converts a zero-based column index to its letters: 0 is A, 26 is AA
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// escape makes s safe for XML text and attributes; characters XML can't hold are replaced
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
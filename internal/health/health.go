@@ -0,0 +1,135 @@
+// Package health serves the liveness and readiness probes used by Kubernetes and
+// other orchestrators to restart a stuck bot or hold traffic until it is ready.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/example/engbot/internal/logging"
+)
+
+const (
+	// checkTimeout bounds a single dependency check
+	checkTimeout = 3 * time.Second
+	// shutdownTimeout bounds how long in-flight probes may take on shutdown
+	shutdownTimeout = 5 * time.Second
+)
+
+// Check reports an error when a dependency is not usable
+type Check func(ctx context.Context) error
+
+// namedCheck is a check with the name shown in the probe response
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Server answers /healthz with the liveness checks and /readyz with the readiness checks
+type Server struct {
+	addr      string
+	liveness  []namedCheck
+	readiness []namedCheck
+}
+
+// response is the JSON body of a probe
+type response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// NewServer creates a probe server listening on addr, like ":8080"
+func NewServer(addr string) *Server {
+	return &Server{addr: addr}
+}
+
+// AddLiveness adds a check to /healthz. A failing liveness check means the process should be restarted.
+func (s *Server) AddLiveness(name string, check Check) {
+	s.liveness = append(s.liveness, namedCheck{name: name, check: check})
+}
+
+// AddReadiness adds a check to /readyz. A failing readiness check means the bot can't serve users yet.
+func (s *Server) AddReadiness(name string, check Check) {
+	s.readiness = append(s.readiness, namedCheck{name: name, check: check})
+}
+
+// Handler returns the HTTP handler serving both probes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", probeHandler(s.liveness))
+	mux.HandleFunc("/readyz", probeHandler(s.readiness))
+	return mux
+}
+
+// Run serves the probes until ctx is cancelled, then shuts the server down
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	slog.Info("serving health checks", "addr", listener.Addr().String())
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("health server stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down health server: %w", err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("health server stopped: %w", err)
+	}
+	return nil
+}
+
+// probeHandler runs the checks and answers 200 if all pass, 503 otherwise
+func probeHandler(checks []namedCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := response{Status: "ok", Checks: make(map[string]string, len(checks))}
+		for _, c := range checks {
+			ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+			err := c.check(ctx)
+			cancel()
+
+			if err != nil {
+				resp.Status = "fail"
+				// Ошибки клиента Telegram содержат URL запроса вместе с токеном
+				resp.Checks[c.name] = logging.Redact(err.Error())
+				slog.Warn("health check failed", "path", r.URL.Path, "check", c.name, "error", err)
+				continue
+			}
+			resp.Checks[c.name] = "ok"
+		}
+
+		status := http.StatusOK
+		if resp.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			slog.Debug("failed to write health response", "error", err)
+		}
+	}
+}
@@ -0,0 +1,1070 @@
+package i18n
+
+// english is the English catalog
+var english = map[string]string{
+	"start.welcome": "👋 Welcome to Spaced Repetition Manager!\n\n" +
+		"I'll help you learn topics efficiently with spaced repetition.\n\n" +
+		"🔹 How it works:\n" +
+		"1. Add a topic to learn\n" +
+		"2. Get review reminders\n" +
+		"3. Mark reviews as done\n" +
+		"4. Track your progress",
+
+	"help.text": "📖 Bot help\n\n" +
+		"🔸 Main commands:\n" +
+		"/start - Start the bot and show the main menu\n" +
+		"/help - Show this help\n" +
+		"/language - Interface language\n\n" +
+		"📚 Topics:\n" +
+		"/add - Add a new topic, /add Topic | 3d - with the first review in 3 days\n" +
+		"/addmany - Add a list of topics, one per line\n" +
+		"/list - List all topics\n" +
+		"/delete - Delete a topic\n" +
+		"/edit <number> - Rename a topic\n" +
+		"/move <number> <parent number> - Make a topic a subtopic of another (0 - back to the top level)\n" +
+		"/merge [number] [number] - Find similar topics or merge the first topic into the second\n" +
+		"/describe <number> <description> - Add a description to a topic\n" +
+		"/search <query> - Find topics and words\n" +
+		"/history <number> - Review history of a topic with notes\n" +
+		"/maintenance <number> - Maintenance reviews of a topic after the cycle\n" +
+		"/topicintervals <number> 1, 2, 4 - Own intervals for a topic (exam 10 - fit into 10 days, off - back to yours)\n" +
+		"/attach <number> - Attach links, photos or documents to a topic\n" +
+		"/archive [number] - Archive a topic keeping its history, or show the archive\n" +
+		"/difficulty <number> <1-5> - Set topic difficulty\n" +
+		"/restartall - Start all reviews over\n" +
+		"/review - Review words with flashcards\n" +
+		"/app - Review topics with flashcards in the Mini App\n" +
+		"/export - Download topics, history, words and statistics as Excel\n" +
+		"/anki - Import Anki decks and export words to Anki\n" +
+		"/decks - Catalog of ready-made word decks to subscribe to\n" +
+		"/plans [number] - Grammar study plans: a topic with the rule, examples and its own schedule in one tap\n" +
+		"/stats [charts|number] - Statistics, charts as pictures or details of a topic\n" +
+		"/goal [number|off] - Daily review goal and day streak\n" +
+		"/quiz - Vocabulary test with buttons, polls, typed answers, in context, synonyms, spelling, word order, listening, by voice, your own sentence or by definition\n" +
+		"/cram <number|category> - Run through every word of a topic before an exam, schedule untouched\n" +
+		"/hard - Your hardest words and a drill on them\n" +
+		"/story [on|off] - A short story with the words to review\n" +
+		"/practice [stop] - A conversation with AI that credits the words you use as reviews\n" +
+		"/wordofday [now|on|off|new|deck number] - Word of the day with examples, pronunciation and a mini quiz\n\n" +
+		"⚙️ Settings:\n" +
+		"/notify on|off - Turn notifications on or off\n" +
+		"/time - Set the notification time\n" +
+		"/channels - Also get reminders by email or webhook\n" +
+		"/skipfirst <0-6> - No reminders for the first reviews\n" +
+		"/overdue - What to do with long overdue reviews\n" +
+		"/load [number|off] - Review forecast and daily limit\n" +
+		"/newwords [number|off] - How many new words a day come up for review\n" +
+		"/direction [topic number] [en|ru|both] - Flashcards from English, from Russian or both ways\n" +
+		"/forecast [chart] - Review calendar for 30 days\n" +
+		"/calendar [reset] - Link to your reviews for Google/Apple Calendar\n" +
+		"/report [now|on|off|<day> <hour>] - Weekly progress report\n" +
+		"/intervals - Choose the review interval schedule\n" +
+		"/news on|off - Receive bot news\n\n" +
+		"🔄 Review intervals:\n" +
+		"The first review is due after 1 day. After each review, rate how easily you " +
+		"remembered the topic, and the bot picks the next interval with the SM-2 algorithm. " +
+		"A topic is learned after 7 successful reviews.\n" +
+		"Hard topics get shorter intervals, easy ones longer.\n\n" +
+		"💡 Tips:\n" +
+		"• Mark your reviews as done regularly\n" +
+		"• Keep an eye on your statistics\n" +
+		"• Pick a convenient notification time",
+
+	"menu.main.text": "🤖 Main menu\n\n" +
+		"Choose a section:\n" +
+		"📚 Topics - add, list and delete topics\n" +
+		"📊 Statistics - your learning progress\n" +
+		"⚙️ Settings - notification settings\n" +
+		"❓ Help - available commands",
+	"menu.main.topics":   "📚 Topics",
+	"menu.main.stats":    "📊 Statistics",
+	"menu.main.settings": "⚙️ Settings",
+	"menu.main.help":     "❓ Help",
+	"menu.back":          "⬅️ Back to menu",
+
+	"command.unknown": "Unknown command. Use /help to see the available commands.",
+
+	"notifications.enabled":  "enabled",
+	"notifications.disabled": "disabled",
+	"digest.enabled":         "enabled",
+	"digest.disabled":        "disabled",
+	"scheduler.sm2":          "SM-2, intervals adapt to your grades",
+	"scheduler.leitner":      "Leitner boxes",
+	"sm2.default":            "default, tuned to you after %d reviews",
+	"sm2.tuned":              "pass from grade %d, EF at least %.1f, first intervals %s days (tuned on %s)",
+
+	"settings.text": "Current settings:\n\n" +
+		"Notifications: %s\n" +
+		"Notification time: %s\n" +
+		"Quiet hours: %s\n" +
+		"No reminders for the first reviews: %d\n" +
+		"Morning digest: %s\n" +
+		"Review algorithm: %s\n" +
+		"SM-2 parameters: %s\n" +
+		"Language: %s\n\n" +
+		"Use these commands to change them:\n" +
+		"/notify on|off - Turn notifications on or off\n" +
+		"/time <hours> - Notification time, several allowed: /time 9, 20\n" +
+		"/skipfirst <N> - No reminders for the first N reviews (0 - remind about all)\n" +
+		"/overdue <remind|reschedule|reset|stall> [days] - What to do with long overdue reviews\n" +
+		"/load <N|off> - At most N reviews a day, the rest move to the following days\n" +
+		"/newwords <N|off> - At most N new words a day, e.g. from large decks\n" +
+		"/direction <en|ru|both> - Flashcards from English to Russian, from Russian to English or both ways\n" +
+		"/report <mon-sun> <0-23>|off - When to send the weekly report\n" +
+		"/intervals - Intensive, standard, relaxed or custom review schedule\n" +
+		"/news on|off - Bot news\n" +
+		"/language - Interface language",
+
+	"notify.usage": "Please specify on or off: /notify <on|off>",
+	"notify.done":  "✅ Notifications %s",
+
+	"time.usage":   "Please specify an hour (0-23) or several separated by commas: /time 9, 20\nQuiet hours are set under «🕒 Notification time».",
+	"time.invalid": "Please specify hours from 0 to 23 separated by commas, at most %d",
+	"time.done":    "✅ Notification time: %s",
+
+	"quiet.off": "off",
+
+	"skipfirst.usage":   "Please specify how many first reviews to skip reminders for (0-6): /skipfirst <N>",
+	"skipfirst.invalid": "Please specify a number from 0 to 6",
+	"skipfirst.all":     "✅ You'll get reminders about all reviews",
+	"skipfirst.from":    "✅ You'll get reminders starting from review %s",
+
+	"language.choose":  "🌐 Choose the interface language:",
+	"language.unknown": "Unknown language. Available: ru, en",
+	"language.done":    "✅ Interface language: English",
+
+	"calendar.name":        "EngBot: reviews",
+	"calendar.summary":     "🔁 %s",
+	"calendar.description": "Repetition %d of %d. Mark it done in the bot.",
+	"calendar.maintenance": "Maintenance review. Mark it done in the bot.",
+
+	"news.usage": "Please specify on or off: /news <on|off>",
+	"news.done":  "✅ Bot news %s",
+
+	"broadcast.footer": "—\nTurn off bot news: /news off",
+
+	"export.empty":   "Nothing to export yet: add a topic with /add",
+	"export.caption": "📤 Your topics, review history, words and statistics",
+
+	"reminder.header":         "🔔 Review reminder:\n\n",
+	"reminder.topic":          "📚 Topic: %s\n",
+	"reminder.maintenance":    "♾ Maintenance review",
+	"reminder.footer":         "\nAfter reviewing, mark the repetition as done with the matching button.",
+	"reminder.button":         "✅ Reviewed \"%s\"",
+	"reminder.count":          "You have %s to review! Open the topic list to start.",
+	"reminder.subject":        "EngBot: time to review",
+	"reminder.test":           "🔔 This is a test reminder. Review reminders will come like this.\n",
+	"reminder.channel_footer": "\nMark the repetitions as done in the bot: https://t.me/%s",
+
+	"command.start":          "🚀 Start the bot",
+	"command.add":            "📝 Add a new topic",
+	"command.addmany":        "📝 Add a list of topics",
+	"command.list":           "📋 All topics",
+	"command.delete":         "🗑 Delete a topic",
+	"command.edit":           "✏️ Rename a topic",
+	"command.difficulty":     "📈 Topic difficulty",
+	"command.restartall":     "🔄 Start the repetitions over",
+	"command.history":        "📜 Topic history",
+	"command.maintenance":    "♾ Maintenance repetitions",
+	"command.topicintervals": "🗓 A schedule of its own for a topic",
+	"command.attach":         "📎 Topic materials",
+	"command.move":           "📂 Put a topic into another",
+	"command.merge":          "🔀 Merge topics",
+	"command.describe":       "📝 Topic description",
+	"command.search":         "🔎 Search topics and words",
+	"command.archive":        "📦 Topic archive",
+	"command.review":         "🃏 Review words",
+	"command.app":            "📱 Topic cards in the mini app",
+	"command.stats":          "📊 Statistics",
+	"command.export":         "📤 Export data to Excel",
+	"command.anki":           "🗂 Anki import and export",
+	"command.decks":          "📚 Deck catalog",
+	"command.plans":          "📘 Ready-made grammar plans",
+	"command.goal":           "🎯 Daily goal and streak",
+	"command.quiz":           "🧠 Word quiz",
+	"command.cram":           "📚 Cramming before an exam",
+	"command.hard":           "🧱 The hardest words",
+	"command.story":          "📖 A story with your words",
+	"command.practice":       "💬 A conversation with your words",
+	"command.wordofday":      "🌅 Word of the day",
+	"command.notify":         "🔔 Notifications on/off",
+	"command.time":           "🕒 Notification time",
+	"command.channels":       "📨 Reminders by email and webhook",
+	"command.skipfirst":      "🔕 No reminders about the first repetitions",
+	"command.overdue":        "⏰ Overdue repetitions",
+	"command.load":           "📈 Daily load",
+	"command.newwords":       "🆕 New words a day",
+	"command.direction":      "🔁 Card direction",
+	"command.forecast":       "🗓 Repetition calendar",
+	"command.calendar":       "📅 Repetitions in Google/Apple Calendar",
+	"command.report":         "📬 Weekly report",
+	"command.intervals":      "🗓 Repetition schedule",
+	"command.language":       "🌐 Язык / Language",
+	"command.news":           "📣 Bot news",
+	"command.help":           "❓ Help",
+
+	"button.cancel":            "❌ Cancel",
+	"button.menu":              "⬅️ Menu",
+	"button.back_to_menu":      "⬅️ Back to menu",
+	"button.main_menu":         "🏠 Main menu",
+	"button.back":              "⬅️ Back",
+	"button.back_to_topics":    "⬅️ Back to topics",
+	"button.back_to_settings":  "⬅️ Back to settings",
+	"button.add_topic":         "📝 Add topic",
+	"button.topic_list":        "📋 Topic list",
+	"button.delete_topic":      "🗑 Delete topic",
+	"button.bulk":              "🧰 Bulk actions",
+	"button.archive":           "📦 Archive",
+	"button.notifications":     "🔔 Notifications",
+	"button.notification_time": "🕒 Notification time",
+	"button.digest":            "📰 Digest",
+	"button.board":             "📌 Daily board",
+	"button.scheduler":         "🗃 Review algorithm",
+	"button.materials":         "📎 Materials",
+	"button.done":              "✅ Done",
+
+	"state.bulk_selecting":    "Mark the topics with the buttons of the «Bulk actions» screen and choose an action.",
+	"state.confirm_broadcast": "Confirm the broadcast with «📣 Send» or cancel it. To change the text, send /broadcast <text> again.",
+	"state.reviewing_word":    "Use the buttons on the card: «🔄 Flip», then rate how easily you remembered the word.",
+	"state.spoken_quiz":       "🎙 Answer this quiz with a voice message or press «🤷 Don't know».",
+	"state.use_menu":          "Please use the menu commands to talk to the bot.",
+
+	"error.create_profile": "❌ Couldn't create your profile. Please try again.",
+	"error.read_profile":   "❌ Couldn't read your profile. Please try again.",
+	"error.no_profile":     "❌ Error: couldn't get your profile",
+
+	"add.empty":             "❌ The topic name can't be empty. Please send the topic name or press \"Cancel\".",
+	"add.exists":            "⚠️ Topic «%s» already exists. Send another name or press \"Cancel\".",
+	"add.failed":            "❌ Couldn't create the topic. Please try again.",
+	"add.done.before":       "✅ Topic ",
+	"add.done.after":        " added!\n",
+	"add.done.first_review": "📅 First review: %s\n\n",
+	"add.done.next": "Now you can:" +
+		"\n1. Add another topic" +
+		"\n2. See the list of all topics" +
+		"\n3. Go back to the main menu",
+
+	"add.title": "📝 New topic",
+	"add.prompt": "\n\nPlease send the name of the topic you want to add.\n" +
+		"For example: \"English grammar\" or \"Sorting algorithms\"\n\n" +
+		"You can send several topics at once, one per line.\n" +
+		"If you have already studied the material, put the first review after «|»: " +
+		"\"Sorting algorithms | 3d\", \"| today\" or \"| tomorrow\".",
+
+	"topic.invalid_number": "Please give a valid topic number",
+	"topic.wrong_number":   "Wrong topic number",
+
+	"topics.none":       "You have no topics yet. Press \"📝 Add topic\" to start.",
+	"topics.title":      "📋 Your topics:\n\n",
+	"topics.cycle_done": " · cycle complete",
+	"topics.subtopics":  "📚 Subtopics: %d, due for review: %d\n",
+	"topics.difficulty": "📈 Difficulty: %s (%d/5)\n",
+	"topics.category":   "📁 Category: %s\n",
+	"topics.archived":   "📦 Archived\n",
+	"topics.muted":      "🔕 Reminders off\n",
+	"topics.due":        "🔄 Due for review!\n",
+	"topics.page":       "\nPage %d of %d",
+	"topics.limit":      "⚠️ Topic limit reached (%d). Delete the topics you don't need to add new ones.",
+
+	"delete.usage":          "Please give the number of the topic to delete: /delete <number>",
+	"delete.done":           "Topic \"%s\" deleted",
+	"delete.done_subtopics": " with its subtopics (%d)",
+
+	"difficulty.usage": "Please give the topic number and a difficulty from 1 (easy) to 5 (hard): /difficulty <number> <1-5>",
+	"difficulty.done":  "✅ Difficulty of \"%s\": %s (%d/5)\nThe next reviews will be scheduled with the difficulty in mind.",
+	"difficulty.1":     "very easy",
+	"difficulty.2":     "easy",
+	"difficulty.3":     "medium",
+	"difficulty.4":     "hard",
+	"difficulty.5":     "very hard",
+
+	"restartall.confirm": "⚠️ Start all reviews over?\n\n" +
+		"All your topics go back to review #1 due tomorrow, " +
+		"and the counters of completed reviews are reset.\n" +
+		"The topics themselves stay, but the review progress can't be restored.",
+	"restartall.button": "🔄 Yes, start over",
+	"restartall.done":   "🔄 Reviews started over.\nTopics reset: %d. The first review is tomorrow.",
+
+	"stats.none":          "You have no statistics yet. Add topics to review!",
+	"stats.title":         "📊 Your statistics\n\n",
+	"stats.topic":         "Topic: %s\nTotal reviews: %d\nCompleted: %d (%.1f%%)\n",
+	"stats.subtopics":     "📚 With subtopics: %d of %d completed (%.1f%%)\n",
+	"stats.details":       "Topic details: /stats <number from /list> or the buttons below",
+	"stats.charts_button": "📈 Charts",
+
+	"callback.unknown": "⚠️ Unknown action",
+
+	"stale":         "This button is out of date.",
+	"stale.topics":  "This button is out of date. Open the topic list again.",
+	"stale.topic":   "This button is out of date. Open the topic again.",
+	"stale.digest":  "This button is out of date. Wait for the next digest.",
+	"stale.history": "This button is out of date. Open the topic history again.",
+	"stale.bulk":    "This button is out of date. Open «Bulk actions» again.",
+	"stale.decks":   "This button is out of date. Open the deck catalog again: /decks",
+	"stale.review":  "This button is out of date. Send /review again.",
+	"stale.quiz":    "This button is out of date. Start the quiz again: /quiz",
+	"stale.time":    "This button is out of date. Open the time settings again.",
+
+	"topics.menu": "📚 Topics\n\n" +
+		"Choose an action:\n" +
+		"📝 Add topic - create a new topic to review\n" +
+		"📋 Topic list - see all your topics\n" +
+		"🗑 Delete topic - delete an existing topic\n" +
+		"🧰 Bulk actions - delete, archive or reset several topics at once",
+	"settings.menu": "⚙️ Settings\n\n" +
+		"Choose what to set up:\n" +
+		"🔔 Notifications - turn notifications on or off\n" +
+		"🕒 Notification time - when to send reminders\n" +
+		"📰 Digest - one morning message instead of separate reminders\n" +
+		"🗃 Review algorithm - SM-2 or the simpler Leitner boxes",
+
+	"notifications.off_button": "🔕 Turn notifications off",
+	"notifications.on_button":  "🔔 Turn notifications on",
+	"notifications.title":      "🔔 Notification settings\n\nCurrent status: %s\n\nChoose an action:",
+
+	"delete.none": "❌ You have no topics to delete yet.\n\nAdd topics first with the \"📝 Add topic\" button",
+	"delete.menu": "🗑 Deleting a topic\n\n" +
+		"To delete a topic, send the command:\n" +
+		"/delete <number>\n\n" +
+		"⚠️ Deleting erases the review history and statistics of the topic together with its subtopics. " +
+		"To keep them, move the topic to the archive with the button below.\n\n" +
+		"Your topics:\n",
+
+	"repetition.already_done": "This review is already marked as done.",
+	"topic.not_found":         "Topic not found. It may have been deleted.",
+
+	"grade.topic":          "📚 Topic ",
+	"grade.prompt":         "\n\nHow easily did you recall the material?",
+	"rating.forgot":        "❌ Forgot",
+	"rating.hard":          "😓 Hard",
+	"rating.good":          "🙂 Good",
+	"rating.easy":          "😎 Easy",
+	"complete.next":        "✅ Great! Review done.\nThe next review is scheduled for %s",
+	"complete.maintenance": "✅ Great! The topic has stuck.\n♾ Next maintenance review - %s.",
+	"complete.again":       "🔁 No worries! We'll review this topic again on %s.",
+
+	"note.button":   "📝 Add a note",
+	"note.prompt":   "📝 Write a short note for this review.\nFor example: \"forgot the exceptions\" or \"getting easier\"",
+	"note.empty":    "❌ The note can't be empty. Write some text or press \"Cancel\".",
+	"note.too_long": "❌ The note is too long. At most %d characters.",
+	"note.saved":    "✅ Note saved. You can see it in the topic history: /history <number>",
+
+	"history.usage":   "Please give the topic number: /history <number>",
+	"history.title":   "📜 History of \"%s\"\n\n",
+	"history.done":    "✅ %s - %s\n",
+	"history.planned": "⏳ %s - scheduled for %s\n",
+
+	"completion.title":   "🎉 Congratulations! You've finished all reviews of \"%s\"!\n\n",
+	"completion.took":    "📅 Learning took: %d %s (%s to %s)\n",
+	"completion.reviews": "🔄 Reviews done: %d\n",
+	"completion.share":   "\nShare your result:\n«I've mastered \"%s\" with spaced repetition: %d %s in %d %s! 💪»",
+
+	"plural.day":    "day|days|days",
+	"plural.review": "review|reviews|reviews",
+
+	"add.start": "Please enter the name of the new topic to review.\n" +
+		"For example: \"Sorting algorithms\" or \"Design patterns\"",
+
+	"similar.ask":          "🤔 Looks like «%s», create «%s» anyway?",
+	"similar.create":       "✅ Create anyway",
+	"similar.keep":         "↩️ Keep «%s»",
+	"similar.kept":         "👌 No new topic created, keep reviewing «%s».",
+	"similar.stale_create": "This action is no longer relevant. Press \"📝 Add topic\" to start over.",
+	"stale.action":         "This action is no longer relevant.",
+
+	"cancel.done": "Action cancelled. Choose another command:",
+
+	"error.transient":    "⏳ The service is busy right now. Please try again in a few seconds.",
+	"error.topic_exists": "⚠️ This topic already exists. Find similar topics and merge them: /merge",
+	"error.not_found":    "❌ Record not found. It may have been deleted already. Open the topic list to see the current data.",
+	"error.unknown":      "❌ Something went wrong. Please try again later.",
+
+	"first_review.no_name": "Put the topic name before «|», for example: /add Topic | 3d",
+	"first_review.unknown": "I didn't understand «%s». Use today, tomorrow or a number of days, for example: /add Topic | 3d",
+	"first_review.too_far": "The first review can be put off by at most %d days.",
+	"day.today":            "today",
+	"day.tomorrow":         "tomorrow",
+
+	"addmany.prompt":       "📝 Send a list of topics, one per line (up to %d):\n\nPresent Simple\nPresent Continuous\nPast Simple",
+	"addmany.empty":        "The list is empty. Send topic names, one per line.",
+	"addmany.too_many":     "You can add at most %d topics at once, and the list has %d.",
+	"addmany.first_review": "The first review date can only be set for a single topic: /add Topic | 3d",
+	"addmany.created":      "✅ Topics created: %d",
+	"addmany.duplicates":   "\n⏭ Skipped, these topics already exist: %d\n",
+	"addmany.over_limit":   "\n⚠️ Not added because of the topic limit (%d): %d",
+
+	"inline.not_found": "Word not found. Open the bot",
+
+	"app.disabled":    "The mini app isn't set up. Review topics with the buttons in the reminders.",
+	"app.nothing_due": "🃏 Nothing to review right now. The button below opens the cards when reviews are due.",
+	"app.due":         "🃏 Topics to review: %d. Open the cards - your grades go into your review schedule just like from the chat.",
+	"app.button":      "🃏 Open the cards",
+
+	"pronunciation.button":    "🔊 Pronunciation",
+	"pronunciation.disabled":  "Word pronunciation is turned off.",
+	"pronunciation.failed":    "😔 Couldn't pronounce the word. Try again a bit later.",
+	"pronunciation.forbidden": "Telegram doesn't let me send you voice messages: allow them in your privacy settings («Voice messages»).",
+	"error.start":             "Profile not found. Send /start.",
+
+	"hard.none":      "💪 No hard words: you haven't forgotten a word in a review yet (/review).",
+	"hard.drill":     "🎯 Drill now",
+	"hard.title":     "🧱 The hardest words\n\n",
+	"hard.forgotten": ", forgotten last time",
+	"hard.footer": "\nThe lower the EF, the more often the word is forgotten. The drill is a button test on these words only, " +
+		"its answers don't change the review schedule.",
+
+	"charts.completion":   "✅ Share of reviews done per week over %d weeks: how many of the reviews due that week were done.",
+	"charts.reviews":      "📅 Reviews per day over %d days, %d in total.",
+	"charts.goal":         " The dashed line is the daily goal (%d).",
+	"charts.topics":       "📚 Reviews done per topic.",
+	"charts.topics_first": " Showing the first %d topics.",
+	"charts.no_data":      "Not enough data for charts yet. Review topics for a few days and they'll show up.",
+
+	"sentence.no_word":       "\n🔍 The sentence doesn't have this word.",
+	"sentence.wrong_meaning": "\n🔍 The word is used in another meaning.",
+	"sentence.no_mistakes":   "\n👍 No mistakes.",
+	"sentence.remove":        " → remove",
+	"sentence.corrected":     "\n📝 Correct: ",
+
+	"calendar.disabled": "The calendar isn't set up: the administrator needs to set API_ADDR and API_PUBLIC_URL.",
+	"calendar.title":    "📅 Your review calendar",
+	"calendar.reset":    "🔑 The old link no longer works. The new calendar link",
+	"calendar.usage":    "Use: /calendar - the calendar link, /calendar reset - replace the link",
+	"calendar.link": ":\n%s\n\n" +
+		"Google Calendar: «Other calendars» → «+» → «From URL».\n" +
+		"Apple Calendar: «File» → «New Calendar Subscription», or on iPhone «Settings» → «Calendar» → «Accounts» → «Add Account» → «Other» → «Add Subscribed Calendar».\n\n" +
+		"The calendar updates itself, but Google picks up changes with a delay of up to a day. " +
+		"The link is personal: if someone else got it, replace it with /calendar reset.",
+
+	"move.usage": "Give the topic and the topic to put it into: /move <number> <parent number>\n" +
+		"For example, lessons into a module and modules into a course. Back to the top level: /move <number> 0",
+	"move.into_itself": "❌ A topic can't go into itself or one of its subtopics.",
+	"move.too_deep":    "❌ Topics nest at most %d levels deep, like course → module → lesson.",
+	"move.top_level":   "📂 Topic \"%s\" is now at the top level.",
+	"move.done":        "📂 Topic \"%s\" is now a subtopic of \"%s\". The topic numbers in /list may have shifted.",
+
+	"quiz.finished":   "This quiz is already over. Start a new one: /quiz",
+	"quiz.question":   "🧠 Question %d of %d\n\n",
+	"quiz.word_order": "🔀 Put together the sentence with «%s» (%s):\n\n%s",
+	"quiz.spelling":   "🔤 Spell the word letter by letter: «%s»\n\n%s",
+	"quiz.skip":       "🤷 Don't know",
+	"quiz.erase":      "⌫ Erase",
+	"quiz.stop":       "⏹ Stop the quiz",
+
+	"scheduler.sm2_on": "🧠 Review algorithm: SM-2. The intervals stretch and shrink with your grades again.",
+	"scheduler.leitner_on": "🗃 Review algorithm: Leitner boxes.\n\n" +
+		"Each topic sits in one of %d boxes. Recall a topic and it moves to the next box, " +
+		"get it wrong and it goes back to the first one. Topics from the boxes are reviewed after %s days.\n" +
+		"How many topics are in each box is shown in /stats.",
+	"scheduler.boxes":      "🗃 Leitner boxes:\n",
+	"scheduler.to_sm2":     "🧠 Switch to SM-2",
+	"scheduler.to_leitner": "🗃 Switch to Leitner boxes",
+
+	"undo.button":       "↩️ Undo",
+	"undo.hint":         "Changed your mind? Press «↩️ Undo» within %d min.",
+	"undo.expired":      "⌛ It's too late to undo: the undo time is over.",
+	"undo.exists":       "Couldn't restore: a topic with this name has been created again. Rename it and press «↩️ Undo» once more.",
+	"undo.restored":     "↩️ Deletion undone, topics restored: %d. The review history and statistics are in place.",
+	"undo.not_archived": "Topic \"%s\" is no longer in the archive.",
+	"undo.unarchived":   "↩️ Archiving undone, topic \"%s\" is back in the list.",
+
+	"cram.usage": "📚 Cramming before an exam: every word of the topic in a row, even the ones not due yet.\n\n" +
+		"Use: /cram <topic number> or /cram <category>\n" +
+		"The answers don't change the word review schedule, and the result is saved like a quiz.",
+	"cram.not_found": "Found no topic or category «%s».\n\n",
+	"cram.no_words":  "No words to cram (%s). Add them from the decks (/decks) or by importing from Anki (/anki).",
+	"cram.started":   "📚 Cramming: %s, %d %s. The answers don't change the review schedule.\n\n%s",
+	"cram.topic":     "topic «%s»",
+	"cram.category":  "category «%s»",
+	"plural.word":    "word|words|words",
+
+	"board.off": "📌 The daily board is off. Reminders come as separate messages again.",
+	"board.on": "📌 The daily board is on. Instead of new reminders I update one pinned message: " +
+		"what's left to review today and what's done.",
+	"board.title":      "📌 Reviews for %s\n\n",
+	"board.all_done":   "🎉 All done for today!",
+	"board.left":       "⏳ Left: %d\n",
+	"board.done":       "\nDone today: %d\n",
+	"board.off_button": "📌 Turn the daily board off",
+	"board.on_button":  "📌 Turn the daily board on",
+
+	"newwords.usage": "Use: /newwords - how many new words are waiting, /newwords <0-%d> - new words per day, " +
+		"/newwords off - all new words at once",
+	"newwords.all":               "✅ New words come up for review all at once.",
+	"newwords.none":              "✅ No new words for now, only reviews of the ones you know.",
+	"newwords.limit":             "✅ At most %d %s a day.",
+	"plural.new_word":            "new word|new words|new words",
+	"newwords.title":             "🆕 New words\n\n",
+	"newwords.per_day_unlimited": "Per day: no limit\n",
+	"newwords.per_day":           "Per day: %d\n",
+	"newwords.status":            "Today so far: %d\nWaiting their turn: %d\n\n",
+	"newwords.footer": "Words from big decks come up for review little by little, mixed with reviews of the ones you know. " +
+		"Change: /newwords <0-%d> or /newwords off",
+
+	"kind.phrase":         "a phrase",
+	"kind.collocation":    "a collocation",
+	"kind.word":           "a word",
+	"kind.to_phrase":      "🧩 It's a phrase",
+	"kind.to_collocation": "🔗 It's a collocation",
+	"kind.single_word":    "«%s» is a single word, it can't be a phrase or a collocation.",
+	"kind.switched":       "✅ «%s» is now %s and counts separately in the statistics: /stats",
+	"kind.words":          "🔤 Words",
+	"kind.phrases":        "🧩 Phrases",
+	"kind.collocations":   "🔗 Collocations",
+	"kind.stats":          "%s: %d, learning %d, learned %d\n",
+
+	"topics.legend":        "🔄 review · 📊 history · ✏️ edit · 📦 archive",
+	"next_review.overdue":  "overdue by %d %s",
+	"next_review.today":    "review today",
+	"next_review.tomorrow": "review tomorrow",
+	"next_review.in":       "review in %d %s",
+
+	"forecast.chart":      "📊 Reviews for %d days from %s: %d in total. The first bar is today together with the overdue ones.",
+	"forecast.usage":      "Use: /forecast - the review calendar, /forecast chart - a chart picture",
+	"forecast.title":      "🗓 Reviews for %d days\n\n",
+	"forecast.weekdays":   "Mo Tu We Th Fr Sa Su",
+	"forecast.legend":     "\n%s none  %s few  %s medium  %s many  %s peak",
+	"forecast.over_limit": " or over the limit (%d)",
+	"forecast.total":      "\n\nTotal: %d, today: %d (with the overdue ones)\n",
+	"forecast.busiest":    "The busiest days:\n",
+	"forecast.footer":     "\nChart picture: /forecast chart, daily limit: /load",
+
+	"load.usage":             "Use: /load - the review forecast, /load <1-%d> - at most this many reviews a day, /load off - no limit",
+	"load.off":               "✅ The limit is off, reviews are no longer moved.",
+	"load.limit":             "✅ At most %d %s a day, the extra ones move to the next days.",
+	"plural.review_genitive": "review|reviews|reviews",
+	"load.moved":             "\nMoved now: %d.",
+	"load.title":             "📈 Review forecast for %d days\n\n",
+	"load.current":           "\nLimit: %d a day. Change: /load <1-%d>, remove: /load off",
+	"load.hint":              "\nLimit the load: /load <1-%d> - the extra reviews move to the next days",
+	"load.today":             "Today",
+	"load.total":             "\nTotal: %d\n",
+
+	"story.unavailable": "📖 Stories aren't available: the bot administrator hasn't connected text generation.",
+	"story.usage":       "Use: /story - a story with your words, /story on|off - send a story every day",
+	"story.off":         "📖 The daily story is off. You can get a story any time: /story",
+	"story.on":          "📖 Every day at %d:00 I'll send a short story with the words that are due for review.",
+	"story.no_words":    "📖 A story needs words. Add them from the ready-made decks (/decks) or by importing from Anki (/anki).",
+	"story.failed":      "😔 Couldn't write a story. Try again a bit later: /story",
+	"story.daily_title": "Story of the day",
+	"story.title":       "A story with your words",
+	"story.translation": "\n\n🇷🇺 Translation (tap to open):\n",
+	"story.more":        "\n\nOne more story: /story",
+
+	"archive.usage":   "Wrong topic number. Use: /archive <number>",
+	"archive.already": "Topic \"%s\" is already in the archive.",
+	"archive.done": "📦 Topic \"%s\" moved to the archive. The review history is kept, there will be no reminders for it.\n" +
+		"You can bring the topic back in «📦 Archive» or with /archive.",
+	"archive.restored":  "♻️ Topic \"%s\" is back from the archive. Reviews continue where you left off.",
+	"archive.subtopics": "\nTogether with its subtopics: %d.",
+	"archive.title":     "📦 Topic archive\n\n",
+	"archive.empty":     "The archive has no topics yet.\n",
+	"archive.hint": "\nTo archive a topic without losing its history, use /archive <number> " +
+		"(the number from the topic list) or the «📦 Archive» button in the delete section.",
+	"archive.restore_button": "♻️ Restore \"%s\"",
+	"archive.button":         "📦 Archive \"%s\"",
+
+	"direction.usage": "Use: /direction en|ru|both - the flashcard direction, " +
+		"/direction <topic number> en|ru|both|default - a topic's own direction",
+	"direction.wrong_topic":   "Wrong topic number. ",
+	"direction.changed":       "✅ Flashcards: %s.",
+	"direction.topic_changed": "✅ Flashcards of topic \"%s\": %s.",
+	"direction.added":         " New Russian to English cards: %d, they are already waiting in /review.",
+	"direction.reverse":       "🇷🇺→🇬🇧 from Russian to English",
+	"direction.both":          "🔁 both ways",
+	"direction.forward":       "🇬🇧→🇷🇺 from English to Russian",
+	"direction.title":         "🔁 Flashcard direction\n\nDefault: %s\n",
+	"direction.own":           "\nTopics with their own direction:\n",
+	"direction.hint": "\nRussian to English cards have their own review schedule: a word that is easy to recognize " +
+		"can be hard to recall yourself.\n\n",
+
+	"maintenance.usage":  "Wrong topic number. Use: /maintenance <number>",
+	"button.topic_stats": "📊 Topic statistics",
+	"maintenance.on": "♾ Maintenance reviews of topic \"%s\" are on: after review %d the topic won't end, " +
+		"it will come back in %d, then every %d days.",
+	"maintenance.off": "Maintenance reviews of topic \"%s\" are off: the topic ends after review %d.",
+	"maintenance.none": "♾ No topic has maintenance reviews yet.\n\n" +
+		"A topic ends after a cycle of %d reviews. To have it come back every few months, " +
+		"use /maintenance <number>.",
+	"maintenance.list":        "♾ Topics with maintenance reviews:\n",
+	"maintenance.toggle_hint": "\nTurn on or off: /maintenance <number>",
+	"maintenance.enable":      "♾ Turn on maintenance reviews",
+	"maintenance.disable":     "♾ Turn off maintenance reviews",
+
+	"digest.all_done": "🎉 Everything for today is reviewed!",
+	"digest.off":      "📰 The digest is off. Reminders come as separate messages again.",
+	"digest.on": "📰 The digest is on. Every day at %s you'll get one message " +
+		"with the topics and words to review and your streak.",
+	"digest.title":        "☀️ Your digest for today\n\n",
+	"digest.topics":       "📚 Topics to review: %d\n",
+	"digest.words":        "🃏 Words to review: %d\n",
+	"digest.topic":        "%d. %s - review %d\n",
+	"digest.page":         "\nPage %d of %d",
+	"digest.done_button":  "✅ Reviewed topic \"%s\"",
+	"digest.words_button": "🃏 Review words (%d)",
+	"digest.disable":      "📰 Turn off the digest",
+	"digest.enable":       "📰 Turn on the digest",
+
+	"intervals.intensive": "⚡ Intensive",
+	"intervals.standard":  "📅 Standard",
+	"intervals.relaxed":   "🌿 Relaxed",
+	"intervals.usage": "Give the intervals in days separated by commas, for example: /intervals 1, 3, 7, 14, 30\n" +
+		"At most %d values from 1 to %d, each no less than the one before.",
+	"intervals.ask":           "✏️ Send your intervals in days separated by commas, for example: 1, 3, 7, 14, 30",
+	"stale.intervals":         "This button is out of date. Open /intervals again.",
+	"intervals.retry":         "❌ Couldn't read the intervals. Try again or press \"Cancel\".\n\n",
+	"intervals.invalid":       "❌ Couldn't read the intervals.\n\n",
+	"intervals.saved":         "✅ Review schedule: %s\nIntervals, days: %s\n\nThe new schedule applies to the next reviews.",
+	"intervals.title":         "🗓 Review schedule\n\n",
+	"intervals.current":       "Now: %s (%s)\n\n",
+	"intervals.hint":          "\nYour ratings during reviews stretch or shrink these intervals.\n",
+	"intervals.custom":        "✏️ Custom",
+	"intervals.custom_button": "✏️ Custom schedule",
+
+	"edit.choose":       "✏️ Choose the topic you want to rename:",
+	"edit.usage":        "Wrong topic number. Use: /edit <number>",
+	"edit.ask":          "✏️ Current name: \"%s\"\n\nSend the new topic name:",
+	"edit.empty":        "❌ The topic name can't be empty. Send a new name or press \"Cancel\".",
+	"edit.taken":        "❌ You already have topic \"%s\". Think of another name or press \"Cancel\".",
+	"edit.done":         "✅ Topic \"%s\" renamed to \"%s\".",
+	"edit.button":       "✏️ Edit \"%s\"",
+	"describe.usage":    "Give the topic and description: /describe <number> <description>\nRemove the description: /describe <number> -",
+	"describe.too_long": "The description is too long. %d characters at most.",
+	"describe.saved":    "📝 Description of topic \"%s\" saved.",
+	"describe.removed":  "📝 Description of topic \"%s\" removed.",
+
+	"goal.usage": "Use: /goal <1-%d> - reviews a day, /goal off - no goal",
+	"goal.off":   "🎯 The daily goal is off. One review a day is enough to keep the streak going.",
+	"goal.set": "🎯 Daily goal: %d %s a day. Word cards (/review) and topic reviews count. " +
+		"The streak grows on the days the goal is met.",
+	"goal.title":     "🎯 Daily goal\n\n",
+	"goal.hint":      "\nChange the goal: /goal <1-%d>, turn off: /goal off",
+	"goal.protected": "🛡 Nothing to review today - the streak won't break.\n",
+	"goal.no_goal":   "📅 Today: %d %s (no daily goal set: /goal)\n",
+	"goal.progress":  "📅 Today: %d of %d %s\n",
+	"goal.met":       "✅ Today's goal is met!\n",
+	"goal.streak":    "%s Streak: %d %s in a row\n",
+
+	"practice.unavailable":  "💬 Conversation practice isn't available: the bot administrator hasn't connected a language model.",
+	"practice.usage":        "Use: /practice - a conversation with your words, /practice stop - end the conversation",
+	"practice.no_words":     "💬 A conversation needs words. Add them from the ready-made decks (/decks) or by importing from Anki (/anki).",
+	"practice.start_failed": "😔 Couldn't start a conversation. Try again a bit later: /practice",
+	"practice.title":        "Conversation practice",
+	"practice.intro":        "\n\nAnswer in English and try to use the words:\n",
+	"practice.credit":       "Each word used correctly counts as a review.\n\n",
+	"practice.text_only":    "💬 Answer with text in English or press «🏁 Finish».",
+	"practice.reply_failed": "😔 Couldn't reply. Send the message again or press «🏁 Finish».",
+	"practice.credited":     "✅ Counted: ",
+	"practice.left":         "\n\n🔤 Left: ",
+	"practice.none":         "💬 There is no conversation. Start a new one: /practice",
+	"practice.over":         "The conversation is over",
+	"practice.used":         "\nWords used: %d of %d",
+	"practice.used_words":   "\n✅ Counted as reviews: ",
+	"practice.missed":       "\n🔁 Not used: %s - they stay in /review",
+	"practice.more":         "\n\nOne more conversation: /practice",
+	"practice.stop":         "🏁 Finish",
+
+	"channels.email":           "📧 Email",
+	"channels.webhook":         "🔗 Webhook",
+	"channels.none":            "No other reminder channels are set up, reminders only come to Telegram.",
+	"channels.unknown":         "There is no channel %q. Available: %s",
+	"channels.off":             "%s: reminders no longer come here.",
+	"channels.already_off":     "%s: the channel is already off.",
+	"channels.invalid_address": "%s: invalid address %s",
+	"channels.on":              "✅ %s: reminders will also come to %s.\nCheck the delivery with /channels test.",
+	"channels.title":           "📨 Review reminders come to Telegram and to these channels:\n\n",
+	"channels.disabled":        "off",
+	"channels.usage":           "Use:\n",
+	"channels.usage_email":     "/channels email <address> - send reminders by email\n",
+	"channels.usage_webhook":   "/channels webhook <https-URL> - send reminders as a JSON POST request\n",
+	"channels.usage_other":     "/channels %s <address>\n",
+	"channels.usage_off":       "/channels <channel> off - turn the channel off\n",
+	"channels.usage_test":      "/channels test - send a test reminder",
+	"channels.no_channels":     "No channels are on. Add a channel with /channels.",
+	"channels.test_title":      "📨 Test reminder:\n\n",
+	"channels.test_disabled":   "%s: the channel was turned off by the administrator\n",
+	"channels.test_failed":     "%s: ❌ not delivered (%v)\n",
+	"channels.test_sent":       "%s: ✅ sent to %s\n",
+
+	"attach.usage": "Please give the topic number: /attach <number>",
+	"attach.start": "📎 Materials of topic \"%s\": %d of %d\n\n" +
+		"Send a link, photo or document - they will come with the reminders about this topic. " +
+		"You can send several, then press \"Done\".",
+	"attach.show":  "👀 Show materials",
+	"attach.clear": "🗑 Delete all materials",
+	"attach.limit": "❌ A topic can have at most %d materials. " +
+		"Delete the old ones: /attach <number> → \"🗑 Delete all materials\".",
+	"attach.added":   "✅ Attached to topic \"%s\" (%d of %d). Send more or press \"Done\".",
+	"attach.invalid": "Send a link (http:// or https://), a photo or a document, or press \"Done\".",
+	"attach.empty":   "Nothing is attached to this topic yet. Add: /attach <number>",
+	"attach.header":  "📎 Materials of topic \"%s\"",
+	"attach.cleared": "🗑 Materials deleted: %d. Send new ones or press \"Done\".",
+	"attach.done":    "✅ Done. The materials will come with the topic reminder, under the \"📎 Materials\" button.",
+
+	"time.last_hour": "At least one reminder time has to stay. To turn reminders off completely, use /notify off.",
+	"time.too_many":  "You can choose at most %d reminders a day.",
+	"time.title":     "🕒 Reminder times\n\n",
+	"time.hours":     "Reminders: %s\n",
+	"time.quiet":     "Quiet hours: %s\n\n",
+	"time.hint": "Tap the hours to add or remove a reminder (at most %d a day). " +
+		"🌙 marks the quiet hours - no reminders come at that time.",
+	"time.quiet_button":     "🌙 Quiet hours",
+	"time.quiet_off_button": "🔔 No quiet hours",
+	"time.quiet_start":      "🌙 Quiet hours\n\nFrom what hour should reminders stop?",
+	"time.quiet_end":        "🌙 Quiet hours start at %d:00.\n\nUntil what hour should reminders stop?",
+
+	"merge.usage": "Say which topic to merge into which: /merge <number> <number>\n" +
+		"The first topic goes into the second and is deleted.",
+	"merge.same":   "Give two different topics.",
+	"merge.button": "🔀 Merge",
+	"merge.none": "✅ No similar topics found.\n\n" +
+		"Merge any two topics: /merge <number> <number> - the first topic goes into the second.",
+	"merge.suggest": "🔀 These topics look like duplicates of each other:\n\n%s" +
+		"\nPress a button to merge a pair, or use /merge <number> <number>.",
+	"stale.merge":     "This button is out of date. Open /merge again.",
+	"merge.not_found": "Topic not found. It may have been deleted or merged already.",
+	"merge.done":      "🔀 Topic \"%s\" merged into \"%s\". The topic numbers in /list may have shifted.",
+	"merge.subtopic":  "❌ A topic can't be merged with its subtopic. Move the subtopic out first: /move <number> 0",
+	"merge.too_deep":  "❌ After merging, the subtopics would be deeper than %d levels.",
+	"merge.confirm": "🔀 Merge topic \"%s\" into \"%s\"?\n\n" +
+		"Words, review history, statistics, materials and subtopics move to \"%s\", " +
+		"and topic \"%s\" is deleted.",
+
+	"plans.usage":          "Use: /plans - ready-made grammar plans, /plans <number> - a plan's description",
+	"plans.not_found":      "Plan not found. Open the list again: /plans",
+	"plans.exists":         "Topic «%s» is already in your list: /list",
+	"plans.created_prefix": "✅ Topic ",
+	"plans.created_suffix": " added from a ready-made plan.\n",
+	"plans.first_review":   "📅 First review: %s\n",
+	"plans.schedule":       "🗓 Schedule, days: %s\n",
+	"plans.examples":       "📝 Examples in the topic materials: %d\n\n",
+	"plans.examples_hint":  "The examples will come with the topic reminder, under the \"📎 Materials\" button.",
+	"plans.back":           "⬅️ Back to plans",
+	"plans.title":          "📘 Ready-made grammar plans\n\n",
+	"plans.hint": "\nA plan creates a topic with the rule, examples and its own review schedule: " +
+		"the harder the topic, the more often it comes back in the first weeks.",
+	"plans.preview":          "📖 %s\nDifficulty: %s\n\n%s\n\nExamples:\n",
+	"plans.preview_schedule": "\n🗓 Review schedule, days: %s",
+	"plans.create":           "➕ Create topic",
+
+	"stale.stats":                  "This button is out of date. Open /stats again.",
+	"topic_stats.intervals_button": "🗓 Own review schedule",
+	"topic_stats.all_button":       "📊 All statistics",
+	"topic_stats.title":            "📊 Topic ",
+	"topic_stats.no_reviews":       "No reviews yet.",
+	"topic_stats.maintenance":      "♾ Cycle complete, next maintenance review: %s\n",
+	"topic_stats.current":          "🔄 Current review: #%d of %d\n",
+	"topic_stats.overdue":          " (overdue)",
+	"topic_stats.next":             "📅 Next review: %s\n",
+	"topic_stats.finished":         "🎉 All reviews of the topic are done\n",
+	"topic_stats.lateness":         "⏱ Average delay: %s\n",
+	"topic_stats.own_intervals":    "🗓 Own schedule: %s days\n",
+	"topic_stats.archived":         "📦 The topic is archived\n",
+	"topic_stats.stalled":          "⏸ The topic is stalled, no reminders\n",
+	"topic_stats.muted":            "🔕 Reminders for the topic are off\n",
+	"topic_stats.finish":           "🏁 The cycle of %d reviews will end around %s\n",
+	"topic_stats.reviews":          "\n🗓 Reviews:",
+	"topic_stats.done":             "\n✅ #%d - %s, %s",
+	"topic_stats.planned":          "\n⏳ #%d - scheduled for %s",
+	"topic_stats.on_time":          "on time",
+	"topic_stats.late":             "%d %s late",
+
+	"report.days": "on Sundays|on Mondays|on Tuesdays|on Wednesdays|on Thursdays|on Fridays|on Saturdays",
+	"report.usage": "Use: /report - report settings, /report now - the report of the last week, " +
+		"/report on|off - send the report every week, /report <mon-sun> <0-23> - the day of the week and hour of the report",
+	"report.saved": "✅ Saved.\n\n",
+	"report.title": "📬 Weekly report\n\n",
+	"report.on": "Comes %s at %02d:00: the week's reviews, words learned, the day streak, " +
+		"the best and lagging topics and a comparison with the week before.\n\nTurn off: /report off",
+	"report.off":        "Off. Turn on: /report on",
+	"report.hint":       "\nDay and hour: /report <mon-sun> <0-23>, for example /report sun 19\nThe report right now: /report now",
+	"report.settings":   "\n\nReport settings: /report",
+	"report.week":       "📬 Report for the week %s - %s\n\n",
+	"report.completed":  "✅ Topic reviews: %d %s\n",
+	"report.reviews":    "🎯 All reviews (topics and cards): %d %s\n",
+	"report.learned":    "📚 Words learned: %d %s\n",
+	"report.streak":     "🔥 Streak: %d %s\n",
+	"report.best":       "\n🏆 Best topics:\n",
+	"report.worst":      "\n🐢 Lagging behind:\n",
+	"report.topic":      "• %s - %d of %d\n",
+	"report.delta_hint": "\nIn parentheses - the difference from the week before.",
+
+	"topic_intervals.usage": "Give the intervals in days separated by commas, for example 1, 2, 4, " +
+		"«exam 10» to fit your schedule into 10 days, or «off» to go back to the common schedule.\n" +
+		"At most %d values from 1 to %d, each no less than the one before.",
+	"topic_intervals.wrong_number":       "Wrong topic number. Use: /topicintervals <number> 1, 2, 4",
+	"topic_intervals.ask":                "\n\n✏️ Send the new schedule.",
+	"topic_intervals.exam":               "Give the number of days until the exam from 1 to %d, for example: exam 10",
+	"topic_intervals.invalid":            "Couldn't read the intervals.\n\n",
+	"topic_intervals.reset_prefix":       "✅ Topic ",
+	"topic_intervals.reset_suffix":       " is reviewed on the common schedule from /intervals again.",
+	"topic_intervals.saved_prefix":       "✅ Own schedule of topic ",
+	"topic_intervals.saved_suffix":       ": %s days.",
+	"topic_intervals.moved":              "\n📅 Review #%d moved to %s.",
+	"topic_intervals.title":              "🗓 Review schedule of topic ",
+	"topic_intervals.current_difficulty": "Now: the common schedule from /intervals adjusted for difficulty «%s» (%s days)\n\n",
+	"topic_intervals.current_common":     "Now: the common schedule from /intervals (%s days)\n\n",
+	"topic_intervals.current_own":        "Now: own schedule (%s days)\n\n",
+	"topic_intervals.item":               "%d. %s: %s days\n",
+	"topic_intervals.none": "🗓 All topics are reviewed on the common schedule from /intervals.\n\n" +
+		"To give a topic its own, for example before an exam, use /topicintervals <number> 1, 2, 4 " +
+		"or /topicintervals <number> exam 10.",
+	"topic_intervals.list":      "🗓 Topics with their own review schedule:\n",
+	"topic_intervals.list_hint": "\nChange: /topicintervals <number> <intervals>, back to common: /topicintervals <number> off",
+
+	"decks.usage":     "Use: /decks - the deck catalog, /decks <number> - the words of a deck",
+	"decks.not_found": "Deck not found. Open the catalog again: /decks",
+	"decks.subscribed": "✅ You are subscribed to the deck \"%s\".\n\n🃏 Added to review: %d %s, they are in the topic \"%s\". " +
+		"New words come a few a day (/newwords). Start right now: /review",
+	"decks.subscribed_none": "✅ You are subscribed to the deck \"%s\". It has no new words yet, all of them are already in review: /review",
+	"decks.admins_only":     "Only admins can publish decks.",
+	"decks.publish_usage":   "Use: /decks publish <topic number> [description]",
+	"decks.empty_topic":     "The topic \"%s\" has no words. Add them, for example by importing from Anki: /anki",
+	"decks.published":       "📚 Deck \"%s\" published.\nWords added: %d, in the deck: %d.",
+	"decks.title":           "📚 Deck catalog\n\n",
+	"decks.none":            "No decks yet.",
+	"decks.hint": "\nOpen a deck to see its words and subscribe. Once you subscribe, the deck's words " +
+		"show up in a topic of their own and in flashcard review (/review).",
+	"decks.more":      "... and %d more\n",
+	"decks.subscribe": "➕ Subscribe",
+	"decks.add_new":   "🔄 Add new words",
+	"decks.back":      "⬅️ Back to decks",
+
+	"overdue.remind":         "remind every day",
+	"overdue.reschedule":     "move the review to a new date",
+	"overdue.reset":          "start the topic over from review #1",
+	"overdue.stall":          "stop the topic and ask once a week whether to bring it back or archive it",
+	"overdue.usage":          "Use: /overdue <remind|reschedule|reset|stall> [days, 1-%d]",
+	"overdue.saved":          "✅ Saved.\n\n",
+	"overdue.title":          "⏰ Overdue reviews\n\n",
+	"overdue.current_remind": "Now: %s, however many days have passed.\n\n",
+	"overdue.current":        "Now: if a review is overdue by more than %d %s, %s.\n\n",
+	"overdue.options":        "Options:\n",
+	"overdue.days_hint":      "\nHow many days overdue before it applies: /overdue <option> <1-%d>",
+	"overdue.rescheduled":    "📅 These reviews were overdue, I moved them to a new date:",
+	"overdue.restarted":      "🔄 These topics haven't been reviewed for a long time, I started them over from review #1:",
+	"overdue.stalled": "⏸ These topics haven't been reviewed for a long time, I won't remind you about them anymore. " +
+		"Once a week I'll ask whether to bring them back or archive them:",
+	"overdue.settings":       "\n\nSettings: /overdue",
+	"overdue.stalled_title":  "⏸ These topics are stopped because they haven't been reviewed for a long time:\n\n",
+	"overdue.archive_button": "📦 Archive",
+	"overdue.stalled_hint":   "\n🔄 - start the topic over from review #1, 📦 - archive it.",
+	"overdue.not_stalled":    "The topic \"%s\" is no longer stopped.",
+	"overdue.revived":        "🔄 The topic \"%s\" started over: the first review is tomorrow, reminders are on.",
+
+	"anki.usage": "🗂 Anki import and export\n\n" +
+		"📥 Import: send me a deck file - .apkg (in Anki: File → Export → «Anki Deck Package», " +
+		"check «Support older Anki versions») or .txt («Notes in Plain Text»). " +
+		"Each deck becomes a topic, and its words go to flashcard review (/review).\n\n" +
+		"The note fields are read like this now: %s. To map them differently, add a caption to the file, " +
+		"for example: word=Front, translation=Back, examples=Example. A field can be given by name or by number.\n\n" +
+		"📤 Export:\n" +
+		"/anki export - Learned words as an .apkg deck\n" +
+		"/anki export all - All your words\n" +
+		"Add txt to get a text file instead of .apkg: /anki export all txt",
+	"anki.export_usage": "Use: /anki export [all] [txt]",
+	"anki.no_words":     "Nothing to export yet: you have no words.",
+	"anki.no_learned":   "No learned words yet. To export all words, send /anki export all",
+	"anki.exported":     "🗂 %d %s for Anki. Open the file in Anki: File → Import.",
+	"anki.unsupported":  "I can import Anki decks in .apkg and .txt files. More: /anki",
+	"anki.too_big":      "The file is too big: Telegram lets bots download files up to %d MB.",
+	"anki.bad_caption":  "Couldn't read the file caption. Give the fields like this: word=Front, translation=Back",
+	"anki.new_format": "This file is saved in the new Anki format. Export the deck again " +
+		"with «Support older Anki versions» checked.",
+	"anki.unreadable": "Couldn't read the file. Send an Anki export: .apkg or .txt. More: /anki",
+	"anki.no_notes": "The file has no notes with a word and a translation. " +
+		"The fields are read like this now: %s. Give other fields in the file caption, for example: word=Front, translation=Back",
+	"anki.imported": "✅ Anki import finished\n\n📚 Topics: %d\n🃏 New words: %d of %d\n\n" +
+		"The words are already waiting for you in flashcard review: /review",
+	"anki.topic_limit": "The file has %d new decks, but you can create only %d more topics (limit %d). " +
+		"Delete the topics you don't need or merge the decks in Anki.",
+
+	"review.nothing_due":   "🎉 No words to review right now.",
+	"review.inactive":      "This card is no longer active. Send /review to go on reviewing.",
+	"review.all_done":      "🎉 All words for today are reviewed!",
+	"review.front_reverse": "🃏 Card\n\n🇷🇺 %s\n\nRecall the English word and tap «Flip».",
+	"review.front":         "🃏 Card\n\n🇬🇧 %s\n\nRecall the translation and tap «Flip».",
+	"review.card":          "🃏 Card\n\n",
+	"review.back_reverse":  "\nHow easily did you recall the word?",
+	"review.back":          "\nHow easily did you recall the translation?",
+	"review.flip":          "🔄 Flip",
+	"word.forms":           "🔤 Forms: %s",
+	"word.synonyms":        "🟰 Synonyms: %s",
+	"word.antonyms":        "↔️ Antonyms: %s",
+	"word.examples":        "\n📝 Examples:\n%s",
+
+	"search.usage":             "🔎 Tell me what to find: /search <query>\nI search topic names and descriptions, words, translations and examples.",
+	"search.too_long":          "The query is too long. At most %d characters.",
+	"search.nothing":           "🔎 Nothing found for «%s».",
+	"search.title":             "🔎 Results for «%s»\n",
+	"search.topics":            "\n📚 Topics:\n",
+	"search.archived":          " (archived)",
+	"search.words":             "\n🔤 Words:\n",
+	"search.stale":             "This button is out of date. Search again: /search <query>",
+	"search.confirm_topic":     "⚠️ Delete the topic \"%s\" with its subtopics and review history?\nTo keep the history, archive the topic instead: /archive",
+	"search.topic_deleted":     "🗑 Topic \"%s\" deleted.\n\n%s",
+	"search.edit_word":         "✏️ %s - %s\n\nSend the new translation:",
+	"search.confirm_word":      "⚠️ Delete the word \"%s\" with its review progress?",
+	"search.word_deleted":      "🗑 Word \"%s\" deleted.",
+	"search.confirm_delete":    "🗑 Yes, delete",
+	"search.empty_translation": "❌ The translation can't be empty. Send a new translation or tap \"Cancel\".",
+	"search.translation_saved": "✅ New translation saved: %s",
+
+	"wod.usage": "Use: /wordofday - settings, /wordofday now - a word right now, " +
+		"/wordofday on|off - send one every day, /wordofday new - from your new words, " +
+		"/wordofday <deck number> - from a deck of the /decks catalog",
+	"wod.no_deck":         "There is no deck number %d. The deck numbers are in the catalog: /decks",
+	"wod.saved":           "✅ Saved.",
+	"wod.title":           "🌅 Word of the day\n\n",
+	"wod.enabled":         "Comes every day at %d:00\n",
+	"wod.disabled":        "Off\n",
+	"wod.from_deck":       "From: the deck «%s»\n\n",
+	"wod.from_own":        "From: your new words that are coming up for review soon\n\n",
+	"wod.about":           "Each word comes with examples, pronunciation and a mini quiz: guess the translation.\n\n",
+	"wod.none_left":       "🌅 No new words left for the word of the day. Pick a deck from the catalog: /wordofday <deck number>",
+	"wod.dont_know":       "🤷 Don't know",
+	"wod.stale":           "This button is out of date.",
+	"wod.name":            "Word of the day",
+	"wod.of_deck":         " from the deck «%s»",
+	"wod.question_prefix": "\n\n🧠 What is the translation of ",
+	"wod.right":           "\n\n✅ Right!",
+	"wod.wrong":           "\n\n❌ Not quite, the right answer is: %s",
+	"wod.settings":        "\n\nWord of the day settings: /wordofday",
+
+	"broadcast.done":     "✅ Broadcast finished.\nDelivered: %d of %d\nNot delivered: %d",
+	"broadcast.progress": "📣 Broadcasting: %d of %d\nNot delivered: %d",
+	"admin.usage":        "Use: /admin stats, /admin backup or /admin undeliverable",
+	"admin.stats": "🛠 Bot statistics\n\n" +
+		"👥 Users: %d\n" +
+		"🔔 With notifications on: %d\n" +
+		"🔕 Opted out of broadcasts: %d\n" +
+		"🚫 Blocked the bot or deleted the account: %d\n\n" +
+		"📚 Topics: %d (archived: %d)\n" +
+		"🔄 Reviews: %d (done: %d)\n\n" +
+		"📨 Messages since start: sent %d, queued %d, retried after 429: %d, dropped: %d\n" +
+		"📮 Notifications waiting to be resent: %d, unreachable users: %d (/admin undeliverable)",
+	"admin.all_delivered":         "📮 All notifications are delivered.",
+	"admin.undeliverable":         "📮 Users who don't get notifications:\n",
+	"admin.blocked":               "🚫 Bot blocked: %d notifications in a row\n",
+	"admin.failed":                "❌ Not delivered after retries: %d\n",
+	"admin.disabled":              "🔕 Notifications turned off by the bot\n",
+	"admin.inactive":              "💤 Inactive until they start the bot again with /start\n",
+	"admin.error":                 "Error: %s\n",
+	"admin.latest":                "\nShowing the latest %d.",
+	"admin.backup_off":            "Backups are not set up: set BACKUP_DIR or BACKUP_S3_BUCKET.",
+	"admin.backup_unsupported":    "Backups are made only for SQLite, use pg_dump for PostgreSQL.",
+	"admin.backup_done":           "💾 Backup %s saved (%.1f MB).",
+	"admin.backup_cleanup_failed": "\n⚠️ Couldn't delete old backups, see the logs for details.",
+	"broadcast.usage":             "Give the broadcast text: /broadcast <text>",
+	"broadcast.preview":           "📣 The broadcast goes to %d users, %d opted out of broadcasts.\n\n%s",
+	"broadcast.send":              "📣 Send",
+	"broadcast.admins_only":       "Only admins can send broadcasts.",
+	"broadcast.not_found":         "Broadcast not found. Send /broadcast <text> again.",
+	"broadcast.running":           "The previous broadcast hasn't finished yet. Wait for its report.",
+
+	"plural.topic":           "topic|topics|topics",
+	"bulk.delete":            "🗑 Delete",
+	"bulk.archive":           "📦 Archive",
+	"bulk.mute":              "🔕 Mute",
+	"bulk.restore":           "🔔 Bring back",
+	"bulk.reset":             "🔄 Start over",
+	"bulk.category":          "📁 To category",
+	"bulk.reset_selection":   "The topic selection was reset. Open «Bulk actions» again.",
+	"bulk.select_first":      "Select at least one topic first.",
+	"bulk.confirm_delete":    "⚠️ Delete the selected topics (%d) with their review history?\nYou can undo the deletion within %d min.",
+	"bulk.back_to_selection": "⬅️ Back to the selection",
+	"bulk.ask_category":      "📁 Write the category name for the selected topics.\nTo take the topics out of their category, send «-».",
+	"bulk.archived":          "📦 Archived",
+	"bulk.muted":             "🔕 Reminders turned off",
+	"bulk.restored":          "🔔 Brought back from the archive, reminders on",
+	"bulk.restarted":         "🔄 Reviews started over",
+	"bulk.deleted":           "🗑 Deleted: %d %s.\n\n%s",
+	"bulk.category_empty":    "❌ The category name can't be empty. Write some text or tap \"Cancel\".",
+	"bulk.category_too_long": "❌ The category name is too long. At most %d characters.",
+	"bulk.categorized":       "📁 Moved to the category «%s»: %d %s.",
+	"bulk.uncategorized":     "📁 Taken out of categories: %d %s.",
+	"bulk.title":             "🧰 Bulk actions\n\nSelect topics and choose an action.\nSelected: %d",
+
+	"plural.question": "question|questions|questions",
+
+	"quiz.need_words":  "🧠 A quiz needs at least 2 words. Add words from the ready-made decks (/decks) or import them from Anki (/anki).",
+	"quiz.all_words":   "📚 All words (%d)",
+	"quiz.hard_words":  "🔥 Hard words (%d)",
+	"quiz.title":       "🧠 Word quiz\n\nChoose the words for the quiz:",
+	"quiz.hard_hint":   "\n\n🔥 Hard words - practice with the words you find hardest in reviews and quizzes coming up most often.",
+	"quiz.topic_empty": "This topic has no words left. Start the quiz again: /quiz",
+	"quiz.count_all":   "All (%d)",
+	"quiz.ask_count":   "🧠 How many questions should the quiz have?",
+	"quiz.modes": "🧠 How do you want to answer?\n\n" +
+		"🔘 Buttons - pick the translation under the message, the bot shows right away whether it's correct.\n" +
+		"📊 Polls - each question comes as a Telegram quiz poll.\n" +
+		"⌨️ Typing - write the translation yourself. Small typos are forgiven, and the answers affect the review schedule (/review).\n" +
+		"🧩 In context - fill in the word missing from a usage example. Works with words that have examples.\n" +
+		"🟰 Synonyms - pick the English word with a similar meaning. Works with words that have synonyms.\n" +
+		"🔤 Spelling - build the English word from shuffled letters by its translation. The answers affect the review schedule, a nearly right word counts partly.\n" +
+		"🔀 Word order - build the usage example by tapping its words in order. Works with words that have examples.",
+	"quiz.mode_buttons":          "🔘 Buttons",
+	"quiz.mode_poll":             "📊 Polls",
+	"quiz.mode_text":             "⌨️ Type the translation",
+	"quiz.mode_context":          "🧩 Word in context",
+	"quiz.mode_synonym":          "🟰 Synonyms",
+	"quiz.mode_spelling":         "🔤 Spelling",
+	"quiz.mode_word_order":       "🔀 Word order",
+	"quiz.modes_voice":           "\n🎙 Voice - say the English word for its translation in a voice message, the bot checks the pronunciation and counts the answer in the review schedule.",
+	"quiz.mode_voice":            "🎙 Voice",
+	"quiz.modes_listening":       "\n🎧 Listening - the bot says a word or an example with it, pick the translation with the buttons.",
+	"quiz.mode_listening":        "🎧 Listening",
+	"quiz.modes_sentence":        "\n✍️ Your sentence - write a sentence with the word. A language model checks the grammar and the word usage, explains the mistakes, and the grade affects the review schedule.",
+	"quiz.mode_sentence":         "✍️ Your sentence",
+	"quiz.modes_definition":      "\n📘 By definition - the bot shows an English definition, and you pick the word with the buttons or type it. The definitions are written by a language model, so the first quiz on new words takes longer to prepare.",
+	"quiz.mode_definition":       "📘 Pick the word",
+	"quiz.mode_definition_text":  "📘 Type the word",
+	"quiz.voice_off":             "Voice answers are unavailable right now. Choose another way: /quiz",
+	"quiz.listening_off":         "The listening quiz is unavailable right now. Choose another way: /quiz",
+	"quiz.sentence_off":          "Sentence checks are unavailable right now. Choose another way: /quiz",
+	"quiz.definition_off":        "The definition quiz is unavailable right now. Choose another way: /quiz",
+	"quiz.preparing_definitions": "⏳ Preparing the word definitions...",
+	"quiz.no_context":            "The context quiz needs words with usage examples, and these words have none. Choose other words or another way to answer: /quiz",
+	"quiz.no_synonyms":           "The synonym quiz needs words with synonyms, and these words have none yet: the bot finds them in the background. Choose other words or another way to answer: /quiz",
+	"quiz.no_spelling":           "Spelling works with single words of up to %d letters, and these words have none. Choose other words or another way to answer: /quiz",
+	"quiz.no_word_order":         "The word order quiz needs usage examples of %d to %d words, and these words have none. Choose other words or another way to answer: /quiz",
+	"quiz.no_definitions":        "Couldn't prepare the definitions of these words. Try again later or choose another way to answer: /quiz",
+	"quiz.not_enough":            "A quiz needs at least 2 words with different translations. Start the quiz again: /quiz",
+	"quiz.poll_started":          "🧠 The quiz has started: %d %s. Answer in the polls below.",
+	"quiz.listening_started":     "🎧 The listening quiz has started: %d %s. Listen to the voice messages and pick the translation.",
+	"quiz.voice_only":            "🎙 I take voice messages as answers in the «Voice» quiz: /quiz",
+	"quiz.voice_too_long":        "The message is too long: just say the word, up to %d seconds.",
+	"quiz.transcribe_failed":     "😔 Couldn't recognize the speech. Try recording the answer again.",
+	"quiz.silence":               "🎙 I didn't hear a word. Record the answer again, closer to the microphone.",
+	"quiz.recognized":            "🎙 Recognized: «%s»\n%s",
+	"quiz.check_failed":          "😔 Couldn't check the sentence. Send it again or tap «🤷 Don't know».",
+	"quiz.poll_question":         "%d/%d. What does «%s» mean?",
+	"quiz.stopped":               "⏹ The quiz was stopped. Start a new one: /quiz",
+	"quiz.ask_definition":        "📘 Which word fits the definition?\n\n%s",
+	"quiz.ask_synonym":           "Which word is a synonym of «%s»?",
+	"quiz.ask_heard_phrase":      "🎧 Which of these words was in the phrase?",
+	"quiz.ask_heard_word":        "🎧 What does the word you heard mean?",
+	"quiz.ask_translation":       "What does «%s» mean?",
+	"quiz.ask_cloze":             "Fill in the missing word (%s):\n\n%s",
+	"quiz.ask_say":               "🎙 Say it in English in a voice message: «%s»",
+	"quiz.ask_sentence":          "✍️ Write an English sentence with the word «%s» (%s)",
+	"quiz.ask_definition_text":   "📘 Type the English word for the definition:\n\n%s",
+	"quiz.ask_typed":             "Type the translation: «%s»",
+	"quiz.right":                 "✅ Right: %s - %s",
+	"quiz.wrong":                 "❌ Wrong: %s - %s",
+	"quiz.almost_letters":        "✅ Almost: %d of %d letters in place. Correct: «%s»",
+	"quiz.almost_words":          "✅ Almost: %d of %d words in place",
+	"quiz.unclear":               "✅ Counted, but it didn't sound quite clear. Correct: «%s»",
+	"quiz.with_mistakes":         "✅ Counted, but with mistakes: %s - %s",
+	"quiz.typo":                  "✅ Counted, but with a typo. Correct: «%s»",
+	"quiz.words_in_place":        "\n%d of %d words in place",
+	"quiz.letters_in_place":      "\n%d of %d letters in place",
+	"quiz.summary":               "🏁 Quiz finished\n\n",
+	"quiz.summary_correct":       "✅ Correct answers: %d of %d (%d%%)\n",
+	"quiz.summary_time":          "⏱ Time: %s\n",
+	"quiz.summary_mistakes":      "\n📝 Worth reviewing:\n",
+	"quiz.summary_cram":          "\nKeep cramming: /cram, check yourself on schedule: /review",
+	"quiz.summary_again":         "\nTake another quiz: /quiz",
+	"quiz.seconds":               "%d s",
+	"quiz.minutes":               "%d min %d s",
+}
@@ -0,0 +1,53 @@
+// Package i18n holds the bot's message catalogs and looks up texts by key
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/example/engbot/internal/locale"
+)
+
+// catalogs maps each supported locale to its messages
+var catalogs = map[locale.Locale]map[string]string{
+	locale.Russian: russian,
+	locale.English: english,
+}
+
+// Language is a locale offered by /language
+type Language struct {
+	Locale locale.Locale
+	Name   string
+}
+
+// Languages lists the interface languages in display order
+var Languages = []Language{
+	{Locale: locale.Russian, Name: "🇷🇺 Русский"},
+	{Locale: locale.English, Name: "🇬🇧 English"},
+}
+
+// T returns the message for key in loc, formatted with args when given. Keys missing
+// from the catalog fall back to the default locale, then to the key itself.
+func T(loc locale.Locale, key string, args ...interface{}) string {
+	msg, ok := catalogs[loc][key]
+	if !ok {
+		msg, ok = catalogs[locale.Default][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Plural returns the form of the counted noun key for n. The catalogs hold the one,
+// few and many forms separated by "|", as in "день|дня|дней".
+func Plural(loc locale.Locale, key string, n int) string {
+	forms := strings.Split(T(loc, key), "|")
+	for len(forms) < 3 {
+		forms = append(forms, forms[len(forms)-1])
+	}
+	return loc.Plural(n, forms[0], forms[1], forms[2])
+}
@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/example/engbot/internal/locale"
+)
+
+// verbPattern matches the formatting verbs of a message
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogsMatch(t *testing.T) {
+	for loc, catalog := range catalogs {
+		if loc == locale.Default {
+			continue
+		}
+		for key, msg := range catalogs[locale.Default] {
+			translated, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s: no %q", loc, key)
+				continue
+			}
+			if want, got := verbPattern.FindAllString(msg, -1), verbPattern.FindAllString(translated, -1); !slices.Equal(got, want) {
+				t.Errorf("%s: %q formats %q, want %q", loc, key, got, want)
+			}
+		}
+		for key := range catalog {
+			if _, ok := catalogs[locale.Default][key]; !ok {
+				t.Errorf("%s: %q is missing from the default catalog", loc, key)
+			}
+		}
+	}
+}
+
+func TestPluralForms(t *testing.T) {
+	for loc, catalog := range catalogs {
+		for key, msg := range catalog {
+			if strings.HasPrefix(key, "plural.") && len(strings.Split(msg, "|")) != 3 {
+				t.Errorf("%s: %q = %q, want three forms", loc, key, msg)
+			}
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(locale.English, "time.done", "9:00"); got != "✅ Notification time: 9:00" {
+		t.Errorf("T(en, time.done) = %q", got)
+	}
+	if got := T(locale.Russian, "time.done", "9:00"); got != "✅ Время уведомлений: 9:00" {
+		t.Errorf("T(ru, time.done) = %q", got)
+	}
+	if got := T(locale.English, "no.such.key"); got != "no.such.key" {
+		t.Errorf("T of a missing key = %q, want the key", got)
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		loc  locale.Locale
+		n    int
+		want string
+	}{
+		{locale.Russian, 1, "день"},
+		{locale.Russian, 3, "дня"},
+		{locale.Russian, 11, "дней"},
+		{locale.English, 1, "day"},
+		{locale.English, 3, "days"},
+	}
+	for _, tt := range tests {
+		if got := Plural(tt.loc, "plural.day", tt.n); got != tt.want {
+			t.Errorf("Plural(%s, %d) = %q, want %q", tt.loc, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,1070 @@
+package i18n
+
+// russian is the default catalog; every key must be present here
+var russian = map[string]string{
+	"start.welcome": "👋 Добро пожаловать в Spaced Repetition Manager!\n\n" +
+		"Я помогу вам эффективно изучать темы с помощью метода интервального повторения.\n\n" +
+		"🔹 Как это работает:\n" +
+		"1. Добавьте тему для изучения\n" +
+		"2. Получайте уведомления о повторении\n" +
+		"3. Отмечайте выполненные повторения\n" +
+		"4. Отслеживайте свой прогресс",
+
+	"help.text": "📖 Справка по использованию бота\n\n" +
+		"🔸 Основные команды:\n" +
+		"/start - Запустить бота и показать главное меню\n" +
+		"/help - Показать эту справку\n" +
+		"/language - Язык интерфейса\n\n" +
+		"📚 Управление темами:\n" +
+		"/add - Добавить новую тему, /add Тема | 3d - с первым повторением через 3 дня\n" +
+		"/addmany - Добавить список тем, по одной на строке\n" +
+		"/list - Показать список всех тем\n" +
+		"/delete - Удалить тему\n" +
+		"/edit <номер> - Переименовать тему\n" +
+		"/move <номер> <номер родителя> - Сделать тему подтемой другой (0 - вернуть на верхний уровень)\n" +
+		"/merge [номер] [номер] - Найти похожие темы или объединить первую тему со второй\n" +
+		"/describe <номер> <описание> - Добавить к теме описание\n" +
+		"/search <запрос> - Найти темы и слова\n" +
+		"/history <номер> - История повторений темы с заметками\n" +
+		"/maintenance <номер> - Поддерживающие повторения темы после цикла\n" +
+		"/topicintervals <номер> 1, 2, 4 - Свой график для темы (экзамен 10 - уложиться в 10 дней, off - общий)\n" +
+		"/attach <номер> - Прикрепить к теме ссылки, фото или документы\n" +
+		"/archive [номер] - Убрать тему в архив с сохранением истории или показать архив\n" +
+		"/difficulty <номер> <1-5> - Задать сложность темы\n" +
+		"/restartall - Начать все повторения заново\n" +
+		"/review - Повторить слова карточками\n" +
+		"/app - Повторить темы карточками в мини-приложении\n" +
+		"/export - Выгрузить темы, историю, слова и статистику в Excel\n" +
+		"/anki - Импорт колод Anki и экспорт слов в Anki\n" +
+		"/decks - Каталог готовых колод слов с подпиской\n" +
+		"/plans [номер] - Готовые планы по грамматике: тема с правилом, примерами и своим графиком в одно касание\n" +
+		"/stats [charts|номер] - Статистика, графики картинками или подробно по теме\n" +
+		"/goal [число|off] - Дневная цель повторений и серия дней\n" +
+		"/quiz - Тест на знание слов: кнопками, опросами, вводом перевода, в контексте, на синонимы, по буквам, порядок слов, на слух, голосом, своим предложением или по определению\n" +
+		"/cram <номер|категория> - Прогнать все слова темы перед экзаменом, не меняя график\n" +
+		"/hard - Самые трудные слова и тренировка по ним\n" +
+		"/story [on|off] - Короткая история со словами к повторению\n" +
+		"/practice [stop] - Разговор с ИИ, в котором ваши слова засчитываются как повторения\n" +
+		"/wordofday [now|on|off|new|номер колоды] - Слово дня с примерами, произношением и мини-тестом\n\n" +
+		"⚙️ Настройки:\n" +
+		"/notify on|off - Включить/выключить уведомления\n" +
+		"/time - Установить время уведомлений\n" +
+		"/channels - Напоминания еще и на почту или вебхук\n" +
+		"/skipfirst <0-6> - Не напоминать о первых повторениях\n" +
+		"/overdue - Что делать с давно просроченными повторениями\n" +
+		"/load [число|off] - Прогноз повторений и лимит в день\n" +
+		"/newwords [число|off] - Сколько новых слов в день приходит на повторение\n" +
+		"/direction [номер темы] [en|ru|both] - Карточки с английского, с русского или в обе стороны\n" +
+		"/forecast [chart] - Календарь повторений на 30 дней\n" +
+		"/calendar [reset] - Ссылка на повторения для Google/Apple Календаря\n" +
+		"/report [now|on|off|<день> <час>] - Еженедельный отчет о прогрессе\n" +
+		"/intervals - Выбрать график интервалов повторения\n" +
+		"/news on|off - Получать новости бота\n\n" +
+		"🔄 Интервалы повторения:\n" +
+		"Первое повторение - через 1 день. После каждого повторения оцените, насколько легко " +
+		"вы вспомнили тему (❌ Не помню, 😓 Трудно, 🙂 Хорошо, 😎 Легко), и бот подберет следующий " +
+		"интервал по алгоритму SM-2. Тема считается закрепленной после 7 успешных повторений.\n" +
+		"Для сложных тем интервалы короче, для легких - длиннее.\n\n" +
+		"💡 Советы:\n" +
+		"• Регулярно отмечайте выполненные повторения\n" +
+		"• Следите за статистикой прогресса\n" +
+		"• Настройте удобное время уведомлений",
+
+	"menu.main.text": "🤖 Главное меню\n\n" +
+		"Выберите нужный раздел:\n" +
+		"📚 Управление темами - добавление, просмотр и удаление тем\n" +
+		"📊 Статистика - ваш прогресс в изучении\n" +
+		"⚙️ Настройки - настройка уведомлений\n" +
+		"❓ Помощь - информация о командах",
+	"menu.main.topics":   "📚 Управление темами",
+	"menu.main.stats":    "📊 Статистика",
+	"menu.main.settings": "⚙️ Настройки",
+	"menu.main.help":     "❓ Помощь",
+	"menu.back":          "⬅️ Вернуться в меню",
+
+	"command.unknown": "Неизвестная команда. Используйте /help для просмотра списка доступных команд.",
+
+	"notifications.enabled":  "включены",
+	"notifications.disabled": "выключены",
+	"digest.enabled":         "включен",
+	"digest.disabled":        "выключен",
+	"scheduler.sm2":          "SM-2, интервалы подстраиваются под оценки",
+	"scheduler.leitner":      "коробки Лейтнера",
+	"sm2.default":            "по умолчанию, подстроятся под вас после %d повторений",
+	"sm2.tuned":              "зачет от оценки %d, EF не ниже %.1f, первые интервалы %s дн. (подобраны %s)",
+
+	"settings.text": "Текущие настройки:\n\n" +
+		"Уведомления: %s\n" +
+		"Время уведомлений: %s\n" +
+		"Тихие часы: %s\n" +
+		"Без напоминаний для первых повторений: %d\n" +
+		"Утренний дайджест: %s\n" +
+		"Алгоритм повторений: %s\n" +
+		"Параметры SM-2: %s\n" +
+		"Язык: %s\n\n" +
+		"Для изменения настроек используйте команды:\n" +
+		"/notify on|off - Включить/выключить уведомления\n" +
+		"/time <часы> - Время уведомлений, можно несколько через запятую: /time 9, 20\n" +
+		"/skipfirst <N> - Не напоминать о первых N повторениях (0 - напоминать обо всех)\n" +
+		"/overdue <remind|reschedule|reset|stall> [дней] - Что делать с давно просроченными повторениями\n" +
+		"/load <N|off> - Не больше N повторений в день, лишние переносятся на следующие дни\n" +
+		"/newwords <N|off> - Не больше N новых слов в день, например из больших колод\n" +
+		"/direction <en|ru|both> - Карточки с английского на русский, с русского на английский или в обе стороны\n" +
+		"/report <пн-вс> <0-23>|off - Когда присылать еженедельный отчет\n" +
+		"/intervals - Интенсивный, стандартный, спокойный или свой график повторений\n" +
+		"/news on|off - Новости бота\n" +
+		"/language - Язык интерфейса",
+
+	"notify.usage": "Пожалуйста, укажите on или off: /notify <on|off>",
+	"notify.done":  "✅ Уведомления %s",
+
+	"time.usage":   "Пожалуйста, укажите час (0-23) или несколько через запятую: /time 9, 20\nТихие часы настраиваются в разделе «🕒 Время уведомлений».",
+	"time.invalid": "Пожалуйста, укажите часы от 0 до 23 через запятую, не больше %d",
+	"time.done":    "✅ Время уведомлений: %s",
+
+	"quiet.off": "нет",
+
+	"skipfirst.usage":   "Пожалуйста, укажите, о скольких первых повторениях не напоминать (0-6): /skipfirst <N>",
+	"skipfirst.invalid": "Пожалуйста, укажите число от 0 до 6",
+	"skipfirst.all":     "✅ Напоминания будут приходить обо всех повторениях",
+	"skipfirst.from":    "✅ Напоминания будут приходить начиная с повторения %s",
+
+	"language.choose":  "🌐 Выберите язык интерфейса:",
+	"language.unknown": "Неизвестный язык. Доступны: ru, en",
+	"language.done":    "✅ Язык интерфейса: русский",
+
+	"calendar.name":        "EngBot: повторения",
+	"calendar.summary":     "🔁 %s",
+	"calendar.description": "Повторение %d из %d. Отметьте его в боте.",
+	"calendar.maintenance": "Поддерживающее повторение. Отметьте его в боте.",
+
+	"news.usage": "Пожалуйста, укажите on или off: /news <on|off>",
+	"news.done":  "✅ Новости бота %s",
+
+	"broadcast.footer": "—\nОтключить новости бота: /news off",
+
+	"export.empty":   "Пока нечего выгружать: добавьте тему командой /add",
+	"export.caption": "📤 Ваши темы, история повторений, слова и статистика",
+
+	"reminder.header":         "🔔 Напоминание о повторении:\n\n",
+	"reminder.topic":          "📚 Тема: %s\n",
+	"reminder.maintenance":    "♾ Поддерживающее повторение",
+	"reminder.footer":         "\nПосле повторения отметьте его как выполненное, нажав на соответствующую кнопку.",
+	"reminder.button":         "✅ Повторил тему \"%s\"",
+	"reminder.count":          "У вас %s для повторения! Откройте список тем, чтобы начать повторение.",
+	"reminder.subject":        "EngBot: пора повторить темы",
+	"reminder.test":           "🔔 Это пробное напоминание. Так будут приходить напоминания о повторениях.\n",
+	"reminder.channel_footer": "\nОтметьте повторения как выполненные в боте: https://t.me/%s",
+
+	"command.start":          "🚀 Запустить бота",
+	"command.add":            "📝 Добавить новую тему",
+	"command.addmany":        "📝 Добавить список тем",
+	"command.list":           "📋 Список всех тем",
+	"command.delete":         "🗑 Удалить тему",
+	"command.edit":           "✏️ Переименовать тему",
+	"command.difficulty":     "📈 Сложность темы",
+	"command.restartall":     "🔄 Начать повторения заново",
+	"command.history":        "📜 История темы",
+	"command.maintenance":    "♾ Поддерживающие повторения",
+	"command.topicintervals": "🗓 Свой график для темы",
+	"command.attach":         "📎 Материалы к теме",
+	"command.move":           "📂 Вложить тему в другую",
+	"command.merge":          "🔀 Объединить темы",
+	"command.describe":       "📝 Описание темы",
+	"command.search":         "🔎 Поиск по темам и словам",
+	"command.archive":        "📦 Архив тем",
+	"command.review":         "🃏 Повторить слова",
+	"command.app":            "📱 Карточки тем в мини-приложении",
+	"command.stats":          "📊 Статистика",
+	"command.export":         "📤 Выгрузить данные в Excel",
+	"command.anki":           "🗂 Импорт и экспорт Anki",
+	"command.decks":          "📚 Каталог колод",
+	"command.plans":          "📘 Готовые планы по грамматике",
+	"command.goal":           "🎯 Дневная цель и серия",
+	"command.quiz":           "🧠 Тест на знание слов",
+	"command.cram":           "📚 Зубрежка перед экзаменом",
+	"command.hard":           "🧱 Самые трудные слова",
+	"command.story":          "📖 История с вашими словами",
+	"command.practice":       "💬 Разговор с вашими словами",
+	"command.wordofday":      "🌅 Слово дня",
+	"command.notify":         "🔔 Вкл/выкл уведомления",
+	"command.time":           "🕒 Время уведомлений",
+	"command.channels":       "📨 Напоминания на почту и вебхук",
+	"command.skipfirst":      "🔕 Без напоминаний о первых повторениях",
+	"command.overdue":        "⏰ Просроченные повторения",
+	"command.load":           "📈 Нагрузка по дням",
+	"command.newwords":       "🆕 Новых слов в день",
+	"command.direction":      "🔁 Направление карточек",
+	"command.forecast":       "🗓 Календарь повторений",
+	"command.calendar":       "📅 Повторения в Google/Apple Календаре",
+	"command.report":         "📬 Еженедельный отчет",
+	"command.intervals":      "🗓 График повторений",
+	"command.language":       "🌐 Язык / Language",
+	"command.news":           "📣 Новости бота",
+	"command.help":           "❓ Помощь",
+
+	"button.cancel":            "❌ Отмена",
+	"button.menu":              "⬅️ В меню",
+	"button.back_to_menu":      "⬅️ Назад в меню",
+	"button.main_menu":         "🏠 Главное меню",
+	"button.back":              "⬅️ Назад",
+	"button.back_to_topics":    "⬅️ Назад к темам",
+	"button.back_to_settings":  "⬅️ Назад в настройки",
+	"button.add_topic":         "📝 Добавить тему",
+	"button.topic_list":        "📋 Список тем",
+	"button.delete_topic":      "🗑 Удалить тему",
+	"button.bulk":              "🧰 Массовые действия",
+	"button.archive":           "📦 Архив",
+	"button.notifications":     "🔔 Уведомления",
+	"button.notification_time": "🕒 Время уведомлений",
+	"button.digest":            "📰 Дайджест",
+	"button.board":             "📌 Доска дня",
+	"button.scheduler":         "🗃 Алгоритм повторений",
+	"button.materials":         "📎 Материалы",
+	"button.done":              "✅ Готово",
+
+	"state.bulk_selecting":    "Отметьте темы кнопками на экране «Массовые действия» и выберите действие.",
+	"state.confirm_broadcast": "Подтвердите рассылку кнопкой «📣 Отправить» или отмените ее. Чтобы изменить текст, отправьте /broadcast <текст> заново.",
+	"state.reviewing_word":    "Используйте кнопки на карточке: «🔄 Перевернуть», а затем оцените, насколько легко вы вспомнили слово.",
+	"state.spoken_quiz":       "🎙 В этом тесте отвечайте голосовым сообщением или нажмите «🤷 Не знаю».",
+	"state.use_menu":          "Пожалуйста, используйте команды из меню для взаимодействия с ботом.",
+
+	"error.create_profile": "❌ Не удалось создать профиль пользователя. Попробуйте еще раз.",
+	"error.read_profile":   "❌ Ошибка при получении данных пользователя. Попробуйте еще раз.",
+	"error.no_profile":     "❌ Ошибка: не удалось получить профиль пользователя",
+
+	"add.empty":             "❌ Название темы не может быть пустым. Пожалуйста, отправьте название темы или нажмите кнопку \"Отмена\".",
+	"add.exists":            "⚠️ Тема «%s» уже существует. Отправьте другое название или нажмите \"Отмена\".",
+	"add.failed":            "❌ Не удалось создать тему. Попробуйте еще раз.",
+	"add.done.before":       "✅ Тема ",
+	"add.done.after":        " успешно добавлена!\n",
+	"add.done.first_review": "📅 Первое повторение: %s\n\n",
+	"add.done.next": "Теперь вы можете:" +
+		"\n1. Добавить еще одну тему" +
+		"\n2. Посмотреть список всех тем" +
+		"\n3. Вернуться в главное меню",
+
+	"add.title": "📝 Добавление новой темы",
+	"add.prompt": "\n\nПожалуйста, отправьте название темы, которую хотите добавить.\n" +
+		"Например: \"Английская грамматика\" или \"Алгоритмы сортировки\"\n\n" +
+		"Можно отправить сразу несколько тем, по одной на строке.\n" +
+		"Если материал вы уже изучали, укажите первое повторение после «|»: " +
+		"\"Алгоритмы сортировки | 3d\", \"| today\" или \"| tomorrow\".",
+
+	"topic.invalid_number": "Пожалуйста, укажите корректный номер темы",
+	"topic.wrong_number":   "Указан неверный номер темы",
+
+	"topics.none":       "У вас пока нет добавленных тем. Нажмите кнопку \"📝 Добавить тему\" чтобы начать.",
+	"topics.title":      "📋 Ваши темы:\n\n",
+	"topics.cycle_done": " · цикл завершен",
+	"topics.subtopics":  "📚 Подтем: %d, требуют повторения: %d\n",
+	"topics.difficulty": "📈 Сложность: %s (%d/5)\n",
+	"topics.category":   "📁 Категория: %s\n",
+	"topics.archived":   "📦 В архиве\n",
+	"topics.muted":      "🔕 Напоминания выключены\n",
+	"topics.due":        "🔄 Требует повторения!\n",
+	"topics.page":       "\nСтраница %d из %d",
+	"topics.limit":      "⚠️ Достигнут лимит тем (%d). Удалите ненужные темы, чтобы добавить новые.",
+
+	"delete.usage":          "Пожалуйста, укажите номер темы для удаления: /delete <номер>",
+	"delete.done":           "Тема \"%s\" удалена",
+	"delete.done_subtopics": " вместе с подтемами (%d)",
+
+	"difficulty.usage": "Пожалуйста, укажите номер темы и сложность от 1 (легко) до 5 (сложно): /difficulty <номер> <1-5>",
+	"difficulty.done":  "✅ Сложность темы \"%s\": %s (%d/5)\nСледующие повторения будут запланированы с учетом сложности.",
+	"difficulty.1":     "очень легко",
+	"difficulty.2":     "легко",
+	"difficulty.3":     "средне",
+	"difficulty.4":     "сложно",
+	"difficulty.5":     "очень сложно",
+
+	"restartall.confirm": "⚠️ Начать все повторения заново?\n\n" +
+		"Все ваши темы вернутся к повторению №1 с датой на завтра, " +
+		"а счетчики выполненных повторений будут обнулены.\n" +
+		"Сами темы не удаляются, но прогресс повторений восстановить будет нельзя.",
+	"restartall.button": "🔄 Да, начать всё заново",
+	"restartall.done":   "🔄 Повторения начаты заново.\nСброшено тем: %d. Первое повторение - завтра.",
+
+	"stats.none":          "У вас пока нет статистики. Добавьте темы для повторения!",
+	"stats.title":         "📊 Ваша статистика\n\n",
+	"stats.topic":         "Тема: %s\nВсего повторений: %d\nВыполнено: %d (%.1f%%)\n",
+	"stats.subtopics":     "📚 С подтемами: выполнено %d из %d (%.1f%%)\n",
+	"stats.details":       "Подробнее о теме: /stats <номер из /list> или кнопки ниже",
+	"stats.charts_button": "📈 Графики",
+
+	"callback.unknown": "⚠️ Неизвестное действие",
+
+	"stale":         "Кнопка устарела.",
+	"stale.topics":  "Кнопка устарела. Откройте список тем заново.",
+	"stale.topic":   "Кнопка устарела. Откройте тему заново.",
+	"stale.digest":  "Кнопка устарела. Дождитесь следующего дайджеста.",
+	"stale.history": "Кнопка устарела. Откройте историю темы заново.",
+	"stale.bulk":    "Кнопка устарела. Откройте «Массовые действия» заново.",
+	"stale.decks":   "Кнопка устарела. Откройте каталог колод заново: /decks",
+	"stale.review":  "Кнопка устарела. Отправьте /review заново.",
+	"stale.quiz":    "Кнопка устарела. Начните тест заново: /quiz",
+	"stale.time":    "Кнопка устарела. Откройте настройки времени заново.",
+
+	"topics.menu": "📚 Управление темами\n\n" +
+		"Выберите действие:\n" +
+		"📝 Добавить тему - создать новую тему для повторения\n" +
+		"📋 Список тем - просмотреть все ваши темы\n" +
+		"🗑 Удалить тему - удалить существующую тему\n" +
+		"🧰 Массовые действия - удалить, архивировать или сбросить сразу несколько тем",
+	"settings.menu": "⚙️ Настройки\n\n" +
+		"Выберите, что хотите настроить:\n" +
+		"🔔 Уведомления - включение/выключение уведомлений\n" +
+		"🕒 Время уведомлений - установка времени для напоминаний\n" +
+		"📰 Дайджест - одно утреннее сообщение вместо отдельных напоминаний\n" +
+		"🗃 Алгоритм повторений - SM-2 или более простые коробки Лейтнера",
+
+	"notifications.off_button": "🔕 Выключить уведомления",
+	"notifications.on_button":  "🔔 Включить уведомления",
+	"notifications.title":      "🔔 Настройки уведомлений\n\nТекущий статус: %s\n\nВыберите действие:",
+
+	"delete.none": "❌ У вас пока нет тем для удаления.\n\nСначала добавьте темы с помощью кнопки \"📝 Добавить тему\"",
+	"delete.menu": "🗑 Удаление темы\n\n" +
+		"Для удаления темы отправьте команду:\n" +
+		"/delete <номер>\n\n" +
+		"⚠️ Удаление стирает историю повторений и статистику темы вместе с ее подтемами. " +
+		"Чтобы их сохранить, уберите тему в архив кнопкой ниже.\n\n" +
+		"Ваши темы:\n",
+
+	"repetition.already_done": "Это повторение уже отмечено как выполненное.",
+	"topic.not_found":         "Тема не найдена. Возможно, она была удалена.",
+
+	"grade.topic":          "📚 Тема ",
+	"grade.prompt":         "\n\nНасколько легко вы вспомнили материал?",
+	"rating.forgot":        "❌ Не помню",
+	"rating.hard":          "😓 Трудно",
+	"rating.good":          "🙂 Хорошо",
+	"rating.easy":          "😎 Легко",
+	"complete.next":        "✅ Отлично! Повторение выполнено.\nСледующее повторение запланировано на %s",
+	"complete.maintenance": "✅ Отлично! Тема держится в памяти.\n♾ Следующее поддерживающее повторение - %s.",
+	"complete.again":       "🔁 Ничего страшного! Повторим эту тему еще раз %s.",
+
+	"note.button":   "📝 Добавить заметку",
+	"note.prompt":   "📝 Напишите короткую заметку к этому повторению.\nНапример: \"забыл про исключения\" или \"стало легче\"",
+	"note.empty":    "❌ Заметка не может быть пустой. Напишите текст или нажмите \"Отмена\".",
+	"note.too_long": "❌ Заметка слишком длинная. Максимум %d символов.",
+	"note.saved":    "✅ Заметка сохранена. Ее можно увидеть в истории темы: /history <номер>",
+
+	"history.usage":   "Пожалуйста, укажите номер темы: /history <номер>",
+	"history.title":   "📜 История темы \"%s\"\n\n",
+	"history.done":    "✅ %s - %s\n",
+	"history.planned": "⏳ %s - запланировано на %s\n",
+
+	"completion.title":   "🎉 Поздравляем! Вы завершили все повторения темы \"%s\"!\n\n",
+	"completion.took":    "📅 Изучение заняло: %d %s (с %s по %s)\n",
+	"completion.reviews": "🔄 Выполнено повторений: %d\n",
+	"completion.share":   "\nПоделитесь результатом:\n«Я закрепил тему \"%s\" методом интервальных повторений: %d %s за %d %s! 💪»",
+
+	"plural.day":    "день|дня|дней",
+	"plural.review": "повторение|повторения|повторений",
+
+	"add.start": "Пожалуйста, введите название новой темы для повторения.\n" +
+		"Например: \"Алгоритмы сортировки\" или \"Паттерны проектирования\"",
+
+	"similar.ask":          "🤔 Похоже на «%s», создать «%s» всё равно?",
+	"similar.create":       "✅ Создать всё равно",
+	"similar.keep":         "↩️ Оставить «%s»",
+	"similar.kept":         "👌 Новая тема не создана, продолжайте повторять «%s».",
+	"similar.stale_create": "Это действие уже неактуально. Нажмите \"📝 Добавить тему\", чтобы начать заново.",
+	"stale.action":         "Это действие уже неактуально.",
+
+	"cancel.done": "Действие отменено. Выберите другую команду:",
+
+	"error.transient":    "⏳ Сервис временно занят. Пожалуйста, попробуйте ещё раз через несколько секунд.",
+	"error.topic_exists": "⚠️ Такая тема уже есть. Найти похожие темы и объединить их: /merge",
+	"error.not_found":    "❌ Запись не найдена. Возможно, она уже была удалена. Откройте список тем, чтобы увидеть актуальные данные.",
+	"error.unknown":      "❌ Произошла ошибка. Пожалуйста, попробуйте позже.",
+
+	"first_review.no_name": "Укажите название темы перед «|», например: /add Тема | 3d",
+	"first_review.unknown": "Не понял срок «%s». Укажите today, tomorrow или число дней, например: /add Тема | 3d",
+	"first_review.too_far": "Первое повторение можно отложить не больше чем на %d дней.",
+	"day.today":            "сегодня",
+	"day.tomorrow":         "завтра",
+
+	"addmany.prompt":       "📝 Отправьте список тем, по одной на строке (до %d):\n\nPresent Simple\nPresent Continuous\nPast Simple",
+	"addmany.empty":        "Список пуст. Отправьте названия тем, по одному на строке.",
+	"addmany.too_many":     "За один раз можно добавить не больше %d тем, а в списке %d.",
+	"addmany.first_review": "Срок первого повторения можно указать только для одной темы: /add Тема | 3d",
+	"addmany.created":      "✅ Создано тем: %d",
+	"addmany.duplicates":   "\n⏭ Пропущено, такие темы уже есть: %d\n",
+	"addmany.over_limit":   "\n⚠️ Не добавлено из-за лимита тем (%d): %d",
+
+	"inline.not_found": "Слово не найдено. Открыть бота",
+
+	"app.disabled":    "Мини-приложение не настроено. Повторяйте темы по кнопкам в напоминаниях.",
+	"app.nothing_due": "🃏 Сейчас нечего повторять. Кнопка ниже откроет карточки, когда подойдет время повторений.",
+	"app.due":         "🃏 Пора повторить тем: %d. Откройте карточки - оценки попадут в ваш график повторений так же, как из чата.",
+	"app.button":      "🃏 Открыть карточки",
+
+	"pronunciation.button":    "🔊 Произношение",
+	"pronunciation.disabled":  "Озвучка слов отключена.",
+	"pronunciation.failed":    "😔 Не получилось озвучить слово. Попробуйте чуть позже.",
+	"pronunciation.forbidden": "Telegram не дает отправить вам голосовое сообщение: разрешите их в настройках конфиденциальности («Голосовые сообщения»).",
+	"error.start":             "Профиль не найден. Отправьте /start.",
+
+	"hard.none":      "💪 Трудных слов нет: вы еще ни разу не забыли слово на повторении (/review).",
+	"hard.drill":     "🎯 Тренировать сейчас",
+	"hard.title":     "🧱 Самые трудные слова\n\n",
+	"hard.forgotten": ", в прошлый раз забыто",
+	"hard.footer": "\nЧем ниже EF, тем чаще слово забывается. Тренировка - это тест кнопками только по этим словам, " +
+		"ответы в нем не меняют график повторения.",
+
+	"charts.completion":   "✅ Доля выполненных повторений по неделям за %d недель: сколько из назначенных на неделю повторений выполнено.",
+	"charts.reviews":      "📅 Повторения по дням за %d дней, всего %d.",
+	"charts.goal":         " Пунктир - цель на день (%d).",
+	"charts.topics":       "📚 Выполнено повторений по темам.",
+	"charts.topics_first": " Показаны первые %d тем.",
+	"charts.no_data":      "Для графиков пока мало данных. Повторяйте темы несколько дней, и они появятся.",
+
+	"sentence.no_word":       "\n🔍 В предложении нет этого слова.",
+	"sentence.wrong_meaning": "\n🔍 Слово употреблено не в этом значении.",
+	"sentence.no_mistakes":   "\n👍 Ошибок нет.",
+	"sentence.remove":        " → убрать",
+	"sentence.corrected":     "\n📝 Правильно: ",
+
+	"calendar.disabled": "Календарь не настроен: администратору нужно задать API_ADDR и API_PUBLIC_URL.",
+	"calendar.title":    "📅 Ваш календарь повторений",
+	"calendar.reset":    "🔑 Старая ссылка больше не работает. Новая ссылка на календарь",
+	"calendar.usage":    "Используйте: /calendar - ссылка на календарь, /calendar reset - заменить ссылку",
+	"calendar.link": ":\n%s\n\n" +
+		"Google Календарь: «Другие календари» → «+» → «Добавить по URL».\n" +
+		"Apple Календарь: «Файл» → «Новая подписка на календарь» или на iPhone «Настройки» → «Календарь» → «Учетные записи» → «Другое» → «Подписной календарь».\n\n" +
+		"Календарь обновляется сам, но Google подтягивает изменения с задержкой до суток. " +
+		"Ссылка личная: если она попала к чужим, замените ее командой /calendar reset.",
+
+	"move.usage": "Укажите тему и тему, в которую ее вложить: /move <номер> <номер родителя>\n" +
+		"Например, уроки в модуль, а модули в курс. Вернуть тему на верхний уровень: /move <номер> 0",
+	"move.into_itself": "❌ Нельзя вложить тему в саму себя или в ее подтему.",
+	"move.too_deep":    "❌ Темы вкладываются не глубже %d уровней, например курс → модуль → урок.",
+	"move.top_level":   "📂 Тема \"%s\" теперь на верхнем уровне.",
+	"move.done":        "📂 Тема \"%s\" теперь подтема \"%s\". Номера тем в /list могли сдвинуться.",
+
+	"quiz.finished":   "Этот тест уже завершен. Начните новый: /quiz",
+	"quiz.question":   "🧠 Вопрос %d из %d\n\n",
+	"quiz.word_order": "🔀 Соберите предложение со словом «%s» (%s):\n\n%s",
+	"quiz.spelling":   "🔤 Соберите слово по буквам: «%s»\n\n%s",
+	"quiz.skip":       "🤷 Не знаю",
+	"quiz.erase":      "⌫ Стереть",
+	"quiz.stop":       "⏹ Завершить тест",
+
+	"scheduler.sm2_on": "🧠 Алгоритм повторений: SM-2. Интервалы снова растягиваются и сжимаются по вашим оценкам.",
+	"scheduler.leitner_on": "🗃 Алгоритм повторений: коробки Лейтнера.\n\n" +
+		"Каждая тема лежит в одной из %d коробок. Вспомнили тему - она переходит в следующую коробку, " +
+		"ошиблись - возвращается в первую. Темы из коробок повторяются через %s дней.\n" +
+		"Сколько тем в каждой коробке, видно в /stats.",
+	"scheduler.boxes":      "🗃 Коробки Лейтнера:\n",
+	"scheduler.to_sm2":     "🧠 Перейти на SM-2",
+	"scheduler.to_leitner": "🗃 Перейти на коробки Лейтнера",
+
+	"undo.button":       "↩️ Отменить",
+	"undo.hint":         "Передумали? Нажмите «↩️ Отменить» в течение %d мин.",
+	"undo.expired":      "⌛ Отменить уже нельзя: время на отмену истекло.",
+	"undo.exists":       "Не получилось восстановить: тема с таким названием уже создана заново. Переименуйте ее и нажмите «↩️ Отменить» еще раз.",
+	"undo.restored":     "↩️ Удаление отменено, восстановлено тем: %d. История повторений и статистика на месте.",
+	"undo.not_archived": "Тема \"%s\" уже не в архиве.",
+	"undo.unarchived":   "↩️ Архивация отменена, тема \"%s\" снова в списке.",
+
+	"cram.usage": "📚 Зубрежка перед экзаменом: все слова темы подряд, даже те, что повторять еще рано.\n\n" +
+		"Используйте: /cram <номер темы> или /cram <категория>\n" +
+		"Ответы не меняют график повторения слов, а результат сохраняется как у теста.",
+	"cram.not_found": "Не нашел ни темы, ни категории «%s».\n\n",
+	"cram.no_words":  "Нет слов для зубрежки (%s). Добавьте их из колод (/decks) или импортом из Anki (/anki).",
+	"cram.started":   "📚 Зубрежка: %s, %d %s. Ответы не меняют график повторения.\n\n%s",
+	"cram.topic":     "тема «%s»",
+	"cram.category":  "категория «%s»",
+	"plural.word":    "слово|слова|слов",
+
+	"board.off": "📌 Доска дня выключена. Напоминания снова приходят отдельными сообщениями.",
+	"board.on": "📌 Доска дня включена. Вместо новых напоминаний я обновляю одно закрепленное сообщение: " +
+		"что осталось повторить сегодня и что уже сделано.",
+	"board.title":      "📌 Повторения на %s\n\n",
+	"board.all_done":   "🎉 Все на сегодня повторено!",
+	"board.left":       "⏳ Осталось: %d\n",
+	"board.done":       "\nСделано сегодня: %d\n",
+	"board.off_button": "📌 Выключить доску дня",
+	"board.on_button":  "📌 Включить доску дня",
+
+	"newwords.usage": "Используйте: /newwords - сколько новых слов ждет, /newwords <0-%d> - новых слов в день, " +
+		"/newwords off - все новые слова сразу",
+	"newwords.all":               "✅ Новые слова приходят на повторение все сразу.",
+	"newwords.none":              "✅ Новые слова пока не приходят, только повторения уже знакомых.",
+	"newwords.limit":             "✅ Не больше %d %s в день.",
+	"plural.new_word":            "нового слова|новых слов|новых слов",
+	"newwords.title":             "🆕 Новые слова\n\n",
+	"newwords.per_day_unlimited": "В день: без ограничения\n",
+	"newwords.per_day":           "В день: %d\n",
+	"newwords.status":            "Сегодня уже: %d\nЖдут своей очереди: %d\n\n",
+	"newwords.footer": "Слова из больших колод приходят на повторение понемногу, вперемешку с повторениями уже знакомых. " +
+		"Изменить: /newwords <0-%d> или /newwords off",
+
+	"kind.phrase":         "фраза",
+	"kind.collocation":    "словосочетание",
+	"kind.word":           "слово",
+	"kind.to_phrase":      "🧩 Это фраза",
+	"kind.to_collocation": "🔗 Это словосочетание",
+	"kind.single_word":    "«%s» - одно слово, фразой или словосочетанием оно быть не может.",
+	"kind.switched":       "✅ «%s» теперь %s и считается в статистике отдельно: /stats",
+	"kind.words":          "🔤 Слова",
+	"kind.phrases":        "🧩 Фразы",
+	"kind.collocations":   "🔗 Словосочетания",
+	"kind.stats":          "%s: %d, изучается %d, выучено %d\n",
+
+	"topics.legend":        "🔄 повторить · 📊 история · ✏️ изменить · 📦 в архив",
+	"next_review.overdue":  "просрочено на %d %s",
+	"next_review.today":    "повторение сегодня",
+	"next_review.tomorrow": "повторение завтра",
+	"next_review.in":       "повторение через %d %s",
+
+	"forecast.chart":      "📊 Повторения на %d дней с %s: всего %d. Первый столбик - сегодня вместе с просроченными.",
+	"forecast.usage":      "Используйте: /forecast - календарь повторений, /forecast chart - график картинкой",
+	"forecast.title":      "🗓 Повторения на %d дней\n\n",
+	"forecast.weekdays":   "Пн Вт Ср Чт Пт Сб Вс",
+	"forecast.legend":     "\n%s нет  %s мало  %s средне  %s много  %s пик",
+	"forecast.over_limit": " или больше лимита (%d)",
+	"forecast.total":      "\n\nВсего: %d, сегодня: %d (вместе с просроченными)\n",
+	"forecast.busiest":    "Самые загруженные дни:\n",
+	"forecast.footer":     "\nГрафик картинкой: /forecast chart, лимит в день: /load",
+
+	"load.usage":             "Используйте: /load - прогноз повторений, /load <1-%d> - не больше повторений в день, /load off - без ограничения",
+	"load.off":               "✅ Ограничение снято, повторения больше не переносятся.",
+	"load.limit":             "✅ Не больше %d %s в день, лишние переносятся на следующие дни.",
+	"plural.review_genitive": "повторения|повторений|повторений",
+	"load.moved":             "\nПеренесено сейчас: %d.",
+	"load.title":             "📈 Прогноз повторений на %d дней\n\n",
+	"load.current":           "\nОграничение: %d в день. Изменить: /load <1-%d>, снять: /load off",
+	"load.hint":              "\nОграничить нагрузку: /load <1-%d> - лишние повторения перейдут на следующие дни",
+	"load.today":             "Сегодня",
+	"load.total":             "\nВсего: %d\n",
+
+	"story.unavailable": "📖 Истории недоступны: администратор бота не подключил генерацию текстов.",
+	"story.usage":       "Используйте: /story - история с вашими словами, /story on|off - присылать историю каждый день",
+	"story.off":         "📖 Ежедневная история выключена. Написать историю можно в любой момент: /story",
+	"story.on":          "📖 Каждый день в %d:00 я буду присылать короткую историю со словами, которые вам пора повторить.",
+	"story.no_words":    "📖 Для истории нужны слова. Добавьте их из готовых колод (/decks) или импортом из Anki (/anki).",
+	"story.failed":      "😔 Не получилось написать историю. Попробуйте чуть позже: /story",
+	"story.daily_title": "История дня",
+	"story.title":       "История с вашими словами",
+	"story.translation": "\n\n🇷🇺 Перевод (нажмите, чтобы открыть):\n",
+	"story.more":        "\n\nЕще одна история: /story",
+
+	"archive.usage":   "Указан неверный номер темы. Используйте: /archive <номер>",
+	"archive.already": "Тема \"%s\" уже в архиве.",
+	"archive.done": "📦 Тема \"%s\" перенесена в архив. История повторений сохранена, напоминаний по ней не будет.\n" +
+		"Вернуть тему можно в разделе «📦 Архив» или командой /archive.",
+	"archive.restored":  "♻️ Тема \"%s\" восстановлена из архива. Повторения продолжатся с того места, где вы остановились.",
+	"archive.subtopics": "\nВместе с ней - подтемы: %d.",
+	"archive.title":     "📦 Архив тем\n\n",
+	"archive.empty":     "В архиве пока нет тем.\n",
+	"archive.hint": "\nЧтобы убрать тему в архив без потери истории, используйте /archive <номер> " +
+		"(номер из списка тем) или кнопку «📦 Архивировать» в разделе удаления.",
+	"archive.restore_button": "♻️ Восстановить \"%s\"",
+	"archive.button":         "📦 Архивировать \"%s\"",
+
+	"direction.usage": "Используйте: /direction en|ru|both - направление карточек, " +
+		"/direction <номер темы> en|ru|both|default - свое направление для темы",
+	"direction.wrong_topic":   "Указан неверный номер темы. ",
+	"direction.changed":       "✅ Карточки: %s.",
+	"direction.topic_changed": "✅ Карточки темы \"%s\": %s.",
+	"direction.added":         " Новых карточек с русского на английский: %d, они уже ждут в /review.",
+	"direction.reverse":       "🇷🇺→🇬🇧 с русского на английский",
+	"direction.both":          "🔁 в обе стороны",
+	"direction.forward":       "🇬🇧→🇷🇺 с английского на русский",
+	"direction.title":         "🔁 Направление карточек\n\nПо умолчанию: %s\n",
+	"direction.own":           "\nСвое направление у тем:\n",
+	"direction.hint": "\nУ карточек с русского на английский свой график повторений: слово, которое легко узнать, " +
+		"бывает трудно вспомнить самому.\n\n",
+
+	"maintenance.usage":  "Указан неверный номер темы. Используйте: /maintenance <номер>",
+	"button.topic_stats": "📊 Статистика темы",
+	"maintenance.on": "♾ Поддерживающие повторения темы \"%s\" включены: после %d-го повторения тема не закончится, " +
+		"а будет возвращаться через %d, потом каждые %d дней.",
+	"maintenance.off": "Поддерживающие повторения темы \"%s\" выключены: тема закончится после %d-го повторения.",
+	"maintenance.none": "♾ Поддерживающих повторений пока нет ни у одной темы.\n\n" +
+		"После цикла из %d повторений тема заканчивается. Чтобы она возвращалась раз в несколько месяцев, " +
+		"используйте /maintenance <номер>.",
+	"maintenance.list":        "♾ Поддерживающие повторения у тем:\n",
+	"maintenance.toggle_hint": "\nВключить или выключить: /maintenance <номер>",
+	"maintenance.enable":      "♾ Включить поддерживающие повторения",
+	"maintenance.disable":     "♾ Выключить поддерживающие повторения",
+
+	"digest.all_done": "🎉 Все на сегодня повторено!",
+	"digest.off":      "📰 Дайджест выключен. Напоминания снова приходят отдельными сообщениями.",
+	"digest.on": "📰 Дайджест включен. Каждый день в %s вы получите одно сообщение " +
+		"с темами и словами к повторению и вашей серией.",
+	"digest.title":        "☀️ Ваш дайджест на сегодня\n\n",
+	"digest.topics":       "📚 Темы к повторению: %d\n",
+	"digest.words":        "🃏 Слова к повторению: %d\n",
+	"digest.topic":        "%d. %s - %d-е повторение\n",
+	"digest.page":         "\nСтраница %d из %d",
+	"digest.done_button":  "✅ Повторил тему \"%s\"",
+	"digest.words_button": "🃏 Повторить слова (%d)",
+	"digest.disable":      "📰 Выключить дайджест",
+	"digest.enable":       "📰 Включить дайджест",
+
+	"intervals.intensive": "⚡ Интенсивный",
+	"intervals.standard":  "📅 Стандартный",
+	"intervals.relaxed":   "🌿 Спокойный",
+	"intervals.usage": "Укажите интервалы в днях через запятую, например: /intervals 1, 3, 7, 14, 30\n" +
+		"Не больше %d значений от 1 до %d, каждое не меньше предыдущего.",
+	"intervals.ask":           "✏️ Отправьте свои интервалы в днях через запятую, например: 1, 3, 7, 14, 30",
+	"stale.intervals":         "Кнопка устарела. Откройте /intervals заново.",
+	"intervals.retry":         "❌ Не удалось разобрать интервалы. Попробуйте еще раз или нажмите \"Отмена\".\n\n",
+	"intervals.invalid":       "❌ Не удалось разобрать интервалы.\n\n",
+	"intervals.saved":         "✅ График повторений: %s\nИнтервалы, дней: %s\n\nНовый график применяется к следующим повторениям.",
+	"intervals.title":         "🗓 График повторений\n\n",
+	"intervals.current":       "Сейчас: %s (%s)\n\n",
+	"intervals.hint":          "\nОценки при повторении растягивают или сжимают эти интервалы.\n",
+	"intervals.custom":        "✏️ Свой",
+	"intervals.custom_button": "✏️ Свой график",
+
+	"edit.choose":       "✏️ Выберите тему, которую хотите переименовать:",
+	"edit.usage":        "Указан неверный номер темы. Используйте: /edit <номер>",
+	"edit.ask":          "✏️ Текущее название: \"%s\"\n\nОтправьте новое название темы:",
+	"edit.empty":        "❌ Название темы не может быть пустым. Отправьте новое название или нажмите \"Отмена\".",
+	"edit.taken":        "❌ У вас уже есть тема \"%s\". Придумайте другое название или нажмите \"Отмена\".",
+	"edit.done":         "✅ Тема \"%s\" переименована в \"%s\".",
+	"edit.button":       "✏️ Редактировать \"%s\"",
+	"describe.usage":    "Укажите тему и описание: /describe <номер> <описание>\nУдалить описание: /describe <номер> -",
+	"describe.too_long": "Описание слишком длинное. Максимум %d символов.",
+	"describe.saved":    "📝 Описание темы \"%s\" сохранено.",
+	"describe.removed":  "📝 Описание темы \"%s\" удалено.",
+
+	"goal.usage": "Используйте: /goal <1-%d> - повторений в день, /goal off - без цели",
+	"goal.off":   "🎯 Дневная цель выключена. Чтобы серия продолжалась, достаточно одного повторения в день.",
+	"goal.set": "🎯 Дневная цель: %d %s в день. Считаются карточки слов (/review) и повторения тем. " +
+		"Серия растет в те дни, когда цель выполнена.",
+	"goal.title":     "🎯 Дневная цель\n\n",
+	"goal.hint":      "\nИзменить цель: /goal <1-%d>, выключить: /goal off",
+	"goal.protected": "🛡 Сегодня нечего повторять - серия не прервется.\n",
+	"goal.no_goal":   "📅 Сегодня: %d %s (дневная цель не задана: /goal)\n",
+	"goal.progress":  "📅 Сегодня: %d из %d %s\n",
+	"goal.met":       "✅ Цель на сегодня выполнена!\n",
+	"goal.streak":    "%s Серия: %d %s подряд\n",
+
+	"practice.unavailable":  "💬 Разговорная практика недоступна: администратор бота не подключил языковую модель.",
+	"practice.usage":        "Используйте: /practice - разговор с вашими словами, /practice stop - закончить разговор",
+	"practice.no_words":     "💬 Для разговора нужны слова. Добавьте их из готовых колод (/decks) или импортом из Anki (/anki).",
+	"practice.start_failed": "😔 Не получилось начать разговор. Попробуйте чуть позже: /practice",
+	"practice.title":        "Разговорная практика",
+	"practice.intro":        "\n\nОтвечайте по-английски и постарайтесь использовать слова:\n",
+	"practice.credit":       "Каждое слово, употребленное правильно, засчитывается как повторение.\n\n",
+	"practice.text_only":    "💬 Отвечайте текстом по-английски или нажмите «🏁 Закончить».",
+	"practice.reply_failed": "😔 Не получилось ответить. Отправьте сообщение еще раз или нажмите «🏁 Закончить».",
+	"practice.credited":     "✅ Засчитано: ",
+	"practice.left":         "\n\n🔤 Осталось: ",
+	"practice.none":         "💬 Разговора нет. Начать новый: /practice",
+	"practice.over":         "Разговор окончен",
+	"practice.used":         "\nИспользовано слов: %d из %d",
+	"practice.used_words":   "\n✅ Засчитаны как повторения: ",
+	"practice.missed":       "\n🔁 Не прозвучали: %s - они остаются в /review",
+	"practice.more":         "\n\nЕще один разговор: /practice",
+	"practice.stop":         "🏁 Закончить",
+
+	"channels.email":           "📧 Почта",
+	"channels.webhook":         "🔗 Вебхук",
+	"channels.none":            "Другие каналы напоминаний не настроены, напоминания приходят только в Telegram.",
+	"channels.unknown":         "Нет канала %q. Доступны: %s",
+	"channels.off":             "%s: напоминания сюда больше не приходят.",
+	"channels.already_off":     "%s: канал и так выключен.",
+	"channels.invalid_address": "%s: неверный адрес %s",
+	"channels.on":              "✅ %s: напоминания будут приходить и на %s.\nПроверьте доставку командой /channels test.",
+	"channels.title":           "📨 Напоминания о повторениях приходят в Telegram и на эти каналы:\n\n",
+	"channels.disabled":        "выключен",
+	"channels.usage":           "Используйте:\n",
+	"channels.usage_email":     "/channels email <адрес> - присылать напоминания на почту\n",
+	"channels.usage_webhook":   "/channels webhook <https-URL> - отправлять напоминания POST-запросом в JSON\n",
+	"channels.usage_other":     "/channels %s <адрес>\n",
+	"channels.usage_off":       "/channels <канал> off - выключить канал\n",
+	"channels.usage_test":      "/channels test - отправить пробное напоминание",
+	"channels.no_channels":     "Каналы не включены. Добавьте канал командой /channels.",
+	"channels.test_title":      "📨 Пробное напоминание:\n\n",
+	"channels.test_disabled":   "%s: канал отключен администратором\n",
+	"channels.test_failed":     "%s: ❌ не доставлено (%v)\n",
+	"channels.test_sent":       "%s: ✅ отправлено на %s\n",
+
+	"attach.usage": "Пожалуйста, укажите номер темы: /attach <номер>",
+	"attach.start": "📎 Материалы темы \"%s\": %d из %d\n\n" +
+		"Отправьте ссылку, фото или документ - они будут приходить вместе с напоминанием об этой теме. " +
+		"Можно отправить несколько, затем нажмите \"Готово\".",
+	"attach.show":  "👀 Показать материалы",
+	"attach.clear": "🗑 Удалить все материалы",
+	"attach.limit": "❌ К теме можно прикрепить не больше %d материалов. " +
+		"Удалите старые: /attach <номер> → \"🗑 Удалить все материалы\".",
+	"attach.added":   "✅ Прикреплено к теме \"%s\" (%d из %d). Отправьте еще или нажмите \"Готово\".",
+	"attach.invalid": "Отправьте ссылку (http:// или https://), фото или документ, либо нажмите \"Готово\".",
+	"attach.empty":   "К этой теме пока ничего не прикреплено. Добавить: /attach <номер>",
+	"attach.header":  "📎 Материалы темы \"%s\"",
+	"attach.cleared": "🗑 Удалено материалов: %d. Отправьте новые или нажмите \"Готово\".",
+	"attach.done":    "✅ Готово. Материалы придут вместе с напоминанием о теме, кнопкой \"📎 Материалы\".",
+
+	"time.last_hour": "Должно остаться хотя бы одно время напоминаний. Чтобы отключить их совсем, используйте /notify off.",
+	"time.too_many":  "Можно выбрать не больше %d напоминаний в день.",
+	"time.title":     "🕒 Время напоминаний\n\n",
+	"time.hours":     "Напоминания: %s\n",
+	"time.quiet":     "Тихие часы: %s\n\n",
+	"time.hint": "Нажимайте на часы, чтобы добавить или убрать напоминание (не больше %d в день). " +
+		"🌙 отмечает тихие часы - в это время напоминания не приходят.",
+	"time.quiet_button":     "🌙 Тихие часы",
+	"time.quiet_off_button": "🔔 Без тихих часов",
+	"time.quiet_start":      "🌙 Тихие часы\n\nС какого часа не присылать напоминания?",
+	"time.quiet_end":        "🌙 Тихие часы начинаются в %d:00.\n\nДо какого часа не присылать напоминания?",
+
+	"merge.usage": "Укажите, какую тему с какой объединить: /merge <номер> <номер>\n" +
+		"Первая тема вливается во вторую и удаляется.",
+	"merge.same":   "Укажите две разные темы.",
+	"merge.button": "🔀 Объединить",
+	"merge.none": "✅ Похожих тем не найдено.\n\n" +
+		"Объединить любые две темы: /merge <номер> <номер> - первая тема вливается во вторую.",
+	"merge.suggest": "🔀 Похоже, эти темы повторяют друг друга:\n\n%s" +
+		"\nНажмите кнопку, чтобы объединить пару, или используйте /merge <номер> <номер>.",
+	"stale.merge":     "Кнопка устарела. Откройте /merge заново.",
+	"merge.not_found": "Тема не найдена. Возможно, она уже удалена или объединена.",
+	"merge.done":      "🔀 Тема \"%s\" объединена с \"%s\". Номера тем в /list могли сдвинуться.",
+	"merge.subtopic":  "❌ Нельзя объединить тему с ее подтемой. Сначала вынесите подтему: /move <номер> 0",
+	"merge.too_deep":  "❌ После объединения подтемы оказались бы глубже %d уровней.",
+	"merge.confirm": "🔀 Объединить тему \"%s\" с \"%s\"?\n\n" +
+		"Слова, история повторений, статистика, материалы и подтемы перейдут в \"%s\", " +
+		"а тема \"%s\" будет удалена.",
+
+	"plans.usage":          "Используйте: /plans - готовые планы по грамматике, /plans <номер> - описание плана",
+	"plans.not_found":      "План не найден. Откройте список заново: /plans",
+	"plans.exists":         "Тема «%s» уже есть в вашем списке: /list",
+	"plans.created_prefix": "✅ Тема ",
+	"plans.created_suffix": " добавлена по готовому плану.\n",
+	"plans.first_review":   "📅 Первое повторение: %s\n",
+	"plans.schedule":       "🗓 График, дней: %s\n",
+	"plans.examples":       "📝 Примеров в материалах темы: %d\n\n",
+	"plans.examples_hint":  "Примеры придут вместе с напоминанием о теме, кнопкой \"📎 Материалы\".",
+	"plans.back":           "⬅️ Назад к планам",
+	"plans.title":          "📘 Готовые планы по грамматике\n\n",
+	"plans.hint": "\nПлан создает тему с описанием правила, примерами и своим графиком повторений: " +
+		"чем сложнее тема, тем чаще она возвращается в первые недели.",
+	"plans.preview":          "📖 %s\nСложность: %s\n\n%s\n\nПримеры:\n",
+	"plans.preview_schedule": "\n🗓 График повторений, дней: %s",
+	"plans.create":           "➕ Создать тему",
+
+	"stale.stats":                  "Кнопка устарела. Откройте /stats заново.",
+	"topic_stats.intervals_button": "🗓 Свой график повторений",
+	"topic_stats.all_button":       "📊 Вся статистика",
+	"topic_stats.title":            "📊 Тема ",
+	"topic_stats.no_reviews":       "Повторений пока нет.",
+	"topic_stats.maintenance":      "♾ Цикл пройден, следующее поддерживающее повторение: %s\n",
+	"topic_stats.current":          "🔄 Текущее повторение: №%d из %d\n",
+	"topic_stats.overdue":          " (просрочено)",
+	"topic_stats.next":             "📅 Следующее повторение: %s\n",
+	"topic_stats.finished":         "🎉 Все повторения темы завершены\n",
+	"topic_stats.lateness":         "⏱ Средняя задержка: %s\n",
+	"topic_stats.own_intervals":    "🗓 Свой график: %s дн.\n",
+	"topic_stats.archived":         "📦 Тема в архиве\n",
+	"topic_stats.stalled":          "⏸ Тема остановлена, напоминаний нет\n",
+	"topic_stats.muted":            "🔕 Напоминания по теме выключены\n",
+	"topic_stats.finish":           "🏁 Цикл из %d повторений закончится примерно %s\n",
+	"topic_stats.reviews":          "\n🗓 Повторения:",
+	"topic_stats.done":             "\n✅ №%d - %s, %s",
+	"topic_stats.planned":          "\n⏳ №%d - запланировано на %s",
+	"topic_stats.on_time":          "вовремя",
+	"topic_stats.late":             "на %d %s позже",
+
+	"report.days": "по воскресеньям|по понедельникам|по вторникам|по средам|по четвергам|по пятницам|по субботам",
+	"report.usage": "Используйте: /report - настройки отчета, /report now - отчет за последнюю неделю, " +
+		"/report on|off - присылать отчет каждую неделю, /report <пн-вс> <0-23> - день недели и час отчета",
+	"report.saved": "✅ Сохранено.\n\n",
+	"report.title": "📬 Еженедельный отчет\n\n",
+	"report.on": "Приходит %s в %02d:00: повторения за неделю, выученные слова, серия дней, " +
+		"лучшие и отстающие темы и сравнение с прошлой неделей.\n\nВыключить: /report off",
+	"report.off":        "Выключен. Включить: /report on",
+	"report.hint":       "\nДень и час: /report <пн-вс> <0-23>, например /report вс 19\nОтчет прямо сейчас: /report now",
+	"report.settings":   "\n\nНастроить отчет: /report",
+	"report.week":       "📬 Отчет за неделю %s - %s\n\n",
+	"report.completed":  "✅ Повторений тем: %d %s\n",
+	"report.reviews":    "🎯 Всего повторений (темы и карточки): %d %s\n",
+	"report.learned":    "📚 Выучено слов: %d %s\n",
+	"report.streak":     "🔥 Серия: %d %s\n",
+	"report.best":       "\n🏆 Лучшие темы:\n",
+	"report.worst":      "\n🐢 Отстают:\n",
+	"report.topic":      "• %s - %d из %d\n",
+	"report.delta_hint": "\nВ скобках - разница с прошлой неделей.",
+
+	"topic_intervals.usage": "Укажите интервалы в днях через запятую, например 1, 2, 4, " +
+		"«экзамен 10», чтобы уложить ваш график в 10 дней, или «off», чтобы вернуть общий график.\n" +
+		"Не больше %d значений от 1 до %d, каждое не меньше предыдущего.",
+	"topic_intervals.wrong_number":       "Указан неверный номер темы. Используйте: /topicintervals <номер> 1, 2, 4",
+	"topic_intervals.ask":                "\n\n✏️ Отправьте новый график.",
+	"topic_intervals.exam":               "Укажите число дней до экзамена от 1 до %d, например: экзамен 10",
+	"topic_intervals.invalid":            "Не удалось разобрать интервалы.\n\n",
+	"topic_intervals.reset_prefix":       "✅ Тема ",
+	"topic_intervals.reset_suffix":       " снова повторяется по общему графику из /intervals.",
+	"topic_intervals.saved_prefix":       "✅ Свой график темы ",
+	"topic_intervals.saved_suffix":       ": %s дн.",
+	"topic_intervals.moved":              "\n📅 Повторение №%d перенесено на %s.",
+	"topic_intervals.title":              "🗓 График повторений темы ",
+	"topic_intervals.current_difficulty": "Сейчас: общий график из /intervals с поправкой на сложность «%s» (%s дн.)\n\n",
+	"topic_intervals.current_common":     "Сейчас: общий график из /intervals (%s дн.)\n\n",
+	"topic_intervals.current_own":        "Сейчас: свой график (%s дн.)\n\n",
+	"topic_intervals.item":               "%d. %s: %s дн.\n",
+	"topic_intervals.none": "🗓 Все темы повторяются по общему графику из /intervals.\n\n" +
+		"Чтобы задать теме свой, например перед экзаменом, используйте /topicintervals <номер> 1, 2, 4 " +
+		"или /topicintervals <номер> экзамен 10.",
+	"topic_intervals.list":      "🗓 Свой график повторений у тем:\n",
+	"topic_intervals.list_hint": "\nИзменить: /topicintervals <номер> <интервалы>, вернуть общий: /topicintervals <номер> off",
+
+	"decks.usage":     "Используйте: /decks - каталог колод, /decks <номер> - слова колоды",
+	"decks.not_found": "Колода не найдена. Откройте каталог заново: /decks",
+	"decks.subscribed": "✅ Вы подписаны на колоду \"%s\".\n\n🃏 В повторение добавлено %d %s, они в теме \"%s\". " +
+		"Новые слова приходят по нескольку в день (/newwords). Начните прямо сейчас: /review",
+	"decks.subscribed_none": "✅ Вы подписаны на колоду \"%s\". Новых слов в ней пока нет, все слова уже в повторении: /review",
+	"decks.admins_only":     "Публиковать колоды могут только администраторы.",
+	"decks.publish_usage":   "Используйте: /decks publish <номер темы> [описание]",
+	"decks.empty_topic":     "В теме \"%s\" нет слов. Добавьте их, например импортом из Anki: /anki",
+	"decks.published":       "📚 Колода \"%s\" опубликована.\nДобавлено слов: %d, всего в колоде: %d.",
+	"decks.title":           "📚 Каталог колод\n\n",
+	"decks.none":            "Колод пока нет.",
+	"decks.hint": "\nОткройте колоду, чтобы посмотреть слова и подписаться. После подписки слова колоды " +
+		"появятся в отдельной теме и в повторении карточками (/review).",
+	"decks.more":      "... и еще %d\n",
+	"decks.subscribe": "➕ Подписаться",
+	"decks.add_new":   "🔄 Добавить новые слова",
+	"decks.back":      "⬅️ Назад к колодам",
+
+	"overdue.remind":         "напоминать каждый день",
+	"overdue.reschedule":     "перенести повторение на новый срок",
+	"overdue.reset":          "начать тему заново с повторения #1",
+	"overdue.stall":          "остановить тему и раз в неделю спрашивать, вернуть ее или убрать в архив",
+	"overdue.usage":          "Используйте: /overdue <remind|reschedule|reset|stall> [дней, 1-%d]",
+	"overdue.saved":          "✅ Сохранено.\n\n",
+	"overdue.title":          "⏰ Просроченные повторения\n\n",
+	"overdue.current_remind": "Сейчас: %s, сколько бы дней ни прошло.\n\n",
+	"overdue.current":        "Сейчас: если повторение просрочено больше чем на %d %s, %s.\n\n",
+	"overdue.options":        "Варианты:\n",
+	"overdue.days_hint":      "\nЧерез сколько дней просрочки срабатывать: /overdue <вариант> <1-%d>",
+	"overdue.rescheduled":    "📅 Эти повторения были просрочены, я перенес их на новый срок:",
+	"overdue.restarted":      "🔄 Эти темы давно не повторялись, я начал их заново с повторения #1:",
+	"overdue.stalled": "⏸ Эти темы давно не повторялись, я больше не буду о них напоминать. " +
+		"Раз в неделю спрошу, вернуть их или убрать в архив:",
+	"overdue.settings":       "\n\nНастроить: /overdue",
+	"overdue.stalled_title":  "⏸ Эти темы остановлены, потому что давно не повторялись:\n\n",
+	"overdue.archive_button": "📦 В архив",
+	"overdue.stalled_hint":   "\n🔄 - начать тему заново с повторения #1, 📦 - убрать в архив.",
+	"overdue.not_stalled":    "Тема \"%s\" уже не остановлена.",
+	"overdue.revived":        "🔄 Тема \"%s\" начата заново: первое повторение завтра, напоминания включены.",
+
+	"anki.usage": "🗂 Импорт и экспорт Anki\n\n" +
+		"📥 Импорт: отправьте мне файл колоды - .apkg (в Anki: Файл → Экспорт → «Колода Anki», " +
+		"отметьте «Поддержка старых версий Anki») или .txt («Записи в виде простого текста»). " +
+		"Каждая колода станет темой, а слова попадут в повторение карточками (/review).\n\n" +
+		"Поля записей сейчас читаются так: %s. Чтобы сопоставить их иначе, добавьте к файлу подпись, " +
+		"например: word=Front, translation=Back, examples=Example. Поле можно указать по названию или по номеру.\n\n" +
+		"📤 Экспорт:\n" +
+		"/anki export - Выученные слова в колоду .apkg\n" +
+		"/anki export all - Все ваши слова\n" +
+		"Добавьте txt, чтобы получить текстовый файл вместо .apkg: /anki export all txt",
+	"anki.export_usage": "Используйте: /anki export [all] [txt]",
+	"anki.no_words":     "Пока нечего выгружать: у вас нет слов.",
+	"anki.no_learned":   "Пока нет выученных слов. Чтобы выгрузить все слова, отправьте /anki export all",
+	"anki.exported":     "🗂 %d %s для Anki. Откройте файл в Anki: Файл → Импорт.",
+	"anki.unsupported":  "Я умею импортировать колоды Anki в файлах .apkg и .txt. Подробнее: /anki",
+	"anki.too_big":      "Файл слишком большой: Telegram позволяет ботам скачивать файлы до %d МБ.",
+	"anki.bad_caption":  "Не удалось разобрать подпись к файлу. Укажите поля так: word=Front, translation=Back",
+	"anki.new_format": "Этот файл сохранен в новом формате Anki. Экспортируйте колоду заново, " +
+		"отметив «Поддержка старых версий Anki» (Support older Anki versions).",
+	"anki.unreadable": "Не удалось прочитать файл. Пришлите экспорт из Anki: .apkg или .txt. Подробнее: /anki",
+	"anki.no_notes": "В файле не нашлось записей со словом и переводом. " +
+		"Поля сейчас читаются так: %s. Укажите другие поля в подписи к файлу, например: word=Front, translation=Back",
+	"anki.imported": "✅ Импорт из Anki завершен\n\n📚 Тем: %d\n🃏 Новых слов: %d из %d\n\n" +
+		"Слова уже ждут вас в повторении карточками: /review",
+	"anki.topic_limit": "В файле %d новых колод, а тем можно создать еще %d (лимит %d). " +
+		"Удалите ненужные темы или объедините колоды в Anki.",
+
+	"review.nothing_due":   "🎉 Сейчас нет слов для повторения.",
+	"review.inactive":      "Эта карточка уже неактивна. Отправьте /review, чтобы продолжить повторение.",
+	"review.all_done":      "🎉 Все слова на сегодня повторены!",
+	"review.front_reverse": "🃏 Карточка\n\n🇷🇺 %s\n\nВспомните английское слово и нажмите «Перевернуть».",
+	"review.front":         "🃏 Карточка\n\n🇬🇧 %s\n\nВспомните перевод и нажмите «Перевернуть».",
+	"review.card":          "🃏 Карточка\n\n",
+	"review.back_reverse":  "\nНасколько легко вы вспомнили слово?",
+	"review.back":          "\nНасколько легко вы вспомнили перевод?",
+	"review.flip":          "🔄 Перевернуть",
+	"word.forms":           "🔤 Формы: %s",
+	"word.synonyms":        "🟰 Синонимы: %s",
+	"word.antonyms":        "↔️ Антонимы: %s",
+	"word.examples":        "\n📝 Примеры:\n%s",
+
+	"search.usage":             "🔎 Укажите, что найти: /search <запрос>\nИщу по названиям и описаниям тем, по словам, переводам и примерам.",
+	"search.too_long":          "Запрос слишком длинный. Максимум %d символов.",
+	"search.nothing":           "🔎 По запросу «%s» ничего не найдено.",
+	"search.title":             "🔎 Результаты по запросу «%s»\n",
+	"search.topics":            "\n📚 Темы:\n",
+	"search.archived":          " (в архиве)",
+	"search.words":             "\n🔤 Слова:\n",
+	"search.stale":             "Кнопка устарела. Повторите поиск: /search <запрос>",
+	"search.confirm_topic":     "⚠️ Удалить тему \"%s\" вместе с подтемами и историей повторений?\nЧтобы сохранить историю, уберите тему в архив: /archive",
+	"search.topic_deleted":     "🗑 Тема \"%s\" удалена.\n\n%s",
+	"search.edit_word":         "✏️ %s - %s\n\nОтправьте новый перевод:",
+	"search.confirm_word":      "⚠️ Удалить слово \"%s\" вместе с прогрессом повторения?",
+	"search.word_deleted":      "🗑 Слово \"%s\" удалено.",
+	"search.confirm_delete":    "🗑 Да, удалить",
+	"search.empty_translation": "❌ Перевод не может быть пустым. Отправьте новый перевод или нажмите \"Отмена\".",
+	"search.translation_saved": "✅ Новый перевод сохранен: %s",
+
+	"wod.usage": "Используйте: /wordofday - настройки, /wordofday now - слово прямо сейчас, " +
+		"/wordofday on|off - присылать каждый день, /wordofday new - из ваших новых слов, " +
+		"/wordofday <номер колоды> - из колоды каталога /decks",
+	"wod.no_deck":         "Колоды с номером %d нет. Номера колод - в каталоге: /decks",
+	"wod.saved":           "✅ Сохранено.",
+	"wod.title":           "🌅 Слово дня\n\n",
+	"wod.enabled":         "Приходит каждый день в %d:00\n",
+	"wod.disabled":        "Выключено\n",
+	"wod.from_deck":       "Откуда: колода «%s»\n\n",
+	"wod.from_own":        "Откуда: ваши новые слова, которые скоро придут на повторение\n\n",
+	"wod.about":           "Каждое слово приходит с примерами, произношением и мини-тестом: угадайте перевод.\n\n",
+	"wod.none_left":       "🌅 Новых слов для слова дня не осталось. Выберите колоду из каталога: /wordofday <номер колоды>",
+	"wod.dont_know":       "🤷 Не знаю",
+	"wod.stale":           "Кнопка устарела.",
+	"wod.name":            "Слово дня",
+	"wod.of_deck":         " из колоды «%s»",
+	"wod.question_prefix": "\n\n🧠 Как переводится ",
+	"wod.right":           "\n\n✅ Верно!",
+	"wod.wrong":           "\n\n❌ Не угадали, правильный ответ: %s",
+	"wod.settings":        "\n\nНастройки слова дня: /wordofday",
+
+	"broadcast.done":     "✅ Рассылка завершена.\nДоставлено: %d из %d\nНе доставлено: %d",
+	"broadcast.progress": "📣 Идет рассылка: %d из %d\nНе доставлено: %d",
+	"admin.usage":        "Используйте: /admin stats, /admin backup или /admin undeliverable",
+	"admin.stats": "🛠 Статистика бота\n\n" +
+		"👥 Пользователей: %d\n" +
+		"🔔 С включенными уведомлениями: %d\n" +
+		"🔕 Отказались от рассылок: %d\n" +
+		"🚫 Заблокировали бота или удалили аккаунт: %d\n\n" +
+		"📚 Тем: %d (в архиве: %d)\n" +
+		"🔄 Повторений: %d (выполнено: %d)\n\n" +
+		"📨 Сообщений с запуска: отправлено %d, в очереди %d, повторов после 429: %d, потеряно: %d\n" +
+		"📮 Уведомлений ждут повторной отправки: %d, недоступных пользователей: %d (/admin undeliverable)",
+	"admin.all_delivered":         "📮 Все уведомления доставляются.",
+	"admin.undeliverable":         "📮 Пользователи, до которых не доходят уведомления:\n",
+	"admin.blocked":               "🚫 Бот заблокирован: %d уведомлений подряд\n",
+	"admin.failed":                "❌ Не доставлено после повторов: %d\n",
+	"admin.disabled":              "🔕 Уведомления выключены ботом\n",
+	"admin.inactive":              "💤 Неактивен, пока снова не запустит бота командой /start\n",
+	"admin.error":                 "Ошибка: %s\n",
+	"admin.latest":                "\nПоказаны последние %d.",
+	"admin.backup_off":            "Резервное копирование не настроено: укажите BACKUP_DIR или BACKUP_S3_BUCKET.",
+	"admin.backup_unsupported":    "Резервные копии делаются только для SQLite, для PostgreSQL используйте pg_dump.",
+	"admin.backup_done":           "💾 Резервная копия %s сохранена (%.1f МБ).",
+	"admin.backup_cleanup_failed": "\n⚠️ Не удалось удалить старые копии, подробности в логах.",
+	"broadcast.usage":             "Укажите текст рассылки: /broadcast <текст>",
+	"broadcast.preview":           "📣 Рассылку получат %d пользователей, %d отказались от рассылок.\n\n%s",
+	"broadcast.send":              "📣 Отправить",
+	"broadcast.admins_only":       "Рассылка доступна только администраторам.",
+	"broadcast.not_found":         "Рассылка не найдена. Отправьте /broadcast <текст> заново.",
+	"broadcast.running":           "Предыдущая рассылка еще не закончилась. Дождитесь отчета о ней.",
+
+	"plural.topic":           "тема|темы|тем",
+	"bulk.delete":            "🗑 Удалить",
+	"bulk.archive":           "📦 В архив",
+	"bulk.mute":              "🔕 Без напоминаний",
+	"bulk.restore":           "🔔 Вернуть",
+	"bulk.reset":             "🔄 Сбросить",
+	"bulk.category":          "📁 В категорию",
+	"bulk.reset_selection":   "Выбор тем сброшен. Откройте «Массовые действия» заново.",
+	"bulk.select_first":      "Сначала выберите хотя бы одну тему.",
+	"bulk.confirm_delete":    "⚠️ Удалить выбранные темы (%d) вместе с историей повторений?\nОтменить удаление можно будет в течение %d мин.",
+	"bulk.back_to_selection": "⬅️ Назад к выбору",
+	"bulk.ask_category":      "📁 Напишите название категории для выбранных тем.\nЧтобы убрать темы из категории, отправьте «-».",
+	"bulk.archived":          "📦 Перенесено в архив",
+	"bulk.muted":             "🔕 Напоминания выключены",
+	"bulk.restored":          "🔔 Возвращено из архива, напоминания включены",
+	"bulk.restarted":         "🔄 Повторения начаты заново",
+	"bulk.deleted":           "🗑 Удалено: %d %s.\n\n%s",
+	"bulk.category_empty":    "❌ Название категории не может быть пустым. Напишите текст или нажмите \"Отмена\".",
+	"bulk.category_too_long": "❌ Название категории слишком длинное. Максимум %d символов.",
+	"bulk.categorized":       "📁 Перенесено в категорию «%s»: %d %s.",
+	"bulk.uncategorized":     "📁 Убрано из категорий: %d %s.",
+	"bulk.title":             "🧰 Массовые действия\n\nОтметьте темы и выберите действие.\nВыбрано: %d",
+
+	"plural.question": "вопрос|вопроса|вопросов",
+
+	"quiz.need_words":  "🧠 Для теста нужно хотя бы 2 слова. Добавьте слова из готовых колод (/decks) или импортом из Anki (/anki).",
+	"quiz.all_words":   "📚 Все слова (%d)",
+	"quiz.hard_words":  "🔥 Трудные слова (%d)",
+	"quiz.title":       "🧠 Тест на знание слов\n\nВыберите слова для теста:",
+	"quiz.hard_hint":   "\n\n🔥 Трудные слова - тренировка, где чаще всего попадаются слова, которые вам даются тяжелее всего по повторениям и тестам.",
+	"quiz.topic_empty": "В этой теме больше нет слов. Начните тест заново: /quiz",
+	"quiz.count_all":   "Все (%d)",
+	"quiz.ask_count":   "🧠 Сколько вопросов будет в тесте?",
+	"quiz.modes": "🧠 Как отвечать на вопросы?\n\n" +
+		"🔘 Кнопками - выберите перевод под сообщением, бот сразу покажет, верно ли.\n" +
+		"📊 Опросами - каждый вопрос придет опросом-викториной Telegram.\n" +
+		"⌨️ Вводом - напишите перевод сами. Небольшие опечатки прощаются, а ответы влияют на график повторения слов (/review).\n" +
+		"🧩 В контексте - впишите слово, пропущенное в примере употребления. Подходят слова с примерами.\n" +
+		"🟰 Синонимы - выберите английское слово, близкое по смыслу. Подходят слова с синонимами.\n" +
+		"🔤 По буквам - соберите английское слово по переводу из перемешанных букв. Ответы влияют на график повторения, за почти верное слово - частично.\n" +
+		"🔀 Порядок слов - соберите предложение из примера употребления, нажимая на слова по порядку. Подходят слова с примерами.",
+	"quiz.mode_buttons":          "🔘 Кнопками",
+	"quiz.mode_poll":             "📊 Опросами",
+	"quiz.mode_text":             "⌨️ Вводом перевода",
+	"quiz.mode_context":          "🧩 Слово в контексте",
+	"quiz.mode_synonym":          "🟰 Синонимы",
+	"quiz.mode_spelling":         "🔤 По буквам",
+	"quiz.mode_word_order":       "🔀 Порядок слов",
+	"quiz.modes_voice":           "\n🎙 Голосом - произнесите английское слово по его переводу голосовым сообщением, бот проверит произношение и учтет ответ в графике повторения.",
+	"quiz.mode_voice":            "🎙 Голосом",
+	"quiz.modes_listening":       "\n🎧 На слух - бот произносит слово или пример с ним, выберите перевод кнопками.",
+	"quiz.mode_listening":        "🎧 На слух",
+	"quiz.modes_sentence":        "\n✍️ Свое предложение - напишите предложение со словом. Языковая модель проверит грамматику и употребление слова, объяснит ошибки, а оценка повлияет на график повторения.",
+	"quiz.mode_sentence":         "✍️ Свое предложение",
+	"quiz.modes_definition":      "\n📘 По определению - бот показывает определение на английском, а вы выбираете слово кнопками или пишете его сами. Определения пишет языковая модель, первый тест по новым словам готовится дольше.",
+	"quiz.mode_definition":       "📘 Выбрать слово",
+	"quiz.mode_definition_text":  "📘 Написать слово",
+	"quiz.voice_off":             "Ответы голосом сейчас недоступны. Выберите другой способ: /quiz",
+	"quiz.listening_off":         "Тест на слух сейчас недоступен. Выберите другой способ: /quiz",
+	"quiz.sentence_off":          "Проверка предложений сейчас недоступна. Выберите другой способ: /quiz",
+	"quiz.definition_off":        "Тест по определениям сейчас недоступен. Выберите другой способ: /quiz",
+	"quiz.preparing_definitions": "⏳ Готовлю определения слов...",
+	"quiz.no_context":            "Для теста в контексте нужны слова с примерами употребления, а у этих слов их нет. Выберите другие слова или другой способ ответа: /quiz",
+	"quiz.no_synonyms":           "Для теста на синонимы нужны слова с синонимами, а у этих слов их пока нет: бот подбирает их в фоне. Выберите другие слова или другой способ ответа: /quiz",
+	"quiz.no_spelling":           "По буквам собираются отдельные слова до %d букв, а среди этих слов таких нет. Выберите другие слова или другой способ ответа: /quiz",
+	"quiz.no_word_order":         "Для теста на порядок слов нужны примеры употребления от %d до %d слов, а у этих слов таких нет. Выберите другие слова или другой способ ответа: /quiz",
+	"quiz.no_definitions":        "Не получилось подготовить определения этих слов. Попробуйте позже или выберите другой способ ответа: /quiz",
+	"quiz.not_enough":            "Для теста нужно хотя бы 2 слова с разными переводами. Начните тест заново: /quiz",
+	"quiz.poll_started":          "🧠 Тест начался: %d %s. Отвечайте в опросах ниже.",
+	"quiz.listening_started":     "🎧 Тест на слух начался: %d %s. Слушайте голосовые сообщения и выбирайте перевод.",
+	"quiz.voice_only":            "🎙 Голосовые сообщения я принимаю как ответы в тесте «Голосом»: /quiz",
+	"quiz.voice_too_long":        "Сообщение слишком длинное: достаточно произнести слово, до %d секунд.",
+	"quiz.transcribe_failed":     "😔 Не получилось распознать речь. Попробуйте записать ответ еще раз.",
+	"quiz.silence":               "🎙 Не расслышал ни слова. Запишите ответ еще раз, ближе к микрофону.",
+	"quiz.recognized":            "🎙 Распознано: «%s»\n%s",
+	"quiz.check_failed":          "😔 Не получилось проверить предложение. Отправьте его еще раз или нажмите «🤷 Не знаю».",
+	"quiz.poll_question":         "%d/%d. Как переводится «%s»?",
+	"quiz.stopped":               "⏹ Тест остановлен. Начать новый: /quiz",
+	"quiz.ask_definition":        "📘 Какое слово подходит к определению?\n\n%s",
+	"quiz.ask_synonym":           "Какое слово - синоним «%s»?",
+	"quiz.ask_heard_phrase":      "🎧 Какое из этих слов прозвучало во фразе?",
+	"quiz.ask_heard_word":        "🎧 Как переводится прозвучавшее слово?",
+	"quiz.ask_translation":       "Как переводится «%s»?",
+	"quiz.ask_cloze":             "Впишите пропущенное слово (%s):\n\n%s",
+	"quiz.ask_say":               "🎙 Скажите по-английски голосовым сообщением: «%s»",
+	"quiz.ask_sentence":          "✍️ Напишите предложение по-английски со словом «%s» (%s)",
+	"quiz.ask_definition_text":   "📘 Напишите английское слово по определению:\n\n%s",
+	"quiz.ask_typed":             "Напишите перевод: «%s»",
+	"quiz.right":                 "✅ Верно: %s - %s",
+	"quiz.wrong":                 "❌ Неверно: %s - %s",
+	"quiz.almost_letters":        "✅ Почти: на месте %d из %d букв. Правильно: «%s»",
+	"quiz.almost_words":          "✅ Почти: на месте %d из %d слов",
+	"quiz.unclear":               "✅ Засчитано, но прозвучало не совсем четко. Правильно: «%s»",
+	"quiz.with_mistakes":         "✅ Засчитано, но с ошибками: %s - %s",
+	"quiz.typo":                  "✅ Засчитано, но с опечаткой. Правильно: «%s»",
+	"quiz.words_in_place":        "\nНа месте %d из %d слов",
+	"quiz.letters_in_place":      "\nНа месте %d из %d букв",
+	"quiz.summary":               "🏁 Тест завершен\n\n",
+	"quiz.summary_correct":       "✅ Правильных ответов: %d из %d (%d%%)\n",
+	"quiz.summary_time":          "⏱ Время: %s\n",
+	"quiz.summary_mistakes":      "\n📝 Стоит повторить:\n",
+	"quiz.summary_cram":          "\nЗубрить дальше: /cram, проверить себя по графику: /review",
+	"quiz.summary_again":         "\nПройти еще один тест: /quiz",
+	"quiz.seconds":               "%d с",
+	"quiz.minutes":               "%d мин %d с",
+}
@@ -0,0 +1,110 @@
+// Package ical writes calendars in the iCalendar format (RFC 5545) that Google Calendar, Apple
+// Calendar and Outlook subscribe to
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the longest content line; longer ones are folded onto continuation lines
+const maxLineOctets = 75
+
+// Calendar is a feed of events
+type Calendar struct {
+	// Product that made the calendar, like "-//engbot//reviews//EN"
+	ProdID string
+	// Name calendar apps show for the subscription
+	Name string
+	// How often subscribers should fetch the feed again
+	Refresh time.Duration
+	Events  []Event
+}
+
+// Event is an all-day event
+type Event struct {
+	// UID identifies the event across fetches, so a moved event is updated rather than duplicated
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+	// Stamp is when this version of the event was made
+	Stamp time.Time
+}
+
+// Write writes the calendar to w
+func Write(w io.Writer, c Calendar) error {
+	out := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(out, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", c.ProdID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	if c.Refresh > 0 {
+		line("REFRESH-INTERVAL;VALUE=DURATION", duration(c.Refresh))
+		line("X-PUBLISHED-TTL", duration(c.Refresh))
+	}
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", e.Stamp.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE", e.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE", e.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return out.Flush()
+}
+
+// escape escapes the characters that are special in text values
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// duration formats d as an RFC 5545 duration in whole minutes, like PT1H30M
+func duration(d time.Duration) string {
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("PT%dM", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("PT%dH", hours)
+	default:
+		return fmt.Sprintf("PT%dH%dM", hours, minutes)
+	}
+}
+
+// writeFolded writes the content line ending with CRLF, folding it every 75 octets without
+// splitting a UTF-8 character
+func writeFolded(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts toward its length
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,107 @@
+package locale
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Locale selects how numbers and counted nouns are rendered
+type Locale string
+
+// Supported locales
+const (
+	Russian Locale = "ru"
+	English Locale = "en"
+)
+
+// Default is used when no or an unknown locale is configured
+const Default = Russian
+
+// Parse returns the locale for a code like "en" or "ru-RU", falling back to Default
+func Parse(code string) Locale {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	switch Locale(code) {
+	case Russian, English:
+		return Locale(code)
+	default:
+		return Default
+	}
+}
+
+// Number formats n with the locale's thousands separator: "12 345" in Russian, "12,345" in English
+func (l Locale) Number(n int) string {
+	sep := " "
+	if l == English {
+		sep = ","
+	}
+
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+// Ordinal formats n as a position mark: "№3" in Russian, "#3" in English
+func (l Locale) Ordinal(n int) string {
+	if l == English {
+		return "#" + l.Number(n)
+	}
+	return "№" + l.Number(n)
+}
+
+// Plural picks the word form for n. Russian distinguishes one (1, 21), few
+// (2-4, 22-24) and many (5-20, 25...); English only uses one and many.
+func (l Locale) Plural(n int, one, few, many string) string {
+	if n < 0 {
+		n = -n
+	}
+	if l == English {
+		if n == 1 {
+			return one
+		}
+		return many
+	}
+
+	n = n % 100
+	if n >= 11 && n <= 14 {
+		return many
+	}
+	switch n % 10 {
+	case 1:
+		return one
+	case 2, 3, 4:
+		return few
+	default:
+		return many
+	}
+}
+
+// Repetition renders the label of the n-th repetition: "Повторение №3" or "Repetition #3"
+func (l Locale) Repetition(n int) string {
+	if l == English {
+		return "Repetition " + l.Ordinal(n)
+	}
+	return "Повторение " + l.Ordinal(n)
+}
+
+// Words renders a word count: "3 слова" or "3 words"
+func (l Locale) Words(n int) string {
+	if l == English {
+		return fmt.Sprintf("%s %s", l.Number(n), l.Plural(n, "word", "words", "words"))
+	}
+	return fmt.Sprintf("%s %s", l.Number(n), l.Plural(n, "слово", "слова", "слов"))
+}
@@ -0,0 +1,96 @@
+package locale
+
+import "testing"
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		n      int
+		ru, en string
+	}{
+		{0, "дней", "days"},
+		{1, "день", "day"},
+		{2, "дня", "days"},
+		{4, "дня", "days"},
+		{5, "дней", "days"},
+		{11, "дней", "days"},
+		{12, "дней", "days"},
+		{14, "дней", "days"},
+		{21, "день", "days"},
+		{22, "дня", "days"},
+		{25, "дней", "days"},
+		{101, "день", "days"},
+		{111, "дней", "days"},
+		{-1, "день", "day"},
+		{-22, "дня", "days"},
+	}
+	for _, tt := range tests {
+		if got := Russian.Plural(tt.n, "день", "дня", "дней"); got != tt.ru {
+			t.Errorf("ru Plural(%d) = %q, want %q", tt.n, got, tt.ru)
+		}
+		if got := English.Plural(tt.n, "day", "days", "days"); got != tt.en {
+			t.Errorf("en Plural(%d) = %q, want %q", tt.n, got, tt.en)
+		}
+	}
+}
+
+func TestRepetition(t *testing.T) {
+	tests := []struct {
+		n      int
+		ru, en string
+	}{
+		{1, "Повторение №1", "Repetition #1"},
+		{2, "Повторение №2", "Repetition #2"},
+		{5, "Повторение №5", "Repetition #5"},
+		{11, "Повторение №11", "Repetition #11"},
+		{21, "Повторение №21", "Repetition #21"},
+		{22, "Повторение №22", "Repetition #22"},
+		{1000, "Повторение №1 000", "Repetition #1,000"},
+	}
+	for _, tt := range tests {
+		if got := Russian.Repetition(tt.n); got != tt.ru {
+			t.Errorf("ru Repetition(%d) = %q, want %q", tt.n, got, tt.ru)
+		}
+		if got := English.Repetition(tt.n); got != tt.en {
+			t.Errorf("en Repetition(%d) = %q, want %q", tt.n, got, tt.en)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		n      int
+		ru, en string
+	}{
+		{1, "1 слово", "1 word"},
+		{2, "2 слова", "2 words"},
+		{5, "5 слов", "5 words"},
+		{11, "11 слов", "11 words"},
+		{21, "21 слово", "21 words"},
+		{22, "22 слова", "22 words"},
+		{12345, "12 345 слов", "12,345 words"},
+	}
+	for _, tt := range tests {
+		if got := Russian.Words(tt.n); got != tt.ru {
+			t.Errorf("ru Words(%d) = %q, want %q", tt.n, got, tt.ru)
+		}
+		if got := English.Words(tt.n); got != tt.en {
+			t.Errorf("en Words(%d) = %q, want %q", tt.n, got, tt.en)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]Locale{
+		"en":    English,
+		"EN-us": English,
+		"ru_RU": Russian,
+		" ru ":  Russian,
+		"de":    Default,
+		"":      Default,
+	}
+	for code, want := range tests {
+		if got := Parse(code); got != want {
+			t.Errorf("Parse(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package plans is the library of prebuilt study plans: grammar topics with a description,
+// examples and an interval schedule suited to how hard they are
+package plans
+
+// Category is the topic category the plans are created in
+const Category = "Грамматика"
+
+// Plan is a prebuilt study plan, created as a topic of the user's
+type Plan struct {
+	Key         string // stable ID for the callbacks
+	Name        string // name of the topic
+	Difficulty  int    // topic difficulty, 1-5
+	Description string
+	Examples    []string // attached to the topic as notes
+	// Intervals are the days between the reviews: the harder the topic, the more often it
+	// comes back at first
+	Intervals []int
+}
+
+// Interval ladders by how hard the topic is
+var (
+	easyIntervals    = []int{1, 3, 7, 16, 35, 80}
+	mediumIntervals  = []int{1, 2, 5, 10, 21, 45, 90}
+	hardIntervals    = []int{1, 2, 4, 8, 15, 30, 60, 120}
+	hardestIntervals = []int{1, 2, 3, 6, 10, 18, 30, 50, 80, 120}
+)
+
+// Grammar are the grammar plans, from the basics up
+var Grammar = []Plan{
+	{
+		Key:        "present_simple_continuous",
+		Name:       "Present Simple и Present Continuous",
+		Difficulty: 2,
+		Description: "Present Simple - привычки, факты, расписания: I work, she works (в 3-м лице -s). " +
+			"Present Continuous - то, что происходит сейчас или временно: I am working. " +
+			"Глаголы состояния (know, like, want, believe) в Continuous обычно не ставятся.",
+		Examples: []string{
+			"She works in a bank. - Она работает в банке.",
+			"She is working from home this week. - На этой неделе она работает из дома.",
+			"Water boils at 100 degrees. - Вода кипит при 100 градусах.",
+			"I know the answer (не I am knowing). - Я знаю ответ.",
+		},
+		Intervals: easyIntervals,
+	},
+	{
+		Key:        "past_simple",
+		Name:       "Past Simple",
+		Difficulty: 2,
+		Description: "Законченное действие в прошлом, часто с указанием времени: yesterday, last year, in 2010. " +
+			"Правильные глаголы получают -ed, неправильные берутся из второй формы. " +
+			"Вопрос и отрицание - с did, а глагол возвращается в начальную форму.",
+		Examples: []string{
+			"I visited London last summer. - Прошлым летом я был в Лондоне.",
+			"We went to the cinema yesterday. - Вчера мы ходили в кино.",
+			"Did you see him? - No, I didn't. - Ты его видел? - Нет.",
+		},
+		Intervals: easyIntervals,
+	},
+	{
+		Key:        "present_perfect",
+		Name:       "Present Perfect",
+		Difficulty: 4,
+		Description: "have/has + третья форма глагола. Прошлое, важное сейчас: опыт (ever, never), " +
+			"результат (just, already, yet) и то, что длится до сих пор (for, since). " +
+			"С точным временем в прошлом (yesterday, in 2010) не употребляется - там нужен Past Simple.",
+		Examples: []string{
+			"I have lived here for five years. - Я живу здесь пять лет.",
+			"Have you ever been to Japan? - Ты когда-нибудь был в Японии?",
+			"She has just finished her report. - Она только что закончила отчет.",
+			"I lost my keys yesterday (не have lost). - Вчера я потерял ключи.",
+		},
+		Intervals: hardIntervals,
+	},
+	{
+		Key:        "past_continuous_perfect",
+		Name:       "Past Continuous и Past Perfect",
+		Difficulty: 3,
+		Description: "Past Continuous (was/were + -ing) - процесс в момент в прошлом или фон для другого события. " +
+			"Past Perfect (had + третья форма) - действие, закончившееся раньше другого момента в прошлом.",
+		Examples: []string{
+			"I was reading when the phone rang. - Я читал, когда зазвонил телефон.",
+			"At 8 pm we were having dinner. - В 8 вечера мы ужинали.",
+			"When we arrived, the film had already started. - Когда мы пришли, фильм уже начался.",
+		},
+		Intervals: mediumIntervals,
+	},
+	{
+		Key:        "future",
+		Name:       "Future: will, going to, Present Continuous",
+		Difficulty: 3,
+		Description: "will - решение в момент речи, обещание, прогноз-мнение. going to - намерение или прогноз " +
+			"по явным признакам. Present Continuous - договоренность на определенное время. " +
+			"После when, if, as soon as о будущем говорят в Present Simple.",
+		Examples: []string{
+			"It's cold. I'll close the window. - Холодно. Я закрою окно.",
+			"I'm going to learn Spanish next year. - В следующем году я собираюсь учить испанский.",
+			"Look at the clouds! It's going to rain. - Посмотри на тучи! Сейчас пойдет дождь.",
+			"We're meeting Tom at six. - Мы встречаемся с Томом в шесть.",
+		},
+		Intervals: mediumIntervals,
+	},
+	{
+		Key:        "conditionals",
+		Name:       "Conditionals (0-3)",
+		Difficulty: 5,
+		Description: "Zero: if + Present, Present - общие истины. First: if + Present, will - реальное будущее. " +
+			"Second: if + Past, would - нереальное настоящее. Third: if + Past Perfect, would have + третья форма - " +
+			"нереальное прошлое. В части с if will и would не ставятся.",
+		Examples: []string{
+			"If you heat ice, it melts. - Если нагреть лед, он тает.",
+			"If it rains, we will stay at home. - Если пойдет дождь, мы останемся дома.",
+			"If I had more time, I would travel. - Если бы у меня было больше времени, я бы путешествовал.",
+			"If she had studied, she would have passed. - Если бы она готовилась, она бы сдала.",
+		},
+		Intervals: hardestIntervals,
+	},
+	{
+		Key:        "passive",
+		Name:       "Passive Voice",
+		Difficulty: 4,
+		Description: "be в нужном времени + третья форма глагола, когда важно действие, а не тот, кто его совершил. " +
+			"Исполнителя, если он нужен, вводят через by.",
+		Examples: []string{
+			"The bridge was built in 1890. - Мост построили в 1890 году.",
+			"English is spoken all over the world. - На английском говорят по всему миру.",
+			"The letters have been sent. - Письма отправлены.",
+			"The novel was written by Tolstoy. - Роман написан Толстым.",
+		},
+		Intervals: hardIntervals,
+	},
+	{
+		Key:        "reported_speech",
+		Name:       "Reported Speech",
+		Difficulty: 4,
+		Description: "Косвенная речь после said/told: время сдвигается на шаг в прошлое (am → was, will → would, " +
+			"Past Simple → Past Perfect), меняются местоимения и слова времени (tomorrow → the next day). " +
+			"В косвенном вопросе прямой порядок слов.",
+		Examples: []string{
+			"\"I am tired.\" → She said (that) she was tired. - Она сказала, что устала.",
+			"\"I will call you.\" → He said he would call me. - Он сказал, что позвонит мне.",
+			"\"Where do you live?\" → She asked where I lived. - Она спросила, где я живу.",
+		},
+		Intervals: hardIntervals,
+	},
+	{
+		Key:        "modals",
+		Name:       "Modal verbs",
+		Difficulty: 3,
+		Description: "can/could - умение и возможность, must - необходимость по мнению говорящего, " +
+			"have to - по обстоятельствам, should - совет, may/might - вероятность. " +
+			"После модального глагола - инфинитив без to, -s в 3-м лице не добавляется.",
+		Examples: []string{
+			"She can speak three languages. - Она говорит на трех языках.",
+			"You must wear a seatbelt. - Вы обязаны пристегнуться.",
+			"I have to get up early tomorrow. - Завтра мне надо рано вставать.",
+			"You should see a doctor. - Тебе стоит сходить к врачу.",
+			"It might rain later. - Позже может пойти дождь.",
+		},
+		Intervals: mediumIntervals,
+	},
+	{
+		Key:        "articles",
+		Name:       "Articles: a, an, the",
+		Difficulty: 4,
+		Description: "a/an - один из многих, упомянут впервые (an перед гласным звуком). the - конкретный, " +
+			"уже известный или единственный в своем роде. Без артикля - неисчисляемые и множественное число " +
+			"в общем смысле, большинство имен и названий стран.",
+		Examples: []string{
+			"I saw a dog. The dog was huge. - Я увидел собаку. Собака была огромной.",
+			"She is an engineer. - Она инженер.",
+			"The sun rises in the east. - Солнце встает на востоке.",
+			"I like coffee, but the coffee here is awful. - Я люблю кофе, но кофе здесь ужасный.",
+		},
+		Intervals: hardIntervals,
+	},
+	{
+		Key:        "gerund_infinitive",
+		Name:       "Gerund или Infinitive",
+		Difficulty: 4,
+		Description: "После enjoy, avoid, finish, mind, suggest и предлогов - -ing. После want, decide, hope, " +
+			"plan, agree - to + глагол. У stop, remember, forget, try смысл зависит от формы.",
+		Examples: []string{
+			"I enjoy swimming. - Мне нравится плавать.",
+			"She decided to leave. - Она решила уехать.",
+			"He stopped smoking. / He stopped to smoke. - Он бросил курить. / Он остановился, чтобы покурить.",
+			"Remember to lock the door. - Не забудь запереть дверь.",
+		},
+		Intervals: hardIntervals,
+	},
+}
+
+// ByKey returns the grammar plan with the key
+func ByKey(key string) (Plan, bool) {
+	for _, plan := range Grammar {
+		if plan.Key == key {
+			return plan, true
+		}
+	}
+	return Plan{}, false
+}
@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// Channel delivers notifications to the users outside Telegram, for those who mute the bot
+type Channel interface {
+	// Name is what users pick the channel by, like "email"
+	Name() string
+	// Validate checks the address a user gives for the channel
+	Validate(address string) error
+	// Send delivers the notification to the address
+	Send(ctx context.Context, address string, n Notification) error
+}
+
+// Notification is a message for the channels beyond Telegram, in plain text
+type Notification struct {
+	// Kind of the notification, like "reminder"
+	Kind    string
+	Subject string
+	Text    string
+}
+
+// ChannelRegistry holds the notification channels the admin configured. A nil registry has none.
+type ChannelRegistry struct {
+	channels map[string]Channel
+}
+
+// NewChannelRegistry creates a registry of the channels
+func NewChannelRegistry(channels ...Channel) *ChannelRegistry {
+	r := &ChannelRegistry{channels: make(map[string]Channel)}
+	for _, c := range channels {
+		r.Register(c)
+	}
+	return r
+}
+
+// ChannelsFromEnv registers the email channel when SMTP_HOST and SMTP_FROM are set and the
+// webhook channel when NOTIFY_WEBHOOK_ENABLED is true. It returns nil when neither is.
+func ChannelsFromEnv() (*ChannelRegistry, error) {
+	var channels []Channel
+	if host, from := os.Getenv("SMTP_HOST"), os.Getenv("SMTP_FROM"); host != "" || from != "" {
+		port := DefaultSMTPPort
+		if value := os.Getenv("SMTP_PORT"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 65535 {
+				return nil, fmt.Errorf("invalid SMTP_PORT %q: expected a port number", value)
+			}
+			port = n
+		}
+		email, err := NewEmailChannel(SMTPConfig{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		})
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, email)
+	}
+	if enabled, _ := strconv.ParseBool(os.Getenv("NOTIFY_WEBHOOK_ENABLED")); enabled {
+		channels = append(channels, NewWebhookChannel(os.Getenv("NOTIFY_WEBHOOK_SECRET")))
+	}
+	if len(channels) == 0 {
+		return nil, nil
+	}
+	return NewChannelRegistry(channels...), nil
+}
+
+// Register adds the channel, replacing one with the same name
+func (r *ChannelRegistry) Register(c Channel) {
+	r.channels[c.Name()] = c
+}
+
+// Get returns the channel with the name
+func (r *ChannelRegistry) Get(name string) (Channel, bool) {
+	if r == nil {
+		return nil, false
+	}
+	c, ok := r.channels[name]
+	return c, ok
+}
+
+// Names returns the names of the channels in alphabetical order
+func (r *ChannelRegistry) Names() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.channels))
+	for name := range r.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSMTPPort is the submission port, where the connection is upgraded with STARTTLS.
+// On port 465 the connection is TLS from the start.
+const DefaultSMTPPort = 587
+
+// smtpTimeout bounds the whole delivery of one email
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig is the mail server the email channel sends through
+type SMTPConfig struct {
+	Host string
+	Port int
+	// Username and Password log in to the server, no login when Username is empty
+	Username string
+	Password string
+	// From is the sender, like "EngBot <bot@example.com>"
+	From string
+}
+
+// EmailChannel sends notifications by email
+type EmailChannel struct {
+	config SMTPConfig
+	from   *mail.Address
+}
+
+// NewEmailChannel creates an email channel sending through the server
+func NewEmailChannel(config SMTPConfig) (*EmailChannel, error) {
+	if config.Host == "" {
+		return nil, errors.New("SMTP_HOST is required for email notifications")
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP_FROM %q: %w", config.From, err)
+	}
+	if config.Port == 0 {
+		config.Port = DefaultSMTPPort
+	}
+	return &EmailChannel{config: config, from: from}, nil
+}
+
+// Name implements Channel
+func (c *EmailChannel) Name() string {
+	return "email"
+}
+
+// Validate accepts a bare email address, like "user@example.com"
+func (c *EmailChannel) Validate(address string) error {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address {
+		return fmt.Errorf("%q is not an email address", address)
+	}
+	return nil
+}
+
+// Send implements Channel
+func (c *EmailChannel) Send(ctx context.Context, address string, n Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	host := c.config.Host
+	addr := net.JoinHostPort(host, strconv.Itoa(c.config.Port))
+	var conn net.Conn
+	var err error
+	if c.config.Port == 465 {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: host}}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if c.config.Username != "" {
+		// PlainAuth refuses to send the password over a connection without TLS
+		if err := client.Auth(smtp.PlainAuth("", c.config.Username, c.config.Password, host)); err != nil {
+			return fmt.Errorf("failed to log in to SMTP server: %w", err)
+		}
+	}
+
+	if err := client.Mail(c.from.Address); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := client.Rcpt(address); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", address, err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if _, err := w.Write(c.message(address, n)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return client.Quit()
+}
+
+// message builds the email in plain text, quoted-printable so long lines and non-ASCII text
+// pass any server
+func (c *EmailChannel) message(to string, n Notification) []byte {
+	// A line break in the subject would start a header of its own
+	subject := strings.Join(strings.Fields(n.Subject), " ")
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&msg)
+	body.Write([]byte(n.Text))
+	body.Close()
+	return msg.Bytes()
+}
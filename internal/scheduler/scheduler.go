@@ -3,52 +3,214 @@ package scheduler
 import (
 	"context"
 	"fmt"
-	"log"
-	"time"
-
+	"log/slog"
+	"math/rand"
 	"runtime/debug"
+	"sync/atomic"
+	"time"
 
+	"github.com/example/engbot/internal/backup"
+	"github.com/example/engbot/internal/clock"
 	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/service"
+	"github.com/example/engbot/internal/spaced_repetition"
 	"github.com/robfig/cron/v3"
 )
 
-// Константы для настроек уведомлений по умолчанию
+// Константы для настроек уведомлений по умолчанию. Тихие часы по умолчанию длятся
+// с DefaultNotificationEndHour до DefaultNotificationStartHour.
 const (
 	DefaultNotificationStartHour = 4  // Время начала уведомлений (8:00)
 	DefaultNotificationEndHour   = 18 // Время окончания уведомлений (22:00)
 )
 
+// notificationLogDays is how many days of the notification log are kept
+const notificationLogDays = 30
+
+// schedulerLease is the name of the lease held by the instance that runs the jobs
+const schedulerLease = "scheduler"
+
+// Job is the schedule of one job
+type Job struct {
+	Enabled bool
+	// Cron spec with seconds, e.g. "0 0 * * * *" for every hour
+	Schedule string
+}
+
+// Config selects the jobs the scheduler runs and when
+type Config struct {
+	// Reminders about due repetitions. The job also runs once at start, a reminder
+	// already sent in this hour is not sent again.
+	Reminders Job
+	// Daily stories at the users' first reminder hour
+	Stories Job
+	// The word of the day at the first reminder hour of the users who opted in
+	WordOfDay Job
+	// Protection of the streaks of users with nothing to review, just before the day ends
+	StreakProtection Job
+	// Pruning of the old days of the notification log
+	NotificationLogPruning Job
+	// Permanent removal of deleted topics that can no longer be restored
+	TrashPruning Job
+	// Rescheduling, resetting or stalling of long overdue repetitions by the users' overdue policies
+	OverduePolicies Job
+	// Prompts to revive or archive stalled topics, weekly by default
+	StalledPrompts Job
+	// Spreading of the repetitions over the users' daily review limits to the following days
+	LoadBalancing Job
+	// Weekly progress reports on the day of the week and at the hour each user picked
+	WeeklyReports Job
+	// Backups of the SQLite database into Backup
+	Backups Job
+	// Retries of the notifications that failed to send, outside the users' quiet hours
+	DeliveryRetries Job
+	// Tuning of the users' SM-2 parameters from their recall history, weekly by default
+	SM2Tuning Job
+	// Rating of the users' word difficulties from their reviews and tests, weekly by default
+	WordDifficulty Job
+	// Where the backups go and how many are kept, nil when no storage is configured
+	Backup *backup.Manager
+	// Channels beyond Telegram the users can also get their reminders through, nil for none
+	Channels *ChannelRegistry
+	// How long deleted topics stay restorable
+	TrashRetention time.Duration
+	// Every run is delayed by a random time up to Jitter to spread the load of several instances.
+	// Keep it well under an hour: the hourly jobs look at the hour they run in.
+	Jitter time.Duration
+	// Locker lets only one of several instances run the jobs: the one holding the scheduler
+	// lease, renewed every third of LeaseTTL. Nil runs them on every instance.
+	Locker Locker
+	// Instance names this instance as the holder of the lease
+	Instance string
+	// How long the lease outlives an instance that stopped renewing it, e.g. after a crash
+	LeaseTTL time.Duration
+}
+
+// Locker hands out named leases, see database.LeaseRepository
+type Locker interface {
+	// Acquire takes or renews the lease for holder and reports whether holder has it
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Release gives up the lease if holder has it
+	Release(ctx context.Context, name, holder string) error
+}
+
+// DefaultConfig returns the schedules the jobs run on by default
+func DefaultConfig() Config {
+	return Config{
+		Reminders:              Job{Enabled: true, Schedule: "0 0 * * * *"},
+		Stories:                Job{Enabled: true, Schedule: "0 0 * * * *"},
+		WordOfDay:              Job{Enabled: true, Schedule: "0 0 * * * *"},
+		StreakProtection:       Job{Enabled: true, Schedule: "0 55 23 * * *"},
+		NotificationLogPruning: Job{Enabled: true, Schedule: "0 30 3 * * *"},
+		TrashPruning:           Job{Enabled: true, Schedule: "0 15 * * * *"},
+		OverduePolicies:        Job{Enabled: true, Schedule: "0 0 5 * * *"},
+		StalledPrompts:         Job{Enabled: true, Schedule: "0 0 12 * * 0"},
+		LoadBalancing:          Job{Enabled: true, Schedule: "0 10 0 * * *"},
+		WeeklyReports:          Job{Enabled: true, Schedule: "0 0 * * * *"},
+		Backups:                Job{Enabled: true, Schedule: "0 0 3 * * *"},
+		DeliveryRetries:        Job{Enabled: true, Schedule: "30 * * * * *"},
+		SM2Tuning:              Job{Enabled: true, Schedule: "0 40 4 * * 1"},
+		WordDifficulty:         Job{Enabled: true, Schedule: "0 50 4 * * 1"},
+		TrashRetention:         10 * time.Minute,
+		LeaseTTL:               time.Minute,
+	}
+}
+
 // Scheduler manages scheduled tasks for the application
 type Scheduler struct {
 	cron     *cron.Cron
 	notifier Notifier
+	clock    clock.Clock
+	config   Config
+	leader   atomic.Bool // this instance holds the scheduler lease
 }
 
 // Notifier interface for sending notifications
 type Notifier interface {
+	// CheckDueRepetitions reminds the users whose reminder time is now about their due repetitions
+	CheckDueRepetitions(ctx context.Context) error
 	SendReminders(userID int64, count int) error
+	SendDailyStory(ctx context.Context, userID int64) error
+	// SendWordOfDay sends the user the word of the day
+	SendWordOfDay(ctx context.Context, userID int64) error
+	// ApplyOverduePolicy handles the user's long overdue repetitions by the user's overdue policy
+	ApplyOverduePolicy(ctx context.Context, userID int64) error
+	// SendStalledPrompt asks the user to revive or archive the stalled topics
+	SendStalledPrompt(ctx context.Context, userID int64) error
+	// SendWeeklyReport sends the user a summary of the last week
+	SendWeeklyReport(ctx context.Context, userID int64) error
+	// RetryDeliveries sends again the notifications that failed and are due for another attempt
+	RetryDeliveries(ctx context.Context) error
 }
 
-// New creates a new scheduler instance
+// New creates a new scheduler instance with the default config
 func New(notifier Notifier) *Scheduler {
-	c := cron.New(cron.WithSeconds())
+	return NewWithClock(notifier, clock.System{}, DefaultConfig())
+}
+
+// NewWithClock creates a scheduler that reads the current time from clk
+func NewWithClock(notifier Notifier, clk clock.Clock, config Config) *Scheduler {
+	// A run still going when the next one is due, e.g. with a short cadence, is not doubled
+	c := cron.New(cron.WithSeconds(), cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger)))
 	return &Scheduler{
 		cron:     c,
 		notifier: notifier,
+		clock:    clk,
+		config:   config,
 	}
 }
 
 // Start begins running all scheduled tasks
 func (s *Scheduler) Start(ctx context.Context) error {
-	// Schedule hourly check for users who need notifications
-	_, err := s.cron.AddFunc("0 0 * * * *", func() { s.checkAndSendReminders(ctx) })
-	if err != nil {
-		return fmt.Errorf("failed to schedule reminders: %w", err)
+	jobs := []struct {
+		name string
+		job  Job
+		run  func(ctx context.Context)
+	}{
+		{"reminders", s.config.Reminders, s.sendReminders},
+		{"stories", s.config.Stories, s.sendDailyStories},
+		{"word_of_day", s.config.WordOfDay, s.sendWordsOfDay},
+		{"streak_protection", s.config.StreakProtection, s.protectIdleStreaks},
+		{"notification_log", s.config.NotificationLogPruning, s.pruneNotificationLog},
+		{"topic_trash", s.config.TrashPruning, s.pruneTrash},
+		{"overdue_policies", s.config.OverduePolicies, s.applyOverduePolicies},
+		{"stalled_prompts", s.config.StalledPrompts, s.sendStalledPrompts},
+		{"load_balancing", s.config.LoadBalancing, s.balanceReviewLoad},
+		{"weekly_reports", s.config.WeeklyReports, s.sendWeeklyReports},
+		{"backups", s.config.Backups, s.backUpDatabase},
+		{"delivery_retries", s.config.DeliveryRetries, s.retryDeliveries},
+		{"sm2_tuning", s.config.SM2Tuning, s.tuneSM2},
+		{"word_difficulty", s.config.WordDifficulty, s.rateWordDifficulty},
 	}
-	
+	for _, j := range jobs {
+		if !j.job.Enabled {
+			slog.Info("scheduled job is disabled", "job", j.name)
+			continue
+		}
+		run := j.run
+		if _, err := s.cron.AddFunc(j.job.Schedule, func() { s.runWithJitter(ctx, run) }); err != nil {
+			return fmt.Errorf("failed to schedule %s: %w", j.name, err)
+		}
+		slog.Debug("scheduled job", "job", j.name, "schedule", j.job.Schedule)
+	}
+
+	if s.config.Locker != nil {
+		if s.config.LeaseTTL <= 0 {
+			s.config.LeaseTTL = DefaultConfig().LeaseTTL
+		}
+		s.renewLease(ctx)
+		go s.holdLease(ctx)
+	}
+
 	// Start the scheduler in a non-blocking manner
 	s.cron.Start()
 
+	// Catch up on the reminders of the current hour, e.g. after a restart
+	if s.config.Reminders.Enabled && s.leading() {
+		go s.sendReminders(ctx)
+	}
+
 	// Wait for context cancellation
 	go func() {
 		<-ctx.Done()
@@ -58,70 +220,410 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop terminates all scheduled tasks
+// Stop terminates all scheduled tasks and hands the lease over to another instance
 func (s *Scheduler) Stop() {
 	s.cron.Stop()
+
+	if s.config.Locker != nil && s.leader.Swap(false) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.config.Locker.Release(ctx, schedulerLease, s.config.Instance); err != nil {
+			slog.Error("failed to release scheduler lease", "error", err)
+		}
+	}
+}
+
+// leading reports whether this instance runs the jobs
+func (s *Scheduler) leading() bool {
+	return s.config.Locker == nil || s.leader.Load()
+}
+
+// holdLease takes or renews the scheduler lease every third of its length until ctx is done
+func (s *Scheduler) holdLease(ctx context.Context) {
+	ticker := time.NewTicker(s.config.LeaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.renewLease(ctx)
+		}
+	}
+}
+
+// renewLease takes or renews the scheduler lease. An instance that can't reach the lease stops
+// running the jobs, as another one may have taken it over.
+func (s *Scheduler) renewLease(ctx context.Context) {
+	held, err := s.config.Locker.Acquire(ctx, schedulerLease, s.config.Instance, s.config.LeaseTTL)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to renew scheduler lease", "instance", s.config.Instance, "error", err)
+		}
+		held = false
+	}
+	if s.leader.Swap(held) != held {
+		if held {
+			slog.Info("this instance runs the scheduled jobs", "instance", s.config.Instance)
+		} else {
+			slog.Info("another instance runs the scheduled jobs", "instance", s.config.Instance)
+		}
+	}
+}
+
+// runWithJitter runs the job after a random delay up to the configured jitter
+func (s *Scheduler) runWithJitter(ctx context.Context, run func(ctx context.Context)) {
+	if s.config.Jitter > 0 {
+		timer := time.NewTimer(time.Duration(rand.Int63n(int64(s.config.Jitter))))
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+	if !s.leading() {
+		return
+	}
+	run(ctx)
+}
+
+// sendReminders reminds the users whose reminder time is now about their due repetitions
+func (s *Scheduler) sendReminders(ctx context.Context) {
+	logger := slog.Default().With("job", "reminders", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in reminder check", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	logger.Info("starting reminder check", "hour", s.clock.Now().Hour())
+	if err := s.notifier.CheckDueRepetitions(ctx); err != nil {
+		logger.Error("failed to check due repetitions", "error", err)
+		return
+	}
+	logger.Info("reminder check completed")
 }
 
-// checkAndSendReminders checks for users who need reminders and sends them
-func (s *Scheduler) checkAndSendReminders(ctx context.Context) {
+// sendDailyStories sends the daily story to the users who get it at this hour
+func (s *Scheduler) sendDailyStories(ctx context.Context) {
+	logger := slog.Default().With("job", "stories", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("Recovered from panic in checkAndSendReminders: %v", r)
-			debug.PrintStack()
+			logger.Error("recovered from panic in daily stories", "panic", r, "stack", string(debug.Stack()))
 		}
 	}()
 
-	log.Println("Starting reminder check...")
+	hour := s.clock.Now().Hour()
+	users, err := database.NewUserRepository().GetUsersForStory(ctx, hour)
+	if err != nil {
+		logger.Error("failed to get users for story", "error", err)
+		return
+	}
 
-	// Get current hour
-	currentHour := time.Now().Hour()
-	log.Printf("Checking reminders for hour: %d", currentHour)
+	sent := 0
+	for _, user := range users {
+		if err := s.notifier.SendDailyStory(ctx, user.TelegramID); err != nil {
+			logger.Error("failed to send daily story", "user_id", user.ID, "error", err)
+			continue
+		}
+		sent++
+	}
+	logger.Info("daily stories completed", "hour", hour, "users", sent)
+}
 
-	// Get users who should receive notifications at this hour
-	userRepo := database.NewUserRepository()
-	users, err := userRepo.GetUsersForNotification(ctx, currentHour)
+// sendWordsOfDay sends the word of the day to the users who get it at this hour
+func (s *Scheduler) sendWordsOfDay(ctx context.Context) {
+	logger := slog.Default().With("job", "word_of_day", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in words of the day", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	hour := s.clock.Now().Hour()
+	users, err := database.NewUserRepository().GetUsersForWordOfDay(ctx, hour)
 	if err != nil {
-		log.Printf("Error getting users for notification: %v", err)
+		logger.Error("failed to get users for word of the day", "error", err)
 		return
 	}
 
-	if len(users) == 0 {
-		log.Printf("No users to notify at hour %d", currentHour)
+	sent := 0
+	for _, user := range users {
+		if err := s.notifier.SendWordOfDay(ctx, user.TelegramID); err != nil {
+			logger.Error("failed to send word of the day", "user_id", user.ID, "error", err)
+			continue
+		}
+		sent++
+	}
+	logger.Info("words of the day completed", "hour", hour, "users", sent)
+}
+
+// protectIdleStreaks keeps today from breaking the streaks of users who had nothing due
+func (s *Scheduler) protectIdleStreaks(ctx context.Context) {
+	logger := slog.Default().With("job", "streak_protection", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in streak protection", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	protected, err := database.NewActivityRepositoryWithClock(s.clock).ProtectIdleDay(ctx)
+	if err != nil {
+		logger.Error("failed to protect streaks", "error", err)
 		return
 	}
+	logger.Info("streak protection completed", "users", protected)
+}
 
-	log.Printf("Found %d users to notify", len(users))
+// pruneNotificationLog deletes the days of the notification log no longer needed for deduplication
+func (s *Scheduler) pruneNotificationLog(ctx context.Context) {
+	logger := slog.Default().With("job", "notification_log", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
 
-	// Get repetitions for each user
-	repetitionRepo := database.NewRepetitionRepository()
-	for _, user := range users {
-		log.Printf("Processing reminders for user %d", user.ID)
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in notification log pruning", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
 
-		// Get due repetitions for user
-		repetitions, err := repetitionRepo.GetDueRepetitions(ctx, user.ID)
-		if err != nil {
-			log.Printf("Error getting due repetitions for user %d: %v", user.ID, err)
+	deleted, err := database.NewNotificationRepositoryWithClock(s.clock).Prune(ctx, notificationLogDays)
+	if err != nil {
+		logger.Error("failed to prune notification log", "error", err)
+		return
+	}
+	logger.Info("notification log pruned", "entries", deleted)
+
+	deleted, err = database.NewDeliveryRepositoryWithClock(s.clock).Prune(ctx, notificationLogDays)
+	if err != nil {
+		logger.Error("failed to prune notification queue", "error", err)
+		return
+	}
+	logger.Info("notification queue pruned", "entries", deleted)
+}
+
+// retryDeliveries sends again the notifications that failed to send and are due for another attempt
+func (s *Scheduler) retryDeliveries(ctx context.Context) {
+	logger := slog.Default().With("job", "delivery_retries", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in delivery retries", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	if err := s.notifier.RetryDeliveries(ctx); err != nil {
+		logger.Error("failed to retry deliveries", "error", err)
+	}
+}
+
+// pruneTrash permanently deletes the topics whose undo window is over
+func (s *Scheduler) pruneTrash(ctx context.Context) {
+	logger := slog.Default().With("job", "topic_trash", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in trash pruning", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	deleted, err := database.NewTopicRepositoryWithClock(s.clock).PruneTrash(ctx, s.clock.Now().Add(-s.config.TrashRetention))
+	if err != nil {
+		logger.Error("failed to prune topic trash", "error", err)
+		return
+	}
+	logger.Info("topic trash pruned", "entries", deleted)
+}
+
+// applyOverduePolicies handles the long overdue repetitions of the users with an overdue policy
+func (s *Scheduler) applyOverduePolicies(ctx context.Context) {
+	logger := slog.Default().With("job", "overdue_policies", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in overdue policies", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	users, err := database.NewUserRepository().GetUsersWithOverduePolicy(ctx)
+	if err != nil {
+		logger.Error("failed to get users with overdue policy", "error", err)
+		return
+	}
+
+	applied := 0
+	for _, user := range users {
+		if err := s.notifier.ApplyOverduePolicy(ctx, user.TelegramID); err != nil {
+			logger.Error("failed to apply overdue policy", "user_id", user.ID, "error", err)
 			continue
 		}
+		applied++
+	}
+	logger.Info("overdue policies completed", "users", applied)
+}
+
+// sendStalledPrompts asks the users with stalled topics to revive or archive them
+func (s *Scheduler) sendStalledPrompts(ctx context.Context) {
+	logger := slog.Default().With("job", "stalled_prompts", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in stalled prompts", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	users, err := database.NewUserRepository().GetUsersWithStalledTopics(ctx)
+	if err != nil {
+		logger.Error("failed to get users with stalled topics", "error", err)
+		return
+	}
 
-		if len(repetitions) == 0 {
-			log.Printf("No due repetitions for user %d", user.ID)
+	sent := 0
+	for _, user := range users {
+		if err := s.notifier.SendStalledPrompt(ctx, user.TelegramID); err != nil {
+			logger.Error("failed to send stalled prompt", "user_id", user.ID, "error", err)
 			continue
 		}
+		sent++
+	}
+	logger.Info("stalled prompts completed", "users", sent)
+}
 
-		log.Printf("Found %d due repetitions for user %d", len(repetitions), user.ID)
+// balanceReviewLoad moves the repetitions over the users' daily review limits to the following days
+func (s *Scheduler) balanceReviewLoad(ctx context.Context) {
+	logger := slog.Default().With("job", "load_balancing", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in load balancing", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	users, err := database.NewUserRepository().GetUsersWithReviewLimit(ctx)
+	if err != nil {
+		logger.Error("failed to get users with review limit", "error", err)
+		return
+	}
 
-		// Send notification
-		if err := s.notifier.SendReminders(user.TelegramID, len(repetitions)); err != nil {
-			log.Printf("Error sending reminder to user %d: %v", user.ID, err)
+	repetitions := database.NewRepetitionRepositoryWithClock(s.clock)
+	moved := 0
+	for _, user := range users {
+		n, err := repetitions.Rebalance(ctx, user.ID, user.DailyReviewLimit, database.LoadBalanceDays)
+		if err != nil {
+			logger.Error("failed to balance review load", "user_id", user.ID, "error", err)
 			continue
 		}
+		moved += n
+	}
+	logger.Info("load balancing completed", "users", len(users), "repetitions", moved)
+}
+
+// tuneSM2 tunes the SM-2 parameters of the users with enough recent reviews
+func (s *Scheduler) tuneSM2(ctx context.Context) {
+	logger := slog.Default().With("job", "sm2_tuning", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in SM-2 tuning", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	sm2 := spaced_repetition.NewSM2()
+	sm2.Clock = s.clock
+	tuned, err := service.NewRepetitionService(s.clock, sm2).TuneAllSM2(ctx)
+	if err != nil {
+		logger.Error("failed to tune SM-2 parameters", "error", err)
+		return
+	}
+	logger.Info("SM-2 tuning completed", "users", tuned)
+}
+
+// rateWordDifficulty recomputes the difficulty of the words the users have reviewed
+func (s *Scheduler) rateWordDifficulty(ctx context.Context) {
+	logger := slog.Default().With("job", "word_difficulty", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in word difficulty rating", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	changed, err := service.NewDifficultyService(s.clock).RateAllWords(ctx)
+	if err != nil {
+		logger.Error("failed to rate word difficulty", "error", err)
+		return
+	}
+	logger.Info("word difficulty rating completed", "words", changed)
+}
+
+// sendWeeklyReports sends the weekly report to the users who get it on this day at this hour
+func (s *Scheduler) sendWeeklyReports(ctx context.Context) {
+	logger := slog.Default().With("job", "weekly_reports", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in weekly reports", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
 
-		log.Printf("Successfully sent reminder to user %d", user.ID)
+	now := s.clock.Now()
+	users, err := database.NewUserRepository().GetUsersForWeeklyReport(ctx, now.Weekday(), now.Hour())
+	if err != nil {
+		logger.Error("failed to get users for weekly report", "error", err)
+		return
+	}
+
+	sent := 0
+	for _, user := range users {
+		if err := s.notifier.SendWeeklyReport(ctx, user.TelegramID); err != nil {
+			logger.Error("failed to send weekly report", "user_id", user.ID, "error", err)
+			continue
+		}
+		sent++
 	}
+	logger.Info("weekly reports completed", "weekday", now.Weekday().String(), "hour", now.Hour(), "users", sent)
+}
+
+// backUpDatabase backs up the database into the configured store and deletes the old backups
+func (s *Scheduler) backUpDatabase(ctx context.Context) {
+	logger := slog.Default().With("job", "backups", "request_id", logging.NewRequestID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("recovered from panic in backup", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
 
-	log.Println("Reminder check completed")
+	if s.config.Backup == nil {
+		logger.Warn("no backup storage configured, set BACKUP_DIR or BACKUP_S3_BUCKET")
+		return
+	}
+	object, err := s.config.Backup.Run(ctx)
+	if object == nil {
+		logger.Error("failed to back up database", "error", err)
+		return
+	}
+	if err != nil {
+		logger.Error("failed to delete old backups", "error", err)
+	}
+	logger.Info("database backed up", "name", object.Name, "size", object.Size)
 }
 
 // RunManualCheck forces a check for a specific user
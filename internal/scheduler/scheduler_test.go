@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/database/dbtest"
+	"github.com/example/engbot/pkg/models"
+)
+
+// recordingNotifier records the users it was asked to send weekly reports to
+type recordingNotifier struct {
+	mu      sync.Mutex
+	reports []int64
+}
+
+func (n *recordingNotifier) CheckDueRepetitions(ctx context.Context) error          { return nil }
+func (n *recordingNotifier) SendReminders(userID int64, count int) error            { return nil }
+func (n *recordingNotifier) SendDailyStory(ctx context.Context, userID int64) error { return nil }
+func (n *recordingNotifier) SendWordOfDay(ctx context.Context, userID int64) error  { return nil }
+func (n *recordingNotifier) ApplyOverduePolicy(ctx context.Context, userID int64) error {
+	return nil
+}
+func (n *recordingNotifier) SendStalledPrompt(ctx context.Context, userID int64) error {
+	return nil
+}
+func (n *recordingNotifier) RetryDeliveries(ctx context.Context) error { return nil }
+
+func (n *recordingNotifier) SendWeeklyReport(ctx context.Context, userID int64) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.reports = append(n.reports, userID)
+	return nil
+}
+
+// sent returns the users reported to so far and forgets them
+func (n *recordingNotifier) sent() []int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sent := n.reports
+	n.reports = nil
+	return sent
+}
+
+func TestWeeklyReportsFollowClock(t *testing.T) {
+	dbtest.Open(t)
+	ctx := context.Background()
+	users := database.NewUserRepository()
+
+	// Wednesday at 18:00 and Sunday at 9:00
+	wednesday := dbtest.User(t, 101)
+	wednesday.ReportEnabled, wednesday.ReportDay, wednesday.ReportHour = true, int(time.Wednesday), 18
+	sunday := dbtest.User(t, 102)
+	sunday.ReportEnabled, sunday.ReportDay, sunday.ReportHour = true, int(time.Sunday), 9
+	for _, user := range []*models.User{wednesday, sunday} {
+		if err := users.Update(ctx, user); err != nil {
+			t.Fatalf("failed to update user: %v", err)
+		}
+	}
+
+	notifier := &recordingNotifier{}
+	fake := clock.NewFake(time.Date(2026, 4, 15, 18, 0, 5, 0, time.UTC)) // a Wednesday
+	s := NewWithClock(notifier, fake, DefaultConfig())
+
+	tests := []struct {
+		at   time.Time
+		want []int64
+	}{
+		{time.Date(2026, 4, 15, 18, 0, 5, 0, time.UTC), []int64{101}},
+		{time.Date(2026, 4, 15, 19, 0, 5, 0, time.UTC), nil},
+		{time.Date(2026, 4, 19, 9, 0, 5, 0, time.UTC), []int64{102}},
+		{time.Date(2026, 4, 22, 18, 0, 5, 0, time.UTC), []int64{101}},
+	}
+	for _, tt := range tests {
+		fake.Set(tt.at)
+		s.sendWeeklyReports(ctx)
+		if got := notifier.sent(); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: reports to %v, want %v", tt.at.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestLoadBalancingFollowsClock(t *testing.T) {
+	dbtest.Open(t)
+	ctx := context.Background()
+
+	user := dbtest.User(t, 201)
+	user.DailyReviewLimit = 2
+	if err := database.NewUserRepository().Update(ctx, user); err != nil {
+		t.Fatalf("failed to update user: %v", err)
+	}
+
+	now := time.Date(2026, 6, 3, 8, 0, 0, 0, time.UTC)
+	for i, name := range []string{"a", "b", "c", "d"} {
+		dbtest.Topic(t, user.ID, name, 1, now.Add(time.Duration(i+1)*time.Hour))
+	}
+
+	// A day before the four repetitions come due the job sees them all on tomorrow
+	fake := clock.NewFake(now.AddDate(0, 0, -1))
+	s := NewWithClock(&recordingNotifier{}, fake, DefaultConfig())
+	s.balanceReviewLoad(ctx)
+
+	forecast, err := database.NewRepetitionRepositoryWithClock(fake).Forecast(ctx, user.ID, 3)
+	if err != nil {
+		t.Fatalf("failed to get forecast: %v", err)
+	}
+	if want := []int{0, 2, 2}; !slices.Equal(forecast, want) {
+		t.Errorf("forecast from %s: %v, want %v", fake.Now().Format("Jan 2"), forecast, want)
+	}
+
+	reps, err := database.NewRepetitionRepository().GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("failed to get repetitions: %v", err)
+	}
+	moved := 0
+	for _, rep := range reps {
+		if rep.NextReviewDate.Equal(time.Date(2026, 6, 4, 0, 0, 0, 0, time.UTC)) {
+			moved++
+		}
+	}
+	if moved != 2 {
+		t.Errorf("%d repetitions moved to the start of the next day, want 2", moved)
+	}
+}
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// webhookTimeout bounds one webhook call
+const webhookTimeout = 10 * time.Second
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of the body, "sha256=<hex>", when the channel
+// has a secret, so the receiver can tell the calls come from the bot
+const WebhookSignatureHeader = "X-EngBot-Signature"
+
+// errPrivateAddress is returned for webhooks pointing into the bot's own network
+var errPrivateAddress = errors.New("webhook address is not public")
+
+// WebhookChannel posts notifications as JSON to the user's URL, e.g. to a chat or automation
+// service
+type WebhookChannel struct {
+	secret string
+	client *http.Client
+}
+
+// webhookPayload is the body of a webhook call
+type webhookPayload struct {
+	Kind    string    `json:"kind"`
+	Subject string    `json:"subject"`
+	Text    string    `json:"text"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// NewWebhookChannel creates a webhook channel signing the calls with the secret, unsigned when
+// it is empty. Users pick the URLs, so the calls only go to public addresses.
+func NewWebhookChannel(secret string) *WebhookChannel {
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: dialPublicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &WebhookChannel{
+		secret: secret,
+		client: &http.Client{
+			Timeout:   webhookTimeout,
+			Transport: transport,
+			// A redirect would be followed to any address the receiver names
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+// Name implements Channel
+func (c *WebhookChannel) Name() string {
+	return "webhook"
+}
+
+// Validate accepts an https URL
+func (c *WebhookChannel) Validate(address string) error {
+	u, err := url.Parse(address)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%q is not an https URL", address)
+	}
+	return nil
+}
+
+// Send implements Channel
+func (c *WebhookChannel) Send(ctx context.Context, address string, n Notification) error {
+	body, err := json.Marshal(webhookPayload{Kind: n.Kind, Subject: n.Subject, Text: n.Text, SentAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "engbot")
+	if c.secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.secret))
+		mac.Write(body)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// dialPublicOnly refuses connections to loopback, private and link-local addresses. It runs
+// after the name is resolved, so a public name pointing inside is refused too.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return errPrivateAddress
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Package service holds the operations that change several repositories at once
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/spaced_repetition"
+	"github.com/example/engbot/pkg/models"
+)
+
+// CycleRepetitions is the number of repetitions a topic goes through until it is learned
+const CycleRepetitions = 7
+
+// MaintenanceIntervals are the days between the maintenance reviews of a topic that has finished
+// its cycle: the first one comes after 60 days, the following ones every 90
+var MaintenanceIntervals = []int{60, 90}
+
+// MaintenanceInterval returns the days until the maintenance review with the given repetition
+// number, the first one after the cycle being CycleRepetitions+1
+func MaintenanceInterval(number int) int {
+	return MaintenanceIntervals[max(0, min(number-CycleRepetitions-1, len(MaintenanceIntervals)-1))]
+}
+
+// ErrTopicNotFound is returned when the topic of a repetition is gone
+var ErrTopicNotFound = errors.New("topic not found")
+
+// RepetitionService completes the repetitions of topics
+type RepetitionService struct {
+	repetitions *database.RepetitionRepository
+	topics      *database.TopicRepository
+	sm2         *spaced_repetition.SM2
+	leitner     *spaced_repetition.Leitner
+	clock       clock.Clock
+}
+
+// NewRepetitionService creates a service that reads the current time from c
+func NewRepetitionService(c clock.Clock, sm2 *spaced_repetition.SM2) *RepetitionService {
+	leitner := spaced_repetition.NewLeitner()
+	leitner.Clock = c
+	return &RepetitionService{
+		repetitions: database.NewRepetitionRepositoryWithClock(c),
+		topics:      database.NewTopicRepositoryWithClock(c),
+		sm2:         sm2,
+		leitner:     leitner,
+		clock:       c,
+	}
+}
+
+// scheduler returns the scheduler the user picked in the settings, SM-2 with the user's tuned
+// parameters unless it is Leitner
+func (s *RepetitionService) scheduler(ctx context.Context, user *models.User) (spaced_repetition.Scheduler, error) {
+	if user.Scheduler == models.SchedulerLeitner {
+		return s.leitner, nil
+	}
+	return s.SM2For(ctx, user.ID)
+}
+
+// CompletionResult is the outcome of a completed repetition
+type CompletionResult struct {
+	Repetition *models.Repetition // the completed repetition
+	Topic      *models.Topic
+	Passed     bool               // the topic moves on to the next repetition
+	Next       *models.Repetition // nil when the topic has gone through the whole cycle
+}
+
+// Complete marks the user's repetition as done with the answer quality. The topic gets its next
+// interval from the user's scheduler, SM-2 or Leitner boxes: a passed answer moves it to the next repetition, a failed one repeats the same
+// repetition. After the cycle a topic with maintenance on gets a maintenance review instead of
+// ending. The repetition, the topic, the next repetition and the statistics are saved in one
+// transaction. Returns database.ErrAlreadyCompleted for a repetition done before.
+func (s *RepetitionService) Complete(ctx context.Context, user *models.User, repID int64, quality spaced_repetition.QualityResponse) (*CompletionResult, error) {
+	rep, err := s.repetitions.GetByID(ctx, user.ID, repID)
+	if err != nil {
+		return nil, err
+	}
+	if rep.Completed {
+		return nil, database.ErrAlreadyCompleted
+	}
+	topic, err := s.topics.GetByID(ctx, user.ID, rep.TopicID)
+	if err != nil {
+		return nil, err
+	}
+	if topic == nil {
+		return nil, ErrTopicNotFound
+	}
+	intervals, err := database.GetUserIntervals(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	intervals = database.TopicIntervals(topic, intervals)
+	scheduler, err := s.scheduler(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	nextReview := scheduler.ProcessTopicAt(topic, quality, intervals, now)
+	completion := &database.Completion{
+		Repetition: rep,
+		Topic:      topic,
+		ReviewedAt: now,
+		Passed:     scheduler.Passes(quality),
+	}
+	maintenance := completion.Passed && topic.Maintenance && rep.RepetitionNumber >= CycleRepetitions
+	if !completion.Passed || rep.RepetitionNumber < CycleRepetitions || maintenance {
+		number := rep.RepetitionNumber
+		if completion.Passed {
+			number++
+		}
+		if maintenance {
+			nextReview = now.AddDate(0, 0, MaintenanceInterval(number))
+		}
+		completion.Next = &models.Repetition{
+			UserID:           user.ID,
+			TopicID:          rep.TopicID,
+			RepetitionNumber: number,
+			NextReviewDate:   s.spillDate(ctx, user, nextReview),
+			// A failed maintenance review is repeated as one
+			Maintenance: maintenance || rep.Maintenance,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+	}
+
+	if err := s.repetitions.Complete(ctx, completion); err != nil {
+		return nil, fmt.Errorf("failed to complete repetition %d: %w", repID, err)
+	}
+	return &CompletionResult{
+		Repetition: rep,
+		Topic:      topic,
+		Passed:     completion.Passed,
+		Next:       completion.Next,
+	}, nil
+}
+
+// spillDate moves the date of the next repetition past the days the user has already filled up.
+// Failures only cost the balance, so they are logged and the date is kept.
+func (s *RepetitionService) spillDate(ctx context.Context, user *models.User, date time.Time) time.Time {
+	spilled, err := s.repetitions.SpillDate(ctx, user.ID, date, user.DailyReviewLimit)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to balance review date", "user_id", user.ID, "error", err)
+		return date
+	}
+	return spilled
+}
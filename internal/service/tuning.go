@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/spaced_repetition"
+)
+
+// TuningWindow is how far back the recall history the SM-2 parameters are tuned from goes
+const TuningWindow = 90 * 24 * time.Hour
+
+// SM2For returns the SM-2 scheduler with the user's tuned parameters, the default one until
+// the first tuning
+func (s *RepetitionService) SM2For(ctx context.Context, userID int64) (*spaced_repetition.SM2, error) {
+	config, err := database.GetUserConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil || !config.SM2TunedAt.Valid {
+		return s.sm2, nil
+	}
+	return s.sm2.WithParams(spaced_repetition.Params{
+		PassThreshold:    config.SM2PassThreshold,
+		InitialIntervals: parseDays(config.SM2InitialIntervals),
+		MinEasiness:      config.SM2MinEasiness,
+	}), nil
+}
+
+// TuneSM2 tunes the user's SM-2 parameters from the reviews of the last TuningWindow and stores
+// them. It reports false and keeps the parameters while the history is too short.
+func (s *RepetitionService) TuneSM2(ctx context.Context, userID int64) (spaced_repetition.Params, bool, error) {
+	now := s.clock.Now()
+	history, err := s.repetitions.GetRecallHistory(ctx, userID, now.Add(-TuningWindow))
+	if err != nil {
+		return spaced_repetition.Params{}, false, err
+	}
+
+	outcomes := make([]spaced_repetition.RecallOutcome, len(history))
+	for i, recall := range history {
+		outcomes[i] = spaced_repetition.RecallOutcome{
+			LateDays: max(0, recall.LastReviewDate.Sub(recall.NextReviewDate).Hours()/24),
+			Passed:   recall.Passed,
+		}
+	}
+	params, ok := spaced_repetition.Tune(outcomes, s.sm2.Params())
+	if !ok {
+		return params, false, nil
+	}
+	err = database.SetSM2Params(ctx, userID, params.PassThreshold, params.InitialIntervals, params.MinEasiness, now)
+	if err != nil {
+		return params, false, err
+	}
+	return params, true, nil
+}
+
+// TuneAllSM2 tunes the SM-2 parameters of every user with enough recent reviews and returns
+// how many were tuned
+func (s *RepetitionService) TuneAllSM2(ctx context.Context) (int, error) {
+	userIDs, err := s.repetitions.GetUsersWithRecallHistory(ctx, s.clock.Now().Add(-TuningWindow), spaced_repetition.MinTuningReviews)
+	if err != nil {
+		return 0, err
+	}
+
+	tuned := 0
+	for _, userID := range userIDs {
+		params, ok, err := s.TuneSM2(ctx, userID)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to tune SM-2 parameters", "user_id", userID, "error", err)
+			continue
+		}
+		if ok {
+			logging.FromContext(ctx).Debug("tuned SM-2 parameters", "user_id", userID,
+				"pass_threshold", params.PassThreshold, "min_easiness", params.MinEasiness)
+			tuned++
+		}
+	}
+	return tuned, nil
+}
+
+// parseDays reads a comma-separated list of days as FormatIntervals writes it, nil for an
+// empty or broken one
+func parseDays(s string) []int {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var days []int
+	for _, field := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil
+		}
+		days = append(days, n)
+	}
+	return days
+}
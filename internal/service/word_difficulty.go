@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/logging"
+	"github.com/example/engbot/internal/spaced_repetition"
+)
+
+// DifficultyWindow is how far back the tests the word difficulties are rated from go
+const DifficultyWindow = 90 * 24 * time.Hour
+
+// DifficultyService rates the difficulty of the users' words from their reviews and tests
+type DifficultyService struct {
+	words       *database.WordRepository
+	testResults *database.TestResultRepository
+	clock       clock.Clock
+}
+
+// NewDifficultyService creates a service that reads the current time from c
+func NewDifficultyService(c clock.Clock) *DifficultyService {
+	return &DifficultyService{
+		words:       database.NewWordRepository(),
+		testResults: database.NewTestResultRepository(),
+		clock:       c,
+	}
+}
+
+// RateWords recomputes the difficulty of the user's reviewed words and returns how many changed.
+// Words not reviewed yet keep theirs.
+func (s *DifficultyService) RateWords(ctx context.Context, userID int64) (int, error) {
+	words, err := s.words.GetReviewedWordEasiness(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	answers, err := s.testResults.GetTopicAnswers(ctx, userID, s.clock.Now().Add(-DifficultyWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	changed := make(map[int]int)
+	for _, w := range words {
+		errorRate := -1.0
+		if a := answers[w.TopicID]; a.Total >= spaced_repetition.MinTestAnswers {
+			errorRate = float64(a.Wrong) / float64(a.Total)
+		}
+		if difficulty := spaced_repetition.WordDifficulty(w.EasinessFactor, errorRate); difficulty != w.Difficulty {
+			changed[w.WordID] = difficulty
+		}
+	}
+	if err := s.words.SetDifficulties(ctx, userID, changed); err != nil {
+		return 0, err
+	}
+	return len(changed), nil
+}
+
+// RateAllWords recomputes the word difficulties of every active user who has reviewed words and
+// returns how many words changed
+func (s *DifficultyService) RateAllWords(ctx context.Context) (int, error) {
+	userIDs, err := s.words.GetUsersWithReviewedWords(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, userID := range userIDs {
+		n, err := s.RateWords(ctx, userID)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to rate word difficulty", "user_id", userID, "error", err)
+			continue
+		}
+		changed += n
+	}
+	return changed, nil
+}
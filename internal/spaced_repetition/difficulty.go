@@ -0,0 +1,36 @@
+package spaced_repetition
+
+// MinTestAnswers is how many test answers about a topic it takes for its error rate to count
+const MinTestAnswers = 10
+
+// WordDifficulty rates how hard a reviewed word is for the user on the 1-5 scale of
+// models.Word.Difficulty. The easiness factor sets the base: each slip lowers it, so a word
+// at 2.5 or above is easy and one below 1.7 is very hard. Tests of the word's topic move
+// it a step: 40% wrong answers or more make it harder, 10% or less easier. A negative
+// testErrorRate means the topic hasn't been tested enough.
+//
+//	EF         2.5+  2.3+  2.0+  1.7+  lower
+//	difficulty 1     2     3     4     5
+func WordDifficulty(easiness, testErrorRate float64) int {
+	var difficulty int
+	switch {
+	case easiness >= 2.5:
+		difficulty = 1
+	case easiness >= 2.3:
+		difficulty = 2
+	case easiness >= 2.0:
+		difficulty = 3
+	case easiness >= 1.7:
+		difficulty = 4
+	default:
+		difficulty = 5
+	}
+
+	switch {
+	case testErrorRate >= 0.4:
+		difficulty++
+	case testErrorRate >= 0 && testErrorRate <= 0.1:
+		difficulty--
+	}
+	return max(1, min(difficulty, 5))
+}
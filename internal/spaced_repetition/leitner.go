@@ -0,0 +1,64 @@
+package spaced_repetition
+
+import (
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/pkg/models"
+)
+
+// LeitnerBoxes is how many boxes a topic goes through
+const LeitnerBoxes = 5
+
+// Leitner is the Leitner box system, a simpler alternative to SM2: every box has a fixed
+// interval, a passed answer moves the topic one box up and a failed one back to the first box.
+type Leitner struct {
+	// BoxIntervals is the interval in days of each box, the first box first
+	BoxIntervals [LeitnerBoxes]int
+	// Источник текущего времени
+	Clock clock.Clock
+}
+
+// NewLeitner creates a Leitner scheduler with boxes of 1, 3, 7, 14 and 30 days
+func NewLeitner() *Leitner {
+	return &Leitner{
+		BoxIntervals: [LeitnerBoxes]int{1, 3, 7, 14, 30},
+		Clock:        clock.System{},
+	}
+}
+
+// Passes reports whether the answer moves the topic up a box
+func (l *Leitner) Passes(quality QualityResponse) bool {
+	return quality >= QualityCorrectDifficult
+}
+
+// ProcessTopic runs a graded topic review through ProcessTopicAt at the current time
+func (l *Leitner) ProcessTopic(topic *models.Topic, quality QualityResponse, intervals []int) time.Time {
+	now := time.Now()
+	if l.Clock != nil {
+		now = l.Clock.Now()
+	}
+	return l.ProcessTopicAt(topic, quality, intervals, now)
+}
+
+// ProcessTopicAt moves the topic to its next box and returns the review date after the box
+// interval. The interval ladder doesn't apply: the boxes have intervals of their own.
+//
+// Example with the default boxes, starting from the first one:
+//
+//	answers                 boxes    next intervals
+//	Good, Good, Good        2, 3, 4  3, 7, 14
+//	Good, Fail, Good        2, 1, 2  3, 1, 3
+func (l *Leitner) ProcessTopicAt(topic *models.Topic, quality QualityResponse, intervals []int, now time.Time) time.Time {
+	box := max(1, min(topic.LeitnerBox, LeitnerBoxes))
+	if l.Passes(quality) {
+		box = min(box+1, LeitnerBoxes)
+		topic.ReviewCount++
+	} else {
+		box = 1
+	}
+
+	topic.LeitnerBox = box
+	topic.ReviewInterval = l.BoxIntervals[box-1]
+	return now.AddDate(0, 0, topic.ReviewInterval)
+}
@@ -0,0 +1,22 @@
+package spaced_repetition
+
+import (
+	"time"
+
+	"github.com/example/engbot/pkg/models"
+)
+
+// Scheduler picks the next review of a topic after a graded answer and keeps its state on the
+// topic. SM2 and Leitner implement it, the user chooses between them in /settings.
+type Scheduler interface {
+	// ProcessTopicAt updates the topic after the answer given at now and returns the next
+	// review date. intervals is the user's or the topic's ladder in days.
+	ProcessTopicAt(topic *models.Topic, quality QualityResponse, intervals []int, now time.Time) time.Time
+	// Passes reports whether the answer counts as remembered and moves the topic on
+	Passes(quality QualityResponse) bool
+}
+
+var (
+	_ Scheduler = (*SM2)(nil)
+	_ Scheduler = (*Leitner)(nil)
+)
@@ -1,9 +1,11 @@
 package spaced_repetition
 
 import (
+	"math"
 	"sort"
 	"time"
 
+	"github.com/example/engbot/internal/clock"
 	"github.com/example/engbot/pkg/models"
 )
 
@@ -15,6 +17,10 @@ type SM2 struct {
 	MaxInterval int
 	// Начальные интервалы повторения в днях
 	InitialIntervals []int
+	// Нижняя граница фактора легкости
+	MinEasiness float64
+	// Источник текущего времени
+	Clock clock.Clock
 }
 
 // NewSM2 создает новый экземпляр SM2 с настройками по умолчанию
@@ -23,9 +29,19 @@ func NewSM2() *SM2 {
 		PassThreshold:    3, // Ответы 3 и выше считаются успешными
 		MaxInterval:      365, // Максимальный интервал - 1 год
 		InitialIntervals: []int{0, 1, 2, 3, 7, 10, 15, 20, 30}, // Предустановленные интервалы для первых повторений
+		MinEasiness:      1.3, // Фактор легкости не опускается ниже 1.3
+		Clock:            clock.System{},
 	}
 }
 
+// now returns the current time from the configured clock
+func (sm *SM2) now() time.Time {
+	if sm.Clock == nil {
+		return time.Now()
+	}
+	return sm.Clock.Now()
+}
+
 // QualityResponse represents the quality of response in SM-2
 type QualityResponse int
 
@@ -46,22 +62,42 @@ const (
 
 // Process implements the SM-2 algorithm to update user progress
 func (sm *SM2) Process(progress *models.UserProgress, quality QualityResponse) {
-	// Record the last review date
-	now := time.Now()
-	progress.LastReviewDate = now.Format(time.RFC3339)
+	sm.ProcessAt(progress, quality, sm.now())
+}
+
+// ProcessAt is Process with an explicit review time, so the resulting dates are deterministic.
+//
+// Expected values with the default settings, starting from a fresh progress (EF 2.5)
+// and answering QualityPerfect every time:
+//
+//	step  repetitions  interval  EF
+//	1     1            0         2.6
+//	2     2            1         2.7
+//	3     3            2         2.8
+//	...   n            InitialIntervals[n-1]
+//	10    10           30*EF     EF+0.1 (clamped to MaxInterval)
+//
+// Any answer below PassThreshold resets the interval to 1 day and
+// ConsecutiveRight to 0, keeps Repetitions, and lowers EF (never below MinEasiness).
+func (sm *SM2) ProcessAt(progress *models.UserProgress, quality QualityResponse, now time.Time) {
+	// Record the last review date, and the first one of a brand-new word
+	progress.LastReviewDate = now
+	if progress.IntroducedAt == nil {
+		progress.IntroducedAt = &now
+	}
 	progress.LastQuality = int(quality)
 	
 	// Calculate the easiness factor (EF)
 	newEF := progress.EasinessFactor + (0.1 - (5.0-float64(quality))*(0.08+(5.0-float64(quality))*0.02))
 	
 	// Ensure minimum easiness factor
-	if newEF < 1.3 {
-		newEF = 1.3 // Не опускаем ниже 1.3
+	if newEF < sm.MinEasiness {
+		newEF = sm.MinEasiness
 	}
 	progress.EasinessFactor = newEF
 	
 	// Handle correct/incorrect response
-	if quality >= QualityCorrectDifficult {
+	if sm.Passes(quality) {
 		// Correct response
 		progress.ConsecutiveRight++
 		
@@ -94,75 +130,126 @@ func (sm *SM2) Process(progress *models.UserProgress, quality QualityResponse) {
 	}
 	
 	// Set the next review date
-	nextDate := now.AddDate(0, 0, progress.Interval)
-	progress.NextReviewDate = nextDate.Format(time.RFC3339)
+	progress.NextReviewDate = now.AddDate(0, 0, progress.Interval)
+}
+
+// Passes reports whether the answer counts as remembered
+func (sm *SM2) Passes(quality QualityResponse) bool {
+	return int(quality) >= sm.PassThreshold
 }
 
-// GetNextWords returns the next n words due for review for a user
-func (sm *SM2) GetNextWords(userProgress []models.UserProgress, limit int) []models.UserProgress {
+// GetNextWords returns the next n words due for review for a user, with at most newLimit
+// brand-new words among them (a negative newLimit doesn't cap them)
+func (sm *SM2) GetNextWords(userProgress []models.UserProgress, limit, newLimit int) []models.UserProgress {
+	return sm.GetNextWordsAt(userProgress, limit, newLimit, sm.now())
+}
+
+// GetNextWordsAt is GetNextWords evaluated at the given moment.
+//
+// Words seen before are sorted by priority: the lowest easiness factor (hardest words) first,
+// then the most overdue. Brand-new words follow the order they were added in, and only the first
+// newLimit of them are taken, so a large deck comes up a few words a day instead of all at once.
+// The new words are spread evenly among the reviews: with 6 reviews and 3 new words the order
+// is R N R R N R R N R.
+func (sm *SM2) GetNextWordsAt(userProgress []models.UserProgress, limit, newLimit int, now time.Time) []models.UserProgress {
 	// Filter words due for review (next_review_date <= now)
-	now := time.Now()
-	var dueProgress []models.UserProgress
-	
+	var reviews, fresh []models.UserProgress
 	for _, p := range userProgress {
-		// Parse the date and compare with current time
-		nextReviewDate, err := time.Parse(time.RFC3339, p.NextReviewDate)
-		if err != nil || !nextReviewDate.After(now) {
-			dueProgress = append(dueProgress, p)
-		}
-	}
-	
-	// Sort due items by priority:
-	// 1. Words that have never been reviewed (repetitions = 0)
-	// 2. Words with lowest easiness factor (hardest words)
-	// 3. Words with earliest next review date
-	
-	// Sort by priority criteria
-	sort.Slice(dueProgress, func(i, j int) bool {
-		// First priority: words that have never been reviewed
-		if dueProgress[i].Repetitions == 0 && dueProgress[j].Repetitions > 0 {
-			return true
+		if p.NextReviewDate.After(now) {
+			continue
 		}
-		if dueProgress[j].Repetitions == 0 && dueProgress[i].Repetitions > 0 {
-			return false
-		}
-		
-		// Second priority: words with lower easiness factor (harder words)
-		if dueProgress[i].EasinessFactor < dueProgress[j].EasinessFactor {
-			return true
-		}
-		if dueProgress[i].EasinessFactor > dueProgress[j].EasinessFactor {
-			return false
-		}
-		
-		// Third priority: words that are more overdue
-		// Parse dates first
-		nextReviewDateI, errI := time.Parse(time.RFC3339, dueProgress[i].NextReviewDate)
-		nextReviewDateJ, errJ := time.Parse(time.RFC3339, dueProgress[j].NextReviewDate)
-		
-		// If both dates are valid, compare them
-		if errI == nil && errJ == nil {
-			return nextReviewDateI.Before(nextReviewDateJ)
-		}
-		
-		// If one date is invalid, prioritize valid dates
-		if errI != nil {
-			return false
+		if p.IntroducedAt == nil {
+			fresh = append(fresh, p)
+		} else {
+			reviews = append(reviews, p)
 		}
-		if errJ != nil {
-			return true
+	}
+
+	sort.Slice(reviews, func(i, j int) bool {
+		// First priority: words with lower easiness factor (harder words)
+		if reviews[i].EasinessFactor != reviews[j].EasinessFactor {
+			return reviews[i].EasinessFactor < reviews[j].EasinessFactor
 		}
-		
-		// Default: maintain original order
-		return i < j
+		// Second priority: words that are more overdue
+		return reviews[i].NextReviewDate.Before(reviews[j].NextReviewDate)
 	})
-	
-	// Return limited number of items
-	if len(dueProgress) > limit {
-		return dueProgress[:limit]
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return fresh[i].ID < fresh[j].ID
+	})
+	if newLimit >= 0 && len(fresh) > newLimit {
+		fresh = fresh[:newLimit]
 	}
-	
-	return dueProgress
+
+	// The n-th new word goes in the middle of its share of the picks
+	total := len(reviews) + len(fresh)
+	picked := make([]models.UserProgress, 0, min(limit, total))
+	r, n := 0, 0
+	for len(picked) < limit && r+n < total {
+		if n < len(fresh) && (r == len(reviews) || r+n >= (2*n+1)*total/(2*len(fresh))) {
+			picked = append(picked, fresh[n])
+			n++
+		} else {
+			picked = append(picked, reviews[r])
+			r++
+		}
+	}
+	return picked
+}
+
+// EasinessForDifficulty returns the starting easiness factor for a topic of the given
+// difficulty (1-5): harder topics start lower and so get shorter intervals
+func EasinessForDifficulty(difficulty int) float64 {
+	if difficulty < models.MinTopicDifficulty || difficulty > models.MaxTopicDifficulty {
+		difficulty = models.DefaultTopicDifficulty
+	}
+	return defaultEasiness + float64(models.DefaultTopicDifficulty-difficulty)*0.15
+}
+
+// defaultEasiness is the SM-2 starting easiness factor
+const defaultEasiness = 2.5
+
+// ProcessTopic runs a graded topic review through Process and returns the next review date.
+//
+// The SM-2 state lives on the topic; a fresh topic starts from its difficulty's easiness.
+// intervals is the base ladder in days (intervals[n] follows the n-th successful review).
+// The ladder step is scaled by EF/2.5, so "Легко" answers stretch the following intervals
+// and "Трудно" ones shrink them. A failed answer brings the topic back the next day.
+//
+// Example with the 1, 2, 3, 7, 15, 25, 40 ladder and a topic of default difficulty:
+//
+//	answers           next intervals
+//	Good, Good, Good  2, 3, 7
+//	Easy, Easy, Easy  2, 3, 8
+//	Hard, Hard, Hard  2, 3, 6
+func (sm *SM2) ProcessTopic(topic *models.Topic, quality QualityResponse, intervals []int) time.Time {
+	return sm.ProcessTopicAt(topic, quality, intervals, sm.now())
+}
+
+// ProcessTopicAt is ProcessTopic with an explicit review time
+func (sm *SM2) ProcessTopicAt(topic *models.Topic, quality QualityResponse, intervals []int, now time.Time) time.Time {
+	if topic.ReviewCount == 0 {
+		topic.EasinessFactor = EasinessForDifficulty(topic.Difficulty)
+	}
+
+	progress := models.UserProgress{
+		EasinessFactor: topic.EasinessFactor,
+		Interval:       topic.ReviewInterval,
+		Repetitions:    topic.ReviewCount,
+	}
+	sm.ProcessAt(&progress, quality, now)
+
+	interval := 1
+	if sm.Passes(quality) && len(intervals) > 0 {
+		step := min(progress.Repetitions, len(intervals)-1)
+		interval = int(math.Round(float64(intervals[step]) * progress.EasinessFactor / defaultEasiness))
+		interval = max(1, min(interval, sm.MaxInterval))
+	}
+
+	topic.EasinessFactor = progress.EasinessFactor
+	topic.ReviewInterval = interval
+	topic.ReviewCount = progress.Repetitions
+
+	return now.AddDate(0, 0, interval)
 }
 
 // IsWordMastered determines if a word is considered "mastered"
@@ -176,42 +263,18 @@ func (sm *SM2) IsWordMastered(progress *models.UserProgress) bool {
 		   progress.Interval >= 30
 }
 
-// ComputeNextInterval вычисляет следующий интервал повторения на основе ответа
-// quality - качество ответа (от 0 до 5)
-// repetitions - текущее количество повторений
-// currentEF - текущий фактор легкости
-// currentInterval - текущий интервал в днях
+// ComputeNextInterval вычисляет следующий интервал, фактор легкости и число повторений
+// по тем же правилам, что и Process: при ошибке интервал - 1 день, а число повторений
+// сохраняется; успешный ответ после n повторений получает интервал InitialIntervals[n].
+// Пример: quality=5, repetitions=1, EF=2.5 -> интервал 1, EF 2.6, повторений 2
 func (sm2 *SM2) ComputeNextInterval(quality, repetitions int, currentEF float64, currentInterval int) (int, float64, int) {
-	// Обновляем фактор легкости
-	newEF := currentEF + (0.1 - float64(5-quality)*(0.08+float64(5-quality)*0.02))
-	if newEF < 1.3 {
-		newEF = 1.3 // Не опускаем ниже 1.3
-	}
-	
-	var newInterval int
-	var newRepetitions int
-	
-	if quality >= sm2.PassThreshold {
-		// Ответ был правильным
-		newRepetitions = repetitions + 1
-		
-		if newRepetitions < len(sm2.InitialIntervals) {
-			// Используем предустановленные интервалы для начальных повторений
-			newInterval = sm2.InitialIntervals[newRepetitions]
-		} else {
-			// Для последующих повторений используем формулу
-			newInterval = int(float64(currentInterval) * newEF)
-			if newInterval > sm2.MaxInterval {
-				newInterval = sm2.MaxInterval
-			}
-		}
-	} else {
-		// Ответ был неправильным - сбрасываем прогресс
-		newRepetitions = 0
-		newInterval = 1 // Повторение на следующий день
+	progress := models.UserProgress{
+		EasinessFactor: currentEF,
+		Interval:       currentInterval,
+		Repetitions:    repetitions,
 	}
-	
-	return newInterval, newEF, newRepetitions
+	sm2.ProcessAt(&progress, QualityResponse(quality), time.Time{})
+	return progress.Interval, progress.EasinessFactor, progress.Repetitions
 }
 
 // CalculateQuality определяет качество ответа на основе затраченного времени и точности
@@ -0,0 +1,232 @@
+package spaced_repetition
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/example/engbot/internal/clock"
+	"github.com/example/engbot/pkg/models"
+)
+
+// reviewTime is the moment the tests review at
+var reviewTime = time.Date(2026, 5, 4, 9, 30, 0, 0, time.UTC)
+
+func TestProcessAtEasinessFloor(t *testing.T) {
+	tests := []struct {
+		name    string
+		ef      float64
+		quality QualityResponse
+		want    float64
+	}{
+		{"perfect raises", 2.5, QualityPerfect, 2.6},
+		{"hesitation keeps", 2.5, QualityCorrectHesitation, 2.5},
+		{"difficult lowers", 2.5, QualityCorrectDifficult, 2.36},
+		{"blackout lowers", 2.5, QualityBlackout, 1.7},
+		{"blackout at the floor", 1.3, QualityBlackout, 1.3},
+		{"familiar below the floor", 1.4, QualityIncorrectFamiliar, 1.3},
+		{"difficult just above the floor", 1.35, QualityCorrectDifficult, 1.3},
+	}
+	sm := NewSM2()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := models.UserProgress{EasinessFactor: tt.ef, Repetitions: 3, Interval: 2}
+			sm.ProcessAt(&progress, tt.quality, reviewTime)
+			if math.Abs(progress.EasinessFactor-tt.want) > 1e-9 {
+				t.Errorf("EF = %v, want %v", progress.EasinessFactor, tt.want)
+			}
+			if progress.EasinessFactor < sm.MinEasiness {
+				t.Errorf("EF %v is below the floor %v", progress.EasinessFactor, sm.MinEasiness)
+			}
+		})
+	}
+}
+
+func TestProcessAtFollowsInitialIntervals(t *testing.T) {
+	sm := NewSM2()
+	progress := models.UserProgress{EasinessFactor: 2.5}
+	for step, want := range sm.InitialIntervals {
+		sm.ProcessAt(&progress, QualityPerfect, reviewTime)
+		if progress.Interval != want {
+			t.Fatalf("review %d: interval %d, want %d", step+1, progress.Interval, want)
+		}
+		if progress.Repetitions != step+1 || progress.ConsecutiveRight != step+1 {
+			t.Fatalf("review %d: %d repetitions, %d right in a row", step+1, progress.Repetitions, progress.ConsecutiveRight)
+		}
+		if want := reviewTime.AddDate(0, 0, want); !progress.NextReviewDate.Equal(want) {
+			t.Fatalf("review %d: due %v, want %v", step+1, progress.NextReviewDate, want)
+		}
+	}
+
+	// Past the ladder the interval grows by EF
+	last := progress.Interval
+	sm.ProcessAt(&progress, QualityPerfect, reviewTime)
+	if want := int(float64(last) * progress.EasinessFactor); progress.Interval != want {
+		t.Errorf("after the ladder: interval %d, want %d", progress.Interval, want)
+	}
+	if progress.IntroducedAt == nil || !progress.IntroducedAt.Equal(reviewTime) {
+		t.Errorf("introduced at %v, want the first review %v", progress.IntroducedAt, reviewTime)
+	}
+}
+
+func TestProcessAtResetsOnFailure(t *testing.T) {
+	tests := []struct {
+		quality QualityResponse
+		reset   bool
+	}{
+		{QualityBlackout, true},
+		{QualityIncorrect, true},
+		{QualityIncorrectFamiliar, true},
+		{QualityCorrectDifficult, false},
+	}
+	sm := NewSM2()
+	for _, tt := range tests {
+		progress := models.UserProgress{EasinessFactor: 2.5, Repetitions: 6, Interval: 15, ConsecutiveRight: 4}
+		sm.ProcessAt(&progress, tt.quality, reviewTime)
+		if !tt.reset {
+			if progress.ConsecutiveRight != 5 || progress.Repetitions != 7 {
+				t.Errorf("quality %d: %d right in a row, %d repetitions after a pass", tt.quality, progress.ConsecutiveRight, progress.Repetitions)
+			}
+			continue
+		}
+		if progress.Interval != 1 || progress.ConsecutiveRight != 0 {
+			t.Errorf("quality %d: interval %d, %d right in a row; want 1 and 0", tt.quality, progress.Interval, progress.ConsecutiveRight)
+		}
+		if progress.Repetitions != 6 {
+			t.Errorf("quality %d: %d repetitions, want them kept at 6", tt.quality, progress.Repetitions)
+		}
+		if want := reviewTime.AddDate(0, 0, 1); !progress.NextReviewDate.Equal(want) {
+			t.Errorf("quality %d: due %v, want %v", tt.quality, progress.NextReviewDate, want)
+		}
+		if progress.LastQuality != int(tt.quality) || !progress.LastReviewDate.Equal(reviewTime) {
+			t.Errorf("quality %d: last quality %d reviewed %v", tt.quality, progress.LastQuality, progress.LastReviewDate)
+		}
+	}
+}
+
+func TestProcessAtClampsToMaxInterval(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxInterval int
+		interval    int
+		want        int
+	}{
+		{"below the cap", 365, 100, 260},
+		{"over the cap", 365, 200, 365},
+		{"custom cap", 90, 50, 90},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewSM2()
+			sm.MaxInterval = tt.maxInterval
+			progress := models.UserProgress{EasinessFactor: 2.5, Repetitions: 20, Interval: tt.interval}
+			sm.ProcessAt(&progress, QualityPerfect, reviewTime)
+			if progress.Interval != tt.want {
+				t.Errorf("interval %d, want %d", progress.Interval, tt.want)
+			}
+			if want := reviewTime.AddDate(0, 0, tt.want); !progress.NextReviewDate.Equal(want) {
+				t.Errorf("due %v, want %v", progress.NextReviewDate, want)
+			}
+		})
+	}
+}
+
+func TestIsWordMastered(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress models.UserProgress
+		want     bool
+	}{
+		{"mastered", models.UserProgress{Repetitions: 5, LastQuality: 4, Interval: 30}, true},
+		{"long ago mastered", models.UserProgress{Repetitions: 12, LastQuality: 5, Interval: 200}, true},
+		{"too few reviews", models.UserProgress{Repetitions: 4, LastQuality: 5, Interval: 30}, false},
+		{"last answer hard", models.UserProgress{Repetitions: 8, LastQuality: 3, Interval: 60}, false},
+		{"interval too short", models.UserProgress{Repetitions: 8, LastQuality: 5, Interval: 29}, false},
+	}
+	sm := NewSM2()
+	for _, tt := range tests {
+		if got := sm.IsWordMastered(&tt.progress); got != tt.want {
+			t.Errorf("%s: IsWordMastered = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestComputeNextIntervalMatchesProcess(t *testing.T) {
+	sm := NewSM2()
+	for quality := 0; quality <= 5; quality++ {
+		for _, start := range []models.UserProgress{
+			{EasinessFactor: 2.5},
+			{EasinessFactor: 2.5, Repetitions: 1},
+			{EasinessFactor: 1.4, Repetitions: 4, Interval: 3},
+			{EasinessFactor: 2.1, Repetitions: 9, Interval: 30},
+			{EasinessFactor: 2.8, Repetitions: 15, Interval: 300},
+		} {
+			interval, ef, repetitions := sm.ComputeNextInterval(quality, start.Repetitions, start.EasinessFactor, start.Interval)
+			progress := start
+			sm.ProcessAt(&progress, QualityResponse(quality), reviewTime)
+			if interval != progress.Interval || ef != progress.EasinessFactor || repetitions != progress.Repetitions {
+				t.Errorf("quality %d from %+v: ComputeNextInterval gives %d days, EF %v, %d repetitions; Process %d days, EF %v, %d repetitions",
+					quality, start, interval, ef, repetitions, progress.Interval, progress.EasinessFactor, progress.Repetitions)
+			}
+		}
+	}
+}
+
+func TestProcessReadsClock(t *testing.T) {
+	fake := clock.NewFake(reviewTime)
+	sm := NewSM2()
+	sm.Clock = fake
+
+	progress := models.UserProgress{EasinessFactor: 2.5, Repetitions: 2, Interval: 1}
+	sm.Process(&progress, QualityPerfect)
+	if !progress.LastReviewDate.Equal(reviewTime) {
+		t.Errorf("reviewed at %v, want %v", progress.LastReviewDate, reviewTime)
+	}
+	if want := reviewTime.AddDate(0, 0, sm.InitialIntervals[2]); !progress.NextReviewDate.Equal(want) {
+		t.Errorf("due %v, want %v", progress.NextReviewDate, want)
+	}
+
+	fake.Advance(50 * time.Hour)
+	sm.Process(&progress, QualityIncorrect)
+	if want := reviewTime.Add(50*time.Hour).AddDate(0, 0, 1); !progress.NextReviewDate.Equal(want) {
+		t.Errorf("after a failure two days later: due %v, want %v", progress.NextReviewDate, want)
+	}
+}
+
+func TestGetNextWordsReadsClock(t *testing.T) {
+	introduced := reviewTime.AddDate(0, 0, -10)
+	progress := []models.UserProgress{
+		{ID: 1, EasinessFactor: 2.5, NextReviewDate: reviewTime.Add(-time.Hour), IntroducedAt: &introduced},
+		{ID: 2, EasinessFactor: 1.8, NextReviewDate: reviewTime.Add(2 * time.Hour), IntroducedAt: &introduced},
+		{ID: 3, EasinessFactor: 2.5, NextReviewDate: reviewTime.Add(26 * time.Hour), IntroducedAt: &introduced},
+		{ID: 4, EasinessFactor: 2.5, NextReviewDate: reviewTime.Add(-time.Minute)},
+	}
+
+	fake := clock.NewFake(reviewTime)
+	sm := NewSM2()
+	sm.Clock = fake
+
+	tests := []struct {
+		advance time.Duration
+		want    []int
+	}{
+		{0, []int{1, 4}},
+		{3 * time.Hour, []int{2, 4, 1}},
+		{27 * time.Hour, []int{2, 1, 4, 3}},
+	}
+	for _, tt := range tests {
+		fake.Set(reviewTime.Add(tt.advance))
+		words := sm.GetNextWords(progress, 10, -1)
+		var got []int
+		for _, w := range words {
+			got = append(got, w.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%v later: words %v, want %v", tt.advance, got, tt.want)
+		}
+		if at := sm.GetNextWordsAt(progress, 10, -1, fake.Now()); len(at) != len(words) {
+			t.Errorf("%v later: GetNextWordsAt gives %d words, GetNextWords %d", tt.advance, len(at), len(words))
+		}
+	}
+}
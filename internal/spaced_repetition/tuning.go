@@ -0,0 +1,102 @@
+package spaced_repetition
+
+import "math"
+
+// MinTuningReviews is how many reviews of a user it takes to tune the SM-2 parameters
+const MinTuningReviews = 20
+
+// Params are the SM-2 parameters tuned per user, a zero field keeps the default
+type Params struct {
+	PassThreshold    int
+	InitialIntervals []int
+	MinEasiness      float64
+}
+
+// RecallOutcome is one past review: how many days after its date it was done and whether it
+// was remembered
+type RecallOutcome struct {
+	LateDays float64
+	Passed   bool
+}
+
+// WithParams returns a copy of the scheduler with the user's tuned parameters
+func (sm *SM2) WithParams(p Params) *SM2 {
+	tuned := *sm
+	if p.PassThreshold > 0 {
+		tuned.PassThreshold = p.PassThreshold
+	}
+	if len(p.InitialIntervals) > 0 {
+		tuned.InitialIntervals = p.InitialIntervals
+	}
+	if p.MinEasiness > 0 {
+		tuned.MinEasiness = p.MinEasiness
+	}
+	return &tuned
+}
+
+// Params returns the parameters the scheduler runs with
+func (sm *SM2) Params() Params {
+	return Params{PassThreshold: sm.PassThreshold, InitialIntervals: sm.InitialIntervals, MinEasiness: sm.MinEasiness}
+}
+
+// Tune derives the user's parameters from the defaults and the recall history, false while
+// the history is shorter than MinTuningReviews:
+//
+//   - remembering almost everything (95%+) raises PassThreshold to QualityCorrectHesitation,
+//     so "hard" answers no longer count as remembered;
+//   - remembering even the reviews done more than a day late (85%+ of at least 5) stretches
+//     the initial intervals by a quarter, forgetting on-time ones (less than 70%) shrinks them
+//     by a fifth;
+//   - a user who remembers most of the time (85%+) gets a floor of 1.5 for EF, so a few
+//     slips don't shorten the intervals for good.
+func Tune(history []RecallOutcome, defaults Params) (Params, bool) {
+	if len(history) < MinTuningReviews {
+		return defaults, false
+	}
+
+	var passed, onTime, onTimePassed, late, latePassed int
+	for _, review := range history {
+		if review.LateDays > 1 {
+			late++
+			if review.Passed {
+				latePassed++
+			}
+		} else {
+			onTime++
+			if review.Passed {
+				onTimePassed++
+			}
+		}
+		if review.Passed {
+			passed++
+		}
+	}
+	success := float64(passed) / float64(len(history))
+
+	tuned := Params{
+		PassThreshold:    int(QualityCorrectDifficult),
+		InitialIntervals: defaults.InitialIntervals,
+		MinEasiness:      defaults.MinEasiness,
+	}
+	if success >= 0.95 {
+		tuned.PassThreshold = int(QualityCorrectHesitation)
+	}
+	if success >= 0.85 {
+		tuned.MinEasiness = max(defaults.MinEasiness, 1.5)
+	}
+
+	scale := 1.0
+	switch {
+	case late >= 5 && float64(latePassed)/float64(late) >= 0.85:
+		scale = 1.25
+	case onTime > 0 && float64(onTimePassed)/float64(onTime) < 0.7:
+		scale = 0.8
+	}
+	if scale != 1 {
+		tuned.InitialIntervals = make([]int, len(defaults.InitialIntervals))
+		for i, days := range defaults.InitialIntervals {
+			tuned.InitialIntervals[i] = int(math.Round(float64(days) * scale))
+		}
+	}
+	return tuned, true
+}
@@ -0,0 +1,329 @@
+package testing
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Blank stands for the word in a context question
+const Blank = "_____"
+
+// tokenPattern matches the words of a sentence. Apostrophes and hyphens split words,
+// so "cat's" and "well-known" still contain "cat" and "known".
+var tokenPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// irregularForms are the past forms of common irregular verbs, for words whose verb forms
+// weren't filled in
+var irregularForms = map[string][]string{
+	"be": {"am", "is", "are", "was", "were", "been", "being"}, "become": {"became"},
+	"begin": {"began", "begun"}, "break": {"broke", "broken"}, "bring": {"brought"},
+	"build": {"built"}, "buy": {"bought"}, "catch": {"caught"}, "choose": {"chose", "chosen"},
+	"come": {"came"}, "do": {"does", "did", "done"}, "draw": {"drew", "drawn"},
+	"drink": {"drank", "drunk"}, "drive": {"drove", "driven"}, "eat": {"ate", "eaten"},
+	"fall": {"fell", "fallen"}, "feel": {"felt"}, "find": {"found"}, "fly": {"flew", "flown"},
+	"forget": {"forgot", "forgotten"}, "get": {"got", "gotten"}, "give": {"gave", "given"},
+	"go": {"went", "gone"}, "grow": {"grew", "grown"}, "have": {"has", "had"},
+	"hear": {"heard"}, "hold": {"held"}, "keep": {"kept"}, "know": {"knew", "known"},
+	"leave": {"left"}, "lend": {"lent"}, "lose": {"lost"}, "make": {"made"}, "mean": {"meant"},
+	"meet": {"met"}, "pay": {"paid"}, "ride": {"rode", "ridden"}, "ring": {"rang", "rung"},
+	"rise": {"rose", "risen"}, "run": {"ran"}, "say": {"said"}, "see": {"saw", "seen"},
+	"sell": {"sold"}, "send": {"sent"}, "sing": {"sang", "sung"}, "sit": {"sat"},
+	"sleep": {"slept"}, "speak": {"spoke", "spoken"}, "spend": {"spent"}, "stand": {"stood"},
+	"steal": {"stole", "stolen"}, "swim": {"swam", "swum"}, "take": {"took", "taken"},
+	"teach": {"taught"}, "tell": {"told"}, "think": {"thought"}, "throw": {"threw", "thrown"},
+	"understand": {"understood"}, "wake": {"woke", "woken"}, "wear": {"wore", "worn"},
+	"win": {"won"}, "write": {"wrote", "written"},
+}
+
+// UsesWord reports whether the text uses the word in any of the forms replaceWordWithBlank
+// recognizes
+func UsesWord(text, word, verbForms string) bool {
+	_, found := replaceWordWithBlank(text, word, verbForms)
+	return len(found) > 0
+}
+
+// placeholders stand for any words in a phrase, like "someone" in "tell someone off". "one"
+// is one only as "one's", like in "make up one's mind".
+var placeholders = map[string]bool{
+	"someone": true, "somebody": true, "something": true, "oneself": true,
+	"sb": true, "sth": true, "smb": true, "smth": true,
+}
+
+// particles are the adverbs of phrasal verbs an object can come between: "look it up"
+var particles = map[string]bool{
+	"up": true, "down": true, "out": true, "off": true, "on": true, "in": true, "away": true,
+	"back": true, "over": true, "around": true, "about": true, "through": true, "apart": true,
+	"aside": true, "together": true,
+}
+
+// Most words a slot of a phrase stands for
+const (
+	maxPlaceholderWords = 3 // "make up my sister's mind"
+	maxObjectWords      = 2 // "pick the kids up"
+)
+
+// phraseElem is a word of a phrase to find in a sentence, or a slot standing for other words
+type phraseElem struct {
+	word  string
+	forms map[string]bool // the forms the word matches, nil for a slot
+	gap   string          // the normalized gap before the word
+	min   int             // slots only: the fewest and the most words they stand for
+	max   int
+}
+
+// phrase is what replaceWordWithBlank looks for: the words of a word or a phrase and its slots
+type phrase []phraseElem
+
+// newPhrase reads the word or phrase to find. The placeholders become slots, except at the ends
+// where they are not part of the phrase in a sentence ("look after someone" is "look after"),
+// and a phrasal verb of two words gets an optional slot for the object between them. The first
+// word matches with its inflected forms and verbForms, the last one with its inflected forms,
+// the words between as is.
+func newPhrase(word, verbForms string) phrase {
+	spans := tokenPattern.FindAllStringIndex(word, -1)
+	var p phrase
+	for i := 0; i < len(spans); i++ {
+		part := strings.ToLower(word[spans[i][0]:spans[i][1]])
+		gap := ""
+		if i > 0 {
+			gap = normalizeGap(word[spans[i-1][1]:spans[i][0]])
+		}
+		possessive := i+1 < len(spans) && strings.ToLower(word[spans[i+1][0]:spans[i+1][1]]) == "s" &&
+			isApostrophe(word[spans[i][1]:spans[i+1][0]])
+		if placeholders[part] || part == "one" && possessive {
+			if possessive {
+				i++
+			}
+			p = append(p, phraseElem{min: 1, max: maxPlaceholderWords})
+			continue
+		}
+		p = append(p, phraseElem{word: part, gap: gap})
+	}
+	for len(p) > 0 && p[0].slot() {
+		p = p[1:]
+	}
+	for len(p) > 0 && p[len(p)-1].slot() {
+		p = p[:len(p)-1]
+	}
+	if len(p) == 0 {
+		return nil
+	}
+
+	if len(p) == 2 && particles[p[1].word] && p[1].gap == " " {
+		p = phrase{p[0], {min: 0, max: maxObjectWords}, p[1]}
+	}
+	last := len(p) - 1
+	for i := range p {
+		switch {
+		case p[i].slot():
+		case i == 0:
+			p[i].forms = inflections(p[i].word, verbForms)
+		case i == last:
+			p[i].forms = inflections(p[i].word, "")
+		default:
+			p[i].forms = map[string]bool{p[i].word: true}
+		}
+	}
+	return p
+}
+
+// slot reports whether the element stands for other words
+func (e phraseElem) slot() bool {
+	return e.word == ""
+}
+
+// replaceWordWithBlank blanks every whole-word occurrence of word in the sentence, with its
+// inflected forms: "cats", "studied", "running", and "ran" for "run" from the built-in irregular
+// verbs or verbForms ("run - ran - run"). Words inside other words, like "cat" in "category",
+// are kept. A phrase like "give up" matches with its first or last word inflected, with an
+// object between ("give it up") and with the words of its placeholders ("make up one's mind"
+// as "made up her mind"). The whole phrase is blanked, the words of its slots too.
+// Returns the sentence with the blanks and the words blanked, as written in the sentence.
+func replaceWordWithBlank(sentence, word, verbForms string) (string, []string) {
+	p := newPhrase(word, verbForms)
+	if p == nil {
+		return sentence, nil
+	}
+
+	tokens := tokenPattern.FindAllStringIndex(sentence, -1)
+	var result strings.Builder
+	var blanked []string
+	pos := 0
+	for i := 0; i < len(tokens); i++ {
+		end := p.match(sentence, tokens, i, 0, true)
+		if end < 0 {
+			continue
+		}
+		start, stop := tokens[i][0], tokens[end-1][1]
+		result.WriteString(sentence[pos:start])
+		result.WriteString(Blank)
+		blanked = append(blanked, sentence[start:stop])
+		pos = stop
+		i = end - 1
+	}
+	if blanked == nil {
+		return sentence, nil
+	}
+	result.WriteString(sentence[pos:])
+	return result.String(), blanked
+}
+
+// match reports where the phrase from its element e on, found at the token i, ends: the index
+// of the token after it, or -1 if it isn't there. Words next to each other in the phrase are
+// separated the same way in the sentence, the words of a slot belong to the same clause.
+// Slots take the fewest words they can.
+func (p phrase) match(sentence string, tokens [][]int, i, e int, adjacent bool) int {
+	if e == len(p) {
+		return i
+	}
+	elem := p[e]
+	if elem.slot() {
+		for n := 0; n <= elem.max && i+n <= len(tokens); n++ {
+			if n > 0 && !plainGap(sentence[tokens[i+n-2][1]:tokens[i+n-1][0]]) {
+				break
+			}
+			if n < elem.min {
+				continue
+			}
+			if end := p.match(sentence, tokens, i+n, e+1, adjacent && n == 0); end >= 0 {
+				return end
+			}
+		}
+		return -1
+	}
+
+	if i >= len(tokens) || !elem.forms[strings.ToLower(sentence[tokens[i][0]:tokens[i][1]])] {
+		return -1
+	}
+	if e > 0 {
+		gap := sentence[tokens[i-1][1]:tokens[i][0]]
+		if adjacent && normalizeGap(gap) != elem.gap || !adjacent && !plainGap(gap) {
+			return -1
+		}
+	}
+	return p.match(sentence, tokens, i+1, e+1, true)
+}
+
+// plainGap reports whether the gap between two words keeps them in one clause: spaces and
+// apostrophes only, like in "my sister's"
+func plainGap(gap string) bool {
+	return strings.TrimFunc(gap, func(r rune) bool { return unicode.IsSpace(r) || r == '\'' || r == '’' }) == ""
+}
+
+// isApostrophe reports whether the gap is an apostrophe, like in "one's"
+func isApostrophe(gap string) bool {
+	gap = strings.TrimSpace(gap)
+	return gap == "'" || gap == "’"
+}
+
+// normalizeGap collapses the spaces between the words of a phrase
+func normalizeGap(gap string) string {
+	if strings.TrimSpace(gap) == "" {
+		return " "
+	}
+	return strings.Join(strings.Fields(gap), " ")
+}
+
+// inflections returns the lowercase word with its regular English forms, the irregular
+// forms and the forms listed in verbForms. Words of one or two letters are taken as is,
+// their suffixed forms are other words too often ("a" and "as"). Verbs, the words with
+// irregular or listed forms, get no comparatives: "runner" is another word. Neither do words
+// that double their last letter for them, "cater" isn't "cat".
+func inflections(word, verbForms string) map[string]bool {
+	forms := map[string]bool{word: true}
+	verb := false
+	for _, form := range tokenPattern.FindAllString(strings.ToLower(verbForms), -1) {
+		forms[form] = true
+		verb = verb || form != word
+	}
+	for _, form := range irregularForms[word] {
+		forms[form] = true
+		verb = true
+	}
+	n := utf8.RuneCountInString(word)
+	if n <= 2 || !isASCIILetters(word) {
+		return forms
+	}
+
+	add := func(stem string, suffixes ...string) {
+		for _, suffix := range suffixes {
+			forms[stem+suffix] = true
+		}
+	}
+	last := word[len(word)-1]
+	beforeLast := word[len(word)-2]
+	// stop - stopped, run - running, big - bigger
+	doubles := !isVowel(last) && last != 'w' && last != 'x' && last != 'y' && isVowel(beforeLast) &&
+		(n == 3 || !isVowel(word[len(word)-3]))
+
+	add(word, "s", "ed", "ing")
+	if strings.ContainsRune("sxzo", rune(last)) || strings.HasSuffix(word, "ch") || strings.HasSuffix(word, "sh") {
+		add(word, "es")
+	}
+	if !verb && !doubles && last != 'e' {
+		add(word, "er", "est")
+	}
+	switch {
+	case last == 'e':
+		add(word, "d")
+		if !verb {
+			add(word, "r", "st")
+		}
+		add(word[:len(word)-1], "ing")
+	case last == 'y' && !isVowel(beforeLast):
+		add(word[:len(word)-1], "ies", "ied")
+		if !verb {
+			add(word[:len(word)-1], "ier", "iest")
+		}
+	case last == 'c':
+		add(word, "ked", "king")
+	}
+	if doubles {
+		add(word+string(last), "ed", "ing")
+		if !verb {
+			add(word+string(last), "er", "est")
+		}
+	}
+	return forms
+}
+
+// isVowel reports whether the ASCII letter is a vowel
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
+
+// isASCIILetters reports whether the word is spelled with latin letters only
+func isASCIILetters(word string) bool {
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// exampleSentences splits the examples of a word into sentences
+func exampleSentences(examples string) []string {
+	var sentences []string
+	for _, line := range strings.Split(examples, "\n") {
+		start := 0
+		for i := 0; i < len(line); i++ {
+			if strings.IndexByte(".!?", line[i]) >= 0 && (i+1 == len(line) || line[i+1] == ' ') {
+				sentences = appendSentence(sentences, line[start:i+1])
+				start = i + 1
+			}
+		}
+		sentences = appendSentence(sentences, line[start:])
+	}
+	return sentences
+}
+
+// appendSentence appends the trimmed sentence unless it is empty
+func appendSentence(sentences []string, sentence string) []string {
+	if sentence = strings.TrimSpace(sentence); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
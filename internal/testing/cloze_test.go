@@ -0,0 +1,76 @@
+package testing
+
+import (
+	"slices"
+	gotesting "testing"
+)
+
+func TestReplaceWordWithBlank(t *gotesting.T) {
+	tests := []struct {
+		name      string
+		sentence  string
+		word      string
+		verbForms string
+		want      string
+		blanked   []string
+	}{
+		{"whole word", "The cat sleeps.", "cat", "", "The _____ sleeps.", []string{"cat"}},
+		{"word inside a word", "This category is new.", "cat", "", "This category is new.", nil},
+		{"suffix making another word", "They cater for weddings.", "cat", "", "They cater for weddings.", nil},
+		{"plural", "Two cats sleep.", "cat", "", "Two _____ sleep.", []string{"cats"}},
+		{"possessive", "The cat's bowl.", "cat", "", "The _____'s bowl.", []string{"cat"}},
+		{"irregular past", "She ran home.", "run", "", "She _____ home.", []string{"ran"}},
+		{"listed verb forms", "He strove to win.", "strive", "strive - strove - striven", "He _____ to win.", []string{"strove"}},
+		{"no comparative for verbs", "He is a fast runner.", "run", "", "He is a fast runner.", nil},
+		{"comparative", "It is bigger now.", "big", "", "It is _____ now.", []string{"bigger"}},
+		{"two occurrences", "A cat saw a cat.", "cat", "", "A _____ saw a _____.", []string{"cat", "cat"}},
+		{"phrasal verb", "Don't give up now.", "give up", "", "Don't _____ now.", []string{"give up"}},
+		{"object between", "Don't give it up.", "give up", "", "Don't _____.", []string{"give it up"}},
+		{"inflected phrasal verb", "She gave up smoking.", "give up", "", "She _____ smoking.", []string{"gave up"}},
+		{"placeholder", "She made up her mind.", "make up one's mind", "", "She _____.", []string{"made up her mind"}},
+		{"placeholder of several words", "Make up my sister's mind!", "make up one's mind", "", "_____!", []string{"Make up my sister's mind"}},
+		{"placeholder at the end", "Look after the kids.", "look after someone", "", "_____ the kids.", []string{"Look after"}},
+		{"across a clause", "Give, it up.", "give up", "", "Give, it up.", nil},
+		{"no word", "The cat sleeps.", "", "", "The cat sleeps.", nil},
+	}
+	for _, tt := range tests {
+		got, blanked := replaceWordWithBlank(tt.sentence, tt.word, tt.verbForms)
+		if got != tt.want || !slices.Equal(blanked, tt.blanked) {
+			t.Errorf("%s: replaceWordWithBlank(%q, %q) = %q, %q, want %q, %q",
+				tt.name, tt.sentence, tt.word, got, blanked, tt.want, tt.blanked)
+		}
+	}
+}
+
+func TestInflections(t *gotesting.T) {
+	tests := []struct {
+		word, verbForms string
+		has, hasNot     []string
+	}{
+		{"cat", "", []string{"cat", "cats"}, []string{"cater", "cates", "catest"}},
+		{"box", "", []string{"boxes", "boxed"}, nil},
+		{"stop", "", []string{"stops", "stopped", "stopping"}, nil},
+		{"big", "", []string{"bigger", "biggest"}, []string{"biger"}},
+		{"fast", "", []string{"faster", "fastest"}, nil},
+		{"large", "", []string{"larger", "largest"}, []string{"largeer"}},
+		{"happy", "", []string{"happier", "happiest"}, nil},
+		{"study", "", []string{"studies", "studied"}, nil},
+		{"run", "", []string{"ran", "runs", "running"}, []string{"runner", "runer"}},
+		{"write", "", []string{"wrote", "written", "writes", "writing"}, []string{"writer"}},
+		{"hang", "hang - hung - hung", []string{"hung", "hangs"}, []string{"hanger"}},
+		{"as", "", []string{"as"}, []string{"ass"}},
+	}
+	for _, tt := range tests {
+		forms := inflections(tt.word, tt.verbForms)
+		for _, form := range tt.has {
+			if !forms[form] {
+				t.Errorf("inflections(%q) lacks %q", tt.word, form)
+			}
+		}
+		for _, form := range tt.hasNot {
+			if forms[form] {
+				t.Errorf("inflections(%q) has %q", tt.word, form)
+			}
+		}
+	}
+}
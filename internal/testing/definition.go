@@ -0,0 +1,36 @@
+package testing
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/example/engbot/pkg/models"
+)
+
+// defineWord fills the definition question: the definition with the word blanked out, in case
+// the model let it slip, and the word among up to MaxOptions-1 other English words. Words with
+// the same translation or listed as synonyms either way would fit the definition too, so they are left out.
+func defineWord(q *Question, words []models.Word, rng *rand.Rand) bool {
+	if strings.TrimSpace(q.Word.Definition) == "" {
+		return false
+	}
+	q.Definition, _ = replaceWordWithBlank(strings.TrimSpace(q.Word.Definition), q.Word.Word, q.Word.VerbForms)
+
+	word := strings.TrimSpace(q.Word.Word)
+	synonyms := splitList(q.Word.Synonyms)
+	var pool []string
+	for _, w := range words {
+		candidate := strings.TrimSpace(w.Word)
+		if candidate == "" || strings.EqualFold(candidate, word) ||
+			strings.EqualFold(strings.TrimSpace(w.Translation), strings.TrimSpace(q.Word.Translation)) ||
+			slices.ContainsFunc(synonyms, func(s string) bool { return strings.EqualFold(s, candidate) }) ||
+			slices.ContainsFunc(splitList(w.Synonyms), func(s string) bool { return strings.EqualFold(s, word) }) ||
+			slices.ContainsFunc(pool, func(p string) bool { return strings.EqualFold(p, candidate) }) {
+			continue
+		}
+		pool = append(pool, candidate)
+	}
+	q.Options, q.Correct = choices(word, pool, rng)
+	return len(q.Options) >= 2
+}
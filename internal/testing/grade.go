@@ -0,0 +1,131 @@
+package testing
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/example/engbot/internal/spaced_repetition"
+	"github.com/example/engbot/internal/textutil"
+)
+
+// Verdict is how a typed answer compares to the translation
+type Verdict int
+
+const (
+	// Wrong answers match none of the translations
+	Wrong Verdict = iota
+	// Typo answers are a translation with a few letters off, or right in other ways but with
+	// some mistakes
+	Typo
+	// Correct answers are one of the translations
+	Correct
+)
+
+// Grade is the grade of a typed answer
+type Grade struct {
+	Verdict Verdict
+	// Expected is the translation closest to the answer
+	Expected string
+	// Quality is the answer's SM-2 quality
+	Quality spaced_repetition.QualityResponse
+	// InPlace is how many letters or words are in place, spelling and word order answers only
+	InPlace int
+}
+
+// Right reports whether the answer counts as right
+func (g Grade) Right() bool {
+	return g.Verdict != Wrong
+}
+
+// GradeAnswer grades a typed answer against the translation. The translation may list several
+// valid variants separated by commas or semicolons; one matching variant is enough. The answer
+// may list several too, but then each of them has to match and the worst one sets the grade, so
+// that guessing a string of words doesn't pass. Case, extra spaces, surrounding punctuation and
+// ё/е don't matter, and a few typos are tolerated depending on the length of the word.
+//
+// The SM-2 quality is 5 for a right answer, 4 for one with typos, 2 for a near miss,
+// 1 for a wrong answer and 0 for an empty one.
+func GradeAnswer(answer, translation string) Grade {
+	variants := splitVariants(translation)
+	attempts := splitVariants(answer)
+	if len(attempts) == 0 {
+		grade := Grade{Verdict: Wrong, Quality: spaced_repetition.QualityBlackout}
+		if len(variants) > 0 {
+			grade.Expected = variants[0]
+		}
+		return grade
+	}
+
+	var grade Grade
+	for i, attempt := range attempts {
+		if g := gradeAttempt(attempt, variants); i == 0 || g.Quality < grade.Quality {
+			grade = g
+		}
+	}
+	return grade
+}
+
+// gradeAttempt grades a single answer against the closest of the variants
+func gradeAttempt(attempt string, variants []string) Grade {
+	grade := Grade{Verdict: Wrong, Quality: spaced_repetition.QualityIncorrect}
+	if len(variants) > 0 {
+		grade.Expected = variants[0]
+	}
+
+	a := normalizeAnswer(attempt)
+	best := -1
+	for _, variant := range variants {
+		v := normalizeAnswer(variant)
+		if a == v {
+			return Grade{Verdict: Correct, Expected: variant, Quality: spaced_repetition.QualityPerfect}
+		}
+		distance := textutil.Levenshtein(a, v)
+		if best < 0 || distance < best {
+			best = distance
+			grade.Expected = variant
+			tolerance := typoTolerance(v)
+			switch {
+			case distance <= tolerance:
+				grade.Verdict, grade.Quality = Typo, spaced_repetition.QualityCorrectHesitation
+			case distance <= tolerance+1:
+				grade.Verdict, grade.Quality = Wrong, spaced_repetition.QualityIncorrectFamiliar
+			default:
+				grade.Verdict, grade.Quality = Wrong, spaced_repetition.QualityIncorrect
+			}
+		}
+	}
+	return grade
+}
+
+// splitVariants splits a translation into its variants, dropping empty ones
+func splitVariants(s string) []string {
+	var variants []string
+	for _, v := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+// normalizeAnswer lowercases the answer, collapses spaces, drops the punctuation around it
+// and spells ё as е
+func normalizeAnswer(s string) string {
+	s = textutil.Normalize(s)
+	s = strings.TrimFunc(s, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSpace(r) })
+	return strings.ReplaceAll(s, "ё", "е")
+}
+
+// typoTolerance is how many typos a variant of this length tolerates: none for short words,
+// where one letter makes another word, then one, then two for long ones
+func typoTolerance(variant string) int {
+	switch n := utf8.RuneCountInString(variant); {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
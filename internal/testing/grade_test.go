@@ -0,0 +1,39 @@
+package testing_test
+
+import (
+	"testing"
+
+	"github.com/example/engbot/internal/spaced_repetition"
+	wordtest "github.com/example/engbot/internal/testing"
+)
+
+func TestGradeAnswer(t *testing.T) {
+	tests := []struct {
+		answer, translation string
+		verdict             wordtest.Verdict
+		quality             spaced_repetition.QualityResponse
+		expected            string
+	}{
+		{"кот", "кот", wordtest.Correct, spaced_repetition.QualityPerfect, "кот"},
+		{"  Кошка! ", "кот, кошка", wordtest.Correct, spaced_repetition.QualityPerfect, "кошка"},
+		{"елка", "ёлка", wordtest.Correct, spaced_repetition.QualityPerfect, "ёлка"},
+		{"кот, кошка", "кот; кошка", wordtest.Correct, spaced_repetition.QualityPerfect, "кот"},
+		{"сабака", "собака", wordtest.Typo, spaced_repetition.QualityCorrectHesitation, "собака"},
+		{"кит", "кот", wordtest.Wrong, spaced_repetition.QualityIncorrectFamiliar, "кот"},
+		{"дом", "собака", wordtest.Wrong, spaced_repetition.QualityIncorrect, "собака"},
+		{"", "кот", wordtest.Wrong, spaced_repetition.QualityBlackout, "кот"},
+		{" , ", "кот", wordtest.Wrong, spaced_repetition.QualityBlackout, "кот"},
+
+		// Listing guesses doesn't pass: every listed answer has to match
+		{"кот, собака, дом, стол", "кот", wordtest.Wrong, spaced_repetition.QualityIncorrect, "кот"},
+		{"стол; кот", "кот, кошка", wordtest.Wrong, spaced_repetition.QualityIncorrect, "кот"},
+		{"кот, кошко", "кот, кошка", wordtest.Typo, spaced_repetition.QualityCorrectHesitation, "кошка"},
+	}
+	for _, tt := range tests {
+		got := wordtest.GradeAnswer(tt.answer, tt.translation)
+		if got.Verdict != tt.verdict || got.Quality != tt.quality || got.Expected != tt.expected {
+			t.Errorf("GradeAnswer(%q, %q) = %v/%d/%q, want %v/%d/%q", tt.answer, tt.translation,
+				got.Verdict, got.Quality, got.Expected, tt.verdict, tt.quality, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package testing
+
+import (
+	"math/rand"
+
+	"github.com/example/engbot/pkg/models"
+)
+
+// listen fills the listening question: half the time the word is heard in one of its example
+// sentences, otherwise on its own, and the options are translations. Words heard in the
+// sentence besides the word asked about could be right too, so their translations are left out.
+func listen(q *Question, words []models.Word, rng *rand.Rand) bool {
+	q.Audio = q.Word.Word
+	candidates := words
+	if rng.Intn(2) == 0 {
+		sentences := exampleSentences(q.Word.Examples)
+		rng.Shuffle(len(sentences), func(i, j int) { sentences[i], sentences[j] = sentences[j], sentences[i] })
+		for _, sentence := range sentences {
+			if _, blanked := replaceWordWithBlank(sentence, q.Word.Word, q.Word.VerbForms); len(blanked) == 0 {
+				continue
+			}
+			q.Audio, q.Sentence = sentence, sentence
+			candidates = nil
+			for _, w := range words {
+				if _, heard := replaceWordWithBlank(sentence, w.Word, w.VerbForms); len(heard) == 0 {
+					candidates = append(candidates, w)
+				}
+			}
+			break
+		}
+	}
+
+	q.Options, q.Correct = choices(q.Word.Translation, translations(candidates), rng)
+	return len(q.Options) >= 2
+}
@@ -0,0 +1,30 @@
+package testing
+
+import (
+	"strings"
+
+	"github.com/example/engbot/internal/spaced_repetition"
+)
+
+// GradeSentence grades a sentence written with the word by what was found in it: whether it
+// uses the word, in the right meaning, and how many mistakes it has.
+//
+// The SM-2 quality is 5 for a sentence without mistakes, 4 for one mistake and 3 for more,
+// 2 for the word used in another meaning, 1 for a sentence without the word and 0 for an
+// empty one. Sentences with the word in the right meaning count as right.
+func GradeSentence(sentence string, usesWord, rightMeaning bool, mistakes int) Grade {
+	switch {
+	case strings.TrimSpace(sentence) == "":
+		return Grade{Verdict: Wrong, Quality: spaced_repetition.QualityBlackout}
+	case !usesWord:
+		return Grade{Verdict: Wrong, Quality: spaced_repetition.QualityIncorrect}
+	case !rightMeaning:
+		return Grade{Verdict: Wrong, Quality: spaced_repetition.QualityIncorrectFamiliar}
+	case mistakes == 0:
+		return Grade{Verdict: Correct, Quality: spaced_repetition.QualityPerfect}
+	case mistakes == 1:
+		return Grade{Verdict: Typo, Quality: spaced_repetition.QualityCorrectHesitation}
+	default:
+		return Grade{Verdict: Typo, Quality: spaced_repetition.QualityCorrectDifficult}
+	}
+}
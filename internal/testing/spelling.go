@@ -0,0 +1,50 @@
+package testing
+
+import (
+	"math/rand"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxSpellingLetters is the longest word a spelling question asks, so that its letter
+// buttons fit the keyboard
+const MaxSpellingLetters = 16
+
+// spellingLetters returns the lowercased letters of the word for a spelling question, or
+// nil if the word can't be spelled with letter buttons: phrases, words with hyphens or
+// apostrophes, single letters and words longer than MaxSpellingLetters
+func spellingLetters(word string) []string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	n := utf8.RuneCountInString(word)
+	if n < 2 || n > MaxSpellingLetters {
+		return nil
+	}
+	var letters []string
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return nil
+		}
+		letters = append(letters, string(r))
+	}
+	return letters
+}
+
+// scramble fills the spelling question with the letters of the word in random order
+func scramble(q *Question, rng *rand.Rand) bool {
+	letters := spellingLetters(q.Word.Word)
+	if letters == nil {
+		return false
+	}
+	q.Tiles = shuffleTiles(letters, rng)
+	return true
+}
+
+// GradeSpelling grades a word spelled with the letter buttons by the letters in place
+func GradeSpelling(answer, word string) Grade {
+	var letters []string
+	for _, r := range strings.TrimSpace(answer) {
+		letters = append(letters, string(r))
+	}
+	return gradeTiles(letters, spellingLetters(word), strings.TrimSpace(word))
+}
@@ -0,0 +1,385 @@
+// Package testing builds vocabulary tests from the user's words and scores the answers
+package testing
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/example/engbot/pkg/models"
+)
+
+// TestType is the kind of questions a test asks
+type TestType string
+
+const (
+	// MultipleChoice asks to pick the translation among several options
+	MultipleChoice TestType = "multiple_choice"
+	// TextInput asks to type the translation
+	TextInput TestType = "text_input"
+	// Context asks to fill the word into an example sentence
+	Context TestType = "context"
+	// Pronunciation asks to say the word for its translation, answers are transcribed speech
+	Pronunciation TestType = "pronunciation"
+	// Synonym asks to pick the synonym of the word among other English words
+	Synonym TestType = "synonym"
+	// Spelling asks to spell the word for its translation from its scrambled letters
+	Spelling TestType = "spelling"
+	// Listening plays the word or an example sentence with it and asks to pick its translation
+	Listening TestType = "listening"
+	// WordOrder asks to put the shuffled words of an example sentence back in order
+	WordOrder TestType = "word_order"
+	// Sentence asks to write a sentence with the word, answers are checked by a language model
+	Sentence TestType = "sentence"
+	// Definition shows the English definition of the word and asks to pick or type the word
+	Definition TestType = "definition"
+)
+
+// MaxOptions is how many options a multiple choice question offers at most
+const MaxOptions = 4
+
+// ErrNotEnoughWords is returned when the words can't make a single question
+var ErrNotEnoughWords = errors.New("not enough words for a test")
+
+// Options configure a new test
+type Options struct {
+	Type  TestType
+	Count int // number of questions, all the words if 0 or more than there are
+	// Distractors are extra words to draw wrong options from, e.g. the user's other topics
+	Distractors []models.Word
+	// HardFirst picks the words weighted by the square of their difficulty, so in a drill
+	// a word of difficulty 5 comes up 25 times as often as one of difficulty 1
+	HardFirst bool
+	// Define writes the definition of a word that has none yet for definition tests, e.g. with
+	// a language model, and keeps it. After it fails the rest of such words are left out.
+	Define func(word models.Word) (string, error)
+}
+
+// Question is a single question of a test
+type Question struct {
+	Word    models.Word
+	Options []string // translations to choose from, multiple choice and listening only, or words for synonym questions
+	Correct int      // index of the right option
+	// Context questions only: the example sentence, the same sentence with the word blanked
+	// and the words to fill in, as written in the sentence. Listening questions played in
+	// a sentence and word order questions have the sentence too.
+	Sentence string
+	Cloze    string
+	Expected string
+	// Tiles are the pieces to put in order, in random order: the letters of the word for
+	// spelling questions, the words of the sentence for word order ones
+	Tiles []string
+	// Audio is the text to play, listening questions only: the word or the sentence
+	Audio string
+	// Definition is the English definition of the word with the word blanked out, definition
+	// questions only
+	Definition string
+}
+
+// Test is a test being taken
+type Test struct {
+	Type      TestType
+	Questions []Question
+	Answers   []bool // whether each answered question was answered right, in order
+	StartedAt time.Time
+}
+
+// CreateTest picks random words and builds a question for each. For multiple choice the wrong
+// options are translations of the other words and the distractors. Context questions take
+// an example sentence of the word, synonym questions one of its synonyms against the other
+// English words; words without them are left out. Spelling questions scramble the letters of
+// the word, leaving out phrases and words too long for the letter buttons. Listening questions
+// play the word, on its own or in an example sentence, and offer translations like multiple choice.
+// Word order questions shuffle the words of an example sentence, for words that have one.
+// Sentence questions take every word. Definition questions take the words with a definition,
+// written by opts.Define for the ones picked without, and offer English words like synonym ones.
+func CreateTest(words []models.Word, opts Options, rng *rand.Rand) (*Test, error) {
+	if opts.Type == "" {
+		opts.Type = MultipleChoice
+	}
+
+	picked := slices.Clone(words)
+	if opts.HardFirst {
+		shuffleByDifficulty(picked, rng)
+	} else {
+		rng.Shuffle(len(picked), func(i, j int) { picked[i], picked[j] = picked[j], picked[i] })
+	}
+
+	all := append(slices.Clone(words), opts.Distractors...)
+	pool := translations(all)
+	define := opts.Define
+	test := &Test{Type: opts.Type}
+	for _, w := range picked {
+		if opts.Count > 0 && len(test.Questions) == opts.Count {
+			break
+		}
+		q := Question{Word: w}
+		switch opts.Type {
+		case MultipleChoice:
+			q.Options, q.Correct = choices(w.Translation, pool, rng)
+			if len(q.Options) < 2 {
+				continue
+			}
+		case Context:
+			if !cloze(&q, rng) {
+				continue
+			}
+		case Synonym:
+			q.Options, q.Correct = synonymChoices(w, all, rng)
+			if len(q.Options) < 2 {
+				continue
+			}
+		case Spelling:
+			if !scramble(&q, rng) {
+				continue
+			}
+		case Listening:
+			if !listen(&q, all, rng) {
+				continue
+			}
+		case WordOrder:
+			if !orderWords(&q, rng) {
+				continue
+			}
+		case Definition:
+			if q.Word.Definition == "" && define != nil {
+				definition, err := define(q.Word)
+				if err != nil {
+					define = nil
+					continue
+				}
+				q.Word.Definition = definition
+			}
+			if !defineWord(&q, all, rng) {
+				continue
+			}
+		}
+		test.Questions = append(test.Questions, q)
+	}
+	if len(test.Questions) == 0 {
+		return nil, ErrNotEnoughWords
+	}
+	return test, nil
+}
+
+// shuffleByDifficulty orders the words at random, the harder ones more likely first: each word
+// draws the key u^(1/weight) for a uniform u and the largest keys go first
+func shuffleByDifficulty(words []models.Word, rng *rand.Rand) {
+	keys := make(map[int]float64, len(words))
+	for _, w := range words {
+		weight := float64(max(1, min(w.Difficulty, 5)))
+		keys[w.ID] = math.Pow(rng.Float64(), 1/(weight*weight))
+	}
+	slices.SortStableFunc(words, func(a, b models.Word) int {
+		return cmp.Compare(keys[b.ID], keys[a.ID])
+	})
+}
+
+// translations returns the distinct translations of the words
+func translations(words []models.Word) []string {
+	seen := make(map[string]bool, len(words))
+	var result []string
+	for _, w := range words {
+		key := strings.ToLower(strings.TrimSpace(w.Translation))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, strings.TrimSpace(w.Translation))
+	}
+	return result
+}
+
+// choices mixes the right translation with up to MaxOptions-1 others from the pool
+func choices(answer string, pool []string, rng *rand.Rand) ([]string, int) {
+	answer = strings.TrimSpace(answer)
+	var wrong []string
+	for _, t := range pool {
+		if !strings.EqualFold(t, answer) {
+			wrong = append(wrong, t)
+		}
+	}
+	rng.Shuffle(len(wrong), func(i, j int) { wrong[i], wrong[j] = wrong[j], wrong[i] })
+	if len(wrong) > MaxOptions-1 {
+		wrong = wrong[:MaxOptions-1]
+	}
+
+	correct := rng.Intn(len(wrong) + 1)
+	options := slices.Insert(wrong, correct, answer)
+	return options, correct
+}
+
+// synonymChoices mixes a random synonym of the word with up to MaxOptions-1 other English words.
+// Words that share a synonym with it could be right too, so they are left out; its antonyms
+// make fine wrong options.
+func synonymChoices(word models.Word, words []models.Word, rng *rand.Rand) ([]string, int) {
+	synonyms := splitList(word.Synonyms)
+	if len(synonyms) == 0 {
+		return nil, 0
+	}
+	related := append([]string{word.Word}, synonyms...)
+	isRelated := func(s string) bool {
+		return slices.ContainsFunc(related, func(r string) bool { return strings.EqualFold(r, s) })
+	}
+
+	var pool []string
+	for _, w := range words {
+		candidate := strings.TrimSpace(w.Word)
+		if candidate == "" || isRelated(candidate) || slices.ContainsFunc(splitList(w.Synonyms), isRelated) ||
+			slices.ContainsFunc(pool, func(p string) bool { return strings.EqualFold(p, candidate) }) {
+			continue
+		}
+		pool = append(pool, candidate)
+	}
+	return choices(synonyms[rng.Intn(len(synonyms))], pool, rng)
+}
+
+// splitList reads a comma-separated list like the synonyms of a word
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// cloze fills the context question from a random example sentence that has the word
+func cloze(q *Question, rng *rand.Rand) bool {
+	sentences := exampleSentences(q.Word.Examples)
+	rng.Shuffle(len(sentences), func(i, j int) { sentences[i], sentences[j] = sentences[j], sentences[i] })
+	for _, sentence := range sentences {
+		text, blanked := replaceWordWithBlank(sentence, q.Word.Word, q.Word.VerbForms)
+		if len(blanked) == 0 {
+			continue
+		}
+		var expected []string
+		for _, b := range blanked {
+			if !slices.ContainsFunc(expected, func(e string) bool { return strings.EqualFold(e, b) }) {
+				expected = append(expected, b)
+			}
+		}
+		q.Sentence, q.Cloze, q.Expected = sentence, text, strings.Join(expected, ", ")
+		return true
+	}
+	return false
+}
+
+// Current returns the question to answer next, or nil when the test is over
+func (t *Test) Current() *Question {
+	if t.Done() {
+		return nil
+	}
+	return &t.Questions[len(t.Answers)]
+}
+
+// Number returns the 1-based number of the current question
+func (t *Test) Number() int {
+	return len(t.Answers) + 1
+}
+
+// Done reports whether all the questions are answered
+func (t *Test) Done() bool {
+	return len(t.Answers) >= len(t.Questions)
+}
+
+// Choose answers the current multiple choice question with the option and reports whether it was right
+func (t *Test) Choose(option int) bool {
+	q := t.Current()
+	if q == nil {
+		return false
+	}
+	correct := option == q.Correct
+	t.Answers = append(t.Answers, correct)
+	return correct
+}
+
+// Answer grades the typed answer to the current question, records it and returns the grade.
+// Context questions expect the word as written in the sentence, pronunciation and definition
+// questions the word itself, the others its translation. Spelling questions expect the word too
+// and word order ones the sentence, both graded by the pieces in place. Sentence answers are only
+// checked for the word here, see Record for the grade of a language model.
+func (t *Test) Answer(text string) Grade {
+	q := t.Current()
+	if q == nil {
+		return Grade{}
+	}
+	var grade Grade
+	switch t.Type {
+	case Context:
+		grade = GradeAnswer(text, q.Expected)
+	case Pronunciation:
+		grade = GradeAnswer(text, q.Word.Word)
+	case Spelling:
+		grade = GradeSpelling(text, q.Word.Word)
+	case WordOrder:
+		grade = GradeWordOrder(text, q.Sentence)
+	case Sentence:
+		grade = GradeSentence(text, UsesWord(text, q.Word.Word, q.Word.VerbForms), true, 0)
+	case Definition:
+		grade = GradeAnswer(text, q.Word.Word)
+	default:
+		grade = GradeAnswer(text, q.Word.Translation)
+	}
+	t.Answers = append(t.Answers, grade.Right())
+	return grade
+}
+
+// Record answers the current question with a grade made elsewhere, like the check of a written
+// sentence by a language model, and returns it
+func (t *Test) Record(grade Grade) Grade {
+	if t.Current() == nil {
+		return Grade{}
+	}
+	t.Answers = append(t.Answers, grade.Right())
+	return grade
+}
+
+// Score returns the number of right answers
+func (t *Test) Score() int {
+	score := 0
+	for _, correct := range t.Answers {
+		if correct {
+			score++
+		}
+	}
+	return score
+}
+
+// Mistakes returns the words answered wrong
+func (t *Test) Mistakes() []models.Word {
+	var words []models.Word
+	for i, correct := range t.Answers {
+		if !correct {
+			words = append(words, t.Questions[i].Word)
+		}
+	}
+	return words
+}
+
+// Result sums up the answered questions for saving. A test stopped early counts only
+// the questions answered.
+func (t *Test) Result(userID int64, now time.Time) models.TestResult {
+	var topics []int64
+	for _, q := range t.Questions[:len(t.Answers)] {
+		if !slices.Contains(topics, q.Word.TopicID) {
+			topics = append(topics, q.Word.TopicID)
+		}
+	}
+	slices.Sort(topics)
+
+	return models.TestResult{
+		UserID:       userID,
+		TestType:     string(t.Type),
+		TotalWords:   len(t.Answers),
+		CorrectWords: t.Score(),
+		Topics:       topics,
+		TestDate:     t.StartedAt,
+		Duration:     int(now.Sub(t.StartedAt).Seconds()),
+	}
+}
@@ -0,0 +1,50 @@
+package testing
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/example/engbot/internal/spaced_repetition"
+)
+
+// shuffleTiles puts the tiles of a spelling or word order question in random order, never in
+// the right one unless all the tiles are the same
+func shuffleTiles(tiles []string, rng *rand.Rand) []string {
+	right := strings.ToLower(strings.Join(tiles, "\x00"))
+	for attempt := 0; attempt < 10; attempt++ {
+		rng.Shuffle(len(tiles), func(i, j int) { tiles[i], tiles[j] = tiles[j], tiles[i] })
+		if strings.ToLower(strings.Join(tiles, "\x00")) != right {
+			break
+		}
+	}
+	return tiles
+}
+
+// gradeTiles grades an answer put together from the question's own tiles. A mistake is a tile
+// out of place, so the answer is graded by the share of the tiles in place: all of them is
+// a right answer, 80% or more counts with a slip like a typo, half or more is a near miss.
+// Case doesn't matter, so the same word twice can go in either order.
+func gradeTiles(answer, expected []string, display string) Grade {
+	grade := Grade{Verdict: Wrong, Expected: display, Quality: spaced_repetition.QualityBlackout}
+	if len(answer) == 0 || len(expected) == 0 {
+		return grade
+	}
+
+	for i, tile := range answer {
+		if i < len(expected) && strings.EqualFold(tile, expected[i]) {
+			grade.InPlace++
+		}
+	}
+	share := float64(grade.InPlace) / float64(len(expected))
+	switch {
+	case grade.InPlace == len(expected) && len(answer) == len(expected):
+		grade.Verdict, grade.Quality = Correct, spaced_repetition.QualityPerfect
+	case share >= 0.8:
+		grade.Verdict, grade.Quality = Typo, spaced_repetition.QualityCorrectHesitation
+	case share >= 0.5:
+		grade.Quality = spaced_repetition.QualityIncorrectFamiliar
+	default:
+		grade.Quality = spaced_repetition.QualityIncorrect
+	}
+	return grade
+}
@@ -0,0 +1,45 @@
+package testing
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// Bounds on the words of a sentence to put in order: shorter ones are no exercise, longer
+// ones don't fit the buttons
+const (
+	MinWordOrderWords = 3
+	MaxWordOrderWords = 12
+)
+
+// sentenceWords splits the sentence into the words to put in order, the final punctuation
+// left out. Other punctuation stays with its word.
+func sentenceWords(sentence string) []string {
+	return strings.Fields(strings.TrimRight(strings.TrimSpace(sentence), ".!?…"))
+}
+
+// orderWords fills the word order question from a random example sentence that has the word
+// and isn't too short or too long, its words in random order
+func orderWords(q *Question, rng *rand.Rand) bool {
+	sentences := exampleSentences(q.Word.Examples)
+	rng.Shuffle(len(sentences), func(i, j int) { sentences[i], sentences[j] = sentences[j], sentences[i] })
+	for _, sentence := range sentences {
+		words := sentenceWords(sentence)
+		if len(words) < MinWordOrderWords || len(words) > MaxWordOrderWords {
+			continue
+		}
+		if _, blanked := replaceWordWithBlank(sentence, q.Word.Word, q.Word.VerbForms); len(blanked) == 0 {
+			continue
+		}
+		q.Sentence = sentence
+		q.Tiles = shuffleTiles(words, rng)
+		return true
+	}
+	return false
+}
+
+// GradeWordOrder grades a sentence put together from the word buttons, its words separated
+// by spaces, by the words in place
+func GradeWordOrder(answer, sentence string) Grade {
+	return gradeTiles(strings.Fields(answer), sentenceWords(sentence), strings.TrimSpace(sentence))
+}
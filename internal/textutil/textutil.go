@@ -0,0 +1,58 @@
+package textutil
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Normalize lowercases s, trims it and collapses inner whitespace, so that
+// "Английская  грамматика " and "английская грамматика" compare equal
+func Normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// Levenshtein returns the edit distance between a and b counted in runes
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// IsSimilar reports whether the normalized strings differ by at most maxDistance edits.
+// Very short strings must differ by less than half their length, so "Go" and "C#" aren't similar.
+func IsSimilar(a, b string, maxDistance int) bool {
+	a, b = Normalize(a), Normalize(b)
+	distance := Levenshtein(a, b)
+	if distance > maxDistance {
+		return false
+	}
+
+	shortest := utf8.RuneCountInString(a)
+	if n := utf8.RuneCountInString(b); n < shortest {
+		shortest = n
+	}
+	return distance*2 < shortest
+}
@@ -0,0 +1,75 @@
+package textutil
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Английская  грамматика ", "английская грамматика"},
+		{"  Present\tSimple\n", "present simple"},
+		{"GO", "go"},
+		{"   ", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"тема", "тема", 0},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"ёж", "еж", 1}, // runes, not bytes
+		{"грамматика", "граматика", 1},
+		{"алгоритмы", "алгоритм", 1},
+	}
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestIsSimilar(t *testing.T) {
+	tests := []struct {
+		a, b        string
+		maxDistance int
+		want        bool
+	}{
+		{"Go", "go", 2, true},
+		{"Английская  грамматика", "английская грамматика ", 0, true},
+		{"Алгоритмы", "алгоритм", 2, true},
+		{"Грамматика", "Граматика", 1, true},
+		{"Java", "Jawa", 2, true},
+		{"cat", "car", 2, true},
+		// Just over the edit distance
+		{"Грамматика", "Граматик", 1, false},
+		{"abcdefgh", "abcdxyzh", 2, false},
+		{"abcdefgh", "abcdxyzh", 3, true},
+		// Short strings must differ by less than half their length
+		{"Go", "C#", 2, false},
+		{"Go", "Ga", 2, false},
+		{"SQL", "SQS", 2, true},
+		{"SQL", "PHP", 5, false},
+		{"kitten", "sitting", 3, false},
+	}
+	for _, tt := range tests {
+		if got := IsSimilar(tt.a, tt.b, tt.maxDistance); got != tt.want {
+			t.Errorf("IsSimilar(%q, %q, %d) = %v, want %v", tt.a, tt.b, tt.maxDistance, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package tts generates pronunciation audio for words
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Defaults of the OpenAI speech API
+const (
+	DefaultModel = "tts-1"
+	DefaultVoice = "alloy"
+)
+
+// openAISpeechURL is the OpenAI speech endpoint
+const openAISpeechURL = "https://api.openai.com/v1/audio/speech"
+
+// requestTimeout bounds the synthesis of a clip
+const requestTimeout = 30 * time.Second
+
+// maxAudioSize bounds the clip read from the API, a word or a phrase takes a few kilobytes
+const maxAudioSize = 1 << 20
+
+// maxTextLength is the longest text worth a clip, the API accepts up to 4096 characters
+const maxTextLength = 500
+
+// ErrTextTooLong is returned for texts longer than maxTextLength
+var ErrTextTooLong = errors.New("text is too long to pronounce")
+
+// Synthesizer turns English text into speech
+type Synthesizer interface {
+	// Synthesize returns the text spoken as OGG/Opus, the format of Telegram voice messages
+	Synthesize(ctx context.Context, text string) ([]byte, error)
+}
+
+// OpenAI synthesizes speech with the OpenAI speech API
+type OpenAI struct {
+	apiKey string
+	model  string
+	voice  string
+	url    string
+	client *http.Client
+}
+
+// NewOpenAI creates a synthesizer with the model and voice, DefaultModel and DefaultVoice if empty
+func NewOpenAI(apiKey, model, voice string) *OpenAI {
+	if model == "" {
+		model = DefaultModel
+	}
+	if voice == "" {
+		voice = DefaultVoice
+	}
+	return &OpenAI{
+		apiKey: apiKey,
+		model:  model,
+		voice:  voice,
+		url:    openAISpeechURL,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// speechRequest is the body of a speech request
+type speechRequest struct {
+	Model          string `json:"model"`
+	Input          string `json:"input"`
+	Voice          string `json:"voice"`
+	ResponseFormat string `json:"response_format"`
+}
+
+// Synthesize implements Synthesizer
+func (s *OpenAI) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.New("nothing to pronounce")
+	}
+	if len([]rune(text)) > maxTextLength {
+		return nil, ErrTextTooLong
+	}
+
+	payload, err := json.Marshal(speechRequest{Model: s.model, Input: text, Voice: s.voice, ResponseFormat: "opus"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call OpenAI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAudioSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAI response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var result struct {
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &result) == nil && result.Error != nil {
+			return nil, fmt.Errorf("OpenAI returned %s: %s", resp.Status, result.Error.Message)
+		}
+		return nil, fmt.Errorf("OpenAI returned %s", resp.Status)
+	}
+	if len(data) > maxAudioSize {
+		return nil, fmt.Errorf("audio is larger than %d bytes", maxAudioSize)
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty audio from OpenAI")
+	}
+	return data, nil
+}
@@ -2,17 +2,55 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/example/engbot/internal/api"
+	"github.com/example/engbot/internal/backup"
 	"github.com/example/engbot/internal/bot"
 	"github.com/example/engbot/internal/database"
+	"github.com/example/engbot/internal/health"
+	"github.com/example/engbot/internal/logging"
 )
 
+// fatal logs the error and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
 func main() {
+	migrate := flag.String("migrate", "", "run schema migrations and exit: up, down or status")
+	steps := flag.Int("steps", 1, "number of migrations to roll back with -migrate=down")
+	restore := flag.String("restore", "", "replace the SQLite database with a backup and exit: a file, a backup name or latest")
+	flag.Parse()
+
+	// LOG_LEVEL: debug, info, warn, error; LOG_FORMAT: text or json
+	logging.Setup(os.Stderr, os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"))
+
+	if *migrate != "" {
+		if err := database.Open(); err != nil {
+			fatal("failed to connect to database", "error", err)
+		}
+		defer database.Close()
+		if err := database.Migrate(context.Background(), *migrate, *steps); err != nil {
+			fatal("migration failed", "error", err)
+		}
+		return
+	}
+
+	if *restore != "" {
+		if err := restoreBackup(context.Background(), *restore); err != nil {
+			fatal("restore failed", "error", err)
+		}
+		return
+	}
+
 	// Создаем канал для сигналов
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -24,53 +62,61 @@ func main() {
 	// Подключаемся к базе данных
 	err := database.Connect()
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("failed to connect to database", "error", err)
 	}
 	defer database.Close()
 
 	// Создаем бота
 	token := os.Getenv("TELEGRAM_BOT_TOKEN")
 	if token == "" {
-		log.Fatal("TELEGRAM_BOT_TOKEN environment variable is not set")
+		fatal("TELEGRAM_BOT_TOKEN environment variable is not set")
 	}
 
 	b, err := bot.NewBot(token)
 	if err != nil {
-		log.Fatalf("Failed to create bot: %v", err)
+		fatal("failed to create bot", "error", err)
 	}
 
 	// Канал для ожидания завершения бота
 	done := make(chan struct{})
 
-	// Запускаем проверку повторений в отдельной горутине
-	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
-		defer ticker.Stop()
+	// HTTP-сервер для проверок /healthz и /readyz, включается через HEALTH_ADDR (например, :8080)
+	healthDone := make(chan struct{})
+	if addr := os.Getenv("HEALTH_ADDR"); addr != "" {
+		probes := health.NewServer(addr)
+		probes.AddLiveness("database", database.Ping)
+		probes.AddLiveness("telegram", b.CheckTelegram)
+		probes.AddReadiness("database", database.Ping)
+		probes.AddReadiness("bot", b.CheckReady)
 
-		// Выполняем первоначальную проверку
-		err := b.CheckDueRepetitions(ctx)
-		if err != nil {
-			log.Printf("Error checking due repetitions: %v", err)
-		}
+		go func() {
+			defer close(healthDone)
+			if err := probes.Run(ctx); err != nil {
+				slog.Error("health server failed", "error", err)
+			}
+		}()
+	} else {
+		close(healthDone)
+	}
 
-		for {
-			select {
-			case <-ticker.C:
-				err := b.CheckDueRepetitions(ctx)
-				if err != nil {
-					log.Printf("Error checking due repetitions: %v", err)
-				}
-			case <-ctx.Done():
-				log.Println("Stopping repetition checker...")
-				return
+	// REST API для веб- и мобильных клиентов, включается через API_ADDR (например, :8081)
+	apiDone := make(chan struct{})
+	if apiConfig := api.ConfigFromEnv(token); apiConfig.Addr != "" {
+		server := api.NewServer(apiConfig)
+		go func() {
+			defer close(apiDone)
+			if err := server.Run(ctx); err != nil {
+				slog.Error("API server failed", "error", err)
 			}
-		}
-	}()
+		}()
+	} else {
+		close(apiDone)
+	}
 
 	// Горутина для обработки сигналов
 	go func() {
 		sig := <-sigChan
-		log.Printf("Received signal: %v\n", sig)
+		slog.Info("received signal", "signal", sig.String())
 		cancel() // Отменяем контекст
 		
 		// Даем время на graceful shutdown
@@ -78,21 +124,51 @@ func main() {
 		defer shutdownCancel()
 
 		if err := b.Stop(shutdownCtx); err != nil {
-			log.Printf("Error during shutdown: %v", err)
+			slog.Error("failed to shut down", "error", err)
 		}
 		
 		close(done) // Сигнализируем о завершении
 	}()
 
 	// Запускаем бота
-	log.Println("Bot started. Press Ctrl+C to stop.")
+	slog.Info("bot started, press Ctrl+C to stop")
 	go func() {
 		if err := b.Start(ctx); err != nil && err != context.Canceled {
-			log.Printf("Bot error: %v", err)
+			slog.Error("bot stopped with error", "error", err)
 		}
 	}()
 
 	// Ждем сигнала завершения
 	<-done
-	log.Println("Bot stopped successfully")
-} 
\ No newline at end of file
+	<-healthDone
+	<-apiDone
+	slog.Info("bot stopped")
+} 
+// restoreBackup puts a backup in place of the database. The backup is a local file or, if there is
+// no such file, a backup in the store configured by BACKUP_DIR or BACKUP_S3_BUCKET.
+func restoreBackup(ctx context.Context, source string) error {
+	path := source
+	if _, err := os.Stat(source); err != nil {
+		manager, err := backup.NewManagerFromEnv()
+		if err != nil {
+			return err
+		}
+		if manager == nil {
+			return fmt.Errorf("no file %s and no backup storage configured", source)
+		}
+		dir, err := os.MkdirTemp("", "engbot-restore")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		if path, err = manager.Download(ctx, source, dir); err != nil {
+			return err
+		}
+	}
+
+	if err := database.Restore(ctx, path); err != nil {
+		return err
+	}
+	slog.Info("database restored", "backup", source)
+	return nil
+}
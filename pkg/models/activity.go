@@ -0,0 +1,16 @@
+package models
+
+// DailyActivity counts the reviews a user did on one day: rated flashcards and completed repetitions
+type DailyActivity struct {
+	UserID    int64  `json:"user_id" db:"user_id"`
+	Day       string `json:"day" db:"day"` // YYYY-MM-DD in the bot's time zone
+	Reviews   int    `json:"reviews" db:"reviews"`
+	Goal      int    `json:"goal" db:"goal"`           // The user's daily goal on that day, 0 means none
+	Protected bool   `json:"protected" db:"protected"` // Nothing was due, so the day doesn't break the streak
+}
+
+// GoalMet reports whether the day counts toward the streak: the daily goal is reached,
+// or at least one review is done when there is no goal
+func (a DailyActivity) GoalMet() bool {
+	return a.Reviews >= max(a.Goal, 1)
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Deck is a curated word collection, such as "Irregular Verbs", that users can subscribe to
+type Deck struct {
+	ID          int64     `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	WordCount   int       `json:"word_count" db:"word_count"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DeckWord is a word of a deck. Subscribing copies it into the user's own words.
+type DeckWord struct {
+	ID          int64  `json:"id" db:"id"`
+	DeckID      int64  `json:"deck_id" db:"deck_id"`
+	Word        string `json:"word" db:"word"`
+	Translation string `json:"translation" db:"translation"`
+	Description string `json:"description,omitempty" db:"description"`
+	Examples    string `json:"examples,omitempty" db:"examples"`
+	VerbForms   string `json:"verb_forms,omitempty" db:"verb_forms"`
+}
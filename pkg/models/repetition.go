@@ -12,6 +12,8 @@ type Repetition struct {
     NextReviewDate  time.Time `json:"next_review_date" db:"next_review_date"`
     LastReviewDate  *time.Time `json:"last_review_date" db:"last_review_date"`
     Completed       bool      `json:"completed" db:"completed"`
+    Maintenance     bool      `json:"maintenance" db:"maintenance"` // a long-interval review after the cycle
+    Notes           string    `json:"notes" db:"notes"`
     CreatedAt       time.Time `json:"created_at" db:"created_at"`
     UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
 } 
\ No newline at end of file
@@ -2,11 +2,59 @@ package models
 
 import "time"
 
+// Topic difficulty bounds (1 - very easy, 5 - very hard)
+const (
+	MinTopicDifficulty     = 1
+	MaxTopicDifficulty     = 5
+	DefaultTopicDifficulty = 3
+)
+
 // Topic represents a subject or theme that needs to be reviewed
 type Topic struct {
 	ID          int64     `json:"id" db:"id"`
 	UserID      int64     `json:"user_id" db:"user_id"`
+	ParentID    int64     `json:"parent_id" db:"parent_id"` // 0 for a top-level topic
+	Depth       int       `json:"-" db:"-"`                 // nesting level in the topic list, 0 for top-level
 	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Difficulty  int       `json:"difficulty" db:"difficulty"` // 1-5, used to pick the interval ladder
+	Archived    bool      `json:"archived" db:"archived"`     // kept for history, no reminders
+	Muted       bool      `json:"muted" db:"muted"`           // no reminders, still listed as active
+	Stalled     bool      `json:"stalled" db:"stalled"`       // overdue for too long, only the weekly revive-or-archive prompt
+	Maintenance bool      `json:"maintenance" db:"maintenance"` // keeps getting maintenance reviews after the cycle
+	Category    string    `json:"category" db:"category"`
+	// CustomIntervals is a JSON array of days replacing the user's ladder for this topic, empty for none
+	CustomIntervals string `json:"custom_intervals" db:"custom_intervals"`
+	// ReviewDirection of the topic's flashcards, one of the Direction* values, empty for the user's
+	ReviewDirection string `json:"review_direction" db:"review_direction"`
+	// SM-2 state of graded reviews
+	EasinessFactor float64 `json:"easiness_factor" db:"easiness_factor"`
+	ReviewInterval int     `json:"review_interval" db:"review_interval"` // days
+	ReviewCount    int     `json:"review_count" db:"review_count"`       // successful graded reviews
+	LeitnerBox     int     `json:"leitner_box" db:"leitner_box"`         // 1 to LeitnerBoxes, moved only by the Leitner scheduler
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
-}
\ No newline at end of file
+}
+
+// MaxTopicDepth is how deep topics nest, e.g. course → module → lesson
+const MaxTopicDepth = 3
+
+// Kinds of topic attachments
+const (
+	AttachmentLink     = "link"
+	AttachmentPhoto    = "photo"
+	AttachmentDocument = "document"
+	AttachmentNote     = "note"
+)
+
+// TopicAttachment is study material of a topic: a link, a photo or document kept in Telegram,
+// or a text note such as an example
+type TopicAttachment struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	TopicID   int64     `json:"topic_id" db:"topic_id"`
+	Kind      string    `json:"kind" db:"kind"`
+	Content   string    `json:"content" db:"content"` // the URL of a link, the Telegram file ID of a file, the text of a note
+	Caption   string    `json:"caption" db:"caption"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
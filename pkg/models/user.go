@@ -2,6 +2,29 @@ package models
 
 import "time"
 
+// Overdue policies: what happens to a repetition overdue by more than User.OverdueDays
+const (
+	OverdueRemind     = "remind"     // keep reminding every day
+	OverdueReschedule = "reschedule" // count the interval of the repetition again from today
+	OverdueReset      = "reset"      // start the topic over from repetition #1
+	OverdueStall      = "stall"      // stop reminding, ask weekly to revive or archive the topic
+)
+
+// Schedulers that pick the next review of a topic, see User.Scheduler
+const (
+	SchedulerSM2     = "sm2"     // SuperMemo-2: the interval ladder stretched by the answers
+	SchedulerLeitner = "leitner" // Leitner boxes: a fixed interval per box, a mistake goes back to box 1
+)
+
+// DefaultOverdueDays is how many days overdue a repetition gets before the policy applies
+const DefaultOverdueDays = 7
+
+// When the weekly report comes by default: Sunday evening
+const (
+	DefaultReportDay  = 0 // time.Sunday
+	DefaultReportHour = 19
+)
+
 // User represents a Telegram user using the bot
 type User struct {
 	ID                  int64     `json:"id" db:"id"`
@@ -13,7 +36,24 @@ type User struct {
 	PreferredTopics     []int64   `json:"preferred_topics" db:"preferred_topics"` // Array of topic IDs
 	NotificationEnabled bool      `json:"notification_enabled" db:"notification_enabled"`
 	NotificationHour    int       `json:"notification_hour" db:"notification_hour"` // Hour of day for notifications (0-23)
-	WordsPerDay         int       `json:"words_per_day" db:"words_per_day"`
+	NotificationHours   string    `json:"notification_hours" db:"notification_hours"` // All reminder hours, comma-separated; empty means only NotificationHour
+	QuietHoursStart     int       `json:"quiet_hours_start" db:"quiet_hours_start"` // No reminders from this hour...
+	QuietHoursEnd       int       `json:"quiet_hours_end" db:"quiet_hours_end"` // ...up to this one, equal bounds disable quiet hours
+	SkipFirstRepetitions int      `json:"skip_first_repetitions" db:"skip_first_repetitions"` // No reminders for repetitions #1..N
+	DigestEnabled       bool      `json:"digest_enabled" db:"digest_enabled"` // One combined morning digest instead of reminders
+	Language            string    `json:"language" db:"language"` // Interface language code, empty means the bot default
+	BroadcastOptOut     bool      `json:"broadcast_opt_out" db:"broadcast_opt_out"` // No admin announcements
+	DailyGoal           int       `json:"daily_goal" db:"daily_goal"` // Reviews per day that keep the streak going, 0 means any review does
+	StoryEnabled        bool      `json:"story_enabled" db:"story_enabled"` // A daily AI story with the words to review
+	BoardEnabled        bool      `json:"board_enabled" db:"board_enabled"` // One pinned message edited in place instead of reminders
+	BoardMessageID      int       `json:"board_message_id" db:"board_message_id"` // The pinned board, 0 until it is sent
+	OverduePolicy       string    `json:"overdue_policy" db:"overdue_policy"` // What happens to repetitions overdue by OverdueDays, see OverdueRemind
+	OverdueDays         int       `json:"overdue_days" db:"overdue_days"`
+	DailyReviewLimit    int       `json:"daily_review_limit" db:"daily_review_limit"` // Most repetitions due per day, the rest spill over; 0 means no limit
+	ReportEnabled       bool      `json:"report_enabled" db:"report_enabled"` // A weekly progress report
+	ReportDay           int       `json:"report_day" db:"report_day"` // Day of the week of the report, 0 is Sunday as in time.Weekday
+	ReportHour          int       `json:"report_hour" db:"report_hour"` // Hour of the report (0-23)
+	Scheduler           string    `json:"scheduler" db:"scheduler"` // SchedulerSM2 or SchedulerLeitner, empty means SM-2
 	CreatedAt           time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
 } 
\ No newline at end of file
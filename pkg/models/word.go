@@ -1,16 +1,54 @@
 package models
 
+import "strings"
+
+// Enrichment statuses of a word: imported words without examples wait for a language model
+// to fill in the examples, the description and the verb forms
+const (
+	EnrichmentPending = "pending"
+	EnrichmentDone    = "done"
+	EnrichmentFailed  = "failed"
+)
+
+// Kinds of a word: a single word, a phrase learned as a unit like "look forward to", or a
+// collocation, words that usually go together like "make a decision"
+const (
+	KindWord        = "word"
+	KindPhrase      = "phrase"
+	KindCollocation = "collocation"
+)
+
 // Word represents an English word to be learned
 type Word struct {
 	ID           int       `json:"id" db:"id"`
 	Word         string    `json:"word" db:"word"`
+	Kind         string    `json:"kind,omitempty" db:"kind"` // KindWord, KindPhrase or KindCollocation
 	Translation  string    `json:"translation" db:"translation"`
 	Description  string    `json:"description,omitempty" db:"description"`
 	TopicID      int64     `json:"topic_id" db:"topic_id"`
+	UserID       int64     `json:"user_id" db:"user_id"` // Owner of the word's topic
 	Difficulty   int       `json:"difficulty,omitempty" db:"difficulty"` // 1-5 scale of difficulty
 	Pronunciation string    `json:"pronunciation,omitempty" db:"pronunciation"` // Optional: URL to audio pronunciation
 	Examples     string    `json:"examples,omitempty" db:"examples"` // Optional: Examples of word usage
 	VerbForms    string    `json:"verb_forms,omitempty" db:"verb_forms"` // Optional: Forms of irregular verbs
+	EnrichmentStatus string `json:"enrichment_status,omitempty" db:"enrichment_status"` // Empty if the word needs no enrichment
+	Synonyms     string    `json:"synonyms,omitempty" db:"synonyms"` // Optional: Comma-separated synonyms
+	Antonyms     string    `json:"antonyms,omitempty" db:"antonyms"` // Optional: Comma-separated antonyms
+	Definition   string    `json:"definition,omitempty" db:"definition"` // Optional: English definition for the definition quiz
 	CreatedAt    string    `json:"created_at" db:"created_at"`
 	UpdatedAt    string    `json:"updated_at" db:"updated_at"`
-} 
\ No newline at end of file
+} 
+
+// IsPhrase reports whether the word is a phrase or a collocation, learned as a unit
+func (w Word) IsPhrase() bool {
+	return w.Kind == KindPhrase || w.Kind == KindCollocation
+}
+
+// KindOf returns the kind a new word gets: a phrase if it has several words, a single word
+// otherwise. Hyphenated words like "well-known" are single words.
+func KindOf(word string) string {
+	if len(strings.Fields(word)) > 1 {
+		return KindPhrase
+	}
+	return KindWord
+}